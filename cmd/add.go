@@ -0,0 +1,676 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/diff"
+	"github.com/KostasZigo/gogit/internal/index"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/patch"
+	"github.com/KostasZigo/gogit/internal/pathspec"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/spf13/cobra"
+)
+
+var addCmd = &cobra.Command{
+	Use:   "add [-p | -u | -A] [--] [<pathspec>...]",
+	Short: "Add file contents to the index",
+	Long: `Stage the current content of the files matching <pathspec>. New and
+modified files are recorded in the index, and tracked files missing from the
+working tree are removed from it. Ignore files are not read yet, so every
+untracked file that matches is added.
+
+-u only updates files already in the index, leaving untracked files alone.
+-A stages every change, like plain add. Both cover the whole working tree,
+not just the current directory, when no <pathspec> is given.
+
+With -p, the differences between the index and the working tree are shown
+one hunk at a time, and only the hunks chosen are staged. <pathspec> limits
+which files are offered; untracked files never are. For each hunk:
+
+  y - stage this hunk
+  n - do not stage this hunk
+  q - quit; do not stage this hunk or any of the remaining ones
+  a - stage this hunk and all later hunks in the file
+  d - do not stage this hunk or any of the later hunks in the file
+  s - split the current hunk into smaller hunks
+  e - manually edit the current hunk
+  ? - print help
+
+Mode changes and deletions are offered on their own. Hunks are edited in
+GOGIT_EDITOR, core.editor, VISUAL or EDITOR, in that order. Binary files,
+symbolic links and unmerged paths are not offered.
+
+Examples:
+  # Stage everything below the current directory
+  gogit add .
+
+  # Stage modifications and deletions of tracked files anywhere
+  gogit add -u
+
+  # Choose which changes to Go files to stage
+  gogit add -p -- '*.go'`,
+	SilenceUsage: true,
+	RunE:         runAdd,
+}
+
+var (
+	addPatchFlag  bool
+	addUpdateFlag bool
+	addAllFlag    bool
+)
+
+// addPatchContext is the number of unchanged lines shown around each hunk.
+const addPatchContext = 3
+
+// addAbbrevLength is the number of hex digits of object names in diff headers.
+const addAbbrevLength = 7
+
+func init() {
+	rootCmd.AddCommand(addCmd)
+
+	addCmd.Flags().BoolVarP(&addPatchFlag, "patch", "p", false, "Interactively choose hunks to stage")
+	addCmd.Flags().BoolVarP(&addUpdateFlag, "update", "u", false, "Stage changes to tracked files only")
+	addCmd.Flags().BoolVarP(&addAllFlag, "all", "A", false, "Stage all changes, in the whole working tree without <pathspec>")
+}
+
+// runAdd stages the files selected by the arguments, or the hunks chosen with -p.
+func runAdd(cmd *cobra.Command, args []string) error {
+	if addUpdateFlag && addAllFlag {
+		return errors.New("options '-A' and '-u' cannot be used together")
+	}
+	if len(args) == 0 && !addPatchFlag && !addUpdateFlag && !addAllFlag {
+		fmt.Fprintln(cmd.ErrOrStderr(), "Nothing specified, nothing added.")
+		return nil
+	}
+
+	layout, err := findRepoRoot()
+	if err != nil {
+		return err
+	}
+	paths, err := parsePathspec(layout.Root, args)
+	if err != nil {
+		return err
+	}
+
+	if addPatchFlag {
+		return addPatch(cmd, layout, paths)
+	}
+	if len(args) == 0 {
+		// -u and -A without a pathspec cover the whole worktree
+		paths = nil
+	}
+	return addFiles(layout, paths, !addUpdateFlag)
+}
+
+// addFiles stages the worktree state of every path selected by paths, leaving untracked files
+// alone unless untracked is set. Tracked files missing from the worktree are removed before
+// files are added, so a file may replace a directory.
+func addFiles(layout repository.Layout, paths *pathspec.Pathspec, untracked bool) error {
+	files, dirs, err := worktreeFiles(layout, paths)
+	if err != nil {
+		return err
+	}
+	repoPath := layout.Root
+	store := newObjectStore(layout.GitDir)
+
+	return index.Update(layout.GitDir, func(idx *index.Index) error {
+		var tracked []string
+		gitlinks := make(map[string]bool)
+		for _, entry := range idx.Entries() {
+			if paths.Match(entry.Path) && (len(tracked) == 0 || tracked[len(tracked)-1] != entry.Path) {
+				tracked = append(tracked, entry.Path)
+			}
+			if entry.Mode == objects.ModeSubmodule {
+				gitlinks[entry.Path] = true
+			}
+		}
+		// A pattern naming an existing directory matches even when nothing is inside it
+		if unmatched := paths.Unmatched(slices.Concat(files, dirs, tracked)); len(unmatched) > 0 {
+			return fmt.Errorf("pathspec '%s' did not match any files", unmatched[0])
+		}
+
+		for _, path := range tracked {
+			if _, found := slices.BinarySearch(files, path); found || outsideWalk(repoPath, path, gitlinks[path]) {
+				continue
+			}
+			idx.Remove(path)
+		}
+		for _, path := range files {
+			if _, found := slices.BinarySearch(tracked, path); !found && !untracked {
+				continue
+			}
+			if err := addWorktreeFile(repoPath, store, idx, path); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// worktreeFiles returns the sorted, slash-separated paths of regular files and symbolic links
+// in the worktree that paths selects, along with the directories visited. Metadata
+// directories and nested repositories are skipped.
+func worktreeFiles(layout repository.Layout, paths *pathspec.Pathspec) (files, dirs []string, err error) {
+	repoPath := layout.Root
+	err = filepath.WalkDir(repoPath, func(fullPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(repoPath, fullPath)
+		if err != nil {
+			return err
+		}
+		path := filepath.ToSlash(rel)
+
+		if entry.IsDir() {
+			if path == "." {
+				dirs = append(dirs, "")
+				return nil
+			}
+			if fullPath == layout.GitDir || entry.Name() == constants.Gogit || entry.Name() == constants.GitMetadataDir || isNestedRepository(fullPath) {
+				return filepath.SkipDir
+			}
+			dirs = append(dirs, path)
+			if !paths.MayMatchInside(path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if (entry.Type().IsRegular() || entry.Type()&fs.ModeSymlink != 0) && paths.Match(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to scan working tree: %w", err)
+	}
+	slices.Sort(files)
+	return files, dirs, nil
+}
+
+// outsideWalk reports whether the tracked path is one worktreeFiles never lists although it is
+// still there: a submodule whose directory exists, or a path inside a nested repository.
+func outsideWalk(repoPath, path string, gitlink bool) bool {
+	if gitlink {
+		info, err := os.Stat(filepath.Join(repoPath, filepath.FromSlash(path)))
+		if err == nil && info.IsDir() {
+			return true
+		}
+	}
+	for i := strings.LastIndex(path, "/"); i > 0; i = strings.LastIndex(path[:i], "/") {
+		if isNestedRepository(filepath.Join(repoPath, filepath.FromSlash(path[:i]))) {
+			return true
+		}
+	}
+	return false
+}
+
+// isNestedRepository reports whether dir is the worktree of another repository.
+func isNestedRepository(dir string) bool {
+	for _, name := range []string{constants.Gogit, constants.GitMetadataDir} {
+		if _, err := os.Lstat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// addWorktreeFile stages the worktree file at path, unless its index entry is up to date.
+func addWorktreeFile(repoPath string, store *objects.ObjectStore, idx *index.Index, path string) error {
+	if entry, ok := idx.Entry(path); ok && len(idx.Stages(path)) == 1 {
+		if _, changed, err := idx.StatWorktree(repoPath, entry); err != nil || !changed {
+			return err
+		}
+	}
+	if conflict, ok := idx.FileDirectoryConflict(path); ok {
+		return fmt.Errorf("'%s' appears as both a file and as a directory, conflicting with '%s'", path, conflict)
+	}
+
+	info, err := os.Lstat(filepath.Join(repoPath, filepath.FromSlash(path)))
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	hash, err := storeWorktreeFile(store, repoPath, path, info)
+	if err != nil {
+		return err
+	}
+	entry, err := index.NewEntry(path, hash, info)
+	if err != nil {
+		return err
+	}
+
+	idx.Remove(path)
+	idx.Add(*entry)
+	return nil
+}
+
+// Kinds of changes add -p offers.
+const (
+	choiceHunk = iota
+	choiceMode
+	choiceDeletion
+)
+
+// hunkChoice is one change of a file offered for staging: a hunk, its mode change or its deletion.
+type hunkChoice struct {
+	kind   int
+	hunk   patch.Hunk // Lines removed by a deletion, or the hunk itself
+	staged bool
+}
+
+// patchFile is a file whose worktree changes are offered for staging.
+type patchFile struct {
+	path       string
+	oldMode    objects.FileMode
+	newMode    objects.FileMode
+	oldContent []byte
+	header     string // Diff header shown before the first choice
+	choices    []hunkChoice
+}
+
+// hunkPrompter asks which changes of each file to stage.
+type hunkPrompter struct {
+	gitDir string
+	in     *bufio.Reader
+	out    io.Writer
+	errOut io.Writer
+}
+
+// addPatch offers the worktree changes of tracked files selected by paths hunk by hunk, then
+// patches the staged content with the chosen hunks.
+func addPatch(cmd *cobra.Command, layout repository.Layout, paths *pathspec.Pathspec) error {
+	idx, err := index.Read(layout.GitDir)
+	if err != nil {
+		return err
+	}
+	changes, err := diff.IndexWorktree(layout.Root, idx, paths)
+	if err != nil {
+		return err
+	}
+
+	store := newObjectStore(layout.GitDir)
+	var files []*patchFile
+	for _, change := range changes {
+		file, err := loadPatchFile(layout.Root, store, change)
+		if err != nil {
+			return err
+		}
+		if file != nil {
+			files = append(files, file)
+		}
+	}
+	if len(files) == 0 {
+		fmt.Fprintln(cmd.ErrOrStderr(), "No changes.")
+		return nil
+	}
+
+	prompter := &hunkPrompter{
+		gitDir: layout.GitDir,
+		in:     bufio.NewReader(cmd.InOrStdin()),
+		out:    cmd.OutOrStdout(),
+		errOut: cmd.ErrOrStderr(),
+	}
+	target := &patchTarget{
+		repoPath:   layout.Root,
+		store:      store,
+		idx:        idx,
+		minContext: -1,
+		files:      make(map[string]*patchedFile),
+	}
+	for _, file := range files {
+		quit, err := prompter.choose(file)
+		if err != nil {
+			return err
+		}
+		if fp := file.stagedPatch(); fp != nil {
+			if err := target.apply(fp); err != nil {
+				return err
+			}
+		}
+		if quit {
+			break
+		}
+	}
+
+	if len(target.order) == 0 {
+		return nil
+	}
+	return index.Update(layout.GitDir, target.writeIndex)
+}
+
+// loadPatchFile returns the changes of a modified or deleted regular file as choices. It returns
+// nil for what add -p does not offer: unmerged paths, type changes, symbolic links, submodules,
+// binary files, and files whose content and mode turn out unchanged.
+func loadPatchFile(repoPath string, store *objects.ObjectStore, change diff.Change) (*patchFile, error) {
+	deleted := change.Status == diff.StatusDeleted
+	if change.Status != diff.StatusModified && !deleted {
+		return nil, nil
+	}
+	if !isRegularMode(change.Old.Mode) || (!deleted && !isRegularMode(change.New.Mode)) {
+		return nil, nil
+	}
+
+	blob, err := store.ReadBlob(change.Old.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from index: %w", change.Path, err)
+	}
+	file := &patchFile{path: change.Path, oldMode: change.Old.Mode, newMode: change.New.Mode, oldContent: blob.Content()}
+
+	var newContent []byte
+	if !deleted {
+		if newContent, err = os.ReadFile(filepath.Join(repoPath, filepath.FromSlash(change.Path))); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", change.Path, err)
+		}
+	}
+	if diff.IsBinary(file.oldContent) || diff.IsBinary(newContent) {
+		return nil, nil
+	}
+	hunks := patch.Diff(file.oldContent, newContent, addPatchContext)
+
+	oldHash := change.Old.Hash[:addAbbrevLength]
+	newHash := objects.NewBlob(newContent).Hash()[:addAbbrevLength]
+	var header strings.Builder
+	fmt.Fprintf(&header, "diff --git a/%s b/%s\n", change.Path, change.Path)
+	switch {
+	case deleted:
+		fmt.Fprintf(&header, "deleted file mode %s\nindex %s..%s\n--- a/%s\n+++ /dev/null\n",
+			change.Old.Mode, oldHash, strings.Repeat("0", addAbbrevLength), change.Path)
+		var removal patch.Hunk
+		if len(hunks) > 0 {
+			removal = hunks[0]
+		}
+		file.choices = append(file.choices, hunkChoice{kind: choiceDeletion, hunk: removal})
+	case len(hunks) > 0:
+		fmt.Fprintf(&header, "index %s..%s", oldHash, newHash)
+		if change.Old.Mode == change.New.Mode {
+			fmt.Fprintf(&header, " %s", change.Old.Mode)
+		}
+		fmt.Fprintf(&header, "\n--- a/%s\n+++ b/%s\n", change.Path, change.Path)
+	}
+	file.header = header.String()
+
+	if !deleted {
+		if change.Old.Mode != change.New.Mode {
+			file.choices = append(file.choices, hunkChoice{kind: choiceMode})
+		}
+		for _, hunk := range hunks {
+			file.choices = append(file.choices, hunkChoice{kind: choiceHunk, hunk: hunk})
+		}
+	}
+	if len(file.choices) == 0 {
+		return nil, nil
+	}
+	return file, nil
+}
+
+// isRegularMode reports whether mode is a regular file, executable or not.
+func isRegularMode(mode objects.FileMode) bool {
+	return mode == objects.ModeRegularFile || mode == objects.ModeExecutable
+}
+
+// choose offers the choices of file one at a time and records the answers. It reports whether
+// the user quit, which leaves the remaining files alone; end of input quits as well.
+func (p *hunkPrompter) choose(file *patchFile) (bool, error) {
+	fmt.Fprint(p.out, file.header)
+	// The last answer is followed by a blank line, ending the file's prompts
+	defer fmt.Fprintln(p.out)
+	for i := 0; i < len(file.choices); {
+		choice := &file.choices[i]
+		fmt.Fprint(p.out, file.describe(choice))
+		options := choiceOptions(choice)
+		fmt.Fprintf(p.out, "(%d/%d) %s [%s]? ", i+1, len(file.choices), choiceQuestion(choice), strings.Join(options, ","))
+
+		answer, ok := p.readLine()
+		if !ok {
+			return true, nil
+		}
+		if answer == "" {
+			continue
+		}
+
+		switch answer[0] {
+		case 'y':
+			choice.staged = true
+			i++
+		case 'n':
+			i++
+		case 'q':
+			return true, nil
+		case 'a':
+			for ; i < len(file.choices); i++ {
+				file.choices[i].staged = true
+			}
+		case 'd':
+			i = len(file.choices)
+		case 's':
+			if !slices.Contains(options, "s") {
+				fmt.Fprintln(p.out, "Sorry, cannot split this hunk")
+				continue
+			}
+			var parts []hunkChoice
+			for _, hunk := range patch.Split(choice.hunk) {
+				parts = append(parts, hunkChoice{kind: choiceHunk, hunk: hunk})
+			}
+			fmt.Fprintf(p.out, "Split into %d hunks.\n", len(parts))
+			file.choices = slices.Replace(file.choices, i, i+1, parts...)
+		case 'e':
+			if choice.kind != choiceHunk {
+				fmt.Fprintln(p.out, "Sorry, cannot edit this hunk")
+				continue
+			}
+			edited, ok, err := p.edit(file, choice.hunk)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				choice.hunk = edited
+				choice.staged = true
+				i++
+			}
+		default:
+			for _, option := range options {
+				fmt.Fprintf(p.out, "%s - %s\n", option, choiceHelp[option])
+			}
+		}
+	}
+	return false, nil
+}
+
+// choiceHelp explains each answer to the add -p prompt.
+var choiceHelp = map[string]string{
+	"y": "stage this hunk",
+	"n": "do not stage this hunk",
+	"q": "quit; do not stage this hunk or any of the remaining ones",
+	"a": "stage this hunk and all later hunks in the file",
+	"d": "do not stage this hunk or any of the later hunks in the file",
+	"s": "split the current hunk into smaller hunks",
+	"e": "manually edit the current hunk",
+	"?": "print help",
+}
+
+// choiceOptions returns the answers the prompt for choice accepts.
+func choiceOptions(choice *hunkChoice) []string {
+	options := []string{"y", "n", "q", "a", "d"}
+	if choice.kind == choiceHunk {
+		if len(patch.Split(choice.hunk)) > 1 {
+			options = append(options, "s")
+		}
+		options = append(options, "e")
+	}
+	return append(options, "?")
+}
+
+// choiceQuestion returns the prompt asking whether to stage choice.
+func choiceQuestion(choice *hunkChoice) string {
+	switch choice.kind {
+	case choiceMode:
+		return "Stage mode change"
+	case choiceDeletion:
+		return "Stage deletion"
+	default:
+		return "Stage this hunk"
+	}
+}
+
+// describe returns what is shown of choice before asking about it.
+func (f *patchFile) describe(choice *hunkChoice) string {
+	if choice.kind == choiceMode {
+		return fmt.Sprintf("old mode %s\nnew mode %s\n", f.oldMode, f.newMode)
+	}
+	if len(choice.hunk.Lines) == 0 {
+		return ""
+	}
+	return choice.hunk.String()
+}
+
+// readLine reads one answer, reporting false at the end of input.
+func (p *hunkPrompter) readLine() (string, bool) {
+	line, err := p.in.ReadString('\n')
+	if err != nil && line == "" {
+		return "", false
+	}
+	return strings.TrimSpace(line), true
+}
+
+// editInstructions follow a hunk opened for editing, as in Git.
+const editInstructions = `# ---
+# To remove '-' lines, make them ' ' lines (context).
+# To remove '+' lines, delete them.
+# Lines starting with # will be removed.
+# If the patch applies cleanly, the edited hunk will immediately be marked for staging.
+# If it does not apply cleanly, you will be given an opportunity to
+# edit again.  If all lines of the hunk are removed, then the edit is
+# aborted and the hunk is left unchanged.
+`
+
+// edit opens hunk in the editor and returns the edited hunk, or false when the edit was
+// abandoned. An edited hunk that does not apply to the staged content may be edited again.
+func (p *hunkPrompter) edit(file *patchFile, hunk patch.Hunk) (patch.Hunk, bool, error) {
+	editPath := filepath.Join(p.gitDir, constants.AddEditFile)
+	text := hunk.String()
+	for {
+		content := "# Manual hunk edit mode -- see bottom for a quick guide.\n" + text + editInstructions
+		if err := os.WriteFile(editPath, []byte(content), constants.FilePerms); err != nil {
+			return patch.Hunk{}, false, fmt.Errorf("failed to write %s: %w", editPath, err)
+		}
+		if err := launchEditor(p.gitDir, editPath, p.out, p.errOut); err != nil {
+			os.Remove(editPath)
+			return patch.Hunk{}, false, err
+		}
+		edited, err := os.ReadFile(editPath)
+		os.Remove(editPath)
+		if err != nil {
+			return patch.Hunk{}, false, fmt.Errorf("failed to read %s: %w", editPath, err)
+		}
+
+		text = removeComments(string(edited))
+		if strings.TrimSpace(text) == "" {
+			return patch.Hunk{}, false, nil
+		}
+		result, err := parseEditedHunk(text, hunk)
+		if err == nil {
+			_, err = patch.ApplyHunks(file.oldContent, []patch.Hunk{result}, -1)
+		}
+		if err == nil {
+			return result, true, nil
+		}
+
+		fmt.Fprint(p.out, `Your edited hunk does not apply. Edit again (saying "no" discards!) [y/n]? `)
+		answer, ok := p.readLine()
+		if !ok || !strings.HasPrefix(answer, "y") {
+			return patch.Hunk{}, false, nil
+		}
+	}
+}
+
+// removeComments drops the lines of text starting with "#".
+func removeComments(text string) string {
+	var kept strings.Builder
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if !strings.HasPrefix(line, "#") {
+			kept.WriteString(line)
+		}
+	}
+	return kept.String()
+}
+
+// parseEditedHunk reads a hunk edited by hand. Its header is optional and its line counts are
+// recomputed, since editing rarely keeps them right; it starts where original did.
+func parseEditedHunk(text string, original patch.Hunk) (patch.Hunk, error) {
+	hunk := patch.Hunk{OldStart: original.OldStart, NewStart: original.NewStart}
+	lines := strings.SplitAfter(text, "\n")
+	if strings.HasPrefix(lines[0], "@@") {
+		lines = lines[1:]
+	}
+
+	for _, line := range lines {
+		switch {
+		case line == "":
+		case line == "\n":
+			// Editors often strip the space of blank context lines
+			hunk.Lines = append(hunk.Lines, patch.Line{Op: ' ', Text: line})
+		case line[0] == '\\':
+			if len(hunk.Lines) == 0 {
+				return patch.Hunk{}, errors.New("no newline marker before any hunk line")
+			}
+			last := &hunk.Lines[len(hunk.Lines)-1]
+			last.Text = strings.TrimSuffix(last.Text, "\n")
+		case line[0] == ' ' || line[0] == '-' || line[0] == '+':
+			hunk.Lines = append(hunk.Lines, patch.Line{Op: line[0], Text: line[1:]})
+		default:
+			return patch.Hunk{}, fmt.Errorf("malformed hunk line %q", strings.TrimRight(line, "\n"))
+		}
+	}
+	hunk.Recount()
+	return hunk, nil
+}
+
+// stagedPatch returns a patch staging the chosen changes of the file, or nil when none were
+// chosen. Skipped hunks move where later ones land, so their new starts are recomputed.
+func (f *patchFile) stagedPatch() *patch.FilePatch {
+	fp := &patch.FilePatch{OldPath: f.path, NewPath: f.path}
+	chosen := false
+	offset := 0
+	for _, choice := range f.choices {
+		if !choice.staged {
+			continue
+		}
+		chosen = true
+
+		switch choice.kind {
+		case choiceMode:
+			fp.NewMode = f.newMode
+		case choiceDeletion:
+			fp.NewPath = ""
+			if len(choice.hunk.Lines) > 0 {
+				fp.Hunks = append(fp.Hunks, choice.hunk)
+			}
+		default:
+			hunk := choice.hunk
+			hunk.NewStart = hunk.OldStart + offset
+			if hunk.OldLines == 0 {
+				hunk.NewStart++
+			}
+			if hunk.NewLines == 0 {
+				hunk.NewStart--
+			}
+			offset += hunk.NewLines - hunk.OldLines
+			fp.Hunks = append(fp.Hunks, hunk)
+		}
+	}
+	if !chosen {
+		return nil
+	}
+	return fp
+}
@@ -0,0 +1,307 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/index"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// stageStoredFiles stages files like stageWorktreeFiles, with their blobs in the object store.
+func stageStoredFiles(t *testing.T, repoPath string, files map[string]string) {
+	t.Helper()
+
+	store := objects.NewObjectStore(repository.GitDir(repoPath))
+	for _, content := range files {
+		if err := store.Store(objects.NewBlob([]byte(content))); err != nil {
+			t.Fatalf("Failed to store blob: %v", err)
+		}
+	}
+	stageWorktreeFiles(t, repoPath, files)
+}
+
+// stagedContent returns the content staged for path.
+func stagedContent(t *testing.T, repoPath, path string) string {
+	t.Helper()
+
+	blob, err := objects.NewObjectStore(repository.GitDir(repoPath)).ReadBlob(readIndexEntry(t, repoPath, path).Hash)
+	if err != nil {
+		t.Fatalf("Failed to read staged %s: %v", path, err)
+	}
+	return string(blob.Content())
+}
+
+// numberedLines returns lines "1\n" through "<count>\n", with the given lines replaced.
+func numberedLines(count int, replacements map[int]string) string {
+	var builder strings.Builder
+	for i := 1; i <= count; i++ {
+		if text, ok := replacements[i]; ok {
+			builder.WriteString(text + "\n")
+		} else {
+			builder.WriteString(strconv.Itoa(i) + "\n")
+		}
+	}
+	return builder.String()
+}
+
+// TestAddCommand_Files verifies new, modified and deleted files are staged by pathspec.
+func TestAddCommand_Files(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	stageWorktreeFiles(t, repoPath, map[string]string{"changed.txt": "old\n", "deleted.txt": "d\n", "kept.go": "k\n"})
+	testutils.CreateTestFile(t, repoPath, "changed.txt", []byte("new content\n"))
+	if err := os.Remove(filepath.Join(repoPath, "deleted.txt")); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(repoPath, "dir"), 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	testutils.CreateTestFile(t, repoPath, "dir/new.txt", []byte("n\n"))
+	testutils.CreateTestFile(t, repoPath, "dir/skipped.go", []byte("s\n"))
+
+	if _, _, err := runCmd(t, constants.AddCmdName, "*.txt"); err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+
+	idx, err := index.Read(repository.GitDir(repoPath))
+	if err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+	var paths []string
+	for _, entry := range idx.Entries() {
+		paths = append(paths, entry.Path)
+	}
+	if strings.Join(paths, " ") != "changed.txt dir/new.txt kept.go" {
+		t.Errorf("Expected changed.txt, dir/new.txt and kept.go in the index, got %v", paths)
+	}
+	if content := stagedContent(t, repoPath, "changed.txt"); content != "new content\n" {
+		t.Errorf("Expected the new content to be staged, got %q", content)
+	}
+
+	_, _, err = runCmd(t, constants.AddCmdName, "missing")
+	if err == nil || !strings.Contains(err.Error(), "pathspec 'missing' did not match any files") {
+		t.Errorf("Expected unmatched pathspec error, got %v", err)
+	}
+
+	_, stderr, err := runCmd(t, constants.AddCmdName)
+	if err != nil || !strings.HasPrefix(stderr, "Nothing specified, nothing added.") {
+		t.Errorf("Expected nothing to be added, got %q and %v", stderr, err)
+	}
+}
+
+// TestAddCommand_UpdateAndAll verifies -u stages only tracked files and -A everything, both
+// across the whole worktree when run from a subdirectory without a pathspec.
+func TestAddCommand_UpdateAndAll(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	stageWorktreeFiles(t, repoPath, map[string]string{"changed.txt": "old\n", "deleted.txt": "d\n"})
+	testutils.CreateTestFile(t, repoPath, "changed.txt", []byte("new content\n"))
+	if err := os.Remove(filepath.Join(repoPath, "deleted.txt")); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(repoPath, "sub"), 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	testutils.CreateTestFile(t, repoPath, "untracked.txt", []byte("u\n"))
+	changeToRepoDir(t, filepath.Join(repoPath, "sub"))
+
+	indexPaths := func() string {
+		idx, err := index.Read(repository.GitDir(repoPath))
+		if err != nil {
+			t.Fatalf("Failed to read index: %v", err)
+		}
+		var paths []string
+		for _, entry := range idx.Entries() {
+			paths = append(paths, entry.Path)
+		}
+		return strings.Join(paths, " ")
+	}
+
+	if _, _, err := runCmd(t, constants.AddCmdName, "-u"); err != nil {
+		t.Fatalf("add -u failed: %v", err)
+	}
+	if paths := indexPaths(); paths != "changed.txt" {
+		t.Errorf("Expected only changed.txt after add -u, got %s", paths)
+	}
+	if content := stagedContent(t, repoPath, "changed.txt"); content != "new content\n" {
+		t.Errorf("Expected the new content to be staged, got %q", content)
+	}
+
+	if _, _, err := runCmd(t, constants.AddCmdName, "-A"); err != nil {
+		t.Fatalf("add -A failed: %v", err)
+	}
+	if paths := indexPaths(); paths != "changed.txt untracked.txt" {
+		t.Errorf("Expected untracked.txt to be added by add -A, got %s", paths)
+	}
+
+	_, _, err := runCmd(t, constants.AddCmdName, "-u", "-A")
+	if err == nil || !strings.Contains(err.Error(), "options '-A' and '-u' cannot be used together") {
+		t.Errorf("Expected incompatible options error, got %v", err)
+	}
+}
+
+// TestAddCommand_KeepsNestedRepositories verifies add . and add -u leave submodule entries and
+// files tracked inside nested repositories in the index, as the worktree scan skips them.
+func TestAddCommand_KeepsNestedRepositories(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	gitDir := repository.GitDir(repoPath)
+	changeToRepoDir(t, repoPath)
+	for _, dir := range []string{"sub/" + constants.GitMetadataDir, "nested/" + constants.GitMetadataDir} {
+		if err := os.MkdirAll(filepath.Join(repoPath, dir), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+	}
+	stageWorktreeFiles(t, repoPath, map[string]string{"kept.txt": "k\n", "nested/file.txt": "n\n"})
+	err := index.Update(gitDir, func(idx *index.Index) error {
+		idx.Add(index.Entry{Mode: objects.ModeSubmodule, Hash: testutils.RandomHash(), Path: "sub"})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to stage submodule: %v", err)
+	}
+
+	indexPaths := func() string {
+		idx, err := index.Read(gitDir)
+		if err != nil {
+			t.Fatalf("Failed to read index: %v", err)
+		}
+		var paths []string
+		for _, entry := range idx.Entries() {
+			paths = append(paths, entry.Path)
+		}
+		return strings.Join(paths, " ")
+	}
+
+	for _, args := range [][]string{{"."}, {"-u"}} {
+		if _, _, err := runCmd(t, constants.AddCmdName, args...); err != nil {
+			t.Fatalf("add %v failed: %v", args, err)
+		}
+		if paths := indexPaths(); paths != "kept.txt nested/file.txt sub" {
+			t.Errorf("Expected add %v to keep every entry, got %s", args, paths)
+		}
+	}
+
+	// A submodule whose directory is gone is removed like any other path
+	if err := os.RemoveAll(filepath.Join(repoPath, "sub")); err != nil {
+		t.Fatalf("Failed to remove submodule: %v", err)
+	}
+	if _, _, err := runCmd(t, constants.AddCmdName, "-u"); err != nil {
+		t.Fatalf("add -u failed: %v", err)
+	}
+	if paths := indexPaths(); paths != "kept.txt nested/file.txt" {
+		t.Errorf("Expected the removed submodule to be unstaged, got %s", paths)
+	}
+}
+
+// TestAddCommand_Patch verifies hunks are split and only the chosen ones staged.
+func TestAddCommand_Patch(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	stageStoredFiles(t, repoPath, map[string]string{"file.txt": numberedLines(20, nil)})
+	testutils.CreateTestFile(t, repoPath, "file.txt", []byte(numberedLines(20, map[int]string{2: "two", 6: "six", 15: "fifteen"})))
+
+	stdout, _, err := runCmdWithInput(t, strings.NewReader("s\ny\nn\ny\n"), constants.AddCmdName, "-p")
+	if err != nil {
+		t.Fatalf("add -p failed: %v", err)
+	}
+	for _, expected := range []string{"(1/2) Stage this hunk [y,n,q,a,d,s,e,?]? ", "Split into 2 hunks.", "(3/3) Stage this hunk [y,n,q,a,d,e,?]? "} {
+		if !strings.Contains(stdout, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, stdout)
+		}
+	}
+
+	expected := numberedLines(20, map[int]string{2: "two", 15: "fifteen"})
+	if content := stagedContent(t, repoPath, "file.txt"); content != expected {
+		t.Errorf("Expected staged content %q, got %q", expected, content)
+	}
+	assertFileContent(t, filepath.Join(repoPath, "file.txt"), numberedLines(20, map[int]string{2: "two", 6: "six", 15: "fifteen"}))
+}
+
+// TestAddCommand_PatchModeAndDeletion verifies mode changes and deletions are offered on their own,
+// and that quitting leaves later files alone.
+func TestAddCommand_PatchModeAndDeletion(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	stageStoredFiles(t, repoPath, map[string]string{"a.sh": "run\n", "b.txt": "bye\n", "c.txt": "c\n"})
+	if err := os.Chmod(filepath.Join(repoPath, "a.sh"), 0o755); err != nil {
+		t.Fatalf("Failed to chmod: %v", err)
+	}
+	for _, name := range []string{"b.txt", "c.txt"} {
+		if err := os.Remove(filepath.Join(repoPath, name)); err != nil {
+			t.Fatalf("Failed to remove file: %v", err)
+		}
+	}
+
+	stdout, _, err := runCmdWithInput(t, strings.NewReader("y\ny\nq\n"), constants.AddCmdName, "-p")
+	if err != nil {
+		t.Fatalf("add -p failed: %v", err)
+	}
+	for _, expected := range []string{"old mode 100644\nnew mode 100755\n(1/1) Stage mode change [y,n,q,a,d,?]? ", "deleted file mode 100644\n", "(1/1) Stage deletion [y,n,q,a,d,?]? "} {
+		if !strings.Contains(stdout, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, stdout)
+		}
+	}
+
+	if entry := readIndexEntry(t, repoPath, "a.sh"); entry.Mode != objects.ModeExecutable {
+		t.Errorf("Expected mode change to be staged, got %s", entry.Mode)
+	}
+	idx, err := index.Read(repository.GitDir(repoPath))
+	if err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+	if _, found := idx.Entry("b.txt"); found {
+		t.Error("Expected deletion of b.txt to be staged")
+	}
+	if _, found := idx.Entry("c.txt"); !found {
+		t.Error("Expected c.txt to stay staged after quitting")
+	}
+}
+
+// TestAddCommand_PatchEdit verifies an edited hunk is staged, and an edit that does not apply is refused.
+func TestAddCommand_PatchEdit(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	stageStoredFiles(t, repoPath, map[string]string{"file.txt": "one\ntwo\nthree\n"})
+	testutils.CreateTestFile(t, repoPath, "file.txt", []byte("one\n2\nthree\n"))
+
+	t.Setenv(constants.EditorEnv, "sed -i s/^+2/+TWO/")
+	if _, _, err := runCmdWithInput(t, strings.NewReader("e\n"), constants.AddCmdName, "-p"); err != nil {
+		t.Fatalf("add -p failed: %v", err)
+	}
+	if content := stagedContent(t, repoPath, "file.txt"); content != "one\nTWO\nthree\n" {
+		t.Errorf("Expected edited hunk to be staged, got %q", content)
+	}
+
+	t.Setenv(constants.EditorEnv, "sed -i s/^.one/-missing/")
+	stdout, _, err := runCmdWithInput(t, strings.NewReader("e\nn\nn\n"), constants.AddCmdName, "-p")
+	if err != nil {
+		t.Fatalf("add -p failed: %v", err)
+	}
+	if !strings.Contains(stdout, "Your edited hunk does not apply.") {
+		t.Errorf("Expected edit to be refused, got:\n%s", stdout)
+	}
+	if content := stagedContent(t, repoPath, "file.txt"); content != "one\nTWO\nthree\n" {
+		t.Errorf("Expected staged content to be unchanged, got %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(repoPath, constants.Gogit, constants.AddEditFile)); !os.IsNotExist(err) {
+		t.Errorf("Expected the edit file to be removed, got %v", err)
+	}
+}
+
+// TestAddCommand_PatchNoChanges verifies a clean worktree is reported.
+func TestAddCommand_PatchNoChanges(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	stageStoredFiles(t, repoPath, map[string]string{"file.txt": "same\n"})
+
+	_, stderr, err := runCmd(t, constants.AddCmdName, "-p")
+	if err != nil || !strings.HasPrefix(stderr, "No changes.") {
+		t.Errorf("Expected no changes, got %q and %v", stderr, err)
+	}
+}
@@ -0,0 +1,382 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/index"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/patch"
+	"github.com/spf13/cobra"
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply [--check] [-R] [-p<n>] [-C<n>] [--cached] [<patch>...]",
+	Short: "Apply a unified diff to files in the working tree or index",
+	Long: `Read unified diffs, as written by git diff or diff -u, from the given
+files or standard input, and apply them to the working tree. With --cached
+the index is patched instead and the working tree is left alone.
+
+Paths in the patch are relative to the repository root, after removing
+<n> leading components (1 by default, dropping the a/ and b/ prefixes).
+Hunks that moved are found at other lines. Every file is patched in memory
+first, so nothing is written unless the whole patch applies.
+
+By default all context lines of a hunk must match. -C<n> allows context to
+be dropped from the edges of a hunk until only <n> lines remain.
+
+Examples:
+  # Check whether a patch applies, without changing anything
+  gogit apply --check fix.patch
+
+  # Undo a patch that was applied to the index
+  gogit diff | gogit apply -R --cached`,
+	SilenceUsage: true,
+	RunE:         runApply,
+}
+
+var (
+	applyCheckFlag   bool
+	applyReverseFlag bool
+	applyCachedFlag  bool
+	applyStripFlag   int
+	applyContextFlag int
+)
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+
+	applyCmd.Flags().BoolVar(&applyCheckFlag, "check", false, "Only check that the patch applies")
+	applyCmd.Flags().BoolVarP(&applyReverseFlag, "reverse", "R", false, "Apply the patch in reverse")
+	applyCmd.Flags().BoolVar(&applyCachedFlag, "cached", false, "Apply the patch to the index without touching the working tree")
+	applyCmd.Flags().IntVarP(&applyStripFlag, "strip", "p", 1, "Remove <n> leading path components from file names")
+	applyCmd.Flags().IntVarP(&applyContextFlag, "context", "C", -1, "Require only <n> lines of context to match (all by default)")
+}
+
+// patchedFile is the state of a path while patches are applied in memory.
+type patchedFile struct {
+	exists  bool
+	content []byte
+	mode    objects.FileMode
+	changed bool
+}
+
+// patchTarget loads and writes the files a patch touches, in the working tree or the index.
+type patchTarget struct {
+	repoPath   string
+	store      *objects.ObjectStore
+	idx        *index.Index // Set with --cached
+	minContext int          // Context lines hunks may be reduced to, or -1 to require all of it
+	files      map[string]*patchedFile
+	order      []string // Paths in the order they were first touched
+}
+
+// runApply parses the patches and applies them all, or reports why they do not apply.
+func runApply(cmd *cobra.Command, args []string) error {
+	if applyStripFlag < 0 {
+		return fmt.Errorf("invalid strip level %d", applyStripFlag)
+	}
+
+	layout, err := findRepoRoot()
+	if err != nil {
+		return err
+	}
+
+	patches, err := readPatches(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	target := &patchTarget{
+		repoPath:   layout.Root,
+		store:      newObjectStore(layout.GitDir),
+		minContext: applyContextFlag,
+		files:      make(map[string]*patchedFile),
+	}
+	if applyCachedFlag {
+		if target.idx, err = index.Read(layout.GitDir); err != nil {
+			return err
+		}
+	}
+
+	for _, fp := range patches {
+		if err := target.apply(fp); err != nil {
+			return err
+		}
+	}
+	if applyCheckFlag {
+		return nil
+	}
+
+	if applyCachedFlag {
+		return index.Update(layout.GitDir, target.writeIndex)
+	}
+	return target.writeWorktree()
+}
+
+// readPatches parses patch files, or standard input without arguments or for "-".
+func readPatches(cmd *cobra.Command, args []string) ([]*patch.FilePatch, error) {
+	if len(args) == 0 {
+		args = []string{"-"}
+	}
+
+	var patches []*patch.FilePatch
+	for _, arg := range args {
+		var reader io.Reader = cmd.InOrStdin()
+		if arg != "-" {
+			file, err := os.Open(arg)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open patch: %w", err)
+			}
+			defer file.Close()
+			reader = file
+		}
+
+		parsed, err := patch.Parse(reader, applyStripFlag)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", arg, err)
+		}
+		patches = append(patches, parsed...)
+	}
+
+	if len(patches) == 0 {
+		return nil, errors.New("no valid patches in input")
+	}
+	for _, fp := range patches {
+		if applyReverseFlag {
+			if err := fp.Reverse(); err != nil {
+				return nil, err
+			}
+		}
+		for _, name := range []string{fp.OldPath, fp.NewPath} {
+			if name != "" && !safePatchPath(name) {
+				return nil, fmt.Errorf("invalid path '%s' in patch", name)
+			}
+		}
+	}
+	return patches, nil
+}
+
+// safePatchPath rejects absolute paths, paths leaving the repository and metadata directories.
+func safePatchPath(name string) bool {
+	if path.IsAbs(name) || path.Clean(name) != name {
+		return false
+	}
+	for _, component := range strings.Split(name, "/") {
+		if component == ".." || component == constants.Gogit || component == constants.GitMetadataDir {
+			return false
+		}
+	}
+	return true
+}
+
+// apply applies one file patch to the in-memory state of its files.
+func (t *patchTarget) apply(fp *patch.FilePatch) error {
+	name := fp.OldPath
+	if fp.IsCreation() {
+		name = fp.NewPath
+	}
+	if fp.Binary {
+		return fmt.Errorf("%s: binary patches are not supported", name)
+	}
+
+	source := &patchedFile{mode: objects.ModeRegularFile}
+	if !fp.IsCreation() {
+		var err error
+		if source, err = t.file(fp.OldPath); err != nil {
+			return err
+		}
+		if !source.exists {
+			return fmt.Errorf("%s: does not exist in %s", fp.OldPath, t.description())
+		}
+	}
+
+	if !fp.IsDeletion() && (fp.IsCreation() || fp.NewPath != fp.OldPath) {
+		existing, err := t.file(fp.NewPath)
+		if err != nil {
+			return err
+		}
+		if existing.exists {
+			return fmt.Errorf("%s: already exists in %s", fp.NewPath, t.description())
+		}
+	}
+
+	content, err := patch.ApplyHunks(source.content, fp.Hunks, t.minContext)
+	if err != nil {
+		return fmt.Errorf("patch failed: %s: %w", name, err)
+	}
+
+	if fp.IsDeletion() {
+		if len(content) > 0 {
+			return fmt.Errorf("%s: removal patch leaves file contents", name)
+		}
+		t.set(fp.OldPath, &patchedFile{})
+		return nil
+	}
+
+	mode := source.mode
+	if fp.NewMode != "" {
+		mode = fp.NewMode
+	}
+	if mode != objects.ModeRegularFile && mode != objects.ModeExecutable {
+		return fmt.Errorf("%s: only regular files can be patched, not mode %s", name, mode)
+	}
+
+	if !fp.IsCreation() && fp.NewPath != fp.OldPath && !fp.Copy {
+		t.set(fp.OldPath, &patchedFile{})
+	}
+	t.set(fp.NewPath, &patchedFile{exists: true, content: content, mode: mode})
+	return nil
+}
+
+// file returns the current state of name, loading it on first use.
+func (t *patchTarget) file(name string) (*patchedFile, error) {
+	if file, ok := t.files[name]; ok {
+		return file, nil
+	}
+
+	var file *patchedFile
+	var err error
+	if t.idx != nil {
+		file, err = t.loadIndexFile(name)
+	} else {
+		file, err = t.loadWorktreeFile(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.files[name] = file
+	t.order = append(t.order, name)
+	return file, nil
+}
+
+// set records the patched state of name.
+func (t *patchTarget) set(name string, file *patchedFile) {
+	if _, ok := t.files[name]; !ok {
+		t.order = append(t.order, name)
+	}
+	file.changed = true
+	t.files[name] = file
+}
+
+// loadIndexFile reads the staged content of name.
+func (t *patchTarget) loadIndexFile(name string) (*patchedFile, error) {
+	entry, ok := t.idx.Entry(name)
+	if !ok {
+		return &patchedFile{}, nil
+	}
+	if entry.Mode != objects.ModeRegularFile && entry.Mode != objects.ModeExecutable {
+		return nil, fmt.Errorf("%s: only regular files can be patched, not mode %s", name, entry.Mode)
+	}
+
+	blob, err := t.store.ReadBlob(entry.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from index: %w", name, err)
+	}
+	return &patchedFile{exists: true, content: blob.Content(), mode: entry.Mode}, nil
+}
+
+// loadWorktreeFile reads name from the working tree.
+func (t *patchTarget) loadWorktreeFile(name string) (*patchedFile, error) {
+	fullPath := filepath.Join(t.repoPath, filepath.FromSlash(name))
+	info, err := os.Lstat(fullPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &patchedFile{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", name, err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s: only regular files can be patched", name)
+	}
+
+	content, err := os.ReadFile(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	mode := objects.ModeRegularFile
+	if info.Mode()&0o111 != 0 {
+		mode = objects.ModeExecutable
+	}
+	return &patchedFile{exists: true, content: content, mode: mode}, nil
+}
+
+// writeWorktree writes patched files and removes deleted ones, with directories left empty.
+func (t *patchTarget) writeWorktree() error {
+	for _, name := range t.order {
+		file := t.files[name]
+		if !file.changed {
+			continue
+		}
+		fullPath := filepath.Join(t.repoPath, filepath.FromSlash(name))
+
+		if !file.exists {
+			if err := os.Remove(fullPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("failed to remove %s: %w", name, err)
+			}
+			removeEmptyParents(t.repoPath, filepath.Dir(fullPath))
+			continue
+		}
+
+		perm := fs.FileMode(0o644)
+		if file.mode == objects.ModeExecutable {
+			perm = 0o755
+		}
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", name, err)
+		}
+		if err := os.WriteFile(fullPath, file.content, perm); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		if err := os.Chmod(fullPath, perm); err != nil {
+			return fmt.Errorf("failed to set mode of %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// writeIndex stores patched content as blobs and updates or removes their index entries.
+func (t *patchTarget) writeIndex(idx *index.Index) error {
+	for _, name := range t.order {
+		file := t.files[name]
+		if !file.changed {
+			continue
+		}
+		if !file.exists {
+			idx.Remove(name)
+			continue
+		}
+
+		blob := objects.NewBlob(file.content)
+		if err := t.store.Store(blob); err != nil {
+			return fmt.Errorf("failed to store %s: %w", name, err)
+		}
+		idx.Add(index.Entry{Path: name, Hash: blob.Hash(), Mode: file.mode, Size: uint32(len(file.content))})
+	}
+	return nil
+}
+
+// removeEmptyParents removes dir and its parents up to repoPath while they are empty.
+func removeEmptyParents(repoPath, dir string) {
+	for dir != repoPath && strings.HasPrefix(dir, repoPath) {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// description names where patches are applied.
+func (t *patchTarget) description() string {
+	if t.idx != nil {
+		return "index"
+	}
+	return "working directory"
+}
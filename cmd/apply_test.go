@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/index"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+const applyTestPatch = `diff --git a/a.txt b/a.txt
+index 1111111..2222222 100644
+--- a/a.txt
++++ b/a.txt
+@@ -1,3 +1,3 @@
+ one
+-two
++TWO
+ three
+diff --git a/sub/new.txt b/sub/new.txt
+new file mode 100755
+--- /dev/null
++++ b/sub/new.txt
+@@ -0,0 +1 @@
++created
+diff --git a/gone.txt b/gone.txt
+deleted file mode 100644
+--- a/gone.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-bye
+`
+
+// setupApplyRepo creates a repository holding the files the test patch modifies and deletes.
+func setupApplyRepo(t *testing.T) string {
+	t.Helper()
+
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	testutils.CreateTestFile(t, repoPath, "a.txt", []byte("one\ntwo\nthree\n"))
+	testutils.CreateTestFile(t, repoPath, "gone.txt", []byte("bye\n"))
+	return repoPath
+}
+
+// assertFileContent fails unless the file at path holds expected.
+func assertFileContent(t *testing.T, path, expected string) {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	if string(content) != expected {
+		t.Errorf("Expected %s to contain %q, got %q", path, expected, content)
+	}
+}
+
+// TestApplyCommand_Worktree verifies modification, creation with mode and deletion, and reversal.
+func TestApplyCommand_Worktree(t *testing.T) {
+	repoPath := setupApplyRepo(t)
+
+	changeToRepoDir(t, repoPath)
+	if _, _, err := runCmdWithInput(t, strings.NewReader(applyTestPatch), constants.ApplyCmdName); err != nil {
+		t.Fatalf("%s failed: %v", constants.ApplyCmdName, err)
+	}
+
+	assertFileContent(t, filepath.Join(repoPath, "a.txt"), "one\nTWO\nthree\n")
+	assertFileContent(t, filepath.Join(repoPath, "sub", "new.txt"), "created\n")
+	testutils.AssertFileNotExists(t, filepath.Join(repoPath, "gone.txt"))
+	info, err := os.Stat(filepath.Join(repoPath, "sub", "new.txt"))
+	if err != nil {
+		t.Fatalf("Failed to stat created file: %v", err)
+	}
+	if info.Mode().Perm()&0o111 == 0 {
+		t.Errorf("Expected created file to be executable, got %v", info.Mode())
+	}
+
+	if _, _, err := runCmdWithInput(t, strings.NewReader(applyTestPatch), constants.ApplyCmdName, "-R"); err != nil {
+		t.Fatalf("%s -R failed: %v", constants.ApplyCmdName, err)
+	}
+
+	assertFileContent(t, filepath.Join(repoPath, "a.txt"), "one\ntwo\nthree\n")
+	assertFileContent(t, filepath.Join(repoPath, "gone.txt"), "bye\n")
+	testutils.AssertFileNotExists(t, filepath.Join(repoPath, "sub"))
+}
+
+// TestApplyCommand_CheckAndFailure verifies --check writes nothing and a failing hunk leaves every file untouched.
+func TestApplyCommand_CheckAndFailure(t *testing.T) {
+	repoPath := setupApplyRepo(t)
+
+	changeToRepoDir(t, repoPath)
+	if _, _, err := runCmdWithInput(t, strings.NewReader(applyTestPatch), constants.ApplyCmdName, "--check"); err != nil {
+		t.Fatalf("%s --check failed: %v", constants.ApplyCmdName, err)
+	}
+	testutils.AssertFileExists(t, filepath.Join(repoPath, "gone.txt"))
+	testutils.AssertFileNotExists(t, filepath.Join(repoPath, "sub"))
+
+	testutils.CreateTestFile(t, repoPath, "gone.txt", []byte("changed\n"))
+	_, _, err := runCmdWithInput(t, strings.NewReader(applyTestPatch), constants.ApplyCmdName)
+	if err == nil || !strings.Contains(err.Error(), "patch failed: gone.txt") {
+		t.Fatalf("Expected failure on gone.txt, got: %v", err)
+	}
+	assertFileContent(t, filepath.Join(repoPath, "a.txt"), "one\ntwo\nthree\n")
+	testutils.AssertFileNotExists(t, filepath.Join(repoPath, "sub"))
+}
+
+// TestApplyCommand_StripAndContext verifies -p and -C.
+func TestApplyCommand_StripAndContext(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	testutils.CreateTestFile(t, repoPath, "f.txt", []byte("CHANGED\n2\n3\n4\n5\n"))
+	input := "--- x/y/f.txt\n+++ x/y/f.txt\n@@ -1,5 +1,5 @@\n 1\n 2\n 3\n-4\n+four\n 5\n"
+
+	changeToRepoDir(t, repoPath)
+	if _, _, err := runCmdWithInput(t, strings.NewReader(input), constants.ApplyCmdName, "-p2"); err == nil {
+		t.Fatal("Expected mismatched context to fail without -C")
+	}
+	if _, _, err := runCmdWithInput(t, strings.NewReader(input), constants.ApplyCmdName, "-p2", "-C1"); err != nil {
+		t.Fatalf("%s -C1 failed: %v", constants.ApplyCmdName, err)
+	}
+	assertFileContent(t, filepath.Join(repoPath, "f.txt"), "CHANGED\n2\n3\nfour\n5\n")
+}
+
+// TestApplyCommand_Cached verifies --cached patches index entries and leaves the working tree alone.
+func TestApplyCommand_Cached(t *testing.T) {
+	repoPath := setupApplyRepo(t)
+	gitDir := repository.GitDir(repoPath)
+	store := objects.NewObjectStore(gitDir)
+	for _, name := range []string{"a.txt", "gone.txt"} {
+		content, err := os.ReadFile(filepath.Join(repoPath, name))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		if err := store.Store(objects.NewBlob(content)); err != nil {
+			t.Fatalf("Failed to store blob: %v", err)
+		}
+		stageTestFile(t, repoPath, name, content)
+	}
+
+	changeToRepoDir(t, repoPath)
+	if _, _, err := runCmdWithInput(t, strings.NewReader(applyTestPatch), constants.ApplyCmdName, "--cached"); err != nil {
+		t.Fatalf("%s --cached failed: %v", constants.ApplyCmdName, err)
+	}
+
+	idx, err := index.Read(gitDir)
+	if err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+	if entry, ok := idx.Entry("a.txt"); !ok || entry.Hash != objects.NewBlob([]byte("one\nTWO\nthree\n")).Hash() {
+		t.Errorf("Expected patched a.txt in index, got %+v", entry)
+	}
+	if entry, ok := idx.Entry("sub/new.txt"); !ok || entry.Mode != objects.ModeExecutable {
+		t.Errorf("Expected executable sub/new.txt in index, got %+v", entry)
+	}
+	if _, ok := idx.Entry("gone.txt"); ok {
+		t.Error("Expected gone.txt removed from index")
+	}
+
+	assertFileContent(t, filepath.Join(repoPath, "a.txt"), "one\ntwo\nthree\n")
+	testutils.AssertFileExists(t, filepath.Join(repoPath, "gone.txt"))
+	testutils.AssertFileNotExists(t, filepath.Join(repoPath, "sub"))
+}
+
+// TestApplyCommand_UnsafePath verifies patches cannot write outside the worktree or into metadata.
+func TestApplyCommand_UnsafePath(t *testing.T) {
+	changeToRepoDir(t, testutils.SetupTestRepoWithInit(t))
+
+	for _, name := range []string{"../escape", constants.Gogit + "/HEAD"} {
+		input := "--- /dev/null\n+++ b/" + name + "\n@@ -0,0 +1 @@\n+x\n"
+		if _, _, err := runCmdWithInput(t, strings.NewReader(input), constants.ApplyCmdName); err == nil || !strings.Contains(err.Error(), "invalid path") {
+			t.Errorf("Expected invalid path error for %s, got: %v", name, err)
+		}
+	}
+}
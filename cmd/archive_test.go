@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// storeArchiveCommit stores commit of a one-file tree and returns commit and its time.
+func storeArchiveCommit(t *testing.T, store *objects.ObjectStore) (*objects.Commit, time.Time) {
+	t.Helper()
+
+	blob := objects.NewBlob([]byte("archived\n"))
+	tree, err := objects.NewTree([]objects.TreeEntry{*mustTreeEntry(t, objects.ModeRegularFile, "file.txt", blob.Hash())})
+	if err != nil {
+		t.Fatalf("Failed to create tree: %v", err)
+	}
+	for _, obj := range []objects.Object{blob, tree} {
+		if err := store.Store(obj); err != nil {
+			t.Fatalf("Failed to store object: %v", err)
+		}
+	}
+
+	when := time.Unix(1700000000, 0).UTC()
+	commit, err := objects.NewInitialCommit(tree.Hash(), "snapshot", objects.Author{Name: "A", Email: "a@example.com", Timestamp: when})
+	if err != nil {
+		t.Fatalf("Failed to create commit: %v", err)
+	}
+	if err := store.Store(commit); err != nil {
+		t.Fatalf("Failed to store commit: %v", err)
+	}
+	return commit, when
+}
+
+// TestArchiveCommand_Tar verifies commit is exported as tar with prefix and committer time.
+func TestArchiveCommand_Tar(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	commit, when := storeArchiveCommit(t, objects.NewObjectStore(repository.GitDir(repoPath)))
+
+	output, _, err := runCmd(t, constants.ArchiveCmdName, "--prefix=project/", commit.Hash()[:8])
+	if err != nil {
+		t.Fatalf("%s command failed: %v", constants.ArchiveCmdName, err)
+	}
+
+	var names []string
+	reader := tar.NewReader(strings.NewReader(output))
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read tar: %v", err)
+		}
+		if header.Typeflag == tar.TypeXGlobalHeader {
+			continue
+		}
+		if !header.ModTime.Equal(when) {
+			t.Errorf("%s: expected mtime %s, got %s", header.Name, when, header.ModTime)
+		}
+		names = append(names, header.Name)
+	}
+
+	if strings.Join(names, ",") != "project/,project/file.txt" {
+		t.Errorf("Unexpected entries: %v", names)
+	}
+}
+
+// TestArchiveCommand_ZipOutput verifies zip format is inferred from the output file name, and
+// ref names are resolved.
+func TestArchiveCommand_ZipOutput(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	commit, _ := storeArchiveCommit(t, objects.NewObjectStore(repository.GitDir(repoPath)))
+	writeTestRef(t, repoPath, constants.BranchRefPrefix+constants.DefaultBranch, commit.Hash())
+	outputPath := filepath.Join(t.TempDir(), "snapshot.zip")
+
+	if _, _, err := runCmd(t, constants.ArchiveCmdName, "-o", outputPath, constants.Head); err != nil {
+		t.Fatalf("%s command failed: %v", constants.ArchiveCmdName, err)
+	}
+
+	reader, err := zip.OpenReader(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to open zip: %v", err)
+	}
+	defer reader.Close()
+
+	if len(reader.File) != 1 || reader.File[0].Name != "file.txt" || reader.Comment != commit.Hash() {
+		t.Errorf("Unexpected zip contents: %d files, comment %q", len(reader.File), reader.Comment)
+	}
+}
+
+// TestArchiveCommand_Errors verifies bad formats and non-tree objects are rejected.
+func TestArchiveCommand_Errors(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	store := objects.NewObjectStore(repository.GitDir(repoPath))
+	commit, _ := storeArchiveCommit(t, store)
+	blob := objects.NewBlob([]byte("archived\n"))
+
+	if _, _, err := runCmd(t, constants.ArchiveCmdName, "--format=rar", commit.Hash()); err == nil || !strings.Contains(err.Error(), "unknown archive format") {
+		t.Errorf("Expected unknown format error, got %v", err)
+	}
+	if _, _, err := runCmd(t, constants.ArchiveCmdName, blob.Hash()); err == nil || !strings.Contains(err.Error(), "not a tree-ish") {
+		t.Errorf("Expected tree-ish error, got %v", err)
+	}
+}
@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/utils"
+	"github.com/spf13/cobra"
+)
+
+var catFileCmd = &cobra.Command{
+	Use:   "cat-file (-t | -s | -p | <type>) <object> | --batch | --batch-check",
+	Short: "Provide content, type or size information for repository objects",
+	Long: `Show information about stored objects. Objects are named by ref, such as HEAD,
+main or v1.0, or by full or abbreviated hash.
+
+  -t        Print the object type
+  -s        Print the object size
+  -p        Pretty-print the object content (trees are listed like ls-tree)
+  <type>    Print raw content, failing unless the object has that type
+
+Batch modes read one object name per line from standard input and answer each in turn,
+so many objects can be queried through a single process:
+
+  --batch-check   Print "<hash> <type> <size>" per object
+  --batch         Same header followed by the raw content and a newline
+
+Unknown names are answered with "<name> missing" and ambiguous ones with "<name> ambiguous".
+
+Examples:
+  # Show a commit
+  gogit cat-file -p 1a2b3c4
+
+  # Query many objects at once
+  printf '%s\n' 1a2b3c4 5d6e7f8 | gogit cat-file --batch-check`,
+	SilenceUsage: true,
+	Args:         catFileArgs,
+	RunE:         runCatFile,
+}
+
+var (
+	showTypeFlag   bool
+	showSizeFlag   bool
+	prettyFlag     bool
+	batchFlag      bool
+	batchCheckFlag bool
+)
+
+func init() {
+	rootCmd.AddCommand(catFileCmd)
+
+	catFileCmd.Flags().BoolVarP(&showTypeFlag, "type", "t", false, "Show object type")
+	catFileCmd.Flags().BoolVarP(&showSizeFlag, "size", "s", false, "Show object size")
+	catFileCmd.Flags().BoolVarP(&prettyFlag, "pretty", "p", false, "Pretty-print object content")
+	catFileCmd.Flags().BoolVar(&batchFlag, "batch", false, "Print header and content of objects named on stdin")
+	catFileCmd.Flags().BoolVar(&batchCheckFlag, "batch-check", false, "Print header of objects named on stdin")
+}
+
+// catFileArgs validates that exactly one mode is selected with its matching arguments.
+// Enables usage printing in case of error.
+func catFileArgs(cmd *cobra.Command, args []string) error {
+	modes := 0
+	for _, set := range []bool{showTypeFlag, showSizeFlag, prettyFlag, batchFlag, batchCheckFlag} {
+		if set {
+			modes++
+		}
+	}
+
+	var err error
+	switch {
+	case modes > 1:
+		err = fmt.Errorf("%s accepts only one of -t, -s, -p, --batch and --batch-check", constants.CatFileCmdName)
+	case (batchFlag || batchCheckFlag) && len(args) > 0:
+		err = fmt.Errorf("%s batch modes read object names from stdin and accept no arguments", constants.CatFileCmdName)
+	case modes == 1 && !batchFlag && !batchCheckFlag && len(args) != 1:
+		err = fmt.Errorf("%s command requires exactly 1 argument (object), received %d", constants.CatFileCmdName, len(args))
+	case modes == 0 && len(args) != 2:
+		err = fmt.Errorf("%s command requires <type> <object> or one of -t, -s, -p, --batch, --batch-check", constants.CatFileCmdName)
+	}
+
+	if err != nil {
+		cmd.SilenceUsage = false
+	}
+	return err
+}
+
+// runCatFile dispatches to the selected cat-file mode.
+func runCatFile(cmd *cobra.Command, args []string) error {
+	layout, err := findRepoRoot()
+	if err != nil {
+		return err
+	}
+	store := newObjectStore(layout.GitDir)
+	out := cmd.OutOrStdout()
+
+	if batchFlag || batchCheckFlag {
+		return runCatFileBatch(layout.GitDir, store, cmd.InOrStdin(), out, batchFlag)
+	}
+
+	name := args[len(args)-1]
+	hash, err := resolveObjectName(layout.GitDir, store, name)
+	if err != nil {
+		return err
+	}
+
+	reader, err := store.OpenObject(hash)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	switch {
+	case showTypeFlag:
+		fmt.Fprintln(out, reader.Type())
+		return nil
+	case showSizeFlag:
+		fmt.Fprintln(out, reader.Size())
+		return nil
+	case prettyFlag:
+		return prettyPrintObject(store, reader, hash, out)
+	}
+
+	if expected := utils.ObjectType(args[0]); reader.Type() != expected {
+		if !expected.IsValid() {
+			return fmt.Errorf("invalid object type %q", args[0])
+		}
+		return fmt.Errorf("object %s is a %s, not a %s", name, reader.Type(), expected)
+	}
+
+	_, err = io.Copy(out, reader)
+	return err
+}
+
+// prettyPrintObject writes object content in human readable form.
+// Trees are listed as "<mode> <type> <hash>\t<name>"; other types are printed verbatim.
+func prettyPrintObject(store *objects.ObjectStore, reader *objects.ObjectReader, hash string, out io.Writer) error {
+	if reader.Type() != utils.TreeObjectType {
+		_, err := io.Copy(out, reader)
+		return err
+	}
+
+	tree, err := store.ReadTree(hash)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range tree.Entries() {
+		fmt.Fprintf(out, "%s %s %s\t%s\n", entry.Mode(), entryObjectType(entry.Mode()), entry.Hash(), entry.Name())
+	}
+	return nil
+}
+
+// runCatFileBatch answers object names read from input until EOF.
+// Output is flushed after every object so callers can interleave requests and responses.
+func runCatFileBatch(gitDir string, store *objects.ObjectStore, input io.Reader, output io.Writer, withContent bool) error {
+	out := bufio.NewWriter(output)
+	scanner := bufio.NewScanner(input)
+
+	for scanner.Scan() {
+		if err := writeBatchObject(gitDir, store, out, scanner.Text(), withContent); err != nil {
+			return err
+		}
+		if err := out.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read object names: %w", err)
+	}
+	return nil
+}
+
+// writeBatchObject writes the batch response for a single object name.
+func writeBatchObject(gitDir string, store *objects.ObjectStore, out io.Writer, name string, withContent bool) error {
+	hash, err := resolveObjectName(gitDir, store, name)
+	if err != nil {
+		if errors.Is(err, objects.ErrAmbiguousObject) {
+			fmt.Fprintf(out, "%s ambiguous\n", name)
+		} else {
+			fmt.Fprintf(out, "%s missing\n", name)
+		}
+		return nil
+	}
+
+	reader, err := store.OpenObject(hash)
+	if errors.Is(err, objects.ErrObjectNotFound) {
+		fmt.Fprintf(out, "%s missing\n", name)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	fmt.Fprintf(out, "%s %s %d\n", hash, reader.Type(), reader.Size())
+	if !withContent {
+		return nil
+	}
+
+	if _, err := io.Copy(out, reader); err != nil {
+		return err
+	}
+	_, err = io.WriteString(out, "\n")
+	return err
+}
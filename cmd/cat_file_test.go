@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/refs"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// setupCatFileRepo creates repository holding one blob and a tree containing it.
+func setupCatFileRepo(t *testing.T) (blob *objects.Blob, tree *objects.Tree) {
+	t.Helper()
+
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	store := objects.NewObjectStore(repository.GitDir(repoPath))
+
+	blob = objects.NewBlob([]byte("hello\n"))
+	if err := store.Store(blob); err != nil {
+		t.Fatalf("Failed to store blob: %v", err)
+	}
+
+	tree, err := objects.NewTree([]objects.TreeEntry{*mustTreeEntry(t, objects.ModeRegularFile, "a.txt", blob.Hash())})
+	if err != nil {
+		t.Fatalf("Failed to create tree: %v", err)
+	}
+	if err := store.Store(tree); err != nil {
+		t.Fatalf("Failed to store tree: %v", err)
+	}
+
+	return blob, tree
+}
+
+// TestCatFileCommand verifies single-object modes print type, size and content.
+func TestCatFileCommand(t *testing.T) {
+	blob, tree := setupCatFileRepo(t)
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"type", []string{"-t", blob.Hash()}, "blob\n"},
+		{"size", []string{"-s", blob.Hash()}, "6\n"},
+		{"pretty blob", []string{"-p", blob.Hash()[:7]}, "hello\n"},
+		{"pretty tree", []string{"-p", tree.Hash()}, fmt.Sprintf("100644 blob %s\ta.txt\n", blob.Hash())},
+		{"typed", []string{"blob", blob.Hash()}, "hello\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, _, err := runCmd(t, constants.CatFileCmdName, tt.args...)
+			if err != nil {
+				t.Fatalf("%s command failed: %v", constants.CatFileCmdName, err)
+			}
+			if output != tt.expected {
+				t.Errorf("Expected output %q, got %q", tt.expected, output)
+			}
+		})
+	}
+}
+
+// TestCatFileCommand_Errors verifies invalid argument combinations and lookups fail.
+func TestCatFileCommand_Errors(t *testing.T) {
+	blob, _ := setupCatFileRepo(t)
+
+	tests := []struct {
+		name          string
+		args          []string
+		expectedError string
+	}{
+		{"no mode", []string{blob.Hash()}, "requires <type> <object>"},
+		{"two modes", []string{"-t", "-s", blob.Hash()}, "only one of"},
+		{"batch with args", []string{"--batch", blob.Hash()}, "accept no arguments"},
+		{"type mismatch", []string{"tree", blob.Hash()}, "is a blob, not a tree"},
+		{"unknown object", []string{"-t", testutils.RandomHash()}, "object not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := runCmd(t, constants.CatFileCmdName, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("Expected error containing [%s], got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+// TestCatFileCommand_Batch verifies batch modes answer every stdin line in order.
+func TestCatFileCommand_Batch(t *testing.T) {
+	blob, tree := setupCatFileRepo(t)
+	missing := testutils.RandomHash()
+	input := strings.Join([]string{blob.Hash()[:6], missing, tree.Hash(), "not-a-hash"}, "\n") + "\n"
+
+	output, _, err := runCmdWithInput(t, strings.NewReader(input), constants.CatFileCmdName, "--batch-check")
+	if err != nil {
+		t.Fatalf("--batch-check failed: %v", err)
+	}
+	expectedCheck := fmt.Sprintf("%s blob 6\n%s missing\n%s tree %d\nnot-a-hash missing\n",
+		blob.Hash(), missing, tree.Hash(), tree.Size())
+	if output != expectedCheck {
+		t.Errorf("Expected batch-check output:\n%s\ngot:\n%s", expectedCheck, output)
+	}
+
+	output, _, err = runCmdWithInput(t, strings.NewReader(blob.Hash()+"\n"+missing+"\n"), constants.CatFileCmdName, "--batch")
+	if err != nil {
+		t.Fatalf("--batch failed: %v", err)
+	}
+	expectedBatch := fmt.Sprintf("%s blob 6\nhello\n\n%s missing\n", blob.Hash(), missing)
+	if output != expectedBatch {
+		t.Errorf("Expected batch output %q, got %q", expectedBatch, output)
+	}
+}
+
+// TestCatFileCommand_Refs verifies branches, tags and HEAD name objects in single and batch modes.
+func TestCatFileCommand_Refs(t *testing.T) {
+	repoPath, history := setupRefHistory(t)
+	gitDir := repository.GitDir(repoPath)
+	tagHash, err := refs.Resolve(gitDir, constants.TagRefPrefix+"v1.0")
+	if err != nil {
+		t.Fatalf("Failed to resolve tag: %v", err)
+	}
+	store := objects.NewObjectStore(gitDir)
+	head, err := store.ReadCommit(history[2])
+	if err != nil {
+		t.Fatalf("Failed to read commit: %v", err)
+	}
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"-t", constants.Head}, "commit\n"},
+		{[]string{"-t", "v1.0"}, "tag\n"},
+		{[]string{"-p", constants.DefaultBranch}, string(head.Content())},
+	}
+	for _, tt := range tests {
+		output, _, err := runCmd(t, constants.CatFileCmdName, tt.args...)
+		if err != nil {
+			t.Fatalf("%s %v failed: %v", constants.CatFileCmdName, tt.args, err)
+		}
+		if output != tt.expected {
+			t.Errorf("%v: expected %q, got %q", tt.args, tt.expected, output)
+		}
+	}
+
+	output, _, err := runCmdWithInput(t, strings.NewReader("HEAD\nmain\nv1.0\n"), constants.CatFileCmdName, "--batch-check")
+	if err != nil {
+		t.Fatalf("--batch-check failed: %v", err)
+	}
+	tag, err := store.ReadTag(tagHash)
+	if err != nil {
+		t.Fatalf("Failed to read tag: %v", err)
+	}
+	expected := fmt.Sprintf("%s commit %d\n%s commit %d\n%s tag %d\n", history[2], head.Size(), history[2], head.Size(), tagHash, tag.Size())
+	if output != expected {
+		t.Errorf("Expected batch-check output:\n%s\ngot:\n%s", expected, output)
+	}
+}
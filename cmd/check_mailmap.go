@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/mailmap"
+	"github.com/spf13/cobra"
+)
+
+var checkMailmapCmd = &cobra.Command{
+	Use:   "check-mailmap <contact>...",
+	Short: "Show canonical names and emails for contacts",
+	Long: `For each "Name <email>" or "<email>" contact, print the canonical identity
+according to the repository's .mailmap file. Contacts without a matching
+entry are printed unchanged.
+
+Examples:
+  # Look up the canonical identity of an old address
+  gogit check-mailmap "Ada <ada@old.example.com>"`,
+	SilenceUsage: true,
+	Args:         checkMailmapArgs,
+	RunE:         runCheckMailmap,
+}
+
+func init() {
+	rootCmd.AddCommand(checkMailmapCmd)
+}
+
+// checkMailmapArgs requires at least one contact.
+// Enables usage printing in case of error.
+func checkMailmapArgs(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		cmd.SilenceUsage = false
+		return fmt.Errorf("%s command requires at least 1 argument (contact), received 0", constants.CheckMailmapCmdName)
+	}
+	return nil
+}
+
+// runCheckMailmap prints mapped identity of each contact.
+func runCheckMailmap(cmd *cobra.Command, args []string) error {
+	layout, err := findRepoRoot()
+	if err != nil {
+		return err
+	}
+
+	identities, err := mailmap.Load(layout.Root)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	for _, contact := range args {
+		name, email, err := parseContact(contact)
+		if err != nil {
+			return err
+		}
+
+		name, email = identities.Map(name, email)
+		if name == "" {
+			fmt.Fprintf(out, "<%s>\n", email)
+		} else {
+			fmt.Fprintf(out, "%s <%s>\n", name, email)
+		}
+	}
+	return nil
+}
+
+// parseContact splits "Name <email>" or "<email>" into its parts.
+func parseContact(contact string) (string, string, error) {
+	name, rest, found := strings.Cut(contact, "<")
+	email, tail, closed := strings.Cut(rest, ">")
+	if !found || !closed || strings.TrimSpace(tail) != "" {
+		return "", "", fmt.Errorf("unable to parse contact: %s", contact)
+	}
+	return strings.TrimSpace(name), strings.TrimSpace(email), nil
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// TestCheckMailmapCommand verifies contacts are mapped through the repository's .mailmap.
+func TestCheckMailmapCommand(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	testutils.CreateTestFile(t, repoPath, constants.MailmapFile,
+		[]byte("Ada Lovelace <ada@example.com> <ada@old.example.com>\n"))
+
+	output, _, err := runCmd(t, constants.CheckMailmapCmdName, "ada <ada@old.example.com>", "<other@example.com>")
+	if err != nil {
+		t.Fatalf("%s command failed: %v", constants.CheckMailmapCmdName, err)
+	}
+
+	expected := "Ada Lovelace <ada@example.com>\n<other@example.com>\n"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
+
+// TestCheckMailmapCommand_Errors verifies missing and malformed contacts are rejected.
+func TestCheckMailmapCommand_Errors(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+
+	if _, _, err := runCmd(t, constants.CheckMailmapCmdName); err == nil || !strings.Contains(err.Error(), "requires at least 1 argument") {
+		t.Errorf("Expected missing argument error, got %v", err)
+	}
+	if _, _, err := runCmd(t, constants.CheckMailmapCmdName, "no email"); err == nil || !strings.Contains(err.Error(), "unable to parse contact") {
+		t.Errorf("Expected parse error, got %v", err)
+	}
+}
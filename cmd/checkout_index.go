@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/index"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/spf13/cobra"
+)
+
+var checkoutIndexCmd = &cobra.Command{
+	Use:   "checkout-index [-a] [-f] [-u] [--prefix=<string>] [<file>...]",
+	Short: "Copy files from the index to the working tree",
+	Long: `Write the index version of the given files, or of every file with -a, to
+the working tree.
+
+Files that already exist are left alone and reported, unless -f is given;
+files whose stat data still matches the index are skipped silently. Unmerged
+paths are reported when named and skipped by -a.
+
+--prefix is prepended to each path as a plain string, relative to the
+repository root, so "out/" exports into a directory and "copy-" prefixes the
+first path component. -u updates the stat data of checked-out entries in the
+index; it has no effect together with --prefix.
+
+Examples:
+  # Restore a file from the index, overwriting local changes
+  gogit checkout-index -f README.md
+
+  # Export the whole index into another directory
+  gogit checkout-index -a --prefix=/tmp/export/`,
+	SilenceUsage: true,
+	RunE:         runCheckoutIndex,
+}
+
+var (
+	checkoutIndexAllFlag    bool
+	checkoutIndexForceFlag  bool
+	checkoutIndexUpdateFlag bool
+	checkoutIndexPrefixFlag string
+)
+
+func init() {
+	rootCmd.AddCommand(checkoutIndexCmd)
+
+	checkoutIndexCmd.Flags().BoolVarP(&checkoutIndexAllFlag, "all", "a", false, "Check out all files in the index")
+	checkoutIndexCmd.Flags().BoolVarP(&checkoutIndexForceFlag, "force", "f", false, "Overwrite existing files")
+	checkoutIndexCmd.Flags().BoolVarP(&checkoutIndexUpdateFlag, "index", "u", false, "Update stat information in the index")
+	checkoutIndexCmd.Flags().StringVar(&checkoutIndexPrefixFlag, "prefix", "", "Prepend <string> to the path of each written file")
+}
+
+// runCheckoutIndex writes the requested index entries, reporting those it cannot write.
+func runCheckoutIndex(cmd *cobra.Command, args []string) error {
+	if checkoutIndexAllFlag && len(args) > 0 {
+		cmd.SilenceUsage = false
+		return fmt.Errorf("%s: don't mix '--all' and explicit filenames", constants.CheckoutIndexCmdName)
+	}
+
+	layout, err := findRepoRoot()
+	if err != nil {
+		return err
+	}
+	repoPath := layout.Root
+	store := newObjectStore(layout.GitDir)
+
+	failed := 0
+	checkout := func(idx *index.Index) error {
+		var entries []*index.Entry
+		if checkoutIndexAllFlag {
+			for i := range idx.Entries() {
+				if entry := &idx.Entries()[i]; entry.Stage == 0 {
+					entries = append(entries, entry)
+				}
+			}
+		}
+		for _, arg := range args {
+			path, err := repoRelativePath(repoPath, arg)
+			if err != nil {
+				return err
+			}
+			switch stages := idx.Stages(path); {
+			case len(stages) == 0:
+				fmt.Fprintf(cmd.ErrOrStderr(), "%s: %s is not in the cache\n", constants.CheckoutIndexCmdName, path)
+				failed++
+			case stages[0].Stage != 0:
+				fmt.Fprintf(cmd.ErrOrStderr(), "%s: %s is unmerged\n", constants.CheckoutIndexCmdName, path)
+				failed++
+			default:
+				entries = append(entries, &stages[0])
+			}
+		}
+
+		for _, entry := range entries {
+			written, err := checkoutEntry(repoPath, store, idx, entry)
+			if err != nil {
+				return err
+			}
+			if !written {
+				fmt.Fprintf(cmd.ErrOrStderr(), "%s already exists, no checkout\n", checkoutIndexPrefixFlag+entry.Path)
+				failed++
+			}
+		}
+		return nil
+	}
+
+	if checkoutIndexUpdateFlag && checkoutIndexPrefixFlag == "" {
+		err = index.Update(layout.GitDir, checkout)
+	} else {
+		var idx *index.Index
+		if idx, err = index.Read(layout.GitDir); err == nil {
+			err = checkout(idx)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d path(s) not checked out", failed)
+	}
+	return nil
+}
+
+// checkoutEntry writes entry at its prefixed path, replacing what is there only with -f.
+// Returns false when an existing file was left in place. Entries checked out to their own
+// path are skipped while their stat data matches, and refreshed afterwards with -u.
+func checkoutEntry(repoPath string, store *objects.ObjectStore, idx *index.Index, entry *index.Entry) (bool, error) {
+	target := filepath.FromSlash(checkoutIndexPrefixFlag + entry.Path)
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(repoPath, target)
+	}
+
+	if _, err := os.Lstat(target); err == nil {
+		if checkoutIndexPrefixFlag == "" {
+			mode, changed, err := idx.StatWorktree(repoPath, entry)
+			if err != nil {
+				return false, err
+			}
+			if mode != "" && !changed {
+				return true, nil
+			}
+		}
+		if !checkoutIndexForceFlag {
+			return false, nil
+		}
+		if err := os.RemoveAll(target); err != nil {
+			return false, fmt.Errorf("failed to remove %s: %w", target, err)
+		}
+	}
+
+	if err := createLeadingDirectories(filepath.Dir(target)); err != nil {
+		return false, err
+	}
+	if err := writeIndexEntry(store, entry, target); err != nil {
+		return false, err
+	}
+
+	if checkoutIndexUpdateFlag && checkoutIndexPrefixFlag == "" {
+		info, err := os.Lstat(target)
+		if err != nil {
+			return false, fmt.Errorf("failed to stat %s: %w", entry.Path, err)
+		}
+		refreshed, err := index.NewEntry(entry.Path, entry.Hash, info)
+		if err != nil {
+			return false, err
+		}
+		idx.Add(*refreshed)
+	}
+	return true, nil
+}
+
+// createLeadingDirectories creates dir and its parents. With -f, a file standing where
+// a directory is needed is removed first.
+func createLeadingDirectories(dir string) error {
+	err := os.MkdirAll(dir, constants.DirPerms)
+	if err == nil {
+		return nil
+	}
+	if checkoutIndexForceFlag {
+		// The nearest ancestor that exists is the file in the way
+		blocked := dir
+		info, statErr := os.Lstat(blocked)
+		for statErr != nil && blocked != filepath.Dir(blocked) {
+			blocked = filepath.Dir(blocked)
+			info, statErr = os.Lstat(blocked)
+		}
+		if statErr == nil && !info.IsDir() && os.Remove(blocked) == nil {
+			err = os.MkdirAll(dir, constants.DirPerms)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("cannot create directory at '%s': %w", dir, err)
+	}
+	return nil
+}
+
+// writeIndexEntry writes the content of entry to target according to its mode: a symlink,
+// a regular or executable file, or an empty directory for a submodule.
+func writeIndexEntry(store *objects.ObjectStore, entry *index.Entry, target string) error {
+	if entry.Mode == objects.ModeSubmodule {
+		return os.Mkdir(target, constants.DirPerms)
+	}
+
+	blob, err := store.ReadBlob(entry.Hash)
+	if err != nil {
+		return fmt.Errorf("unable to read %s for %s: %w", entry.Hash, entry.Path, err)
+	}
+	if entry.Mode == objects.ModeSymlink {
+		if err := os.Symlink(string(blob.Content()), target); err != nil {
+			return fmt.Errorf("failed to create symlink %s: %w", entry.Path, err)
+		}
+		return nil
+	}
+
+	perm := fs.FileMode(0o644)
+	if entry.Mode == objects.ModeExecutable {
+		perm = 0o755
+	}
+	if err := os.WriteFile(target, blob.Content(), perm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", entry.Path, err)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/index"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// setupCheckoutIndexRepo stages a regular file, a nested file, an executable and a symlink.
+func setupCheckoutIndexRepo(t *testing.T) string {
+	t.Helper()
+
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	gitDir := repository.GitDir(repoPath)
+	changeToRepoDir(t, repoPath)
+	store := objects.NewObjectStore(gitDir)
+
+	err := index.Update(gitDir, func(idx *index.Index) error {
+		for _, file := range []struct {
+			path, content string
+			mode          objects.FileMode
+		}{
+			{"a.txt", "a\n", objects.ModeRegularFile},
+			{"dir/b.txt", "b\n", objects.ModeRegularFile},
+			{"run.sh", "#!/bin/sh\n", objects.ModeExecutable},
+			{"link", "a.txt", objects.ModeSymlink},
+		} {
+			blob := objects.NewBlob([]byte(file.content))
+			if err := store.Store(blob); err != nil {
+				return err
+			}
+			idx.Add(index.Entry{Path: file.path, Mode: file.mode, Hash: blob.Hash()})
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to stage files: %v", err)
+	}
+	return repoPath
+}
+
+// TestCheckoutIndexCommand_Prefix verifies every file type is exported under a prefix.
+func TestCheckoutIndexCommand_Prefix(t *testing.T) {
+	repoPath := setupCheckoutIndexRepo(t)
+	exportPath := filepath.Join(t.TempDir(), "export") + "/"
+
+	if _, _, err := runCmd(t, constants.CheckoutIndexCmdName, "-a", "--prefix="+exportPath); err != nil {
+		t.Fatalf("%s failed: %v", constants.CheckoutIndexCmdName, err)
+	}
+
+	if content, _ := os.ReadFile(filepath.Join(exportPath, "dir", "b.txt")); string(content) != "b\n" {
+		t.Errorf("Expected nested file content, got %q", content)
+	}
+	if info, err := os.Stat(filepath.Join(exportPath, "run.sh")); err != nil || info.Mode().Perm()&0o100 == 0 {
+		t.Errorf("Expected executable file, got %v (%v)", info, err)
+	}
+	if target, err := os.Readlink(filepath.Join(exportPath, "link")); err != nil || target != "a.txt" {
+		t.Errorf("Expected symlink to a.txt, got %q (%v)", target, err)
+	}
+	if _, err := os.Lstat(filepath.Join(repoPath, "a.txt")); err == nil {
+		t.Error("Expected working tree to be left alone")
+	}
+
+	// A relative prefix is a plain string prepended to paths from the repository root
+	if _, _, err := runCmd(t, constants.CheckoutIndexCmdName, "--prefix=copy-", "dir/b.txt"); err != nil {
+		t.Fatalf("%s failed: %v", constants.CheckoutIndexCmdName, err)
+	}
+	testutils.AssertFileExists(t, filepath.Join(repoPath, "copy-dir", "b.txt"))
+
+	_, stderr, err := runCmd(t, constants.CheckoutIndexCmdName, "-a", "--prefix="+exportPath)
+	if err == nil || !strings.Contains(stderr, exportPath+"a.txt already exists, no checkout") {
+		t.Errorf("Expected existing files to be reported, got %q (%v)", stderr, err)
+	}
+}
+
+// TestCheckoutIndexCommand_Force verifies existing files are only overwritten with -f, and -u
+// refreshes their stat data.
+func TestCheckoutIndexCommand_Force(t *testing.T) {
+	repoPath := setupCheckoutIndexRepo(t)
+	testutils.CreateTestFile(t, repoPath, "a.txt", []byte("local change\n"))
+	testutils.CreateTestFile(t, repoPath, "dir", []byte("file in the way\n"))
+
+	_, stderr, err := runCmd(t, constants.CheckoutIndexCmdName, "a.txt")
+	if err == nil || !strings.HasPrefix(stderr, "a.txt already exists, no checkout\n") {
+		t.Fatalf("Expected existing file to be kept, got %q (%v)", stderr, err)
+	}
+	if _, _, err := runCmd(t, constants.CheckoutIndexCmdName, "dir/b.txt"); err == nil || !strings.Contains(err.Error(), "cannot create directory") {
+		t.Fatalf("Expected blocked directory error, got %v", err)
+	}
+
+	if _, _, err := runCmd(t, constants.CheckoutIndexCmdName, "-f", "-u", "a.txt", "dir/b.txt"); err != nil {
+		t.Fatalf("%s -f failed: %v", constants.CheckoutIndexCmdName, err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(repoPath, "a.txt")); string(content) != "a\n" {
+		t.Errorf("Expected index content restored, got %q", content)
+	}
+	testutils.AssertFileExists(t, filepath.Join(repoPath, "dir", "b.txt"))
+
+	info, err := os.Lstat(filepath.Join(repoPath, "a.txt"))
+	if err != nil {
+		t.Fatalf("Failed to stat a.txt: %v", err)
+	}
+	if entry := readIndexEntry(t, repoPath, "a.txt"); !entry.MatchesStat(info) {
+		t.Error("Expected -u to record stat data of the written file")
+	}
+
+	// Unchanged files are skipped without -f
+	if _, _, err := runCmd(t, constants.CheckoutIndexCmdName, "a.txt"); err != nil {
+		t.Errorf("Expected up-to-date file to be skipped, got %v", err)
+	}
+}
+
+// TestCheckoutIndexCommand_Errors verifies unknown and unmerged paths and mixing -a with paths.
+func TestCheckoutIndexCommand_Errors(t *testing.T) {
+	repoPath := setupCheckoutIndexRepo(t)
+	err := index.Update(repository.GitDir(repoPath), func(idx *index.Index) error {
+		idx.Add(index.Entry{Path: "conflict", Mode: objects.ModeRegularFile, Hash: testutils.RandomHash(), Stage: 2})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to update index: %v", err)
+	}
+
+	_, stderr, err := runCmd(t, constants.CheckoutIndexCmdName, "missing", "conflict")
+	expected := "checkout-index: missing is not in the cache\ncheckout-index: conflict is unmerged\n"
+	if err == nil || !strings.HasPrefix(stderr, expected) {
+		t.Errorf("Expected %q, got %q (%v)", expected, stderr, err)
+	}
+
+	if _, _, err := runCmd(t, constants.CheckoutIndexCmdName, "-a", "a.txt"); err == nil || !strings.Contains(err.Error(), "don't mix '--all' and explicit filenames") {
+		t.Errorf("Expected error mixing -a and paths, got %v", err)
+	}
+}
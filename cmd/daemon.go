@@ -0,0 +1,290 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/internal/transport"
+	"github.com/spf13/cobra"
+)
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon [--listen=<host>] [--port=<n>] [--base-path=<path>] [--export-all] [--max-connections=<n>] [--timeout=<n>] [--verbose] [<directory>...]",
+	Short: "Serve repositories over the git:// protocol",
+	Long: `Listen for git:// connections and serve fetches from repositories on this
+machine, read-only: pushes are refused. Each fetch is handled by a separate
+gogit upload-pack process.
+
+A repository is only served when its metadata directory holds a
+git-daemon-export-ok file, or with --export-all. When directories are given,
+only repositories inside them are served. Requested paths are absolute, or
+relative to --base-path when set, and may leave out a ".git" suffix. Symbolic
+links are resolved before a path is checked, so they cannot lead out of the
+base path or the given directories.
+
+At most --max-connections clients are served at once (default 32, 0 for no
+limit); further connections are closed. A connection that sends or receives
+nothing for --timeout seconds (default 600, 0 for no limit) is dropped.
+
+Examples:
+  # Share exported repositories under /srv/git as git://host/<name>
+  gogit daemon --base-path=/srv/git /srv/git
+
+  # Share one repository on the LAN without marking it
+  gogit daemon --export-all /home/alice/project`,
+	SilenceUsage: true,
+	RunE:         runDaemon,
+}
+
+// daemonRequestTimeout bounds the wait for a client's request after it connects.
+const daemonRequestTimeout = 30 * time.Second
+
+// Connection limits applied unless overridden, Git's --max-connections default and a timeout
+// long enough for large fetches over slow links.
+const (
+	defaultDaemonMaxConnections = 32
+	defaultDaemonTimeout        = 600
+)
+
+var (
+	daemonListenFlag         string
+	daemonPortFlag           int
+	daemonBasePathFlag       string
+	daemonExportAllFlag      bool
+	daemonMaxConnectionsFlag int
+	daemonTimeoutFlag        int
+	daemonVerboseFlag        bool
+)
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+
+	daemonCmd.Flags().StringVar(&daemonListenFlag, "listen", "", "Listen on <host> only")
+	daemonCmd.Flags().IntVar(&daemonPortFlag, "port", transport.DaemonPort, "Listen on port <n>")
+	daemonCmd.Flags().StringVar(&daemonBasePathFlag, "base-path", "", "Resolve requested paths under <path>")
+	daemonCmd.Flags().BoolVar(&daemonExportAllFlag, "export-all", false, "Serve repositories without git-daemon-export-ok")
+	daemonCmd.Flags().IntVar(&daemonMaxConnectionsFlag, "max-connections", defaultDaemonMaxConnections, "Serve at most <n> clients at once, 0 for no limit")
+	daemonCmd.Flags().IntVar(&daemonTimeoutFlag, "timeout", defaultDaemonTimeout, "Drop connections idle for <n> seconds, 0 for no limit")
+	daemonCmd.Flags().BoolVar(&daemonVerboseFlag, "verbose", false, "Log every connection and refusal")
+}
+
+// daemon serves git:// requests for the repositories its settings allow.
+type daemon struct {
+	basePath       string
+	exportAll      bool
+	allowed        []string      // Absolute directories repositories must lie in; any when empty
+	maxConnections int           // Connections served at once; unlimited when 0
+	timeout        time.Duration // Longest a connection may be idle; unlimited when 0
+	logger         *log.Logger
+	uploadPack     func(ctx context.Context, repoPath string, conn net.Conn) error
+}
+
+// runDaemon listens until interrupted, serving each connection concurrently.
+func runDaemon(cmd *cobra.Command, args []string) error {
+	if daemonMaxConnectionsFlag < 0 || daemonTimeoutFlag < 0 {
+		return errors.New("--max-connections and --timeout must not be negative")
+	}
+	d := &daemon{
+		basePath:       daemonBasePathFlag,
+		exportAll:      daemonExportAllFlag,
+		maxConnections: daemonMaxConnectionsFlag,
+		timeout:        time.Duration(daemonTimeoutFlag) * time.Second,
+		logger:         log.New(io.Discard, "", log.LstdFlags),
+		uploadPack:     spawnUploadPack,
+	}
+	if daemonVerboseFlag {
+		d.logger.SetOutput(cmd.ErrOrStderr())
+	}
+	for _, dir := range args {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", dir, err)
+		}
+		d.allowed = append(d.allowed, abs)
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(daemonListenFlag, strconv.Itoa(daemonPortFlag)))
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	d.logger.Printf("listening on %s", listener.Addr())
+	return d.serve(cmd.Context(), listener)
+}
+
+// serve accepts connections on listener until ctx is done, which closes it. Connections beyond
+// the limit are closed at once.
+func (d *daemon) serve(ctx context.Context, listener net.Listener) error {
+	stop := context.AfterFunc(ctx, func() { listener.Close() })
+	defer stop()
+
+	var slots chan struct{}
+	if d.maxConnections > 0 {
+		slots = make(chan struct{}, d.maxConnections)
+	}
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to accept connection: %w", err)
+		}
+		if slots == nil {
+			go d.handle(ctx, conn)
+			continue
+		}
+
+		select {
+		case slots <- struct{}{}:
+			go func() {
+				defer func() { <-slots }()
+				d.handle(ctx, conn)
+			}()
+		default:
+			d.logger.Printf("[%s] refused: too many connections", conn.RemoteAddr())
+			conn.Close()
+		}
+	}
+}
+
+// handle reads the client's request and runs upload-pack for it, or refuses it with an error
+// packet. Refusals never say whether the repository exists.
+func (d *daemon) handle(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	client := conn.RemoteAddr()
+
+	conn.SetReadDeadline(time.Now().Add(daemonRequestTimeout))
+	request, err := transport.ReadDaemonRequest(conn)
+	if err != nil {
+		d.logger.Printf("[%s] bad request: %v", client, err)
+		return
+	}
+	conn.SetReadDeadline(time.Time{})
+	d.logger.Printf("[%s] %s %s", client, request.Service, request.Path)
+
+	if request.Service != transport.UploadPackService {
+		d.logger.Printf("[%s] refused service %s", client, request.Service)
+		transport.WriteDaemonError(conn, "service not enabled: "+request.Path)
+		return
+	}
+	repoPath, err := d.resolve(request.Path)
+	if err != nil {
+		d.logger.Printf("[%s] refused %s: %v", client, request.Path, err)
+		transport.WriteDaemonError(conn, "access denied or repository not exported: "+request.Path)
+		return
+	}
+
+	if d.timeout > 0 {
+		conn = &idleTimeoutConn{Conn: conn, timeout: d.timeout}
+	}
+	if err := d.uploadPack(ctx, repoPath, conn); err != nil {
+		d.logger.Printf("[%s] upload-pack %s failed: %v", client, repoPath, err)
+	}
+}
+
+// resolve maps a requested path onto a served repository directory, checking it against the
+// base path and allowed directories and for the export-ok mark. Symlinks are resolved first,
+// so the checks apply to where the repository really is.
+func (d *daemon) resolve(requested string) (string, error) {
+	if !strings.HasPrefix(requested, "/") {
+		return "", errors.New("path is not absolute")
+	}
+	if slices.Contains(strings.Split(requested, "/"), "..") {
+		return "", errors.New("path leaves its directory")
+	}
+	dir := filepath.Clean(filepath.FromSlash(requested))
+	if d.basePath != "" {
+		dir = filepath.Join(d.basePath, dir)
+	}
+
+	var layout repository.Layout
+	var err error
+	for _, candidate := range []string{dir, dir + ".git"} {
+		if layout, err = repository.Open(candidate); err == nil {
+			dir = candidate
+			break
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	if dir, err = filepath.EvalSymlinks(dir); err != nil {
+		return "", err
+	}
+
+	if d.basePath != "" && !inDirectory(dir, d.basePath) {
+		return "", errors.New("path leaves the base path")
+	}
+	if len(d.allowed) > 0 && !slices.ContainsFunc(d.allowed, func(allowed string) bool {
+		return inDirectory(dir, allowed)
+	}) {
+		return "", errors.New("not in an allowed directory")
+	}
+	if !d.exportAll {
+		if _, err := os.Stat(filepath.Join(layout.GitDir, constants.DaemonExportOKFile)); err != nil {
+			return "", fmt.Errorf("not exported: %w", err)
+		}
+	}
+	return dir, nil
+}
+
+// inDirectory reports whether the symlink-free path dir is root or lies under it, resolving
+// symlinks in root.
+func inDirectory(dir, root string) bool {
+	root, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return false
+	}
+	root = strings.TrimSuffix(root, string(filepath.Separator))
+	return dir == root || strings.HasPrefix(dir, root+string(filepath.Separator))
+}
+
+// idleTimeoutConn fails reads and writes once the connection has been idle for timeout, as
+// each one pushes its deadline back.
+type idleTimeoutConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func (c *idleTimeoutConn) Read(p []byte) (int, error) {
+	c.Conn.SetReadDeadline(time.Now().Add(c.timeout))
+	return c.Conn.Read(p)
+}
+
+func (c *idleTimeoutConn) Write(p []byte) (int, error) {
+	c.Conn.SetWriteDeadline(time.Now().Add(c.timeout))
+	return c.Conn.Write(p)
+}
+
+// spawnUploadPack runs "gogit upload-pack" on repoPath with the connection as its standard input
+// and output. A process per fetch keeps a failing fetch from taking the daemon down.
+func spawnUploadPack(ctx context.Context, repoPath string, conn net.Conn) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	process := exec.CommandContext(ctx, executable, constants.UploadPackCmdName, repoPath)
+	process.Stdin, process.Stdout = conn, conn
+	// Input is copied from the connection, which stays open after upload-pack exits
+	process.WaitDelay = time.Second
+	var stderr strings.Builder
+	process.Stderr = &stderr
+
+	if err := process.Run(); err != nil && !errors.Is(err, exec.ErrWaitDelay) {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/transport"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// startTestDaemon serves git:// on a free local port until the test ends, running upload-pack
+// in-process, and returns the address. The test binary cannot be spawned as gogit.
+func startTestDaemon(t *testing.T, d *daemon) string {
+	t.Helper()
+
+	d.logger = log.New(io.Discard, "", 0)
+	d.uploadPack = func(ctx context.Context, repoPath string, conn net.Conn) error {
+		layout, err := enterRepository(repoPath)
+		if err != nil {
+			return err
+		}
+		return transport.ServeUploadPack(layout.GitDir, conn, conn)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- d.serve(ctx, listener) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Daemon failed: %v", err)
+		}
+	})
+	return listener.Addr().String()
+}
+
+// discoverDaemonRefs lists the refs served for path by the daemon at address.
+func discoverDaemonRefs(t *testing.T, address, path string) (*transport.Advertisement, error) {
+	t.Helper()
+
+	endpoint, err := transport.ParseEndpoint("git://" + address + path)
+	if err != nil {
+		t.Fatalf("Failed to parse endpoint: %v", err)
+	}
+	return transport.DiscoverRefs(endpoint)
+}
+
+// exportTestRepo marks the repository at repoPath as served by the daemon.
+func exportTestRepo(t *testing.T, repoPath string) {
+	t.Helper()
+	testutils.CreateTestFile(t, filepath.Join(repoPath, constants.Gogit), constants.DaemonExportOKFile, nil)
+}
+
+// TestDaemon verifies exported repositories are served under the base path, with or without
+// a ".git" suffix, and unexported or escaping paths are refused alike.
+func TestDaemon(t *testing.T) {
+	repoPath, history := setupRefHistory(t)
+	base := filepath.Dir(repoPath)
+	name := "/" + filepath.Base(repoPath)
+	hidden := testutils.SetupTestRepoWithInit(t)
+
+	address := startTestDaemon(t, &daemon{basePath: base})
+
+	exportTestRepo(t, repoPath)
+	advertisement, err := discoverDaemonRefs(t, address, name)
+	if err != nil {
+		t.Fatalf("Failed to list exported repository: %v", err)
+	}
+	if len(advertisement.Refs) == 0 || advertisement.Refs[0].Hash != history[2] {
+		t.Errorf("Expected HEAD at %s first, got %v", history[2], advertisement.Refs)
+	}
+
+	for _, path := range []string{"/" + filepath.Base(hidden), "/missing", name + "/../" + filepath.Base(hidden)} {
+		_, err := discoverDaemonRefs(t, address, path)
+		if err == nil || !strings.Contains(err.Error(), "access denied or repository not exported") {
+			t.Errorf("Expected %s to be refused, got %v", path, err)
+		}
+	}
+}
+
+// TestDaemon_AllowedDirectories verifies --export-all serves unmarked repositories, but only
+// inside the allowed directories.
+func TestDaemon_AllowedDirectories(t *testing.T) {
+	allowed := testutils.SetupTestRepoWithInit(t)
+	other := testutils.SetupTestRepoWithInit(t)
+	address := startTestDaemon(t, &daemon{exportAll: true, allowed: []string{allowed}})
+
+	if _, err := discoverDaemonRefs(t, address, filepath.ToSlash(allowed)); err != nil {
+		t.Errorf("Expected allowed repository to be served, got %v", err)
+	}
+	if _, err := discoverDaemonRefs(t, address, filepath.ToSlash(other)); err == nil {
+		t.Error("Expected repository outside the allowed directories to be refused")
+	}
+}
+
+// TestDaemon_Symlinks verifies symlinks leading out of the base path or the allowed directories
+// are refused.
+func TestDaemon_Symlinks(t *testing.T) {
+	outside := testutils.SetupTestRepoWithInit(t)
+	base := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(base, "link")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	for _, d := range []*daemon{{basePath: base, exportAll: true}, {exportAll: true, allowed: []string{base}}} {
+		address := startTestDaemon(t, d)
+		path := "/link"
+		if d.basePath == "" {
+			path = filepath.ToSlash(filepath.Join(base, "link"))
+		}
+		if _, err := discoverDaemonRefs(t, address, path); err == nil {
+			t.Errorf("Expected symlink %s out of %s to be refused", path, base)
+		}
+	}
+}
+
+// TestDaemon_MaxConnections verifies connections beyond the limit are closed at once.
+func TestDaemon_MaxConnections(t *testing.T) {
+	address := startTestDaemon(t, &daemon{exportAll: true, maxConnections: 1})
+
+	first, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer first.Close()
+
+	second, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer second.Close()
+	second.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := io.ReadAll(second); err != nil {
+		t.Errorf("Expected connection over the limit to be closed, got %v", err)
+	}
+}
+
+// TestDaemon_Timeout verifies an idle fetch is dropped.
+func TestDaemon_Timeout(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	address := startTestDaemon(t, &daemon{exportAll: true, timeout: 100 * time.Millisecond})
+
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	request := "git-upload-pack " + filepath.ToSlash(repoPath) + "\x00"
+	if _, err := fmt.Fprintf(conn, "%04x%s", len(request)+4, request); err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+
+	// The advertisement arrives, then the server gives up waiting for wants
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := io.ReadAll(conn); err != nil {
+		t.Errorf("Expected idle connection to be closed, got %v", err)
+	}
+}
+
+// TestDaemon_ReceivePack verifies pushes are refused.
+func TestDaemon_ReceivePack(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	address := startTestDaemon(t, &daemon{exportAll: true})
+
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	request := "git-receive-pack " + filepath.ToSlash(repoPath) + "\x00"
+	if _, err := fmt.Fprintf(conn, "%04x%s", len(request)+4, request); err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+
+	response, err := io.ReadAll(conn)
+	if err != nil {
+		t.Fatalf("Failed to read response: %v", err)
+	}
+	if !strings.Contains(string(response), "ERR service not enabled") {
+		t.Errorf("Expected service to be refused, got %q", response)
+	}
+}
@@ -0,0 +1,285 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/index"
+	"github.com/KostasZigo/gogit/internal/merge"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/refs"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/utils"
+	"github.com/spf13/cobra"
+)
+
+var describeCmd = &cobra.Command{
+	Use:   "describe [--tags] [--long] [--dirty[=<mark>]] [<commit>...]",
+	Short: "Name a commit after the nearest tag reachable from it",
+	Long: `Find the most recent tag reachable from each commit (HEAD by default) and
+print it. When the tag is not on the commit itself, the number of commits
+since the tag and the abbreviated commit hash are appended: <tag>-<n>-g<hash>.
+
+Only annotated tags are considered unless --tags is given. As in Git, the
+count covers every commit reachable from the described one but not from the
+tag, across all parents of merges, and the tag with the lowest count wins.
+
+Examples:
+  # Describe the current commit
+  gogit describe
+
+  # Include lightweight tags and always print the long form
+  gogit describe --tags --long main
+
+  # Mark the description when the worktree has uncommitted changes
+  gogit describe --dirty`,
+	SilenceUsage: true,
+	Args:         describeArgs,
+	RunE:         runDescribe,
+}
+
+// describeAbbrevLength is the number of hash characters in long descriptions.
+const describeAbbrevLength = 7
+
+// describeMaxCandidates is the number of most recently committed tagged ancestors considered, as in Git.
+const describeMaxCandidates = 10
+
+var (
+	tagsFlag  bool
+	longFlag  bool
+	dirtyFlag string
+)
+
+func init() {
+	rootCmd.AddCommand(describeCmd)
+
+	describeCmd.Flags().BoolVar(&tagsFlag, "tags", false, "Use lightweight tags as well as annotated ones")
+	describeCmd.Flags().BoolVar(&longFlag, "long", false, "Always print <tag>-<n>-g<hash>, even on a tagged commit")
+	describeCmd.Flags().StringVar(&dirtyFlag, "dirty", "", "Append <mark> (default \"-dirty\") when the worktree differs from HEAD")
+	describeCmd.Flags().Lookup("dirty").NoOptDefVal = "-dirty"
+}
+
+// describeArgs rejects --dirty with explicit commits, as the worktree only relates to HEAD.
+// Enables usage printing in case of error.
+func describeArgs(cmd *cobra.Command, args []string) error {
+	if dirtyFlag != "" && len(args) > 0 {
+		cmd.SilenceUsage = false
+		return fmt.Errorf("%s cannot combine --dirty with commits", constants.DescribeCmdName)
+	}
+	return nil
+}
+
+// describeTag is a tag candidate for naming the commit it points at.
+type describeTag struct {
+	name      string
+	annotated bool
+	date      time.Time // Tagger date of annotated tags
+}
+
+// better reports whether t should name its commit instead of other.
+// Annotated tags win over lightweight ones, then newer tags, then lower names.
+func (t describeTag) better(other describeTag) bool {
+	if t.annotated != other.annotated {
+		return t.annotated
+	}
+	if !t.date.Equal(other.date) {
+		return t.date.After(other.date)
+	}
+	return t.name < other.name
+}
+
+// runDescribe prints description for each requested commit.
+func runDescribe(cmd *cobra.Command, args []string) error {
+	layout, err := findRepoRoot()
+	if err != nil {
+		return err
+	}
+	store := newObjectStore(layout.GitDir)
+
+	tags, err := loadDescribeTags(layout.GitDir, store)
+	if err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return errors.New("no names found, cannot describe anything")
+	}
+
+	if len(args) == 0 {
+		head, err := refs.Resolve(layout.GitDir, constants.Head)
+		if err != nil {
+			return fmt.Errorf("cannot describe HEAD: %w", err)
+		}
+
+		description, err := describeCommit(store, tags, head)
+		if err != nil {
+			return err
+		}
+		if dirtyFlag != "" {
+			dirty, err := isWorktreeDirty(cmd, layout, store, head)
+			if err != nil {
+				return err
+			}
+			if dirty {
+				description += dirtyFlag
+			}
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), description)
+		return nil
+	}
+
+	for _, arg := range args {
+		hash, err := resolveObjectName(layout.GitDir, store, arg)
+		if err != nil {
+			return fmt.Errorf("not a valid object name %s: %w", arg, err)
+		}
+		if hash, err = peelObject(store, hash); err != nil {
+			return err
+		}
+
+		description, err := describeCommit(store, tags, hash)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), description)
+	}
+	return nil
+}
+
+// loadDescribeTags maps commit hashes to the best tag pointing at them.
+// Lightweight tags are kept so their presence can be reported even without --tags.
+func loadDescribeTags(gitDir string, store *objects.ObjectStore) (map[string]describeTag, error) {
+	tagRefs, err := refs.List(gitDir, constants.TagRefPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string]describeTag)
+	for _, ref := range tagRefs {
+		candidate := describeTag{name: strings.TrimPrefix(ref.Name, constants.TagRefPrefix)}
+
+		target, err := peelTag(store, ref.Hash, &candidate)
+		if err != nil {
+			return nil, err
+		}
+		if target == "" {
+			continue
+		}
+
+		if current, ok := tags[target]; !ok || candidate.better(current) {
+			tags[target] = candidate
+		}
+	}
+	return tags, nil
+}
+
+// peelTag follows tag objects from hash to the commit they finally point at.
+// Records annotation and date of the outermost tag; returns empty hash for non-commit targets.
+func peelTag(store *objects.ObjectStore, hash string, candidate *describeTag) (string, error) {
+	for {
+		objectType, err := storedObjectType(store, hash)
+		if err != nil {
+			return "", fmt.Errorf("failed to read tagged object %s: %w", hash, err)
+		}
+
+		switch objectType {
+		case utils.CommitObjectType:
+			return hash, nil
+		case utils.TagObjectType:
+			tag, err := store.ReadTag(hash)
+			if err != nil {
+				return "", err
+			}
+			if !candidate.annotated {
+				candidate.annotated = true
+				candidate.date = tag.Tagger().Timestamp
+			}
+			hash = tag.Object()
+		default:
+			return "", nil
+		}
+	}
+}
+
+// describeCommit names hash after the usable tag with the fewest commits reachable from hash but
+// not from the tagged commit. Ties go to the more recently committed tag.
+func describeCommit(store *objects.ObjectStore, tags map[string]describeTag, hash string) (string, error) {
+	usable := func(tag describeTag, ok bool) bool { return ok && (tag.annotated || tagsFlag) }
+	if tag, ok := tags[hash]; usable(tag, ok) {
+		if !longFlag {
+			return tag.name, nil
+		}
+		return fmt.Sprintf("%s-0-g%s", tag.name, hash[:describeAbbrevLength]), nil
+	}
+
+	reachable, err := merge.Ancestors(store, hash)
+	if err != nil {
+		return "", err
+	}
+
+	var candidates []string
+	sawLightweight := false
+	for commit := range reachable {
+		tag, ok := tags[commit]
+		if usable(tag, ok) {
+			candidates = append(candidates, commit)
+		}
+		sawLightweight = sawLightweight || ok
+	}
+	if len(candidates) == 0 {
+		if sawLightweight {
+			return "", fmt.Errorf("no annotated tags can describe %s; however, there were unannotated tags: try --tags", hash)
+		}
+		return "", fmt.Errorf("no tags can describe %s", hash)
+	}
+
+	candidates, err = merge.SortByCommitterDate(store, candidates)
+	if err != nil {
+		return "", err
+	}
+	best, bestDepth := "", 0
+	for _, candidate := range candidates[:min(len(candidates), describeMaxCandidates)] {
+		tagged, err := merge.Ancestors(store, candidate)
+		if err != nil {
+			return "", err
+		}
+		if depth := len(reachable) - len(tagged); best == "" || depth < bestDepth {
+			best, bestDepth = candidate, depth
+		}
+	}
+	return fmt.Sprintf("%s-%d-g%s", tags[best].name, bestDepth, hash[:describeAbbrevLength]), nil
+}
+
+// isWorktreeDirty reports whether index or tracked worktree files differ from commit head.
+func isWorktreeDirty(cmd *cobra.Command, layout repository.Layout, store *objects.ObjectStore, head string) (bool, error) {
+	commit, err := store.ReadCommit(head)
+	if err != nil {
+		return false, err
+	}
+
+	idx, err := index.Read(layout.GitDir)
+	if err != nil {
+		return false, err
+	}
+
+	matches, err := idx.MatchesTree(store, commit.TreeHash())
+	if err != nil || !matches {
+		return !matches, err
+	}
+
+	for _, entry := range idx.Entries() {
+		if err := cmd.Context().Err(); err != nil {
+			return false, err
+		}
+		modified, err := idx.IsModified(layout.Root, &entry)
+		if err != nil {
+			return false, err
+		}
+		if modified {
+			return true, nil
+		}
+	}
+	return false, nil
+}
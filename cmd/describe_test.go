@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/index"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+	"github.com/KostasZigo/gogit/utils"
+)
+
+// writeTestRef points ref name at hash, creating parent directories.
+func writeTestRef(t *testing.T, repoPath, name, hash string) {
+	t.Helper()
+
+	path := filepath.Join(repoPath, constants.Gogit, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), constants.DirPerms); err != nil {
+		t.Fatalf("Failed to create directory for %s: %v", name, err)
+	}
+	if err := os.WriteFile(path, []byte(hash+"\n"), constants.FilePerms); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+}
+
+// storeEmptyTreeHistory stores linear history of count commits on the empty tree, oldest first.
+func storeEmptyTreeHistory(t *testing.T, store *objects.ObjectStore, count int) []string {
+	t.Helper()
+
+	var hashes []string
+	parent := ""
+	for i := range count {
+		author := objects.Author{Name: "A", Email: "a@example.com", Timestamp: time.Unix(int64(1700000000+i), 0).UTC()}
+		commit, err := objects.NewCommit(constants.EmptyTreeHash, parent, "commit", author)
+		if err != nil {
+			t.Fatalf("Failed to create commit: %v", err)
+		}
+		if err := store.Store(commit); err != nil {
+			t.Fatalf("Failed to store commit: %v", err)
+		}
+		parent = commit.Hash()
+		hashes = append(hashes, parent)
+	}
+	return hashes
+}
+
+// storeAnnotatedTag stores tag object for commit and points refs/tags/name at it.
+func storeAnnotatedTag(t *testing.T, repoPath string, store *objects.ObjectStore, name, commit string) {
+	t.Helper()
+
+	tagger := objects.Author{Name: "T", Email: "t@example.com", Timestamp: time.Unix(1700000000, 0).UTC()}
+	tag, err := objects.NewTag(commit, utils.CommitObjectType, name, tagger, name)
+	if err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	if err := store.Store(tag); err != nil {
+		t.Fatalf("Failed to store tag: %v", err)
+	}
+	writeTestRef(t, repoPath, constants.TagRefPrefix+name, tag.Hash())
+}
+
+// TestDescribeCommand verifies nearest annotated tag naming, --long and --tags.
+func TestDescribeCommand(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	store := objects.NewObjectStore(repository.GitDir(repoPath))
+	history := storeEmptyTreeHistory(t, store, 4)
+	storeAnnotatedTag(t, repoPath, store, "v1.0", history[0])
+	writeTestRef(t, repoPath, constants.TagRefPrefix+"light", history[2])
+	writeTestRef(t, repoPath, "refs/heads/"+constants.DefaultBranch, history[3])
+
+	tip := history[3]
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{nil, "v1.0-3-g" + tip[:7]},
+		{[]string{history[0]}, "v1.0"},
+		{[]string{"--long", history[0][:8]}, "v1.0-0-g" + history[0][:7]},
+		{[]string{"--tags", tip}, "light-1-g" + tip[:7]},
+		{[]string{constants.DefaultBranch}, "v1.0-3-g" + tip[:7]},
+		{[]string{"v1.0"}, "v1.0"},
+	}
+
+	for _, tt := range tests {
+		output, _, err := runCmd(t, constants.DescribeCmdName, tt.args...)
+		if err != nil {
+			t.Fatalf("%s %v failed: %v", constants.DescribeCmdName, tt.args, err)
+		}
+		if strings.TrimSpace(output) != tt.expected {
+			t.Errorf("%v: expected %q, got %q", tt.args, tt.expected, strings.TrimSpace(output))
+		}
+	}
+}
+
+// storeCommitWithParents stores a commit on the empty tree with the given parents and returns its hash.
+func storeCommitWithParents(t *testing.T, store *objects.ObjectStore, parents ...string) string {
+	t.Helper()
+
+	var content strings.Builder
+	content.WriteString("tree " + constants.EmptyTreeHash + "\n")
+	for _, parent := range parents {
+		content.WriteString("parent " + parent + "\n")
+	}
+	content.WriteString("author A <a@example.com> 1700000100 +0000\ncommitter A <a@example.com> 1700000100 +0000\n\nmerge\n")
+
+	commit, err := objects.ParseCommit([]byte(content.String()))
+	if err != nil {
+		t.Fatalf("Failed to parse commit: %v", err)
+	}
+	if err := store.Store(commit); err != nil {
+		t.Fatalf("Failed to store commit: %v", err)
+	}
+	return commit.Hash()
+}
+
+// TestDescribeCommand_Merge verifies tags on merged branches are found and the count covers
+// every commit not reachable from the tag, as Git counts it.
+func TestDescribeCommand_Merge(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	store := objects.NewObjectStore(repository.GitDir(repoPath))
+	history := storeEmptyTreeHistory(t, store, 4)
+	side := storeCommitWithParents(t, store, history[0])
+	merged := storeCommitWithParents(t, store, history[3], side)
+	storeAnnotatedTag(t, repoPath, store, "v1.0", history[0])
+	storeAnnotatedTag(t, repoPath, store, "v2.0", side)
+
+	output, _, err := runCmd(t, constants.DescribeCmdName, merged)
+	if err != nil {
+		t.Fatalf("%s command failed: %v", constants.DescribeCmdName, err)
+	}
+	if expected := "v2.0-4-g" + merged[:7]; strings.TrimSpace(output) != expected {
+		t.Errorf("Expected %q, got %q", expected, strings.TrimSpace(output))
+	}
+}
+
+// TestDescribeCommand_NoTags verifies errors when no usable tag is reachable.
+func TestDescribeCommand_NoTags(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	store := objects.NewObjectStore(repository.GitDir(repoPath))
+	history := storeEmptyTreeHistory(t, store, 2)
+
+	if _, _, err := runCmd(t, constants.DescribeCmdName, history[1]); err == nil || !strings.Contains(err.Error(), "no names found") {
+		t.Errorf("Expected no names error, got %v", err)
+	}
+
+	writeTestRef(t, repoPath, constants.TagRefPrefix+"light", history[0])
+	if _, _, err := runCmd(t, constants.DescribeCmdName, history[1]); err == nil || !strings.Contains(err.Error(), "try --tags") {
+		t.Errorf("Expected hint about unannotated tags, got %v", err)
+	}
+}
+
+// TestDescribeCommand_Dirty verifies dirty mark is appended only when index differs from HEAD.
+func TestDescribeCommand_Dirty(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	gitDir := repository.GitDir(repoPath)
+	changeToRepoDir(t, repoPath)
+	store := objects.NewObjectStore(gitDir)
+	history := storeEmptyTreeHistory(t, store, 1)
+	storeAnnotatedTag(t, repoPath, store, "v1.0", history[0])
+	writeTestRef(t, repoPath, "refs/heads/"+constants.DefaultBranch, history[0])
+
+	output, _, err := runCmd(t, constants.DescribeCmdName, "--dirty")
+	if err != nil || strings.TrimSpace(output) != "v1.0" {
+		t.Fatalf("Expected clean description v1.0, got %q, %v", output, err)
+	}
+
+	fullPath := testutils.CreateTestFile(t, repoPath, "file.txt", []byte("content"))
+	info, _ := os.Lstat(fullPath)
+	entry, err := index.NewEntry("file.txt", objects.NewBlob([]byte("content")).Hash(), info)
+	if err != nil {
+		t.Fatalf("Failed to create entry: %v", err)
+	}
+	err = index.Update(gitDir, func(idx *index.Index) error {
+		idx.Add(*entry)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to update index: %v", err)
+	}
+
+	output, _, err = runCmd(t, constants.DescribeCmdName, "--dirty=-wip")
+	if err != nil || strings.TrimSpace(output) != "v1.0-wip" {
+		t.Errorf("Expected v1.0-wip, got %q, %v", output, err)
+	}
+
+	if _, _, err := runCmd(t, constants.DescribeCmdName, "--dirty", history[0]); err == nil {
+		t.Error("Expected error combining --dirty with commits")
+	}
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"github.com/KostasZigo/gogit/internal/diff"
+	"github.com/KostasZigo/gogit/internal/index"
+	"github.com/spf13/cobra"
+)
+
+var diffFilesCmd = &cobra.Command{
+	Use:   "diff-files [-z] [[--] <path>...]",
+	Short: "Compare files in the working tree and the index",
+	Long: `Compare the files in the working tree with the index and print one raw line
+per changed path, in the format of diff-tree.
+
+Files whose stat data differs from the index are shown with an all-zero object
+name, since their content is not hashed; run "update-index --refresh" first to
+drop files that were only touched. Deleted files have status D. An unmerged
+path is shown with status U, followed by the comparison with its "ours" version.
+Paths limit the comparison to matching pathspecs.
+
+Examples:
+  # Tracked files with unstaged changes
+  gogit diff-files
+
+  # Unstaged changes to Go files outside vendor/
+  gogit diff-files -- '*.go' ':!vendor'`,
+	SilenceUsage: true,
+	RunE:         runDiffFiles,
+}
+
+var diffFilesNulFlag bool
+
+func init() {
+	rootCmd.AddCommand(diffFilesCmd)
+
+	diffFilesCmd.Flags().BoolVarP(&diffFilesNulFlag, "null", "z", false, "Terminate lines with NUL")
+}
+
+// runDiffFiles compares the index with the working tree.
+func runDiffFiles(cmd *cobra.Command, args []string) error {
+	layout, err := findRepoRoot()
+	if err != nil {
+		return err
+	}
+	repoPath := layout.Root
+
+	paths, err := parsePathspec(repoPath, args)
+	if err != nil {
+		return err
+	}
+	idx, err := index.Read(layout.GitDir)
+	if err != nil {
+		return err
+	}
+	changes, err := diff.IndexWorktree(repoPath, idx, paths)
+	if err != nil {
+		return err
+	}
+	return diff.WriteRaw(cmd.OutOrStdout(), changes, diffFilesNulFlag)
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/index"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// stageWorktreeFiles writes files to the worktree and records them in the index with their stat data.
+func stageWorktreeFiles(t *testing.T, repoPath string, files map[string]string) {
+	t.Helper()
+
+	err := index.Update(repository.GitDir(repoPath), func(idx *index.Index) error {
+		for name, content := range files {
+			info, err := os.Lstat(testutils.CreateTestFile(t, repoPath, name, []byte(content)))
+			if err != nil {
+				return err
+			}
+			entry, err := index.NewEntry(name, objects.NewBlob([]byte(content)).Hash(), info)
+			if err != nil {
+				return err
+			}
+			idx.Add(*entry)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to stage files: %v", err)
+	}
+}
+
+// TestDiffFilesCommand verifies modified and deleted files are listed with unhashed worktree sides.
+func TestDiffFilesCommand(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	stageWorktreeFiles(t, repoPath, map[string]string{"changed.txt": "old\n", "deleted.txt": "d\n", "kept.txt": "k\n"})
+
+	output, _, err := runCmd(t, constants.DiffFilesCmdName)
+	if err != nil {
+		t.Fatalf("diff-files failed: %v", err)
+	}
+	if output != "" {
+		t.Errorf("Expected no changes in a freshly staged worktree, got %q", output)
+	}
+
+	testutils.CreateTestFile(t, repoPath, "changed.txt", []byte("new content\n"))
+	if err := os.Remove("deleted.txt"); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+
+	zero := strings.Repeat("0", constants.HashStringLength)
+	expected := ":100644 100644 " + objects.NewBlob([]byte("old\n")).Hash() + " " + zero + " M\tchanged.txt\n" +
+		":100644 000000 " + objects.NewBlob([]byte("d\n")).Hash() + " " + zero + " D\tdeleted.txt\n"
+	output, _, err = runCmd(t, constants.DiffFilesCmdName)
+	if err != nil {
+		t.Fatalf("diff-files failed: %v", err)
+	}
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+
+	output, _, err = runCmd(t, constants.DiffFilesCmdName, "-z")
+	if err != nil {
+		t.Fatalf("diff-files failed: %v", err)
+	}
+	if !strings.HasSuffix(output, " D\x00deleted.txt\x00") {
+		t.Errorf("Expected NUL-terminated paths, got %q", output)
+	}
+
+	output, _, err = runCmd(t, constants.DiffFilesCmdName, "--", "*.txt", ":!deleted.txt")
+	if err != nil {
+		t.Fatalf("diff-files failed: %v", err)
+	}
+	if !strings.HasSuffix(output, " M\tchanged.txt\n") || strings.Count(output, "\n") != 1 {
+		t.Errorf("Expected only changed.txt to be selected, got %q", output)
+	}
+}
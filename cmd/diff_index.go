@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/diff"
+	"github.com/KostasZigo/gogit/internal/index"
+	"github.com/spf13/cobra"
+)
+
+var diffIndexCmd = &cobra.Command{
+	Use:   "diff-index [--cached] [-z] <tree-ish> [[--] <path>...]",
+	Short: "Compare a tree to the working tree or index",
+	Long: `Compare a tree with the files tracked in the index and print one raw line
+per changed path, in the format of diff-tree.
+
+By default the tracked files in the working tree are compared. Files whose
+stat data differs from the index are shown with an all-zero object name, since
+their content is not hashed; run "update-index --refresh" first to drop files
+that were only touched. With --cached the index itself is compared, and
+unmerged paths are shown with status U. Paths limit the comparison to
+matching pathspecs.
+
+Examples:
+  # What would be committed
+  gogit diff-index --cached HEAD`,
+	SilenceUsage: true,
+	Args:         diffIndexArgs,
+	RunE:         runDiffIndex,
+}
+
+var (
+	diffIndexCachedFlag bool
+	diffIndexNulFlag    bool
+)
+
+func init() {
+	rootCmd.AddCommand(diffIndexCmd)
+
+	diffIndexCmd.Flags().BoolVar(&diffIndexCachedFlag, "cached", false, "Compare with the index instead of the working tree")
+	diffIndexCmd.Flags().BoolVarP(&diffIndexNulFlag, "null", "z", false, "Terminate lines with NUL")
+}
+
+// diffIndexArgs requires the tree-ish to compare against, before any paths.
+// Enables usage printing in case of error.
+func diffIndexArgs(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 || cmd.ArgsLenAtDash() == 0 || cmd.ArgsLenAtDash() > 1 {
+		cmd.SilenceUsage = false
+		return fmt.Errorf("%s requires exactly 1 tree-ish argument before paths", constants.DiffIndexCmdName)
+	}
+	return nil
+}
+
+// runDiffIndex compares the tree with the index or working tree.
+func runDiffIndex(cmd *cobra.Command, args []string) error {
+	layout, err := findRepoRoot()
+	if err != nil {
+		return err
+	}
+	repoPath := layout.Root
+	store := newObjectStore(layout.GitDir)
+
+	tree, err := resolveTreeish(layout.GitDir, store, args[0])
+	if err != nil {
+		return err
+	}
+	paths, err := parsePathspec(repoPath, args[1:])
+	if err != nil {
+		return err
+	}
+	idx, err := index.Read(layout.GitDir)
+	if err != nil {
+		return err
+	}
+
+	var changes []diff.Change
+	if diffIndexCachedFlag {
+		changes, err = diff.TreeIndex(store, tree, idx, paths)
+	} else {
+		changes, err = diff.TreeWorktree(store, repoPath, tree, idx, paths)
+	}
+	if err != nil {
+		return err
+	}
+	return diff.WriteRaw(cmd.OutOrStdout(), changes, diffIndexNulFlag)
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// TestDiffIndexCommand verifies comparison of a commit with the index and with the worktree.
+func TestDiffIndexCommand(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	store := objects.NewObjectStore(repository.GitDir(repoPath))
+
+	commit, _ := storeFilesCommit(t, store, "", map[string]string{"a.txt": "a\n", "gone.txt": "g\n"})
+	writeTestRef(t, repoPath, constants.BranchRefPrefix+"main", commit)
+	stageWorktreeFiles(t, repoPath, map[string]string{"a.txt": "a\n", "new.txt": "n\n"})
+	testutils.CreateTestFile(t, repoPath, "a.txt", []byte("unstaged change\n"))
+
+	zero := strings.Repeat("0", constants.HashStringLength)
+	aHash := objects.NewBlob([]byte("a\n")).Hash()
+	added := ":000000 100644 " + zero + " " + objects.NewBlob([]byte("n\n")).Hash() + " A\tnew.txt\n"
+	deleted := ":100644 000000 " + objects.NewBlob([]byte("g\n")).Hash() + " " + zero + " D\tgone.txt\n"
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{name: "cached", args: []string{"--cached", "main"}, expected: deleted + added},
+		{name: "worktree", args: []string{"main"}, expected: ":100644 100644 " + aHash + " " + zero + " M\ta.txt\n" + deleted + added},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output, _, err := runCmd(t, constants.DiffIndexCmdName, test.args...)
+			if err != nil {
+				t.Fatalf("diff-index failed: %v", err)
+			}
+			if output != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, output)
+			}
+		})
+	}
+
+	if _, _, err := runCmd(t, constants.DiffIndexCmdName); err == nil || !strings.Contains(err.Error(), "requires exactly 1 tree-ish argument") {
+		t.Errorf("Expected argument count error, got %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/diff"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/pathspec"
+	"github.com/KostasZigo/gogit/utils"
+	"github.com/spf13/cobra"
+)
+
+var diffTreeCmd = &cobra.Command{
+	Use:   "diff-tree [-r] [-z] [--root] <tree-ish> [<tree-ish>] [[--] <path>...]",
+	Short: "Compare the content and mode of blobs found via two tree objects",
+	Long: `Compare two trees and print one raw line per changed path:
+
+  :<old mode> <new mode> <old object> <new object> <status>\t<path>
+
+Status is A (added), D (deleted), M (modified) or T (type changed). Without
+-r only top-level entries are compared, so a changed directory is one line.
+
+With a single commit, it is compared with its parent and its hash is printed
+first. Root commits are compared with the empty tree only with --root, and
+merge commits are skipped. -z ends the hash line and each path with NUL and
+separates the path from its status with NUL.
+
+Paths limit the comparison to matching pathspecs, such as "src", "*.go",
+":(glob)src/**/*.c" or ":!vendor". A second argument that is not a tree-ish
+starts the paths; "--" separates them explicitly.
+
+Examples:
+  # Files changed by the last commit
+  gogit diff-tree -r HEAD
+
+  # Top-level differences between two branches
+  gogit diff-tree main topic`,
+	SilenceUsage: true,
+	Args:         diffTreeArgs,
+	RunE:         runDiffTree,
+}
+
+var (
+	diffTreeRecursiveFlag bool
+	diffTreeNulFlag       bool
+	diffTreeRootFlag      bool
+)
+
+func init() {
+	rootCmd.AddCommand(diffTreeCmd)
+
+	diffTreeCmd.Flags().BoolVarP(&diffTreeRecursiveFlag, "recursive", "r", false, "Recurse into subtrees")
+	diffTreeCmd.Flags().BoolVarP(&diffTreeNulFlag, "null", "z", false, "Terminate lines with NUL")
+	diffTreeCmd.Flags().BoolVar(&diffTreeRootFlag, "root", false, "Compare a root commit with the empty tree")
+}
+
+// diffTreeArgs requires one commit or two tree-ish objects, before any "--".
+// Enables usage printing in case of error.
+func diffTreeArgs(cmd *cobra.Command, args []string) error {
+	trees := cmd.ArgsLenAtDash()
+	if trees < 0 {
+		trees = min(len(args), 1)
+	}
+	if trees < 1 || trees > 2 {
+		cmd.SilenceUsage = false
+		return fmt.Errorf("%s requires 1 or 2 tree-ish arguments, received %d", constants.DiffTreeCmdName, trees)
+	}
+	return nil
+}
+
+// runDiffTree compares two trees, or a commit with its parent.
+func runDiffTree(cmd *cobra.Command, args []string) error {
+	layout, err := findRepoRoot()
+	if err != nil {
+		return err
+	}
+	repoPath := layout.Root
+	store := newObjectStore(layout.GitDir)
+
+	// Without "--", a second argument is a tree-ish when it names an object and a path otherwise
+	trees := cmd.ArgsLenAtDash()
+	if trees < 0 {
+		trees = 1
+		if len(args) > 1 {
+			if _, err := resolveObjectName(layout.GitDir, store, args[1]); err == nil {
+				trees = 2
+			}
+		}
+	}
+	paths, err := parsePathspec(repoPath, args[trees:])
+	if err != nil {
+		return err
+	}
+
+	if trees == 2 {
+		oldTree, err := resolveTreeish(layout.GitDir, store, args[0])
+		if err != nil {
+			return err
+		}
+		newTree, err := resolveTreeish(layout.GitDir, store, args[1])
+		if err != nil {
+			return err
+		}
+		changes, err := diff.Trees(store, oldTree, newTree, diffTreeRecursiveFlag, paths)
+		if err != nil {
+			return err
+		}
+		return diff.WriteRaw(cmd.OutOrStdout(), changes, diffTreeNulFlag)
+	}
+
+	return diffCommit(cmd, layout.GitDir, store, args[0], paths)
+}
+
+// diffCommit prints the changes a commit made to its parent, preceded by the commit hash.
+// Nothing is printed for merges, root commits without --root, or commits changing nothing.
+func diffCommit(cmd *cobra.Command, gitDir string, store *objects.ObjectStore, name string, paths *pathspec.Pathspec) error {
+	hash, err := resolveObjectName(gitDir, store, name)
+	if err != nil {
+		return fmt.Errorf("not a valid object name %s: %w", name, err)
+	}
+	hash, err = peelObject(store, hash)
+	if err != nil {
+		return err
+	}
+	objectType, err := storedObjectType(store, hash)
+	if err != nil {
+		return err
+	}
+	if objectType != utils.CommitObjectType {
+		return fmt.Errorf("object %s is a %s, not a commit", hash, objectType)
+	}
+
+	commit, err := store.ReadCommit(hash)
+	if err != nil {
+		return err
+	}
+	parentTree := ""
+	switch parents := commit.Parents(); {
+	case len(parents) > 1, len(parents) == 0 && !diffTreeRootFlag:
+		return nil
+	case len(parents) == 1:
+		parent, err := store.ReadCommit(parents[0])
+		if err != nil {
+			return fmt.Errorf("failed to read commit %s: %w", parents[0], err)
+		}
+		parentTree = parent.TreeHash()
+	}
+
+	changes, err := diff.Trees(store, parentTree, commit.TreeHash(), diffTreeRecursiveFlag, paths)
+	if err != nil || len(changes) == 0 {
+		return err
+	}
+
+	terminator := "\n"
+	if diffTreeNulFlag {
+		terminator = "\x00"
+	}
+	fmt.Fprint(cmd.OutOrStdout(), hash+terminator)
+	return diff.WriteRaw(cmd.OutOrStdout(), changes, diffTreeNulFlag)
+}
+
+// resolveTreeish resolves a ref name or object name prefix to a tree, peeling tags and commits.
+func resolveTreeish(gitDir string, store *objects.ObjectStore, name string) (string, error) {
+	hash, err := resolveObjectName(gitDir, store, name)
+	if err != nil {
+		return "", fmt.Errorf("not a valid object name %s: %w", name, err)
+	}
+	hash, err = peelObject(store, hash)
+	if err != nil {
+		return "", err
+	}
+
+	objectType, err := storedObjectType(store, hash)
+	if err != nil {
+		return "", err
+	}
+	switch objectType {
+	case utils.CommitObjectType:
+		commit, err := store.ReadCommit(hash)
+		if err != nil {
+			return "", err
+		}
+		return commit.TreeHash(), nil
+	case utils.TreeObjectType:
+		return hash, nil
+	default:
+		return "", fmt.Errorf("%s is a %s, not a tree-ish", name, objectType)
+	}
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// setupDiffTreeRepo stores a root commit and a child changing dir/b.txt and adding c.txt.
+// Returns the commit hashes, with main pointing at the child.
+func setupDiffTreeRepo(t *testing.T) (store *objects.ObjectStore, root, child string) {
+	t.Helper()
+
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	store = objects.NewObjectStore(repository.GitDir(repoPath))
+
+	root, _ = storeFilesCommit(t, store, "", map[string]string{"a.txt": "a\n", "dir/b.txt": "b\n"})
+	child, _ = storeFilesCommit(t, store, root, map[string]string{"a.txt": "a\n", "dir/b.txt": "changed\n", "c.txt": "c\n"})
+	writeTestRef(t, repoPath, constants.BranchRefPrefix+"main", child)
+	return store, root, child
+}
+
+// TestDiffTreeCommand verifies two-tree and single-commit comparisons, with and without recursion.
+func TestDiffTreeCommand(t *testing.T) {
+	store, root, child := setupDiffTreeRepo(t)
+	blobHash := func(content string) string { return objects.NewBlob([]byte(content)).Hash() }
+	zero := strings.Repeat("0", constants.HashStringLength)
+
+	nested := ":100644 100644 " + blobHash("b\n") + " " + blobHash("changed\n") + " M\tdir/b.txt\n"
+	added := ":000000 100644 " + zero + " " + blobHash("c\n") + " A\tc.txt\n"
+
+	rootCommit, err := store.ReadCommit(root)
+	if err != nil {
+		t.Fatalf("Failed to read commit: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{name: "recursive", args: []string{"-r", root, "main"}, expected: added + nested},
+		{name: "single commit", args: []string{"-r", "main"}, expected: child + "\n" + added + nested},
+		{name: "root commit skipped", args: []string{"-r", root}, expected: ""},
+		{name: "tree arguments", args: []string{rootCommit.TreeHash(), rootCommit.TreeHash()}, expected: ""},
+		{
+			name:     "nul terminated",
+			args:     []string{"-r", "-z", "main"},
+			expected: child + "\x00" + strings.ReplaceAll(strings.ReplaceAll(added+nested, "\t", "\x00"), "\n", "\x00"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output, _, err := runCmd(t, constants.DiffTreeCmdName, test.args...)
+			if err != nil {
+				t.Fatalf("diff-tree failed: %v", err)
+			}
+			if output != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, output)
+			}
+		})
+	}
+
+	output, _, err := runCmd(t, constants.DiffTreeCmdName, root, "main")
+	if err != nil {
+		t.Fatalf("diff-tree failed: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n"); len(lines) != 2 || !strings.HasSuffix(lines[1], " M\tdir") {
+		t.Errorf("Expected a changed directory reported as one entry, got %q", output)
+	}
+
+	output, _, err = runCmd(t, constants.DiffTreeCmdName, "--root", "-r", root)
+	if err != nil {
+		t.Fatalf("diff-tree failed: %v", err)
+	}
+	if !strings.HasPrefix(output, root+"\n") || strings.Count(output, " A\t") != 2 {
+		t.Errorf("Expected root commit compared with the empty tree, got %q", output)
+	}
+}
+
+// TestDiffTreeCommand_Errors verifies argument count, unknown names and non-tree objects.
+func TestDiffTreeCommand_Errors(t *testing.T) {
+	store, _, _ := setupDiffTreeRepo(t)
+	blob := objects.NewBlob([]byte("a\n"))
+	if err := store.Store(blob); err != nil {
+		t.Fatalf("Failed to store blob: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		args          []string
+		expectedError string
+	}{
+		{name: "no arguments", args: nil, expectedError: "requires 1 or 2 tree-ish arguments"},
+		{name: "three trees", args: []string{"main", "main", "main", "--"}, expectedError: "requires 1 or 2 tree-ish arguments"},
+		{name: "unknown name", args: []string{"main", "missing", "--"}, expectedError: "not a valid object name missing"},
+		{name: "blob", args: []string{"main", blob.Hash()}, expectedError: "is a blob, not a tree-ish"},
+		{name: "single blob", args: []string{blob.Hash()}, expectedError: "is a blob, not a commit"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := runCmd(t, constants.DiffTreeCmdName, test.args...)
+			if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+				t.Errorf("Expected error containing %q, got %v", test.expectedError, err)
+			}
+		})
+	}
+}
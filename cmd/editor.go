@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/repository"
+)
+
+// defaultEditor is run when no editor is configured.
+const defaultEditor = "vi"
+
+// editorCommand returns the editor to open files in: GOGIT_EDITOR, core.editor, VISUAL,
+// EDITOR or vi, in that order.
+func editorCommand(gitDir string) (string, error) {
+	if editor := os.Getenv(constants.EditorEnv); editor != "" {
+		return editor, nil
+	}
+	editor, err := repository.ConfigValue(gitDir, "core", "editor")
+	if err != nil || editor != "" {
+		return editor, err
+	}
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if editor := os.Getenv(name); editor != "" {
+			return editor, nil
+		}
+	}
+	return defaultEditor, nil
+}
+
+// launchEditor opens file in the user's editor and waits for it to exit. The editor runs
+// through the shell, so it may carry arguments; ":" leaves the file as it is, as in Git.
+func launchEditor(gitDir, file string, stdout, stderr io.Writer) error {
+	editor, err := editorCommand(gitDir)
+	if err != nil {
+		return err
+	}
+	if editor == ":" {
+		return nil
+	}
+
+	command := exec.Command("sh", "-c", editor+` "$@"`, editor, file)
+	command.Stdin = os.Stdin
+	command.Stdout = stdout
+	command.Stderr = stderr
+	if err := command.Run(); err != nil {
+		return fmt.Errorf("there was a problem with the editor '%s': %w", editor, err)
+	}
+	return nil
+}
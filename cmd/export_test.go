@@ -2,10 +2,14 @@ package cmd
 
 import (
 	"bytes"
+	"io"
 	"os"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // createTestRootCmd creates fresh root command with init subcommand.
@@ -46,3 +50,66 @@ func changeToRepoDir(t *testing.T, repoPath string) {
 		os.Chdir(oldDir)
 	})
 }
+
+// resetFlags restores command flags, and the silenced usage every command starts with, to their
+// defaults now and once the test finishes. Commands are package globals, so values would
+// otherwise leak between tests.
+func resetFlags(t *testing.T, cmd *cobra.Command) {
+	t.Helper()
+
+	reset := func() {
+		cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+			if slice, ok := flag.Value.(pflag.SliceValue); ok {
+				slice.Replace(nil)
+			} else {
+				flag.Value.Set(flag.DefValue)
+			}
+			flag.Changed = false
+		})
+		// Forget where "--" was in the previous run; Init keeps the defined flags
+		cmd.Flags().Init(cmd.Name(), pflag.ContinueOnError)
+		// Args validators turn usage back on for the run that fails them
+		cmd.SilenceUsage = true
+	}
+
+	reset()
+	t.Cleanup(reset)
+}
+
+// runCmd runs the command called name with args under a fresh root with its flags reset,
+// returning what it wrote to stdout and stderr.
+func runCmd(t *testing.T, name string, args ...string) (string, string, error) {
+	t.Helper()
+	return runCmdWithInput(t, strings.NewReader(""), name, args...)
+}
+
+// runCmdWithInput is runCmd with input as the command's standard input.
+func runCmdWithInput(t *testing.T, input io.Reader, name string, args ...string) (string, string, error) {
+	t.Helper()
+
+	i := slices.IndexFunc(rootCmd.Commands(), func(cmd *cobra.Command) bool { return cmd.Name() == name })
+	if i < 0 {
+		t.Fatalf("Unknown command %s", name)
+	}
+	cmd := rootCmd.Commands()[i]
+	resetCommandFlags(t, cmd)
+
+	testRootCmd := createTestRootCmd(cmd)
+	stdout := captureStdout(testRootCmd)
+	stderr := captureStderr(testRootCmd)
+	testRootCmd.SetIn(input)
+	testRootCmd.SetArgs(append([]string{name}, args...))
+
+	err := testRootCmd.Execute()
+	return stdout.String(), stderr.String(), err
+}
+
+// resetCommandFlags resets the flags of cmd and its subcommands, as with resetFlags.
+func resetCommandFlags(t *testing.T, cmd *cobra.Command) {
+	t.Helper()
+
+	resetFlags(t, cmd)
+	for _, sub := range cmd.Commands() {
+		resetCommandFlags(t, sub)
+	}
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/faststream"
+	"github.com/KostasZigo/gogit/internal/refs"
+	"github.com/spf13/cobra"
+)
+
+var fastExportCmd = &cobra.Command{
+	Use:   "fast-export (--all | <ref>...)",
+	Short: "Write history as a fast-import stream",
+	Long: `Write branches and tags, with all history reachable from them, as a
+fast-import stream on standard output. The stream can be replayed with
+gogit fast-import or git fast-import, or fed to other version control tools.
+
+Refs may be given by full name or short name (main, v1.0). Commit
+signatures and other extra headers are not exported.
+
+Examples:
+  # Export every branch and tag
+  gogit fast-export --all > repo.stream
+
+  # Copy the main branch into a Git repository
+  gogit fast-export main | (cd ../clone && git fast-import)`,
+	SilenceUsage: true,
+	Args:         fastExportArgs,
+	RunE:         runFastExport,
+}
+
+var fastExportAllFlag bool
+
+func init() {
+	rootCmd.AddCommand(fastExportCmd)
+
+	fastExportCmd.Flags().BoolVar(&fastExportAllFlag, "all", false, "Export all branches and tags")
+}
+
+// fastExportArgs requires refs to export, either named or through --all.
+// Enables usage printing in case of error.
+func fastExportArgs(cmd *cobra.Command, args []string) error {
+	switch {
+	case fastExportAllFlag && len(args) > 0:
+		cmd.SilenceUsage = false
+		return fmt.Errorf("%s cannot combine --all with ref arguments", constants.FastExportCmdName)
+	case !fastExportAllFlag && len(args) == 0:
+		cmd.SilenceUsage = false
+		return fmt.Errorf("%s command requires at least 1 argument (ref) or --all, received 0", constants.FastExportCmdName)
+	}
+	return nil
+}
+
+// runFastExport resolves refs to export and streams their history to stdout.
+func runFastExport(cmd *cobra.Command, args []string) error {
+	layout, err := findRepoRoot()
+	if err != nil {
+		return err
+	}
+
+	exportRefs, err := fastExportRefs(layout.GitDir, args)
+	if err != nil {
+		return err
+	}
+
+	store := newObjectStore(layout.GitDir)
+	if err := faststream.Export(cmd.Context(), cmd.OutOrStdout(), store, exportRefs); err != nil {
+		return fmt.Errorf("failed to export: %w", err)
+	}
+	return nil
+}
+
+// fastExportRefs returns refs to export: every ref with --all, otherwise those named.
+func fastExportRefs(gitDir string, names []string) ([]refs.Ref, error) {
+	if fastExportAllFlag {
+		return refs.List(gitDir, constants.Refs+"/")
+	}
+
+	exportRefs := make([]refs.Ref, 0, len(names))
+	for _, name := range names {
+		ref, err := refs.Expand(gitDir, name)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(ref.Name, constants.Refs+"/") {
+			return nil, fmt.Errorf("%s is not a branch or tag", name)
+		}
+		exportRefs = append(exportRefs, ref)
+	}
+	return exportRefs, nil
+}
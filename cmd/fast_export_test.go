@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// TestFastExportCommand verifies imported history exports back to the same stream.
+func TestFastExportCommand(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	if _, _, err := runCmdWithInput(t, strings.NewReader(fastImportStream), constants.FastImportCmdName); err != nil {
+		t.Fatalf("%s command failed: %v", constants.FastImportCmdName, err)
+	}
+
+	for _, args := range [][]string{{"--all"}, {"main", "v1"}} {
+		output, _, err := runCmd(t, constants.FastExportCmdName, args...)
+		if err != nil {
+			t.Fatalf("%s %v failed: %v", constants.FastExportCmdName, args, err)
+		}
+		if output != fastImportStream {
+			t.Errorf("Expected %v to export:\n%s\ngot:\n%s", args, fastImportStream, output)
+		}
+	}
+}
+
+// TestFastExportCommand_Errors verifies missing, conflicting and unknown refs are rejected.
+func TestFastExportCommand_Errors(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+
+	tests := []struct {
+		name          string
+		args          []string
+		expectedError string
+	}{
+		{"no refs", nil, "requires at least 1 argument"},
+		{"all with refs", []string{"--all", "main"}, "cannot combine --all"},
+		{"unknown ref", []string{"missing"}, "ref not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := runCmd(t, constants.FastExportCmdName, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("Expected error containing [%s], got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/faststream"
+	"github.com/spf13/cobra"
+)
+
+var fastImportCmd = &cobra.Command{
+	Use:   "fast-import",
+	Short: "Import history from a fast-import stream",
+	Long: `Read a fast-import stream from standard input, store the blobs, trees,
+commits and tags it describes, and update the branches and tags it names.
+Refs are only updated once the whole stream has been read, so a malformed
+stream leaves the repository's refs untouched.
+
+Blobs may be streamed with counted or delimited data. File changes support
+M, D, C, R and deleteall; notes and directory modes are not supported.
+
+Examples:
+  # Import history exported from Git
+  git fast-export --all | gogit fast-import
+
+  # Build a test fixture from a hand-written stream
+  gogit fast-import < fixture.stream`,
+	SilenceUsage: true,
+	Args:         noArgs(constants.FastImportCmdName),
+	RunE:         runFastImport,
+}
+
+func init() {
+	rootCmd.AddCommand(fastImportCmd)
+}
+
+// runFastImport replays stdin into the repository.
+func runFastImport(cmd *cobra.Command, args []string) error {
+	layout, err := findRepoRoot()
+	if err != nil {
+		return err
+	}
+
+	store := newObjectStore(layout.GitDir)
+	if err := faststream.Import(cmd.Context(), cmd.InOrStdin(), cmd.OutOrStdout(), layout.GitDir, store); err != nil {
+		return fmt.Errorf("failed to import: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/refs"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// fastImportStream creates main with one file and a tag pointing at it.
+const fastImportStream = `blob
+mark :1
+data 6
+hello
+
+reset refs/heads/main
+commit refs/heads/main
+mark :2
+author Ada <ada@example.com> 1700000000 +0100
+committer Ada <ada@example.com> 1700000000 +0100
+data 6
+first
+M 100644 :1 a.txt
+
+tag v1
+from :2
+tagger Ada <ada@example.com> 1700000300 +0100
+data 8
+release
+
+`
+
+// TestFastImportCommand verifies stream objects are stored and refs updated.
+func TestFastImportCommand(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+
+	output, _, err := runCmdWithInput(t, strings.NewReader(fastImportStream+"progress imported\n"), constants.FastImportCmdName)
+	if err != nil {
+		t.Fatalf("%s command failed: %v", constants.FastImportCmdName, err)
+	}
+	if output != "progress imported\n" {
+		t.Errorf("Expected progress output, got %q", output)
+	}
+
+	// Hashes Git produces for the same stream
+	expected := map[string]string{
+		"refs/heads/main": "d608f04674a22a1965bb12a77fcba6d61ad426e0",
+		"refs/tags/v1":    "09443db5540a72bbafdab3916e99fb9b25a7ae74",
+	}
+	for name, hash := range expected {
+		resolved, err := refs.Resolve(repository.GitDir(repoPath), name)
+		if err != nil {
+			t.Fatalf("Failed to resolve %s: %v", name, err)
+		}
+		if resolved != hash {
+			t.Errorf("Expected %s at %s, got %s", name, hash, resolved)
+		}
+	}
+}
+
+// TestFastImportCommand_Errors verifies arguments and malformed streams are rejected.
+func TestFastImportCommand_Errors(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+
+	if _, _, err := runCmd(t, constants.FastImportCmdName, "extra"); err == nil || !strings.Contains(err.Error(), "accepts no arguments") {
+		t.Errorf("Expected argument error, got %v", err)
+	}
+	if _, _, err := runCmdWithInput(t, strings.NewReader("bogus\n"), constants.FastImportCmdName); err == nil || !strings.Contains(err.Error(), "unsupported command") {
+		t.Errorf("Expected unsupported command error, got %v", err)
+	}
+}
@@ -0,0 +1,419 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/refs"
+	"github.com/KostasZigo/gogit/utils"
+	"github.com/spf13/cobra"
+)
+
+var forEachRefCmd = &cobra.Command{
+	Use:   "for-each-ref [--count=<n>] [--sort=<key>]... [--format=<format>] [<pattern>...]",
+	Short: "List refs with custom formatting and sorting",
+	Long: `List refs matching the given patterns, or all refs, one per line.
+A pattern matches refs equal to it, refs under it up to a slash
+(refs/heads matches refs/heads/main), or refs it matches as a glob.
+
+The format interpolates %(field) atoms, %% and %xx hex escapes. Supported fields:
+  refname, refname:short, objectname, objectname:short, objecttype,
+  subject, contents, and author, committer, tagger or creator followed by
+  name, email or date (creator is the committer of commits and the tagger of tags).
+Prefixing a field with * reads it from the object an annotated tag points at.
+
+Refs are sorted by refname unless --sort is given. Any field can be a sort
+key, as can version:refname (or v:refname), which orders numbers in names
+numerically so v1.10 sorts after v1.9. Prefix a key with - to reverse it;
+with several --sort options the last one is the primary key.
+
+Examples:
+  # List branches, most recently committed first
+  gogit for-each-ref --sort=-committerdate --format='%(refname:short) %(subject)' refs/heads
+
+  # Print the commits annotated tags point at
+  gogit for-each-ref --format='%(refname) %(*objectname)' 'refs/tags/v*'`,
+	SilenceUsage: true,
+	RunE:         runForEachRef,
+}
+
+// forEachRefDefaultFormat matches Git's default for-each-ref output.
+const forEachRefDefaultFormat = "%(objectname) %(objecttype)\t%(refname)"
+
+// forEachRefDateLayout is Git's default date format.
+const forEachRefDateLayout = "Mon Jan 2 15:04:05 2006 -0700"
+
+// forEachRefAbbrevLength is the number of hash characters in short object names.
+const forEachRefAbbrevLength = 7
+
+var (
+	forEachRefFormatFlag string
+	forEachRefSortFlag   []string
+	forEachRefCountFlag  int
+)
+
+func init() {
+	rootCmd.AddCommand(forEachRefCmd)
+
+	forEachRefCmd.Flags().StringVar(&forEachRefFormatFlag, "format", forEachRefDefaultFormat, "Format of each output line, with %(field) atoms")
+	forEachRefCmd.Flags().StringArrayVar(&forEachRefSortFlag, "sort", nil, "Field to sort by, prefixed with - for descending order")
+	forEachRefCmd.Flags().IntVar(&forEachRefCountFlag, "count", 0, "Stop after printing <n> refs")
+}
+
+// runForEachRef lists matching refs, sorted and formatted as requested.
+func runForEachRef(cmd *cobra.Command, patterns []string) error {
+	format, err := parseRefFormat(forEachRefFormatFlag)
+	if err != nil {
+		return err
+	}
+	sortKeys, err := parseRefSortKeys(forEachRefSortFlag)
+	if err != nil {
+		return err
+	}
+
+	layout, err := findRepoRoot()
+	if err != nil {
+		return err
+	}
+	store := newObjectStore(layout.GitDir)
+
+	var infos []*refInfo
+	err = refs.ForEach(layout.GitDir, constants.Refs+"/", func(ref refs.Ref) error {
+		if len(patterns) > 0 && !slices.ContainsFunc(patterns, func(pattern string) bool {
+			return refMatchesPattern(ref.Name, pattern)
+		}) {
+			return nil
+		}
+
+		info, err := loadRefInfo(store, ref.Name, ref.Hash, true)
+		if err != nil {
+			return err
+		}
+		infos = append(infos, info)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sortRefInfos(infos, sortKeys)
+	if forEachRefCountFlag > 0 && len(infos) > forEachRefCountFlag {
+		infos = infos[:forEachRefCountFlag]
+	}
+
+	for _, info := range infos {
+		var line strings.Builder
+		for _, part := range format {
+			if part.atom == "" {
+				line.WriteString(part.literal)
+				continue
+			}
+			line.WriteString(info.field(part.atom))
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), line.String())
+	}
+	return nil
+}
+
+// refMatchesPattern reports whether name equals pattern, lies under it up to a slash, or matches it as a glob.
+func refMatchesPattern(name, pattern string) bool {
+	if name == pattern || strings.HasPrefix(name, strings.TrimSuffix(pattern, "/")+"/") {
+		return true
+	}
+	matched, err := path.Match(pattern, name)
+	return err == nil && matched
+}
+
+// refFormatPart is literal text or a %(field) atom of a format string.
+type refFormatPart struct {
+	literal string
+	atom    string
+}
+
+// parseRefFormat splits format into literal text and validated field atoms.
+func parseRefFormat(format string) ([]refFormatPart, error) {
+	var parts []refFormatPart
+	var literal strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i+1 == len(format) {
+			literal.WriteByte(format[i])
+			continue
+		}
+
+		switch rest := format[i+1:]; {
+		case rest[0] == '%':
+			literal.WriteByte('%')
+			i++
+		case rest[0] == '(':
+			atom, _, ok := strings.Cut(rest[1:], ")")
+			if !ok {
+				return nil, fmt.Errorf("malformed format string %s", format)
+			}
+			if !validRefAtom(atom) {
+				return nil, fmt.Errorf("unknown field name: %s", atom)
+			}
+			parts = append(parts, refFormatPart{literal: literal.String()}, refFormatPart{atom: atom})
+			literal.Reset()
+			i += len(atom) + 2
+		case len(rest) >= 2 && isHexByte(rest[:2]):
+			value, _ := strconv.ParseUint(rest[:2], 16, 8)
+			literal.WriteByte(byte(value))
+			i += 2
+		default:
+			literal.WriteByte('%')
+		}
+	}
+	return append(parts, refFormatPart{literal: literal.String()}), nil
+}
+
+// isHexByte reports whether s is two hexadecimal digits.
+func isHexByte(s string) bool {
+	_, err := strconv.ParseUint(s, 16, 8)
+	return err == nil
+}
+
+// versionSortKey orders ref names with digit runs compared as numbers.
+const versionSortKey = "version:refname"
+
+// refSortKey orders refs by one field.
+type refSortKey struct {
+	atom       string
+	descending bool
+}
+
+// parseRefSortKeys validates --sort values, returning keys with the primary key first.
+func parseRefSortKeys(values []string) ([]refSortKey, error) {
+	keys := make([]refSortKey, 0, len(values)+1)
+	for _, value := range slices.Backward(values) {
+		atom, descending := strings.CutPrefix(value, "-")
+		if atom == "v:refname" {
+			atom = versionSortKey
+		}
+		if atom != versionSortKey && !validRefAtom(atom) {
+			return nil, fmt.Errorf("unknown field name: %s", atom)
+		}
+		keys = append(keys, refSortKey{atom: atom, descending: descending})
+	}
+	// Ties fall back to ref name, as in Git.
+	return append(keys, refSortKey{atom: "refname"}), nil
+}
+
+// sortRefInfos orders infos by keys, the first key taking precedence.
+func sortRefInfos(infos []*refInfo, keys []refSortKey) {
+	slices.SortStableFunc(infos, func(a, b *refInfo) int {
+		for _, key := range keys {
+			var result int
+			if key.atom == versionSortKey {
+				result = compareVersions(a.name, b.name)
+			} else if dateA, ok := a.date(key.atom); ok {
+				dateB, _ := b.date(key.atom)
+				result = dateA.Compare(dateB)
+			} else {
+				result = strings.Compare(a.field(key.atom), b.field(key.atom))
+			}
+			if key.descending {
+				result = -result
+			}
+			if result != 0 {
+				return result
+			}
+		}
+		return 0
+	})
+}
+
+// compareVersions compares a and b bytewise, except that runs of digits are compared as numbers.
+func compareVersions(a, b string) int {
+	for a != "" && b != "" {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			numberA, restA := cutDigits(a)
+			numberB, restB := cutDigits(b)
+			if result := cmp.Or(cmp.Compare(len(numberA), len(numberB)), strings.Compare(numberA, numberB)); result != 0 {
+				return result
+			}
+			a, b = restA, restB
+			continue
+		}
+		if a[0] != b[0] {
+			return cmp.Compare(a[0], b[0])
+		}
+		a, b = a[1:], b[1:]
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+// cutDigits splits the leading run of digits from s, dropping leading zeros of the number.
+func cutDigits(s string) (string, string) {
+	end := 0
+	for end < len(s) && isDigit(s[end]) {
+		end++
+	}
+	return strings.TrimLeft(s[:end], "0"), s[end:]
+}
+
+// isDigit reports whether c is an ASCII digit.
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// refIdentityRoles are the identities a field can read; creator is the committer or tagger.
+var refIdentityRoles = []string{"author", "committer", "tagger", "creator"}
+
+// validRefAtom reports whether atom names a supported field.
+func validRefAtom(atom string) bool {
+	atom = strings.TrimPrefix(atom, "*")
+	switch atom {
+	case "refname", "refname:short", "objectname", "objectname:short", "objecttype", "subject", "contents":
+		return true
+	}
+	for _, role := range refIdentityRoles {
+		if part, ok := strings.CutPrefix(atom, role); ok && (part == "name" || part == "email" || part == "date") {
+			return true
+		}
+	}
+	return false
+}
+
+// refInfo is a ref with the object it points at loaded for formatting.
+type refInfo struct {
+	name       string
+	hash       string
+	objectType utils.ObjectType
+	commit     *objects.Commit
+	tag        *objects.Tag
+	peeled     *refInfo // Object an annotated tag points at, for * fields
+}
+
+// loadRefInfo reads object hash named by ref name. With peel set, the target of a tag is loaded too.
+func loadRefInfo(store *objects.ObjectStore, name, hash string, peel bool) (*refInfo, error) {
+	objectType, err := storedObjectType(store, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object %s of %s: %w", hash, name, err)
+	}
+
+	info := &refInfo{name: name, hash: hash, objectType: objectType}
+	switch objectType {
+	case utils.CommitObjectType:
+		if info.commit, err = store.ReadCommit(hash); err != nil {
+			return nil, err
+		}
+	case utils.TagObjectType:
+		if info.tag, err = store.ReadTag(hash); err != nil {
+			return nil, err
+		}
+		if peel {
+			if info.peeled, err = loadRefInfo(store, name, info.tag.Object(), false); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return info, nil
+}
+
+// field returns value of atom for the ref, empty when the object has no such field.
+func (info *refInfo) field(atom string) string {
+	if derefAtom, ok := strings.CutPrefix(atom, "*"); ok {
+		if info.peeled == nil {
+			return ""
+		}
+		return info.peeled.field(derefAtom)
+	}
+
+	switch atom {
+	case "refname":
+		return info.name
+	case "refname:short":
+		return shortRefName(info.name)
+	case "objectname":
+		return info.hash
+	case "objectname:short":
+		return info.hash[:forEachRefAbbrevLength]
+	case "objecttype":
+		return string(info.objectType)
+	case "subject":
+		switch {
+		case info.commit != nil:
+			return info.commit.Subject()
+		case info.tag != nil:
+			return info.tag.Subject()
+		}
+		return ""
+	case "contents":
+		switch {
+		case info.commit != nil:
+			return info.commit.Message()
+		case info.tag != nil:
+			return info.tag.Message()
+		}
+		return ""
+	}
+
+	for _, role := range refIdentityRoles {
+		part, ok := strings.CutPrefix(atom, role)
+		if !ok {
+			continue
+		}
+		identity, ok := info.identity(role)
+		if !ok {
+			return ""
+		}
+		switch part {
+		case "name":
+			return identity.Name
+		case "email":
+			return "<" + identity.Email + ">"
+		case "date":
+			return identity.Timestamp.Format(forEachRefDateLayout)
+		}
+	}
+	return ""
+}
+
+// date returns the time of a date atom, reporting false for atoms that are not dates.
+// Refs without the identity sort as the zero time.
+func (info *refInfo) date(atom string) (time.Time, bool) {
+	target := info
+	if derefAtom, ok := strings.CutPrefix(atom, "*"); ok {
+		target, atom = info.peeled, derefAtom
+	}
+
+	role, ok := strings.CutSuffix(atom, "date")
+	if !ok {
+		return time.Time{}, false
+	}
+	if target == nil {
+		return time.Time{}, true
+	}
+	identity, _ := target.identity(role)
+	return identity.Timestamp, true
+}
+
+// identity returns the author, committer, tagger or creator of the ref's object.
+func (info *refInfo) identity(role string) (objects.Author, bool) {
+	switch {
+	case info.commit != nil && role == "author":
+		return info.commit.Author(), true
+	case info.commit != nil && (role == "committer" || role == "creator"):
+		return info.commit.Committer(), true
+	case info.tag != nil && (role == "tagger" || role == "creator"):
+		return info.tag.Tagger(), true
+	}
+	return objects.Author{}, false
+}
+
+// shortRefName strips the refs/heads/, refs/tags/, refs/remotes/ or refs/ prefix from name.
+func shortRefName(name string) string {
+	for _, prefix := range []string{constants.BranchRefPrefix, constants.TagRefPrefix, "refs/remotes/", constants.Refs + "/"} {
+		if short, ok := strings.CutPrefix(name, prefix); ok {
+			return cmp.Or(short, name)
+		}
+	}
+	return name
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/refs"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// setupRefHistory stores three commits with main and side branches, an annotated and a lightweight tag.
+func setupRefHistory(t *testing.T) (string, []string) {
+	t.Helper()
+
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	store := objects.NewObjectStore(repository.GitDir(repoPath))
+	history := storeEmptyTreeHistory(t, store, 3)
+	writeTestRef(t, repoPath, constants.BranchRefPrefix+constants.DefaultBranch, history[2])
+	writeTestRef(t, repoPath, constants.BranchRefPrefix+"side", history[0])
+	storeAnnotatedTag(t, repoPath, store, "v1.0", history[1])
+	writeTestRef(t, repoPath, constants.TagRefPrefix+"light", history[2])
+	return repoPath, history
+}
+
+// TestForEachRefCommand verifies default output lists every ref sorted by name.
+func TestForEachRefCommand(t *testing.T) {
+	repoPath, history := setupRefHistory(t)
+
+	output, _, err := runCmd(t, constants.ForEachRefCmdName)
+	if err != nil {
+		t.Fatalf("%s command failed: %v", constants.ForEachRefCmdName, err)
+	}
+
+	tagHash, err := refs.Resolve(repository.GitDir(repoPath), "refs/tags/v1.0")
+	if err != nil {
+		t.Fatalf("Failed to resolve tag: %v", err)
+	}
+	expected := fmt.Sprintf("%s commit\trefs/heads/main\n%s commit\trefs/heads/side\n%s commit\trefs/tags/light\n%s tag\trefs/tags/v1.0\n",
+		history[2], history[0], history[2], tagHash)
+	if output != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, output)
+	}
+}
+
+// TestForEachRefCommand_FormatSortCount verifies format atoms, dereferenced fields, sorting and patterns.
+func TestForEachRefCommand_FormatSortCount(t *testing.T) {
+	repoPath, history := setupRefHistory(t)
+	tagHash, err := refs.Resolve(repository.GitDir(repoPath), "refs/tags/v1.0")
+	if err != nil {
+		t.Fatalf("Failed to resolve tag: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "descending creator date, ties by name",
+			args:     []string{"--sort=-creatordate", "--format=%(refname:short) %(objectname:short)"},
+			expected: fmt.Sprintf("main %s\nlight %s\nside %s\nv1.0 %s\n", history[2][:7], history[2][:7], history[0][:7], tagHash[:7]),
+		},
+		{
+			name:     "dereferenced tag fields",
+			args:     []string{"--format=%(refname:short)%09%(taggername) %(*objectname) %(*subject)%%", "refs/tags/v*"},
+			expected: fmt.Sprintf("v1.0\tT %s commit%%\n", history[1]),
+		},
+		{
+			name:     "prefix pattern with count",
+			args:     []string{"--count=1", "--sort=-refname", "--format=%(refname)", "refs/heads"},
+			expected: "refs/heads/side\n",
+		},
+		{
+			name:     "identity fields",
+			args:     []string{"--format=%(authorname) %(authoremail) %(committerdate)", constants.BranchRefPrefix + "side"},
+			expected: "A <a@example.com> Tue Nov 14 22:13:20 2023 +0000\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, _, err := runCmd(t, constants.ForEachRefCmdName, tt.args...)
+			if err != nil {
+				t.Fatalf("%s command failed: %v", constants.ForEachRefCmdName, err)
+			}
+			if output != tt.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.expected, output)
+			}
+		})
+	}
+}
+
+// TestForEachRefCommand_InvalidFields verifies unknown format and sort fields are rejected.
+func TestForEachRefCommand_InvalidFields(t *testing.T) {
+	setupRefHistory(t)
+
+	for _, args := range [][]string{{"--format=%(bogus)"}, {"--sort=bogus"}, {"--format=%(refname"}} {
+		if _, _, err := runCmd(t, constants.ForEachRefCmdName, args...); err == nil {
+			t.Errorf("Expected error for %v", args)
+		}
+	}
+}
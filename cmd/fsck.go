@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/utils"
+	"github.com/spf13/cobra"
+)
+
+var fsckCmd = &cobra.Command{
+	Use:   "fsck [--unreachable] [--lost-found]",
+	Short: "Verify the objects of the repository and find lost ones",
+	Long: `Read every loose object and check that its content hashes to its name, then
+walk the history reachable from HEAD, the refs, the index and the reflogs.
+
+Problems are reported as "error: <hash>: <reason>" on stderr for objects
+that cannot be read and "missing object <hash>" for objects that are pointed
+to but absent, and make fsck fail.
+
+Unreachable objects that no other unreachable object points to are listed as
+"dangling <type> <hash>": the tips of lost history, such as commits left
+behind by a reset. --unreachable lists every unreachable object instead.
+--lost-found also writes each dangling commit to lost-found/commit/<hash> and
+other dangling objects to lost-found/other/<hash> in the metadata directory:
+the contents of blobs, the names of the rest. gogit recover lists dangling
+commits with their messages.
+
+Examples:
+  # Check the repository
+  gogit fsck
+
+  # Collect dangling objects for inspection
+  gogit fsck --lost-found`,
+	SilenceUsage: true,
+	Args:         noArgs(constants.FsckCmdName),
+	RunE:         runFsck,
+}
+
+// lostFoundDir holds the objects written by fsck --lost-found, inside the metadata directory.
+const lostFoundDir = "lost-found"
+
+var (
+	fsckUnreachableFlag bool
+	fsckLostFoundFlag   bool
+)
+
+func init() {
+	rootCmd.AddCommand(fsckCmd)
+
+	fsckCmd.Flags().BoolVar(&fsckUnreachableFlag, "unreachable", false, "List every unreachable object, not only dangling ones")
+	fsckCmd.Flags().BoolVar(&fsckLostFoundFlag, "lost-found", false, "Write dangling objects to lost-found")
+}
+
+// runFsck checks every loose object and the connectivity of reachable history, and reports
+// unreachable objects.
+func runFsck(cmd *cobra.Command, args []string) error {
+	layout, err := findRepoRoot()
+	if err != nil {
+		return err
+	}
+	// Both the replaced objects and their replacements are stored and checked
+	store := objects.NewObjectStore(layout.GitDir, objects.WithReplaceObjects(false))
+	out := cmd.OutOrStdout()
+
+	broken := 0
+	err = store.ForEachObject(cmd.Context(), func(hash string) error {
+		if _, _, err := readCheckedObject(store, hash); err != nil {
+			cmd.PrintErrf("error: %s: %v\n", hash, err)
+			broken++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	err = markReachable(layout.GitDir, store, seen, func(hash string, err error) error {
+		// Stored objects that cannot be read were reported above
+		if errors.Is(err, objects.ErrObjectNotFound) {
+			fmt.Fprintf(out, "missing object %s\n", hash)
+			broken++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	unreachable, err := findUnreachable(cmd.Context(), store, seen)
+	if err != nil {
+		return err
+	}
+	for _, object := range unreachable {
+		if fsckUnreachableFlag {
+			fmt.Fprintf(out, "unreachable %s %s\n", object.objectType, object.hash)
+		}
+		if !object.dangling {
+			continue
+		}
+		if !fsckUnreachableFlag {
+			fmt.Fprintf(out, "dangling %s %s\n", object.objectType, object.hash)
+		}
+		if fsckLostFoundFlag {
+			if err := writeLostFound(layout.GitDir, store, object); err != nil {
+				return err
+			}
+		}
+	}
+
+	if broken > 0 {
+		return fmt.Errorf("found %d broken or missing objects", broken)
+	}
+	return nil
+}
+
+// unreachableObject is a stored object outside the reachable history.
+type unreachableObject struct {
+	hash       string
+	objectType utils.ObjectType
+	dangling   bool // No other unreachable object points to it
+}
+
+// findUnreachable returns the readable loose objects outside seen, in hash order.
+func findUnreachable(ctx context.Context, store *objects.ObjectStore, seen map[string]bool) ([]unreachableObject, error) {
+	var unreachable []unreachableObject
+	referenced := make(map[string]bool)
+	err := store.ForEachObject(ctx, func(hash string) error {
+		if seen[hash] {
+			return nil
+		}
+		object, objectType, err := readCheckedObject(store, hash)
+		if err != nil {
+			return nil
+		}
+		unreachable = append(unreachable, unreachableObject{hash: hash, objectType: objectType})
+		for _, target := range objects.References(object) {
+			referenced[target] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range unreachable {
+		unreachable[i].dangling = !referenced[unreachable[i].hash]
+	}
+	return unreachable, nil
+}
+
+// readCheckedObject reads the stored object hash, verifying that its content hashes to its name.
+// The stored empty tree, which tree parsing refuses, comes back as a nil tree.
+func readCheckedObject(store *objects.ObjectStore, hash string) (objects.Object, utils.ObjectType, error) {
+	if hash != constants.EmptyTreeHash {
+		return store.ReadObject(hash)
+	}
+
+	reader, err := store.OpenObject(hash)
+	if err != nil {
+		return nil, "", err
+	}
+	defer reader.Close()
+	if _, err := io.Copy(io.Discard, reader); err != nil {
+		return nil, "", err
+	}
+	return nil, reader.Type(), nil
+}
+
+// writeLostFound saves a dangling object under lost-found, in commit/ for commits and other/
+// for the rest: the content of blobs, the name of anything else.
+func writeLostFound(gitDir string, store *objects.ObjectStore, object unreachableObject) error {
+	subdir, content := "other", []byte(object.hash+"\n")
+	switch object.objectType {
+	case utils.CommitObjectType:
+		subdir = "commit"
+	case utils.BlobObjectType:
+		reader, err := store.OpenObject(object.hash)
+		if err != nil {
+			return err
+		}
+		content, err = io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read object %s: %w", object.hash, err)
+		}
+	}
+
+	dir := filepath.Join(gitDir, lostFoundDir, subdir)
+	if err := os.MkdirAll(dir, constants.DirPerms); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, object.hash), content, constants.FilePerms); err != nil {
+		return fmt.Errorf("failed to write lost-found entry: %w", err)
+	}
+	return nil
+}
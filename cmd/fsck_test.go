@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// storeLostCommit stores a commit on the empty tree that no ref points to, committed at the
+// Unix time when, and returns its hash.
+func storeLostCommit(t *testing.T, store *objects.ObjectStore, parent, message string, when int64) string {
+	t.Helper()
+
+	author := objects.Author{Name: "A", Email: "a@example.com", Timestamp: time.Unix(when, 0).UTC()}
+	commit, err := objects.NewCommit(constants.EmptyTreeHash, parent, message, author)
+	if err != nil {
+		t.Fatalf("Failed to create commit: %v", err)
+	}
+	if err := store.Store(commit); err != nil {
+		t.Fatalf("Failed to store commit: %v", err)
+	}
+	return commit.Hash()
+}
+
+// sortedLines joins lines sorted by the hash ending each, as fsck lists objects in hash order.
+func sortedLines(lines ...string) string {
+	slices.SortFunc(lines, func(a, b string) int {
+		return strings.Compare(a[strings.LastIndex(a, " "):], b[strings.LastIndex(b, " "):])
+	})
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// TestFsckCommand verifies only the tips of unreachable history are dangling, --unreachable lists
+// all of it and --lost-found saves the dangling objects.
+func TestFsckCommand(t *testing.T) {
+	repoPath, history := setupRefHistory(t)
+	store := objects.NewObjectStore(repository.GitDir(repoPath))
+	lost := storeLostCommit(t, store, history[2], "lost work", 1800000000)
+	lostTip := storeLostCommit(t, store, lost, "more lost work", 1800000100)
+	blob := storeTestBlobs(t, repoPath, "lost content\n")[0]
+
+	stdout, stderr, err := runCmd(t, constants.FsckCmdName)
+	if err != nil {
+		t.Fatalf("%s command failed: %v\n%s", constants.FsckCmdName, err, stderr)
+	}
+	expected := sortedLines("dangling commit "+lostTip, "dangling blob "+blob)
+	if stdout != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, stdout)
+	}
+
+	stdout, _, err = runCmd(t, constants.FsckCmdName, "--unreachable")
+	expected = sortedLines("unreachable commit "+lost, "unreachable commit "+lostTip, "unreachable blob "+blob)
+	if err != nil || stdout != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s%v", expected, stdout, err)
+	}
+
+	if _, _, err := runCmd(t, constants.FsckCmdName, "--lost-found"); err != nil {
+		t.Fatalf("%s --lost-found failed: %v", constants.FsckCmdName, err)
+	}
+	lostFound := filepath.Join(repoPath, constants.Gogit, lostFoundDir)
+	for path, expected := range map[string]string{
+		filepath.Join(lostFound, "commit", lostTip): lostTip + "\n",
+		filepath.Join(lostFound, "other", blob):     "lost content\n",
+	} {
+		if content, err := os.ReadFile(path); err != nil || string(content) != expected {
+			t.Errorf("Expected %s to hold %q, got %q, %v", path, expected, content, err)
+		}
+	}
+	testutils.AssertFileNotExists(t, filepath.Join(lostFound, "commit", lost))
+}
+
+// TestFsckCommand_Broken verifies corrupt and missing objects are reported and fail fsck.
+func TestFsckCommand_Broken(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	store := objects.NewObjectStore(repository.GitDir(repoPath))
+	missing := testutils.RandomHash()
+	writeTestRef(t, repoPath, constants.BranchRefPrefix+constants.DefaultBranch, storeLostCommit(t, store, missing, "child", 1700000000))
+
+	corrupt := storeTestBlobs(t, repoPath, "corrupt me\n")[0]
+	objectPath := filepath.Join(repoPath, constants.Gogit, constants.Objects, corrupt[:constants.HashDirPrefixLength], corrupt[constants.HashDirPrefixLength:])
+	os.Chmod(objectPath, constants.FilePerms)
+	if err := os.WriteFile(objectPath, []byte("garbage"), constants.FilePerms); err != nil {
+		t.Fatalf("Failed to corrupt object: %v", err)
+	}
+
+	stdout, stderr, err := runCmd(t, constants.FsckCmdName)
+	if err == nil {
+		t.Fatal("Expected fsck to fail")
+	}
+	if stdout != "missing object "+missing+"\n" {
+		t.Errorf("Expected missing parent reported, got %q", stdout)
+	}
+	if !strings.HasPrefix(stderr, "error: "+corrupt+": ") {
+		t.Errorf("Expected corrupt object reported, got %q", stderr)
+	}
+}
@@ -1,98 +1,195 @@
 package cmd
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
+	"io"
 	"os"
-	"path/filepath"
+	"strings"
 
 	"github.com/KostasZigo/gogit/internal/constants"
 	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/utils"
 	"github.com/spf13/cobra"
 )
 
 var hashObjectCmd = &cobra.Command{
-	Use:   "hash-object <filepath>",
+	Use:   "hash-object [-t <type>] [-w] [--stdin] [--stdin-paths] [<filepath>...]",
 	Short: "Compute object hash and optionally create and store a blob from a file",
 	Long: `Compute the object hash (SHA-1 hash) for a file's content.
 Optionally write the resulting object's blob into the objects folder.
+One hash is printed per line, in the order inputs are given.
+
+With -t, content is treated as a raw tree, commit or tag object instead of a blob.
+Such content is validated before hashing so malformed objects are never written.
 
 Examples:
   # Compute hash without storing
   gogit hash-object myfile.txt
 
   # Compute hash and store in .gogit/objects
-  gogit hash-object -w myfile.txt`,
+  gogit hash-object -w myfile.txt
+
+  # Hash several files at once
+  gogit hash-object a.txt b.txt
+
+  # Hash content piped on standard input
+  echo "hello" | gogit hash-object --stdin
+
+  # Hash every file listed on standard input, one path per line
+  find . -name '*.go' | gogit hash-object --stdin-paths
+
+  # Store raw commit content as a commit object
+  gogit hash-object -t commit -w --stdin < commit.txt`,
 	SilenceUsage: true,
-	Args:         exactArgs(1),
+	Args:         hashObjectArgs,
 	RunE:         runHashObject,
 }
 
-var writeFlag bool
+var (
+	writeFlag      bool
+	stdinFlag      bool
+	stdinPathsFlag bool
+	objectTypeFlag string
+)
 
 func init() {
 	rootCmd.AddCommand(hashObjectCmd)
 
 	// Add flag using Cobra's flag system
 	hashObjectCmd.Flags().BoolVarP(&writeFlag, "write", "w", false, "Write the object into the objects folder")
+	hashObjectCmd.Flags().BoolVar(&stdinFlag, "stdin", false, "Read the object content from standard input")
+	hashObjectCmd.Flags().BoolVar(&stdinPathsFlag, "stdin-paths", false, "Read file paths from standard input, one per line")
+	hashObjectCmd.Flags().StringVarP(&objectTypeFlag, "type", "t", string(utils.BlobObjectType), "Type of object to create (blob, tree, commit, tag)")
 }
 
-// exactArgs validates command receives exactly n positional arguments.
-// enables usage printing in case of error
-func exactArgs(n int) cobra.PositionalArgs {
-	return func(cmd *cobra.Command, args []string) error {
-		if len(args) != n {
-			cmd.SilenceUsage = false
-			return fmt.Errorf("%s command requires exactly %d argument (filepath), received %d", constants.HashObjectCmdName, n, len(args))
-		}
-		return nil
+// hashObjectArgs validates input sources of hash-object.
+// Enables usage printing in case of error.
+func hashObjectArgs(cmd *cobra.Command, args []string) error {
+	switch {
+	case !utils.ObjectType(objectTypeFlag).IsValid():
+		cmd.SilenceUsage = false
+		return fmt.Errorf("%s: invalid object type %q", constants.HashObjectCmdName, objectTypeFlag)
+	case stdinFlag && stdinPathsFlag:
+		cmd.SilenceUsage = false
+		return fmt.Errorf("%s cannot combine --stdin with --stdin-paths", constants.HashObjectCmdName)
+	case stdinPathsFlag && len(args) > 0:
+		cmd.SilenceUsage = false
+		return fmt.Errorf("%s cannot combine --stdin-paths with file arguments", constants.HashObjectCmdName)
+	case !stdinFlag && !stdinPathsFlag && len(args) == 0:
+		cmd.SilenceUsage = false
+		return fmt.Errorf("%s command requires at least 1 argument (filepath) or --stdin/--stdin-paths, received 0", constants.HashObjectCmdName)
 	}
+	return nil
 }
 
-// runHashObject computes hash and optionally stores blob object.
+// runHashObject computes hashes and optionally stores blob objects.
+// File content is streamed, so memory use stays constant for large files.
 func runHashObject(cmd *cobra.Command, args []string) error {
-	// Create blob from file's contents]
-	blob, err := objects.NewBlobFromFile(args[0])
-	if err != nil {
-		return err
+	var store *objects.ObjectStore
+	if writeFlag {
+		layout, err := findRepoRoot()
+		if err != nil {
+			return err
+		}
+		store = newObjectStore(layout.GitDir)
 	}
 
-	// Print hash to stdout
-	fmt.Fprintln(cmd.OutOrStdout(), blob.Hash())
+	out := cmd.OutOrStdout()
+	objectType := utils.ObjectType(objectTypeFlag)
 
-	if writeFlag {
-		repoPath, err := findRepoRoot()
+	if stdinFlag {
+		hash, err := hashOrStoreReader(store, objectType, cmd.InOrStdin())
 		if err != nil {
 			return err
 		}
+		fmt.Fprintln(out, hash)
+	}
 
-		store := objects.NewObjectStore(repoPath)
-		if err := store.Store(blob); err != nil {
-			return fmt.Errorf("failed to store object: %w", err)
+	paths := args
+	if stdinPathsFlag {
+		var err error
+		paths, err = readStdinPaths(cmd.InOrStdin())
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, path := range paths {
+		hash, err := hashOrStoreFile(store, objectType, path)
+		if err != nil {
+			return err
 		}
+		// Print hash to stdout
+		fmt.Fprintln(out, hash)
 	}
 
 	return nil
 }
 
-// findRepoRoot locates .gogit directory by walking up directory tree.
-func findRepoRoot() (string, error) {
-	dir, err := os.Getwd()
+// readStdinPaths reads newline-separated paths, skipping empty lines.
+func readStdinPaths(reader io.Reader) ([]string, error) {
+	var paths []string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		if path := strings.TrimSuffix(scanner.Text(), "\r"); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read paths from stdin: %w", err)
+	}
+	return paths, nil
+}
+
+// hashOrStoreReader hashes all content from reader, storing it when store is set.
+// Standard input has no known size, so content is buffered before hashing.
+func hashOrStoreReader(store *objects.ObjectStore, objectType utils.ObjectType, reader io.Reader) (string, error) {
+	content, err := io.ReadAll(reader)
 	if err != nil {
+		return "", fmt.Errorf("failed to read stdin: %w", err)
+	}
+
+	return hashOrStoreContent(store, objectType, content)
+}
+
+// hashOrStoreContent validates and hashes in-memory content, storing it when store is set.
+func hashOrStoreContent(store *objects.ObjectStore, objectType utils.ObjectType, content []byte) (string, error) {
+	if err := objects.ValidateContent(objectType, content); err != nil {
 		return "", err
 	}
 
-	for {
-		gogitPath := filepath.Join(dir, constants.Gogit)
-		if info, err := os.Stat(gogitPath); err == nil && info.IsDir() {
-			return dir, nil
-		}
+	if store == nil {
+		return utils.ComputeHash(content, objectType)
+	}
 
-		// Dir returns all but the last element of path
-		parent := filepath.Dir(dir)
-		if parent == dir {
-			// Reached root without finding .gogit
-			return "", fmt.Errorf("%s directory not found", constants.Gogit)
+	hash, err := store.StoreStream(objectType, int64(len(content)), bytes.NewReader(content))
+	if err != nil {
+		return "", fmt.Errorf("failed to store object: %w", err)
+	}
+	return hash, nil
+}
+
+// hashOrStoreFile hashes file content, storing it when store is set.
+// Blobs are streamed; other types are read whole since they must be validated first.
+func hashOrStoreFile(store *objects.ObjectStore, objectType utils.ObjectType, path string) (string, error) {
+	if objectType != utils.BlobObjectType {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read file %s: %w", path, err)
 		}
-		dir = parent
+		return hashOrStoreContent(store, objectType, content)
 	}
+
+	if store == nil {
+		return objects.HashBlobFile(path)
+	}
+
+	hash, err := store.StoreBlobFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to store object: %w", err)
+	}
+
+	return hash, nil
 }
@@ -12,6 +12,7 @@ import (
 
 	"github.com/KostasZigo/gogit/internal/constants"
 	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/repository"
 	"github.com/KostasZigo/gogit/testutils"
 	"github.com/KostasZigo/gogit/utils"
 	"github.com/agiledragon/gomonkey/v2"
@@ -93,7 +94,7 @@ func TestHashObjectCommand_Success_WithStorage(t *testing.T) {
 	testutils.AssertFileExists(t, objectPath)
 
 	// Verify object can be read back
-	store := objects.NewObjectStore(repoPath)
+	store := objects.NewObjectStore(repository.GitDir(repoPath))
 	blob, err := store.ReadBlob(expectedHash)
 	if err != nil {
 		t.Errorf("Failed to read stored blob: %v", err)
@@ -131,9 +132,10 @@ func TestHashObject_FileNotFound(t *testing.T) {
 	}
 }
 
-// TestHashObjectCommand_NoArguments verifies error when no arguments provided.
+// TestHashObjectCommand_NoArguments verifies error when no input source provided.
 func TestHashObjectCommand_NoArguments(t *testing.T) {
 	testRootCmd := createTestRootCmd(hashObjectCmd)
+	resetFlags(t, hashObjectCmd)
 	captureStderr(testRootCmd)
 	captureStdout(testRootCmd)
 
@@ -146,30 +148,111 @@ func TestHashObjectCommand_NoArguments(t *testing.T) {
 	}
 
 	// Verify error message matches argument validation error
-	expectedErrorMessage := fmt.Sprintf("%s command requires exactly 1 argument (filepath), received 0", constants.HashObjectCmdName)
+	expectedErrorMessage := fmt.Sprintf("%s command requires at least 1 argument (filepath) or --stdin/--stdin-paths, received 0", constants.HashObjectCmdName)
 	if !strings.Contains(err.Error(), expectedErrorMessage) {
 		t.Fatalf("Expected error message to contain [%s] but got error message [%s]", expectedErrorMessage, err.Error())
 	}
 }
 
-// TestHashObjectCommand_TooManyArguments verifies error when too many arguments provided.
-func TestHashObjectCommand_TooManyArguments(t *testing.T) {
+// TestHashObjectCommand_MultiplePaths verifies one hash is printed per file, in argument order.
+func TestHashObjectCommand_MultiplePaths(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	changeToRepoDir(t, repoPath)
+
+	contents := [][]byte{[]byte("first\n"), []byte("second\n")}
+	testutils.CreateTestFile(t, repoPath, "a.txt", contents[0])
+	testutils.CreateTestFile(t, repoPath, "b.txt", contents[1])
+
 	testRootCmd := createTestRootCmd(hashObjectCmd)
-	captureStderr(testRootCmd)
-	captureStdout(testRootCmd)
+	resetFlags(t, hashObjectCmd)
+	stdout := captureStdout(testRootCmd)
 
-	// Execute hash-object command with too many arguments
-	testRootCmd.SetArgs([]string{constants.HashObjectCmdName, "a.txt", "b.txt"})
-	err := testRootCmd.Execute()
+	testRootCmd.SetArgs([]string{constants.HashObjectCmdName, "-w", "a.txt", "b.txt"})
+	if err := testRootCmd.Execute(); err != nil {
+		t.Fatalf("%s command failed: %v", constants.HashObjectCmdName, err)
+	}
 
-	if err == nil {
-		t.Fatal("Expected error when too many arguments are provided")
+	assertHashLines(t, stdout.String(), contents...)
+	store := objects.NewObjectStore(repository.GitDir(repoPath))
+	for _, content := range contents {
+		if !store.Exists(objects.NewBlob(content).Hash()) {
+			t.Errorf("Expected blob for %q to be stored", content)
+		}
 	}
+}
 
-	// Verify error message matches argument validation error
-	expectedErrorMessage := fmt.Sprintf("%s command requires exactly 1 argument (filepath), received 2", constants.HashObjectCmdName)
-	if !strings.Contains(err.Error(), expectedErrorMessage) {
-		t.Fatalf("Expected error message to contain [%s] but got error message [%s]", expectedErrorMessage, err.Error())
+// TestHashObjectCommand_Stdin verifies content is read from stdin before any file arguments.
+func TestHashObjectCommand_Stdin(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	changeToRepoDir(t, repoPath)
+
+	fileContent := []byte("from file\n")
+	stdinContent := []byte("from stdin\n")
+	testutils.CreateTestFile(t, repoPath, "file.txt", fileContent)
+
+	testRootCmd := createTestRootCmd(hashObjectCmd)
+	resetFlags(t, hashObjectCmd)
+	stdout := captureStdout(testRootCmd)
+	testRootCmd.SetIn(bytes.NewReader(stdinContent))
+
+	testRootCmd.SetArgs([]string{constants.HashObjectCmdName, "--stdin", "-w", "file.txt"})
+	if err := testRootCmd.Execute(); err != nil {
+		t.Fatalf("%s command failed: %v", constants.HashObjectCmdName, err)
+	}
+
+	assertHashLines(t, stdout.String(), stdinContent, fileContent)
+	if !objects.NewObjectStore(repository.GitDir(repoPath)).Exists(objects.NewBlob(stdinContent).Hash()) {
+		t.Error("Expected stdin blob to be stored")
+	}
+}
+
+// TestHashObjectCommand_StdinPaths verifies paths listed on stdin are hashed in order.
+func TestHashObjectCommand_StdinPaths(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	changeToRepoDir(t, repoPath)
+
+	contents := [][]byte{[]byte("one"), []byte("two"), []byte("three")}
+	for i, name := range []string{"one.txt", "two.txt", "with space.txt"} {
+		testutils.CreateTestFile(t, repoPath, name, contents[i])
+	}
+
+	testRootCmd := createTestRootCmd(hashObjectCmd)
+	resetFlags(t, hashObjectCmd)
+	stdout := captureStdout(testRootCmd)
+	testRootCmd.SetIn(strings.NewReader("one.txt\ntwo.txt\n\nwith space.txt\n"))
+
+	testRootCmd.SetArgs([]string{constants.HashObjectCmdName, "--stdin-paths"})
+	if err := testRootCmd.Execute(); err != nil {
+		t.Fatalf("%s command failed: %v", constants.HashObjectCmdName, err)
+	}
+
+	assertHashLines(t, stdout.String(), contents...)
+}
+
+// TestHashObjectCommand_ConflictingInputs verifies incompatible input sources are rejected.
+func TestHashObjectCommand_ConflictingInputs(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		expectedError string
+	}{
+		{"stdin with stdin-paths", []string{"--stdin", "--stdin-paths"}, "cannot combine --stdin with --stdin-paths"},
+		{"stdin-paths with files", []string{"--stdin-paths", "a.txt"}, "cannot combine --stdin-paths with file arguments"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testRootCmd := createTestRootCmd(hashObjectCmd)
+			resetFlags(t, hashObjectCmd)
+			captureStderr(testRootCmd)
+			captureStdout(testRootCmd)
+
+			testRootCmd.SetArgs(append([]string{constants.HashObjectCmdName}, tt.args...))
+			err := testRootCmd.Execute()
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Fatalf("Expected error containing [%s], got %v", tt.expectedError, err)
+			}
+		})
 	}
 }
 
@@ -211,11 +294,11 @@ func TestHashObjectCommand_StoreFailure(t *testing.T) {
 	testFileContent := []byte("Charmander user Ember !")
 	testutils.CreateTestFile(t, repoPath, testFileName, testFileContent)
 
-	// Mock ObjectStore.Store failure
+	// Mock ObjectStore.StoreBlobFile failure
 	mockError := errors.New("failed to store blob to .gogit/objects")
-	patches := gomonkey.ApplyMethod(&objects.ObjectStore{}, "Store",
-		func(_ *objects.ObjectStore, _ objects.Object) error {
-			return mockError
+	patches := gomonkey.ApplyMethod(&objects.ObjectStore{}, "StoreBlobFile",
+		func(_ *objects.ObjectStore, _ string) (string, error) {
+			return "", mockError
 		})
 	defer patches.Reset()
 
@@ -238,8 +321,8 @@ func TestHashObjectCommand_StoreFailure(t *testing.T) {
 	}
 }
 
-// TestHashObjectCommand_NewBlobFromFileFailure verifies error handling when blob creation fails.
-func TestHashObjectCommand_NewBlobFromFileFailure(t *testing.T) {
+// TestHashObjectCommand_HashBlobFileFailure verifies error handling when hashing fails.
+func TestHashObjectCommand_HashBlobFileFailure(t *testing.T) {
 	repoPath := testutils.SetupTestRepoWithGogitDir(t)
 	changeToRepoDir(t, repoPath)
 
@@ -249,20 +332,21 @@ func TestHashObjectCommand_NewBlobFromFileFailure(t *testing.T) {
 	testutils.CreateTestFile(t, repoPath, testFileName, testFileContent)
 
 	// Mock failure
-	mockError := errors.New("failed to create new blob from file")
-	patches := gomonkey.ApplyFunc(objects.NewBlobFromFile,
-		func(_ string) (*objects.Blob, error) {
-			return nil, mockError
+	mockError := errors.New("failed to hash blob file")
+	patches := gomonkey.ApplyFunc(objects.HashBlobFile,
+		func(_ string) (string, error) {
+			return "", mockError
 		})
 	defer patches.Reset()
 
 	testRootCmd := createTestRootCmd(hashObjectCmd)
+	resetFlags(t, hashObjectCmd)
 	captureStderr(testRootCmd)
 	captureStdout(testRootCmd)
 
-	// Execute hash-object command with write directive
-	// Store is only executed when we are storing the blob
-	testRootCmd.SetArgs([]string{constants.HashObjectCmdName, testFileName, "-w"})
+	// Execute hash-object command without write directive
+	// HashBlobFile is only executed when the blob is not stored
+	testRootCmd.SetArgs([]string{constants.HashObjectCmdName, testFileName})
 	err := testRootCmd.Execute()
 
 	if err == nil {
@@ -382,3 +466,73 @@ func TestHashObjectCommand_LargeFile(t *testing.T) {
 	objectPath := filepath.Join(repoPath, constants.Gogit, constants.Objects, outputHash[:constants.HashDirPrefixLength], outputHash[constants.HashDirPrefixLength:])
 	testutils.AssertFileExists(t, objectPath)
 }
+
+// TestHashObjectCommand_Type verifies -t hashes and stores validated non-blob objects.
+func TestHashObjectCommand_Type(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	changeToRepoDir(t, repoPath)
+
+	blobHash := objects.NewBlob([]byte("x")).Hash()
+	tagContent := []byte("object " + blobHash + "\ntype blob\ntag v1\ntagger A <a@b.c> 1700000000 +0000\n\nmsg\n")
+	testutils.CreateTestFile(t, repoPath, "tag.txt", tagContent)
+
+	testRootCmd := createTestRootCmd(hashObjectCmd)
+	resetFlags(t, hashObjectCmd)
+	stdout := captureStdout(testRootCmd)
+
+	testRootCmd.SetArgs([]string{constants.HashObjectCmdName, "-t", "tag", "-w", "tag.txt"})
+	if err := testRootCmd.Execute(); err != nil {
+		t.Fatalf("%s command failed: %v", constants.HashObjectCmdName, err)
+	}
+
+	expectedHash := utils.MustComputeHash(tagContent, utils.TagObjectType)
+	if outputHash := strings.TrimSpace(stdout.String()); outputHash != expectedHash {
+		t.Fatalf("Expected hash %s, got %s", expectedHash, outputHash)
+	}
+	if _, err := objects.NewObjectStore(repository.GitDir(repoPath)).ReadTag(expectedHash); err != nil {
+		t.Errorf("Failed to read stored tag: %v", err)
+	}
+}
+
+// TestHashObjectCommand_Type_Errors verifies unknown types and malformed content are rejected.
+func TestHashObjectCommand_Type_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		expectedError string
+	}{
+		{"unknown type", []string{"-t", "bogus", "--stdin"}, "invalid object type"},
+		{"malformed commit", []string{"-t", "commit", "--stdin"}, "invalid commit object"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testRootCmd := createTestRootCmd(hashObjectCmd)
+			resetFlags(t, hashObjectCmd)
+			captureStderr(testRootCmd)
+			captureStdout(testRootCmd)
+			testRootCmd.SetIn(strings.NewReader("not a commit\n"))
+
+			testRootCmd.SetArgs(append([]string{constants.HashObjectCmdName}, tt.args...))
+			err := testRootCmd.Execute()
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Fatalf("Expected error containing [%s], got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+// assertHashLines verifies output holds one blob hash per line for contents, in order.
+func assertHashLines(t *testing.T, output string, contents ...[]byte) {
+	t.Helper()
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != len(contents) {
+		t.Fatalf("Expected %d hashes, got %d: %q", len(contents), len(lines), output)
+	}
+	for i, content := range contents {
+		if expected := objects.NewBlob(content).Hash(); lines[i] != expected {
+			t.Errorf("Line %d: expected hash %s, got %s", i+1, expected, lines[i])
+		}
+	}
+}
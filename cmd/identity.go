@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+)
+
+// currentAuthor returns identity for new objects, stamped with the current time.
+// Environment overrides win; otherwise the name and email are derived from the OS user.
+func currentAuthor() (objects.Author, error) {
+	name := os.Getenv(constants.AuthorNameEnv)
+	email := os.Getenv(constants.AuthorEmailEnv)
+
+	if name == "" || email == "" {
+		current, err := user.Current()
+		if err != nil {
+			return objects.Author{}, fmt.Errorf("unable to determine author identity; set %s and %s: %w",
+				constants.AuthorNameEnv, constants.AuthorEmailEnv, err)
+		}
+		if name == "" {
+			name = current.Name
+			if name == "" {
+				name = current.Username
+			}
+		}
+		if email == "" {
+			hostname, err := os.Hostname()
+			if err != nil {
+				return objects.Author{}, fmt.Errorf("unable to determine author email; set %s: %w", constants.AuthorEmailEnv, err)
+			}
+			email = current.Username + "@" + hostname
+		}
+	}
+
+	return objects.Author{Name: name, Email: email, Timestamp: time.Now()}, nil
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/lockfile"
+	"github.com/KostasZigo/gogit/internal/pack"
+	"github.com/spf13/cobra"
+)
+
+var indexPackCmd = &cobra.Command{
+	Use:   "index-pack [-o <index-file>] <pack-file>",
+	Short: "Build the index of a packfile",
+	Long: `Read a packfile, check its checksum and the size of every object, resolve its
+deltas, and write the version 2 index describing it next to it (or to the
+-o file). The checksum of the pack is printed.
+
+The pack must be complete: deltas against objects outside it are rejected.
+No repository is needed.
+
+Examples:
+  # Index a pack received from elsewhere
+  gogit index-pack pack-1a2b3c.pack`,
+	SilenceUsage: true,
+	Args:         indexPackArgs,
+	RunE:         runIndexPack,
+}
+
+// packSuffix and packIndexSuffix end the names of packfiles and their indexes.
+const (
+	packSuffix      = ".pack"
+	packIndexSuffix = ".idx"
+)
+
+var indexPackOutputFlag string
+
+func init() {
+	rootCmd.AddCommand(indexPackCmd)
+
+	indexPackCmd.Flags().StringVarP(&indexPackOutputFlag, "output", "o", "", "Write the index to <index-file>")
+}
+
+// indexPackArgs requires exactly one packfile.
+// Enables usage printing in case of error.
+func indexPackArgs(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		cmd.SilenceUsage = false
+		return fmt.Errorf("%s command requires exactly 1 argument (pack-file), received %d", constants.IndexPackCmdName, len(args))
+	}
+	return nil
+}
+
+// runIndexPack parses the packfile and writes its index.
+func runIndexPack(cmd *cobra.Command, args []string) error {
+	packPath := args[0]
+	indexPath := indexPackOutputFlag
+	if indexPath == "" {
+		base, ok := strings.CutSuffix(packPath, packSuffix)
+		if !ok {
+			return fmt.Errorf("packfile name '%s' does not end with '%s'", packPath, packSuffix)
+		}
+		indexPath = base + packIndexSuffix
+	}
+
+	data, err := os.ReadFile(packPath)
+	if err != nil {
+		return fmt.Errorf("cannot open packfile '%s': %w", packPath, err)
+	}
+	parsed, err := pack.Parse(data, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", packPath, err)
+	}
+
+	if err := writePackIndex(indexPath, parsed); err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), parsed.Checksum)
+	return nil
+}
+
+// writePackIndex writes the index of parsed to path, replacing any existing one atomically.
+func writePackIndex(path string, parsed *pack.Pack) error {
+	lock, err := lockfile.Acquire(path)
+	if err != nil {
+		return err
+	}
+	defer lock.Rollback()
+
+	if _, err := pack.WriteIndex(lock, parsed); err != nil {
+		return fmt.Errorf("failed to write pack index %s: %w", path, err)
+	}
+	return lock.Commit()
+}
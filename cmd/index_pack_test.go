@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/pack"
+	"github.com/KostasZigo/gogit/testutils"
+	"github.com/KostasZigo/gogit/utils"
+)
+
+// writeTestPack writes a pack of a blob and a delta against it to dir as name, returning its path.
+func writeTestPack(t *testing.T, dir, name string) string {
+	t.Helper()
+
+	data := testutils.CreateTestPack(t, []testutils.PackEntry{
+		{Type: utils.BlobObjectType, Data: []byte("hello world\n")},
+		{OffsetDelta: true, BaseEntry: 0, Data: append([]byte{12, 12, 0x90, 6, 6}, "there\n"...)},
+	})
+	return testutils.CreateTestFile(t, dir, name, data)
+}
+
+// TestIndexPackCommand verifies the index is written next to the pack, or to -o, and the
+// pack checksum printed.
+func TestIndexPackCommand(t *testing.T) {
+	dir := t.TempDir()
+	packPath := writeTestPack(t, dir, "test.pack")
+
+	output, _, err := runCmd(t, constants.IndexPackCmdName, packPath)
+	if err != nil {
+		t.Fatalf("%s command failed: %v", constants.IndexPackCmdName, err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "test.idx"))
+	if err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+	index, err := pack.ReadIndex(data)
+	if err != nil {
+		t.Fatalf("Failed to parse index: %v", err)
+	}
+	if output != index.PackChecksum+"\n" || len(index.Entries) != 2 {
+		t.Errorf("Expected checksum %s and 2 objects, got %q and %d", index.PackChecksum, output, len(index.Entries))
+	}
+
+	otherPath := filepath.Join(dir, "other.idx")
+	if _, _, err := runCmd(t, constants.IndexPackCmdName, "-o", otherPath, packPath); err != nil {
+		t.Fatalf("%s -o failed: %v", constants.IndexPackCmdName, err)
+	}
+	testutils.AssertFileExists(t, otherPath)
+}
+
+// TestIndexPackCommand_Errors verifies misnamed, corrupt and thin packs are rejected.
+func TestIndexPackCommand_Errors(t *testing.T) {
+	dir := t.TempDir()
+	packPath := writeTestPack(t, dir, "test.pack")
+	data, err := os.ReadFile(packPath)
+	if err != nil {
+		t.Fatalf("Failed to read pack: %v", err)
+	}
+	corruptPath := testutils.CreateTestFile(t, dir, "corrupt.pack", data[:len(data)-1])
+	thinPath := testutils.CreateTestFile(t, dir, "thin.pack", testutils.CreateTestPack(t, []testutils.PackEntry{
+		{BaseHash: testutils.RandomHash(), Data: []byte{12, 6, 0x91, 6, 6}},
+	}))
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"not a pack name", []string{writeTestPack(t, dir, "test.dat")}, "does not end with '.pack'"},
+		{"corrupt", []string{corruptPath}, "SHA1 mismatch"},
+		{"thin", []string{thinPath}, "pack has 1 unresolved deltas"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := runCmd(t, constants.IndexPackCmdName, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+	testutils.AssertFileNotExists(t, filepath.Join(dir, "thin.idx"))
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/KostasZigo/gogit/internal/constants"
 	"github.com/KostasZigo/gogit/internal/repository"
@@ -14,14 +15,28 @@ var initCmd = &cobra.Command{
 	Short: "Initialize a new GoGit repository",
 	Long: `The 'init' command sets up a new GoGit repository in the current directory.
 It creates a .gogit directory and necessary configuration files, allowing you to start tracking your project's history.
-If a repository already exists, the command will not overwrite existing data.`,
+
+HEAD points at the branch given by --initial-branch, otherwise init.defaultBranch
+from ~/.gogitconfig (or GOGIT_CONFIG_GLOBAL), otherwise main. Files in the
+--template directory, or init.templateDir, are copied into the new .gogit directory.
+
+Running init in an existing repository is safe: it adds missing directories and
+template files but never overwrites existing data, and reports "Reinitialized".`,
 	SilenceUsage: true,
 	Args:         maximumArgs(1),
 	RunE:         runInit,
 }
 
+var (
+	initBranchFlag   string
+	initTemplateFlag string
+)
+
 func init() {
 	rootCmd.AddCommand(initCmd)
+
+	initCmd.Flags().StringVarP(&initBranchFlag, "initial-branch", "b", "", "Name of the branch HEAD points to in the new repository")
+	initCmd.Flags().StringVar(&initTemplateFlag, "template", "", "Directory whose files are copied into the repository")
 }
 
 // maximumArgs validates command receives at most n positional arguments.
@@ -43,10 +58,27 @@ func runInit(cmd *cobra.Command, args []string) error {
 		dirPath = args[0]
 	}
 
-	if err := repository.InitRepository(dirPath); err != nil {
-		return fmt.Errorf("failed to initialize repository - %w", err)
+	opts := repository.InitOptions{InitialBranch: initBranchFlag, TemplateDir: initTemplateFlag}
+	gitDir := utils.BuildDirPath(dirPath, constants.Gogit)
+	if explicit := explicitGitDir(); explicit != "" {
+		var err error
+		if opts.GitDir, err = filepath.Abs(explicit); err != nil {
+			return err
+		}
+		gitDir = utils.BuildDirPath(opts.GitDir)
 	}
 
-	cmd.Printf("Initialized empty GoGit repository in %s\n", utils.BuildDirPath(dirPath, constants.Gogit))
+	reinitialized, err := repository.InitRepository(dirPath, opts)
+	if err != nil {
+		return fmt.Errorf("failed to initialize repository - %w", err)
+	}
+	if reinitialized {
+		if initBranchFlag != "" {
+			cmd.PrintErrf("warning: re-init: ignored --initial-branch=%s\n", initBranchFlag)
+		}
+		cmd.Printf("Reinitialized existing GoGit repository in %s\n", gitDir)
+		return nil
+	}
+	cmd.Printf("Initialized empty GoGit repository in %s\n", gitDir)
 	return nil
 }
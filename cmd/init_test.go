@@ -64,12 +64,13 @@ func TestInitCommand_WithDirectory_Success(t *testing.T) {
 	testutils.AssertRepositoryStructure(t, targetDirectory)
 }
 
-// TestInitCommand_AlreadyExists verifies error when repository already exists.
+// TestInitCommand_AlreadyExists verifies re-running init reports reinitialization and keeps HEAD.
 func TestInitCommand_AlreadyExists(t *testing.T) {
 	repoPath := t.TempDir()
 
 	// Initialize once
 	testRootCmd1 := createTestRootCmd(initCmd)
+	resetFlags(t, initCmd)
 	captureStdout(testRootCmd1)
 	testRootCmd1.SetArgs([]string{constants.InitCmdName, repoPath})
 
@@ -77,20 +78,45 @@ func TestInitCommand_AlreadyExists(t *testing.T) {
 		t.Fatalf("First %s failed: %v", constants.InitCmdName, err)
 	}
 
-	// Try to initialize again
+	// Initialize again with a branch, which is ignored
 	testRootCmd2 := createTestRootCmd(initCmd)
-	captureStderr(testRootCmd2)
-	testRootCmd2.SetArgs([]string{constants.InitCmdName, repoPath})
+	stdout := captureStdout(testRootCmd2)
+	stderr := captureStderr(testRootCmd2)
+	testRootCmd2.SetArgs([]string{constants.InitCmdName, "-b", "trunk", repoPath})
 
-	err := testRootCmd2.Execute()
-	if err == nil {
-		t.Error("Expected error when repository already exists")
+	if err := testRootCmd2.Execute(); err != nil {
+		t.Fatalf("Second %s failed: %v", constants.InitCmdName, err)
+	}
+
+	expectedMsg := fmt.Sprintf("Reinitialized existing GoGit repository in %s", utils.BuildDirPath(repoPath, constants.Gogit))
+	if !strings.Contains(stdout.String(), expectedMsg) {
+		t.Errorf("Expected output to contain %q, got: %q", expectedMsg, stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "ignored --initial-branch=trunk") {
+		t.Errorf("Expected warning about ignored branch, got: %q", stderr.String())
 	}
+	testutils.AssertRepositoryStructure(t, repoPath)
+}
+
+// TestInitCommand_InitialBranch verifies -b sets the branch HEAD points to.
+func TestInitCommand_InitialBranch(t *testing.T) {
+	repoPath := t.TempDir()
 
-	// Verify error message mentions repository exists
-	expectedErrorMsg := fmt.Sprintf("failed to initialize repository - repository already exists at %s", filepath.Join(repoPath, constants.Gogit))
-	if !strings.Contains(err.Error(), expectedErrorMsg) {
-		t.Errorf("Expected error to contain %q, got: %q", expectedErrorMsg, err.Error())
+	testRootCmd := createTestRootCmd(initCmd)
+	resetFlags(t, initCmd)
+	captureStdout(testRootCmd)
+	testRootCmd.SetArgs([]string{constants.InitCmdName, "--initial-branch", "trunk", repoPath})
+
+	if err := testRootCmd.Execute(); err != nil {
+		t.Fatalf("%s failed: %v", constants.InitCmdName, err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(repoPath, constants.Gogit, constants.Head))
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", constants.Head, err)
+	}
+	if expected := constants.DefaultRefPrefix + "trunk\n"; string(content) != expected {
+		t.Errorf("Expected %s %q, got %q", constants.Head, expected, content)
 	}
 }
 
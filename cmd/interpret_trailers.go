@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/spf13/cobra"
+)
+
+var interpretTrailersCmd = &cobra.Command{
+	Use:   "interpret-trailers [--trailer <key>:<value>]... [--only-trailers] [<file>...]",
+	Short: "Add or parse structured trailers in commit messages",
+	Long: `Read commit messages from the given files, or standard input when none are given,
+and print them with trailers in normalized "Key: value" form.
+
+Trailers are the "Key: value" lines of the last paragraph of a message,
+such as Signed-off-by or Co-authored-by. Each --trailer is appended to that
+paragraph, or to a new one when the message has none. A trailer identical
+to the last existing one is not repeated.
+
+Examples:
+  # Sign off a message
+  gogit interpret-trailers --trailer "Signed-off-by: Ada <ada@example.com>" < msg.txt
+
+  # List the trailers of a message
+  gogit interpret-trailers --only-trailers msg.txt`,
+	SilenceUsage: true,
+	RunE:         runInterpretTrailers,
+}
+
+var (
+	trailersFlag     []string
+	onlyTrailersFlag bool
+)
+
+func init() {
+	rootCmd.AddCommand(interpretTrailersCmd)
+
+	interpretTrailersCmd.Flags().StringArrayVar(&trailersFlag, "trailer", nil, "Trailer to add, as <key>:<value> or <key>=<value>")
+	interpretTrailersCmd.Flags().BoolVar(&onlyTrailersFlag, "only-trailers", false, "Print only the trailers")
+}
+
+// runInterpretTrailers adds requested trailers to each message and prints result.
+func runInterpretTrailers(cmd *cobra.Command, args []string) error {
+	add := make([]objects.Trailer, 0, len(trailersFlag))
+	for _, text := range trailersFlag {
+		trailer, err := objects.ParseTrailer(text)
+		if err != nil {
+			return err
+		}
+		add = append(add, trailer)
+	}
+
+	out := cmd.OutOrStdout()
+	if len(args) == 0 {
+		message, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return fmt.Errorf("failed to read stdin: %w", err)
+		}
+		fmt.Fprint(out, interpretTrailers(string(message), add))
+		return nil
+	}
+
+	for _, path := range args {
+		message, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read file %s: %w", path, err)
+		}
+		fmt.Fprint(out, interpretTrailers(string(message), add))
+	}
+	return nil
+}
+
+// interpretTrailers returns message with trailers added, or only its trailers when requested.
+func interpretTrailers(message string, add []objects.Trailer) string {
+	result := objects.AddTrailers(message, add...)
+	if !onlyTrailersFlag {
+		return result
+	}
+
+	_, trailers := objects.SplitTrailers(result)
+	var builder strings.Builder
+	for _, trailer := range trailers {
+		builder.WriteString(trailer.String())
+		builder.WriteByte('\n')
+	}
+	return builder.String()
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// TestInterpretTrailersCommand verifies trailers are appended to message from stdin.
+func TestInterpretTrailersCommand(t *testing.T) {
+	output, _, err := runCmdWithInput(t, strings.NewReader("Fix bug\n\nReviewed-by:Bob\n"), constants.InterpretTrailersCmdName, "--trailer", "Signed-off-by=Ada <ada@example.com>")
+	if err != nil {
+		t.Fatalf("%s command failed: %v", constants.InterpretTrailersCmdName, err)
+	}
+
+	expected := "Fix bug\n\nReviewed-by: Bob\nSigned-off-by: Ada <ada@example.com>\n"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
+
+// TestInterpretTrailersCommand_OnlyTrailers verifies only trailers are printed for file arguments.
+func TestInterpretTrailersCommand_OnlyTrailers(t *testing.T) {
+	dir := t.TempDir()
+	testutils.CreateTestFile(t, dir, "msg.txt", []byte("Subject\n\nBody\n\nAcked-by: Ada\n"))
+
+	output, _, err := runCmd(t, constants.InterpretTrailersCmdName, "--only-trailers", "--trailer", "Tested-by: Bob", filepath.Join(dir, "msg.txt"))
+	if err != nil {
+		t.Fatalf("%s command failed: %v", constants.InterpretTrailersCmdName, err)
+	}
+
+	if expected := "Acked-by: Ada\nTested-by: Bob\n"; output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
+
+// TestInterpretTrailersCommand_InvalidTrailer verifies malformed --trailer values are rejected.
+func TestInterpretTrailersCommand_InvalidTrailer(t *testing.T) {
+	if _, _, err := runCmdWithInput(t, strings.NewReader("Subject\n"), constants.InterpretTrailersCmdName, "--trailer", "no separator"); err == nil {
+		t.Fatal("Expected error for trailer without separator")
+	}
+}
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/pathspec"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/internal/transport"
+	"github.com/spf13/cobra"
+)
+
+var lsRemoteCmd = &cobra.Command{
+	Use:   "ls-remote [--heads] [--tags] [--refs] [--symref] [--exit-code] [--get-url] [-q] [<repository> [<pattern>...]]",
+	Short: "List the refs of a remote repository",
+	Long: `Contact a remote repository and list the refs it advertises, one
+"<hash>\t<name>" line each, without fetching anything. Annotated tags are
+followed by a "<name>^{}" line naming the object they point to.
+
+The repository is a remote configured in remote.<name>.url, a URL (http://,
+https://, ssh://, git:// or scp-like [user@]host:path) or a local path, and
+defaults to origin. GOGIT_SSH_COMMAND replaces ssh for SSH remotes.
+
+Other transports are provided by remote helpers: "<scheme>::<address>" and
+URLs with any other scheme run the gogit-remote-<scheme> program found on
+PATH, which lists the refs over the remote helper protocol.
+
+Patterns select refs whose names end with them, matching whole components:
+"main" selects refs/heads/main and refs/remotes/origin/main but not
+refs/heads/domain. --heads and --tags keep branches and tags only, --refs
+leaves out HEAD and the peeled "^{}" lines, and --symref shows what symbolic
+refs such as HEAD point to. --exit-code fails when no ref is listed.
+
+Examples:
+  # List branches of origin
+  gogit ls-remote --heads origin
+
+  # Show the default branch of a repository
+  gogit ls-remote --symref https://example.com/repo.git HEAD`,
+	SilenceUsage: true,
+	RunE:         runLsRemote,
+}
+
+var (
+	lsRemoteHeadsFlag    bool
+	lsRemoteTagsFlag     bool
+	lsRemoteRefsFlag     bool
+	lsRemoteSymrefFlag   bool
+	lsRemoteExitCodeFlag bool
+	lsRemoteGetURLFlag   bool
+	lsRemoteQuietFlag    bool
+)
+
+func init() {
+	rootCmd.AddCommand(lsRemoteCmd)
+
+	lsRemoteCmd.Flags().BoolVar(&lsRemoteHeadsFlag, "heads", false, "List branches only")
+	lsRemoteCmd.Flags().BoolVarP(&lsRemoteTagsFlag, "tags", "t", false, "List tags only")
+	lsRemoteCmd.Flags().BoolVar(&lsRemoteRefsFlag, "refs", false, "Leave out HEAD and peeled tags")
+	lsRemoteCmd.Flags().BoolVar(&lsRemoteSymrefFlag, "symref", false, "Show the targets of symbolic refs")
+	lsRemoteCmd.Flags().BoolVar(&lsRemoteExitCodeFlag, "exit-code", false, "Fail when no matching ref is found")
+	lsRemoteCmd.Flags().BoolVar(&lsRemoteGetURLFlag, "get-url", false, "Print the URL of the remote and exit")
+	lsRemoteCmd.Flags().BoolVarP(&lsRemoteQuietFlag, "quiet", "q", false, "Do not print the remote URL to stderr")
+}
+
+// runLsRemote lists the refs the remote advertises that pass the type filters and patterns.
+func runLsRemote(cmd *cobra.Command, args []string) error {
+	location, err := lsRemoteLocation(args)
+	if err != nil {
+		return err
+	}
+	if lsRemoteGetURLFlag {
+		fmt.Fprintln(cmd.OutOrStdout(), location)
+		return nil
+	}
+	var patterns []string
+	if len(args) > 0 {
+		patterns = args[1:]
+	}
+
+	endpoint, err := transport.ParseEndpoint(location)
+	if err != nil {
+		return err
+	}
+	var advertisement *transport.Advertisement
+	if endpoint.Protocol == transport.ProtocolFile {
+		var remote repository.Layout
+		if remote, err = enterRepository(endpoint.Path); err == nil {
+			advertisement, err = transport.LocalAdvertisement(remote.GitDir)
+		}
+	} else {
+		advertisement, err = transport.DiscoverRefs(endpoint)
+	}
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 && !lsRemoteQuietFlag {
+		cmd.PrintErrf("From %s\n", location)
+	}
+
+	listed := 0
+	out := cmd.OutOrStdout()
+	for _, ref := range advertisement.Refs {
+		if lsRemoteSelected(ref.Name, false, patterns) {
+			if target, ok := advertisement.Symrefs[ref.Name]; ok && lsRemoteSymrefFlag {
+				fmt.Fprintf(out, "ref: %s\t%s\n", target, ref.Name)
+			}
+			fmt.Fprintf(out, "%s\t%s\n", ref.Hash, ref.Name)
+			listed++
+		}
+		if peeled, ok := advertisement.Peeled[ref.Name]; ok && lsRemoteSelected(ref.Name, true, patterns) {
+			fmt.Fprintf(out, "%s\t%s^{}\n", peeled, ref.Name)
+			listed++
+		}
+	}
+
+	if listed == 0 && lsRemoteExitCodeFlag {
+		return errors.New("no matching refs")
+	}
+	return nil
+}
+
+// lsRemoteLocation returns the URL of the remote named by the first argument, origin by default,
+// or the argument itself when it names no configured remote.
+func lsRemoteLocation(args []string) (string, error) {
+	name := "origin"
+	if len(args) > 0 {
+		name = args[0]
+	}
+
+	if layout, err := findRepoRoot(); err == nil {
+		url, err := repository.ConfigValue(layout.GitDir, `remote "`+name+`"`, "url")
+		if err != nil {
+			return "", err
+		}
+		if url != "" {
+			return url, nil
+		}
+	}
+	if len(args) == 0 {
+		return "", errors.New("no remote configured to list refs from")
+	}
+	return name, nil
+}
+
+// lsRemoteSelected reports whether the ref name, or its peeled line, passes the type filters and
+// patterns. A pattern matches the end of the name at a component boundary.
+func lsRemoteSelected(name string, peeled bool, patterns []string) bool {
+	if lsRemoteRefsFlag && (peeled || !strings.HasPrefix(name, constants.Refs+"/")) {
+		return false
+	}
+	if lsRemoteHeadsFlag || lsRemoteTagsFlag {
+		if !(lsRemoteHeadsFlag && strings.HasPrefix(name, constants.BranchRefPrefix) ||
+			lsRemoteTagsFlag && strings.HasPrefix(name, constants.TagRefPrefix)) {
+			return false
+		}
+	}
+	if len(patterns) == 0 {
+		return true
+	}
+
+	if peeled {
+		name += "^{}"
+	}
+	for _, pattern := range patterns {
+		if pathspec.Wildmatch("*/"+pattern, "/"+name, false) {
+			return true
+		}
+	}
+	return false
+}
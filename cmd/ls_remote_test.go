@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/refs"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// TestLsRemoteCommand verifies a local remote is listed like upload-pack advertises it, with
+// type filters, tail-matching patterns and symref targets.
+func TestLsRemoteCommand(t *testing.T) {
+	remotePath, history := setupRefHistory(t)
+	tagHash, err := refs.Resolve(repository.GitDir(remotePath), "refs/tags/v1.0")
+	if err != nil {
+		t.Fatalf("Failed to resolve tag: %v", err)
+	}
+
+	all := fmt.Sprintf("%[1]s\tHEAD\n%[1]s\trefs/heads/main\n%[2]s\trefs/heads/side\n%[1]s\trefs/tags/light\n%[3]s\trefs/tags/v1.0\n%[4]s\trefs/tags/v1.0^{}\n",
+		history[2], history[0], tagHash, history[1])
+
+	tests := []struct {
+		name     string
+		flags    []string
+		patterns []string
+		expected string
+	}{
+		{"all", nil, nil, all},
+		{"symref", []string{"--symref"}, nil, "ref: refs/heads/main\tHEAD\n" + all},
+		{"refs only", []string{"--refs", "--tags"}, nil, fmt.Sprintf("%s\trefs/tags/light\n%s\trefs/tags/v1.0\n", history[2], tagHash)},
+		{"heads", []string{"--heads"}, nil, fmt.Sprintf("%s\trefs/heads/main\n%s\trefs/heads/side\n", history[2], history[0])},
+		{"patterns", nil, []string{"main", "v1*"}, fmt.Sprintf("%s\trefs/heads/main\n%s\trefs/tags/v1.0\n%s\trefs/tags/v1.0^{}\n", history[2], tagHash, history[1])},
+		{"whole components only", nil, []string{"ain"}, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			args := slices.Concat(test.flags, []string{remotePath}, test.patterns)
+			output, _, err := runCmd(t, constants.LsRemoteCmdName, args...)
+			if err != nil {
+				t.Fatalf("%s command failed: %v", constants.LsRemoteCmdName, err)
+			}
+			if output != test.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", test.expected, output)
+			}
+		})
+	}
+}
+
+// TestLsRemoteCommand_ConfiguredRemote verifies origin is listed by default from its configured
+// URL, announced on stderr unless quiet, and --get-url prints the URL alone.
+func TestLsRemoteCommand_ConfiguredRemote(t *testing.T) {
+	remotePath, history := setupRefHistory(t)
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	config := fmt.Sprintf("[remote \"origin\"]\n\turl = %s\n", remotePath)
+	if err := os.WriteFile(filepath.Join(repoPath, constants.Gogit, "config"), []byte(config), constants.FilePerms); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	output, stderr, err := runCmd(t, constants.LsRemoteCmdName, "--heads")
+	if err != nil {
+		t.Fatalf("%s command failed: %v", constants.LsRemoteCmdName, err)
+	}
+	if expected := fmt.Sprintf("%s\trefs/heads/main\n%s\trefs/heads/side\n", history[2], history[0]); output != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, output)
+	}
+	if expected := "From " + remotePath + "\n"; stderr != expected {
+		t.Errorf("Expected stderr %q, got %q", expected, stderr)
+	}
+
+	if _, stderr, _ := runCmd(t, constants.LsRemoteCmdName, "-q", "--heads"); stderr != "" {
+		t.Errorf("Expected nothing on stderr with -q, got %q", stderr)
+	}
+
+	output, _, err = runCmd(t, constants.LsRemoteCmdName, "--get-url", "origin")
+	if err != nil {
+		t.Fatalf("%s --get-url failed: %v", constants.LsRemoteCmdName, err)
+	}
+	if output != remotePath+"\n" {
+		t.Errorf("Expected %s, got %q", remotePath, output)
+	}
+}
+
+// TestLsRemoteCommand_Errors verifies --exit-code fails without matches and non-repositories are rejected.
+func TestLsRemoteCommand_Errors(t *testing.T) {
+	remotePath, _ := setupRefHistory(t)
+
+	if _, _, err := runCmd(t, constants.LsRemoteCmdName, remotePath, "nomatch"); err != nil {
+		t.Errorf("Expected success without --exit-code, got %v", err)
+	}
+	if _, _, err := runCmd(t, constants.LsRemoteCmdName, "--exit-code", remotePath, "nomatch"); err == nil {
+		t.Error("Expected error with --exit-code and no matching refs")
+	}
+	if _, _, err := runCmd(t, constants.LsRemoteCmdName, t.TempDir()); err == nil {
+		t.Error("Expected error listing a directory that is not a repository")
+	}
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/merge"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/utils"
+	"github.com/spf13/cobra"
+)
+
+var mergeTreeCmd = &cobra.Command{
+	Use:   "merge-tree [--write-tree] [--name-only] [--[no-]messages] [-z] [--allow-unrelated-histories] <branch1> <branch2>",
+	Short: "Merge two commits without touching the index or working tree",
+	Long: `Perform a three-way merge of two commits against their merge base entirely
+in the object database, and print the hash of the merged tree.
+
+When the merge conflicts, the tree holds the files with conflict markers, and
+the output continues with one "<mode> <object> <stage>\t<path>" line per
+version of each conflicted path (only paths with --name-only), an empty line,
+and messages describing what happened. Messages are shown for clean merges
+with --messages. -z separates lines with NUL, and each message becomes
+"<count> NUL <paths> NUL <kind> NUL <message>".
+
+merge.conflictStyle selects the conflict markers: "merge" (default), "diff3"
+to include the merge base version, or "zdiff3" to also move lines both sides
+share out of the conflict.
+
+The exit status is 0 for a clean merge and 1 for a conflicted one.
+
+Examples:
+  # Preview whether a topic branch merges cleanly into main
+  gogit merge-tree --write-tree main topic`,
+	SilenceUsage: true,
+	Args:         mergeTreeArgs,
+	RunE:         runMergeTree,
+}
+
+var (
+	mergeTreeWriteTreeFlag  bool
+	mergeTreeNameOnlyFlag   bool
+	mergeTreeMessagesFlag   bool
+	mergeTreeNoMessagesFlag bool
+	mergeTreeNulFlag        bool
+	mergeTreeUnrelatedFlag  bool
+)
+
+func init() {
+	rootCmd.AddCommand(mergeTreeCmd)
+
+	mergeTreeCmd.Flags().BoolVar(&mergeTreeWriteTreeFlag, "write-tree", true, "Write the merged tree (the only supported mode)")
+	mergeTreeCmd.Flags().BoolVar(&mergeTreeNameOnlyFlag, "name-only", false, "List conflicted paths without modes, objects and stages")
+	mergeTreeCmd.Flags().BoolVar(&mergeTreeMessagesFlag, "messages", false, "Show messages even when the merge is clean")
+	mergeTreeCmd.Flags().BoolVarP(&mergeTreeNulFlag, "null", "z", false, "Separate lines and message fields with NUL")
+	mergeTreeCmd.Flags().BoolVar(&mergeTreeUnrelatedFlag, "allow-unrelated-histories", false, "Merge commits without a common ancestor")
+	mergeTreeCmd.Flags().BoolVar(&mergeTreeNoMessagesFlag, "no-messages", false, "Never show messages")
+}
+
+// mergeTreeArgs requires the two commits to merge.
+// Enables usage printing in case of error.
+func mergeTreeArgs(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		cmd.SilenceUsage = false
+		return fmt.Errorf("%s requires exactly 2 arguments (branch1, branch2), received %d", constants.MergeTreeCmdName, len(args))
+	}
+	return nil
+}
+
+// runMergeTree merges the two commits and prints the tree, conflicts and messages.
+func runMergeTree(cmd *cobra.Command, args []string) error {
+	if !mergeTreeWriteTreeFlag {
+		return errors.New("only --write-tree merges are supported")
+	}
+
+	layout, err := findRepoRoot()
+	if err != nil {
+		return err
+	}
+	store := newObjectStore(layout.GitDir)
+
+	ours, err := resolveCommitish(layout.GitDir, store, args[0])
+	if err != nil {
+		return err
+	}
+	theirs, err := resolveCommitish(layout.GitDir, store, args[1])
+	if err != nil {
+		return err
+	}
+
+	bases, err := merge.Bases(store, ours, theirs)
+	if err != nil {
+		return err
+	}
+	if len(bases) == 0 && !mergeTreeUnrelatedFlag {
+		return errors.New("refusing to merge unrelated histories")
+	}
+
+	configuredStyle, err := repository.ConfigValue(layout.GitDir, "merge", "conflictStyle")
+	if err != nil {
+		return err
+	}
+	style, err := merge.ParseConflictStyle(configuredStyle)
+	if err != nil {
+		return fmt.Errorf("%w given for 'merge.conflictStyle'", err)
+	}
+
+	result, err := merge.Commits(store, bases, ours, theirs, merge.Labels{Ours: args[0], Theirs: args[1]}, style)
+	if err != nil {
+		return err
+	}
+
+	showMessages := (!result.Clean() || mergeTreeMessagesFlag) && !mergeTreeNoMessagesFlag
+	printMergeTreeResult(cmd.OutOrStdout(), result, showMessages)
+
+	if !result.Clean() {
+		return fmt.Errorf("merge of %s and %s has %d conflicted path(s)", args[0], args[1], len(result.Conflicts))
+	}
+	return nil
+}
+
+// resolveCommitish resolves a ref name or object name prefix to a commit, peeling tags.
+func resolveCommitish(gitDir string, store *objects.ObjectStore, name string) (string, error) {
+	hash, err := resolveObjectName(gitDir, store, name)
+	if err != nil {
+		return "", fmt.Errorf("%s - not something we can merge", name)
+	}
+
+	peeled, err := peelObject(store, hash)
+	if err != nil {
+		return "", err
+	}
+	objectType, err := storedObjectType(store, peeled)
+	if err != nil {
+		return "", err
+	}
+	if objectType != utils.CommitObjectType {
+		return "", fmt.Errorf("%s - not something we can merge", name)
+	}
+	return peeled, nil
+}
+
+// printMergeTreeResult writes the tree hash, then conflicted paths and messages when requested.
+func printMergeTreeResult(out io.Writer, result *merge.Result, showMessages bool) {
+	terminator := "\n"
+	if mergeTreeNulFlag {
+		terminator = "\x00"
+	}
+
+	fmt.Fprint(out, result.Tree+terminator)
+	printConflictedPaths(out, result.Conflicts, terminator)
+	if !showMessages {
+		return
+	}
+
+	fmt.Fprint(out, terminator)
+	for _, message := range result.Messages {
+		if mergeTreeNulFlag {
+			fmt.Fprintf(out, "%d\x00%s\x00%s\x00%s\n\x00", len(message.Paths), strings.Join(message.Paths, "\x00"), message.Kind, message.Text)
+			continue
+		}
+		fmt.Fprintln(out, message.Text)
+	}
+}
+
+// printConflictedPaths lists each version of every conflicted path as "<mode> <hash> <stage>\t<path>",
+// or each path once with --name-only.
+func printConflictedPaths(out io.Writer, conflicts []merge.Conflict, terminator string) {
+	for _, conflict := range conflicts {
+		if mergeTreeNameOnlyFlag {
+			fmt.Fprint(out, conflict.Path+terminator)
+			continue
+		}
+		for stage, version := range []*merge.Version{conflict.Base, conflict.Ours, conflict.Theirs} {
+			if version != nil {
+				fmt.Fprintf(out, "%s %s %d\t%s%s", version.Mode, version.Hash, stage+1, conflict.Path, terminator)
+			}
+		}
+	}
+}
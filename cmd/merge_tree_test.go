@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/index"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// storeFilesCommit stores a commit of files, mapping paths to content, on top of parent.
+// Returns the commit and tree hashes.
+func storeFilesCommit(t *testing.T, store *objects.ObjectStore, parent string, files map[string]string) (string, string) {
+	t.Helper()
+
+	idx := index.New()
+	for name, content := range files {
+		blob := objects.NewBlob([]byte(content))
+		if err := store.Store(blob); err != nil {
+			t.Fatalf("Failed to store blob: %v", err)
+		}
+		idx.Add(index.Entry{Path: name, Mode: objects.ModeRegularFile, Hash: blob.Hash(), Size: uint32(len(content))})
+	}
+	tree, err := idx.WriteTree(store)
+	if err != nil {
+		t.Fatalf("Failed to write tree: %v", err)
+	}
+
+	author := objects.Author{Name: "A", Email: "a@example.com", Timestamp: time.Unix(1700000000, 0).UTC()}
+	commit, err := objects.NewCommit(tree, parent, "commit", author)
+	if err != nil {
+		t.Fatalf("Failed to create commit: %v", err)
+	}
+	if err := store.Store(commit); err != nil {
+		t.Fatalf("Failed to store commit: %v", err)
+	}
+	return commit.Hash(), tree
+}
+
+// setupMergeTreeRepo creates branches main and side forked from one commit, each changing
+// a.txt, and a branch other with unrelated history.
+func setupMergeTreeRepo(t *testing.T, sideContent string) (repoPath string, store *objects.ObjectStore) {
+	t.Helper()
+
+	repoPath = testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	store = objects.NewObjectStore(repository.GitDir(repoPath))
+
+	base, _ := storeFilesCommit(t, store, "", map[string]string{"a.txt": "1\n2\n3\n4\n5\n"})
+	main, _ := storeFilesCommit(t, store, base, map[string]string{"a.txt": "one\n2\n3\n4\n5\n"})
+	side, _ := storeFilesCommit(t, store, base, map[string]string{"a.txt": sideContent})
+	other, _ := storeFilesCommit(t, store, "", map[string]string{"b.txt": "b\n"})
+
+	writeTestRef(t, repoPath, constants.BranchRefPrefix+"main", main)
+	writeTestRef(t, repoPath, constants.BranchRefPrefix+"side", side)
+	writeTestRef(t, repoPath, constants.BranchRefPrefix+"other", other)
+	return repoPath, store
+}
+
+// TestMergeTreeCommand_Clean verifies a clean merge prints only the merged tree, and messages on request.
+func TestMergeTreeCommand_Clean(t *testing.T) {
+	_, store := setupMergeTreeRepo(t, "1\n2\n3\n4\nfive\n")
+	_, expectedTree := storeFilesCommit(t, store, "", map[string]string{"a.txt": "one\n2\n3\n4\nfive\n"})
+
+	output, _, err := runCmd(t, constants.MergeTreeCmdName, "main", "side")
+	if err != nil {
+		t.Fatalf("%s failed: %v", constants.MergeTreeCmdName, err)
+	}
+	if output != expectedTree+"\n" {
+		t.Errorf("Expected only tree %s, got %q", expectedTree, output)
+	}
+
+	output, _, err = runCmd(t, constants.MergeTreeCmdName, "--messages", "main", "side")
+	if err != nil {
+		t.Fatalf("%s --messages failed: %v", constants.MergeTreeCmdName, err)
+	}
+	expected := expectedTree + "\n\nAuto-merging a.txt\n"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
+
+// TestMergeTreeCommand_Conflict verifies conflicted stages, messages, --name-only and the error.
+func TestMergeTreeCommand_Conflict(t *testing.T) {
+	_, store := setupMergeTreeRepo(t, "ONE\n2\n3\n4\n5\n")
+
+	output, _, err := runCmd(t, constants.MergeTreeCmdName, "main", "side")
+	if err == nil {
+		t.Fatal("Expected an error for a conflicted merge")
+	}
+
+	lines := strings.Split(output, "\n")
+	if len(lines) != 8 {
+		t.Fatalf("Expected tree, 3 stages, blank line and 2 messages, got %q", output)
+	}
+	for i, stage := range []string{"1", "2", "3"} {
+		fields := strings.Fields(lines[i+1])
+		if len(fields) != 4 || fields[0] != string(objects.ModeRegularFile) || fields[2] != stage || fields[3] != "a.txt" {
+			t.Errorf("Expected stage %s of a.txt, got %q", stage, lines[i+1])
+		}
+	}
+	if lines[5] != "Auto-merging a.txt" || lines[6] != "CONFLICT (content): Merge conflict in a.txt" {
+		t.Errorf("Expected auto-merge and content conflict messages, got %q", lines[5:7])
+	}
+
+	files, err := store.FlattenTree(lines[0])
+	if err != nil {
+		t.Fatalf("Failed to read merged tree: %v", err)
+	}
+	entry := files["a.txt"]
+	blob, err := store.ReadBlob(entry.Hash())
+	if err != nil {
+		t.Fatalf("Failed to read merged file: %v", err)
+	}
+	expectedContent := "<<<<<<< main\none\n=======\nONE\n>>>>>>> side\n2\n3\n4\n5\n"
+	if string(blob.Content()) != expectedContent {
+		t.Errorf("Expected merged content %q, got %q", expectedContent, blob.Content())
+	}
+
+	output, _, _ = runCmd(t, constants.MergeTreeCmdName, "--name-only", "--no-messages", "main", "side")
+	if output != lines[0]+"\na.txt\n" {
+		t.Errorf("Expected tree and conflicted name only, got %q", output)
+	}
+}
+
+// TestMergeTreeCommand_Errors verifies argument count, unknown names and unrelated histories.
+func TestMergeTreeCommand_Errors(t *testing.T) {
+	setupMergeTreeRepo(t, "1\n2\n3\n4\nfive\n")
+
+	tests := []struct {
+		name          string
+		args          []string
+		expectedError string
+	}{
+		{name: "one argument", args: []string{"main"}, expectedError: "requires exactly 2 arguments"},
+		{name: "unknown name", args: []string{"main", "missing"}, expectedError: "missing - not something we can merge"},
+		{name: "unrelated histories", args: []string{"main", "other"}, expectedError: "refusing to merge unrelated histories"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := runCmd(t, constants.MergeTreeCmdName, test.args...)
+			if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+				t.Errorf("Expected error containing %q, got %v", test.expectedError, err)
+			}
+		})
+	}
+
+	if _, _, err := runCmd(t, constants.MergeTreeCmdName, "--allow-unrelated-histories", "main", "other"); err != nil {
+		t.Errorf("Expected unrelated histories to merge when allowed, got %v", err)
+	}
+}
+
+// TestMergeTreeCommand_ConflictStyle verifies merge.conflictStyle from the repository config.
+func TestMergeTreeCommand_ConflictStyle(t *testing.T) {
+	repoPath, store := setupMergeTreeRepo(t, "ONE\n2\n3\n4\n5\n")
+	testutils.CreateTestFile(t, repoPath, filepath.Join(constants.Gogit, "config"), []byte("[merge]\n\tconflictStyle = diff3\n"))
+
+	output, _, _ := runCmd(t, constants.MergeTreeCmdName, "--name-only", "main", "side")
+	tree, _, _ := strings.Cut(output, "\n")
+	files, err := store.FlattenTree(tree)
+	if err != nil {
+		t.Fatalf("Failed to read merged tree: %v", err)
+	}
+	entry := files["a.txt"]
+	blob, err := store.ReadBlob(entry.Hash())
+	if err != nil {
+		t.Fatalf("Failed to read merged file: %v", err)
+	}
+
+	if !strings.Contains(string(blob.Content()), "=======\nONE\n") || !strings.Contains(string(blob.Content()), "\n1\n=======") {
+		t.Errorf("Expected the base line between the conflict sides, got %q", blob.Content())
+	}
+
+	testutils.CreateTestFile(t, repoPath, filepath.Join(constants.Gogit, "config"), []byte("[merge]\n\tconflictStyle = fancy\n"))
+	if _, _, err := runCmd(t, constants.MergeTreeCmdName, "main", "side"); err == nil || !strings.Contains(err.Error(), "unknown conflict style") {
+		t.Errorf("Expected an unknown style error, got %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/spf13/cobra"
+)
+
+var mktagCmd = &cobra.Command{
+	Use:   "mktag",
+	Short: "Create a tag object with extra validation",
+	Long: `Read raw tag content from standard input, validate it, and write the tag object.
+The hash of the new tag is printed.
+
+The content must have the form:
+
+  object <hash>
+  type <type>
+  tag <name>
+  tagger <name> <<email>> <unix time> <timezone>
+
+  <message>
+
+The tagged object must exist and have the declared type.
+
+Examples:
+  # Create a tag from a prepared file
+  gogit mktag < tag.txt`,
+	SilenceUsage: true,
+	Args:         noArgs(constants.MktagCmdName),
+	RunE:         runMktag,
+}
+
+func init() {
+	rootCmd.AddCommand(mktagCmd)
+}
+
+// runMktag validates tag content from stdin and stores it.
+func runMktag(cmd *cobra.Command, args []string) error {
+	layout, err := findRepoRoot()
+	if err != nil {
+		return err
+	}
+	store := newObjectStore(layout.GitDir)
+
+	content, err := io.ReadAll(cmd.InOrStdin())
+	if err != nil {
+		return fmt.Errorf("failed to read stdin: %w", err)
+	}
+
+	tag, err := objects.ParseTag(content)
+	if err != nil {
+		return err
+	}
+
+	objectType, err := storedObjectType(store, tag.Object())
+	if errors.Is(err, objects.ErrObjectNotFound) {
+		return fmt.Errorf("tagged object %s does not exist", tag.Object())
+	}
+	if err != nil {
+		return err
+	}
+	if objectType != tag.ObjectType() {
+		return fmt.Errorf("tagged object %s is a %s, not a %s", tag.Object(), objectType, tag.ObjectType())
+	}
+
+	if err := store.Store(tag); err != nil {
+		return fmt.Errorf("failed to store tag: %w", err)
+	}
+
+	fmt.Fprintln(cmd.OutOrStdout(), tag.Hash())
+	return nil
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// tagContent builds raw tag content pointing at hash with given type.
+func tagContent(hash, objectType string) string {
+	return fmt.Sprintf("object %s\ntype %s\ntag v1\ntagger A U Thor <a@b.c> 1700000000 +0100\n\nRelease\n", hash, objectType)
+}
+
+// TestMktagCommand verifies valid tag content is stored and its hash printed.
+func TestMktagCommand(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	store := objects.NewObjectStore(repository.GitDir(repoPath))
+	blob := objects.NewBlob([]byte("tagged\n"))
+	if err := store.Store(blob); err != nil {
+		t.Fatalf("Failed to store blob: %v", err)
+	}
+
+	content := tagContent(blob.Hash(), "blob")
+	output, _, err := runCmdWithInput(t, strings.NewReader(content), constants.MktagCmdName)
+	if err != nil {
+		t.Fatalf("%s command failed: %v", constants.MktagCmdName, err)
+	}
+
+	tag, err := store.ReadTag(strings.TrimSpace(output))
+	if err != nil {
+		t.Fatalf("Failed to read created tag: %v", err)
+	}
+	if string(tag.Content()) != content {
+		t.Errorf("Expected content %q, got %q", content, tag.Content())
+	}
+}
+
+// TestMktagCommand_Errors verifies malformed content and bad targets are rejected.
+func TestMktagCommand_Errors(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	blob := objects.NewBlob([]byte("tagged\n"))
+	if err := objects.NewObjectStore(repository.GitDir(repoPath)).Store(blob); err != nil {
+		t.Fatalf("Failed to store blob: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		input         string
+		expectedError string
+	}{
+		{"malformed", "not a tag\n", "invalid tag"},
+		{"missing object", tagContent(testutils.RandomHash(), "blob"), "does not exist"},
+		{"type mismatch", tagContent(blob.Hash(), "commit"), "is a blob, not a commit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := runCmdWithInput(t, strings.NewReader(tt.input), constants.MktagCmdName)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("Expected error containing [%s], got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
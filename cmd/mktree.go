@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/utils"
+	"github.com/spf13/cobra"
+)
+
+var mktreeCmd = &cobra.Command{
+	Use:   "mktree [--missing]",
+	Short: "Build a tree object from ls-tree formatted text",
+	Long: `Read entries in ls-tree format from standard input and write the resulting tree object.
+Each line has the form "<mode> SP <type> SP <object> TAB <name>". Entries are sorted
+automatically. The hash of the new tree is printed.
+
+Referenced objects must exist with the type named on their line, except submodule
+commits, which live in another repository. Use --missing to skip this check.
+
+Examples:
+  # Build a tree containing one file
+  printf '100644 blob %s\tREADME.md\n' "$(gogit hash-object -w README.md)" | gogit mktree`,
+	SilenceUsage: true,
+	Args:         noArgs(constants.MktreeCmdName),
+	RunE:         runMktree,
+}
+
+var allowMissingFlag bool
+
+func init() {
+	rootCmd.AddCommand(mktreeCmd)
+
+	mktreeCmd.Flags().BoolVar(&allowMissingFlag, "missing", false, "Allow entries referring to objects that do not exist")
+}
+
+// runMktree parses entries from stdin and stores them as a tree.
+func runMktree(cmd *cobra.Command, args []string) error {
+	layout, err := findRepoRoot()
+	if err != nil {
+		return err
+	}
+	store := newObjectStore(layout.GitDir)
+
+	entries, err := readTreeEntries(cmd.InOrStdin())
+	if err != nil {
+		return err
+	}
+
+	if !allowMissingFlag {
+		if err := verifyTreeEntries(store, entries); err != nil {
+			return err
+		}
+	}
+
+	tree, err := objects.NewTree(entries)
+	if err != nil {
+		return err
+	}
+
+	if err := store.Store(tree); err != nil {
+		return fmt.Errorf("failed to store tree: %w", err)
+	}
+
+	fmt.Fprintln(cmd.OutOrStdout(), tree.Hash())
+	return nil
+}
+
+// readTreeEntries parses ls-tree formatted lines, rejecting duplicate names.
+func readTreeEntries(reader io.Reader) ([]objects.TreeEntry, error) {
+	var entries []objects.TreeEntry
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(reader)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		entry, err := parseTreeEntryLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		if seen[entry.Name()] {
+			return nil, fmt.Errorf("line %d: duplicate entry %q", lineNumber, entry.Name())
+		}
+		seen[entry.Name()] = true
+		entries = append(entries, *entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read tree entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// parseTreeEntryLine parses "<mode> SP <type> SP <object> TAB <name>" into a tree entry.
+func parseTreeEntryLine(line string) (*objects.TreeEntry, error) {
+	meta, name, found := strings.Cut(line, "\t")
+	if !found {
+		return nil, fmt.Errorf("malformed entry %q: expected tab before name", line)
+	}
+
+	fields := strings.Fields(meta)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("malformed entry %q: expected mode, type and object", line)
+	}
+	if strings.Contains(name, "/") {
+		return nil, fmt.Errorf("invalid name %q: paths with slashes are not allowed", name)
+	}
+
+	mode := objects.FileMode(fields[0])
+	// Git writes directory modes without the leading zero
+	if mode == "40000" {
+		mode = objects.ModeDirectory
+	}
+
+	if expected := entryObjectType(mode); utils.ObjectType(fields[1]) != expected {
+		return nil, fmt.Errorf("entry %q: mode %s requires type %s, got %s", name, fields[0], expected, fields[1])
+	}
+
+	return objects.NewTreeEntry(mode, name, strings.ToLower(fields[2]))
+}
+
+// entryObjectType returns the object type a tree entry of given mode must point to.
+func entryObjectType(mode objects.FileMode) utils.ObjectType {
+	switch mode {
+	case objects.ModeDirectory:
+		return utils.TreeObjectType
+	case objects.ModeSubmodule:
+		return utils.CommitObjectType
+	default:
+		return utils.BlobObjectType
+	}
+}
+
+// verifyTreeEntries checks every entry refers to a stored object of the expected type.
+// Submodule commits are skipped since they belong to another repository.
+func verifyTreeEntries(store *objects.ObjectStore, entries []objects.TreeEntry) error {
+	for _, entry := range entries {
+		if entry.Mode() == objects.ModeSubmodule {
+			continue
+		}
+
+		objectType, err := storedObjectType(store, entry.Hash())
+		if errors.Is(err, objects.ErrObjectNotFound) {
+			return fmt.Errorf("entry %q refers to missing object %s", entry.Name(), entry.Hash())
+		}
+		if err != nil {
+			return err
+		}
+
+		if expected := entryObjectType(entry.Mode()); objectType != expected {
+			return fmt.Errorf("entry %q: object %s is a %s, not a %s", entry.Name(), entry.Hash(), objectType, expected)
+		}
+	}
+	return nil
+}
+
+// storedObjectType reads only the header of a stored object to determine its type.
+func storedObjectType(store *objects.ObjectStore, hash string) (utils.ObjectType, error) {
+	reader, err := store.OpenObject(hash)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	return reader.Type(), nil
+}
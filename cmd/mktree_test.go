@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// TestMktreeCommand verifies entries are sorted, stored and the tree hash printed.
+func TestMktreeCommand(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	store := objects.NewObjectStore(repository.GitDir(repoPath))
+
+	blob := objects.NewBlob([]byte("content\n"))
+	if err := store.Store(blob); err != nil {
+		t.Fatalf("Failed to store blob: %v", err)
+	}
+	subtree, err := objects.NewTree([]objects.TreeEntry{*mustTreeEntry(t, objects.ModeRegularFile, "inner.txt", blob.Hash())})
+	if err != nil {
+		t.Fatalf("Failed to create subtree: %v", err)
+	}
+	if err := store.Store(subtree); err != nil {
+		t.Fatalf("Failed to store subtree: %v", err)
+	}
+
+	input := fmt.Sprintf("100755 blob %s\tz.sh\n40000 tree %s\tdir\n100644 blob %s\ta.txt\n", blob.Hash(), subtree.Hash(), blob.Hash())
+	output, _, err := runCmdWithInput(t, strings.NewReader(input), constants.MktreeCmdName)
+	if err != nil {
+		t.Fatalf("%s command failed: %v", constants.MktreeCmdName, err)
+	}
+
+	tree, err := store.ReadTree(strings.TrimSpace(output))
+	if err != nil {
+		t.Fatalf("Failed to read created tree: %v", err)
+	}
+
+	var names []string
+	for _, entry := range tree.Entries() {
+		names = append(names, entry.Name())
+	}
+	if strings.Join(names, ",") != "a.txt,dir,z.sh" {
+		t.Errorf("Expected sorted entries a.txt,dir,z.sh, got %v", names)
+	}
+}
+
+// TestMktreeCommand_Errors verifies invalid input and dangling references are rejected.
+func TestMktreeCommand_Errors(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	blob := objects.NewBlob([]byte("stored"))
+	if err := objects.NewObjectStore(repository.GitDir(repoPath)).Store(blob); err != nil {
+		t.Fatalf("Failed to store blob: %v", err)
+	}
+	missing := testutils.RandomHash()
+
+	tests := []struct {
+		name          string
+		input         string
+		expectedError string
+	}{
+		{"missing tab", "100644 blob " + blob.Hash() + " a.txt\n", "expected tab before name"},
+		{"mode type mismatch", "100644 tree " + blob.Hash() + "\ta.txt\n", "requires type blob"},
+		{"slash in name", "100644 blob " + blob.Hash() + "\tdir/a.txt\n", "slashes are not allowed"},
+		{"duplicate", "100644 blob " + blob.Hash() + "\ta\n100644 blob " + blob.Hash() + "\ta\n", "duplicate entry"},
+		{"missing object", "100644 blob " + missing + "\ta.txt\n", "missing object"},
+		{"wrong stored type", "040000 tree " + blob.Hash() + "\tdir\n", "is a blob, not a tree"},
+		{"empty input", "", "at least one entry"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := runCmdWithInput(t, strings.NewReader(tt.input), constants.MktreeCmdName)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("Expected error containing [%s], got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+// TestMktreeCommand_Missing verifies --missing skips the existence check.
+func TestMktreeCommand_Missing(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+
+	output, _, err := runCmdWithInput(t, strings.NewReader("100644 blob "+testutils.RandomHash()+"\ta.txt\n"), constants.MktreeCmdName, "--missing")
+	if err != nil {
+		t.Fatalf("%s --missing failed: %v", constants.MktreeCmdName, err)
+	}
+	if len(strings.TrimSpace(output)) != constants.HashStringLength {
+		t.Errorf("Expected tree hash, got [%s]", output)
+	}
+}
+
+// mustTreeEntry creates tree entry, failing test on error.
+func mustTreeEntry(t *testing.T, mode objects.FileMode, name, hash string) *objects.TreeEntry {
+	t.Helper()
+
+	entry, err := objects.NewTreeEntry(mode, name, hash)
+	if err != nil {
+		t.Fatalf("Failed to create tree entry: %v", err)
+	}
+	return entry
+}
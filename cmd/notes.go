@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/notes"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/refs"
+	"github.com/spf13/cobra"
+)
+
+var notesCmd = &cobra.Command{
+	Use:   "notes [list [<object>]]",
+	Short: "Add or inspect notes attached to objects",
+	Long: `Notes attach extra text to commits and other objects without changing them.
+They are stored as blobs in a tree under refs/notes/commits, in the same layout
+Git uses, so each change to the notes is a commit of its own.
+
+Without a subcommand, all notes are listed.
+
+Examples:
+  # Attach a note to the current commit
+  gogit notes add -m "Reviewed in staging"
+
+  # Show the note of a commit
+  gogit notes show main
+
+  # List all notes as "<note blob> <annotated object>"
+  gogit notes list`,
+	SilenceUsage: true,
+	Args:         noArgs(constants.NotesCmdName),
+	RunE:         runNotesList,
+}
+
+var notesAddCmd = &cobra.Command{
+	Use:          "add [-f] -m <message>... [<object>]",
+	Short:        "Attach a note to an object (HEAD by default)",
+	SilenceUsage: true,
+	Args:         maxObjectArgs,
+	RunE:         runNotesAdd,
+}
+
+var notesShowCmd = &cobra.Command{
+	Use:          "show [<object>]",
+	Short:        "Print the note of an object (HEAD by default)",
+	SilenceUsage: true,
+	Args:         maxObjectArgs,
+	RunE:         runNotesShow,
+}
+
+var notesListCmd = &cobra.Command{
+	Use:          "list [<object>]",
+	Short:        "List notes, or print the note blob of one object",
+	SilenceUsage: true,
+	Args:         maxObjectArgs,
+	RunE:         runNotesList,
+}
+
+var (
+	noteMessagesFlag []string
+	forceNoteFlag    bool
+)
+
+func init() {
+	rootCmd.AddCommand(notesCmd)
+	notesCmd.AddCommand(notesAddCmd, notesShowCmd, notesListCmd)
+
+	notesAddCmd.Flags().StringArrayVarP(&noteMessagesFlag, "message", "m", nil, "Note message; several are joined as paragraphs")
+	notesAddCmd.Flags().BoolVarP(&forceNoteFlag, "force", "f", false, "Replace an existing note")
+}
+
+// maxObjectArgs accepts at most one object argument.
+// Enables usage printing in case of error.
+func maxObjectArgs(cmd *cobra.Command, args []string) error {
+	if len(args) > 1 {
+		cmd.SilenceUsage = false
+		return fmt.Errorf("%s %s accepts at most 1 arg(s), received %d", constants.NotesCmdName, cmd.Name(), len(args))
+	}
+	return nil
+}
+
+// loadNotes opens the repository's notes and resolves the object named by args, HEAD if none.
+func loadNotes(args []string) (*notes.Notes, string, error) {
+	repoNotes, gitDir, store, err := openNotes()
+	if err != nil {
+		return nil, "", err
+	}
+
+	var object string
+	if len(args) == 0 {
+		object, err = refs.Resolve(gitDir, constants.Head)
+	} else {
+		object, err = resolveObjectName(gitDir, store, args[0])
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to resolve object: %w", err)
+	}
+	return repoNotes, object, nil
+}
+
+// openNotes loads notes of the repository containing the working directory.
+func openNotes() (*notes.Notes, string, *objects.ObjectStore, error) {
+	layout, err := findRepoRoot()
+	if err != nil {
+		return nil, "", nil, err
+	}
+	store := newObjectStore(layout.GitDir)
+
+	repoNotes, err := notes.Load(layout.GitDir, store, constants.NotesRef)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return repoNotes, layout.GitDir, store, nil
+}
+
+// runNotesAdd attaches message to object.
+func runNotesAdd(cmd *cobra.Command, args []string) error {
+	if len(noteMessagesFlag) == 0 {
+		return errors.New("no note message given; use -m <message>")
+	}
+
+	repoNotes, object, err := loadNotes(args)
+	if err != nil {
+		return err
+	}
+
+	if _, exists := repoNotes.Get(object); exists && !forceNoteFlag {
+		return fmt.Errorf("cannot add notes: found existing notes for object %s; use -f to overwrite them", object)
+	}
+
+	author, err := currentAuthor()
+	if err != nil {
+		return err
+	}
+
+	message := strings.Join(noteMessagesFlag, "\n\n")
+	if !strings.HasSuffix(message, "\n") {
+		message += "\n"
+	}
+	return repoNotes.Set(object, []byte(message), author, "Notes added by 'gogit notes add'")
+}
+
+// runNotesShow prints note content of object.
+func runNotesShow(cmd *cobra.Command, args []string) error {
+	repoNotes, object, err := loadNotes(args)
+	if err != nil {
+		return err
+	}
+
+	content, err := repoNotes.Read(object)
+	if err != nil {
+		return err
+	}
+
+	_, err = cmd.OutOrStdout().Write(content)
+	return err
+}
+
+// runNotesList prints every note, or the note blob hash of the given object.
+func runNotesList(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+
+	if len(args) == 1 {
+		repoNotes, object, err := loadNotes(args)
+		if err != nil {
+			return err
+		}
+		blobHash, ok := repoNotes.Get(object)
+		if !ok {
+			return fmt.Errorf("%w for object %s", notes.ErrNoteNotFound, object)
+		}
+		fmt.Fprintln(out, blobHash)
+		return nil
+	}
+
+	repoNotes, _, _, err := openNotes()
+	if err != nil {
+		return err
+	}
+	for _, note := range repoNotes.List() {
+		fmt.Fprintf(out, "%s %s\n", note.Blob, note.Object)
+	}
+	return nil
+}
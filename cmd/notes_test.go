@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// setupNotesRepo creates repository with one commit on the default branch and returns its hash.
+func setupNotesRepo(t *testing.T) (string, string) {
+	t.Helper()
+
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	t.Setenv(constants.AuthorNameEnv, "Note Author")
+	t.Setenv(constants.AuthorEmailEnv, "notes@example.com")
+
+	head := storeEmptyTreeHistory(t, objects.NewObjectStore(repository.GitDir(repoPath)), 1)[0]
+	writeTestRef(t, repoPath, "refs/heads/"+constants.DefaultBranch, head)
+	return repoPath, head
+}
+
+// TestNotesCommand verifies notes are added to HEAD, shown, and listed.
+func TestNotesCommand(t *testing.T) {
+	_, head := setupNotesRepo(t)
+
+	if _, _, err := runCmd(t, constants.NotesCmdName, "add", "-m", "First paragraph", "-m", "Second"); err != nil {
+		t.Fatalf("notes add failed: %v", err)
+	}
+
+	output, _, err := runCmd(t, constants.NotesCmdName, "show", head[:8])
+	if err != nil {
+		t.Fatalf("notes show failed: %v", err)
+	}
+	if expected := "First paragraph\n\nSecond\n"; output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+
+	blob := objects.NewBlob([]byte("First paragraph\n\nSecond\n")).Hash()
+	output, _, err = runCmd(t, constants.NotesCmdName)
+	if err != nil {
+		t.Fatalf("notes failed: %v", err)
+	}
+	if expected := blob + " " + head + "\n"; output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+
+	output, _, err = runCmd(t, constants.NotesCmdName, "list", head)
+	if err != nil || strings.TrimSpace(output) != blob {
+		t.Errorf("Expected %s, got %q, %v", blob, output, err)
+	}
+}
+
+// TestNotesCommand_Overwrite verifies existing notes are only replaced with -f.
+func TestNotesCommand_Overwrite(t *testing.T) {
+	_, head := setupNotesRepo(t)
+
+	if _, _, err := runCmd(t, constants.NotesCmdName, "add", "-m", "original", head); err != nil {
+		t.Fatalf("notes add failed: %v", err)
+	}
+	if _, _, err := runCmd(t, constants.NotesCmdName, "add", "-m", "replacement", head); err == nil || !strings.Contains(err.Error(), "use -f") {
+		t.Fatalf("Expected existing note error, got %v", err)
+	}
+	if _, _, err := runCmd(t, constants.NotesCmdName, "add", "-f", "-m", "replacement", head); err != nil {
+		t.Fatalf("notes add -f failed: %v", err)
+	}
+
+	output, _, _ := runCmd(t, constants.NotesCmdName, "show", head)
+	if output != "replacement\n" {
+		t.Errorf("Expected replaced note, got %q", output)
+	}
+}
+
+// TestNotesCommand_Refs verifies objects can be named by ref.
+func TestNotesCommand_Refs(t *testing.T) {
+	_, head := setupNotesRepo(t)
+
+	if _, _, err := runCmd(t, constants.NotesCmdName, "add", "-m", "on main", constants.DefaultBranch); err != nil {
+		t.Fatalf("notes add failed: %v", err)
+	}
+	output, _, err := runCmd(t, constants.NotesCmdName, "show", constants.Head)
+	if err != nil || output != "on main\n" {
+		t.Errorf("Expected the note of main through HEAD, got %q, %v", output, err)
+	}
+
+	blob := objects.NewBlob([]byte("on main\n")).Hash()
+	if output, _, err := runCmd(t, constants.NotesCmdName, "list", head); err != nil || strings.TrimSpace(output) != blob {
+		t.Errorf("Expected the note to be attached to %s, got %q, %v", head, output, err)
+	}
+}
+
+// TestNotesCommand_Errors verifies missing messages and notes are reported.
+func TestNotesCommand_Errors(t *testing.T) {
+	_, head := setupNotesRepo(t)
+
+	if _, _, err := runCmd(t, constants.NotesCmdName, "add", head); err == nil || !strings.Contains(err.Error(), "no note message") {
+		t.Errorf("Expected missing message error, got %v", err)
+	}
+	if _, _, err := runCmd(t, constants.NotesCmdName, "show", head); err == nil || !strings.Contains(err.Error(), "no note found") {
+		t.Errorf("Expected missing note error, got %v", err)
+	}
+}
@@ -0,0 +1,288 @@
+package cmd
+
+import (
+	"bufio"
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/index"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/refs"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/spf13/cobra"
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune [-n] [-v] [--expire <time>]",
+	Short: "Remove unreachable loose objects",
+	Long: `Delete loose objects that cannot be reached from HEAD, any ref, the index or
+the reflogs, along with temporary object files left by interrupted writes.
+
+Only objects last written before the expiry time are removed, two weeks ago
+unless gc.pruneExpire or --expire says otherwise. Writing an object that
+already exists refreshes its modification time, so objects that another
+command is still about to use survive. Objects reachable from such recent
+objects are kept as well.
+
+--expire takes "now", "never", a relative time such as "2.weeks.ago" or
+"3 days ago", a Unix timestamp, or a date as 2006-01-02 or in RFC 3339.
+-n lists what would be removed as "<hash> <type>" lines without removing it;
+-v lists it while removing.
+
+Examples:
+  # See what would go
+  gogit prune -n
+
+  # Remove every unreachable object now
+  gogit prune --expire=now`,
+	SilenceUsage: true,
+	Args:         noArgs(constants.PruneCmdName),
+	RunE:         runPrune,
+}
+
+// defaultPruneExpire is the grace period of unreachable objects when gc.pruneExpire is unset.
+const defaultPruneExpire = "2.weeks.ago"
+
+var (
+	pruneDryRunFlag  bool
+	pruneVerboseFlag bool
+	pruneExpireFlag  string
+)
+
+func init() {
+	rootCmd.AddCommand(pruneCmd)
+
+	pruneCmd.Flags().BoolVarP(&pruneDryRunFlag, "dry-run", "n", false, "List unreachable objects without removing them")
+	pruneCmd.Flags().BoolVarP(&pruneVerboseFlag, "verbose", "v", false, "List removed objects")
+	pruneCmd.Flags().StringVar(&pruneExpireFlag, "expire", "", "Only remove objects older than <time>")
+}
+
+// runPrune removes the unreachable loose objects older than the expiry time.
+func runPrune(cmd *cobra.Command, args []string) error {
+	layout, err := findRepoRoot()
+	if err != nil {
+		return err
+	}
+	expire, err := pruneExpiry(layout.GitDir)
+	if err != nil {
+		return err
+	}
+	// Replaced objects are still referenced by what is stored
+	store := objects.NewObjectStore(layout.GitDir, objects.WithReplaceObjects(false))
+
+	// Pruning around a missing object could delete more of the history it belongs to
+	seen := make(map[string]bool)
+	if err := markReachable(layout.GitDir, store, seen, nil); err != nil {
+		return err
+	}
+
+	// Objects written during the grace period may be about to be referenced, with what they reach
+	var recent, expired []string
+	err = store.ForEachObject(cmd.Context(), func(hash string) error {
+		if seen[hash] {
+			return nil
+		}
+		modTime, err := store.ModTime(hash)
+		if err != nil {
+			return err
+		}
+		if modTime.Before(expire) {
+			expired = append(expired, hash)
+		} else {
+			recent = append(recent, hash)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := store.Reachable(recent, seen, ignoreMissing); err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	for _, hash := range expired {
+		if seen[hash] {
+			continue
+		}
+		if pruneDryRunFlag || pruneVerboseFlag {
+			objectType, err := storedObjectType(store, hash)
+			if err != nil {
+				objectType = "unknown"
+			}
+			fmt.Fprintf(out, "%s %s\n", hash, objectType)
+		}
+		if !pruneDryRunFlag {
+			if err := store.Remove(hash); err != nil {
+				return err
+			}
+		}
+	}
+
+	if pruneDryRunFlag {
+		return nil
+	}
+	removed, err := store.RemoveTempObjects(expire)
+	for _, tempPath := range removed {
+		if pruneVerboseFlag {
+			fmt.Fprintf(out, "Removing stale temporary file %s\n", tempPath)
+		}
+	}
+	return err
+}
+
+// pruneExpiry returns the time before which unreachable objects are removed, from --expire,
+// gc.pruneExpire or the default.
+func pruneExpiry(gitDir string) (time.Time, error) {
+	value := pruneExpireFlag
+	if value == "" {
+		configured, err := repository.ConfigValue(gitDir, "gc", "pruneExpire")
+		if err != nil {
+			return time.Time{}, err
+		}
+		value = cmp.Or(configured, defaultPruneExpire)
+	}
+
+	expire, err := parseExpiry(value, time.Now())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("malformed expiration date '%s': %w", value, err)
+	}
+	return expire, nil
+}
+
+// expiryUnits maps the units of relative expiry times to the date offset of one of them.
+var expiryUnits = map[string]func(t time.Time, n int) time.Time{
+	"second": func(t time.Time, n int) time.Time { return t.Add(-time.Duration(n) * time.Second) },
+	"minute": func(t time.Time, n int) time.Time { return t.Add(-time.Duration(n) * time.Minute) },
+	"hour":   func(t time.Time, n int) time.Time { return t.Add(-time.Duration(n) * time.Hour) },
+	"day":    func(t time.Time, n int) time.Time { return t.AddDate(0, 0, -n) },
+	"week":   func(t time.Time, n int) time.Time { return t.AddDate(0, 0, -7*n) },
+	"month":  func(t time.Time, n int) time.Time { return t.AddDate(0, -n, 0) },
+	"year":   func(t time.Time, n int) time.Time { return t.AddDate(-n, 0, 0) },
+}
+
+// parseExpiry parses an expiry time relative to now: "now", "never" (the zero time, before every
+// object), "<n>.<unit>.ago" with dots or spaces, a Unix timestamp, or a date.
+func parseExpiry(value string, now time.Time) (time.Time, error) {
+	switch value {
+	case "now":
+		return now, nil
+	case "never":
+		return time.Time{}, nil
+	}
+
+	fields := strings.FieldsFunc(value, func(c rune) bool { return c == '.' || c == ' ' })
+	if len(fields) == 3 && fields[2] == "ago" {
+		n, err := strconv.Atoi(fields[0])
+		offset, ok := expiryUnits[strings.TrimSuffix(fields[1], "s")]
+		if err != nil || n < 0 || !ok {
+			return time.Time{}, errors.New("expected <n>.<unit>.ago")
+		}
+		return offset(now, n), nil
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateTime, time.DateOnly} {
+		if date, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, errors.New("unknown date format")
+}
+
+// markReachable adds to seen every object reachable from HEAD, the refs, the index entries and
+// the reflogs. Objects the reflogs name may be gone already. Any other object that cannot be read
+// is passed to unreadable, as by ObjectStore.Reachable; with a nil unreadable it is an error.
+func markReachable(gitDir string, store *objects.ObjectStore, seen map[string]bool, unreadable func(hash string, err error) error) error {
+	var tips []string
+	head, err := refs.Resolve(gitDir, constants.Head)
+	if err == nil {
+		tips = append(tips, head)
+	} else if !errors.Is(err, refs.ErrRefNotFound) {
+		return err
+	}
+	err = refs.ForEach(gitDir, constants.Refs+"/", func(ref refs.Ref) error {
+		tips = append(tips, ref.Hash)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	idx, err := index.Read(gitDir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range idx.Entries() {
+		if entry.Mode != objects.ModeSubmodule {
+			tips = append(tips, entry.Hash)
+		}
+	}
+	if _, err := store.Reachable(tips, seen, unreadable); err != nil {
+		return err
+	}
+
+	reflogTips, err := reflogObjects(gitDir)
+	if err != nil {
+		return err
+	}
+	_, err = store.Reachable(reflogTips, seen, ignoreMissing)
+	return err
+}
+
+// reflogObjects returns the old and new objects of every entry of the reflogs Git keeps under
+// logs/, which gogit reads but does not write.
+func reflogObjects(gitDir string) ([]string, error) {
+	var hashes []string
+	logsDir := filepath.Join(gitDir, "logs")
+	err := filepath.WalkDir(logsDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			for _, hash := range fields[:min(2, len(fields))] {
+				if len(hash) == constants.HashStringLength {
+					hashes = append(hashes, hash)
+				}
+			}
+		}
+		return scanner.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reflogs: %w", err)
+	}
+	return hashes, nil
+}
+
+// ignoreMissing lets a reachability walk go on past objects that are already gone, but not past
+// objects that cannot be read.
+func ignoreMissing(hash string, err error) error {
+	if errors.Is(err, objects.ErrObjectNotFound) {
+		return nil
+	}
+	return fmt.Errorf("failed to read object %s: %w", hash, err)
+}
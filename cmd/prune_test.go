@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/index"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// ageObjects sets the modification time of every loose object to a month ago.
+func ageObjects(t *testing.T, repoPath string) {
+	t.Helper()
+
+	old := time.Now().AddDate(0, -1, 0)
+	objectsDir := filepath.Join(repoPath, constants.Gogit, constants.Objects)
+	err := filepath.WalkDir(objectsDir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		return os.Chtimes(path, old, old)
+	})
+	if err != nil {
+		t.Fatalf("Failed to age objects: %v", err)
+	}
+}
+
+// TestPruneCommand verifies only old objects unreachable from refs, the index and recent
+// objects are listed by -n and then removed.
+func TestPruneCommand(t *testing.T) {
+	repoPath, history := setupRefHistory(t)
+	gitDir := repository.GitDir(repoPath)
+	store := objects.NewObjectStore(gitDir)
+	blobs := storeTestBlobs(t, repoPath, "unreachable\n", "staged\n", "kept by a recent tree\n")
+	unreachable, staged, kept := blobs[0], blobs[1], blobs[2]
+
+	info, _ := os.Lstat(testutils.CreateTestFile(t, repoPath, "staged.txt", []byte("staged\n")))
+	entry, err := index.NewEntry("staged.txt", staged, info)
+	if err != nil {
+		t.Fatalf("Failed to create entry: %v", err)
+	}
+	err = index.Update(gitDir, func(idx *index.Index) error {
+		idx.Add(*entry)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to update index: %v", err)
+	}
+	ageObjects(t, repoPath)
+
+	treeEntry, _ := objects.NewTreeEntry(objects.ModeRegularFile, "kept.txt", kept)
+	recentTree, _ := objects.NewTree([]objects.TreeEntry{*treeEntry})
+	if err := store.Store(recentTree); err != nil {
+		t.Fatalf("Failed to store tree: %v", err)
+	}
+
+	output, _, err := runCmd(t, constants.PruneCmdName, "-n")
+	if err != nil {
+		t.Fatalf("%s -n failed: %v", constants.PruneCmdName, err)
+	}
+	if output != unreachable+" blob\n" {
+		t.Errorf("Expected only %s listed, got %q", unreachable, output)
+	}
+	if !store.Exists(unreachable) {
+		t.Error("Expected -n to remove nothing")
+	}
+
+	if output, _, err = runCmd(t, constants.PruneCmdName); err != nil || output != "" {
+		t.Fatalf("%s command failed: %q, %v", constants.PruneCmdName, output, err)
+	}
+	if store.Exists(unreachable) {
+		t.Error("Expected the unreachable blob to be removed")
+	}
+	for _, hash := range append(slices.Clone(history), staged, kept, recentTree.Hash()) {
+		if !store.Exists(hash) {
+			t.Errorf("Expected %s to be kept", hash)
+		}
+	}
+}
+
+// TestPruneCommand_Expire verifies --expire and gc.pruneExpire move the grace period, and that
+// writing an existing object again restarts it.
+func TestPruneCommand_Expire(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	store := objects.NewObjectStore(repository.GitDir(repoPath))
+	blobs := storeTestBlobs(t, repoPath, "rewritten\n", "fresh\n")
+	ageObjects(t, repoPath)
+	storeTestBlobs(t, repoPath, "rewritten\n")
+
+	output, _, err := runCmd(t, constants.PruneCmdName, "-n", "--expire=2.months.ago")
+	if err != nil || output != "" {
+		t.Errorf("Expected nothing older than two months, got %q, %v", output, err)
+	}
+	if output, _, err = runCmd(t, constants.PruneCmdName, "-n"); err != nil || output != blobs[1]+" blob\n" {
+		t.Errorf("Expected only %s past the default grace period, got %q, %v", blobs[1], output, err)
+	}
+
+	configPath := filepath.Join(repoPath, constants.Gogit, "config")
+	config, _ := os.ReadFile(configPath)
+	if err := os.WriteFile(configPath, append(config, "[gc]\n\tpruneExpire = never\n"...), constants.FilePerms); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if output, _, err = runCmd(t, constants.PruneCmdName, "-v"); err != nil || output != "" {
+		t.Errorf("Expected gc.pruneExpire=never to keep everything, got %q, %v", output, err)
+	}
+
+	if output, _, err = runCmd(t, constants.PruneCmdName, "-v", "--expire", "now"); err != nil {
+		t.Fatalf("%s --expire now failed: %v", constants.PruneCmdName, err)
+	}
+	for _, hash := range blobs {
+		if !strings.Contains(output, hash+" blob\n") || store.Exists(hash) {
+			t.Errorf("Expected %s to be listed and removed, got %q", hash, output)
+		}
+	}
+
+	if _, _, err := runCmd(t, constants.PruneCmdName, "--expire", "yesterday-ish"); err == nil {
+		t.Error("Expected a malformed expiry to be rejected")
+	}
+}
+
+// TestPruneCommand_MissingObject verifies nothing is removed when a ref leads to a missing object.
+func TestPruneCommand_MissingObject(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	store := objects.NewObjectStore(repository.GitDir(repoPath))
+	unreachable := storeTestBlobs(t, repoPath, "unreachable\n")[0]
+	writeTestRef(t, repoPath, constants.BranchRefPrefix+constants.DefaultBranch, testutils.RandomHash())
+
+	if _, _, err := runCmd(t, constants.PruneCmdName, "--expire=now"); err == nil {
+		t.Fatal("Expected error for a missing object")
+	}
+	if !store.Exists(unreachable) {
+		t.Error("Expected nothing to be removed")
+	}
+}
+
+// TestParseExpiry verifies the accepted expiry formats.
+func TestParseExpiry(t *testing.T) {
+	now := time.Date(2024, 3, 31, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value    string
+		expected time.Time
+	}{
+		{"now", now},
+		{"never", time.Time{}},
+		{"2.weeks.ago", now.AddDate(0, 0, -14)},
+		{"1 month ago", now.AddDate(0, -1, 0)},
+		{"90.minutes.ago", now.Add(-90 * time.Minute)},
+		{"1700000000", time.Unix(1700000000, 0)},
+		{"2024-01-02T03:04:05Z", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		expire, err := parseExpiry(test.value, now)
+		if err != nil || !expire.Equal(test.expected) {
+			t.Errorf("Expected %q to give %v, got %v, %v", test.value, test.expected, expire, err)
+		}
+	}
+
+	for _, value := range []string{"", "2.fortnights.ago", "soon", "-1.days.ago"} {
+		if _, err := parseExpiry(value, now); err == nil {
+			t.Errorf("Expected error parsing %q", value)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/utils"
+	"github.com/spf13/cobra"
+)
+
+var recoverCmd = &cobra.Command{
+	Use:   "recover",
+	Short: "List lost commits that can still be recovered",
+	Long: `List the dangling commits of the repository, newest first: commits no ref,
+the index or the reflogs lead to and that no other lost commit has as parent,
+such as the old tip of a branch moved by a reset. Each is shown as
+"<hash> <date> <subject>", with its committer date.
+
+A listed commit and its history are kept by pointing a ref at it, for
+instance with gogit tag. Until then, gogit prune removes them once they are
+older than its grace period.
+
+Examples:
+  # Find the commit lost by a reset and keep it
+  gogit recover
+  gogit tag rescued 1a2b3c4`,
+	SilenceUsage: true,
+	Args:         noArgs(constants.RecoverCmdName),
+	RunE:         runRecover,
+}
+
+// recoverAbbrevLength is the number of hash characters shown for each lost commit.
+const recoverAbbrevLength = 7
+
+// recoverDateFormat formats the committer date of lost commits.
+const recoverDateFormat = "2006-01-02 15:04"
+
+func init() {
+	rootCmd.AddCommand(recoverCmd)
+}
+
+// runRecover prints the dangling commits, most recently committed first.
+func runRecover(cmd *cobra.Command, args []string) error {
+	layout, err := findRepoRoot()
+	if err != nil {
+		return err
+	}
+	store := objects.NewObjectStore(layout.GitDir, objects.WithReplaceObjects(false))
+
+	// Broken history is fsck's to report; what can be read is still worth listing
+	seen := make(map[string]bool)
+	err = markReachable(layout.GitDir, store, seen, func(string, error) error { return nil })
+	if err != nil {
+		return err
+	}
+	unreachable, err := findUnreachable(cmd.Context(), store, seen)
+	if err != nil {
+		return err
+	}
+
+	var lost []*objects.Commit
+	for _, object := range unreachable {
+		if !object.dangling || object.objectType != utils.CommitObjectType {
+			continue
+		}
+		commit, err := store.ReadCommit(object.hash)
+		if err != nil {
+			return err
+		}
+		lost = append(lost, commit)
+	}
+	if len(lost) == 0 {
+		cmd.PrintErrln("No lost commits found.")
+		return nil
+	}
+
+	slices.SortStableFunc(lost, func(a, b *objects.Commit) int {
+		return b.Committer().Timestamp.Compare(a.Committer().Timestamp)
+	})
+	out := cmd.OutOrStdout()
+	for _, commit := range lost {
+		fmt.Fprintf(out, "%s %s %s\n", commit.Hash()[:recoverAbbrevLength],
+			commit.Committer().Timestamp.Format(recoverDateFormat), commit.Subject())
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/repository"
+)
+
+// TestRecoverCommand verifies dangling commits are listed newest first with their date and
+// subject, leaving out their lost ancestors.
+func TestRecoverCommand(t *testing.T) {
+	_, history := setupRefHistory(t)
+
+	stdout, stderr, err := runCmd(t, constants.RecoverCmdName)
+	if err != nil || stdout != "" || !strings.Contains(stderr, "No lost commits") {
+		t.Errorf("Expected no lost commits, got %q, %q, %v", stdout, stderr, err)
+	}
+
+	store := objects.NewObjectStore(repository.GitDir("."))
+	older := storeLostCommit(t, store, history[0], "Abandoned experiment\n\nDetails", 1800000000)
+	lost := storeLostCommit(t, store, history[2], "lost work", 1800000100)
+	newer := storeLostCommit(t, store, lost, "Reset away by mistake", 1800000200)
+
+	stdout, _, err = runCmd(t, constants.RecoverCmdName)
+	if err != nil {
+		t.Fatalf("%s command failed: %v", constants.RecoverCmdName, err)
+	}
+	expected := newer[:recoverAbbrevLength] + " " + time.Unix(1800000200, 0).UTC().Format(recoverDateFormat) + " Reset away by mistake\n" +
+		older[:recoverAbbrevLength] + " " + time.Unix(1800000000, 0).UTC().Format(recoverDateFormat) + " Abandoned experiment\n"
+	if stdout != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, stdout)
+	}
+}
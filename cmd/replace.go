@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/refs"
+	"github.com/spf13/cobra"
+)
+
+var replaceCmd = &cobra.Command{
+	Use:   "replace [-f] <object> <replacement> | -d <object>... | [--format=<format>] [-l [<pattern>]]",
+	Short: "Create, list or delete refs to replace objects",
+	Long: `With an object and a replacement, create refs/replace/<object> so that every
+command reading the object reads the replacement instead, without rewriting
+the history that refers to it. Both must have the same type unless -f is
+given, which also overwrites an existing replacement.
+
+Without arguments, or with -l, list replaced objects whose hashes match the
+glob pattern. --format chooses what is printed for each: short (the replaced
+object), medium (the object and its replacement) or long (both with their
+types). -d deletes the replacements of the given objects.
+
+Replacements are ignored by commands run with --no-replace-objects or with
+GOGIT_NO_REPLACE_OBJECTS set.
+
+Examples:
+  # Make history show a fixed commit in place of a broken one
+  gogit replace 1a2b3c4 5d6e7f8
+
+  # List replacements with their types
+  gogit replace -l --format=long
+
+  # Remove a replacement
+  gogit replace -d 1a2b3c4`,
+	SilenceUsage: true,
+	Args:         replaceArgs,
+	RunE:         runReplace,
+}
+
+// Formats replace -l lists replacements in.
+const (
+	replaceFormatShort  = "short"
+	replaceFormatMedium = "medium"
+	replaceFormatLong   = "long"
+)
+
+var (
+	replaceListFlag   bool
+	replaceDeleteFlag bool
+	replaceForceFlag  bool
+	replaceFormatFlag string
+)
+
+func init() {
+	rootCmd.AddCommand(replaceCmd)
+
+	replaceCmd.Flags().BoolVarP(&replaceListFlag, "list", "l", false, "List replaced objects matching the given pattern")
+	replaceCmd.Flags().BoolVarP(&replaceDeleteFlag, "delete", "d", false, "Delete the replacements of the given objects")
+	replaceCmd.Flags().BoolVarP(&replaceForceFlag, "force", "f", false, "Overwrite an existing replacement and allow a different type")
+	replaceCmd.Flags().StringVar(&replaceFormatFlag, "format", "", "Listing format: short, medium or long")
+}
+
+// replaceListing reports whether replace runs in list mode, explicitly or for lack of arguments.
+func replaceListing(args []string) bool {
+	return replaceListFlag || (len(args) == 0 && !replaceDeleteFlag)
+}
+
+// replaceArgs rejects combinations of listing, deleting and creating replacements.
+// Enables usage printing in case of error.
+func replaceArgs(cmd *cobra.Command, args []string) error {
+	var err error
+	switch {
+	case replaceDeleteFlag && (replaceListFlag || len(args) == 0):
+		err = fmt.Errorf("%s -d requires at least 1 argument (object) and cannot be combined with -l", constants.ReplaceCmdName)
+	case replaceForceFlag && (replaceDeleteFlag || replaceListing(args)):
+		err = errors.New("-f only makes sense when writing a replacement")
+	case replaceFormatFlag != "" && !replaceListing(args):
+		err = errors.New("--format cannot be used when not listing")
+	case replaceListing(args) && len(args) > 1:
+		err = errors.New("only one pattern can be given with -l")
+	case !replaceDeleteFlag && !replaceListing(args) && len(args) != 2:
+		err = fmt.Errorf("%s command requires 2 arg(s) when creating a replacement, received %d", constants.ReplaceCmdName, len(args))
+	default:
+		return nil
+	}
+	cmd.SilenceUsage = false
+	return err
+}
+
+// runReplace dispatches to deleting, listing or creating replacements. Objects are read
+// as stored, since replacements are what is being managed.
+func runReplace(cmd *cobra.Command, args []string) error {
+	layout, err := findRepoRoot()
+	if err != nil {
+		return err
+	}
+	store := objects.NewObjectStore(layout.GitDir, objects.WithReplaceObjects(false))
+
+	switch {
+	case replaceDeleteFlag:
+		return deleteReplacements(cmd, layout.GitDir, store, args)
+	case replaceListing(args):
+		return listReplacements(cmd.OutOrStdout(), layout.GitDir, store, args)
+	default:
+		return createReplacement(layout.GitDir, store, args[0], args[1])
+	}
+}
+
+// createReplacement points refs/replace/<object> at replacement after checking both
+// objects have the same type and, without -f, that object is not replaced already.
+func createReplacement(gitDir string, store *objects.ObjectStore, objectName, replacementName string) error {
+	object, err := resolveObjectName(gitDir, store, objectName)
+	if err != nil {
+		return fmt.Errorf("failed to resolve '%s' as a valid ref: %w", objectName, err)
+	}
+	replacement, err := resolveObjectName(gitDir, store, replacementName)
+	if err != nil {
+		return fmt.Errorf("failed to resolve '%s' as a valid ref: %w", replacementName, err)
+	}
+	if object == replacement {
+		return fmt.Errorf("new object is the same as the old one: '%s'", object)
+	}
+
+	objectType, err := storedObjectType(store, object)
+	if err != nil {
+		return fmt.Errorf("unable to get object type for %s: %w", object, err)
+	}
+	replacementType, err := storedObjectType(store, replacement)
+	if err != nil {
+		return fmt.Errorf("unable to get object type for %s: %w", replacement, err)
+	}
+	if objectType != replacementType && !replaceForceFlag {
+		return fmt.Errorf("objects must be of the same type: '%s' points to a replaced object of type '%s' while '%s' points to a replacement object of type '%s'",
+			objectName, objectType, replacementName, replacementType)
+	}
+
+	ref := constants.ReplaceRefPrefix + object
+	if _, err := refs.Resolve(gitDir, ref); err == nil && !replaceForceFlag {
+		return fmt.Errorf("replace ref '%s' already exists", ref)
+	} else if err != nil && !errors.Is(err, refs.ErrRefNotFound) {
+		return err
+	}
+	return refs.Update(gitDir, ref, replacement)
+}
+
+// deleteReplacements removes the replacement of each named object.
+// All names are attempted; the error lists those that were not replaced.
+func deleteReplacements(cmd *cobra.Command, gitDir string, store *objects.ObjectStore, names []string) error {
+	var missing []string
+	for _, name := range names {
+		object, err := resolveObjectName(gitDir, store, name)
+		if err != nil {
+			return fmt.Errorf("failed to resolve '%s' as a valid ref: %w", name, err)
+		}
+
+		ref := constants.ReplaceRefPrefix + object
+		if _, err := refs.Resolve(gitDir, ref); errors.Is(err, refs.ErrRefNotFound) {
+			missing = append(missing, ref)
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if err := refs.Delete(gitDir, ref); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Deleted replace ref '%s'\n", object)
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("replace ref not found: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// listReplacements prints replaced objects whose hashes match the optional pattern, in the --format layout.
+func listReplacements(out io.Writer, gitDir string, store *objects.ObjectStore, patterns []string) error {
+	format := replaceFormatFlag
+	switch format {
+	case "":
+		format = replaceFormatShort
+	case replaceFormatShort, replaceFormatMedium, replaceFormatLong:
+	default:
+		return fmt.Errorf("invalid replace format '%s'; valid formats are '%s', '%s' and '%s'",
+			format, replaceFormatShort, replaceFormatMedium, replaceFormatLong)
+	}
+
+	replaced, err := refs.List(gitDir, constants.ReplaceRefPrefix)
+	if err != nil {
+		return err
+	}
+	for _, ref := range replaced {
+		object := strings.TrimPrefix(ref.Name, constants.ReplaceRefPrefix)
+		if len(patterns) > 0 {
+			if matched, err := path.Match(patterns[0], object); err != nil || !matched {
+				continue
+			}
+		}
+
+		switch format {
+		case replaceFormatShort:
+			fmt.Fprintln(out, object)
+		case replaceFormatMedium:
+			fmt.Fprintf(out, "%s -> %s\n", object, ref.Hash)
+		case replaceFormatLong:
+			objectType, err := storedObjectType(store, object)
+			if err != nil {
+				return fmt.Errorf("unable to get object type for %s: %w", object, err)
+			}
+			replacementType, err := storedObjectType(store, ref.Hash)
+			if err != nil {
+				return fmt.Errorf("unable to get object type for %s: %w", ref.Hash, err)
+			}
+			fmt.Fprintf(out, "%s (%s) -> %s (%s)\n", object, objectType, ref.Hash, replacementType)
+		}
+	}
+	return nil
+}
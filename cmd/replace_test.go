@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// storeTestBlobs stores a blob for each content and returns their hashes.
+func storeTestBlobs(t *testing.T, repoPath string, contents ...string) []string {
+	t.Helper()
+
+	store := objects.NewObjectStore(repository.GitDir(repoPath))
+	var hashes []string
+	for _, content := range contents {
+		blob := objects.NewBlob([]byte(content))
+		if err := store.Store(blob); err != nil {
+			t.Fatalf("Failed to store blob: %v", err)
+		}
+		hashes = append(hashes, blob.Hash())
+	}
+	return hashes
+}
+
+// TestReplaceCommand verifies a replacement is read in place of the object until it is
+// deleted, and ignored when replacements are disabled.
+func TestReplaceCommand(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	blobs := storeTestBlobs(t, repoPath, "original\n", "replacement\n")
+
+	if _, _, err := runCmd(t, constants.ReplaceCmdName, blobs[0][:7], blobs[1]); err != nil {
+		t.Fatalf("%s command failed: %v", constants.ReplaceCmdName, err)
+	}
+	if output, _, err := runCmd(t, constants.CatFileCmdName, "-p", blobs[0]); err != nil || output != "replacement\n" {
+		t.Errorf("Expected the replacement to be read, got %q and %v", output, err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"short", nil, blobs[0] + "\n"},
+		{"medium", []string{"--format=medium"}, fmt.Sprintf("%s -> %s\n", blobs[0], blobs[1])},
+		{"long", []string{"-l", "--format=long", blobs[0][:2] + "*"}, fmt.Sprintf("%s (blob) -> %s (blob)\n", blobs[0], blobs[1])},
+		{"unmatched pattern", []string{"-l", "none"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, _, err := runCmd(t, constants.ReplaceCmdName, tt.args...)
+			if err != nil {
+				t.Fatalf("%s command failed: %v", constants.ReplaceCmdName, err)
+			}
+			if output != tt.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.expected, output)
+			}
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Setenv(constants.NoReplaceObjectsEnv, "1")
+		if output, _, err := runCmd(t, constants.CatFileCmdName, "-p", blobs[0]); err != nil || output != "original\n" {
+			t.Errorf("Expected the original to be read, got %q and %v", output, err)
+		}
+	})
+
+	t.Run("disabled by flag", func(t *testing.T) {
+		noReplaceObjectsFlag = true
+		defer func() { noReplaceObjectsFlag = false }()
+		if output, _, err := runCmd(t, constants.CatFileCmdName, "-p", blobs[0]); err != nil || output != "original\n" {
+			t.Errorf("Expected the original to be read, got %q and %v", output, err)
+		}
+		if env, ok := os.LookupEnv(constants.NoReplaceObjectsEnv); ok {
+			t.Errorf("Expected %s to be left unset, got %q", constants.NoReplaceObjectsEnv, env)
+		}
+	})
+
+	output, _, err := runCmd(t, constants.ReplaceCmdName, "-d", blobs[0])
+	if err != nil {
+		t.Fatalf("%s -d failed: %v", constants.ReplaceCmdName, err)
+	}
+	if output != fmt.Sprintf("Deleted replace ref '%s'\n", blobs[0]) {
+		t.Errorf("Unexpected delete output %q", output)
+	}
+	if output, _, err := runCmd(t, constants.CatFileCmdName, "-p", blobs[0]); err != nil || output != "original\n" {
+		t.Errorf("Expected the original to be read after deletion, got %q and %v", output, err)
+	}
+}
+
+// TestReplaceCommand_Errors verifies replacements are refused across types, over existing ones
+// without -f, and for missing refs when deleting.
+func TestReplaceCommand_Errors(t *testing.T) {
+	repoPath, history := setupRefHistory(t)
+	blobs := storeTestBlobs(t, repoPath, "a\n", "b\n", "c\n")
+
+	if _, _, err := runCmd(t, constants.ReplaceCmdName, blobs[0], blobs[1]); err != nil {
+		t.Fatalf("%s command failed: %v", constants.ReplaceCmdName, err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"different type", []string{history[0], blobs[0]}, "objects must be of the same type"},
+		{"existing", []string{blobs[0], blobs[2]}, "replace ref 'refs/replace/" + blobs[0] + "' already exists"},
+		{"same object", []string{blobs[1], blobs[1]}, "new object is the same as the old one"},
+		{"unresolved", []string{"missing", blobs[1]}, "failed to resolve 'missing' as a valid ref"},
+		{"not replaced", []string{"-d", blobs[1]}, "replace ref not found: refs/replace/" + blobs[1]},
+		{"bad format", []string{"--format=full"}, "invalid replace format 'full'"},
+		{"format when creating", []string{"--format=long", blobs[1], blobs[2]}, "--format cannot be used when not listing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := runCmd(t, constants.ReplaceCmdName, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	if _, _, err := runCmd(t, constants.ReplaceCmdName, "-f", blobs[0], blobs[2]); err != nil {
+		t.Fatalf("%s -f failed: %v", constants.ReplaceCmdName, err)
+	}
+	if output, _, err := runCmd(t, constants.CatFileCmdName, "-p", blobs[0]); err != nil || output != "c\n" {
+		t.Errorf("Expected the forced replacement to be read, got %q and %v", output, err)
+	}
+}
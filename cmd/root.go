@@ -1,8 +1,19 @@
 package cmd
 
 import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
 
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/pathspec"
+	"github.com/KostasZigo/gogit/internal/refs"
+	"github.com/KostasZigo/gogit/internal/repository"
 	"github.com/spf13/cobra"
 )
 
@@ -13,13 +24,100 @@ var rootCmd = &cobra.Command{
 	Use:   "gogit",
 	Short: "A simplified Git implementation in GO",
 	Long: `GoGit is a simplified Git Implementation developed in GO that offers the main capabilites
-	and features expected from a Git project like init, add, commit etc.`,
+	and features expected from a Git project like init, add, commit etc.
+
+Repository metadata lives in .gogit by default. --git-dir (or GOGIT_DIR) points
+gogit at another metadata directory, such as the .git directory of an existing
+Git clone, with the current directory as the worktree root. Repositories using
+Git features gogit does not support yet, such as packfiles, are refused.
+
+Objects named by refs/replace/ are read in place of the objects they replace
+(see gogit replace); --no-replace-objects (or GOGIT_NO_REPLACE_OBJECTS) reads
+the original objects instead.`,
+}
+
+var (
+	gitDirFlag           string
+	noReplaceObjectsFlag bool
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&gitDirFlag, "git-dir", "", "Use <path> as the repository metadata directory, like GOGIT_DIR")
+	rootCmd.PersistentFlags().BoolVar(&noReplaceObjectsFlag, "no-replace-objects", false, "Do not read replacement objects, like GOGIT_NO_REPLACE_OBJECTS")
+}
+
+// newObjectStore opens the object store in gitDir. --no-replace-objects turns replacement objects
+// off; without it the store follows GOGIT_NO_REPLACE_OBJECTS.
+func newObjectStore(gitDir string, opts ...objects.StoreOption) *objects.ObjectStore {
+	if noReplaceObjectsFlag {
+		opts = append(opts, objects.WithReplaceObjects(false))
+	}
+	return objects.NewObjectStore(gitDir, opts...)
+}
+
+// explicitGitDir returns the metadata directory named by --git-dir, or else GOGIT_DIR, or "".
+func explicitGitDir() string {
+	return cmp.Or(gitDirFlag, os.Getenv(constants.GitDirEnv))
+}
+
+// findRepoRoot discovers the repository containing the working directory. An explicit metadata
+// directory is used as it is, with the working directory as the root.
+func findRepoRoot() (repository.Layout, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return repository.Layout{}, err
+	}
+	return repository.Discover(dir, explicitGitDir())
+}
+
+// enterRepository finds the repository at exactly path, such as a local remote.
+func enterRepository(path string) (repository.Layout, error) {
+	layout, err := repository.Open(path)
+	if err != nil {
+		return repository.Layout{}, fmt.Errorf("'%s' does not appear to be a repository: %w", path, err)
+	}
+	return layout, nil
+}
+
+// resolveObjectName returns the object a ref name such as "main" or "HEAD", or an object
+// name prefix, refers to.
+func resolveObjectName(gitDir string, store *objects.ObjectStore, name string) (string, error) {
+	ref, err := refs.Expand(gitDir, name)
+	if err == nil {
+		return ref.Hash, nil
+	}
+	if !errors.Is(err, refs.ErrRefNotFound) {
+		return "", err
+	}
+	return store.ResolvePrefix(name)
+}
+
+// parsePathspec parses pathspec arguments, which are relative to the working directory
+// unless their magic says otherwise.
+func parsePathspec(repoPath string, args []string) (*pathspec.Pathspec, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	prefix, err := filepath.Rel(repoPath, dir)
+	if err != nil {
+		return nil, err
+	}
+	if prefix == "." {
+		prefix = ""
+	}
+	return pathspec.Parse(filepath.ToSlash(prefix), args)
 }
 
 // Execute runs the root command and handles exit codes.
 // Called from main.go to start CLI execution.
+// An interrupt (Ctrl-C) cancels the command context so long-running commands stop cleanly.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+
+	if err != nil {
 		os.Exit(1)
 	}
 }
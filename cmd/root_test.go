@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// TestFindRepoRoot_ExplicitGitDir verifies --git-dir and GOGIT_DIR name the metadata directory
+// of the working directory without being exported to the environment, and that commands use it.
+func TestFindRepoRoot_ExplicitGitDir(t *testing.T) {
+	gitDir := filepath.Join(testutils.SetupTestRepoWithInit(t), constants.Gogit)
+	worktree := t.TempDir()
+	changeToRepoDir(t, worktree)
+	t.Setenv(constants.GitDirEnv, "")
+
+	gitDirFlag = gitDir
+	t.Cleanup(func() { gitDirFlag = "" })
+	layout, err := findRepoRoot()
+	if err != nil {
+		t.Fatalf("findRepoRoot failed: %v", err)
+	}
+	expected := repository.Layout{Root: worktree, GitDir: gitDir}
+	if layout != expected {
+		t.Errorf("Expected %+v, got %+v", expected, layout)
+	}
+	if env := os.Getenv(constants.GitDirEnv); env != "" {
+		t.Errorf("Expected %s to be left unset, got %q", constants.GitDirEnv, env)
+	}
+
+	gitDirFlag = ""
+	t.Setenv(constants.GitDirEnv, gitDir)
+	testutils.CreateTestFile(t, worktree, "file.txt", []byte("content\n"))
+	output, _, err := runCmd(t, constants.HashObjectCmdName, "-w", "file.txt")
+	if err != nil {
+		t.Fatalf("%s command failed: %v", constants.HashObjectCmdName, err)
+	}
+	if !objects.NewObjectStore(gitDir).Exists(strings.TrimSpace(output)) {
+		t.Errorf("Expected the object to be written to %s", gitDir)
+	}
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/mailmap"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/spf13/cobra"
+)
+
+var shortlogCmd = &cobra.Command{
+	Use:   "shortlog [-s] [-n] [-e] <commit>...",
+	Short: "Summarize commit history by author",
+	Long: `Group commits reachable from the given commits by author and print
+each author with their commit count and subjects, oldest first.
+Authors are canonicalized through the repository's .mailmap file.
+History is followed through every parent of merges, as in Git.
+
+Examples:
+  # List commit subjects per author
+  gogit shortlog HEAD
+
+  # Show only counts, most active authors first, with emails
+  gogit shortlog -sne 1a2b3c4d`,
+	SilenceUsage: true,
+	Args:         shortlogArgs,
+	RunE:         runShortlog,
+}
+
+var (
+	summaryFlag  bool
+	numberedFlag bool
+	emailFlag    bool
+)
+
+func init() {
+	rootCmd.AddCommand(shortlogCmd)
+
+	shortlogCmd.Flags().BoolVarP(&summaryFlag, "summary", "s", false, "Print only commit counts per author")
+	shortlogCmd.Flags().BoolVarP(&numberedFlag, "numbered", "n", false, "Sort authors by number of commits instead of by name")
+	shortlogCmd.Flags().BoolVarP(&emailFlag, "email", "e", false, "Show email address of each author")
+}
+
+// shortlogArgs requires at least one starting commit.
+// Enables usage printing in case of error.
+func shortlogArgs(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		cmd.SilenceUsage = false
+		return fmt.Errorf("%s command requires at least 1 argument (commit), received 0", constants.ShortlogCmdName)
+	}
+	return nil
+}
+
+// shortlogGroup collects subjects of one author, newest first as walked.
+type shortlogGroup struct {
+	author   string
+	subjects []string
+}
+
+// runShortlog groups reachable commits by author and prints summary.
+func runShortlog(cmd *cobra.Command, args []string) error {
+	layout, err := findRepoRoot()
+	if err != nil {
+		return err
+	}
+	store := newObjectStore(layout.GitDir)
+
+	identities, err := mailmap.Load(layout.Root)
+	if err != nil {
+		return err
+	}
+
+	groups := make(map[string]*shortlogGroup)
+	err = walkCommits(cmd.Context(), layout.GitDir, store, args, func(commit *objects.Commit) error {
+		name, email := identities.Map(commit.Author().Name, commit.Author().Email)
+		author := name
+		if emailFlag {
+			author = fmt.Sprintf("%s <%s>", name, email)
+		}
+
+		group, ok := groups[author]
+		if !ok {
+			group = &shortlogGroup{author: author}
+			groups[author] = group
+		}
+		group.subjects = append(group.subjects, commit.Subject())
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	printShortlog(cmd.OutOrStdout(), sortShortlogGroups(groups))
+	return nil
+}
+
+// sortShortlogGroups orders groups by author, or by descending count when numbered.
+func sortShortlogGroups(groups map[string]*shortlogGroup) []*shortlogGroup {
+	sorted := make([]*shortlogGroup, 0, len(groups))
+	for _, group := range groups {
+		sorted = append(sorted, group)
+	}
+
+	slices.SortFunc(sorted, func(a, b *shortlogGroup) int {
+		if numberedFlag && len(a.subjects) != len(b.subjects) {
+			return len(b.subjects) - len(a.subjects)
+		}
+		return strings.Compare(a.author, b.author)
+	})
+	return sorted
+}
+
+// printShortlog writes groups in Git's shortlog format.
+func printShortlog(out io.Writer, groups []*shortlogGroup) {
+	for _, group := range groups {
+		if summaryFlag {
+			fmt.Fprintf(out, "%6d\t%s\n", len(group.subjects), group.author)
+			continue
+		}
+
+		fmt.Fprintf(out, "%s (%d):\n", group.author, len(group.subjects))
+		for i := len(group.subjects) - 1; i >= 0; i-- {
+			fmt.Fprintf(out, "      %s\n", group.subjects[i])
+		}
+		fmt.Fprintln(out)
+	}
+}
+
+// walkCommits resolves the starting revisions and calls fn for each commit reachable from them,
+// in the order ObjectStore.WalkCommits visits them.
+func walkCommits(ctx context.Context, gitDir string, store *objects.ObjectStore, starts []string, fn func(*objects.Commit) error) error {
+	hashes := make([]string, 0, len(starts))
+	for _, start := range starts {
+		hash, err := resolveObjectName(gitDir, store, start)
+		if err != nil {
+			return fmt.Errorf("bad revision %s: %w", start, err)
+		}
+		if hash, err = peelObject(store, hash); err != nil {
+			return err
+		}
+		hashes = append(hashes, hash)
+	}
+	return store.WalkCommits(ctx, hashes, fn)
+}
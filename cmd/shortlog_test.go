@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// storeHistory stores a linear history with one commit per author name and returns the tip hash.
+func storeHistory(t *testing.T, store *objects.ObjectStore, authors ...string) string {
+	t.Helper()
+
+	parent := ""
+	for i, name := range authors {
+		author := objects.Author{
+			Name:      name,
+			Email:     strings.ToLower(name) + "@example.com",
+			Timestamp: time.Unix(int64(1700000000+i), 0).UTC(),
+		}
+		commit, err := objects.NewCommit(testutils.RandomHash(), parent, name+" change "+string(rune('a'+i)), author)
+		if err != nil {
+			t.Fatalf("Failed to create commit: %v", err)
+		}
+		if err := store.Store(commit); err != nil {
+			t.Fatalf("Failed to store commit: %v", err)
+		}
+		parent = commit.Hash()
+	}
+	return parent
+}
+
+// TestShortlogCommand verifies commits are grouped by author, sorted by name, oldest first.
+func TestShortlogCommand(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	tip := storeHistory(t, objects.NewObjectStore(repository.GitDir(repoPath)), "Bob", "Alice", "Bob")
+
+	output, _, err := runCmd(t, constants.ShortlogCmdName, tip)
+	if err != nil {
+		t.Fatalf("%s command failed: %v", constants.ShortlogCmdName, err)
+	}
+
+	expected := "Alice (1):\n      Alice change b\n\n" +
+		"Bob (2):\n      Bob change a\n      Bob change c\n\n"
+	if output != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, output)
+	}
+}
+
+// TestShortlogCommand_Summary verifies counts, numbered sort, emails and mailmap canonicalization.
+func TestShortlogCommand_Summary(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	tip := storeHistory(t, objects.NewObjectStore(repository.GitDir(repoPath)), "Alice", "Bob", "Bobby")
+	testutils.CreateTestFile(t, repoPath, constants.MailmapFile, []byte("Bob <bob@example.com> <bobby@example.com>\n"))
+
+	output, _, err := runCmd(t, constants.ShortlogCmdName, "-sne", tip[:7], tip)
+	if err != nil {
+		t.Fatalf("%s command failed: %v", constants.ShortlogCmdName, err)
+	}
+
+	expected := "     2\tBob <bob@example.com>\n     1\tAlice <alice@example.com>\n"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
+
+// TestShortlogCommand_Merge verifies commits of every merged parent are counted, starting from a ref.
+func TestShortlogCommand_Merge(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	store := objects.NewObjectStore(repository.GitDir(repoPath))
+	main := storeHistory(t, store, "Bob", "Alice")
+	side := storeHistory(t, store, "Carol")
+	writeTestRef(t, repoPath, constants.BranchRefPrefix+constants.DefaultBranch, storeCommitWithParents(t, store, main, side))
+
+	output, _, err := runCmd(t, constants.ShortlogCmdName, "-s", constants.Head)
+	if err != nil {
+		t.Fatalf("%s command failed: %v", constants.ShortlogCmdName, err)
+	}
+
+	expected := "     1\tA\n     1\tAlice\n     1\tBob\n     1\tCarol\n"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
+
+// TestShortlogCommand_Errors verifies missing and unknown revisions are rejected.
+func TestShortlogCommand_Errors(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+
+	if _, _, err := runCmd(t, constants.ShortlogCmdName); err == nil {
+		t.Error("Expected error without revisions")
+	}
+	if _, _, err := runCmd(t, constants.ShortlogCmdName, testutils.RandomHash()); err == nil || !strings.Contains(err.Error(), "bad revision") {
+		t.Errorf("Expected bad revision error, got %v", err)
+	}
+}
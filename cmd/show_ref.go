@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/refs"
+	"github.com/KostasZigo/gogit/utils"
+	"github.com/spf13/cobra"
+)
+
+var showRefCmd = &cobra.Command{
+	Use:   "show-ref [--head] [--heads] [--tags] [-d] [-s] [--verify] [<pattern>...]",
+	Short: "List refs and the objects they point at",
+	Long: `List refs as "<hash> <name>", sorted by name. A pattern matches a ref
+whose name equals it or ends with it after a slash, so "main" matches
+refs/heads/main and refs/remotes/origin/main.
+
+With --verify, each argument must be a full ref name that exists, and
+no pattern matching is done. -d adds a "<name>^{}" line with the object
+each annotated tag finally points at.
+
+Examples:
+  # List tags with the commits they point at
+  gogit show-ref --tags -d
+
+  # Check a branch exists, printing only its hash
+  gogit show-ref --verify -s refs/heads/main`,
+	SilenceUsage: true,
+	Args:         showRefArgs,
+	RunE:         runShowRef,
+}
+
+var (
+	showRefHeadFlag   bool
+	showRefHeadsFlag  bool
+	showRefTagsFlag   bool
+	showRefDerefFlag  bool
+	showRefHashFlag   bool
+	showRefVerifyFlag bool
+	showRefQuietFlag  bool
+)
+
+func init() {
+	rootCmd.AddCommand(showRefCmd)
+
+	showRefCmd.Flags().BoolVar(&showRefHeadFlag, "head", false, "Show HEAD as well")
+	showRefCmd.Flags().BoolVar(&showRefHeadsFlag, "heads", false, "Only show branches")
+	showRefCmd.Flags().BoolVar(&showRefTagsFlag, "tags", false, "Only show tags")
+	showRefCmd.Flags().BoolVarP(&showRefDerefFlag, "dereference", "d", false, "Show what annotated tags point at")
+	showRefCmd.Flags().BoolVarP(&showRefHashFlag, "hash", "s", false, "Only show object hashes")
+	showRefCmd.Flags().BoolVar(&showRefVerifyFlag, "verify", false, "Require exact ref names and fail if any is missing")
+	showRefCmd.Flags().BoolVarP(&showRefQuietFlag, "quiet", "q", false, "Print nothing, only report through the exit status")
+}
+
+// showRefArgs requires refs to verify with --verify.
+// Enables usage printing in case of error.
+func showRefArgs(cmd *cobra.Command, args []string) error {
+	if showRefVerifyFlag && len(args) == 0 {
+		cmd.SilenceUsage = false
+		return fmt.Errorf("%s --verify requires at least 1 argument (ref), received 0", constants.ShowRefCmdName)
+	}
+	return nil
+}
+
+// runShowRef prints refs matching patterns, or the refs named with --verify.
+func runShowRef(cmd *cobra.Command, args []string) error {
+	layout, err := findRepoRoot()
+	if err != nil {
+		return err
+	}
+	store := newObjectStore(layout.GitDir)
+
+	shown, err := showRefCandidates(layout.GitDir, args)
+	if err != nil {
+		return err
+	}
+	if len(shown) == 0 {
+		return errors.New("no matching refs")
+	}
+	if showRefQuietFlag {
+		return nil
+	}
+
+	for _, ref := range shown {
+		printShowRef(cmd, ref.Hash, ref.Name)
+		if !showRefDerefFlag {
+			continue
+		}
+
+		peeled, err := peelObject(store, ref.Hash)
+		if err != nil {
+			return err
+		}
+		if peeled != ref.Hash {
+			printShowRef(cmd, peeled, ref.Name+"^{}")
+		}
+	}
+	return nil
+}
+
+// showRefCandidates returns refs named with --verify, or refs matching patterns and the type filters.
+func showRefCandidates(gitDir string, args []string) ([]refs.Ref, error) {
+	var shown []refs.Ref
+	if showRefVerifyFlag {
+		for _, name := range args {
+			if name != constants.Head && !strings.HasPrefix(name, constants.Refs+"/") {
+				return nil, fmt.Errorf("'%s' - not a valid ref", name)
+			}
+			hash, err := refs.Resolve(gitDir, name)
+			if err != nil {
+				return nil, fmt.Errorf("'%s' - not a valid ref", name)
+			}
+			shown = append(shown, refs.Ref{Name: name, Hash: hash})
+		}
+		return shown, nil
+	}
+
+	if showRefHeadFlag {
+		hash, err := refs.Resolve(gitDir, constants.Head)
+		if err != nil && !errors.Is(err, refs.ErrRefNotFound) {
+			return nil, err
+		}
+		if err == nil {
+			shown = append(shown, refs.Ref{Name: constants.Head, Hash: hash})
+		}
+	}
+
+	all, err := refs.List(gitDir, constants.Refs+"/")
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range all {
+		if showRefHeadsFlag || showRefTagsFlag {
+			isBranch := showRefHeadsFlag && strings.HasPrefix(ref.Name, constants.BranchRefPrefix)
+			isTag := showRefTagsFlag && strings.HasPrefix(ref.Name, constants.TagRefPrefix)
+			if !isBranch && !isTag {
+				continue
+			}
+		}
+		if len(args) > 0 && !showRefMatches(ref.Name, args) {
+			continue
+		}
+		shown = append(shown, ref)
+	}
+	return shown, nil
+}
+
+// showRefMatches reports whether name equals a pattern or ends with it after a slash.
+func showRefMatches(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if name == pattern || strings.HasSuffix(name, "/"+pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// printShowRef prints one ref line, or only its hash with --hash.
+func printShowRef(cmd *cobra.Command, hash, name string) {
+	if showRefHashFlag {
+		fmt.Fprintln(cmd.OutOrStdout(), hash)
+		return
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", hash, name)
+}
+
+// peelObject follows tag objects from hash to the first object that is not a tag.
+func peelObject(store *objects.ObjectStore, hash string) (string, error) {
+	for {
+		objectType, err := storedObjectType(store, hash)
+		if err != nil {
+			return "", fmt.Errorf("failed to read object %s: %w", hash, err)
+		}
+		if objectType != utils.TagObjectType {
+			return hash, nil
+		}
+
+		tag, err := store.ReadTag(hash)
+		if err != nil {
+			return "", err
+		}
+		hash = tag.Object()
+	}
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/refs"
+	"github.com/KostasZigo/gogit/internal/repository"
+)
+
+// TestShowRefCommand verifies listing, pattern suffix matching, type filters and dereferencing.
+func TestShowRefCommand(t *testing.T) {
+	repoPath, history := setupRefHistory(t)
+	tagHash, err := refs.Resolve(repository.GitDir(repoPath), "refs/tags/v1.0")
+	if err != nil {
+		t.Fatalf("Failed to resolve tag: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name: "all refs",
+			expected: fmt.Sprintf("%s refs/heads/main\n%s refs/heads/side\n%s refs/tags/light\n%s refs/tags/v1.0\n",
+				history[2], history[0], history[2], tagHash),
+		},
+		{
+			name:     "pattern matches last components",
+			args:     []string{"main", "v1.0"},
+			expected: fmt.Sprintf("%s refs/heads/main\n%s refs/tags/v1.0\n", history[2], tagHash),
+		},
+		{
+			name:     "dereferenced tags",
+			args:     []string{"--tags", "-d"},
+			expected: fmt.Sprintf("%s refs/tags/light\n%s refs/tags/v1.0\n%s refs/tags/v1.0^{}\n", history[2], tagHash, history[1]),
+		},
+		{
+			name:     "head and branches as hashes",
+			args:     []string{"--head", "--heads", "-s"},
+			expected: fmt.Sprintf("%s\n%s\n%s\n", history[2], history[2], history[0]),
+		},
+		{
+			name:     "verify",
+			args:     []string{"--verify", "refs/heads/side"},
+			expected: fmt.Sprintf("%s refs/heads/side\n", history[0]),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, _, err := runCmd(t, constants.ShowRefCmdName, tt.args...)
+			if err != nil {
+				t.Fatalf("%s command failed: %v", constants.ShowRefCmdName, err)
+			}
+			if output != tt.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.expected, output)
+			}
+		})
+	}
+}
+
+// TestShowRefCommand_Errors verifies missing refs fail, with --verify requiring full names.
+func TestShowRefCommand_Errors(t *testing.T) {
+	setupRefHistory(t)
+
+	tests := []struct {
+		name          string
+		args          []string
+		expectedError string
+	}{
+		{"no match", []string{"missing"}, "no matching refs"},
+		{"verify short name", []string{"--verify", "main"}, "'main' - not a valid ref"},
+		{"verify missing ref", []string{"--verify", "refs/heads/missing"}, "not a valid ref"},
+		{"verify without refs", []string{"--verify"}, "requires at least 1 argument"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := runCmd(t, constants.ShowRefCmdName, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("Expected error containing [%s], got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
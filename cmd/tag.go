@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/refs"
+	"github.com/KostasZigo/gogit/utils"
+	"github.com/spf13/cobra"
+)
+
+var tagCmd = &cobra.Command{
+	Use:   "tag [-a] [-f] [-m <message>]... <tagname> [<object>] | -d <tagname>... | [-l] [-n[=<num>]] [--sort=<key>] [--points-at <object>] [<pattern>...]",
+	Short: "Create, list or delete tags",
+	Long: `Without arguments, or with -l, list tags matching the given glob patterns.
+-n prints the first lines of each annotation (or of the commit message for
+lightweight tags), and --points-at only lists tags of the given object.
+Tags are sorted by name unless --sort is given, which accepts the keys of
+for-each-ref, including version:refname to order v1.10 after v1.9.
+
+With a name, create a tag pointing at the object (HEAD by default). The tag
+is lightweight unless -a or -m is given, which makes an annotated tag object.
+-d deletes the named tags.
+
+Examples:
+  # List release tags, newest version first, with their messages
+  gogit tag -n -l 'v*' --sort=-version:refname
+
+  # Create an annotated tag for the current commit
+  gogit tag -a v1.0 -m "First release"
+
+  # Delete a tag
+  gogit tag -d v1.0`,
+	SilenceUsage: true,
+	Args:         tagArgs,
+	RunE:         runTag,
+}
+
+// tagNameWidth is the column width tag names are padded to before annotation lines.
+const tagNameWidth = 15
+
+var (
+	tagListFlag     bool
+	tagDeleteFlag   bool
+	tagAnnotateFlag bool
+	tagForceFlag    bool
+	tagMessagesFlag []string
+	tagLinesFlag    int
+	tagSortFlag     []string
+	tagPointsAtFlag string
+)
+
+func init() {
+	rootCmd.AddCommand(tagCmd)
+
+	tagCmd.Flags().BoolVarP(&tagListFlag, "list", "l", false, "List tags matching the given patterns")
+	tagCmd.Flags().BoolVarP(&tagDeleteFlag, "delete", "d", false, "Delete the named tags")
+	tagCmd.Flags().BoolVarP(&tagAnnotateFlag, "annotate", "a", false, "Create an annotated tag object")
+	tagCmd.Flags().BoolVarP(&tagForceFlag, "force", "f", false, "Replace an existing tag")
+	tagCmd.Flags().StringArrayVarP(&tagMessagesFlag, "message", "m", nil, "Tag message; several are joined as paragraphs")
+	tagCmd.Flags().IntVarP(&tagLinesFlag, "lines", "n", 0, "Print <num> lines of each tag message (1 if <num> is omitted)")
+	tagCmd.Flags().Lookup("lines").NoOptDefVal = "1"
+	tagCmd.Flags().StringArrayVar(&tagSortFlag, "sort", nil, "Field to sort by, prefixed with - for descending order")
+	tagCmd.Flags().StringVar(&tagPointsAtFlag, "points-at", "", "Only list tags of the given object")
+}
+
+// tagListing reports whether tag runs in list mode, explicitly or implied by listing options.
+func tagListing(args []string) bool {
+	return tagListFlag || len(args) == 0 || tagLinesFlag > 0 || tagPointsAtFlag != ""
+}
+
+// tagArgs rejects combinations of listing, deleting and creating.
+// Enables usage printing in case of error.
+func tagArgs(cmd *cobra.Command, args []string) error {
+	var err error
+	switch {
+	case tagDeleteFlag && (tagListFlag || len(args) == 0):
+		err = fmt.Errorf("%s -d requires at least 1 argument (tagname) and cannot be combined with -l", constants.TagCmdName)
+	case tagDeleteFlag || tagListing(args):
+		return nil
+	case len(args) > 2:
+		err = fmt.Errorf("%s command accepts at most 2 arg(s) when creating a tag, received %d", constants.TagCmdName, len(args))
+	default:
+		return nil
+	}
+	cmd.SilenceUsage = false
+	return err
+}
+
+// runTag dispatches to deleting, listing or creating tags.
+func runTag(cmd *cobra.Command, args []string) error {
+	layout, err := findRepoRoot()
+	if err != nil {
+		return err
+	}
+	store := newObjectStore(layout.GitDir)
+
+	switch {
+	case tagDeleteFlag:
+		return deleteTags(cmd, layout.GitDir, args)
+	case tagListing(args):
+		return listTags(cmd, layout.GitDir, store, args)
+	default:
+		return createTag(cmd, layout.GitDir, store, args)
+	}
+}
+
+// deleteTags removes each named tag, reporting the object it pointed at.
+// All names are attempted; the error lists those that were missing.
+func deleteTags(cmd *cobra.Command, gitDir string, names []string) error {
+	var missing []string
+	for _, name := range names {
+		ref := constants.TagRefPrefix + name
+		hash, err := refs.Resolve(gitDir, ref)
+		if errors.Is(err, refs.ErrRefNotFound) {
+			missing = append(missing, name)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := refs.Delete(gitDir, ref); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Deleted tag '%s' (was %s)\n", name, hash[:forEachRefAbbrevLength])
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("tag not found: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// listTags prints tags whose short names match any pattern, sorted and filtered as requested.
+func listTags(cmd *cobra.Command, gitDir string, store *objects.ObjectStore, patterns []string) error {
+	sortKeys, err := parseRefSortKeys(tagSortFlag)
+	if err != nil {
+		return err
+	}
+
+	pointsAt := ""
+	if tagPointsAtFlag != "" {
+		if pointsAt, err = resolveObjectName(gitDir, store, tagPointsAtFlag); err != nil {
+			return fmt.Errorf("malformed object name %s: %w", tagPointsAtFlag, err)
+		}
+	}
+
+	var infos []*refInfo
+	err = refs.ForEach(gitDir, constants.TagRefPrefix, func(ref refs.Ref) error {
+		name := strings.TrimPrefix(ref.Name, constants.TagRefPrefix)
+		if len(patterns) > 0 && !slices.ContainsFunc(patterns, func(pattern string) bool {
+			matched, err := path.Match(pattern, name)
+			return err == nil && matched
+		}) {
+			return nil
+		}
+
+		info, err := loadRefInfo(store, ref.Name, ref.Hash, true)
+		if err != nil {
+			return err
+		}
+		if pointsAt != "" && info.hash != pointsAt && (info.peeled == nil || info.peeled.hash != pointsAt) {
+			return nil
+		}
+		infos = append(infos, info)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sortRefInfos(infos, sortKeys)
+	for _, info := range infos {
+		name := strings.TrimPrefix(info.name, constants.TagRefPrefix)
+		if tagLinesFlag == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), name)
+			continue
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%-*s %s\n", tagNameWidth, name, tagMessageLines(info, tagLinesFlag))
+	}
+	return nil
+}
+
+// tagMessageLines returns the first count lines of the tag's annotation, or of the tagged commit's
+// message for lightweight tags. Lines after the first are indented by four spaces.
+func tagMessageLines(info *refInfo, count int) string {
+	message := info.field("contents")
+	lines := strings.Split(strings.TrimRight(message, "\n"), "\n")
+	if len(lines) > count {
+		lines = lines[:count]
+	}
+	return strings.Join(lines, "\n    ")
+}
+
+// createTag points refs/tags/<name> at the object, through a new tag object when annotating.
+func createTag(cmd *cobra.Command, gitDir string, store *objects.ObjectStore, args []string) error {
+	name := args[0]
+	if !utils.ValidRefName(name) {
+		return fmt.Errorf("'%s' is not a valid tag name", name)
+	}
+
+	ref := constants.TagRefPrefix + name
+	if _, err := refs.Resolve(gitDir, ref); err == nil && !tagForceFlag {
+		return fmt.Errorf("tag '%s' already exists", name)
+	} else if err != nil && !errors.Is(err, refs.ErrRefNotFound) {
+		return err
+	}
+
+	var object string
+	var err error
+	if len(args) == 1 {
+		object, err = refs.Resolve(gitDir, constants.Head)
+	} else {
+		object, err = resolveObjectName(gitDir, store, args[1])
+	}
+	if err != nil {
+		return fmt.Errorf("failed to resolve object: %w", err)
+	}
+
+	if tagAnnotateFlag || len(tagMessagesFlag) > 0 {
+		if object, err = storeTagObject(store, name, object); err != nil {
+			return err
+		}
+	}
+	return refs.Update(gitDir, ref, object)
+}
+
+// storeTagObject stores an annotated tag of object with the -m messages, returning its hash.
+func storeTagObject(store *objects.ObjectStore, name, object string) (string, error) {
+	if len(tagMessagesFlag) == 0 {
+		return "", errors.New("no tag message given; use -m <message>")
+	}
+
+	objectType, err := storedObjectType(store, object)
+	if err != nil {
+		return "", fmt.Errorf("failed to read tagged object %s: %w", object, err)
+	}
+	tagger, err := currentAuthor()
+	if err != nil {
+		return "", err
+	}
+
+	tag, err := objects.NewTag(object, objectType, name, tagger, strings.Join(tagMessagesFlag, "\n\n"))
+	if err != nil {
+		return "", err
+	}
+	if err := store.Store(tag); err != nil {
+		return "", fmt.Errorf("failed to store tag: %w", err)
+	}
+	return tag.Hash(), nil
+}
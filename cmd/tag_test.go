@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/refs"
+	"github.com/KostasZigo/gogit/internal/repository"
+)
+
+// TestTagCommand_List verifies pattern filtering, version sorting, message lines and --points-at.
+func TestTagCommand_List(t *testing.T) {
+	repoPath, history := setupRefHistory(t)
+	writeTestRef(t, repoPath, constants.TagRefPrefix+"v1.10", history[0])
+	writeTestRef(t, repoPath, constants.TagRefPrefix+"v1.9", history[0])
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"all tags by name", nil, "light\nv1.0\nv1.10\nv1.9\n"},
+		{"version sort", []string{"-l", "v*", "--sort=-version:refname"}, "v1.10\nv1.9\nv1.0\n"},
+		{"message lines", []string{"-n", "light", "v1.0"}, fmt.Sprintf("%-15s commit\n%-15s v1.0\n", "light", "v1.0")},
+		{"points at commit or tagged commit", []string{"--points-at", history[1]}, "v1.0\n"},
+		{"points at ref", []string{"--points-at", "HEAD"}, "light\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, _, err := runCmd(t, constants.TagCmdName, tt.args...)
+			if err != nil {
+				t.Fatalf("%s command failed: %v", constants.TagCmdName, err)
+			}
+			if output != tt.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.expected, output)
+			}
+		})
+	}
+}
+
+// TestTagCommand_CreateAndDelete verifies lightweight and annotated tags are created, protected and deleted.
+func TestTagCommand_CreateAndDelete(t *testing.T) {
+	repoPath, history := setupRefHistory(t)
+	gitDir := repository.GitDir(repoPath)
+	t.Setenv(constants.AuthorNameEnv, "Ada")
+	t.Setenv(constants.AuthorEmailEnv, "ada@example.com")
+
+	if _, _, err := runCmd(t, constants.TagCmdName, "light-new", history[0]); err != nil {
+		t.Fatalf("Failed to create lightweight tag: %v", err)
+	}
+	if hash, err := refs.Resolve(gitDir, "refs/tags/light-new"); err != nil || hash != history[0] {
+		t.Errorf("Expected lightweight tag at %s, got %s (%v)", history[0], hash, err)
+	}
+
+	if _, _, err := runCmd(t, constants.TagCmdName, "side-tag", "side"); err != nil {
+		t.Fatalf("Failed to tag a branch: %v", err)
+	}
+	if hash, err := refs.Resolve(gitDir, "refs/tags/side-tag"); err != nil || hash != history[0] {
+		t.Errorf("Expected tag of side at %s, got %s (%v)", history[0], hash, err)
+	}
+
+	if _, _, err := runCmd(t, constants.TagCmdName, "-m", "Release", "rel"); err != nil {
+		t.Fatalf("Failed to create annotated tag: %v", err)
+	}
+	tagHash, err := refs.Resolve(gitDir, "refs/tags/rel")
+	if err != nil {
+		t.Fatalf("Failed to resolve tag: %v", err)
+	}
+	tag, err := objects.NewObjectStore(gitDir).ReadTag(tagHash)
+	if err != nil {
+		t.Fatalf("Expected annotated tag object: %v", err)
+	}
+	if tag.Object() != history[2] || tag.Message() != "Release\n" || tag.Tagger().Name != "Ada" {
+		t.Errorf("Expected tag of HEAD with message Release by Ada, got %s %q %s", tag.Object(), tag.Message(), tag.Tagger().Name)
+	}
+
+	if _, _, err := runCmd(t, constants.TagCmdName, "rel"); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected existing tag to be refused, got %v", err)
+	}
+	if _, _, err := runCmd(t, constants.TagCmdName, "-f", "rel", history[0]); err != nil {
+		t.Errorf("Expected -f to replace tag, got %v", err)
+	}
+
+	output, _, err := runCmd(t, constants.TagCmdName, "-d", "rel", "missing")
+	if err == nil || !strings.Contains(err.Error(), "tag not found: missing") {
+		t.Errorf("Expected missing tag error, got %v", err)
+	}
+	if expected := fmt.Sprintf("Deleted tag 'rel' (was %s)\n", history[0][:7]); output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+	if _, err := refs.Resolve(gitDir, "refs/tags/rel"); !errors.Is(err, refs.ErrRefNotFound) {
+		t.Errorf("Expected tag to be deleted, got %v", err)
+	}
+}
+
+// TestTagCommand_Errors verifies invalid names and argument combinations are rejected.
+func TestTagCommand_Errors(t *testing.T) {
+	setupRefHistory(t)
+
+	tests := []struct {
+		name          string
+		args          []string
+		expectedError string
+	}{
+		{"invalid name", []string{"bad..name"}, "not a valid tag name"},
+		{"annotate without message", []string{"-a", "v2"}, "no tag message given"},
+		{"delete without names", []string{"-d"}, "requires at least 1 argument"},
+		{"too many args", []string{"v2", "a", "b"}, "at most 2 arg(s)"},
+		{"unknown object", []string{"v2", "Missing"}, `invalid object name "Missing"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := runCmd(t, constants.TagCmdName, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("Expected error containing [%s], got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/pack"
+	"github.com/KostasZigo/gogit/utils"
+	"github.com/spf13/cobra"
+)
+
+var unpackObjectsCmd = &cobra.Command{
+	Use:   "unpack-objects [-n]",
+	Short: "Unpack objects from a packfile into loose objects",
+	Long: `Read a packfile from standard input and write each of its objects to the
+repository as a loose object. Objects already present are left alone.
+
+Deltas may be against objects the repository already has, as in the thin
+packs sent over the network. -n checks the pack and resolves its deltas
+without writing anything.
+
+Examples:
+  # Unpack a pack received from elsewhere
+  gogit unpack-objects < pack-1a2b3c.pack`,
+	SilenceUsage: true,
+	Args:         noArgs(constants.UnpackObjectsCmdName),
+	RunE:         runUnpackObjects,
+}
+
+var unpackObjectsDryRunFlag bool
+
+func init() {
+	rootCmd.AddCommand(unpackObjectsCmd)
+
+	unpackObjectsCmd.Flags().BoolVarP(&unpackObjectsDryRunFlag, "dry-run", "n", false, "Check the pack without writing objects")
+}
+
+// runUnpackObjects parses the pack on stdin and stores the objects the repository lacks.
+func runUnpackObjects(cmd *cobra.Command, args []string) error {
+	layout, err := findRepoRoot()
+	if err != nil {
+		return err
+	}
+	// Delta bases are named by what is stored, whatever replacements are in place
+	store := objects.NewObjectStore(layout.GitDir, objects.WithReplaceObjects(false))
+
+	data, err := io.ReadAll(cmd.InOrStdin())
+	if err != nil {
+		return fmt.Errorf("failed to read stdin: %w", err)
+	}
+	parsed, err := pack.Parse(data, storedDeltaBase(store))
+	if err != nil {
+		return err
+	}
+	if unpackObjectsDryRunFlag {
+		return nil
+	}
+
+	for i, entry := range parsed.Entries {
+		if store.Exists(entry.Hash) {
+			continue
+		}
+		content := parsed.Content(i)
+		if _, err := store.StoreStream(entry.Type, int64(len(content)), bytes.NewReader(content)); err != nil {
+			return fmt.Errorf("failed to write object %s: %w", entry.Hash, err)
+		}
+	}
+	return nil
+}
+
+// storedDeltaBase looks up delta bases missing from a pack in store.
+func storedDeltaBase(store *objects.ObjectStore) pack.BaseFunc {
+	return func(hash string) (utils.ObjectType, []byte, error) {
+		reader, err := store.OpenObject(hash)
+		if err != nil {
+			return "", nil, err
+		}
+		defer reader.Close()
+
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			return "", nil, err
+		}
+		return reader.Type(), content, nil
+	}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+	"github.com/KostasZigo/gogit/utils"
+)
+
+// TestUnpackObjectsCommand verifies objects are written loose, with deltas against objects
+// the repository has, and that -n writes nothing.
+func TestUnpackObjectsCommand(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	base := storeTestBlobs(t, repoPath, "hello world\n")[0]
+	data := testutils.CreateTestPack(t, []testutils.PackEntry{
+		{BaseHash: base, Data: append([]byte{12, 12, 0x90, 6, 6}, "there\n"...)},
+		{OffsetDelta: true, BaseEntry: 0, Data: []byte{12, 6, 0x91, 6, 6}},
+		{Type: utils.BlobObjectType, Data: []byte("whole\n")},
+	})
+	store := objects.NewObjectStore(repository.GitDir(repoPath))
+	unpacked := []string{"hello there\n", "there\n", "whole\n"}
+
+	if _, _, err := runCmdWithInput(t, bytes.NewReader(data), constants.UnpackObjectsCmdName, "-n"); err != nil {
+		t.Fatalf("%s -n failed: %v", constants.UnpackObjectsCmdName, err)
+	}
+	for _, content := range unpacked {
+		if store.Exists(utils.MustComputeHash([]byte(content), utils.BlobObjectType)) {
+			t.Errorf("Expected -n to write nothing, found %q", content)
+		}
+	}
+
+	if _, _, err := runCmdWithInput(t, bytes.NewReader(data), constants.UnpackObjectsCmdName); err != nil {
+		t.Fatalf("%s command failed: %v", constants.UnpackObjectsCmdName, err)
+	}
+	for _, content := range unpacked {
+		blob, err := store.ReadBlob(utils.MustComputeHash([]byte(content), utils.BlobObjectType))
+		if err != nil {
+			t.Fatalf("Failed to read unpacked %q: %v", content, err)
+		}
+		if string(blob.Content()) != content {
+			t.Errorf("Expected %q, got %q", content, blob.Content())
+		}
+	}
+}
+
+// TestUnpackObjectsCommand_MissingBase verifies a delta against an unknown object is rejected
+// before anything is written.
+func TestUnpackObjectsCommand_MissingBase(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	data := testutils.CreateTestPack(t, []testutils.PackEntry{
+		{Type: utils.BlobObjectType, Data: []byte("whole\n")},
+		{BaseHash: testutils.RandomHash(), Data: []byte{12, 6, 0x91, 6, 6}},
+	})
+
+	_, _, err := runCmdWithInput(t, bytes.NewReader(data), constants.UnpackObjectsCmdName)
+	if err == nil || !strings.Contains(err.Error(), "unresolved deltas") {
+		t.Fatalf("Expected unresolved delta error, got %v", err)
+	}
+	if objects.NewObjectStore(repository.GitDir(repoPath)).Exists(utils.MustComputeHash([]byte("whole\n"), utils.BlobObjectType)) {
+		t.Error("Expected no objects to be written")
+	}
+}
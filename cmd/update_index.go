@@ -0,0 +1,291 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/index"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/spf13/cobra"
+)
+
+var updateIndexCmd = &cobra.Command{
+	Use:   "update-index [--add] [--remove] [--chmod=(+|-)x] [--cacheinfo <mode>,<object>,<path>]... [--refresh] [<file>...]",
+	Short: "Register file contents in the working tree to the index",
+	Long: `Modify the index directly.
+
+Each <file> has its current content stored as a blob and recorded in the
+index, resolving any conflict at that path. Files not yet in the index are
+only added with --add, and files missing from the working tree are only
+removed from the index with --remove. --chmod sets or clears the executable
+bit of the recorded mode of each <file>.
+
+--cacheinfo records an object directly, without touching the working tree.
+It is applied before any <file>, and new paths again need --add.
+
+With --refresh, stat data of every entry is compared with the working tree.
+Files whose metadata changed but whose content is identical get their stat data
+resynced, so later change detection can skip rehashing them. Files with different
+content are reported as needing an update.
+
+Examples:
+  # Stage a new file and mark it executable
+  gogit update-index --add --chmod=+x build.sh
+
+  # Stage an existing blob under a new path
+  gogit update-index --add --cacheinfo 100644,<object>,docs/copy.txt
+
+  # Resync stat information after touching or copying files
+  gogit update-index --refresh`,
+	SilenceUsage: true,
+	RunE:         runUpdateIndex,
+}
+
+var (
+	refreshFlag              bool
+	updateIndexAddFlag       bool
+	updateIndexRemoveFlag    bool
+	updateIndexChmodFlag     string
+	updateIndexCacheinfoFlag []string
+)
+
+func init() {
+	rootCmd.AddCommand(updateIndexCmd)
+
+	updateIndexCmd.Flags().BoolVar(&refreshFlag, "refresh", false, "Refresh stat information of index entries")
+	updateIndexCmd.Flags().BoolVar(&updateIndexAddFlag, "add", false, "Add files that are not in the index yet")
+	updateIndexCmd.Flags().BoolVar(&updateIndexRemoveFlag, "remove", false, "Remove files that are missing from the working tree")
+	updateIndexCmd.Flags().StringVar(&updateIndexChmodFlag, "chmod", "", "Set (+x) or clear (-x) the executable bit of the given files")
+	updateIndexCmd.Flags().StringArrayVar(&updateIndexCacheinfoFlag, "cacheinfo", nil, "Record <mode>,<object>,<path> in the index")
+}
+
+// noArgs validates command receives no positional arguments.
+// Enables usage printing in case of error.
+func noArgs(cmdName string) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 {
+			cmd.SilenceUsage = false
+			return fmt.Errorf("%s command accepts no arguments, received %d", cmdName, len(args))
+		}
+		return nil
+	}
+}
+
+// runUpdateIndex applies requested index modifications.
+func runUpdateIndex(cmd *cobra.Command, args []string) error {
+	if !refreshFlag && len(updateIndexCacheinfoFlag) == 0 && len(args) == 0 {
+		cmd.SilenceUsage = false
+		return fmt.Errorf("%s requires an operation flag such as --refresh, or files to update", constants.UpdateIndexCmdName)
+	}
+	if updateIndexChmodFlag != "" && updateIndexChmodFlag != "+x" && updateIndexChmodFlag != "-x" {
+		cmd.SilenceUsage = false
+		return fmt.Errorf(`option 'chmod' expects "+x" or "-x"`)
+	}
+
+	layout, err := findRepoRoot()
+	if err != nil {
+		return err
+	}
+	repoPath := layout.Root
+	store := newObjectStore(layout.GitDir)
+
+	var needsUpdate []string
+	err = index.Update(layout.GitDir, func(idx *index.Index) error {
+		for _, cacheinfo := range updateIndexCacheinfoFlag {
+			if err := addCacheinfo(repoPath, idx, cacheinfo); err != nil {
+				return err
+			}
+		}
+		for _, arg := range args {
+			if err := updateIndexFile(repoPath, store, idx, arg); err != nil {
+				return err
+			}
+		}
+
+		if refreshFlag {
+			if needsUpdate, err = idx.Refresh(cmd.Context(), repoPath); err != nil {
+				return fmt.Errorf("failed to refresh index: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, path := range needsUpdate {
+		fmt.Fprintf(cmd.OutOrStdout(), "%s: needs update\n", path)
+	}
+
+	if len(needsUpdate) > 0 {
+		return fmt.Errorf("%d path(s) need update", len(needsUpdate))
+	}
+
+	return nil
+}
+
+// addCacheinfo records the object named by a "<mode>,<object>,<path>" argument at its path.
+func addCacheinfo(repoPath string, idx *index.Index, cacheinfo string) error {
+	fields := strings.SplitN(cacheinfo, ",", 3)
+	if len(fields) != 3 || len(fields[1]) != constants.HashStringLength || strings.Trim(strings.ToLower(fields[1]), "0123456789abcdef") != "" {
+		return fmt.Errorf("option 'cacheinfo' expects <mode>,<object>,<path>, got '%s'", cacheinfo)
+	}
+	mode, err := parseIndexMode(fields[0])
+	if err != nil {
+		return err
+	}
+	path, err := repoRelativePath(repoPath, fields[2])
+	if err != nil {
+		return err
+	}
+
+	if err := checkAddable(idx, path); err != nil {
+		return err
+	}
+	idx.Remove(path)
+	idx.Add(index.Entry{Path: path, Mode: mode, Hash: strings.ToLower(fields[1])})
+	return nil
+}
+
+// parseIndexMode parses an octal mode and canonicalizes it as Git does: any regular file
+// mode becomes 100644 or 100755 depending on its owner executable bit.
+func parseIndexMode(value string) (objects.FileMode, error) {
+	mode, err := strconv.ParseUint(value, 8, 32)
+	if err != nil {
+		return "", fmt.Errorf("invalid mode '%s'", value)
+	}
+
+	switch mode & syscall.S_IFMT {
+	case syscall.S_IFREG:
+		if mode&0o100 != 0 {
+			return objects.ModeExecutable, nil
+		}
+		return objects.ModeRegularFile, nil
+	case syscall.S_IFLNK:
+		return objects.ModeSymlink, nil
+	case syscall.S_IFDIR | syscall.S_IFLNK:
+		return objects.ModeSubmodule, nil
+	default:
+		return "", fmt.Errorf("invalid mode '%s'", value)
+	}
+}
+
+// updateIndexFile records the worktree content of arg in the index, or removes its entry
+// when the file is gone and --remove was given.
+func updateIndexFile(repoPath string, store *objects.ObjectStore, idx *index.Index, arg string) error {
+	path, err := repoRelativePath(repoPath, arg)
+	if err != nil {
+		return err
+	}
+	fullPath := filepath.Join(repoPath, filepath.FromSlash(path))
+
+	info, err := os.Lstat(fullPath)
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ENOTDIR) {
+		if !updateIndexRemoveFlag {
+			return fmt.Errorf("%s: does not exist and --remove not passed", path)
+		}
+		idx.Remove(path)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s: is a directory - add individual files instead", path)
+	}
+	if err := checkAddable(idx, path); err != nil {
+		return err
+	}
+
+	hash, err := storeWorktreeFile(store, repoPath, path, info)
+	if err != nil {
+		return err
+	}
+
+	entry, err := index.NewEntry(path, hash, info)
+	if err != nil {
+		return err
+	}
+	if updateIndexChmodFlag != "" {
+		if entry.Mode != objects.ModeRegularFile && entry.Mode != objects.ModeExecutable {
+			return fmt.Errorf("cannot chmod %s '%s'", updateIndexChmodFlag, path)
+		}
+		entry.Mode = objects.ModeRegularFile
+		if updateIndexChmodFlag == "+x" {
+			entry.Mode = objects.ModeExecutable
+		}
+	}
+
+	idx.Remove(path)
+	idx.Add(*entry)
+	return nil
+}
+
+// storeWorktreeFile stores the content of the worktree file at path as a blob, or the
+// target of a symbolic link, and returns its hash.
+func storeWorktreeFile(store *objects.ObjectStore, repoPath, path string, info fs.FileInfo) (string, error) {
+	fullPath := filepath.Join(repoPath, filepath.FromSlash(path))
+	if info.Mode()&fs.ModeSymlink == 0 {
+		hash, err := store.StoreBlobFile(fullPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to store %s: %w", path, err)
+		}
+		return hash, nil
+	}
+
+	target, err := os.Readlink(fullPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read symlink %s: %w", path, err)
+	}
+	blob := objects.NewBlob([]byte(target))
+	if err := store.Store(blob); err != nil {
+		return "", fmt.Errorf("failed to store %s: %w", path, err)
+	}
+	return blob.Hash(), nil
+}
+
+// checkAddable reports why path cannot be recorded: it is new and --add was not given,
+// or it would turn a file in the index into a directory or the other way around.
+func checkAddable(idx *index.Index, path string) error {
+	if len(idx.Stages(path)) > 0 {
+		return nil
+	}
+	if !updateIndexAddFlag {
+		return fmt.Errorf("%s: cannot add to the index - missing --add option?", path)
+	}
+	if conflict, ok := idx.FileDirectoryConflict(path); ok {
+		return fmt.Errorf("'%s' appears as both a file and as a directory, conflicting with '%s'", path, conflict)
+	}
+	return nil
+}
+
+// repoRelativePath converts a path given relative to the current directory into a
+// slash-separated path relative to repoPath. Paths outside the worktree are rejected.
+func repoRelativePath(repoPath, path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(repoPath, absPath)
+	if err != nil {
+		return "", fmt.Errorf("'%s' is outside repository", path)
+	}
+
+	relPath := filepath.ToSlash(rel)
+	if relPath == "." || relPath == ".." || strings.HasPrefix(relPath, "../") {
+		return "", fmt.Errorf("'%s' is outside repository", path)
+	}
+	for _, metadataDir := range []string{constants.Gogit, constants.GitMetadataDir} {
+		if relPath == metadataDir || strings.HasPrefix(relPath, metadataDir+"/") {
+			return "", fmt.Errorf("'%s' is inside the repository metadata directory", path)
+		}
+	}
+	return relPath, nil
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/index"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// stageTestFile creates file in repository and records it in the index.
+func stageTestFile(t *testing.T, repoPath, path string, content []byte) {
+	t.Helper()
+
+	fullPath := testutils.CreateTestFile(t, repoPath, path, content)
+	info, err := os.Lstat(fullPath)
+	if err != nil {
+		t.Fatalf("Failed to stat %s: %v", path, err)
+	}
+
+	entry, err := index.NewEntry(path, objects.NewBlob(content).Hash(), info)
+	if err != nil {
+		t.Fatalf("Failed to create index entry: %v", err)
+	}
+
+	err = index.Update(repository.GitDir(repoPath), func(idx *index.Index) error {
+		idx.Add(*entry)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to update index: %v", err)
+	}
+}
+
+// TestUpdateIndexCommand_Refresh verifies stat data is resynced for unchanged files.
+func TestUpdateIndexCommand_Refresh(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	stageTestFile(t, repoPath, "file.txt", []byte("content"))
+
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	os.Chtimes(filepath.Join(repoPath, "file.txt"), past, past)
+
+	output, _, err := runCmd(t, constants.UpdateIndexCmdName, "--refresh")
+	if err != nil {
+		t.Fatalf("%s command failed: %v", constants.UpdateIndexCmdName, err)
+	}
+	if output != "" {
+		t.Errorf("Expected no output, got [%s]", output)
+	}
+
+	idx, err := index.Read(repository.GitDir(repoPath))
+	if err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+	entry, _ := idx.Entry("file.txt")
+	if !entry.MTime.Equal(past) {
+		t.Errorf("Expected refreshed mtime %s, got %s", past, entry.MTime)
+	}
+}
+
+// TestUpdateIndexCommand_RefreshNeedsUpdate verifies modified files are reported.
+func TestUpdateIndexCommand_RefreshNeedsUpdate(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	stageTestFile(t, repoPath, "file.txt", []byte("content"))
+	testutils.CreateTestFile(t, repoPath, "file.txt", []byte("changed content"))
+
+	output, _, err := runCmd(t, constants.UpdateIndexCmdName, "--refresh")
+	if err == nil {
+		t.Fatal("Expected error when paths need update")
+	}
+
+	expectedOutput := "file.txt: needs update\n"
+	if output != expectedOutput {
+		t.Errorf("Expected output [%s], got [%s]", expectedOutput, output)
+	}
+}
+
+// TestUpdateIndexCommand_NoOperation verifies error when no operation flag is given.
+func TestUpdateIndexCommand_NoOperation(t *testing.T) {
+	_, _, err := runCmd(t, constants.UpdateIndexCmdName)
+	if err == nil {
+		t.Fatal("Expected error without operation flag")
+	}
+
+	expectedErrorMessage := fmt.Sprintf("%s requires an operation flag", constants.UpdateIndexCmdName)
+	if !strings.Contains(err.Error(), expectedErrorMessage) {
+		t.Fatalf("Expected error message to contain [%s] but got [%s]", expectedErrorMessage, err.Error())
+	}
+}
+
+// readIndexEntry returns the stage 0 entry for path, failing the test if it is missing.
+func readIndexEntry(t *testing.T, repoPath, path string) *index.Entry {
+	t.Helper()
+
+	idx, err := index.Read(repository.GitDir(repoPath))
+	if err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+	entry, found := idx.Entry(path)
+	if !found {
+		t.Fatalf("Expected %s in the index", path)
+	}
+	return entry
+}
+
+// TestUpdateIndexCommand_Files verifies files are added, updated, made executable and removed.
+func TestUpdateIndexCommand_Files(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	os.Mkdir(filepath.Join(repoPath, "dir"), constants.DirPerms)
+	testutils.CreateTestFile(t, repoPath, "dir/new.txt", []byte("new"))
+
+	if _, _, err := runCmd(t, constants.UpdateIndexCmdName, "dir/new.txt"); err == nil || !strings.Contains(err.Error(), "missing --add option") {
+		t.Fatalf("Expected missing --add error, got %v", err)
+	}
+	if _, _, err := runCmd(t, constants.UpdateIndexCmdName, "--add", "--chmod=+x", "dir/new.txt"); err != nil {
+		t.Fatalf("%s --add failed: %v", constants.UpdateIndexCmdName, err)
+	}
+	entry := readIndexEntry(t, repoPath, "dir/new.txt")
+	if entry.Hash != objects.NewBlob([]byte("new")).Hash() || entry.Mode != objects.ModeExecutable {
+		t.Errorf("Expected executable entry for new content, got %s %s", entry.Mode, entry.Hash)
+	}
+	testutils.AssertFileExists(t, filepath.Join(repoPath, constants.Gogit, constants.Objects, entry.Hash[:2], entry.Hash[2:]))
+
+	// Paths are relative to the current directory
+	testutils.CreateTestFile(t, repoPath, "dir/new.txt", []byte("changed"))
+	t.Chdir(filepath.Join(repoPath, "dir"))
+	if _, _, err := runCmd(t, constants.UpdateIndexCmdName, "new.txt"); err != nil {
+		t.Fatalf("%s failed: %v", constants.UpdateIndexCmdName, err)
+	}
+	if entry := readIndexEntry(t, repoPath, "dir/new.txt"); entry.Hash != objects.NewBlob([]byte("changed")).Hash() {
+		t.Errorf("Expected updated content, got %s", entry.Hash)
+	}
+
+	os.Remove("new.txt")
+	if _, _, err := runCmd(t, constants.UpdateIndexCmdName, "new.txt"); err == nil || !strings.Contains(err.Error(), "--remove not passed") {
+		t.Fatalf("Expected --remove error, got %v", err)
+	}
+	if _, _, err := runCmd(t, constants.UpdateIndexCmdName, "--remove", "new.txt"); err != nil {
+		t.Fatalf("%s --remove failed: %v", constants.UpdateIndexCmdName, err)
+	}
+	idx, _ := index.Read(repository.GitDir(repoPath))
+	if idx.Len() != 0 {
+		t.Errorf("Expected empty index, got %d entries", idx.Len())
+	}
+}
+
+// TestUpdateIndexCommand_Cacheinfo verifies objects are recorded directly with canonical modes.
+func TestUpdateIndexCommand_Cacheinfo(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	changeToRepoDir(t, repoPath)
+	stageTestFile(t, repoPath, "file", []byte("content"))
+	hash := testutils.RandomHash()
+
+	tests := []struct {
+		name          string
+		args          []string
+		expectedError string
+	}{
+		{name: "without add", args: []string{"--cacheinfo", "100644," + hash + ",new"}, expectedError: "missing --add option"},
+		{name: "bad format", args: []string{"--add", "--cacheinfo", "100644," + hash}, expectedError: "expects <mode>,<object>,<path>"},
+		{name: "short hash", args: []string{"--add", "--cacheinfo", "100644,abc,new"}, expectedError: "expects <mode>,<object>,<path>"},
+		{name: "directory mode", args: []string{"--add", "--cacheinfo", "040000," + hash + ",new"}, expectedError: "invalid mode '040000'"},
+		{name: "under a file", args: []string{"--add", "--cacheinfo", "100644," + hash + ",file/new"}, expectedError: "appears as both a file and as a directory"},
+		{name: "bad chmod", args: []string{"--chmod=x", "file"}, expectedError: `expects "+x" or "-x"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := runCmd(t, constants.UpdateIndexCmdName, test.args...)
+			if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+				t.Errorf("Expected error containing %q, got %v", test.expectedError, err)
+			}
+		})
+	}
+
+	_, _, err := runCmd(t, constants.UpdateIndexCmdName, "--add", "--cacheinfo", "100775,"+hash+",bin/tool", "--cacheinfo", "120000,"+hash+",file")
+	if err != nil {
+		t.Fatalf("%s --cacheinfo failed: %v", constants.UpdateIndexCmdName, err)
+	}
+	if entry := readIndexEntry(t, repoPath, "bin/tool"); entry.Mode != objects.ModeExecutable || entry.Hash != hash {
+		t.Errorf("Expected executable entry for %s, got %s %s", hash, entry.Mode, entry.Hash)
+	}
+	if entry := readIndexEntry(t, repoPath, "file"); entry.Mode != objects.ModeSymlink {
+		t.Errorf("Expected existing entry replaced by a symlink, got %s", entry.Mode)
+	}
+}
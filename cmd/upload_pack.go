@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/transport"
+	"github.com/spf13/cobra"
+)
+
+var uploadPackCmd = &cobra.Command{
+	Use:   "upload-pack <directory>",
+	Short: "Send objects to a fetching client",
+	Long: `Serve one fetch from the repository at <directory> over standard input and
+output: advertise its refs, read the objects the client wants and the ones it
+already has, and send a pack of the missing objects.
+
+This is the remote end of fetches over SSH and the git:// daemon, and is not
+meant to be run by hand. Only the basic protocol v0 exchange is offered.
+
+Examples:
+  # What an SSH client runs on the server
+  ssh example.com "gogit upload-pack /srv/repo"`,
+	SilenceUsage: true,
+	Args:         uploadPackArgs,
+	RunE:         runUploadPack,
+}
+
+func init() {
+	rootCmd.AddCommand(uploadPackCmd)
+}
+
+// uploadPackArgs requires exactly one repository directory.
+// Enables usage printing in case of error.
+func uploadPackArgs(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		cmd.SilenceUsage = false
+		return fmt.Errorf("%s command requires exactly 1 argument (directory), received %d", constants.UploadPackCmdName, len(args))
+	}
+	return nil
+}
+
+// runUploadPack serves a fetch from the repository over stdin and stdout.
+func runUploadPack(cmd *cobra.Command, args []string) error {
+	layout, err := enterRepository(args[0])
+	if err != nil {
+		return err
+	}
+	return transport.ServeUploadPack(layout.GitDir, cmd.InOrStdin(), cmd.OutOrStdout())
+}
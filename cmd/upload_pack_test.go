@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/refs"
+	"github.com/KostasZigo/gogit/internal/repository"
+)
+
+// TestUploadPackCommand verifies the repository's refs are advertised and a client that only
+// wanted them can hang up.
+func TestUploadPackCommand(t *testing.T) {
+	repoPath, history := setupRefHistory(t)
+	tagHash, err := refs.Resolve(repository.GitDir(repoPath), "refs/tags/v1.0")
+	if err != nil {
+		t.Fatalf("Failed to resolve tag: %v", err)
+	}
+
+	output, _, err := runCmdWithInput(t, strings.NewReader("0000"), constants.UploadPackCmdName, repoPath)
+	if err != nil {
+		t.Fatalf("%s command failed: %v", constants.UploadPackCmdName, err)
+	}
+	for _, expected := range []string{
+		history[2] + " HEAD\x00agent=gogit symref=HEAD:refs/heads/main\n",
+		tagHash + " refs/tags/v1.0\n",
+		history[1] + " refs/tags/v1.0^{}\n",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected advertisement to contain %q, got %q", expected, output)
+		}
+	}
+	if !strings.HasSuffix(output, "0000") {
+		t.Errorf("Expected advertisement to end with a flush packet, got %q", output)
+	}
+}
+
+// TestUploadPackCommand_NotRepository verifies a directory without a repository is rejected.
+func TestUploadPackCommand_NotRepository(t *testing.T) {
+	if _, _, err := runCmdWithInput(t, strings.NewReader("0000"), constants.UploadPackCmdName, t.TempDir()); err == nil {
+		t.Error("Expected error serving a directory that is not a repository")
+	}
+}
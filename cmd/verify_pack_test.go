@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/utils"
+)
+
+// TestVerifyPackCommand verifies objects and delta statistics are listed for an intact pack.
+func TestVerifyPackCommand(t *testing.T) {
+	dir := t.TempDir()
+	packPath := writeTestPack(t, dir, "test.pack")
+	if _, _, err := runCmd(t, constants.IndexPackCmdName, packPath); err != nil {
+		t.Fatalf("%s command failed: %v", constants.IndexPackCmdName, err)
+	}
+	base := strings.TrimSuffix(packPath, ".pack")
+	world := utils.MustComputeHash([]byte("hello world\n"), utils.BlobObjectType)
+	there := utils.MustComputeHash([]byte("hello there\n"), utils.BlobObjectType)
+
+	stats := "non delta: 1 object\nchain length = 1: 1 object\n"
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"quiet", []string{base + ".idx"}, ""},
+		{"stat only", []string{"-s", packPath}, stats},
+		{"verbose", []string{"-v", base + ".idx"}, fmt.Sprintf("%s blob   12 26 12\n%s blob   11 26 38 1 %s\n%s%s.pack: ok\n",
+			world, there, world, stats, base)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, _, err := runCmd(t, constants.VerifyPackCmdName, tt.args...)
+			if err != nil {
+				t.Fatalf("%s command failed: %v", constants.VerifyPackCmdName, err)
+			}
+			if output != tt.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.expected, output)
+			}
+		})
+	}
+}
+
+// TestVerifyPackCommand_Mismatch verifies a pack its index does not describe is reported bad.
+func TestVerifyPackCommand_Mismatch(t *testing.T) {
+	dir := t.TempDir()
+	packPath := writeTestPack(t, dir, "test.pack")
+	if _, _, err := runCmd(t, constants.IndexPackCmdName, packPath); err != nil {
+		t.Fatalf("%s command failed: %v", constants.IndexPackCmdName, err)
+	}
+	other, err := os.ReadFile(writeTestPack(t, dir, "other.pack"))
+	if err != nil {
+		t.Fatalf("Failed to read pack: %v", err)
+	}
+	// Same objects, different trailer: the index records the original checksum
+	other[len(other)-1] ^= 1
+	if err := os.WriteFile(packPath, other, 0o644); err != nil {
+		t.Fatalf("Failed to overwrite pack: %v", err)
+	}
+
+	output, stderr, err := runCmd(t, constants.VerifyPackCmdName, "-v", packPath)
+	if err == nil || !strings.HasSuffix(output, "test.pack: bad\n") || !strings.Contains(stderr, "SHA1 mismatch") {
+		t.Errorf("Expected the pack to be reported bad, got %q, %q and %v", output, stderr, err)
+	}
+}
@@ -4,10 +4,9 @@ go 1.25.3
 
 require (
 	github.com/agiledragon/gomonkey/v2 v2.13.0
+	github.com/klauspost/compress v1.20.1
 	github.com/spf13/cobra v1.10.1
+	github.com/spf13/pflag v1.0.9
 )
 
-require (
-	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/spf13/pflag v1.0.9 // indirect
-)
+require github.com/inconshreveable/mousetrap v1.1.0 // indirect
@@ -0,0 +1,267 @@
+// Package archive exports tree snapshots as tar or zip streams.
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+)
+
+// Format selects archive container format.
+type Format string
+
+const (
+	FormatTar Format = "tar"
+	FormatZip Format = "zip"
+)
+
+// Permission bits written for archive entries, matching Git's default tar.umask of 002.
+const (
+	fileMode       fs.FileMode = 0664
+	executableMode fs.FileMode = 0775
+	dirMode        fs.FileMode = 0775
+	symlinkMode    fs.FileMode = 0777
+)
+
+// Options controls archive layout and metadata.
+type Options struct {
+	Format  Format
+	Prefix  string    // Prepended to every path; a trailing "/" adds a directory entry
+	ModTime time.Time // Timestamp of every entry
+	Commit  string    // Commit hash recorded in archive comment, empty for bare trees
+}
+
+// entryWriter abstracts the container so tree walking is shared between formats.
+type entryWriter interface {
+	writeDir(name string) error
+	writeFile(name string, mode fs.FileMode, size int64, content io.Reader) error
+	writeSymlink(name, target string) error
+	Close() error
+}
+
+// Write streams tree treeHash into w. Blob content is copied from the object store
+// without buffering whole files, so memory use does not grow with file size.
+func Write(ctx context.Context, w io.Writer, store *objects.ObjectStore, treeHash string, opts Options) error {
+	var writer entryWriter
+	switch opts.Format {
+	case FormatTar:
+		tarWriter, err := newTarWriter(w, opts)
+		if err != nil {
+			return err
+		}
+		writer = tarWriter
+	case FormatZip:
+		writer = newZipWriter(w, opts)
+	default:
+		return fmt.Errorf("unknown archive format %q", opts.Format)
+	}
+
+	if err := writeTree(ctx, writer, store, treeHash, opts.Prefix); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// writeTree writes entries of tree under prefix, descending into subtrees depth first.
+func writeTree(ctx context.Context, writer entryWriter, store *objects.ObjectStore, treeHash, prefix string) error {
+	if len(prefix) > 0 && prefix[len(prefix)-1] == '/' {
+		if err := writer.writeDir(prefix); err != nil {
+			return err
+		}
+	}
+
+	if treeHash == constants.EmptyTreeHash {
+		return nil
+	}
+
+	tree, err := store.ReadTree(treeHash)
+	if err != nil {
+		return fmt.Errorf("failed to read tree %s: %w", treeHash, err)
+	}
+
+	for _, entry := range tree.Entries() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		name := prefix + entry.Name()
+		switch entry.Mode() {
+		case objects.ModeDirectory:
+			err = writeTree(ctx, writer, store, entry.Hash(), name+"/")
+		case objects.ModeSubmodule:
+			// Submodule content lives in another repository; Git exports an empty directory
+			err = writer.writeDir(name + "/")
+		case objects.ModeSymlink:
+			err = writeSymlink(writer, store, name, entry.Hash())
+		case objects.ModeExecutable:
+			err = writeBlob(writer, store, name, executableMode, entry.Hash())
+		default:
+			err = writeBlob(writer, store, name, fileMode, entry.Hash())
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeBlob streams blob content as a file entry.
+func writeBlob(writer entryWriter, store *objects.ObjectStore, name string, mode fs.FileMode, hash string) error {
+	reader, err := store.OpenObject(hash)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	if err := writer.writeFile(name, mode, reader.Size(), reader); err != nil {
+		return fmt.Errorf("failed to archive %s: %w", name, err)
+	}
+	return nil
+}
+
+// writeSymlink writes a symlink entry whose target is the blob content.
+func writeSymlink(writer entryWriter, store *objects.ObjectStore, name, hash string) error {
+	blob, err := store.ReadBlob(hash)
+	if err != nil {
+		return err
+	}
+	return writer.writeSymlink(name, string(blob.Content()))
+}
+
+// tarRecordSize is the unit Git pads tar output to, 20 blocks of 512 bytes.
+const tarRecordSize = 10240
+
+// tarWriter writes entries as a ustar/pax archive.
+type tarWriter struct {
+	*tar.Writer
+	output  *countingWriter
+	modTime time.Time
+}
+
+// newTarWriter starts tar archive, recording commit hash in a pax global header as Git does.
+func newTarWriter(w io.Writer, opts Options) (*tarWriter, error) {
+	output := &countingWriter{Writer: w}
+	writer := &tarWriter{Writer: tar.NewWriter(output), output: output, modTime: opts.ModTime}
+	if opts.Commit != "" {
+		header := &tar.Header{
+			Typeflag:   tar.TypeXGlobalHeader,
+			Name:       "pax_global_header",
+			PAXRecords: map[string]string{"comment": opts.Commit},
+		}
+		if err := writer.WriteHeader(header); err != nil {
+			return nil, fmt.Errorf("failed to write archive header: %w", err)
+		}
+	}
+	return writer, nil
+}
+
+// Close writes the end-of-archive blocks and pads the output to a whole record, as Git does.
+func (t *tarWriter) Close() error {
+	if err := t.Writer.Close(); err != nil {
+		return err
+	}
+	if remainder := t.output.written % tarRecordSize; remainder != 0 {
+		_, err := t.output.Write(make([]byte, tarRecordSize-remainder))
+		return err
+	}
+	return nil
+}
+
+func (t *tarWriter) writeDir(name string) error {
+	return t.WriteHeader(t.header(name, tar.TypeDir, dirMode, 0))
+}
+
+func (t *tarWriter) writeFile(name string, mode fs.FileMode, size int64, content io.Reader) error {
+	if err := t.WriteHeader(t.header(name, tar.TypeReg, mode, size)); err != nil {
+		return err
+	}
+	_, err := io.Copy(t.Writer, content)
+	return err
+}
+
+func (t *tarWriter) writeSymlink(name, target string) error {
+	header := t.header(name, tar.TypeSymlink, symlinkMode, 0)
+	header.Linkname = target
+	return t.WriteHeader(header)
+}
+
+// header builds entry header owned by root, as Git writes them.
+func (t *tarWriter) header(name string, typeflag byte, mode fs.FileMode, size int64) *tar.Header {
+	return &tar.Header{
+		Typeflag: typeflag,
+		Name:     name,
+		Mode:     int64(mode),
+		Size:     size,
+		ModTime:  t.modTime,
+		Uname:    "root",
+		Gname:    "root",
+		Format:   tar.FormatPAX,
+	}
+}
+
+// countingWriter counts bytes written through it.
+type countingWriter struct {
+	io.Writer
+	written int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.Writer.Write(p)
+	c.written += int64(n)
+	return n, err
+}
+
+// zipWriter writes entries as a deflate-compressed zip archive.
+type zipWriter struct {
+	*zip.Writer
+	modTime time.Time
+}
+
+// newZipWriter starts zip archive, recording commit hash as the archive comment as Git does.
+func newZipWriter(w io.Writer, opts Options) *zipWriter {
+	writer := &zipWriter{Writer: zip.NewWriter(w), modTime: opts.ModTime}
+	if opts.Commit != "" {
+		writer.SetComment(opts.Commit)
+	}
+	return writer
+}
+
+func (z *zipWriter) writeDir(name string) error {
+	_, err := z.CreateHeader(z.header(name, fs.ModeDir|dirMode, zip.Store))
+	return err
+}
+
+func (z *zipWriter) writeFile(name string, mode fs.FileMode, size int64, content io.Reader) error {
+	writer, err := z.CreateHeader(z.header(name, mode, zip.Deflate))
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(writer, content)
+	return err
+}
+
+func (z *zipWriter) writeSymlink(name, target string) error {
+	writer, err := z.CreateHeader(z.header(name, fs.ModeSymlink|symlinkMode, zip.Store))
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(writer, target)
+	return err
+}
+
+func (z *zipWriter) header(name string, mode fs.FileMode, method uint16) *zip.FileHeader {
+	header := &zip.FileHeader{
+		Name:     name,
+		Method:   method,
+		Modified: z.modTime,
+	}
+	header.SetMode(mode)
+	return header
+}
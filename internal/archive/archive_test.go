@@ -0,0 +1,186 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// archivedEntry is the part of an archive entry tests compare.
+type archivedEntry struct {
+	mode    fs.FileMode
+	content string
+}
+
+// storeTestTree stores tree with a file, executable, symlink and nested directory.
+func storeTestTree(t *testing.T, store *objects.ObjectStore) string {
+	t.Helper()
+
+	mustStore := func(obj objects.Object) {
+		t.Helper()
+		if err := store.Store(obj); err != nil {
+			t.Fatalf("Failed to store object: %v", err)
+		}
+	}
+	mustEntry := func(mode objects.FileMode, name, hash string) objects.TreeEntry {
+		t.Helper()
+		entry, err := objects.NewTreeEntry(mode, name, hash)
+		if err != nil {
+			t.Fatalf("Failed to create entry: %v", err)
+		}
+		return *entry
+	}
+	mustTree := func(entries ...objects.TreeEntry) *objects.Tree {
+		t.Helper()
+		tree, err := objects.NewTree(entries)
+		if err != nil {
+			t.Fatalf("Failed to create tree: %v", err)
+		}
+		mustStore(tree)
+		return tree
+	}
+
+	readme := objects.NewBlob([]byte("readme\n"))
+	script := objects.NewBlob([]byte("#!/bin/sh\n"))
+	target := objects.NewBlob([]byte("README.md"))
+	for _, blob := range []*objects.Blob{readme, script, target} {
+		mustStore(blob)
+	}
+
+	sub := mustTree(mustEntry(objects.ModeRegularFile, "nested.txt", readme.Hash()))
+	root := mustTree(
+		mustEntry(objects.ModeRegularFile, "README.md", readme.Hash()),
+		mustEntry(objects.ModeExecutable, "run.sh", script.Hash()),
+		mustEntry(objects.ModeSymlink, "link", target.Hash()),
+		mustEntry(objects.ModeDirectory, "src", sub.Hash()),
+	)
+	return root.Hash()
+}
+
+// expectedEntries lists archive contents of storeTestTree under prefix "p/".
+var expectedEntries = map[string]archivedEntry{
+	"p/":               {fs.ModeDir | dirMode, ""},
+	"p/README.md":      {fileMode, "readme\n"},
+	"p/link":           {fs.ModeSymlink | symlinkMode, "README.md"},
+	"p/run.sh":         {executableMode, "#!/bin/sh\n"},
+	"p/src/":           {fs.ModeDir | dirMode, ""},
+	"p/src/nested.txt": {fileMode, "readme\n"},
+}
+
+// assertEntries compares archived entries with expectedEntries.
+func assertEntries(t *testing.T, entries map[string]archivedEntry) {
+	t.Helper()
+
+	if len(entries) != len(expectedEntries) {
+		t.Errorf("Expected %d entries, got %d: %v", len(expectedEntries), len(entries), entries)
+	}
+	for name, expected := range expectedEntries {
+		if entries[name] != expected {
+			t.Errorf("%s: expected %v, got %v", name, expected, entries[name])
+		}
+	}
+}
+
+// TestWrite_Tar verifies tar entries, modes, timestamps, commit comment and record padding.
+func TestWrite_Tar(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := objects.NewObjectStore(repository.GitDir(repoPath))
+	treeHash := storeTestTree(t, store)
+	modTime := time.Unix(1700000000, 0)
+	commit := testutils.RandomHash()
+
+	var buf bytes.Buffer
+	opts := Options{Format: FormatTar, Prefix: "p/", ModTime: modTime, Commit: commit}
+	if err := Write(context.Background(), &buf, store, treeHash, opts); err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+
+	if buf.Len()%tarRecordSize != 0 {
+		t.Errorf("Expected output padded to %d-byte records, got %d bytes", tarRecordSize, buf.Len())
+	}
+
+	reader := tar.NewReader(&buf)
+	entries := make(map[string]archivedEntry)
+	sawComment := false
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read archive: %v", err)
+		}
+		if header.Typeflag == tar.TypeXGlobalHeader {
+			sawComment = header.Name == "pax_global_header" && header.PAXRecords["comment"] == commit
+			continue
+		}
+		if !header.ModTime.Equal(modTime) {
+			t.Errorf("%s: expected mtime %s, got %s", header.Name, modTime, header.ModTime)
+		}
+
+		content, _ := io.ReadAll(reader)
+		if header.Typeflag == tar.TypeSymlink {
+			content = []byte(header.Linkname)
+		}
+		entries[header.Name] = archivedEntry{header.FileInfo().Mode(), string(content)}
+	}
+
+	if !sawComment {
+		t.Errorf("Expected global header with commit comment %s", commit)
+	}
+	assertEntries(t, entries)
+}
+
+// TestWrite_Zip verifies zip entries, modes and commit comment.
+func TestWrite_Zip(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := objects.NewObjectStore(repository.GitDir(repoPath))
+	treeHash := storeTestTree(t, store)
+	commit := testutils.RandomHash()
+
+	var buf bytes.Buffer
+	opts := Options{Format: FormatZip, Prefix: "p/", ModTime: time.Unix(1700000000, 0), Commit: commit}
+	if err := Write(context.Background(), &buf, store, treeHash, opts); err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Failed to open archive: %v", err)
+	}
+	if reader.Comment != commit {
+		t.Errorf("Expected comment %s, got %q", commit, reader.Comment)
+	}
+
+	entries := make(map[string]archivedEntry)
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", file.Name, err)
+		}
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		entries[file.Name] = archivedEntry{file.Mode(), string(content)}
+	}
+
+	assertEntries(t, entries)
+}
+
+// TestWrite_UnknownFormat verifies unsupported formats are rejected.
+func TestWrite_UnknownFormat(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := objects.NewObjectStore(repository.GitDir(repoPath))
+
+	if err := Write(context.Background(), io.Discard, store, storeTestTree(t, store), Options{Format: "rar"}); err == nil {
+		t.Fatal("Expected error for unknown format")
+	}
+}
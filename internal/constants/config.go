@@ -5,8 +5,40 @@ import "os"
 // Command name constants used in tests and error messages.
 // Cobra Use fields remain inline for CLI discoverability.
 const (
-	InitCmdName       = "init"
-	HashObjectCmdName = "hash-object"
+	InitCmdName              = "init"
+	HashObjectCmdName        = "hash-object"
+	UpdateIndexCmdName       = "update-index"
+	MktreeCmdName            = "mktree"
+	MktagCmdName             = "mktag"
+	CatFileCmdName           = "cat-file"
+	InterpretTrailersCmdName = "interpret-trailers"
+	CheckMailmapCmdName      = "check-mailmap"
+	ShortlogCmdName          = "shortlog"
+	DescribeCmdName          = "describe"
+	NotesCmdName             = "notes"
+	ArchiveCmdName           = "archive"
+	FastExportCmdName        = "fast-export"
+	FastImportCmdName        = "fast-import"
+	ForEachRefCmdName        = "for-each-ref"
+	ShowRefCmdName           = "show-ref"
+	TagCmdName               = "tag"
+	ApplyCmdName             = "apply"
+	MergeTreeCmdName         = "merge-tree"
+	DiffTreeCmdName          = "diff-tree"
+	DiffIndexCmdName         = "diff-index"
+	DiffFilesCmdName         = "diff-files"
+	CheckoutIndexCmdName     = "checkout-index"
+	AddCmdName               = "add"
+	ReplaceCmdName           = "replace"
+	IndexPackCmdName         = "index-pack"
+	VerifyPackCmdName        = "verify-pack"
+	UnpackObjectsCmdName     = "unpack-objects"
+	LsRemoteCmdName          = "ls-remote"
+	UploadPackCmdName        = "upload-pack"
+	DaemonCmdName            = "daemon"
+	PruneCmdName             = "prune"
+	FsckCmdName              = "fsck"
+	RecoverCmdName           = "recover"
 )
 
 // Repository directory and file names define the gogit metadata structure.
@@ -14,6 +46,9 @@ const (
 	// Gogit is the repository metadata directory.
 	Gogit = ".gogit"
 
+	// GitMetadataDir is Git's metadata directory, never tracked as worktree content.
+	GitMetadataDir = ".git"
+
 	// Objects stores content-addressable objects (blobs, trees, commits).
 	Objects = "objects"
 
@@ -28,6 +63,27 @@ const (
 
 	// Head points to current branch or detached commit.
 	Head = "HEAD"
+
+	// Index is the staging area file holding the next commit's snapshot.
+	Index = "index"
+
+	// TempObjectPattern names temporary files written under objects/ before rename into place.
+	TempObjectPattern = "tmp_obj_*"
+
+	// MailmapFile maps commit identities to canonical ones, read from the worktree root.
+	MailmapFile = ".mailmap"
+
+	// GlobalConfigFile is the user's config file, read from the home directory.
+	GlobalConfigFile = ".gogitconfig"
+
+	// AddEditFile holds a hunk while it is edited during add -p, under the metadata directory.
+	AddEditFile = "addp-hunk-edit.diff"
+
+	// LockSuffix is appended to a file name to guard it against concurrent writers.
+	LockSuffix = ".lock"
+
+	// DaemonExportOKFile marks a repository the daemon may serve, in its metadata directory.
+	DaemonExportOKFile = "git-daemon-export-ok"
 )
 
 // Default repository values.
@@ -37,6 +93,21 @@ const (
 
 	// DefaultRefPrefix is prepended to branch names in HEAD file.
 	DefaultRefPrefix = "ref: refs/heads/"
+
+	// SymbolicRefPrefix starts the content of refs that point at another ref.
+	SymbolicRefPrefix = "ref: "
+
+	// NotesRef holds the history of notes attached to objects.
+	NotesRef = "refs/notes/commits"
+
+	// TagRefPrefix is prepended to tag names to form their full ref name.
+	TagRefPrefix = "refs/tags/"
+
+	// BranchRefPrefix is prepended to branch names to form their full ref name.
+	BranchRefPrefix = "refs/heads/"
+
+	// ReplaceRefPrefix is prepended to the hash of a replaced object to name the ref of its replacement.
+	ReplaceRefPrefix = "refs/replace/"
 )
 
 // File system permissions for created files and directories.
@@ -46,6 +117,9 @@ const (
 
 	// FilePerms grants read/write to owner, read-only to others (rw-r--r--).
 	FilePerms os.FileMode = 0644
+
+	// ObjectFilePerms makes immutable object files read-only for everyone (r--r--r--).
+	ObjectFilePerms os.FileMode = 0444
 )
 
 // Cryptographic hash properties.
@@ -56,8 +130,17 @@ const (
 	// HashStringLength is hex string length of SHA-1 hash (40 characters).
 	HashStringLength = 40
 
+	// EmptyTreeHash names the tree with no entries, which Git treats as always present.
+	EmptyTreeHash = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
 	// HashDirPrefixLength is subdirectory prefix length under objects/ (2 characters).
 	HashDirPrefixLength = 2
+
+	// MinPrefixLength is the shortest abbreviated hash accepted when resolving objects (4 characters).
+	MinPrefixLength = 4
+
+	// DefaultObjectCacheSize is how many parsed trees and commits an object store keeps in memory.
+	DefaultObjectCacheSize = 1024
 )
 
 // Git object type prefixes used in object headers and commit metadata.
@@ -79,6 +162,21 @@ const (
 
 	// CommitCommitterPrefix marks committer metadata in commit objects.
 	CommitCommitterPrefix = "committer "
+
+	// CommitEncodingPrefix marks the message encoding line in commit objects.
+	CommitEncodingPrefix = "encoding "
+
+	// TagPrefix identifies tag objects in headers ("tag <size>\0") and marks the tag name line.
+	TagPrefix = "tag "
+
+	// TagObjectPrefix marks the tagged object line in tag objects.
+	TagObjectPrefix = "object "
+
+	// TagTypePrefix marks the tagged object type line in tag objects.
+	TagTypePrefix = "type "
+
+	// TagTaggerPrefix marks tagger metadata in tag objects.
+	TagTaggerPrefix = "tagger "
 )
 
 // Object format constants.
@@ -87,8 +185,51 @@ const (
 	NullByte = '\x00'
 )
 
+// Index file format constants (Git index version 2).
+const (
+	// IndexSignature is the magic number at the start of every index file.
+	IndexSignature = "DIRC"
+
+	// IndexVersion is the index format version written by gogit.
+	IndexVersion = 2
+)
+
 // Time conversion constants for timezone formatting.
 const (
 	SecondsPerHour   = 3600
 	SecondsPerMinute = 60
 )
+
+// Environment variables setting the identity recorded in objects gogit creates.
+const (
+	// AuthorNameEnv overrides the author name, defaulting to the current user's name.
+	AuthorNameEnv = "GOGIT_AUTHOR_NAME"
+
+	// AuthorEmailEnv overrides the author email, defaulting to <user>@<hostname>.
+	AuthorEmailEnv = "GOGIT_AUTHOR_EMAIL"
+)
+
+// Environment variables controlling repository discovery.
+const (
+	// GitDirEnv names the repository metadata directory explicitly, such as an existing .git directory.
+	// The current directory is then the worktree root.
+	GitDirEnv = "GOGIT_DIR"
+
+	// CeilingDirectoriesEnv lists absolute directories, separated like PATH, that discovery
+	// never moves up into while searching for a repository.
+	CeilingDirectoriesEnv = "GOGIT_CEILING_DIRECTORIES"
+)
+
+// NoReplaceObjectsEnv, when set, makes object reads ignore refs/replace/, like --no-replace-objects.
+const NoReplaceObjectsEnv = "GOGIT_NO_REPLACE_OBJECTS"
+
+// GlobalConfigEnv overrides the path of the user's config file.
+const GlobalConfigEnv = "GOGIT_CONFIG_GLOBAL"
+
+// EditorEnv names the editor to open files in, taking precedence over core.editor,
+// VISUAL and EDITOR.
+const EditorEnv = "GOGIT_EDITOR"
+
+// SSHCommandEnv replaces "ssh" as the command reaching remotes over SSH. It is split on
+// whitespace, so it may carry options, as in "ssh -i /etc/gogit/deploy_key".
+const SSHCommandEnv = "GOGIT_SSH_COMMAND"
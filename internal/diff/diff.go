@@ -0,0 +1,314 @@
+// Package diff compares trees, the index and the worktree path by path, producing the
+// changes that Git's raw diff format describes, and compares file content line by line.
+package diff
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/index"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/pathspec"
+)
+
+// Status letters of raw diff output.
+const (
+	StatusAdded       = 'A'
+	StatusDeleted     = 'D'
+	StatusModified    = 'M'
+	StatusTypeChanged = 'T'
+	StatusUnmerged    = 'U'
+)
+
+// absentMode is printed for the missing side of an added, deleted or unmerged path.
+const absentMode = "000000"
+
+// zeroHash stands for content not known without hashing a worktree file, or for a missing side.
+var zeroHash = strings.Repeat("0", constants.HashStringLength)
+
+// Side is one version of a path in a comparison. Mode is empty when the path is absent
+// and Hash is empty when the content was not hashed.
+type Side struct {
+	Mode objects.FileMode
+	Hash string
+}
+
+// Change is a difference at one path.
+type Change struct {
+	Path   string
+	Old    Side
+	New    Side
+	Status byte
+}
+
+// newChange returns the change from old to new at path, deriving its status.
+func newChange(path string, old, new Side) Change {
+	change := Change{Path: path, Old: old, New: new, Status: StatusModified}
+	switch {
+	case old.Mode == "":
+		change.Status = StatusAdded
+	case new.Mode == "":
+		change.Status = StatusDeleted
+	case fileType(old.Mode) != fileType(new.Mode):
+		change.Status = StatusTypeChanged
+	}
+	return change
+}
+
+// fileType groups modes that differ only in permissions, so only real type changes report T.
+func fileType(mode objects.FileMode) objects.FileMode {
+	if mode == objects.ModeExecutable {
+		return objects.ModeRegularFile
+	}
+	return mode
+}
+
+// WriteRaw writes changes as ":<old mode> <new mode> <old hash> <new hash> <status>\t<path>" lines.
+// With nul, the path follows the status after a NUL and is terminated by one.
+func WriteRaw(out io.Writer, changes []Change, nul bool) error {
+	separator, terminator := "\t", "\n"
+	if nul {
+		separator, terminator = "\x00", "\x00"
+	}
+
+	for _, change := range changes {
+		_, err := fmt.Fprintf(out, ":%s %s %s %s %c%s%s%s",
+			rawMode(change.Old.Mode), rawMode(change.New.Mode), rawHash(change.Old.Hash), rawHash(change.New.Hash),
+			change.Status, separator, change.Path, terminator)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rawMode returns mode as printed in raw output.
+func rawMode(mode objects.FileMode) string {
+	if mode == "" {
+		return absentMode
+	}
+	return string(mode)
+}
+
+// rawHash returns hash as printed in raw output.
+func rawHash(hash string) string {
+	if hash == "" {
+		return zeroHash
+	}
+	return hash
+}
+
+// Trees compares tree oldTree with tree newTree at the paths selected by paths, or everywhere
+// when it is nil. Without recursive, only top-level entries are compared and a changed
+// subdirectory is reported as one tree entry. Either tree may be empty.
+func Trees(store *objects.ObjectStore, oldTree, newTree string, recursive bool, paths *pathspec.Pathspec) ([]Change, error) {
+	var changes []Change
+	err := compareTrees(store, "", oldTree, newTree, recursive, paths, &changes)
+	return changes, err
+}
+
+// treeItem is an entry of either tree being compared. Files and directories sort apart even
+// with equal names, in the order Git sorts tree entries.
+type treeItem struct {
+	name  string
+	entry *objects.TreeEntry
+}
+
+// compareTrees appends the changes between the trees at prefix to changes.
+func compareTrees(store *objects.ObjectStore, prefix, oldTree, newTree string, recursive bool, paths *pathspec.Pathspec, changes *[]Change) error {
+	if oldTree == newTree {
+		return nil
+	}
+	oldItems, err := treeItems(store, oldTree)
+	if err != nil {
+		return err
+	}
+	newItems, err := treeItems(store, newTree)
+	if err != nil {
+		return err
+	}
+
+	keys := slices.Collect(func(yield func(string) bool) {
+		for key := range oldItems {
+			yield(key)
+		}
+		for key := range newItems {
+			if _, ok := oldItems[key]; !ok {
+				yield(key)
+			}
+		}
+	})
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		oldItem, newItem := oldItems[key], newItems[key]
+		var name string
+		var old, new Side
+		if oldItem.entry != nil {
+			name, old = oldItem.name, Side{Mode: oldItem.entry.Mode(), Hash: oldItem.entry.Hash()}
+		}
+		if newItem.entry != nil {
+			name, new = newItem.name, Side{Mode: newItem.entry.Mode(), Hash: newItem.entry.Hash()}
+		}
+		if old == new {
+			continue
+		}
+
+		path := prefix + name
+		switch {
+		case !strings.HasSuffix(key, "/"):
+			if !paths.Match(path) {
+				continue
+			}
+		case recursive:
+			if !paths.MayMatchInside(path) {
+				continue
+			}
+			if err := compareTrees(store, path+"/", old.Hash, new.Hash, recursive, paths, changes); err != nil {
+				return err
+			}
+			continue
+		case !paths.MatchDirectory(path):
+			continue
+		}
+		*changes = append(*changes, newChange(path, old, new))
+	}
+	return nil
+}
+
+// treeItems reads the entries of tree hash keyed for comparison; an empty hash has none.
+func treeItems(store *objects.ObjectStore, hash string) (map[string]treeItem, error) {
+	items := make(map[string]treeItem)
+	if hash == "" || hash == constants.EmptyTreeHash {
+		return items, nil
+	}
+
+	tree, err := store.ReadTree(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tree %s: %w", hash, err)
+	}
+	for _, entry := range tree.Entries() {
+		key := entry.Name()
+		if entry.IsDirectory() {
+			key += "/"
+		}
+		items[key] = treeItem{name: entry.Name(), entry: &entry}
+	}
+	return items, nil
+}
+
+// TreeIndex compares tree treeHash with the index at the paths selected by paths. Unmerged
+// paths are reported once each, with their tree version as the old side.
+func TreeIndex(store *objects.ObjectStore, treeHash string, idx *index.Index, paths *pathspec.Pathspec) ([]Change, error) {
+	return compareTreeIndex(store, treeHash, idx, paths, func(entry *index.Entry) (Side, error) {
+		return Side{Mode: entry.Mode, Hash: entry.Hash}, nil
+	}, true)
+}
+
+// TreeWorktree compares tree treeHash with the worktree, as far as the index tracks it, at the
+// paths selected by paths. Files whose stat data differs from the index have an unhashed new
+// side; missing files are deleted.
+func TreeWorktree(store *objects.ObjectStore, repoPath, treeHash string, idx *index.Index, paths *pathspec.Pathspec) ([]Change, error) {
+	return compareTreeIndex(store, treeHash, idx, paths, func(entry *index.Entry) (Side, error) {
+		return worktreeSide(repoPath, idx, entry)
+	}, false)
+}
+
+// compareTreeIndex compares tree entries with index entries, taking the new side of each index
+// entry from side. With reportUnmerged, unmerged paths are reported as such instead.
+func compareTreeIndex(store *objects.ObjectStore, treeHash string, idx *index.Index, paths *pathspec.Pathspec, side func(*index.Entry) (Side, error), reportUnmerged bool) ([]Change, error) {
+	treeFiles, err := store.FlattenTree(treeHash)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	seen := make(map[string]bool)
+	entries := idx.Entries()
+	for i := range entries {
+		entry := &entries[i]
+		if seen[entry.Path] {
+			continue
+		}
+		seen[entry.Path] = true
+		if !paths.Match(entry.Path) {
+			continue
+		}
+
+		var old Side
+		if treeEntry, ok := treeFiles[entry.Path]; ok {
+			old = Side{Mode: treeEntry.Mode(), Hash: treeEntry.Hash()}
+		}
+		if entry.Stage != 0 && reportUnmerged {
+			changes = append(changes, Change{Path: entry.Path, Old: old, Status: StatusUnmerged})
+			continue
+		}
+
+		new, err := side(entry)
+		if err != nil {
+			return nil, err
+		}
+		if old != new {
+			changes = append(changes, newChange(entry.Path, old, new))
+		}
+	}
+
+	for path, treeEntry := range treeFiles {
+		if !seen[path] && paths.Match(path) {
+			changes = append(changes, newChange(path, Side{Mode: treeEntry.Mode(), Hash: treeEntry.Hash()}, Side{}))
+		}
+	}
+	slices.SortStableFunc(changes, func(a, b Change) int { return cmp.Compare(a.Path, b.Path) })
+	return changes, nil
+}
+
+// IndexWorktree compares the index with the worktree at the paths selected by paths. An unmerged
+// path is reported as unmerged with its worktree mode, followed by the comparison of its
+// stage 2 ("ours") version.
+func IndexWorktree(repoPath string, idx *index.Index, paths *pathspec.Pathspec) ([]Change, error) {
+	var changes []Change
+	entries := idx.Entries()
+	for start := 0; start < len(entries); {
+		end := start + 1
+		for end < len(entries) && entries[end].Path == entries[start].Path {
+			end++
+		}
+		stages := entries[start:end]
+		start = end
+		if !paths.Match(stages[0].Path) {
+			continue
+		}
+
+		new, err := worktreeSide(repoPath, idx, &stages[0])
+		if err != nil {
+			return nil, err
+		}
+		if stages[0].Stage != 0 {
+			changes = append(changes, Change{Path: stages[0].Path, New: Side{Mode: new.Mode}, Status: StatusUnmerged})
+		}
+
+		for _, entry := range stages {
+			old := Side{Mode: entry.Mode, Hash: entry.Hash}
+			if (entry.Stage == 0 || entry.Stage == 2) && old != new {
+				changes = append(changes, newChange(entry.Path, old, new))
+			}
+		}
+	}
+	return changes, nil
+}
+
+// worktreeSide returns the worktree version of entry: unhashed when it may have changed.
+func worktreeSide(repoPath string, idx *index.Index, entry *index.Entry) (Side, error) {
+	mode, changed, err := idx.StatWorktree(repoPath, entry)
+	if err != nil || mode == "" {
+		return Side{}, err
+	}
+	if changed {
+		return Side{Mode: mode}, nil
+	}
+	return Side{Mode: entry.Mode, Hash: entry.Hash}, nil
+}
@@ -0,0 +1,189 @@
+package diff
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/index"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// storeFiles stores content as blobs and returns index entries for them, keyed by path.
+func storeFiles(t *testing.T, store *objects.ObjectStore, files map[string]string) *index.Index {
+	t.Helper()
+
+	idx := index.New()
+	for name, content := range files {
+		blob := objects.NewBlob([]byte(content))
+		if err := store.Store(blob); err != nil {
+			t.Fatalf("Failed to store blob: %v", err)
+		}
+		idx.Add(index.Entry{Path: name, Mode: objects.ModeRegularFile, Hash: blob.Hash(), Size: uint32(len(content))})
+	}
+	return idx
+}
+
+// storeTree stores files as a tree and returns its hash.
+func storeTree(t *testing.T, store *objects.ObjectStore, files map[string]string) string {
+	t.Helper()
+
+	tree, err := storeFiles(t, store, files).WriteTree(store)
+	if err != nil {
+		t.Fatalf("Failed to write tree: %v", err)
+	}
+	return tree
+}
+
+// statuses returns "<status> <path>" for each change.
+func statuses(changes []Change) []string {
+	var result []string
+	for _, change := range changes {
+		result = append(result, string(change.Status)+" "+change.Path)
+	}
+	return result
+}
+
+// TestTrees verifies recursive and top-level comparison, including a file replaced by a directory.
+func TestTrees(t *testing.T) {
+	store := objects.NewObjectStore(repository.GitDir(testutils.SetupTestRepoWithInit(t)))
+	oldTree := storeTree(t, store, map[string]string{"a.txt": "a\n", "dir/x": "x\n", "dir/y": "y\n", "gone": "g\n", "path": "file\n"})
+	newTree := storeTree(t, store, map[string]string{"a.txt": "a\n", "dir/x": "changed\n", "dir/y": "y\n", "added": "n\n", "path/inner": "i\n"})
+
+	tests := []struct {
+		name      string
+		oldTree   string
+		newTree   string
+		recursive bool
+		expected  []string
+	}{
+		{
+			name:      "recursive",
+			oldTree:   oldTree,
+			newTree:   newTree,
+			recursive: true,
+			expected:  []string{"A added", "M dir/x", "D gone", "D path", "A path/inner"},
+		},
+		{
+			name:     "top level",
+			oldTree:  oldTree,
+			newTree:  newTree,
+			expected: []string{"A added", "M dir", "D gone", "D path", "A path"},
+		},
+		{
+			name:      "from nothing",
+			newTree:   storeTree(t, store, map[string]string{"only": "o\n"}),
+			recursive: true,
+			expected:  []string{"A only"},
+		},
+		{
+			name:     "identical",
+			oldTree:  oldTree,
+			newTree:  oldTree,
+			expected: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			changes, err := Trees(store, test.oldTree, test.newTree, test.recursive, nil)
+			if err != nil {
+				t.Fatalf("Trees failed: %v", err)
+			}
+			if got := statuses(changes); !slices.Equal(got, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+// TestTreeIndex verifies additions, deletions, mode changes and unmerged paths against the index.
+func TestTreeIndex(t *testing.T) {
+	store := objects.NewObjectStore(repository.GitDir(testutils.SetupTestRepoWithInit(t)))
+	tree := storeTree(t, store, map[string]string{"conflict": "c\n", "gone": "g\n", "script": "s\n", "same": "s\n"})
+
+	idx := storeFiles(t, store, map[string]string{"added": "a\n", "script": "s\n", "same": "s\n"})
+	script, _ := idx.Entry("script")
+	script.Mode = objects.ModeExecutable
+	idx.Add(*script)
+	for stage := uint8(1); stage <= 3; stage++ {
+		idx.Add(index.Entry{Path: "conflict", Mode: objects.ModeRegularFile, Hash: testutils.RandomHash(), Stage: stage})
+	}
+
+	changes, err := TreeIndex(store, tree, idx, nil)
+	if err != nil {
+		t.Fatalf("TreeIndex failed: %v", err)
+	}
+	expected := []string{"A added", "U conflict", "D gone", "M script"}
+	if got := statuses(changes); !slices.Equal(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	if changes[1].Old.Mode != objects.ModeRegularFile || changes[1].New != (Side{}) {
+		t.Errorf("Expected unmerged path to keep only its tree side, got %+v", changes[1])
+	}
+}
+
+// TestIndexWorktree verifies modified, deleted and untouched files, and that untouched
+// files are not rehashed.
+func TestIndexWorktree(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	idx := index.New()
+	for name, content := range map[string]string{"changed": "old\n", "deleted": "d\n", "kept": "k\n"} {
+		fullPath := testutils.CreateTestFile(t, repoPath, name, []byte(content))
+		info, err := os.Lstat(fullPath)
+		if err != nil {
+			t.Fatalf("Failed to stat %s: %v", name, err)
+		}
+		entry, err := index.NewEntry(name, objects.NewBlob([]byte(content)).Hash(), info)
+		if err != nil {
+			t.Fatalf("Failed to create entry: %v", err)
+		}
+		idx.Add(*entry)
+	}
+	testutils.CreateTestFile(t, repoPath, "changed", []byte("new content\n"))
+	if err := os.Remove(filepath.Join(repoPath, "deleted")); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+
+	changes, err := IndexWorktree(repoPath, idx, nil)
+	if err != nil {
+		t.Fatalf("IndexWorktree failed: %v", err)
+	}
+	expected := []string{"M changed", "D deleted"}
+	if got := statuses(changes); !slices.Equal(got, expected) {
+		t.Fatalf("Expected %v, got %v", expected, got)
+	}
+	if changes[0].New != (Side{Mode: objects.ModeRegularFile}) {
+		t.Errorf("Expected an unhashed worktree side, got %+v", changes[0].New)
+	}
+}
+
+// TestWriteRaw verifies raw lines with tab and NUL separators.
+func TestWriteRaw(t *testing.T) {
+	changes := []Change{{
+		Path:   "dir/file",
+		Old:    Side{Mode: objects.ModeRegularFile, Hash: "1111111111111111111111111111111111111111"},
+		Status: StatusDeleted,
+	}}
+	line := ":100644 000000 1111111111111111111111111111111111111111 0000000000000000000000000000000000000000 D"
+
+	var out bytes.Buffer
+	if err := WriteRaw(&out, changes, false); err != nil {
+		t.Fatalf("WriteRaw failed: %v", err)
+	}
+	if out.String() != line+"\tdir/file\n" {
+		t.Errorf("Unexpected raw output %q", out.String())
+	}
+
+	out.Reset()
+	if err := WriteRaw(&out, changes, true); err != nil {
+		t.Fatalf("WriteRaw failed: %v", err)
+	}
+	if out.String() != line+"\x00dir/file\x00" {
+		t.Errorf("Unexpected -z output %q", out.String())
+	}
+}
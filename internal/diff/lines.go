@@ -0,0 +1,290 @@
+package diff
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+)
+
+// binaryDetectionSize is how many leading bytes are searched for NUL when detecting binary content.
+const binaryDetectionSize = 8000
+
+// Edit is a change between two line sequences: ACount lines at AStart in a
+// were replaced by BCount lines at BStart in b.
+type Edit struct {
+	AStart, ACount int
+	BStart, BCount int
+}
+
+// SplitLines splits content into lines that keep their newlines.
+func SplitLines(content []byte) []string {
+	if len(content) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// IsBinary reports whether content looks binary, as Git decides: a NUL byte near the start.
+func IsBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), binaryDetectionSize)], 0) >= 0
+}
+
+// Lines returns the edits turning a into b. Changes that could equally sit at several
+// positions are placed as Git's xdiff places them, so merges and patches line up with Git's.
+func Lines(a, b []string) []Edit {
+	aSide := &diffSide{lines: a, changed: make([]bool, len(a))}
+	bSide := &diffSide{lines: b, changed: make([]bool, len(b))}
+	for i := range bSide.changed {
+		bSide.changed[i] = true
+	}
+	for i, j := range matchLines(a, b) {
+		if j < 0 {
+			aSide.changed[i] = true
+		} else {
+			bSide.changed[j] = false
+		}
+	}
+
+	aSide.compact(bSide)
+	bSide.compact(aSide)
+
+	var edits []Edit
+	for i, j := 0, 0; i < len(a) || j < len(b); {
+		if i < len(a) && j < len(b) && !aSide.changed[i] && !bSide.changed[j] {
+			i++
+			j++
+			continue
+		}
+		e := Edit{AStart: i, BStart: j}
+		for i < len(a) && aSide.changed[i] {
+			i++
+		}
+		for j < len(b) && bSide.changed[j] {
+			j++
+		}
+		e.ACount, e.BCount = i-e.AStart, j-e.BStart
+		edits = append(edits, e)
+	}
+	return edits
+}
+
+// matchLines pairs lines of a with equal lines of b along a longest common subsequence.
+// The result maps each index of a to its matching index in b, or -1 when the line is not kept.
+func matchLines(a, b []string) []int {
+	matches := make([]int, len(a))
+	for i := range matches {
+		matches[i] = -1
+	}
+
+	// Common prefix and suffix are matched directly, keeping the search to the changed middle
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		matches[prefix] = prefix
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		matches[len(a)-1-suffix] = len(b) - 1 - suffix
+		suffix++
+	}
+
+	for _, pair := range commonSubsequence(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		matches[prefix+pair[0]] = prefix + pair[1]
+	}
+	return matches
+}
+
+// commonSubsequence returns index pairs of a longest common subsequence of a and b,
+// found with Myers' O(ND) algorithm.
+func commonSubsequence(a, b []string) [][2]int {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return nil
+	}
+
+	// frontier[offset+k] holds the furthest x reached on diagonal k = x - y
+	offset := n + m
+	frontier := make([]int, 2*offset+2)
+	var trace [][]int
+
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, slices.Clone(frontier))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && frontier[offset+k-1] < frontier[offset+k+1]) {
+				x = frontier[offset+k+1]
+			} else {
+				x = frontier[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			frontier[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(trace, offset, n, m)
+			}
+		}
+	}
+	return nil
+}
+
+// backtrack walks the recorded frontiers from the end back to the start, collecting diagonal moves.
+func backtrack(trace [][]int, offset, x, y int) [][2]int {
+	var pairs [][2]int
+	for d := len(trace) - 1; d >= 0; d-- {
+		frontier := trace[d]
+		k := x - y
+
+		var previousK int
+		if k == -d || (k != d && frontier[offset+k-1] < frontier[offset+k+1]) {
+			previousK = k + 1
+		} else {
+			previousK = k - 1
+		}
+		previousX := frontier[offset+previousK]
+		previousY := previousX - previousK
+
+		for x > previousX && y > previousY {
+			x--
+			y--
+			pairs = append(pairs, [2]int{x, y})
+		}
+		x, y = previousX, previousY
+	}
+
+	slices.Reverse(pairs)
+	return pairs
+}
+
+// diffSide is one side of a diff: its lines and which of them are changed.
+type diffSide struct {
+	lines   []string
+	changed []bool
+}
+
+// group is a run of changed lines [start, end); it is empty between two unchanged lines.
+// The k-th group of one side corresponds to the k-th group of the other.
+type group struct {
+	start, end int
+}
+
+// firstGroup returns the group at the start of the side.
+func (s *diffSide) firstGroup() group {
+	g := group{}
+	for g.end < len(s.lines) && s.changed[g.end] {
+		g.end++
+	}
+	return g
+}
+
+// nextGroup moves g to the following group, reporting false at the end of the side.
+func (s *diffSide) nextGroup(g *group) bool {
+	if g.end == len(s.lines) {
+		return false
+	}
+	g.start = g.end + 1
+	g.end = g.start
+	for g.end < len(s.lines) && s.changed[g.end] {
+		g.end++
+	}
+	return true
+}
+
+// previousGroup moves g to the preceding group, reporting false at the start of the side.
+func (s *diffSide) previousGroup(g *group) bool {
+	if g.start == 0 {
+		return false
+	}
+	g.end = g.start - 1
+	g.start = g.end
+	for g.start > 0 && s.changed[g.start-1] {
+		g.start--
+	}
+	return true
+}
+
+// slideDown shifts g one line down when the line after it equals its first line,
+// absorbing any group it runs into. Reports whether it moved.
+func (s *diffSide) slideDown(g *group) bool {
+	if g.end >= len(s.lines) || s.lines[g.start] != s.lines[g.end] {
+		return false
+	}
+	s.changed[g.start] = false
+	s.changed[g.end] = true
+	g.start++
+	g.end++
+	for g.end < len(s.lines) && s.changed[g.end] {
+		g.end++
+	}
+	return true
+}
+
+// slideUp shifts g one line up when the line before it equals its last line,
+// absorbing any group it runs into. Reports whether it moved.
+func (s *diffSide) slideUp(g *group) bool {
+	if g.start == 0 || s.lines[g.start-1] != s.lines[g.end-1] {
+		return false
+	}
+	g.start--
+	g.end--
+	s.changed[g.start] = true
+	s.changed[g.end] = false
+	for g.start > 0 && s.changed[g.start-1] {
+		g.start--
+	}
+	return true
+}
+
+// compact moves each group of changes in s as far down as it can slide, merging groups that
+// meet, unless it can line up with a change in other, as xdiff's change compaction does.
+func (s *diffSide) compact(other *diffSide) {
+	g, otherGroup := s.firstGroup(), other.firstGroup()
+	for {
+		if g.end != g.start {
+			var size, earliestEnd int
+			endMatchingOther := -1
+			for {
+				size = g.end - g.start
+				endMatchingOther = -1
+
+				for s.slideUp(&g) {
+					other.previousGroup(&otherGroup)
+				}
+				earliestEnd = g.end
+				if otherGroup.end > otherGroup.start {
+					endMatchingOther = g.end
+				}
+
+				for s.slideDown(&g) {
+					other.nextGroup(&otherGroup)
+					if otherGroup.end > otherGroup.start {
+						endMatchingOther = g.end
+					}
+				}
+				if size == g.end-g.start {
+					break
+				}
+			}
+
+			// Prefer lining up with the last change on the other side the group could sit next to
+			if g.end != earliestEnd && endMatchingOther != -1 {
+				for otherGroup.end == otherGroup.start {
+					s.slideUp(&g)
+					other.previousGroup(&otherGroup)
+				}
+			}
+		}
+
+		if !s.nextGroup(&g) {
+			return
+		}
+		other.nextGroup(&otherGroup)
+	}
+}
@@ -0,0 +1,16 @@
+package diff
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestLines verifies ambiguous changes slide to the last position they can take.
+func TestLines(t *testing.T) {
+	edits := Lines([]string{"a\n", "b\n", "b\n", "c\n"}, []string{"a\n", "b\n", "c\n"})
+
+	expected := []Edit{{AStart: 2, ACount: 1, BStart: 2, BCount: 0}}
+	if !slices.Equal(edits, expected) {
+		t.Errorf("Expected %v, got %v", expected, edits)
+	}
+}
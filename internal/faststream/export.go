@@ -0,0 +1,257 @@
+package faststream
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/refs"
+	"github.com/KostasZigo/gogit/utils"
+)
+
+// exporter writes history reachable from refs, numbering every blob and commit with a mark.
+type exporter struct {
+	ctx      context.Context
+	out      *bufio.Writer
+	store    *objects.ObjectStore
+	marks    map[string]int // Object hash to the mark it was written with
+	nextMark int
+}
+
+// Export writes a fast-import stream recreating refs and the history reachable from them.
+// Commits are written parents first, each followed by its file changes against its first parent,
+// and annotated tags of commits are written as tag commands. Signatures and other extra commit
+// headers are dropped, as Git's fast-export does by default.
+func Export(ctx context.Context, w io.Writer, store *objects.ObjectStore, exportRefs []refs.Ref) error {
+	e := &exporter{
+		ctx:      ctx,
+		out:      bufio.NewWriter(w),
+		store:    store,
+		marks:    make(map[string]int),
+		nextMark: 1,
+	}
+
+	for _, ref := range exportRefs {
+		if err := e.exportRef(ref); err != nil {
+			return err
+		}
+	}
+	return e.out.Flush()
+}
+
+// exportRef writes history of a single ref, followed by a reset or tag command that points it at its tip.
+func (e *exporter) exportRef(ref refs.Ref) error {
+	objectType, err := e.objectType(ref.Hash)
+	if err != nil {
+		return err
+	}
+
+	switch objectType {
+	case utils.CommitObjectType:
+		return e.exportBranch(ref.Name, ref.Hash)
+	case utils.TagObjectType:
+		return e.exportTag(ref)
+	default:
+		return fmt.Errorf("cannot export %s: it points at a %s, not a commit", ref.Name, objectType)
+	}
+}
+
+// exportBranch writes commits missing from the stream under name. When the tip was
+// already written for another ref, a reset points name at it instead.
+func (e *exporter) exportBranch(name, tip string) error {
+	if _, done := e.marks[tip]; !done {
+		return e.writeHistory(name, tip)
+	}
+
+	fmt.Fprintf(e.out, "%s%s\n%s:%d\n\n", cmdReset, name, lineFrom, e.marks[tip])
+	return nil
+}
+
+// exportTag writes the tagged commit's history and then the annotated tag.
+func (e *exporter) exportTag(ref refs.Ref) error {
+	tag, err := e.store.ReadTag(ref.Hash)
+	if err != nil {
+		return err
+	}
+	if tag.ObjectType() != utils.CommitObjectType {
+		return fmt.Errorf("cannot export %s: tag points at a %s, not a commit", ref.Name, tag.ObjectType())
+	}
+
+	if _, done := e.marks[tag.Object()]; !done {
+		if err := e.writeHistory(ref.Name, tag.Object()); err != nil {
+			return err
+		}
+	}
+
+	name := strings.TrimPrefix(ref.Name, constants.TagRefPrefix)
+	fmt.Fprintf(e.out, "%s%s\n%s:%d\n", cmdTag, name, lineFrom, e.marks[tag.Object()])
+	fmt.Fprintf(e.out, "%s%s\n", lineTagger, tag.Tagger().Identity())
+	e.writeData([]byte(tag.Message()))
+	return nil
+}
+
+// writeHistory writes tip and its unwritten ancestors, every commit after all of its parents.
+func (e *exporter) writeHistory(name, tip string) error {
+	stack := []string{tip}
+	for len(stack) > 0 {
+		if err := e.ctx.Err(); err != nil {
+			return err
+		}
+
+		hash := stack[len(stack)-1]
+		if _, done := e.marks[hash]; done {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		commit, err := e.store.ReadCommit(hash)
+		if err != nil {
+			return err
+		}
+
+		pending := slices.DeleteFunc(commit.Parents(), func(parent string) bool {
+			_, done := e.marks[parent]
+			return done
+		})
+		if len(pending) > 0 {
+			stack = append(stack, pending...)
+			continue
+		}
+
+		stack = stack[:len(stack)-1]
+		if err := e.writeCommit(name, commit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCommit writes blobs the commit introduces, then the commit with its changes against its first parent.
+func (e *exporter) writeCommit(name string, commit *objects.Commit) error {
+	files, err := e.store.FlattenTree(commit.TreeHash())
+	if err != nil {
+		return err
+	}
+
+	parentFiles := make(map[string]objects.TreeEntry)
+	if !commit.IsInitialCommit() {
+		parent, err := e.store.ReadCommit(commit.ParentHash())
+		if err != nil {
+			return err
+		}
+		if parentFiles, err = e.store.FlattenTree(parent.TreeHash()); err != nil {
+			return err
+		}
+	}
+
+	var deleted, modified []string
+	for _, path := range slices.Sorted(maps.Keys(parentFiles)) {
+		if _, ok := files[path]; !ok {
+			deleted = append(deleted, path)
+		}
+	}
+	for _, path := range slices.Sorted(maps.Keys(files)) {
+		entry, parentEntry := files[path], parentFiles[path]
+		if entry.Hash() == parentEntry.Hash() && entry.Mode() == parentEntry.Mode() {
+			continue
+		}
+		modified = append(modified, path)
+		if err := e.writeBlob(entry); err != nil {
+			return err
+		}
+	}
+
+	if commit.IsInitialCommit() {
+		fmt.Fprintf(e.out, "%s%s\n", cmdReset, name)
+	}
+	fmt.Fprintf(e.out, "%s%s\n%s:%d\n", cmdCommit, name, lineMark, e.addMark(commit.Hash()))
+	fmt.Fprintf(e.out, "%s%s\n", lineAuthor, commit.Author().Identity())
+	fmt.Fprintf(e.out, "%s%s\n", lineCommitter, commit.Committer().Identity())
+	if commit.Encoding() != "" {
+		fmt.Fprintf(e.out, "%s%s\n", lineEncoding, commit.Encoding())
+	}
+
+	// Message bytes come from the raw object, since parsing trims its trailing newlines
+	_, message, _ := bytes.Cut(commit.Content(), []byte("\n\n"))
+	fmt.Fprintf(e.out, "%s%d\n", lineData, len(message))
+	e.out.Write(message)
+	if !bytes.HasSuffix(message, []byte("\n")) {
+		// The newline after data is optional, so it keeps the next command on its own line
+		e.out.WriteByte('\n')
+	}
+
+	for i, parent := range commit.Parents() {
+		prefix := lineFrom
+		if i > 0 {
+			prefix = lineMerge
+		}
+		fmt.Fprintf(e.out, "%s:%d\n", prefix, e.marks[parent])
+	}
+	for _, path := range deleted {
+		fmt.Fprintf(e.out, "%s%s\n", opDelete, quotePath(path))
+	}
+	for _, path := range modified {
+		entry := files[path]
+		dataRef := entry.Hash()
+		if entry.Mode() != objects.ModeSubmodule {
+			dataRef = fmt.Sprintf(":%d", e.marks[entry.Hash()])
+		}
+		fmt.Fprintf(e.out, "%s%s %s %s\n", opModify, entry.Mode(), dataRef, quotePath(path))
+	}
+	e.out.WriteByte('\n')
+	return nil
+}
+
+// writeBlob streams blob of entry into the stream unless it was already written.
+// Submodule entries name commits of another repository and have no blob.
+func (e *exporter) writeBlob(entry objects.TreeEntry) error {
+	if _, done := e.marks[entry.Hash()]; done || entry.Mode() == objects.ModeSubmodule {
+		return nil
+	}
+
+	reader, err := e.store.OpenObject(entry.Hash())
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	fmt.Fprintf(e.out, "%s\n%s:%d\n%s%d\n", cmdBlob, lineMark, e.addMark(entry.Hash()), lineData, reader.Size())
+	if _, err := io.Copy(e.out, reader); err != nil {
+		return fmt.Errorf("failed to export blob %s: %w", entry.Hash(), err)
+	}
+	e.out.WriteByte('\n')
+	return nil
+}
+
+// writeData writes content framed by its length, followed by a separating newline.
+func (e *exporter) writeData(content []byte) {
+	fmt.Fprintf(e.out, "%s%d\n", lineData, len(content))
+	e.out.Write(content)
+	e.out.WriteByte('\n')
+}
+
+// addMark assigns the next mark to hash.
+func (e *exporter) addMark(hash string) int {
+	mark := e.nextMark
+	e.marks[hash] = mark
+	e.nextMark++
+	return mark
+}
+
+// objectType returns stored type of object hash.
+func (e *exporter) objectType(hash string) (utils.ObjectType, error) {
+	reader, err := e.store.OpenObject(hash)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	return reader.Type(), nil
+}
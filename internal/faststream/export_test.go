@@ -0,0 +1,124 @@
+package faststream
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/refs"
+	"github.com/KostasZigo/gogit/internal/repository"
+)
+
+// TestExport verifies exporting imported history reproduces the stream.
+func TestExport(t *testing.T) {
+	repoPath, store, _, err := importStream(t, testStream)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	exportRefs, err := refs.List(repository.GitDir(repoPath), "refs/")
+	if err != nil {
+		t.Fatalf("Failed to list refs: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := Export(context.Background(), &out, store, exportRefs); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if out.String() != testStream {
+		t.Errorf("Expected stream:\n%s\ngot:\n%s", testStream, out.String())
+	}
+}
+
+// TestExport_Merge verifies merges export from and merge lines after both parents.
+func TestExport_Merge(t *testing.T) {
+	stream := `commit refs/heads/side
+mark :1
+committer Ada <ada@example.com> 1700000000 +0000
+data 5
+side
+M 644 inline b
+data 2
+b
+
+commit refs/heads/main
+mark :2
+committer Ada <ada@example.com> 1700000100 +0000
+data 5
+main
+M 644 inline a
+data 2
+a
+
+commit refs/heads/main
+committer Ada <ada@example.com> 1700000200 +0000
+data 6
+merge
+merge :1
+`
+	repoPath, store, _, err := importStream(t, stream)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	main, err := refs.Expand(repository.GitDir(repoPath), "main")
+	if err != nil {
+		t.Fatalf("Failed to resolve main: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := Export(context.Background(), &out, store, []refs.Ref{main}); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	output := out.String()
+	if !strings.Contains(output, "data 6\nmerge\nfrom :") || strings.Count(output, "\nmerge :") != 1 {
+		t.Errorf("Expected merge commit with from and merge lines, got:\n%s", output)
+	}
+	if strings.Index(output, "data 6\nmerge\n") < strings.Index(output, "data 5\nside\n") {
+		t.Errorf("Expected merged parent before merge commit, got:\n%s", output)
+	}
+}
+
+// TestExport_RejectsNonCommits verifies refs to trees or blobs cannot be exported.
+func TestExport_RejectsNonCommits(t *testing.T) {
+	_, store, _, err := importStream(t, "")
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	blob := objects.NewBlob([]byte("content\n"))
+	if err := store.Store(blob); err != nil {
+		t.Fatalf("Failed to store blob: %v", err)
+	}
+
+	err = Export(context.Background(), &bytes.Buffer{}, store, []refs.Ref{{Name: "refs/heads/blob", Hash: blob.Hash()}})
+	if err == nil || !strings.Contains(err.Error(), "not a commit") {
+		t.Errorf("Expected not a commit error, got %v", err)
+	}
+}
+
+// TestQuotePath verifies paths with quotes, backslashes or control characters round trip.
+func TestQuotePath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"plain/file name.txt", "plain/file name.txt"},
+		{`say "hi"`, `"say \"hi\""`},
+		{"tab\there", `"tab\there"`},
+		{"bell\a", `"bell\007"`},
+	}
+
+	for _, tt := range tests {
+		quoted := quotePath(tt.path)
+		if quoted != tt.expected {
+			t.Errorf("Expected %q quoted as %s, got %s", tt.path, tt.expected, quoted)
+		}
+		path, _, err := parsePath(quoted, true)
+		if err != nil || path != tt.path {
+			t.Errorf("Expected %s to parse back to %q, got %q (%v)", quoted, tt.path, path, err)
+		}
+	}
+}
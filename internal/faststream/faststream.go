@@ -0,0 +1,108 @@
+// Package faststream reads and writes Git's fast-import stream, the plain-text format
+// version control tools use to exchange history: blobs, commits, resets and tags
+// written as commands, with file content and messages framed by "data <length>".
+package faststream
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Stream command words and commit sub-commands.
+const (
+	cmdBlob       = "blob"
+	cmdCommit     = "commit "
+	cmdReset      = "reset "
+	cmdTag        = "tag "
+	cmdProgress   = "progress "
+	cmdFeature    = "feature "
+	cmdCheckpoint = "checkpoint"
+	cmdDone       = "done"
+
+	lineMark        = "mark "
+	lineOriginalOID = "original-oid "
+	lineAuthor      = "author "
+	lineCommitter   = "committer "
+	lineTagger      = "tagger "
+	lineEncoding    = "encoding "
+	lineData        = "data "
+	lineFrom        = "from "
+	lineMerge       = "merge "
+
+	opModify    = "M "
+	opDelete    = "D "
+	opCopy      = "C "
+	opRename    = "R "
+	opNote      = "N "
+	opDeleteAll = "deleteall"
+
+	inlineData = "inline"
+)
+
+// quotePath C-quotes path when it holds characters the stream cannot carry verbatim.
+func quotePath(path string) string {
+	if !strings.ContainsAny(path, "\"\\") && !hasControl(path) {
+		return path
+	}
+
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := range len(path) {
+		switch c := path[i]; c {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if c < 0x20 || c == 0x7f {
+				fmt.Fprintf(&b, "\\%03o", c)
+			} else {
+				b.WriteByte(c)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// hasControl reports whether s contains ASCII control characters.
+func hasControl(s string) bool {
+	return strings.ContainsFunc(s, func(r rune) bool {
+		return r < 0x20 || r == 0x7f
+	})
+}
+
+// parsePath reads a path argument from the start of s, returning the path and the rest of s.
+// Quoted paths end at their closing quote; unquoted ones at the first space when last is false,
+// or at the end of s when the path is the final argument.
+func parsePath(s string, last bool) (string, string, error) {
+	if !strings.HasPrefix(s, `"`) {
+		if last {
+			return s, "", nil
+		}
+		path, rest, found := strings.Cut(s, " ")
+		if !found {
+			return "", "", fmt.Errorf("missing path after %q", s)
+		}
+		return path, rest, nil
+	}
+
+	// Find the closing quote, skipping escaped characters
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			path, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				return "", "", fmt.Errorf("invalid quoted path %s: %w", s[:i+1], err)
+			}
+			return path, strings.TrimPrefix(s[i+1:], " "), nil
+		}
+	}
+	return "", "", fmt.Errorf("unterminated quoted path %s", s)
+}
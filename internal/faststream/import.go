@@ -0,0 +1,699 @@
+package faststream
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/refs"
+	"github.com/KostasZigo/gogit/utils"
+)
+
+// importer replays a stream into the object store, tracking marks and ref tips as it goes.
+type importer struct {
+	ctx      context.Context
+	reader   *bufio.Reader
+	progress io.Writer
+	gitDir   string
+	store    *objects.ObjectStore
+	marks    map[string]string // Mark (":1") to object hash
+	tips     map[string]string // Ref name to its new target, empty after a reset without from
+	unread   *string           // Line pushed back after looking ahead
+}
+
+// Import reads a fast-import stream from r, storing its objects and pointing the refs it names
+// at their final commits or tags. Refs are only updated once the whole stream was read, so a
+// malformed stream leaves them untouched. Progress commands are echoed to progress.
+//
+// Blobs, commits, resets, tags and file changes (M, D, C, R and deleteall) are supported;
+// notes, directory modes and the cat-blob/ls/get-mark queries are rejected.
+func Import(ctx context.Context, r io.Reader, progress io.Writer, gitDir string, store *objects.ObjectStore) error {
+	im := &importer{
+		ctx:      ctx,
+		reader:   bufio.NewReader(r),
+		progress: progress,
+		gitDir:   gitDir,
+		store:    store,
+		marks:    make(map[string]string),
+		tips:     make(map[string]string),
+	}
+
+	if err := im.readCommands(); err != nil {
+		return fmt.Errorf("invalid fast-import stream: %w", err)
+	}
+	return im.updateRefs()
+}
+
+// readCommands dispatches stream commands until done or end of input.
+func (im *importer) readCommands() error {
+	requireDone := false
+	for {
+		if err := im.ctx.Err(); err != nil {
+			return err
+		}
+
+		line, err := im.readLine()
+		if errors.Is(err, io.EOF) {
+			if requireDone {
+				return fmt.Errorf("stream ended without %q", cmdDone)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || line == cmdCheckpoint:
+			continue
+		case line == cmdDone:
+			return nil
+		case line == cmdBlob:
+			err = im.importBlob()
+		case strings.HasPrefix(line, cmdCommit):
+			err = im.importCommit(strings.TrimPrefix(line, cmdCommit))
+		case strings.HasPrefix(line, cmdReset):
+			err = im.importReset(strings.TrimPrefix(line, cmdReset))
+		case strings.HasPrefix(line, cmdTag):
+			err = im.importTag(strings.TrimPrefix(line, cmdTag))
+		case strings.HasPrefix(line, cmdProgress):
+			_, err = fmt.Fprintln(im.progress, line)
+		case strings.HasPrefix(line, cmdFeature):
+			switch feature := strings.TrimPrefix(line, cmdFeature); feature {
+			case cmdDone:
+				requireDone = true
+			case "date-format=raw":
+			default:
+				err = fmt.Errorf("unsupported feature %q", feature)
+			}
+		default:
+			err = fmt.Errorf("unsupported command %q", line)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// importBlob stores a blob, streaming counted data straight into the object store.
+func (im *importer) importBlob() error {
+	mark := im.readMark()
+	im.readOptional(lineOriginalOID)
+
+	line, err := im.readLine()
+	if err != nil {
+		return err
+	}
+	header, ok := strings.CutPrefix(line, lineData)
+	if !ok {
+		return fmt.Errorf("expected data, got %q", line)
+	}
+
+	var hash string
+	if strings.HasPrefix(header, "<<") {
+		content, err := im.readDelimited(strings.TrimPrefix(header, "<<"))
+		if err != nil {
+			return err
+		}
+		hash, err = im.store.StoreStream(utils.BlobObjectType, int64(len(content)), bytes.NewReader(content))
+		if err != nil {
+			return fmt.Errorf("failed to store blob: %w", err)
+		}
+	} else {
+		size, err := parseDataSize(header)
+		if err != nil {
+			return err
+		}
+		hash, err = im.store.StoreStream(utils.BlobObjectType, size, io.LimitReader(im.reader, size))
+		if err != nil {
+			return fmt.Errorf("failed to store blob: %w", err)
+		}
+		im.skipNewline()
+	}
+
+	im.setMark(mark, hash)
+	return nil
+}
+
+// importCommit builds a commit on ref from its headers, message and file changes.
+func (im *importer) importCommit(ref string) error {
+	if err := validateRefName(ref); err != nil {
+		return err
+	}
+
+	mark := im.readMark()
+	im.readOptional(lineOriginalOID)
+	author, hasAuthor := im.readOptional(lineAuthor)
+	committer, ok := im.readOptional(lineCommitter)
+	if !ok {
+		return fmt.Errorf("commit %s is missing a committer", ref)
+	}
+	if !hasAuthor {
+		author = committer
+	}
+	encoding, _ := im.readOptional(lineEncoding)
+
+	message, err := im.readData()
+	if err != nil {
+		return err
+	}
+
+	// Without from, a commit continues the branch it is made on
+	var parents []string
+	if from, ok := im.readOptional(lineFrom); ok {
+		hash, err := im.resolveCommit(from)
+		if err != nil {
+			return err
+		}
+		parents = append(parents, hash)
+	} else if tip, err := im.currentTip(ref); err != nil {
+		return err
+	} else if tip != "" {
+		parents = append(parents, tip)
+	}
+	for {
+		merge, ok := im.readOptional(lineMerge)
+		if !ok {
+			break
+		}
+		hash, err := im.resolveCommit(merge)
+		if err != nil {
+			return err
+		}
+		parents = append(parents, hash)
+	}
+
+	files := make(map[string]objects.TreeEntry)
+	if len(parents) > 0 {
+		parent, err := im.store.ReadCommit(parents[0])
+		if err != nil {
+			return err
+		}
+		if files, err = im.store.FlattenTree(parent.TreeHash()); err != nil {
+			return err
+		}
+	}
+	if err := im.applyFileChanges(files); err != nil {
+		return err
+	}
+
+	treeHash, err := im.storeTree(files)
+	if err != nil {
+		return err
+	}
+
+	var content bytes.Buffer
+	fmt.Fprintf(&content, "%s%s\n", constants.TreePrefix, treeHash)
+	for _, parent := range parents {
+		fmt.Fprintf(&content, "%s%s\n", constants.CommitParentPrefix, parent)
+	}
+	fmt.Fprintf(&content, "%s%s\n", constants.CommitAuthorPrefix, author)
+	fmt.Fprintf(&content, "%s%s\n", constants.CommitCommitterPrefix, committer)
+	if encoding != "" {
+		fmt.Fprintf(&content, "%s%s\n", constants.CommitEncodingPrefix, encoding)
+	}
+	content.WriteByte('\n')
+	content.Write(message)
+
+	commit, err := objects.ParseCommit(content.Bytes())
+	if err != nil {
+		return fmt.Errorf("invalid commit %s: %w", ref, err)
+	}
+	if err := im.store.Store(commit); err != nil {
+		return fmt.Errorf("failed to store commit: %w", err)
+	}
+
+	im.setMark(mark, commit.Hash())
+	im.tips[ref] = commit.Hash()
+	return nil
+}
+
+// applyFileChanges applies M, D, C, R and deleteall lines to files until a blank line or another command.
+func (im *importer) applyFileChanges(files map[string]objects.TreeEntry) error {
+	for {
+		line, err := im.readLine()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case line == "":
+			return nil
+		case line == opDeleteAll:
+			clear(files)
+		case strings.HasPrefix(line, opModify):
+			err = im.modifyFile(files, strings.TrimPrefix(line, opModify))
+		case strings.HasPrefix(line, opDelete):
+			var path string
+			if path, _, err = parsePath(strings.TrimPrefix(line, opDelete), true); err == nil {
+				removePath(files, path)
+			}
+		case strings.HasPrefix(line, opCopy), strings.HasPrefix(line, opRename):
+			err = copyPath(files, line[len(opCopy):], strings.HasPrefix(line, opRename))
+		case strings.HasPrefix(line, opNote):
+			err = fmt.Errorf("notes are not supported: %q", line)
+		default:
+			im.unreadLine(line)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// modifyFile handles "M <mode> <dataref> <path>", where dataref is a mark, a hash or inline data.
+func (im *importer) modifyFile(files map[string]objects.TreeEntry, args string) error {
+	fields := strings.SplitN(args, " ", 3)
+	if len(fields) != 3 {
+		return fmt.Errorf("invalid file change %q", opModify+args)
+	}
+
+	mode, err := parseFileMode(fields[0])
+	if err != nil {
+		return err
+	}
+	path, _, err := parsePath(fields[2], true)
+	if err != nil {
+		return err
+	}
+
+	var hash string
+	switch dataRef := fields[1]; {
+	case dataRef == inlineData:
+		content, err := im.readData()
+		if err != nil {
+			return err
+		}
+		blob := objects.NewBlob(content)
+		if err := im.store.Store(blob); err != nil {
+			return fmt.Errorf("failed to store blob: %w", err)
+		}
+		hash = blob.Hash()
+	case strings.HasPrefix(dataRef, ":"):
+		var ok bool
+		if hash, ok = im.marks[dataRef]; !ok {
+			return fmt.Errorf("unknown mark %s", dataRef)
+		}
+	case !utils.IsHash(dataRef):
+		return fmt.Errorf("invalid dataref %q for %s", dataRef, path)
+	case mode == objects.ModeSubmodule || im.store.Exists(dataRef):
+		hash = dataRef
+	default:
+		return fmt.Errorf("object %s for %s does not exist", dataRef, path)
+	}
+
+	entry, err := objects.NewTreeEntry(mode, pathBase(path), hash)
+	if err != nil {
+		return err
+	}
+
+	// A file replaces any directory at its path and any file where its parent directories go
+	removePath(files, path)
+	for dir := range parentDirs(path) {
+		delete(files, dir)
+	}
+	files[path] = *entry
+	return nil
+}
+
+// importReset points ref at the optional from commit, or starts it afresh without one.
+func (im *importer) importReset(ref string) error {
+	if err := validateRefName(ref); err != nil {
+		return err
+	}
+
+	im.tips[ref] = ""
+	if from, ok := im.readOptional(lineFrom); ok {
+		hash, err := im.resolveCommit(from)
+		if err != nil {
+			return err
+		}
+		im.tips[ref] = hash
+	}
+	return nil
+}
+
+// importTag creates an annotated tag and points refs/tags/<name> at it.
+func (im *importer) importTag(name string) error {
+	mark := im.readMark()
+	from, ok := im.readOptional(lineFrom)
+	if !ok {
+		return fmt.Errorf("tag %s is missing from", name)
+	}
+	im.readOptional(lineOriginalOID)
+	tagger, ok := im.readOptional(lineTagger)
+	if !ok {
+		return fmt.Errorf("tag %s is missing a tagger", name)
+	}
+	message, err := im.readData()
+	if err != nil {
+		return err
+	}
+
+	target, err := im.resolveObject(from)
+	if err != nil {
+		return err
+	}
+	targetType, err := im.objectType(target)
+	if err != nil {
+		return err
+	}
+
+	var content bytes.Buffer
+	fmt.Fprintf(&content, "%s%s\n", constants.TagObjectPrefix, target)
+	fmt.Fprintf(&content, "%s%s\n", constants.TagTypePrefix, targetType)
+	fmt.Fprintf(&content, "%s%s\n", constants.TagPrefix, name)
+	fmt.Fprintf(&content, "%s%s\n", constants.TagTaggerPrefix, tagger)
+	content.WriteByte('\n')
+	content.Write(message)
+
+	tag, err := objects.ParseTag(content.Bytes())
+	if err != nil {
+		return err
+	}
+	if err := im.store.Store(tag); err != nil {
+		return fmt.Errorf("failed to store tag: %w", err)
+	}
+
+	im.setMark(mark, tag.Hash())
+	im.tips[constants.TagRefPrefix+name] = tag.Hash()
+	return nil
+}
+
+// updateRefs points every ref the stream touched at its final target, in name order.
+func (im *importer) updateRefs() error {
+	for _, name := range slices.Sorted(maps.Keys(im.tips)) {
+		if hash := im.tips[name]; hash != "" {
+			if err := refs.Update(im.gitDir, name, hash); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// storeTree writes nested trees for flat files and returns the root tree hash.
+func (im *importer) storeTree(files map[string]objects.TreeEntry) (string, error) {
+	if len(files) == 0 {
+		return constants.EmptyTreeHash, nil
+	}
+
+	subdirs := make(map[string]map[string]objects.TreeEntry)
+	var entries []objects.TreeEntry
+	for path, entry := range files {
+		dir, rest, nested := strings.Cut(path, "/")
+		if !nested {
+			entries = append(entries, entry)
+			continue
+		}
+		if subdirs[dir] == nil {
+			subdirs[dir] = make(map[string]objects.TreeEntry)
+		}
+		subdirs[dir][rest] = entry
+	}
+
+	for dir, subdirFiles := range subdirs {
+		hash, err := im.storeTree(subdirFiles)
+		if err != nil {
+			return "", err
+		}
+		entry, err := objects.NewTreeEntry(objects.ModeDirectory, dir, hash)
+		if err != nil {
+			return "", err
+		}
+		entries = append(entries, *entry)
+	}
+
+	tree, err := objects.NewTree(entries)
+	if err != nil {
+		return "", err
+	}
+	if err := im.store.Store(tree); err != nil {
+		return "", fmt.Errorf("failed to store tree: %w", err)
+	}
+	return tree.Hash(), nil
+}
+
+// currentTip returns commit ref points at so far, reading the repository for refs the stream has not touched.
+func (im *importer) currentTip(ref string) (string, error) {
+	if tip, ok := im.tips[ref]; ok {
+		return tip, nil
+	}
+
+	tip, err := refs.Resolve(im.gitDir, ref)
+	if errors.Is(err, refs.ErrRefNotFound) {
+		return "", nil
+	}
+	return tip, err
+}
+
+// resolveCommit resolves a commit-ish reference and checks it names a commit.
+func (im *importer) resolveCommit(name string) (string, error) {
+	hash, err := im.resolveObject(name)
+	if err != nil {
+		return "", err
+	}
+	if _, err := im.store.ReadCommit(hash); err != nil {
+		return "", fmt.Errorf("%s is not a commit: %w", name, err)
+	}
+	return hash, nil
+}
+
+// resolveObject resolves a mark, full hash or ref name to an object hash.
+func (im *importer) resolveObject(name string) (string, error) {
+	if strings.HasPrefix(name, ":") {
+		hash, ok := im.marks[name]
+		if !ok {
+			return "", fmt.Errorf("unknown mark %s", name)
+		}
+		return hash, nil
+	}
+	if utils.IsHash(name) && im.store.Exists(name) {
+		return name, nil
+	}
+
+	hash, err := im.currentTip(name)
+	if err != nil {
+		return "", err
+	}
+	if hash == "" {
+		return "", fmt.Errorf("%s does not name an object", name)
+	}
+	return hash, nil
+}
+
+// objectType returns stored type of object hash.
+func (im *importer) objectType(hash string) (utils.ObjectType, error) {
+	reader, err := im.store.OpenObject(hash)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	return reader.Type(), nil
+}
+
+// readData reads "data <count>" or "data <<<delimiter>" content.
+func (im *importer) readData() ([]byte, error) {
+	line, err := im.readLine()
+	if err != nil {
+		return nil, err
+	}
+	header, ok := strings.CutPrefix(line, lineData)
+	if !ok {
+		return nil, fmt.Errorf("expected data, got %q", line)
+	}
+
+	if delimiter, ok := strings.CutPrefix(header, "<<"); ok {
+		return im.readDelimited(delimiter)
+	}
+
+	size, err := parseDataSize(header)
+	if err != nil {
+		return nil, err
+	}
+	content := make([]byte, size)
+	if _, err := io.ReadFull(im.reader, content); err != nil {
+		return nil, fmt.Errorf("failed to read %d bytes of data: %w", size, err)
+	}
+	im.skipNewline()
+	return content, nil
+}
+
+// readDelimited reads lines up to delimiter, keeping the newline ending each line.
+func (im *importer) readDelimited(delimiter string) ([]byte, error) {
+	var content bytes.Buffer
+	for {
+		line, err := im.readLine()
+		if err != nil {
+			return nil, fmt.Errorf("data not terminated by %q: %w", delimiter, err)
+		}
+		if line == delimiter {
+			return content.Bytes(), nil
+		}
+		content.WriteString(line)
+		content.WriteByte('\n')
+	}
+}
+
+// readMark reads an optional "mark :<n>" line.
+func (im *importer) readMark() string {
+	mark, _ := im.readOptional(lineMark)
+	return mark
+}
+
+// setMark records hash under mark, if the command carried one.
+func (im *importer) setMark(mark, hash string) {
+	if mark != "" {
+		im.marks[mark] = hash
+	}
+}
+
+// readOptional returns value of the next line if it starts with prefix, leaving other lines unread.
+func (im *importer) readOptional(prefix string) (string, bool) {
+	line, err := im.readLine()
+	if err != nil {
+		return "", false
+	}
+	value, ok := strings.CutPrefix(line, prefix)
+	if !ok {
+		im.unreadLine(line)
+		return "", false
+	}
+	return value, true
+}
+
+// readLine returns the next line without its newline, or io.EOF at end of input.
+func (im *importer) readLine() (string, error) {
+	if im.unread != nil {
+		line := *im.unread
+		im.unread = nil
+		return line, nil
+	}
+
+	line, err := im.reader.ReadString('\n')
+	if errors.Is(err, io.EOF) && line != "" {
+		err = nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(line, "\n"), nil
+}
+
+// unreadLine pushes line back so the next readLine returns it.
+func (im *importer) unreadLine(line string) {
+	im.unread = &line
+}
+
+// skipNewline consumes the optional newline following counted data.
+func (im *importer) skipNewline() {
+	if next, err := im.reader.Peek(1); err == nil && next[0] == '\n' {
+		im.reader.ReadByte()
+	}
+}
+
+// parseDataSize parses byte count of a "data <count>" header.
+func parseDataSize(header string) (int64, error) {
+	size, err := strconv.ParseInt(header, 10, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid data length %q", header)
+	}
+	return size, nil
+}
+
+// parseFileMode accepts full modes and Git's short forms 644 and 755.
+func parseFileMode(mode string) (objects.FileMode, error) {
+	switch objects.FileMode(mode) {
+	case "644", objects.ModeRegularFile:
+		return objects.ModeRegularFile, nil
+	case "755", objects.ModeExecutable:
+		return objects.ModeExecutable, nil
+	case objects.ModeSymlink, objects.ModeSubmodule:
+		return objects.FileMode(mode), nil
+	default:
+		return "", fmt.Errorf("unsupported file mode %s", mode)
+	}
+}
+
+// validateRefName rejects ref names outside refs/, which fast-import streams never target.
+func validateRefName(ref string) error {
+	if !strings.HasPrefix(ref, constants.Refs+"/") || strings.Contains(ref, "..") || strings.HasSuffix(ref, "/") {
+		return fmt.Errorf("invalid ref name %q", ref)
+	}
+	return nil
+}
+
+// removePath deletes file at path and everything under it.
+func removePath(files map[string]objects.TreeEntry, path string) {
+	delete(files, path)
+	maps.DeleteFunc(files, func(name string, _ objects.TreeEntry) bool {
+		return strings.HasPrefix(name, path+"/")
+	})
+}
+
+// copyPath handles "C <src> <dst>" and "R <src> <dst>", copying a file or directory and
+// removing the source when renaming.
+func copyPath(files map[string]objects.TreeEntry, args string, rename bool) error {
+	src, rest, err := parsePath(args, false)
+	if err != nil {
+		return err
+	}
+	dst, _, err := parsePath(rest, true)
+	if err != nil {
+		return err
+	}
+
+	copied := make(map[string]objects.TreeEntry)
+	for name, entry := range files {
+		if name != src && !strings.HasPrefix(name, src+"/") {
+			continue
+		}
+		target := dst + strings.TrimPrefix(name, src)
+		copiedEntry, err := objects.NewTreeEntry(entry.Mode(), pathBase(target), entry.Hash())
+		if err != nil {
+			return err
+		}
+		copied[target] = *copiedEntry
+	}
+	if len(copied) == 0 {
+		return fmt.Errorf("path %s not in branch", src)
+	}
+
+	if rename {
+		removePath(files, src)
+	}
+	removePath(files, dst)
+	maps.Copy(files, copied)
+	return nil
+}
+
+// pathBase returns the final element of a slash-separated path.
+func pathBase(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// parentDirs yields each leading directory of path: "a", "a/b" for "a/b/c".
+func parentDirs(path string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for i := range len(path) {
+			if path[i] == '/' && !yield(path[:i]) {
+				return
+			}
+		}
+	}
+}
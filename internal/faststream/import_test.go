@@ -0,0 +1,279 @@
+package faststream
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/refs"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// testStream is a stream in the form Export writes it. Importing it into Git
+// yields the hashes in testStreamRefs.
+const testStream = `blob
+mark :1
+data 6
+hello
+
+reset refs/heads/main
+commit refs/heads/main
+mark :2
+author Ada <ada@example.com> 1700000000 +0100
+committer Ada <ada@example.com> 1700000000 +0100
+data 6
+first
+M 100644 :1 a.txt
+
+blob
+mark :3
+data 4
+run
+
+commit refs/heads/main
+mark :4
+author Ada <ada@example.com> 1700000100 +0100
+committer Bob <bob@example.com> 1700000200 -0500
+data 7
+second
+from :2
+D a.txt
+M 100755 :3 "run \"me\".sh"
+
+reset refs/heads/topic
+from :2
+
+tag v1
+from :4
+tagger Ada <ada@example.com> 1700000300 +0100
+data 8
+release
+
+`
+
+// testStreamRefs are the refs Git creates when importing testStream.
+var testStreamRefs = []refs.Ref{
+	{Name: "refs/heads/main", Hash: "10e0cd25f983f9cbde93da4bf897b5f9414b5676"},
+	{Name: "refs/heads/topic", Hash: "d608f04674a22a1965bb12a77fcba6d61ad426e0"},
+	{Name: "refs/tags/v1", Hash: "1ae3af817e70b269951c08426d33e14af583c25a"},
+}
+
+// importStream imports stream into a new repository, returning its path, store, progress output and import error.
+func importStream(t *testing.T, stream string) (string, *objects.ObjectStore, string, error) {
+	t.Helper()
+
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	gitDir := repository.GitDir(repoPath)
+	store := objects.NewObjectStore(gitDir)
+	var progress bytes.Buffer
+	err := Import(context.Background(), strings.NewReader(stream), &progress, gitDir, store)
+	return repoPath, store, progress.String(), err
+}
+
+// assertFiles checks tree of commit hash holds exactly files, mapped from path to content.
+func assertFiles(t *testing.T, store *objects.ObjectStore, commitHash string, files map[string]string) {
+	t.Helper()
+
+	commit, err := store.ReadCommit(commitHash)
+	if err != nil {
+		t.Fatalf("Failed to read commit: %v", err)
+	}
+	entries, err := store.FlattenTree(commit.TreeHash())
+	if err != nil {
+		t.Fatalf("Failed to read tree: %v", err)
+	}
+
+	if len(entries) != len(files) {
+		t.Errorf("Expected %d files, got %d", len(files), len(entries))
+	}
+	for path, content := range files {
+		entry, ok := entries[path]
+		if !ok {
+			t.Errorf("Expected file %s", path)
+			continue
+		}
+		blob, err := store.ReadBlob(entry.Hash())
+		if err != nil {
+			t.Fatalf("Failed to read blob of %s: %v", path, err)
+		}
+		if string(blob.Content()) != content {
+			t.Errorf("Expected %s to contain %q, got %q", path, content, blob.Content())
+		}
+	}
+}
+
+// TestImport verifies commits, resets and tags produce the same objects Git creates.
+func TestImport(t *testing.T) {
+	repoPath, store, _, err := importStream(t, testStream)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	for _, expected := range testStreamRefs {
+		hash, err := refs.Resolve(repository.GitDir(repoPath), expected.Name)
+		if err != nil {
+			t.Fatalf("Failed to resolve %s: %v", expected.Name, err)
+		}
+		if hash != expected.Hash {
+			t.Errorf("Expected %s at %s, got %s", expected.Name, expected.Hash, hash)
+		}
+	}
+
+	commit, err := store.ReadCommit(testStreamRefs[0].Hash)
+	if err != nil {
+		t.Fatalf("Failed to read commit: %v", err)
+	}
+	if commit.Committer().Name != "Bob" || commit.Author().Name != "Ada" {
+		t.Errorf("Expected author Ada and committer Bob, got %s and %s", commit.Author().Name, commit.Committer().Name)
+	}
+}
+
+// TestImport_FileChanges verifies inline and delimited data, copies, renames and implicit parents.
+func TestImport_FileChanges(t *testing.T) {
+	stream := `commit refs/heads/main
+committer Ada <ada@example.com> 1700000000 +0000
+data <<EOF
+first
+EOF
+M 644 inline docs/a.txt
+data 2
+a
+M 644 inline b.txt
+data <<END
+b
+END
+
+progress first done
+commit refs/heads/main
+committer Ada <ada@example.com> 1700000100 +0000
+data 7
+second
+C docs docs-copy
+R b.txt docs/b.txt
+M 100644 inline docs
+data 5
+file
+done
+`
+	repoPath, store, progress, err := importStream(t, stream)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if progress != "progress first done\n" {
+		t.Errorf("Expected progress output, got %q", progress)
+	}
+
+	tip, err := refs.Resolve(repository.GitDir(repoPath), "refs/heads/main")
+	if err != nil {
+		t.Fatalf("Failed to resolve main: %v", err)
+	}
+	assertFiles(t, store, tip, map[string]string{
+		"docs":            "file\n",
+		"docs-copy/a.txt": "a\n",
+	})
+
+	commit, err := store.ReadCommit(tip)
+	if err != nil {
+		t.Fatalf("Failed to read commit: %v", err)
+	}
+	if commit.IsInitialCommit() {
+		t.Fatal("Expected second commit to continue the branch")
+	}
+	if commit.Message() != "second" {
+		t.Errorf("Expected message %q, got %q", "second", commit.Message())
+	}
+	assertFiles(t, store, commit.ParentHash(), map[string]string{
+		"docs/a.txt": "a\n",
+		"b.txt":      "b\n",
+	})
+}
+
+// TestImport_Merge verifies merge lines add parents in order.
+func TestImport_Merge(t *testing.T) {
+	stream := `commit refs/heads/main
+mark :1
+committer Ada <ada@example.com> 1700000000 +0000
+data 5
+base
+M 644 inline a
+data 2
+a
+
+commit refs/heads/side
+mark :2
+committer Ada <ada@example.com> 1700000100 +0000
+data 5
+side
+from :1
+M 644 inline b
+data 2
+b
+
+commit refs/heads/main
+mark :3
+committer Ada <ada@example.com> 1700000200 +0000
+data 6
+merge
+merge :2
+`
+	repoPath, store, _, err := importStream(t, stream)
+	gitDir := repository.GitDir(repoPath)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	tip, err := refs.Resolve(gitDir, "refs/heads/main")
+	if err != nil {
+		t.Fatalf("Failed to resolve main: %v", err)
+	}
+	merge, err := store.ReadCommit(tip)
+	if err != nil {
+		t.Fatalf("Failed to read merge: %v", err)
+	}
+	side, err := refs.Resolve(gitDir, "refs/heads/side")
+	if err != nil {
+		t.Fatalf("Failed to resolve side: %v", err)
+	}
+	if parents := merge.Parents(); len(parents) != 2 || parents[1] != side {
+		t.Errorf("Expected merge of main and %s, got parents %v", side, parents)
+	}
+	assertFiles(t, store, tip, map[string]string{"a": "a\n"})
+}
+
+// TestImport_Errors verifies malformed streams are rejected without updating refs.
+func TestImport_Errors(t *testing.T) {
+	validCommit := "commit refs/heads/main\ncommitter Ada <ada@example.com> 1700000000 +0000\ndata 2\nok\n\n"
+
+	tests := []struct {
+		name          string
+		stream        string
+		expectedError string
+	}{
+		{"unknown command", validCommit + "bogus\n", `unsupported command "bogus"`},
+		{"missing committer", "commit refs/heads/main\ndata 2\nok\n", "missing a committer"},
+		{"bad ref", "commit main\ncommitter Ada <ada@example.com> 1700000000 +0000\ndata 2\nok\n", "invalid ref name"},
+		{"unknown mark", validCommit + "reset refs/heads/other\nfrom :9\n", "unknown mark :9"},
+		{"short data", "blob\ndata 10\nshort\n", "failed to store blob"},
+		{"notes", validCommit + "commit refs/heads/main\ncommitter Ada <ada@example.com> 1700000000 +0000\ndata 2\nok\nN inline :1\n", "notes are not supported"},
+		{"missing done", "feature done\n" + validCommit, "stream ended without"},
+		{"unsupported feature", "feature import-marks=marks\n", "unsupported feature"},
+		{"short dataref", validCommit + "commit refs/heads/main\ncommitter Ada <ada@example.com> 1700000000 +0000\ndata 2\nok\nM 100644 a path\n", `invalid dataref "a"`},
+		{"path as dataref", validCommit + "commit refs/heads/main\ncommitter Ada <ada@example.com> 1700000000 +0000\ndata 2\nok\nM 100644 ../x path\n", `invalid dataref "../x"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoPath, _, _, err := importStream(t, tt.stream)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Fatalf("Expected error containing [%s], got %v", tt.expectedError, err)
+			}
+			if _, err := refs.Resolve(repository.GitDir(repoPath), "refs/heads/main"); !errors.Is(err, refs.ErrRefNotFound) {
+				t.Errorf("Expected no refs after failed import, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package index
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+)
+
+// cacheTreeSignature identifies the cached tree extension in the index file.
+const cacheTreeSignature = "TREE"
+
+// cacheTree records the tree hash of an index directory so unchanged subtrees
+// can be reused by WriteTree instead of being rebuilt and rehashed.
+type cacheTree struct {
+	name       string       // Path component relative to parent, empty for root
+	entryCount int          // Index entries covered by this tree, -1 when invalidated
+	hash       string       // Tree object hash, valid only when entryCount >= 0
+	children   []*cacheTree // Subdirectories sorted by name
+}
+
+// isValid reports whether the cached hash can be reused.
+func (ct *cacheTree) isValid() bool {
+	return ct.entryCount >= 0
+}
+
+// child finds subdirectory node by name.
+func (ct *cacheTree) child(name string) *cacheTree {
+	i, found := slices.BinarySearchFunc(ct.children, name, compareCacheTreeName)
+	if !found {
+		return nil
+	}
+	return ct.children[i]
+}
+
+// ensureChild returns subdirectory node by name, creating an invalid one if missing.
+func (ct *cacheTree) ensureChild(name string) *cacheTree {
+	i, found := slices.BinarySearchFunc(ct.children, name, compareCacheTreeName)
+	if found {
+		return ct.children[i]
+	}
+
+	node := &cacheTree{name: name, entryCount: -1}
+	ct.children = slices.Insert(ct.children, i, node)
+	return node
+}
+
+// compareCacheTreeName orders cache tree nodes by name.
+func compareCacheTreeName(node *cacheTree, name string) int {
+	return strings.Compare(node.name, name)
+}
+
+// invalidate marks every node on the way to path as stale.
+// Directories not containing path keep their cached hashes.
+func (ct *cacheTree) invalidate(path string) {
+	node := ct
+	components := strings.Split(path, "/")
+
+	for _, component := range components[:len(components)-1] {
+		node.entryCount = -1
+		node = node.child(component)
+		if node == nil {
+			return
+		}
+	}
+	node.entryCount = -1
+}
+
+// encode serializes node and its children depth-first:
+// <name>\0<entry count> <subtree count>\n[20-byte hash]
+func (ct *cacheTree) encode(buf *bytes.Buffer) {
+	buf.WriteString(ct.name)
+	buf.WriteByte(constants.NullByte)
+	fmt.Fprintf(buf, "%d %d\n", ct.entryCount, len(ct.children))
+
+	if ct.isValid() {
+		hashBytes, _ := hex.DecodeString(ct.hash)
+		buf.Write(hashBytes)
+	}
+
+	for _, child := range ct.children {
+		child.encode(buf)
+	}
+}
+
+// decodeCacheTree parses TREE extension data into a cache tree rooted at "".
+func decodeCacheTree(data []byte) (*cacheTree, error) {
+	root, rest, err := decodeCacheTreeNode(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s extension: %w", cacheTreeSignature, err)
+	}
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("invalid %s extension: %d trailing bytes", cacheTreeSignature, len(rest))
+	}
+	if root.name != "" {
+		return nil, fmt.Errorf("invalid %s extension: root has name %q", cacheTreeSignature, root.name)
+	}
+	return root, nil
+}
+
+// decodeCacheTreeNode parses one node with its children and returns the remaining data.
+func decodeCacheTreeNode(data []byte) (*cacheTree, []byte, error) {
+	nullIndex := bytes.IndexByte(data, constants.NullByte)
+	if nullIndex == -1 {
+		return nil, nil, fmt.Errorf("unterminated path")
+	}
+	name := string(data[:nullIndex])
+	data = data[nullIndex+1:]
+
+	newlineIndex := bytes.IndexByte(data, '\n')
+	if newlineIndex == -1 {
+		return nil, nil, fmt.Errorf("unterminated counts for %q", name)
+	}
+
+	counts := strings.Fields(string(data[:newlineIndex]))
+	data = data[newlineIndex+1:]
+	if len(counts) != 2 {
+		return nil, nil, fmt.Errorf("malformed counts for %q", name)
+	}
+
+	entryCount, err := strconv.Atoi(counts[0])
+	if err != nil || entryCount < -1 {
+		return nil, nil, fmt.Errorf("invalid entry count for %q", name)
+	}
+	subtreeCount, err := strconv.Atoi(counts[1])
+	if err != nil || subtreeCount < 0 {
+		return nil, nil, fmt.Errorf("invalid subtree count for %q", name)
+	}
+
+	node := &cacheTree{name: name, entryCount: entryCount}
+	if node.isValid() {
+		if len(data) < constants.HashByteLength {
+			return nil, nil, fmt.Errorf("truncated hash for %q", name)
+		}
+		node.hash = hex.EncodeToString(data[:constants.HashByteLength])
+		data = data[constants.HashByteLength:]
+	}
+
+	for range subtreeCount {
+		var child *cacheTree
+		child, data, err = decodeCacheTreeNode(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		i, _ := slices.BinarySearchFunc(node.children, child.name, compareCacheTreeName)
+		node.children = slices.Insert(node.children, i, child)
+	}
+
+	return node, data, nil
+}
+
+// WriteTree stores tree objects for the index content and returns the root tree hash.
+// Subtrees whose cached hash is still valid are reused without rebuilding or rehashing.
+// The refreshed cache is persisted on the next Write of the index.
+func (idx *Index) WriteTree(store *objects.ObjectStore) (string, error) {
+	if len(idx.entries) == 0 {
+		return "", fmt.Errorf("cannot write tree from empty index")
+	}
+
+	for _, entry := range idx.entries {
+		if entry.Stage != 0 {
+			return "", fmt.Errorf("cannot write tree: %s is unmerged", entry.Path)
+		}
+	}
+
+	if idx.cacheTree == nil {
+		idx.cacheTree = &cacheTree{entryCount: -1}
+	}
+
+	if err := idx.updateCacheTree(idx.cacheTree, idx.entries, "", store); err != nil {
+		return "", err
+	}
+
+	return idx.cacheTree.hash, nil
+}
+
+// updateCacheTree rebuilds node from entries located under prefix, recursing into subdirectories.
+func (idx *Index) updateCacheTree(node *cacheTree, entries []Entry, prefix string, store *objects.ObjectStore) error {
+	if node.isValid() && node.entryCount == len(entries) && store.Exists(node.hash) {
+		return nil
+	}
+
+	var treeEntries []objects.TreeEntry
+	var children []*cacheTree
+
+	for i := 0; i < len(entries); {
+		relativePath := strings.TrimPrefix(entries[i].Path, prefix)
+		name, _, isNested := strings.Cut(relativePath, "/")
+
+		if !isNested {
+			treeEntry, err := objects.NewTreeEntry(entries[i].Mode, name, entries[i].Hash)
+			if err != nil {
+				return fmt.Errorf("invalid index entry %s: %w", entries[i].Path, err)
+			}
+			treeEntries = append(treeEntries, *treeEntry)
+			i++
+			continue
+		}
+
+		// Collect consecutive entries belonging to the same subdirectory
+		childPrefix := prefix + name + "/"
+		end := i + 1
+		for end < len(entries) && strings.HasPrefix(entries[end].Path, childPrefix) {
+			end++
+		}
+
+		child := node.ensureChild(name)
+		if err := idx.updateCacheTree(child, entries[i:end], childPrefix, store); err != nil {
+			return err
+		}
+		children = append(children, child)
+
+		treeEntry, err := objects.NewTreeEntry(objects.ModeDirectory, name, child.hash)
+		if err != nil {
+			return fmt.Errorf("invalid subtree %s: %w", childPrefix, err)
+		}
+		treeEntries = append(treeEntries, *treeEntry)
+		i = end
+	}
+
+	tree, err := objects.NewTree(treeEntries)
+	if err != nil {
+		return fmt.Errorf("failed to build tree for %q: %w", prefix, err)
+	}
+
+	if err := store.Store(tree); err != nil {
+		return fmt.Errorf("failed to store tree for %q: %w", prefix, err)
+	}
+
+	// Drop cached subdirectories that no longer exist in the index
+	slices.SortFunc(children, func(a, b *cacheTree) int {
+		return strings.Compare(a.name, b.name)
+	})
+	node.children = children
+	node.entryCount = len(entries)
+	node.hash = tree.Hash()
+
+	return nil
+}
@@ -0,0 +1,230 @@
+package index
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// createIndexWithBlobs builds index from path->content map and stores each blob.
+func createIndexWithBlobs(t *testing.T, store *objects.ObjectStore, files map[string]string) *Index {
+	t.Helper()
+
+	idx := New()
+	for path, content := range files {
+		blob := objects.NewBlob([]byte(content))
+		if err := store.Store(blob); err != nil {
+			t.Fatalf("Failed to store blob: %v", err)
+		}
+
+		entry := createTestEntry(path)
+		entry.Hash = blob.Hash()
+		idx.Add(entry)
+	}
+	return idx
+}
+
+// writeTree writes index trees and fails test on error.
+func writeTree(t *testing.T, idx *Index, store *objects.ObjectStore) string {
+	t.Helper()
+
+	hash, err := idx.WriteTree(store)
+	if err != nil {
+		t.Fatalf("WriteTree failed: %v", err)
+	}
+	return hash
+}
+
+// TestWriteTree_BuildsNestedTrees verifies tree objects mirror the index directory layout.
+func TestWriteTree_BuildsNestedTrees(t *testing.T) {
+	store := objects.NewObjectStore(repository.GitDir(testutils.SetupTestRepoWithGogitDir(t)))
+	idx := createIndexWithBlobs(t, store, map[string]string{
+		"README.md":    "readme",
+		"src/main.go":  "main",
+		"src/lib/a.go": "a",
+	})
+
+	rootHash := writeTree(t, idx, store)
+
+	root, err := store.ReadTree(rootHash)
+	if err != nil {
+		t.Fatalf("Failed to read root tree: %v", err)
+	}
+	if len(root.Entries()) != 2 {
+		t.Fatalf("Expected 2 root entries, got %d", len(root.Entries()))
+	}
+
+	srcEntry, found := root.FindEntry("src")
+	if !found || !srcEntry.IsDirectory() {
+		t.Fatal("Expected src directory entry in root tree")
+	}
+
+	src, err := store.ReadTree(srcEntry.Hash())
+	if err != nil {
+		t.Fatalf("Failed to read src tree: %v", err)
+	}
+	if _, found := src.FindEntry("lib"); !found {
+		t.Error("Expected lib directory entry in src tree")
+	}
+	if _, found := src.FindEntry("main.go"); !found {
+		t.Error("Expected main.go entry in src tree")
+	}
+}
+
+// TestWriteTree_Deterministic verifies cached and uncached builds yield same hash.
+func TestWriteTree_Deterministic(t *testing.T) {
+	store := objects.NewObjectStore(repository.GitDir(testutils.SetupTestRepoWithGogitDir(t)))
+	files := map[string]string{"a/b/c.txt": "c", "a/d.txt": "d", "e.txt": "e"}
+
+	first := writeTree(t, createIndexWithBlobs(t, store, files), store)
+	idx := createIndexWithBlobs(t, store, files)
+	writeTree(t, idx, store)
+	second := writeTree(t, idx, store)
+
+	if first != second {
+		t.Fatalf("Expected identical tree hashes, got %s and %s", first, second)
+	}
+}
+
+// TestWriteTree_ReusesUnchangedSubtrees verifies valid cached subtrees are not rebuilt.
+func TestWriteTree_ReusesUnchangedSubtrees(t *testing.T) {
+	store := objects.NewObjectStore(repository.GitDir(testutils.SetupTestRepoWithGogitDir(t)))
+	idx := createIndexWithBlobs(t, store, map[string]string{
+		"lib/util.go": "util",
+		"src/main.go": "main",
+	})
+	writeTree(t, idx, store)
+
+	// Point cached lib tree at a different stored tree: reuse means it shows up in the root
+	decoy := createIndexWithBlobs(t, store, map[string]string{"decoy.go": "decoy"})
+	decoyHash := writeTree(t, decoy, store)
+	libNode := idx.cacheTree.child("lib")
+	libNode.hash = decoyHash
+
+	changed := createIndexWithBlobs(t, store, map[string]string{"src/main.go": "changed"})
+	idx.Add(changed.Entries()[0])
+
+	root, err := store.ReadTree(writeTree(t, idx, store))
+	if err != nil {
+		t.Fatalf("Failed to read root tree: %v", err)
+	}
+	libEntry, _ := root.FindEntry("lib")
+	if libEntry.Hash() != decoyHash {
+		t.Errorf("Expected cached lib hash %s to be reused, got %s", decoyHash, libEntry.Hash())
+	}
+}
+
+// TestIndex_AddInvalidatesAncestors verifies only directories containing the path are invalidated.
+func TestIndex_AddInvalidatesAncestors(t *testing.T) {
+	store := objects.NewObjectStore(repository.GitDir(testutils.SetupTestRepoWithGogitDir(t)))
+	idx := createIndexWithBlobs(t, store, map[string]string{
+		"a/b/c.txt": "c",
+		"a/d.txt":   "d",
+		"x/y.txt":   "y",
+	})
+	writeTree(t, idx, store)
+
+	idx.Add(createTestEntry("a/b/new.txt"))
+
+	a := idx.cacheTree.child("a")
+	if idx.cacheTree.isValid() || a.isValid() || a.child("b").isValid() {
+		t.Error("Expected root, a and a/b to be invalidated")
+	}
+	if !idx.cacheTree.child("x").isValid() {
+		t.Error("Expected unrelated directory x to stay valid")
+	}
+}
+
+// TestWriteTree_RemovedDirectoryDropped verifies cache forgets directories removed from index.
+func TestWriteTree_RemovedDirectoryDropped(t *testing.T) {
+	store := objects.NewObjectStore(repository.GitDir(testutils.SetupTestRepoWithGogitDir(t)))
+	idx := createIndexWithBlobs(t, store, map[string]string{"gone/a.txt": "a", "keep.txt": "k"})
+	writeTree(t, idx, store)
+
+	idx.Remove("gone/a.txt")
+	writeTree(t, idx, store)
+
+	if idx.cacheTree.child("gone") != nil {
+		t.Error("Expected removed directory to be dropped from cache tree")
+	}
+}
+
+// TestWriteTree_Errors verifies empty and unmerged indexes cannot be written.
+func TestWriteTree_Errors(t *testing.T) {
+	store := objects.NewObjectStore(repository.GitDir(testutils.SetupTestRepoWithGogitDir(t)))
+
+	if _, err := New().WriteTree(store); err == nil {
+		t.Error("Expected error for empty index")
+	}
+
+	idx := New()
+	conflicted := createTestEntry("conflict.txt")
+	conflicted.Stage = 1
+	idx.Add(conflicted)
+
+	_, err := idx.WriteTree(store)
+	if err == nil || !strings.Contains(err.Error(), "unmerged") {
+		t.Errorf("Expected unmerged error, got: %v", err)
+	}
+}
+
+// TestCacheTree_EncodeDecodeRoundTrip verifies TREE extension survives index serialization.
+func TestCacheTree_EncodeDecodeRoundTrip(t *testing.T) {
+	store := objects.NewObjectStore(repository.GitDir(testutils.SetupTestRepoWithGogitDir(t)))
+	idx := createIndexWithBlobs(t, store, map[string]string{
+		"a/b/c.txt": "c",
+		"a/d.txt":   "d",
+		"x/y.txt":   "y",
+	})
+	rootHash := writeTree(t, idx, store)
+	idx.Add(createTestEntry("x/z.txt"))
+
+	decoded, err := Decode(encodeIndex(t, idx))
+	if err != nil {
+		t.Fatalf("Failed to decode index: %v", err)
+	}
+
+	root := decoded.cacheTree
+	if root == nil {
+		t.Fatal("Expected cache tree to be decoded")
+	}
+	if root.isValid() {
+		t.Error("Expected invalidated root to stay invalid")
+	}
+
+	a := root.child("a")
+	if a == nil || !a.isValid() || a.entryCount != 2 {
+		t.Fatalf("Expected valid a node covering 2 entries, got %+v", a)
+	}
+	if b := a.child("b"); b == nil || b.hash != idx.cacheTree.child("a").child("b").hash {
+		t.Error("Expected a/b hash to round-trip")
+	}
+	if root.child("x").isValid() {
+		t.Error("Expected invalidated x node to stay invalid")
+	}
+
+	// Rebuilding from decoded cache must give a fresh, consistent tree
+	if hash := writeTree(t, decoded, store); hash == rootHash {
+		t.Error("Expected root hash to change after adding x/z.txt")
+	}
+}
+
+// TestDecodeCacheTree_Malformed verifies corrupt TREE data is rejected.
+func TestDecodeCacheTree_Malformed(t *testing.T) {
+	tests := []string{
+		"",
+		"\x00abc\n",
+		"\x001 0\n",
+		"\x00-1 1\n",
+		"\x00-1 0\nextra",
+	}
+
+	for _, data := range tests {
+		if _, err := decodeCacheTree([]byte(data)); err == nil {
+			t.Errorf("Expected error decoding %q", data)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package index
+
+import "github.com/KostasZigo/gogit/internal/objects"
+
+// MatchesTree reports whether staged entries are exactly the contents of tree treeHash.
+// Unmerged entries never match.
+func (idx *Index) MatchesTree(store *objects.ObjectStore, treeHash string) (bool, error) {
+	treeEntries, err := store.FlattenTree(treeHash)
+	if err != nil {
+		return false, err
+	}
+
+	if len(idx.entries) != len(treeEntries) {
+		return false, nil
+	}
+	for _, entry := range idx.entries {
+		treeEntry, ok := treeEntries[entry.Path]
+		if !ok || entry.Stage != 0 || entry.Hash != treeEntry.Hash() || entry.Mode != treeEntry.Mode() {
+			return false, nil
+		}
+	}
+	return true, nil
+}
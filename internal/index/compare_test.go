@@ -0,0 +1,47 @@
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// TestIndex_MatchesTree verifies staged entries are compared against nested tree contents.
+func TestIndex_MatchesTree(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := objects.NewObjectStore(repository.GitDir(repoPath))
+	idx := New()
+
+	if matches, err := idx.MatchesTree(store, constants.EmptyTreeHash); err != nil || !matches {
+		t.Fatalf("Expected empty index to match empty tree, got %v, %v", matches, err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(repoPath, "src"), constants.DirPerms); err != nil {
+		t.Fatalf("Failed to create src directory: %v", err)
+	}
+	stageFile(t, repoPath, idx, "README.md", []byte("readme"))
+	stageFile(t, repoPath, idx, "src/main.go", []byte("package main"))
+	treeHash, err := idx.WriteTree(store)
+	if err != nil {
+		t.Fatalf("Failed to write tree: %v", err)
+	}
+
+	if matches, err := idx.MatchesTree(store, treeHash); err != nil || !matches {
+		t.Fatalf("Expected index to match its own tree, got %v, %v", matches, err)
+	}
+
+	stageFile(t, repoPath, idx, "src/main.go", []byte("package changed"))
+	if matches, _ := idx.MatchesTree(store, treeHash); matches {
+		t.Error("Expected changed entry not to match tree")
+	}
+
+	idx.Remove("src/main.go")
+	if matches, _ := idx.MatchesTree(store, treeHash); matches {
+		t.Error("Expected removed entry not to match tree")
+	}
+}
@@ -0,0 +1,445 @@
+package index
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/lockfile"
+)
+
+// Binary layout of the index file (Git index format version 2).
+const (
+	headerLength     = 12     // signature(4) + version(4) + entry count(4)
+	entryFixedLength = 62     // 10 stat fields(40) + hash(20) + flags(2)
+	extensionHeader  = 8      // signature(4) + size(4)
+	flagAssumeValid  = 0x8000 // Entry is assumed unchanged in the worktree
+	flagExtended     = 0x4000 // Entry carries version 3 extended flags
+	flagStageMask    = 0x3000 // Merge stage bits
+	flagStageShift   = 12
+	flagNameMask     = 0x0FFF // Path length, saturated at 0xFFF
+	maxStage         = 3
+	entryAlignment   = 8
+)
+
+// Index represents the staging area: a sorted list of entries describing the next commit.
+type Index struct {
+	entries   []Entry
+	cacheTree *cacheTree // Cached subtree hashes (TREE extension), nil if absent
+	timestamp time.Time  // Index file modification time, used to detect racily clean entries
+}
+
+// New creates an empty index.
+func New() *Index {
+	return &Index{}
+}
+
+// Read loads the index file of the repository whose metadata directory is gitDir.
+// Returns empty index if the file does not exist yet.
+func Read(gitDir string) (*Index, error) {
+	path := indexPath(gitDir)
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return New(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat index file: %w", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index file: %w", err)
+	}
+
+	idx, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	idx.timestamp = info.ModTime()
+	return idx, nil
+}
+
+// Write serializes the index into the repository's index file.
+// Content goes through index.lock and is renamed into place, so the previous
+// index survives a crash mid-write. Fails with lockfile.ErrLocked if another process holds the lock.
+func (idx *Index) Write(gitDir string) error {
+	lock, err := lockfile.Acquire(indexPath(gitDir))
+	if err != nil {
+		return err
+	}
+	defer lock.Rollback()
+
+	return idx.writeLocked(lock)
+}
+
+// Update reads the index, applies fn and writes the result while holding the index lock
+// for the whole read-modify-write cycle. The index is left untouched if fn returns error.
+func Update(gitDir string, fn func(idx *Index) error) error {
+	lock, err := lockfile.Acquire(indexPath(gitDir))
+	if err != nil {
+		return err
+	}
+	defer lock.Rollback()
+
+	idx, err := Read(gitDir)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(idx); err != nil {
+		return err
+	}
+
+	return idx.writeLocked(lock)
+}
+
+// writeLocked encodes index into held lock file and commits it over the index file.
+func (idx *Index) writeLocked(lock *lockfile.LockFile) error {
+	data, err := idx.Encode()
+	if err != nil {
+		return err
+	}
+
+	if _, err := lock.Write(data); err != nil {
+		return fmt.Errorf("failed to write index file: %w", err)
+	}
+
+	if err := lock.Commit(); err != nil {
+		return fmt.Errorf("failed to write index file: %w", err)
+	}
+
+	// Entries written in the same instant as the index are racy from now on
+	if info, err := os.Stat(lock.Path()); err == nil {
+		idx.timestamp = info.ModTime()
+	}
+
+	return nil
+}
+
+// Entries returns all entries sorted by path and stage.
+func (idx *Index) Entries() []Entry {
+	return idx.entries
+}
+
+// Len returns number of entries in the index.
+func (idx *Index) Len() int {
+	return len(idx.entries)
+}
+
+// Entry finds the stage 0 entry for path.
+func (idx *Index) Entry(path string) (*Entry, bool) {
+	i, found := idx.search(path, 0)
+	if !found {
+		return nil, false
+	}
+	return &idx.entries[i], true
+}
+
+// Stages returns the entries for path, one per merge stage, sorted by stage.
+func (idx *Index) Stages(path string) []Entry {
+	start, _ := idx.search(path, 0)
+	end := start
+	for end < len(idx.entries) && idx.entries[end].Path == path {
+		end++
+	}
+	return idx.entries[start:end]
+}
+
+// FileDirectoryConflict returns the entry that adding path would conflict with, either
+// a file at one of its leading directories or a file inside a directory at path.
+func (idx *Index) FileDirectoryConflict(path string) (string, bool) {
+	for dir := path; strings.Contains(dir, "/"); {
+		dir = dir[:strings.LastIndex(dir, "/")]
+		if len(idx.Stages(dir)) > 0 {
+			return dir, true
+		}
+	}
+
+	i, _ := idx.search(path+"/", 0)
+	if i < len(idx.entries) && strings.HasPrefix(idx.entries[i].Path, path+"/") {
+		return idx.entries[i].Path, true
+	}
+	return "", false
+}
+
+// Add inserts entry keeping the index sorted, replacing any entry with same path and stage.
+func (idx *Index) Add(entry Entry) {
+	idx.invalidateCacheTree(entry.Path)
+
+	i, found := idx.search(entry.Path, entry.Stage)
+	if found {
+		idx.entries[i] = entry
+		return
+	}
+	idx.entries = slices.Insert(idx.entries, i, entry)
+}
+
+// Remove deletes all stages of path from the index.
+// Returns true if at least one entry was removed.
+func (idx *Index) Remove(path string) bool {
+	idx.invalidateCacheTree(path)
+
+	before := len(idx.entries)
+	idx.entries = slices.DeleteFunc(idx.entries, func(e Entry) bool {
+		return e.Path == path
+	})
+	return len(idx.entries) != before
+}
+
+// invalidateCacheTree marks cached trees containing path as stale.
+func (idx *Index) invalidateCacheTree(path string) {
+	if idx.cacheTree != nil {
+		idx.cacheTree.invalidate(path)
+	}
+}
+
+// search performs binary search for path and stage, returning insert position if absent.
+func (idx *Index) search(path string, stage uint8) (int, bool) {
+	return slices.BinarySearchFunc(idx.entries, Entry{Path: path, Stage: stage}, compareEntries)
+}
+
+// compareEntries orders entries by raw path bytes, then by stage, as Git does.
+func compareEntries(a, b Entry) int {
+	if c := strings.Compare(a.Path, b.Path); c != 0 {
+		return c
+	}
+	return int(a.Stage) - int(b.Stage)
+}
+
+// indexPath constructs filesystem path of the index file.
+func indexPath(gitDir string) string {
+	return filepath.Join(gitDir, constants.Index)
+}
+
+// Encode serializes index into Git index version 2 binary format with SHA-1 trailer.
+func (idx *Index) Encode() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteString(constants.IndexSignature)
+	binary.Write(&buf, binary.BigEndian, uint32(constants.IndexVersion))
+	binary.Write(&buf, binary.BigEndian, uint32(len(idx.entries)))
+
+	for _, entry := range idx.entries {
+		if err := encodeEntry(&buf, entry); err != nil {
+			return nil, fmt.Errorf("failed to encode entry %s: %w", entry.Path, err)
+		}
+	}
+
+	if idx.cacheTree != nil {
+		var extension bytes.Buffer
+		idx.cacheTree.encode(&extension)
+		writeExtension(&buf, cacheTreeSignature, extension.Bytes())
+	}
+
+	checksum := sha1.Sum(buf.Bytes())
+	buf.Write(checksum[:])
+
+	return buf.Bytes(), nil
+}
+
+// encodeEntry writes a single entry: stat data, hash, flags, path and NUL padding.
+func encodeEntry(buf *bytes.Buffer, entry Entry) error {
+	mode, err := modeToUint32(entry.Mode)
+	if err != nil {
+		return err
+	}
+
+	hashBytes, err := hex.DecodeString(entry.Hash)
+	if err != nil || len(hashBytes) != constants.HashByteLength {
+		return fmt.Errorf("invalid hash %q", entry.Hash)
+	}
+
+	if entry.Stage > maxStage {
+		return fmt.Errorf("invalid stage %d", entry.Stage)
+	}
+
+	fields := []uint32{
+		uint32(entry.CTime.Unix()), uint32(entry.CTime.Nanosecond()),
+		uint32(entry.MTime.Unix()), uint32(entry.MTime.Nanosecond()),
+		entry.Dev, entry.Ino, mode, entry.UID, entry.GID, entry.Size,
+	}
+	binary.Write(buf, binary.BigEndian, fields)
+	buf.Write(hashBytes)
+
+	flags := uint16(min(len(entry.Path), flagNameMask))
+	flags |= uint16(entry.Stage) << flagStageShift
+	if entry.AssumeValid {
+		flags |= flagAssumeValid
+	}
+	binary.Write(buf, binary.BigEndian, flags)
+
+	buf.WriteString(entry.Path)
+
+	// Pad with 1-8 NUL bytes so entry length is a multiple of 8
+	entryLength := entryFixedLength + len(entry.Path)
+	padding := alignedEntryLength(len(entry.Path)) - entryLength
+	buf.Write(make([]byte, padding))
+
+	return nil
+}
+
+// writeExtension appends extension block: signature, big-endian size, data.
+func writeExtension(buf *bytes.Buffer, signature string, data []byte) {
+	buf.WriteString(signature)
+	binary.Write(buf, binary.BigEndian, uint32(len(data)))
+	buf.Write(data)
+}
+
+// alignedEntryLength returns total on-disk entry length including NUL padding.
+func alignedEntryLength(pathLength int) int {
+	return (entryFixedLength + pathLength + entryAlignment) &^ (entryAlignment - 1)
+}
+
+// Decode parses Git index version 2 binary data, verifying header, ordering and checksum.
+func Decode(data []byte) (*Index, error) {
+	if len(data) < headerLength+constants.HashByteLength {
+		return nil, fmt.Errorf("corrupt index: file too short (%d bytes)", len(data))
+	}
+
+	// Verify trailing checksum before trusting any content
+	contentEnd := len(data) - constants.HashByteLength
+	checksum := sha1.Sum(data[:contentEnd])
+	if !bytes.Equal(checksum[:], data[contentEnd:]) {
+		return nil, fmt.Errorf("corrupt index: checksum mismatch")
+	}
+
+	if string(data[:4]) != constants.IndexSignature {
+		return nil, fmt.Errorf("corrupt index: invalid signature %q", data[:4])
+	}
+
+	version := binary.BigEndian.Uint32(data[4:8])
+	if version != constants.IndexVersion {
+		return nil, fmt.Errorf("unsupported index version %d", version)
+	}
+
+	count := binary.BigEndian.Uint32(data[8:12])
+	// Bound preallocation by what the data can hold so a corrupt count cannot exhaust memory
+	idx := &Index{entries: make([]Entry, 0, min(int(count), contentEnd/entryFixedLength))}
+	offset := headerLength
+
+	for i := uint32(0); i < count; i++ {
+		entry, length, err := decodeEntry(data[offset:contentEnd])
+		if err != nil {
+			return nil, fmt.Errorf("corrupt index: entry %d: %w", i, err)
+		}
+
+		if n := len(idx.entries); n > 0 && compareEntries(idx.entries[n-1], entry) >= 0 {
+			return nil, fmt.Errorf("corrupt index: entries not sorted at %s", entry.Path)
+		}
+
+		idx.entries = append(idx.entries, entry)
+		offset += length
+	}
+
+	if err := idx.decodeExtensions(data[offset:contentEnd]); err != nil {
+		return nil, fmt.Errorf("corrupt index: %w", err)
+	}
+
+	return idx, nil
+}
+
+// decodeEntry parses one entry and returns it with its padded on-disk length.
+func decodeEntry(data []byte) (Entry, int, error) {
+	if len(data) < entryFixedLength {
+		return Entry{}, 0, fmt.Errorf("truncated entry")
+	}
+
+	var fields [10]uint32
+	for i := range fields {
+		fields[i] = binary.BigEndian.Uint32(data[i*4:])
+	}
+
+	mode, err := modeFromUint32(fields[6])
+	if err != nil {
+		return Entry{}, 0, err
+	}
+
+	flags := binary.BigEndian.Uint16(data[60:62])
+	if flags&flagExtended != 0 {
+		return Entry{}, 0, fmt.Errorf("extended flags require index version 3")
+	}
+
+	// Path length is stored directly unless it overflows the 12-bit field
+	nameLength := int(flags & flagNameMask)
+	if nameLength == flagNameMask {
+		nameLength = bytes.IndexByte(data[entryFixedLength:], constants.NullByte)
+		if nameLength == -1 {
+			return Entry{}, 0, fmt.Errorf("unterminated path")
+		}
+	}
+
+	length := alignedEntryLength(nameLength)
+	if len(data) < length {
+		return Entry{}, 0, fmt.Errorf("truncated path")
+	}
+
+	path := string(data[entryFixedLength : entryFixedLength+nameLength])
+	if path == "" {
+		return Entry{}, 0, fmt.Errorf("empty path")
+	}
+
+	// Padding must be NUL bytes only
+	for _, b := range data[entryFixedLength+nameLength : length] {
+		if b != constants.NullByte {
+			return Entry{}, 0, fmt.Errorf("invalid padding after %s", path)
+		}
+	}
+
+	return Entry{
+		CTime:       time.Unix(int64(fields[0]), int64(fields[1])),
+		MTime:       time.Unix(int64(fields[2]), int64(fields[3])),
+		Dev:         fields[4],
+		Ino:         fields[5],
+		Mode:        mode,
+		UID:         fields[7],
+		GID:         fields[8],
+		Size:        fields[9],
+		Hash:        hex.EncodeToString(data[40:60]),
+		Stage:       uint8((flags & flagStageMask) >> flagStageShift),
+		AssumeValid: flags&flagAssumeValid != 0,
+		Path:        path,
+	}, length, nil
+}
+
+// decodeExtensions parses known extensions and skips unknown optional ones.
+// Extensions whose signature starts with an uppercase letter are optional per Git's spec.
+func (idx *Index) decodeExtensions(data []byte) error {
+	for len(data) > 0 {
+		if len(data) < extensionHeader {
+			return fmt.Errorf("truncated extension header")
+		}
+
+		signature := string(data[:4])
+		size := int(binary.BigEndian.Uint32(data[4:8]))
+		if len(data)-extensionHeader < size {
+			return fmt.Errorf("truncated extension %s", signature)
+		}
+		content := data[extensionHeader : extensionHeader+size]
+
+		switch {
+		case signature == cacheTreeSignature:
+			cacheTree, err := decodeCacheTree(content)
+			if err != nil {
+				return err
+			}
+			idx.cacheTree = cacheTree
+		case signature[0] < 'A' || signature[0] > 'Z':
+			return fmt.Errorf("unsupported mandatory extension %s", signature)
+		}
+
+		data = data[extensionHeader+size:]
+	}
+
+	return nil
+}
@@ -0,0 +1,487 @@
+package index
+
+import (
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/lockfile"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// createTestEntry returns entry with random hash and fixed stat data.
+func createTestEntry(path string) Entry {
+	timestamp := time.Unix(1700000000, 123456789)
+	return Entry{
+		CTime: timestamp,
+		MTime: timestamp,
+		Dev:   42,
+		Ino:   4242,
+		Mode:  objects.ModeRegularFile,
+		UID:   1000,
+		GID:   1000,
+		Size:  128,
+		Hash:  testutils.RandomHash(),
+		Path:  path,
+	}
+}
+
+// encodeIndex encodes index and fails test on error.
+func encodeIndex(t *testing.T, idx *Index) []byte {
+	t.Helper()
+
+	data, err := idx.Encode()
+	if err != nil {
+		t.Fatalf("Failed to encode index: %v", err)
+	}
+	return data
+}
+
+// resealChecksum recomputes trailing SHA-1 after test mutates index bytes.
+func resealChecksum(data []byte) []byte {
+	contentEnd := len(data) - constants.HashByteLength
+	checksum := sha1.Sum(data[:contentEnd])
+	copy(data[contentEnd:], checksum[:])
+	return data
+}
+
+// assertDecodeError verifies decoding fails with message containing expected text.
+func assertDecodeError(t *testing.T, data []byte, expected string) {
+	t.Helper()
+
+	_, err := Decode(data)
+	if err == nil {
+		t.Fatalf("Expected decode error containing [%s], got nil", expected)
+	}
+	if !strings.Contains(err.Error(), expected) {
+		t.Fatalf("Expected error containing [%s], got [%s]", expected, err.Error())
+	}
+}
+
+// TestIndex_AddKeepsEntriesSorted verifies entries are ordered by path regardless of insertion order.
+func TestIndex_AddKeepsEntriesSorted(t *testing.T) {
+	idx := New()
+	for _, path := range []string{"src/main.go", "README.md", "src-old/a.go", "a.txt"} {
+		idx.Add(createTestEntry(path))
+	}
+
+	expected := []string{"README.md", "a.txt", "src-old/a.go", "src/main.go"}
+	if idx.Len() != len(expected) {
+		t.Fatalf("Expected %d entries, got %d", len(expected), idx.Len())
+	}
+	for i, entry := range idx.Entries() {
+		if entry.Path != expected[i] {
+			t.Errorf("Entry %d: expected path [%s], got [%s]", i, expected[i], entry.Path)
+		}
+	}
+}
+
+// TestIndex_AddReplacesExistingEntry verifies re-adding a path updates it in place.
+func TestIndex_AddReplacesExistingEntry(t *testing.T) {
+	idx := New()
+	idx.Add(createTestEntry("file.txt"))
+
+	updated := createTestEntry("file.txt")
+	idx.Add(updated)
+
+	if idx.Len() != 1 {
+		t.Fatalf("Expected 1 entry, got %d", idx.Len())
+	}
+
+	entry, found := idx.Entry("file.txt")
+	if !found {
+		t.Fatal("Expected entry to be found")
+	}
+	if entry.Hash != updated.Hash {
+		t.Errorf("Expected hash [%s], got [%s]", updated.Hash, entry.Hash)
+	}
+}
+
+// TestIndex_Remove verifies entries can be removed by path.
+func TestIndex_Remove(t *testing.T) {
+	idx := New()
+	idx.Add(createTestEntry("keep.txt"))
+	idx.Add(createTestEntry("drop.txt"))
+
+	if !idx.Remove("drop.txt") {
+		t.Fatal("Expected Remove to report removal")
+	}
+	if idx.Remove("missing.txt") {
+		t.Error("Expected Remove to report nothing removed for unknown path")
+	}
+	if _, found := idx.Entry("drop.txt"); found {
+		t.Error("Expected removed entry to be gone")
+	}
+	if idx.Len() != 1 {
+		t.Errorf("Expected 1 entry, got %d", idx.Len())
+	}
+}
+
+// TestIndex_Stages verifies all merge stages of a path are returned in stage order.
+func TestIndex_Stages(t *testing.T) {
+	idx := New()
+	idx.Add(createTestEntry("a.txt"))
+	for _, stage := range []uint8{3, 1} {
+		entry := createTestEntry("conflict.txt")
+		entry.Stage = stage
+		idx.Add(entry)
+	}
+
+	if stages := idx.Stages("conflict.txt"); len(stages) != 2 || stages[0].Stage != 1 || stages[1].Stage != 3 {
+		t.Errorf("Expected stages 1 and 3, got %+v", stages)
+	}
+	if stages := idx.Stages("a.txt"); len(stages) != 1 || stages[0].Stage != 0 {
+		t.Errorf("Expected single stage 0 entry, got %+v", stages)
+	}
+	if stages := idx.Stages("missing.txt"); len(stages) != 0 {
+		t.Errorf("Expected no entries, got %+v", stages)
+	}
+}
+
+// TestIndex_FileDirectoryConflict verifies paths clashing with a file or directory in the index.
+func TestIndex_FileDirectoryConflict(t *testing.T) {
+	idx := New()
+	idx.Add(createTestEntry("file"))
+	idx.Add(createTestEntry("dir/nested/a.txt"))
+	idx.Add(createTestEntry("dir-other"))
+
+	tests := []struct {
+		path     string
+		conflict string
+	}{
+		{path: "file/inside", conflict: "file"},
+		{path: "dir", conflict: "dir/nested/a.txt"},
+		{path: "dir/nested", conflict: "dir/nested/a.txt"},
+		{path: "dir/new.txt"},
+		{path: "fil"},
+		{path: "dir-other/x", conflict: "dir-other"},
+	}
+
+	for _, test := range tests {
+		conflict, found := idx.FileDirectoryConflict(test.path)
+		if conflict != test.conflict || found != (test.conflict != "") {
+			t.Errorf("%s: expected conflict [%s], got [%s] (found=%v)", test.path, test.conflict, conflict, found)
+		}
+	}
+}
+
+// TestIndex_EncodeDecodeRoundTrip verifies all entry fields survive serialization.
+func TestIndex_EncodeDecodeRoundTrip(t *testing.T) {
+	idx := New()
+	executable := createTestEntry("bin/run.sh")
+	executable.Mode = objects.ModeExecutable
+	conflicted := createTestEntry("conflict.txt")
+	conflicted.Stage = 2
+	assumed := createTestEntry("docs/guide.md")
+	assumed.AssumeValid = true
+	longPath := createTestEntry(strings.Repeat("d/", 2100) + "deep.txt")
+
+	for _, entry := range []Entry{executable, conflicted, assumed, longPath} {
+		idx.Add(entry)
+	}
+
+	decoded, err := Decode(encodeIndex(t, idx))
+	if err != nil {
+		t.Fatalf("Failed to decode index: %v", err)
+	}
+
+	if decoded.Len() != idx.Len() {
+		t.Fatalf("Expected %d entries, got %d", idx.Len(), decoded.Len())
+	}
+	for i, expected := range idx.Entries() {
+		actual := decoded.Entries()[i]
+		if !actual.MTime.Equal(expected.MTime) || !actual.CTime.Equal(expected.CTime) {
+			t.Errorf("Entry %s: timestamp mismatch", expected.Path)
+		}
+		actual.MTime, actual.CTime = expected.MTime, expected.CTime
+		if actual != expected {
+			t.Errorf("Entry mismatch:\nexpected %+v\ngot      %+v", expected, actual)
+		}
+	}
+}
+
+// TestIndex_EncodeLayout verifies header fields and 8-byte entry alignment.
+func TestIndex_EncodeLayout(t *testing.T) {
+	idx := New()
+	idx.Add(createTestEntry("a"))
+	idx.Add(createTestEntry("abcdefgh"))
+	data := encodeIndex(t, idx)
+
+	if string(data[:4]) != constants.IndexSignature {
+		t.Errorf("Expected signature %s, got %q", constants.IndexSignature, data[:4])
+	}
+	if version := binary.BigEndian.Uint32(data[4:8]); version != constants.IndexVersion {
+		t.Errorf("Expected version %d, got %d", constants.IndexVersion, version)
+	}
+	if count := binary.BigEndian.Uint32(data[8:12]); count != 2 {
+		t.Errorf("Expected 2 entries, got %d", count)
+	}
+
+	// "a" -> 62+1 padded to 64, "abcdefgh" -> 62+8 padded to 72
+	expectedLength := headerLength + 64 + 72 + constants.HashByteLength
+	if len(data) != expectedLength {
+		t.Errorf("Expected encoded length %d, got %d", expectedLength, len(data))
+	}
+}
+
+// TestIndex_EncodeInvalidHash verifies encoding rejects malformed hashes.
+func TestIndex_EncodeInvalidHash(t *testing.T) {
+	idx := New()
+	entry := createTestEntry("file.txt")
+	entry.Hash = "not-a-hash"
+	idx.Add(entry)
+
+	if _, err := idx.Encode(); err == nil {
+		t.Fatal("Expected error for invalid hash")
+	}
+}
+
+// TestDecode_ChecksumMismatch verifies corrupted content is detected.
+func TestDecode_ChecksumMismatch(t *testing.T) {
+	idx := New()
+	idx.Add(createTestEntry("file.txt"))
+	data := encodeIndex(t, idx)
+
+	data[headerLength+10] ^= 0xFF
+	assertDecodeError(t, data, "checksum mismatch")
+}
+
+// TestDecode_InvalidSignature verifies non-index files are rejected.
+func TestDecode_InvalidSignature(t *testing.T) {
+	data := encodeIndex(t, New())
+	copy(data, "XXXX")
+	assertDecodeError(t, resealChecksum(data), "invalid signature")
+}
+
+// TestDecode_UnsupportedVersion verifies other index versions are rejected.
+func TestDecode_UnsupportedVersion(t *testing.T) {
+	data := encodeIndex(t, New())
+	binary.BigEndian.PutUint32(data[4:8], 4)
+	assertDecodeError(t, resealChecksum(data), "unsupported index version 4")
+}
+
+// TestDecode_TooShort verifies truncated files are rejected.
+func TestDecode_TooShort(t *testing.T) {
+	assertDecodeError(t, []byte(constants.IndexSignature), "file too short")
+}
+
+// TestDecode_TruncatedEntries verifies entry count larger than content is detected.
+func TestDecode_TruncatedEntries(t *testing.T) {
+	idx := New()
+	idx.Add(createTestEntry("file.txt"))
+	data := encodeIndex(t, idx)
+
+	binary.BigEndian.PutUint32(data[8:12], 1000)
+	assertDecodeError(t, resealChecksum(data), "truncated")
+}
+
+// TestDecode_UnsortedEntries verifies out-of-order entries are rejected.
+func TestDecode_UnsortedEntries(t *testing.T) {
+	idx := New()
+	idx.Add(createTestEntry("aaaa"))
+	idx.Add(createTestEntry("bbbb"))
+	data := encodeIndex(t, idx)
+
+	// Both entries have same aligned length, so swap their paths in place
+	first := headerLength + entryFixedLength
+	second := headerLength + alignedEntryLength(4) + entryFixedLength
+	copy(data[first:], "bbbb")
+	copy(data[second:], "aaaa")
+	assertDecodeError(t, resealChecksum(data), "not sorted")
+}
+
+// TestDecode_Extensions verifies optional extensions are skipped and mandatory ones rejected.
+func TestDecode_Extensions(t *testing.T) {
+	idx := New()
+	idx.Add(createTestEntry("file.txt"))
+	base := encodeIndex(t, idx)
+	content := base[:len(base)-constants.HashByteLength]
+
+	withExtension := func(signature string) []byte {
+		data := append([]byte{}, content...)
+		data = append(data, signature...)
+		data = binary.BigEndian.AppendUint32(data, 3)
+		data = append(data, "xyz"...)
+		return resealChecksum(append(data, make([]byte, constants.HashByteLength)...))
+	}
+
+	decoded, err := Decode(withExtension("ZZZZ"))
+	if err != nil {
+		t.Fatalf("Expected optional extension to be skipped, got: %v", err)
+	}
+	if decoded.Len() != 1 {
+		t.Errorf("Expected 1 entry, got %d", decoded.Len())
+	}
+
+	assertDecodeError(t, withExtension("link"), "unsupported mandatory extension link")
+}
+
+// TestIndex_ReadWrite verifies index persists to .gogit/index.
+func TestIndex_ReadWrite(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	gitDir := repository.GitDir(repoPath)
+
+	idx := New()
+	idx.Add(createTestEntry("file.txt"))
+	if err := idx.Write(gitDir); err != nil {
+		t.Fatalf("Failed to write index: %v", err)
+	}
+	testutils.AssertFileExists(t, filepath.Join(repoPath, constants.Gogit, constants.Index))
+
+	loaded, err := Read(gitDir)
+	if err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+	if _, found := loaded.Entry("file.txt"); !found {
+		t.Error("Expected written entry to be read back")
+	}
+}
+
+// TestIndex_ReadMissing verifies missing index file yields empty index.
+func TestIndex_ReadMissing(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+
+	idx, err := Read(repository.GitDir(repoPath))
+	if err != nil {
+		t.Fatalf("Expected no error for missing index, got: %v", err)
+	}
+	if idx.Len() != 0 {
+		t.Errorf("Expected empty index, got %d entries", idx.Len())
+	}
+}
+
+// TestNewEntry verifies entry creation from file stat data.
+func TestNewEntry(t *testing.T) {
+	dir := t.TempDir()
+	content := []byte("hello\n")
+	regular := testutils.CreateTestFile(t, dir, "regular.txt", content)
+	script := testutils.CreateTestFile(t, dir, "script.sh", content)
+	if err := os.Chmod(script, 0755); err != nil {
+		t.Fatalf("Failed to chmod: %v", err)
+	}
+
+	tests := []struct {
+		path string
+		mode objects.FileMode
+	}{
+		{regular, objects.ModeRegularFile},
+		{script, objects.ModeExecutable},
+	}
+
+	for _, tt := range tests {
+		info, err := os.Lstat(tt.path)
+		if err != nil {
+			t.Fatalf("Failed to stat %s: %v", tt.path, err)
+		}
+
+		hash := testutils.RandomHash()
+		entry, err := NewEntry(filepath.Base(tt.path), hash, info)
+		if err != nil {
+			t.Fatalf("Failed to create entry: %v", err)
+		}
+
+		if entry.Mode != tt.mode {
+			t.Errorf("%s: expected mode %s, got %s", tt.path, tt.mode, entry.Mode)
+		}
+		if entry.Size != uint32(len(content)) {
+			t.Errorf("%s: expected size %d, got %d", tt.path, len(content), entry.Size)
+		}
+		if !entry.MTime.Equal(info.ModTime()) {
+			t.Errorf("%s: expected mtime %s, got %s", tt.path, info.ModTime(), entry.MTime)
+		}
+	}
+}
+
+// TestNewEntry_Directory verifies directories cannot be staged as entries.
+func TestNewEntry_Directory(t *testing.T) {
+	info, err := os.Stat(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to stat directory: %v", err)
+	}
+
+	if _, err := NewEntry("dir", testutils.RandomHash(), info); err == nil {
+		t.Fatal("Expected error when creating entry for directory")
+	}
+}
+
+// TestIndex_WriteLocked verifies writes fail fast while another process holds the lock.
+func TestIndex_WriteLocked(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	gitDir := repository.GitDir(repoPath)
+	original := New()
+	original.Add(createTestEntry("original.txt"))
+	if err := original.Write(gitDir); err != nil {
+		t.Fatalf("Failed to write index: %v", err)
+	}
+
+	lock, err := lockfile.Acquire(filepath.Join(repoPath, constants.Gogit, constants.Index))
+	if err != nil {
+		t.Fatalf("Failed to acquire lock: %v", err)
+	}
+	defer lock.Rollback()
+
+	updated := New()
+	updated.Add(createTestEntry("updated.txt"))
+	if err := updated.Write(gitDir); !errors.Is(err, lockfile.ErrLocked) {
+		t.Fatalf("Expected ErrLocked, got: %v", err)
+	}
+
+	loaded, err := Read(gitDir)
+	if err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+	if _, found := loaded.Entry("original.txt"); !found {
+		t.Error("Expected original index to be intact")
+	}
+}
+
+// TestUpdate verifies read-modify-write persists changes and releases the lock.
+func TestUpdate(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	gitDir := repository.GitDir(repoPath)
+	lockPath := filepath.Join(repoPath, constants.Gogit, constants.Index+constants.LockSuffix)
+
+	err := Update(gitDir, func(idx *Index) error {
+		testutils.AssertFileExists(t, lockPath)
+		idx.Add(createTestEntry("file.txt"))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	testutils.AssertFileNotExists(t, lockPath)
+
+	loaded, err := Read(gitDir)
+	if err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+	if _, found := loaded.Entry("file.txt"); !found {
+		t.Error("Expected updated entry to be persisted")
+	}
+}
+
+// TestUpdate_CallbackError verifies failed updates leave index untouched and release the lock.
+func TestUpdate_CallbackError(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	lockPath := filepath.Join(repoPath, constants.Gogit, constants.Index+constants.LockSuffix)
+	mockError := errors.New("mocked update failure")
+
+	err := Update(repository.GitDir(repoPath), func(idx *Index) error {
+		idx.Add(createTestEntry("file.txt"))
+		return mockError
+	})
+	if !errors.Is(err, mockError) {
+		t.Fatalf("Expected callback error, got: %v", err)
+	}
+
+	testutils.AssertFileNotExists(t, lockPath)
+	testutils.AssertFileNotExists(t, filepath.Join(repoPath, constants.Gogit, constants.Index))
+}
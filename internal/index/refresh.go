@@ -0,0 +1,170 @@
+package index
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/objects"
+)
+
+// MatchesStat reports whether stat data recorded in entry equals the file's current metadata.
+// Matching stat data means the file can be assumed unchanged without rehashing it.
+func (e *Entry) MatchesStat(info fs.FileInfo) bool {
+	current, err := NewEntry(e.Path, e.Hash, info)
+	if err != nil {
+		return false
+	}
+
+	return e.Mode == current.Mode &&
+		e.Size == current.Size &&
+		sameTimestamp(e.MTime, current.MTime) &&
+		sameTimestamp(e.CTime, current.CTime) &&
+		e.Ino == current.Ino &&
+		e.Dev == current.Dev &&
+		e.UID == current.UID &&
+		e.GID == current.GID
+}
+
+// IsRacy reports whether entry was modified too close to the index write to trust its stat data.
+// A file changed within the same timestamp granularity as the index write could have
+// new content with identical stat data, so it must be rehashed.
+func (idx *Index) IsRacy(entry *Entry) bool {
+	return !idx.timestamp.IsZero() && !entry.MTime.Before(idx.timestamp)
+}
+
+// IsModified compares entry against the worktree file at repoPath, rehashing only
+// when stat data differs or is racy. Missing files are reported as modified.
+func (idx *Index) IsModified(repoPath string, entry *Entry) (bool, error) {
+	info, err := os.Lstat(worktreePath(repoPath, entry.Path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to stat %s: %w", entry.Path, err)
+	}
+
+	if entry.MatchesStat(info) && !idx.IsRacy(entry) {
+		return false, nil
+	}
+
+	mode, err := modeFromFileInfo(info)
+	if err != nil || mode != entry.Mode {
+		return true, nil
+	}
+
+	hash, err := hashWorktreeFile(repoPath, entry.Path, info)
+	if err != nil {
+		return false, err
+	}
+
+	return hash != entry.Hash, nil
+}
+
+// StatWorktree returns the mode of entry's worktree file, or "" when no file is there, and
+// whether the file may differ from entry. As in Git's plumbing diffs, differing stat data
+// counts as a change without rehashing; only racy entries have their content compared.
+func (idx *Index) StatWorktree(repoPath string, entry *Entry) (objects.FileMode, bool, error) {
+	info, err := os.Lstat(worktreePath(repoPath, entry.Path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", true, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to stat %s: %w", entry.Path, err)
+	}
+
+	mode, err := modeFromFileInfo(info)
+	if err != nil {
+		// A directory or special file in place of the entry leaves no file for it
+		return "", true, nil
+	}
+	if entry.Stage != 0 || !entry.MatchesStat(info) {
+		return mode, true, nil
+	}
+	if !idx.IsRacy(entry) {
+		return mode, false, nil
+	}
+
+	hash, err := hashWorktreeFile(repoPath, entry.Path, info)
+	if err != nil {
+		return "", false, err
+	}
+	return mode, hash != entry.Hash, nil
+}
+
+// Refresh resyncs stat data of entries whose content is unchanged in the worktree.
+// Returns paths whose content or mode differs from the index and therefore need to be re-added.
+// Cancelling ctx aborts the scan and returns its error.
+func (idx *Index) Refresh(ctx context.Context, repoPath string) ([]string, error) {
+	var needsUpdate []string
+
+	for i := range idx.entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		entry := &idx.entries[i]
+		if entry.Stage != 0 {
+			needsUpdate = append(needsUpdate, entry.Path)
+			continue
+		}
+
+		modified, err := idx.IsModified(repoPath, entry)
+		if err != nil {
+			return nil, err
+		}
+		if modified {
+			needsUpdate = append(needsUpdate, entry.Path)
+			continue
+		}
+
+		info, err := os.Lstat(worktreePath(repoPath, entry.Path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %w", entry.Path, err)
+		}
+
+		if !entry.MatchesStat(info) {
+			refreshed, err := NewEntry(entry.Path, entry.Hash, info)
+			if err != nil {
+				return nil, err
+			}
+			refreshed.AssumeValid = entry.AssumeValid
+			*entry = *refreshed
+		}
+	}
+
+	return needsUpdate, nil
+}
+
+// hashWorktreeFile computes blob hash of worktree file, using link target for symlinks.
+func hashWorktreeFile(repoPath, path string, info fs.FileInfo) (string, error) {
+	fullPath := worktreePath(repoPath, path)
+
+	if info.Mode()&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(fullPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to read symlink %s: %w", path, err)
+		}
+		return objects.NewBlob([]byte(target)).Hash(), nil
+	}
+
+	blob, err := objects.NewBlobFromFile(fullPath)
+	if err != nil {
+		return "", err
+	}
+	return blob.Hash(), nil
+}
+
+// worktreePath converts slash-separated index path into filesystem path under repoPath.
+func worktreePath(repoPath, path string) string {
+	return filepath.Join(repoPath, filepath.FromSlash(path))
+}
+
+// sameTimestamp compares timestamps at the precision stored in the index (32-bit seconds, nanoseconds).
+func sameTimestamp(a, b time.Time) bool {
+	return uint32(a.Unix()) == uint32(b.Unix()) && a.Nanosecond() == b.Nanosecond()
+}
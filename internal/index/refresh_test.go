@@ -0,0 +1,210 @@
+package index
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// stageFile creates worktree file, stages it with current stat data, and returns its entry.
+func stageFile(t *testing.T, repoPath string, idx *Index, path string, content []byte) *Entry {
+	t.Helper()
+
+	fullPath := testutils.CreateTestFile(t, repoPath, path, content)
+	info, err := os.Lstat(fullPath)
+	if err != nil {
+		t.Fatalf("Failed to stat %s: %v", path, err)
+	}
+
+	entry, err := NewEntry(path, objects.NewBlob(content).Hash(), info)
+	if err != nil {
+		t.Fatalf("Failed to create entry: %v", err)
+	}
+	idx.Add(*entry)
+
+	staged, _ := idx.Entry(path)
+	return staged
+}
+
+// setMTime changes file modification time, failing test on error.
+func setMTime(t *testing.T, path string, mtime time.Time) {
+	t.Helper()
+
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatalf("Failed to change times of %s: %v", path, err)
+	}
+}
+
+// TestEntry_MatchesStat verifies stat comparison detects metadata changes.
+func TestEntry_MatchesStat(t *testing.T) {
+	repoPath := t.TempDir()
+	idx := New()
+	entry := stageFile(t, repoPath, idx, "file.txt", []byte("content"))
+	fullPath := filepath.Join(repoPath, "file.txt")
+
+	info, _ := os.Lstat(fullPath)
+	if !entry.MatchesStat(info) {
+		t.Fatal("Expected freshly staged entry to match stat data")
+	}
+
+	setMTime(t, fullPath, time.Now().Add(-time.Hour))
+	info, _ = os.Lstat(fullPath)
+	if entry.MatchesStat(info) {
+		t.Error("Expected mtime change to break stat match")
+	}
+}
+
+// TestIndex_IsModified verifies content changes are detected and unchanged files skip rehashing.
+func TestIndex_IsModified(t *testing.T) {
+	repoPath := t.TempDir()
+	idx := New()
+	past := time.Now().Add(-time.Hour)
+
+	unchanged := stageFile(t, repoPath, idx, "unchanged.txt", []byte("same"))
+	touched := stageFile(t, repoPath, idx, "touched.txt", []byte("same"))
+	edited := stageFile(t, repoPath, idx, "edited.txt", []byte("before"))
+	deleted := stageFile(t, repoPath, idx, "deleted.txt", []byte("gone"))
+	idx.timestamp = time.Now().Add(time.Hour)
+
+	setMTime(t, filepath.Join(repoPath, "touched.txt"), past)
+	testutils.CreateTestFile(t, repoPath, "edited.txt", []byte("after!"))
+	os.Remove(filepath.Join(repoPath, "deleted.txt"))
+
+	tests := []struct {
+		entry    *Entry
+		modified bool
+	}{
+		{unchanged, false},
+		{touched, false},
+		{edited, true},
+		{deleted, true},
+	}
+
+	for _, tt := range tests {
+		modified, err := idx.IsModified(repoPath, tt.entry)
+		if err != nil {
+			t.Fatalf("IsModified failed for %s: %v", tt.entry.Path, err)
+		}
+		if modified != tt.modified {
+			t.Errorf("%s: expected modified=%v, got %v", tt.entry.Path, tt.modified, modified)
+		}
+	}
+}
+
+// TestIndex_IsModified_RacyEntry verifies entries written as racily clean are rehashed.
+func TestIndex_IsModified_RacyEntry(t *testing.T) {
+	repoPath := t.TempDir()
+	idx := New()
+	original := objects.NewBlob([]byte("aaaa")).Hash()
+
+	// Stat data matches the edited file while the hash is of the original content,
+	// as happens when a same-size edit lands within the index write timestamp
+	fullPath := testutils.CreateTestFile(t, repoPath, "racy.txt", []byte("bbbb"))
+	info, _ := os.Lstat(fullPath)
+	entry, err := NewEntry("racy.txt", original, info)
+	if err != nil {
+		t.Fatalf("Failed to create entry: %v", err)
+	}
+
+	idx.timestamp = entry.MTime.Add(time.Hour)
+	if modified, _ := idx.IsModified(repoPath, entry); modified {
+		t.Fatal("Expected non-racy entry with matching stat data to be trusted")
+	}
+
+	idx.timestamp = entry.MTime
+	modified, err := idx.IsModified(repoPath, entry)
+	if err != nil {
+		t.Fatalf("IsModified failed: %v", err)
+	}
+	if !modified {
+		t.Error("Expected racy entry with changed content to be reported as modified")
+	}
+}
+
+// TestIndex_StatWorktree verifies touched files count as changed without rehashing, while
+// missing files and directories report no worktree mode.
+func TestIndex_StatWorktree(t *testing.T) {
+	repoPath := t.TempDir()
+	idx := New()
+
+	unchanged := stageFile(t, repoPath, idx, "unchanged.txt", []byte("same"))
+	touched := stageFile(t, repoPath, idx, "touched.txt", []byte("same"))
+	deleted := stageFile(t, repoPath, idx, "deleted.txt", []byte("gone"))
+	replaced := stageFile(t, repoPath, idx, "replaced", []byte("file"))
+	idx.timestamp = time.Now().Add(time.Hour)
+
+	setMTime(t, filepath.Join(repoPath, "touched.txt"), time.Now().Add(-time.Hour))
+	os.Remove(filepath.Join(repoPath, "deleted.txt"))
+	os.Remove(filepath.Join(repoPath, "replaced"))
+	if err := os.Mkdir(filepath.Join(repoPath, "replaced"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	tests := []struct {
+		entry   *Entry
+		mode    objects.FileMode
+		changed bool
+	}{
+		{unchanged, objects.ModeRegularFile, false},
+		{touched, objects.ModeRegularFile, true},
+		{deleted, "", true},
+		{replaced, "", true},
+	}
+
+	for _, tt := range tests {
+		mode, changed, err := idx.StatWorktree(repoPath, tt.entry)
+		if err != nil {
+			t.Fatalf("StatWorktree failed for %s: %v", tt.entry.Path, err)
+		}
+		if mode != tt.mode || changed != tt.changed {
+			t.Errorf("%s: expected (%q, %v), got (%q, %v)", tt.entry.Path, tt.mode, tt.changed, mode, changed)
+		}
+	}
+}
+
+// TestIndex_Refresh verifies stat data is resynced for unchanged files and changed files are reported.
+func TestIndex_Refresh(t *testing.T) {
+	repoPath := t.TempDir()
+	idx := New()
+	stageFile(t, repoPath, idx, "touched.txt", []byte("same"))
+	stageFile(t, repoPath, idx, "edited.txt", []byte("before"))
+	idx.timestamp = time.Now().Add(time.Hour)
+
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	setMTime(t, filepath.Join(repoPath, "touched.txt"), past)
+	testutils.CreateTestFile(t, repoPath, "edited.txt", []byte("after"))
+
+	needsUpdate, err := idx.Refresh(context.Background(), repoPath)
+	if err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+
+	if len(needsUpdate) != 1 || needsUpdate[0] != "edited.txt" {
+		t.Fatalf("Expected only edited.txt to need update, got %v", needsUpdate)
+	}
+
+	touched, _ := idx.Entry("touched.txt")
+	if !touched.MTime.Equal(past) {
+		t.Errorf("Expected refreshed mtime %s, got %s", past, touched.MTime)
+	}
+}
+
+// TestIndex_Refresh_Cancelled verifies a cancelled context aborts the worktree scan.
+func TestIndex_Refresh_Cancelled(t *testing.T) {
+	repoPath := t.TempDir()
+	idx := New()
+	stageFile(t, repoPath, idx, "file.txt", []byte("content"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := idx.Refresh(ctx, repoPath); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
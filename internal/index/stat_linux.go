@@ -0,0 +1,23 @@
+//go:build linux
+
+package index
+
+import (
+	"io/fs"
+	"syscall"
+	"time"
+)
+
+// fillStatData copies inode metadata from the platform stat structure into entry.
+func fillStatData(entry *Entry, info fs.FileInfo) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return
+	}
+
+	entry.CTime = time.Unix(int64(stat.Ctim.Sec), int64(stat.Ctim.Nsec))
+	entry.Dev = uint32(stat.Dev)
+	entry.Ino = uint32(stat.Ino)
+	entry.UID = stat.Uid
+	entry.GID = stat.Gid
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+
+package index
+
+import "io/fs"
+
+// fillStatData is a no-op on platforms without inode metadata.
+// Entries keep modification time and size, which is enough for change detection.
+func fillStatData(entry *Entry, info fs.FileInfo) {}
@@ -0,0 +1,105 @@
+package lockfile
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+)
+
+// ErrLocked is returned when another process already holds the lock.
+var ErrLocked = errors.New("lock already held by another process")
+
+// LockFile guards a file against concurrent writers using a sibling "<name>.lock" file.
+// New content is written to the lock file and atomically renamed over the target on Commit,
+// so a crash at any point leaves the original file intact.
+type LockFile struct {
+	path     string   // Target file path
+	lockPath string   // Path of the .lock file
+	file     *os.File // Open handle to the lock file, nil once released
+}
+
+// Acquire creates the lock file for path exclusively.
+// Fails fast with ErrLocked if the lock file already exists.
+func Acquire(path string) (*LockFile, error) {
+	lockPath := path + constants.LockSuffix
+
+	file, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE|os.O_EXCL, constants.FilePerms)
+	if errors.Is(err, fs.ErrExist) {
+		return nil, fmt.Errorf("unable to create %s: %w; if no other gogit process is running, remove the file manually", lockPath, ErrLocked)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lock file %s: %w", lockPath, err)
+	}
+
+	return &LockFile{
+		path:     path,
+		lockPath: lockPath,
+		file:     file,
+	}, nil
+}
+
+// Path returns the target file path guarded by the lock.
+func (l *LockFile) Path() string {
+	return l.path
+}
+
+// Write appends data to the lock file.
+func (l *LockFile) Write(data []byte) (int, error) {
+	if l.file == nil {
+		return 0, fmt.Errorf("lock file %s already released", l.lockPath)
+	}
+	return l.file.Write(data)
+}
+
+// Commit flushes the lock file to disk and renames it over the target file.
+func (l *LockFile) Commit() error {
+	if l.file == nil {
+		return fmt.Errorf("lock file %s already released", l.lockPath)
+	}
+
+	file := l.file
+	l.file = nil
+
+	if err := file.Sync(); err != nil {
+		file.Close()
+		l.remove()
+		return fmt.Errorf("failed to sync lock file %s: %w", l.lockPath, err)
+	}
+
+	if err := file.Close(); err != nil {
+		l.remove()
+		return fmt.Errorf("failed to close lock file %s: %w", l.lockPath, err)
+	}
+
+	if err := os.Rename(l.lockPath, l.path); err != nil {
+		l.remove()
+		return fmt.Errorf("failed to rename %s to %s: %w", l.lockPath, l.path, err)
+	}
+
+	return nil
+}
+
+// Rollback discards the lock file leaving the target untouched.
+// Safe to call after Commit, in which case it does nothing.
+func (l *LockFile) Rollback() {
+	if l.file == nil {
+		return
+	}
+
+	l.file.Close()
+	l.file = nil
+	l.remove()
+}
+
+// remove deletes the lock file, logging failures since callers are already handling an error.
+func (l *LockFile) remove() {
+	if err := os.Remove(l.lockPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		slog.Warn("Failed to remove lock file",
+			"path", l.lockPath,
+			"error", err)
+	}
+}
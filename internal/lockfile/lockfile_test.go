@@ -0,0 +1,106 @@
+package lockfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// assertFileContent verifies file at path holds expected content.
+func assertFileContent(t *testing.T, path, expected string) {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	if string(content) != expected {
+		t.Errorf("Expected content [%s], got [%s]", expected, content)
+	}
+}
+
+// TestLockFile_Commit verifies committed content replaces target and lock is removed.
+func TestLockFile_Commit(t *testing.T) {
+	dir := t.TempDir()
+	target := testutils.CreateTestFile(t, dir, "index", []byte("old"))
+
+	lock, err := Acquire(target)
+	if err != nil {
+		t.Fatalf("Failed to acquire lock: %v", err)
+	}
+	testutils.AssertFileExists(t, target+constants.LockSuffix)
+
+	if _, err := lock.Write([]byte("new")); err != nil {
+		t.Fatalf("Failed to write lock file: %v", err)
+	}
+
+	// Target must keep old content until commit
+	assertFileContent(t, target, "old")
+
+	if err := lock.Commit(); err != nil {
+		t.Fatalf("Failed to commit lock file: %v", err)
+	}
+
+	assertFileContent(t, target, "new")
+	testutils.AssertFileNotExists(t, target+constants.LockSuffix)
+}
+
+// TestLockFile_Rollback verifies rollback discards content and keeps target intact.
+func TestLockFile_Rollback(t *testing.T) {
+	dir := t.TempDir()
+	target := testutils.CreateTestFile(t, dir, "index", []byte("old"))
+
+	lock, err := Acquire(target)
+	if err != nil {
+		t.Fatalf("Failed to acquire lock: %v", err)
+	}
+	lock.Write([]byte("new"))
+	lock.Rollback()
+
+	assertFileContent(t, target, "old")
+	testutils.AssertFileNotExists(t, target+constants.LockSuffix)
+
+	// Rollback after release is a no-op
+	lock.Rollback()
+	if err := lock.Commit(); err == nil {
+		t.Error("Expected commit after rollback to fail")
+	}
+}
+
+// TestAcquire_AlreadyLocked verifies a second writer fails fast with ErrLocked.
+func TestAcquire_AlreadyLocked(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "index")
+
+	lock, err := Acquire(target)
+	if err != nil {
+		t.Fatalf("Failed to acquire lock: %v", err)
+	}
+	defer lock.Rollback()
+
+	_, err = Acquire(target)
+	if !errors.Is(err, ErrLocked) {
+		t.Fatalf("Expected ErrLocked, got: %v", err)
+	}
+}
+
+// TestAcquire_StaleLockAfterCrash verifies a leftover lock blocks writers until removed.
+func TestAcquire_StaleLockAfterCrash(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "index")
+	testutils.CreateTestFile(t, dir, "index"+constants.LockSuffix, []byte("partial"))
+
+	if _, err := Acquire(target); !errors.Is(err, ErrLocked) {
+		t.Fatalf("Expected ErrLocked for stale lock, got: %v", err)
+	}
+
+	os.Remove(target + constants.LockSuffix)
+	lock, err := Acquire(target)
+	if err != nil {
+		t.Fatalf("Expected lock after stale file removal, got: %v", err)
+	}
+	lock.Rollback()
+}
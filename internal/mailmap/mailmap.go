@@ -0,0 +1,170 @@
+// Package mailmap canonicalizes author identities using a .mailmap file.
+package mailmap
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+)
+
+// identity is a name and email pair; empty fields are left unchanged when mapping.
+type identity struct {
+	name  string
+	email string
+}
+
+// mapping holds replacements for one commit email.
+type mapping struct {
+	any    *identity           // Applies to every name used with the email
+	byName map[string]identity // Applies only when the commit name also matches, keyed by lowercased name
+}
+
+// Mailmap maps commit identities to canonical ones.
+// The zero value maps nothing.
+type Mailmap struct {
+	byEmail map[string]*mapping // Keyed by lowercased commit email
+}
+
+// Load reads .mailmap from the repository worktree root.
+// A missing file yields an empty mailmap.
+func Load(repoPath string) (*Mailmap, error) {
+	file, err := os.Open(filepath.Join(repoPath, constants.MailmapFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Mailmap{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", constants.MailmapFile, err)
+	}
+	defer file.Close()
+
+	return Parse(file)
+}
+
+// Parse reads mailmap entries, one per line, in any of Git's forms:
+//
+//	Proper Name <commit@email>
+//	<proper@email> <commit@email>
+//	Proper Name <proper@email> <commit@email>
+//	Proper Name <proper@email> Commit Name <commit@email>
+//
+// Blank lines and lines starting with '#' are ignored, as is text after the last email.
+func Parse(reader io.Reader) (*Mailmap, error) {
+	mailmap := &Mailmap{}
+	scanner := bufio.NewScanner(reader)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := mailmap.addLine(line); err != nil {
+			return nil, fmt.Errorf("invalid mailmap line %d: %w", lineNumber, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read mailmap: %w", err)
+	}
+	return mailmap, nil
+}
+
+// addLine parses one entry and records it.
+func (m *Mailmap) addLine(line string) error {
+	properName, properEmail, rest, ok := cutIdentity(line)
+	if !ok {
+		return fmt.Errorf("missing email in %q", line)
+	}
+
+	commitName, commitEmail, _, ok := cutIdentity(rest)
+	if !ok {
+		// Single email: it is the commit email and only the name is replaced
+		m.add(identity{name: properName}, "", properEmail)
+		return nil
+	}
+
+	m.add(identity{name: properName, email: properEmail}, commitName, commitEmail)
+	return nil
+}
+
+// cutIdentity splits "Name <email>" from the start of text, returning what follows it.
+func cutIdentity(text string) (name, email, rest string, ok bool) {
+	open := strings.IndexByte(text, '<')
+	if open == -1 {
+		return "", "", "", false
+	}
+	closing := strings.IndexByte(text[open:], '>')
+	if closing == -1 {
+		return "", "", "", false
+	}
+	closing += open
+
+	return strings.TrimSpace(text[:open]), strings.TrimSpace(text[open+1 : closing]), text[closing+1:], true
+}
+
+// add records replacement for commits using commitEmail and, when set, commitName.
+// Later entries override earlier ones field by field, as in Git.
+func (m *Mailmap) add(replacement identity, commitName, commitEmail string) {
+	if m.byEmail == nil {
+		m.byEmail = make(map[string]*mapping)
+	}
+
+	key := strings.ToLower(commitEmail)
+	entry, exists := m.byEmail[key]
+	if !exists {
+		entry = &mapping{byName: make(map[string]identity)}
+		m.byEmail[key] = entry
+	}
+
+	if commitName == "" {
+		if entry.any == nil {
+			entry.any = &identity{}
+		}
+		merge(entry.any, replacement)
+		return
+	}
+
+	nameKey := strings.ToLower(commitName)
+	existing := entry.byName[nameKey]
+	merge(&existing, replacement)
+	entry.byName[nameKey] = existing
+}
+
+// merge overwrites fields of target with the non-empty fields of source.
+func merge(target *identity, source identity) {
+	if source.name != "" {
+		target.name = source.name
+	}
+	if source.email != "" {
+		target.email = source.email
+	}
+}
+
+// Map returns canonical name and email for a commit identity.
+// Identities without a matching entry are returned unchanged.
+func (m *Mailmap) Map(name, email string) (string, string) {
+	entry, ok := m.byEmail[strings.ToLower(email)]
+	if !ok {
+		return name, email
+	}
+
+	replacement, ok := entry.byName[strings.ToLower(name)]
+	if !ok {
+		if entry.any == nil {
+			return name, email
+		}
+		replacement = *entry.any
+	}
+
+	if replacement.name != "" {
+		name = replacement.name
+	}
+	if replacement.email != "" {
+		email = replacement.email
+	}
+	return name, email
+}
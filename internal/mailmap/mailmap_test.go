@@ -0,0 +1,75 @@
+package mailmap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+const testMailmap = `# Canonical identities
+Ada Lovelace <ada@example.com>
+<charles@example.com> <babbage@old.example.com>
+Grace Hopper <grace@example.com> <ghopper@navy.example.com>
+Alan Turing <alan@example.com> Turing <shared@example.com>
+Joan Clarke <joan@example.com> Clarke <shared@example.com>
+`
+
+// TestMailmap_Map verifies each entry form maps matching identities and leaves others alone.
+func TestMailmap_Map(t *testing.T) {
+	mailmap, err := Parse(strings.NewReader(testMailmap))
+	if err != nil {
+		t.Fatalf("Failed to parse mailmap: %v", err)
+	}
+
+	tests := []struct {
+		name, email         string
+		wantName, wantEmail string
+	}{
+		{"ada", "ADA@example.com", "Ada Lovelace", "ADA@example.com"},
+		{"Charles", "babbage@old.example.com", "Charles", "charles@example.com"},
+		{"G. Hopper", "ghopper@navy.example.com", "Grace Hopper", "grace@example.com"},
+		{"turing", "shared@example.com", "Alan Turing", "alan@example.com"},
+		{"Clarke", "shared@example.com", "Joan Clarke", "joan@example.com"},
+		{"Someone Else", "shared@example.com", "Someone Else", "shared@example.com"},
+		{"Unmapped", "nobody@example.com", "Unmapped", "nobody@example.com"},
+	}
+
+	for _, tt := range tests {
+		name, email := mailmap.Map(tt.name, tt.email)
+		if name != tt.wantName || email != tt.wantEmail {
+			t.Errorf("Map(%q, %q) = (%q, %q), expected (%q, %q)", tt.name, tt.email, name, email, tt.wantName, tt.wantEmail)
+		}
+	}
+}
+
+// TestParse_InvalidLine verifies lines without an email are rejected with their line number.
+func TestParse_InvalidLine(t *testing.T) {
+	_, err := Parse(strings.NewReader("Ada <ada@example.com>\nno email here\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("Expected error for line 2, got %v", err)
+	}
+}
+
+// TestLoad verifies mailmap is read from worktree root and a missing file maps nothing.
+func TestLoad(t *testing.T) {
+	repoPath := t.TempDir()
+
+	empty, err := Load(repoPath)
+	if err != nil {
+		t.Fatalf("Failed to load missing mailmap: %v", err)
+	}
+	if name, _ := empty.Map("ada", "ada@example.com"); name != "ada" {
+		t.Errorf("Expected empty mailmap to leave name unchanged, got %q", name)
+	}
+
+	testutils.CreateTestFile(t, repoPath, constants.MailmapFile, []byte(testMailmap))
+	mailmap, err := Load(repoPath)
+	if err != nil {
+		t.Fatalf("Failed to load mailmap: %v", err)
+	}
+	if name, _ := mailmap.Map("ada", "ada@example.com"); name != "Ada Lovelace" {
+		t.Errorf("Expected mapped name, got %q", name)
+	}
+}
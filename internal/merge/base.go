@@ -0,0 +1,104 @@
+package merge
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/KostasZigo/gogit/internal/objects"
+)
+
+// Bases returns the best common ancestors of commits a and b: common ancestors that are not
+// ancestors of another common ancestor. Newest commits come first. Unrelated histories have none.
+func Bases(store *objects.ObjectStore, a, b string) ([]string, error) {
+	ancestorsOfA, err := Ancestors(store, a)
+	if err != nil {
+		return nil, err
+	}
+
+	// Walk back from b, stopping at the first common commits on every path
+	var candidates []string
+	seen := map[string]bool{b: true}
+	queue := []string{b}
+	for len(queue) > 0 {
+		hash := queue[0]
+		queue = queue[1:]
+		if ancestorsOfA[hash] {
+			candidates = append(candidates, hash)
+			continue
+		}
+
+		commit, err := store.ReadCommit(hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read commit %s: %w", hash, err)
+		}
+		for _, parent := range commit.Parents() {
+			if !seen[parent] {
+				seen[parent] = true
+				queue = append(queue, parent)
+			}
+		}
+	}
+
+	// A candidate reachable from another candidate is not a best common ancestor
+	var bases []string
+	for _, candidate := range candidates {
+		redundant := false
+		for _, other := range candidates {
+			if other == candidate {
+				continue
+			}
+			reachable, err := Ancestors(store, other)
+			if err != nil {
+				return nil, err
+			}
+			if reachable[candidate] {
+				redundant = true
+				break
+			}
+		}
+		if !redundant {
+			bases = append(bases, candidate)
+		}
+	}
+	return SortByCommitterDate(store, bases)
+}
+
+// Ancestors returns the set of commits reachable from hash through every parent, including itself.
+func Ancestors(store *objects.ObjectStore, hash string) (map[string]bool, error) {
+	reachable := map[string]bool{hash: true}
+	stack := []string{hash}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		commit, err := store.ReadCommit(current)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read commit %s: %w", current, err)
+		}
+		for _, parent := range commit.Parents() {
+			if !reachable[parent] {
+				reachable[parent] = true
+				stack = append(stack, parent)
+			}
+		}
+	}
+	return reachable, nil
+}
+
+// SortByCommitterDate orders commits newest first, breaking ties by hash.
+func SortByCommitterDate(store *objects.ObjectStore, hashes []string) ([]string, error) {
+	dates := make(map[string]int64, len(hashes))
+	for _, hash := range hashes {
+		commit, err := store.ReadCommit(hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read commit %s: %w", hash, err)
+		}
+		dates[hash] = commit.Committer().Timestamp.Unix()
+	}
+
+	slices.SortFunc(hashes, func(a, b string) int {
+		return cmp.Or(cmp.Compare(dates[b], dates[a]), cmp.Compare(a, b))
+	})
+	return hashes, nil
+}
@@ -0,0 +1,45 @@
+package merge
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// TestBases verifies the merge base of forked, linear and unrelated histories.
+func TestBases(t *testing.T) {
+	store := objects.NewObjectStore(repository.GitDir(testutils.SetupTestRepoWithInit(t)))
+
+	root := storeCommit(t, store, "", 0, map[string]string{"f": "0\n"})
+	fork := storeCommit(t, store, root, 1, map[string]string{"f": "1\n"})
+	left := storeCommit(t, store, fork, 2, map[string]string{"f": "left\n"})
+	right := storeCommit(t, store, fork, 3, map[string]string{"f": "right\n"})
+	rightTip := storeCommit(t, store, right, 4, map[string]string{"f": "right tip\n"})
+	unrelated := storeCommit(t, store, "", 5, map[string]string{"g": "g\n"})
+
+	tests := []struct {
+		name     string
+		a, b     string
+		expected []string
+	}{
+		{name: "forked branches", a: left, b: rightTip, expected: []string{fork}},
+		{name: "ancestor", a: rightTip, b: fork, expected: []string{fork}},
+		{name: "same commit", a: left, b: left, expected: []string{left}},
+		{name: "unrelated", a: left, b: unrelated, expected: nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bases, err := Bases(store, test.a, test.b)
+			if err != nil {
+				t.Fatalf("Bases failed: %v", err)
+			}
+			if !slices.Equal(bases, test.expected) {
+				t.Errorf("Expected bases %v, got %v", test.expected, bases)
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+package merge
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/diff"
+)
+
+// markerLength is the width of conflict markers such as "<<<<<<<".
+const markerLength = 7
+
+// conflictGap is the most unchanged lines allowed between two conflicts before they are shown as one.
+const conflictGap = 3
+
+// Labels name the sides of a merge in conflict markers and messages.
+type Labels struct {
+	Base   string
+	Ours   string
+	Theirs string
+}
+
+// ConflictStyle selects how conflicts are written, as merge.conflictStyle configures.
+type ConflictStyle int
+
+const (
+	StyleMerge        ConflictStyle = iota // Ours and theirs sections only
+	StyleDiff3                             // The base version between ours and theirs
+	StyleZealousDiff3                      // As diff3, with lines both sides share at the edges moved out
+)
+
+// ParseConflictStyle parses a merge.conflictStyle value; empty selects StyleMerge.
+func ParseConflictStyle(name string) (ConflictStyle, error) {
+	switch strings.ToLower(name) {
+	case "", "merge":
+		return StyleMerge, nil
+	case "diff3":
+		return StyleDiff3, nil
+	case "zdiff3":
+		return StyleZealousDiff3, nil
+	default:
+		return StyleMerge, fmt.Errorf("unknown conflict style '%s'", name)
+	}
+}
+
+// regionSource says which version a merged region takes its lines from.
+type regionSource int
+
+const (
+	sourceConflict regionSource = iota
+	sourceOurs
+	sourceTheirs
+	sourceBoth
+)
+
+// region is a range of lines changed by either side, given as a start and count in each version.
+type region struct {
+	source                   regionSource
+	baseStart, baseCount     int
+	oursStart, oursCount     int
+	theirsStart, theirsCount int
+}
+
+// MergeFile merges the changes from base to ours and from base to theirs, line by line.
+// Regions changed on both sides in different ways are written between conflict markers
+// labelled with labels.Ours and labels.Theirs. In StyleMerge, lines both sides share inside
+// such a region are kept outside the markers, and conflicts only a few lines apart are shown
+// as one. StyleDiff3 adds the base lines, labelled with labels.Base, and keeps each conflict
+// whole; StyleZealousDiff3 only moves shared lines at its edges out.
+// Reports whether any conflict was written.
+func MergeFile(base, ours, theirs []byte, labels Labels, style ConflictStyle) ([]byte, bool) {
+	baseLines, oursLines, theirsLines := diff.SplitLines(base), diff.SplitLines(ours), diff.SplitLines(theirs)
+
+	regions := mergeRegions(diff.Lines(baseLines, oursLines), diff.Lines(baseLines, theirsLines), len(baseLines), oursLines, theirsLines)
+	switch style {
+	case StyleMerge:
+		regions = refineConflicts(regions, oursLines, theirsLines)
+		regions = joinNearbyConflicts(regions)
+	case StyleZealousDiff3:
+		trimConflicts(regions, oursLines, theirsLines)
+	}
+
+	var result bytes.Buffer
+	conflicted := false
+	next := 0
+	for _, r := range regions {
+		writeLines(&result, oursLines[next:r.oursStart])
+		oursPart := oursLines[r.oursStart : r.oursStart+r.oursCount]
+		theirsPart := theirsLines[r.theirsStart : r.theirsStart+r.theirsCount]
+		switch r.source {
+		case sourceConflict:
+			conflicted = true
+			writeMarker(&result, '<', labels.Ours)
+			writeLines(&result, oursPart)
+			if style != StyleMerge {
+				writeMarker(&result, '|', labels.Base)
+				writeLines(&result, baseLines[r.baseStart:r.baseStart+r.baseCount])
+			}
+			writeMarker(&result, '=', "")
+			writeLines(&result, theirsPart)
+			writeMarker(&result, '>', labels.Theirs)
+		case sourceTheirs:
+			writeLines(&result, theirsPart)
+		default:
+			writeLines(&result, oursPart)
+		}
+		next = r.oursStart + r.oursCount
+	}
+	writeLines(&result, oursLines[next:])
+	return result.Bytes(), conflicted
+}
+
+// mergeRegions walks the changes each side made to base in order. A change that overlaps or
+// touches no change of the other side is taken from its side; overlapping changes form a
+// conflict spanning both, unless they are identical.
+func mergeRegions(oursHunks, theirsHunks []diff.Edit, baseLength int, ours, theirs []string) []region {
+	var regions []region
+	for len(oursHunks) > 0 && len(theirsHunks) > 0 {
+		o, t := oursHunks[0], theirsHunks[0]
+		switch {
+		case o.AStart+o.ACount < t.AStart:
+			regions = appendRegion(regions, region{
+				source:    sourceOurs,
+				baseStart: o.AStart, baseCount: o.ACount,
+				oursStart: o.BStart, oursCount: o.BCount,
+				theirsStart: t.BStart - t.AStart + o.AStart, theirsCount: o.ACount,
+			})
+			oursHunks = oursHunks[1:]
+			continue
+		case t.AStart+t.ACount < o.AStart:
+			regions = appendRegion(regions, region{
+				source:    sourceTheirs,
+				baseStart: t.AStart, baseCount: t.ACount,
+				oursStart: o.BStart - o.AStart + t.AStart, oursCount: t.ACount,
+				theirsStart: t.BStart, theirsCount: t.BCount,
+			})
+			theirsHunks = theirsHunks[1:]
+			continue
+		}
+
+		identical := o.AStart == t.AStart && o.ACount == t.ACount &&
+			slices.Equal(ours[o.BStart:o.BStart+o.BCount], theirs[t.BStart:t.BStart+t.BCount])
+		if !identical {
+			// Widen both sides so the conflict covers the same base lines on each
+			baseStart := min(o.AStart, t.AStart)
+			baseEnd := max(o.AStart+o.ACount, t.AStart+t.ACount)
+			oursStart := o.BStart - (o.AStart - baseStart)
+			theirsStart := t.BStart - (t.AStart - baseStart)
+			regions = appendRegion(regions, region{
+				source:    sourceConflict,
+				baseStart: baseStart, baseCount: baseEnd - baseStart,
+				oursStart: oursStart, oursCount: o.BStart + o.BCount + (baseEnd - o.AStart - o.ACount) - oursStart,
+				theirsStart: theirsStart, theirsCount: t.BStart + t.BCount + (baseEnd - t.AStart - t.ACount) - theirsStart,
+			})
+		}
+
+		oursEnd, theirsEnd := o.AStart+o.ACount, t.AStart+t.ACount
+		if oursEnd >= theirsEnd {
+			theirsHunks = theirsHunks[1:]
+		}
+		if theirsEnd >= oursEnd {
+			oursHunks = oursHunks[1:]
+		}
+	}
+
+	// Past the last change of one side, the other side's lines are offset by its total growth
+	for _, o := range oursHunks {
+		regions = appendRegion(regions, region{
+			source:    sourceOurs,
+			baseStart: o.AStart, baseCount: o.ACount,
+			oursStart: o.BStart, oursCount: o.BCount,
+			theirsStart: o.AStart + len(theirs) - baseLength, theirsCount: o.ACount,
+		})
+	}
+	for _, t := range theirsHunks {
+		regions = appendRegion(regions, region{
+			source:    sourceTheirs,
+			baseStart: t.AStart, baseCount: t.ACount,
+			oursStart: t.AStart + len(ours) - baseLength, oursCount: t.ACount,
+			theirsStart: t.BStart, theirsCount: t.BCount,
+		})
+	}
+	return regions
+}
+
+// appendRegion adds r to regions, folding it into the last region when the two touch on
+// either side. A region folded from both sides becomes a conflict.
+func appendRegion(regions []region, r region) []region {
+	if len(regions) == 0 {
+		return append(regions, r)
+	}
+	last := &regions[len(regions)-1]
+	if r.oursStart > last.oursStart+last.oursCount && r.theirsStart > last.theirsStart+last.theirsCount {
+		return append(regions, r)
+	}
+	if r.source != last.source {
+		last.source = sourceConflict
+	}
+	last.baseCount = r.baseStart + r.baseCount - last.baseStart
+	last.oursCount = r.oursStart + r.oursCount - last.oursStart
+	last.theirsCount = r.theirsStart + r.theirsCount - last.theirsStart
+	return regions
+}
+
+// refineConflicts diffs the two sides of each conflict against each other and narrows it to the
+// lines that really differ, splitting it where the sides agree. A conflict whose sides turn out
+// equal is resolved.
+func refineConflicts(regions []region, ours, theirs []string) []region {
+	var refined []region
+	for _, r := range regions {
+		if r.source != sourceConflict || r.oursCount == 0 || r.theirsCount == 0 {
+			refined = append(refined, r)
+			continue
+		}
+
+		hunks := diff.Lines(ours[r.oursStart:r.oursStart+r.oursCount], theirs[r.theirsStart:r.theirsStart+r.theirsCount])
+		if len(hunks) == 0 {
+			r.source = sourceBoth
+			refined = append(refined, r)
+			continue
+		}
+		for _, h := range hunks {
+			refined = append(refined, region{
+				source:    sourceConflict,
+				baseStart: r.baseStart, baseCount: r.baseCount,
+				oursStart: r.oursStart + h.AStart, oursCount: h.ACount,
+				theirsStart: r.theirsStart + h.BStart, theirsCount: h.BCount,
+			})
+		}
+	}
+	return refined
+}
+
+// trimConflicts moves lines both sides share at the start and end of each conflict out of it,
+// leaving its base lines whole.
+func trimConflicts(regions []region, ours, theirs []string) {
+	for i := range regions {
+		r := &regions[i]
+		if r.source != sourceConflict {
+			continue
+		}
+		for r.oursCount > 0 && r.theirsCount > 0 && ours[r.oursStart] == theirs[r.theirsStart] {
+			r.oursStart++
+			r.theirsStart++
+			r.oursCount--
+			r.theirsCount--
+		}
+		for r.oursCount > 0 && r.theirsCount > 0 &&
+			ours[r.oursStart+r.oursCount-1] == theirs[r.theirsStart+r.theirsCount-1] {
+			r.oursCount--
+			r.theirsCount--
+		}
+	}
+}
+
+// joinNearbyConflicts merges conflicts separated by at most conflictGap lines, including the
+// lines between them, since one conflict reads more easily than several close together.
+func joinNearbyConflicts(regions []region) []region {
+	var joined []region
+	for _, r := range regions {
+		if len(joined) > 0 {
+			last := &joined[len(joined)-1]
+			if last.source == sourceConflict && r.source == sourceConflict && r.oursStart-(last.oursStart+last.oursCount) <= conflictGap {
+				last.oursCount = r.oursStart + r.oursCount - last.oursStart
+				last.theirsCount = r.theirsStart + r.theirsCount - last.theirsStart
+				continue
+			}
+		}
+		joined = append(joined, r)
+	}
+	return joined
+}
+
+// writeMarker writes a conflict marker line, starting a new line if the content before it lacks one.
+func writeMarker(result *bytes.Buffer, marker byte, label string) {
+	if result.Len() > 0 && !bytes.HasSuffix(result.Bytes(), []byte("\n")) {
+		result.WriteByte('\n')
+	}
+	result.WriteString(strings.Repeat(string(marker), markerLength))
+	if label != "" {
+		result.WriteString(" " + label)
+	}
+	result.WriteByte('\n')
+}
+
+// writeLines appends lines to result.
+func writeLines(result *bytes.Buffer, lines []string) {
+	for _, line := range lines {
+		result.WriteString(line)
+	}
+}
@@ -0,0 +1,140 @@
+package merge
+
+import (
+	"testing"
+)
+
+// TestMergeFile verifies clean merges, conflicts, and how conflicts are narrowed and joined.
+func TestMergeFile(t *testing.T) {
+	labels := Labels{Ours: "ours", Theirs: "theirs"}
+
+	tests := []struct {
+		name               string
+		base, ours, theirs string
+		expected           string
+		expectedConflicted bool
+	}{
+		{
+			name:     "changes on separate lines",
+			base:     "1\n2\n3\n4\n5\n",
+			ours:     "one\n2\n3\n4\n5\n",
+			theirs:   "1\n2\n3\n4\nfive\n",
+			expected: "one\n2\n3\n4\nfive\n",
+		},
+		{
+			name:     "same change on both sides",
+			base:     "1\n2\n3\n",
+			ours:     "1\ntwo\n3\n",
+			theirs:   "1\ntwo\n3\n",
+			expected: "1\ntwo\n3\n",
+		},
+		{
+			name:     "only theirs changed",
+			base:     "1\n2\n",
+			ours:     "1\n2\n",
+			theirs:   "1\n2\n3\n",
+			expected: "1\n2\n3\n",
+		},
+		{
+			name:               "different changes to one line",
+			base:               "1\n2\n3\n",
+			ours:               "1\nours\n3\n",
+			theirs:             "1\ntheirs\n3\n",
+			expected:           "1\n<<<<<<< ours\nours\n=======\ntheirs\n>>>>>>> theirs\n3\n",
+			expectedConflicted: true,
+		},
+		{
+			name:               "lines both sides added alike stay outside markers",
+			base:               "1\n2\n",
+			ours:               "1\nsame\nours\n2\n",
+			theirs:             "1\nsame\ntheirs\n2\n",
+			expected:           "1\nsame\n<<<<<<< ours\nours\n=======\ntheirs\n>>>>>>> theirs\n2\n",
+			expectedConflicted: true,
+		},
+		{
+			name:               "close conflicts are joined",
+			base:               "1\n2\n3\n",
+			ours:               "a\n2\nc\n",
+			theirs:             "x\n2\nz\n",
+			expected:           "<<<<<<< ours\na\n2\nc\n=======\nx\n2\nz\n>>>>>>> theirs\n",
+			expectedConflicted: true,
+		},
+		{
+			name:               "distant conflicts stay apart",
+			base:               "1\n2\n3\n4\n5\n6\n",
+			ours:               "a\n2\n3\n4\n5\nf\n",
+			theirs:             "x\n2\n3\n4\n5\nz\n",
+			expected:           "<<<<<<< ours\na\n=======\nx\n>>>>>>> theirs\n2\n3\n4\n5\n<<<<<<< ours\nf\n=======\nz\n>>>>>>> theirs\n",
+			expectedConflicted: true,
+		},
+		{
+			name:               "missing final newline",
+			base:               "1\n",
+			ours:               "ours",
+			theirs:             "theirs",
+			expected:           "<<<<<<< ours\nours\n=======\ntheirs\n>>>>>>> theirs\n",
+			expectedConflicted: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			merged, conflicted := MergeFile([]byte(test.base), []byte(test.ours), []byte(test.theirs), labels, StyleMerge)
+			if string(merged) != test.expected {
+				t.Errorf("Expected merge:\n%s\ngot:\n%s", test.expected, merged)
+			}
+			if conflicted != test.expectedConflicted {
+				t.Errorf("Expected conflicted %v, got %v", test.expectedConflicted, conflicted)
+			}
+		})
+	}
+}
+
+// TestMergeFile_Styles verifies diff3 shows the base lines and zdiff3 moves shared edge lines out.
+func TestMergeFile_Styles(t *testing.T) {
+	labels := Labels{Base: "base", Ours: "ours", Theirs: "theirs"}
+	base := "1\n2\n3\n"
+	ours := "1\nsame\nours\n3\n"
+	theirs := "1\nsame\ntheirs\n3\n"
+
+	tests := []struct {
+		name     string
+		style    ConflictStyle
+		expected string
+	}{
+		{
+			name:     "diff3",
+			style:    StyleDiff3,
+			expected: "1\n<<<<<<< ours\nsame\nours\n||||||| base\n2\n=======\nsame\ntheirs\n>>>>>>> theirs\n3\n",
+		},
+		{
+			name:     "zdiff3",
+			style:    StyleZealousDiff3,
+			expected: "1\nsame\n<<<<<<< ours\nours\n||||||| base\n2\n=======\ntheirs\n>>>>>>> theirs\n3\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			merged, conflicted := MergeFile([]byte(base), []byte(ours), []byte(theirs), labels, test.style)
+			if !conflicted || string(merged) != test.expected {
+				t.Errorf("Expected conflicted merge:\n%s\ngot (conflicted %v):\n%s", test.expected, conflicted, merged)
+			}
+		})
+	}
+}
+
+// TestParseConflictStyle verifies merge.conflictStyle values, ignoring case.
+func TestParseConflictStyle(t *testing.T) {
+	tests := map[string]ConflictStyle{"": StyleMerge, "merge": StyleMerge, "diff3": StyleDiff3, "zDiff3": StyleZealousDiff3}
+	for name, expected := range tests {
+		style, err := ParseConflictStyle(name)
+		if err != nil || style != expected {
+			t.Errorf("Expected %q to parse as %v, got %v, %v", name, expected, style, err)
+		}
+	}
+
+	if _, err := ParseConflictStyle("fancy"); err == nil {
+		t.Error("Expected an error for an unknown style")
+	}
+}
@@ -0,0 +1,430 @@
+// Package merge performs three-way merges of files, trees and commits entirely in the
+// object database, without touching a worktree or index.
+package merge
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/diff"
+	"github.com/KostasZigo/gogit/internal/index"
+	"github.com/KostasZigo/gogit/internal/objects"
+)
+
+// Message kinds, as Git names them in machine-readable merge output.
+const (
+	KindAutoMerging   = "Auto-merging"
+	KindContents      = "CONFLICT (contents)"
+	KindBinary        = "CONFLICT (binary)"
+	KindModifyDelete  = "CONFLICT (modify/delete)"
+	KindFileDirectory = "CONFLICT (file/directory)"
+)
+
+// Labels used when the merge base is not a single commit.
+const (
+	emptyTreeLabel     = "empty tree"
+	mergedBasesLabel   = "merged common ancestors"
+	virtualOursLabel   = "Temporary merge branch 1"
+	virtualTheirsLabel = "Temporary merge branch 2"
+)
+
+const baseAbbrevLength = 7 // Hex digits of a single merge base shown as its label
+
+// Version is the file one side of a merge has at a path.
+type Version struct {
+	Mode objects.FileMode
+	Hash string
+}
+
+// Conflict is a path the merge could not resolve, with the version each side has.
+type Conflict struct {
+	Path   string
+	Base   *Version // nil when the path was added on both sides
+	Ours   *Version // nil when ours deleted the path
+	Theirs *Version // nil when theirs deleted the path
+}
+
+// Message reports what the merge did at one or more paths, such as an auto-merge or a conflict.
+type Message struct {
+	Paths []string
+	Kind  string
+	Text  string
+}
+
+// Result is the outcome of a tree merge.
+type Result struct {
+	Tree      string // Merged tree, with conflict markers in files that conflicted
+	Conflicts []Conflict
+	Messages  []Message
+}
+
+// Clean reports whether the merge resolved every path.
+func (r *Result) Clean() bool {
+	return len(r.Conflicts) == 0
+}
+
+// resolvedFile is a path's entry in the merged tree and the side it came from.
+type resolvedFile struct {
+	Version
+	side string
+}
+
+// treeMerger collects the merged entries, conflicts and messages of one tree merge.
+type treeMerger struct {
+	store     *objects.ObjectStore
+	labels    Labels
+	style     ConflictStyle
+	files     map[string]resolvedFile
+	conflicts map[string]*Conflict
+	messages  []Message
+}
+
+// Commits merges commits ours and theirs given bases, their best common ancestors as
+// returned by Bases. Several bases are first merged into a virtual base; without any,
+// the histories are unrelated and the empty tree is used. labels.Base is set accordingly.
+// style selects how conflicts in file content are written.
+func Commits(store *objects.ObjectStore, bases []string, ours, theirs string, labels Labels, style ConflictStyle) (*Result, error) {
+	baseTree, baseLabel, err := mergedBaseTree(store, bases, style)
+	if err != nil {
+		return nil, err
+	}
+	oursTree, err := commitTree(store, ours)
+	if err != nil {
+		return nil, err
+	}
+	theirsTree, err := commitTree(store, theirs)
+	if err != nil {
+		return nil, err
+	}
+
+	labels.Base = baseLabel
+	return Trees(store, baseTree, oursTree, theirsTree, labels, style)
+}
+
+// mergedBaseTree returns the tree to use as merge base and its label in conflict markers.
+func mergedBaseTree(store *objects.ObjectStore, bases []string, style ConflictStyle) (string, string, error) {
+	switch len(bases) {
+	case 0:
+		return constants.EmptyTreeHash, emptyTreeLabel, nil
+	case 1:
+		tree, err := commitTree(store, bases[0])
+		return tree, bases[0][:baseAbbrevLength], err
+	}
+
+	tree, err := commitTree(store, bases[0])
+	if err != nil {
+		return "", "", err
+	}
+	for _, next := range bases[1:] {
+		innerBases, err := Bases(store, bases[0], next)
+		if err != nil {
+			return "", "", err
+		}
+		innerTree, _, err := mergedBaseTree(store, innerBases, style)
+		if err != nil {
+			return "", "", err
+		}
+		nextTree, err := commitTree(store, next)
+		if err != nil {
+			return "", "", err
+		}
+
+		// Conflicts stay in the virtual base as markers, for the outer merge to compare against
+		result, err := Trees(store, innerTree, tree, nextTree, Labels{Ours: virtualOursLabel, Theirs: virtualTheirsLabel}, style)
+		if err != nil {
+			return "", "", err
+		}
+		tree = result.Tree
+	}
+	return tree, mergedBasesLabel, nil
+}
+
+// commitTree returns the tree hash of a commit.
+func commitTree(store *objects.ObjectStore, hash string) (string, error) {
+	commit, err := store.ReadCommit(hash)
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit %s: %w", hash, err)
+	}
+	return commit.TreeHash(), nil
+}
+
+// Trees merges the changes from base to ours and from base to theirs and stores the merged tree.
+// Paths changed on one side take that side's version, and files changed on both are merged
+// line by line. Paths that cannot be resolved are reported as conflicts; the merged tree then
+// holds ours' version, or the content with conflict markers written in style.
+func Trees(store *objects.ObjectStore, base, ours, theirs string, labels Labels, style ConflictStyle) (*Result, error) {
+	baseFiles, err := store.FlattenTree(base)
+	if err != nil {
+		return nil, err
+	}
+	oursFiles, err := store.FlattenTree(ours)
+	if err != nil {
+		return nil, err
+	}
+	theirsFiles, err := store.FlattenTree(theirs)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := slices.Collect(maps.Keys(baseFiles))
+	paths = slices.AppendSeq(paths, maps.Keys(oursFiles))
+	paths = slices.AppendSeq(paths, maps.Keys(theirsFiles))
+	slices.Sort(paths)
+	paths = slices.Compact(paths)
+
+	m := &treeMerger{
+		store:     store,
+		labels:    labels,
+		style:     style,
+		files:     make(map[string]resolvedFile),
+		conflicts: make(map[string]*Conflict),
+	}
+	for _, name := range paths {
+		err := m.mergePath(name, version(baseFiles, name), version(oursFiles, name), version(theirsFiles, name))
+		if err != nil {
+			return nil, err
+		}
+	}
+	m.moveFilesOutOfDirectories()
+
+	tree, err := m.writeTree()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Tree: tree, Messages: m.messages}
+	for _, name := range slices.Sorted(maps.Keys(m.conflicts)) {
+		result.Conflicts = append(result.Conflicts, *m.conflicts[name])
+	}
+	return result, nil
+}
+
+// version returns the version of path in files, or nil if absent.
+func version(files map[string]objects.TreeEntry, name string) *Version {
+	entry, ok := files[name]
+	if !ok {
+		return nil
+	}
+	return &Version{Mode: entry.Mode(), Hash: entry.Hash()}
+}
+
+// sameVersion reports whether a and b are both absent or have equal mode and content.
+func sameVersion(a, b *Version) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// isRegularFile reports whether mode is a file whose content can be merged line by line.
+func isRegularFile(mode objects.FileMode) bool {
+	return mode == objects.ModeRegularFile || mode == objects.ModeExecutable
+}
+
+// mergePath resolves one path from its base, ours and theirs versions.
+func (m *treeMerger) mergePath(name string, base, ours, theirs *Version) error {
+	switch {
+	case sameVersion(ours, theirs), sameVersion(base, theirs):
+		m.keep(name, ours, m.labels.Ours)
+	case sameVersion(base, ours):
+		m.keep(name, theirs, m.labels.Theirs)
+	case ours == nil:
+		m.modifyDelete(name, base, ours, theirs, m.labels.Ours, m.labels.Theirs)
+		m.keep(name, theirs, m.labels.Theirs)
+	case theirs == nil:
+		m.modifyDelete(name, base, ours, theirs, m.labels.Theirs, m.labels.Ours)
+		m.keep(name, ours, m.labels.Ours)
+	default:
+		return m.mergeContent(name, base, ours, theirs)
+	}
+	return nil
+}
+
+// keep records v as the merged version of name, unless the path ends up deleted.
+func (m *treeMerger) keep(name string, v *Version, side string) {
+	if v != nil {
+		m.files[name] = resolvedFile{Version: *v, side: side}
+	}
+}
+
+// modifyDelete reports a path deleted on one side and modified on the other.
+func (m *treeMerger) modifyDelete(name string, base, ours, theirs *Version, deletedIn, modifiedIn string) {
+	m.conflict(name, base, ours, theirs)
+	m.modifyDeleteMessage(name, deletedIn, modifiedIn)
+}
+
+// modifyDeleteMessage records the message for a modify/delete conflict at name.
+func (m *treeMerger) modifyDeleteMessage(name, deletedIn, modifiedIn string) {
+	m.message(KindModifyDelete, fmt.Sprintf("CONFLICT (modify/delete): %s deleted in %s and modified in %s.  Version %s of %s left in tree.",
+		name, deletedIn, modifiedIn, modifiedIn, name), name)
+}
+
+// mergeContent merges a path both sides changed, combining modes and merging file content.
+func (m *treeMerger) mergeContent(name string, base, ours, theirs *Version) error {
+	mode, conflicted := mergeModes(base, ours, theirs)
+	hash := ours.Hash
+
+	if !isRegularFile(ours.Mode) || !isRegularFile(theirs.Mode) {
+		// Symlinks and submodules cannot be merged; ours is kept
+		mode, conflicted = ours.Mode, true
+	} else if ours.Hash != theirs.Hash {
+		merged, contentConflicted, err := m.mergeBlobs(name, base, ours, theirs)
+		if err != nil {
+			return err
+		}
+		hash, conflicted = merged, conflicted || contentConflicted
+	}
+
+	m.keep(name, &Version{Mode: mode, Hash: hash}, m.labels.Ours)
+	if !conflicted {
+		return nil
+	}
+
+	m.conflict(name, base, ours, theirs)
+	reason := "content"
+	if base == nil {
+		reason = "add/add"
+	}
+	m.message(KindContents, fmt.Sprintf("CONFLICT (%s): Merge conflict in %s", reason, name), name)
+	return nil
+}
+
+// mergeModes combines file modes, reporting a conflict when both sides changed it differently.
+func mergeModes(base, ours, theirs *Version) (objects.FileMode, bool) {
+	switch {
+	case ours.Mode == theirs.Mode:
+		return ours.Mode, false
+	case base != nil && base.Mode == ours.Mode:
+		return theirs.Mode, false
+	case base != nil && base.Mode == theirs.Mode:
+		return ours.Mode, false
+	default:
+		return ours.Mode, true
+	}
+}
+
+// mergeBlobs merges the content of the three versions and stores the result.
+// Binary content is not merged; ours is kept and reported as conflicting.
+func (m *treeMerger) mergeBlobs(name string, base, ours, theirs *Version) (string, bool, error) {
+	var baseContent []byte
+	if base != nil && isRegularFile(base.Mode) {
+		blob, err := m.store.ReadBlob(base.Hash)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		baseContent = blob.Content()
+	}
+	oursBlob, err := m.store.ReadBlob(ours.Hash)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	theirsBlob, err := m.store.ReadBlob(theirs.Hash)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	if diff.IsBinary(baseContent) || diff.IsBinary(oursBlob.Content()) || diff.IsBinary(theirsBlob.Content()) {
+		m.message(KindBinary, fmt.Sprintf("warning: Cannot merge binary files: %s (%s vs. %s)", name, m.labels.Ours, m.labels.Theirs), name)
+		m.message(KindAutoMerging, "Auto-merging "+name, name)
+		return ours.Hash, true, nil
+	}
+
+	m.message(KindAutoMerging, "Auto-merging "+name, name)
+	merged, conflicted := MergeFile(baseContent, oursBlob.Content(), theirsBlob.Content(), m.labels, m.style)
+	blob := objects.NewBlob(merged)
+	if err := m.store.Store(blob); err != nil {
+		return "", false, fmt.Errorf("failed to store merged %s: %w", name, err)
+	}
+	return blob.Hash(), conflicted, nil
+}
+
+// moveFilesOutOfDirectories renames merged files whose path became a directory on the other
+// side to "<path>~<side>", reporting each as a file/directory conflict.
+func (m *treeMerger) moveFilesOutOfDirectories() {
+	directories := make(map[string]bool)
+	for name := range m.files {
+		for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+			directories[dir] = true
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(m.files)) {
+		if !directories[name] {
+			continue
+		}
+		file := m.files[name]
+		moved := m.uniquePath(name + "~" + file.side)
+		delete(m.files, name)
+		m.files[moved] = file
+
+		// Only a modify/delete conflict can leave a file where the other side has a directory
+		conflict, modifyDelete := m.conflicts[name]
+		if modifyDelete {
+			delete(m.conflicts, name)
+			m.messages = slices.DeleteFunc(m.messages, func(message Message) bool {
+				return message.Kind == KindModifyDelete && message.Paths[0] == name
+			})
+		} else {
+			conflict = &Conflict{}
+			if file.side == m.labels.Ours {
+				conflict.Ours = &file.Version
+			} else {
+				conflict.Theirs = &file.Version
+			}
+		}
+		conflict.Path = moved
+		m.conflicts[moved] = conflict
+
+		m.message(KindFileDirectory, fmt.Sprintf("CONFLICT (file/directory): directory in the way of %s from %s; moving it to %s instead.",
+			name, file.side, moved), moved, name)
+		if modifyDelete {
+			deletedIn := m.labels.Theirs
+			if file.side == m.labels.Theirs {
+				deletedIn = m.labels.Ours
+			}
+			m.modifyDeleteMessage(moved, deletedIn, file.side)
+		}
+	}
+}
+
+// uniquePath returns name, or name with a numeric suffix if a merged file already uses it.
+func (m *treeMerger) uniquePath(name string) string {
+	candidate := name
+	for i := 0; ; i++ {
+		if _, taken := m.files[candidate]; !taken {
+			return candidate
+		}
+		candidate = name + "_" + strconv.Itoa(i)
+	}
+}
+
+// conflict records the versions of a path that could not be resolved.
+func (m *treeMerger) conflict(name string, base, ours, theirs *Version) {
+	m.conflicts[name] = &Conflict{Path: name, Base: base, Ours: ours, Theirs: theirs}
+}
+
+// message records an informational message about paths.
+func (m *treeMerger) message(kind, text string, paths ...string) {
+	m.messages = append(m.messages, Message{Paths: paths, Kind: kind, Text: text})
+}
+
+// writeTree stores the merged entries as trees and returns the root tree hash.
+func (m *treeMerger) writeTree() (string, error) {
+	if len(m.files) == 0 {
+		return constants.EmptyTreeHash, nil
+	}
+
+	idx := index.New()
+	for name, file := range m.files {
+		idx.Add(index.Entry{Path: name, Mode: file.Mode, Hash: file.Hash})
+	}
+	tree, err := idx.WriteTree(m.store)
+	if err != nil {
+		return "", fmt.Errorf("failed to write merged tree: %w", err)
+	}
+	return tree, nil
+}
@@ -0,0 +1,189 @@
+package merge
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/index"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// storeTree stores files, mapping paths to content, as blobs and trees and returns the root tree hash.
+func storeTree(t *testing.T, store *objects.ObjectStore, files map[string]string) string {
+	t.Helper()
+
+	idx := index.New()
+	for name, content := range files {
+		blob := objects.NewBlob([]byte(content))
+		if err := store.Store(blob); err != nil {
+			t.Fatalf("Failed to store blob: %v", err)
+		}
+		idx.Add(index.Entry{Path: name, Mode: objects.ModeRegularFile, Hash: blob.Hash(), Size: uint32(len(content))})
+	}
+	tree, err := idx.WriteTree(store)
+	if err != nil {
+		t.Fatalf("Failed to write tree: %v", err)
+	}
+	return tree
+}
+
+// storeCommit stores a commit of files on top of parent, or a root commit when parent is empty.
+// day orders commits by committer date.
+func storeCommit(t *testing.T, store *objects.ObjectStore, parent string, day int, files map[string]string) string {
+	t.Helper()
+
+	author := objects.Author{Name: "M", Email: "m@example.com", Timestamp: time.Unix(1700000000, 0).AddDate(0, 0, day).UTC()}
+	tree := storeTree(t, store, files)
+	var commit *objects.Commit
+	var err error
+	if parent == "" {
+		commit, err = objects.NewInitialCommit(tree, "commit", author)
+	} else {
+		commit, err = objects.NewCommit(tree, parent, "commit", author)
+	}
+	if err != nil {
+		t.Fatalf("Failed to create commit: %v", err)
+	}
+	if err := store.Store(commit); err != nil {
+		t.Fatalf("Failed to store commit: %v", err)
+	}
+	return commit.Hash()
+}
+
+// readMergedFile returns the content of name in the merged tree.
+func readMergedFile(t *testing.T, store *objects.ObjectStore, tree, name string) string {
+	t.Helper()
+
+	files, err := store.FlattenTree(tree)
+	if err != nil {
+		t.Fatalf("Failed to read merged tree: %v", err)
+	}
+	entry, ok := files[name]
+	if !ok {
+		t.Fatalf("Expected %s in merged tree, got %v", name, files)
+	}
+	blob, err := store.ReadBlob(entry.Hash())
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", name, err)
+	}
+	return string(blob.Content())
+}
+
+// TestTrees_Clean verifies changes to different files and to different lines are combined.
+func TestTrees_Clean(t *testing.T) {
+	store := objects.NewObjectStore(repository.GitDir(testutils.SetupTestRepoWithInit(t)))
+	labels := Labels{Base: "base", Ours: "ours", Theirs: "theirs"}
+
+	base := storeTree(t, store, map[string]string{"a.txt": "1\n2\n3\n4\n5\n", "b.txt": "b\n", "gone.txt": "x\n"})
+	ours := storeTree(t, store, map[string]string{"a.txt": "one\n2\n3\n4\n5\n", "b.txt": "b\n", "gone.txt": "x\n", "new.txt": "new\n"})
+	theirs := storeTree(t, store, map[string]string{"a.txt": "1\n2\n3\n4\nfive\n", "b.txt": "bee\n"})
+
+	result, err := Trees(store, base, ours, theirs, labels, StyleMerge)
+	if err != nil {
+		t.Fatalf("Trees failed: %v", err)
+	}
+	if !result.Clean() {
+		t.Fatalf("Expected a clean merge, got conflicts %v", result.Conflicts)
+	}
+
+	expected := storeTree(t, store, map[string]string{"a.txt": "one\n2\n3\n4\nfive\n", "b.txt": "bee\n", "new.txt": "new\n"})
+	if result.Tree != expected {
+		t.Errorf("Expected tree %s, got %s", expected, result.Tree)
+	}
+	if len(result.Messages) != 1 || result.Messages[0].Text != "Auto-merging a.txt" {
+		t.Errorf("Expected a single auto-merge message, got %v", result.Messages)
+	}
+}
+
+// TestTrees_Conflicts verifies content, add/add, modify/delete and file/directory conflicts.
+func TestTrees_Conflicts(t *testing.T) {
+	store := objects.NewObjectStore(repository.GitDir(testutils.SetupTestRepoWithInit(t)))
+	labels := Labels{Base: "base", Ours: "ours", Theirs: "theirs"}
+
+	base := storeTree(t, store, map[string]string{"content.txt": "1\n2\n3\n", "deleted.txt": "d\n", "path": "file\n"})
+	ours := storeTree(t, store, map[string]string{"content.txt": "1\nours\n3\n", "added.txt": "ours\n", "path": "changed\n"})
+	theirs := storeTree(t, store, map[string]string{"content.txt": "1\ntheirs\n3\n", "added.txt": "theirs\n", "deleted.txt": "changed\n", "path/inner": "inner\n"})
+
+	result, err := Trees(store, base, ours, theirs, labels, StyleMerge)
+	if err != nil {
+		t.Fatalf("Trees failed: %v", err)
+	}
+
+	var conflicted []string
+	for _, conflict := range result.Conflicts {
+		conflicted = append(conflicted, conflict.Path)
+	}
+	expectedConflicted := []string{"added.txt", "content.txt", "deleted.txt", "path~ours"}
+	if !slices.Equal(conflicted, expectedConflicted) {
+		t.Errorf("Expected conflicted paths %v, got %v", expectedConflicted, conflicted)
+	}
+
+	expectedContent := "1\n<<<<<<< ours\nours\n=======\ntheirs\n>>>>>>> theirs\n3\n"
+	if content := readMergedFile(t, store, result.Tree, "content.txt"); content != expectedContent {
+		t.Errorf("Expected content.txt:\n%s\ngot:\n%s", expectedContent, content)
+	}
+	if content := readMergedFile(t, store, result.Tree, "deleted.txt"); content != "changed\n" {
+		t.Errorf("Expected the modified deleted.txt to be kept, got %q", content)
+	}
+	if content := readMergedFile(t, store, result.Tree, "path~ours"); content != "changed\n" {
+		t.Errorf("Expected path moved to path~ours, got %q", content)
+	}
+	if content := readMergedFile(t, store, result.Tree, "path/inner"); content != "inner\n" {
+		t.Errorf("Expected path/inner from theirs, got %q", content)
+	}
+
+	var kinds []string
+	for _, message := range result.Messages {
+		kinds = append(kinds, message.Kind)
+	}
+	expectedKinds := []string{
+		KindAutoMerging, KindContents, KindAutoMerging, KindContents, KindModifyDelete, KindFileDirectory, KindModifyDelete,
+	}
+	if !slices.Equal(kinds, expectedKinds) {
+		t.Errorf("Expected message kinds %v, got %v", expectedKinds, kinds)
+	}
+}
+
+// TestTrees_Binary verifies binary files changed on both sides keep ours and conflict.
+func TestTrees_Binary(t *testing.T) {
+	store := objects.NewObjectStore(repository.GitDir(testutils.SetupTestRepoWithInit(t)))
+
+	base := storeTree(t, store, map[string]string{"data.bin": "\x00base"})
+	ours := storeTree(t, store, map[string]string{"data.bin": "\x00ours"})
+	theirs := storeTree(t, store, map[string]string{"data.bin": "\x00theirs"})
+
+	result, err := Trees(store, base, ours, theirs, Labels{Ours: "ours", Theirs: "theirs"}, StyleMerge)
+	if err != nil {
+		t.Fatalf("Trees failed: %v", err)
+	}
+	if result.Clean() {
+		t.Fatal("Expected a binary conflict")
+	}
+	if content := readMergedFile(t, store, result.Tree, "data.bin"); content != "\x00ours" {
+		t.Errorf("Expected ours kept, got %q", content)
+	}
+	if result.Messages[0].Kind != KindBinary {
+		t.Errorf("Expected a binary warning first, got %v", result.Messages)
+	}
+}
+
+// TestCommits_UnrelatedHistories verifies commits without a base merge against the empty tree.
+func TestCommits_UnrelatedHistories(t *testing.T) {
+	store := objects.NewObjectStore(repository.GitDir(testutils.SetupTestRepoWithInit(t)))
+
+	ours := storeCommit(t, store, "", 0, map[string]string{"ours.txt": "ours\n", "both.txt": "same\n"})
+	theirs := storeCommit(t, store, "", 0, map[string]string{"theirs.txt": "theirs\n", "both.txt": "same\n"})
+
+	result, err := Commits(store, nil, ours, theirs, Labels{Ours: "ours", Theirs: "theirs"}, StyleMerge)
+	if err != nil {
+		t.Fatalf("Commits failed: %v", err)
+	}
+
+	expected := storeTree(t, store, map[string]string{"ours.txt": "ours\n", "theirs.txt": "theirs\n", "both.txt": "same\n"})
+	if !result.Clean() || result.Tree != expected {
+		t.Errorf("Expected clean tree %s, got %s with conflicts %v", expected, result.Tree, result.Conflicts)
+	}
+}
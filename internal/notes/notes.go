@@ -0,0 +1,163 @@
+// Package notes attaches text to objects without changing them, using Git's notes layout:
+// a notes ref points at a commit whose tree maps annotated object hashes to note blobs.
+package notes
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/refs"
+)
+
+// ErrNoteNotFound is returned when an object has no note.
+var ErrNoteNotFound = errors.New("no note found")
+
+// Note links an annotated object to the blob holding its note.
+type Note struct {
+	Object string
+	Blob   string
+}
+
+// Notes is the set of notes recorded under one notes ref.
+type Notes struct {
+	gitDir  string
+	store   *objects.ObjectStore
+	ref     string
+	commit  string            // Current notes commit, empty before the first note
+	entries map[string]string // Annotated object hash to note blob hash
+}
+
+// Load reads notes recorded under ref, returning an empty set if the ref does not exist yet.
+func Load(gitDir string, store *objects.ObjectStore, ref string) (*Notes, error) {
+	notes := &Notes{
+		gitDir:  gitDir,
+		store:   store,
+		ref:     ref,
+		entries: make(map[string]string),
+	}
+
+	hash, err := refs.Resolve(gitDir, ref)
+	if errors.Is(err, refs.ErrRefNotFound) {
+		return notes, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := store.ReadCommit(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notes commit: %w", err)
+	}
+	notes.commit = hash
+
+	if err := notes.readTree(commit.TreeHash(), ""); err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+// readTree collects note entries, joining fan-out directory names (ab/cdef...) into full hashes.
+func (n *Notes) readTree(treeHash, prefix string) error {
+	if treeHash == constants.EmptyTreeHash {
+		return nil
+	}
+
+	tree, err := n.store.ReadTree(treeHash)
+	if err != nil {
+		return fmt.Errorf("failed to read notes tree: %w", err)
+	}
+
+	for _, entry := range tree.Entries() {
+		name := prefix + entry.Name()
+		if entry.IsDirectory() {
+			if err := n.readTree(entry.Hash(), name); err != nil {
+				return err
+			}
+			continue
+		}
+		if len(name) == constants.HashStringLength {
+			n.entries[name] = entry.Hash()
+		}
+	}
+	return nil
+}
+
+// Get returns hash of the note blob attached to object.
+func (n *Notes) Get(object string) (string, bool) {
+	blob, ok := n.entries[object]
+	return blob, ok
+}
+
+// Read returns note content attached to object.
+func (n *Notes) Read(object string) ([]byte, error) {
+	blobHash, ok := n.entries[object]
+	if !ok {
+		return nil, fmt.Errorf("%w for object %s", ErrNoteNotFound, object)
+	}
+
+	blob, err := n.store.ReadBlob(blobHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read note for object %s: %w", object, err)
+	}
+	return blob.Content(), nil
+}
+
+// List returns all notes sorted by annotated object hash.
+func (n *Notes) List() []Note {
+	objectHashes := slices.Sorted(maps.Keys(n.entries))
+	notes := make([]Note, len(objectHashes))
+	for i, object := range objectHashes {
+		notes[i] = Note{Object: object, Blob: n.entries[object]}
+	}
+	return notes
+}
+
+// Set attaches content to object, replacing any existing note, and records a new notes commit.
+func (n *Notes) Set(object string, content []byte, author objects.Author, message string) error {
+	blob := objects.NewBlob(content)
+	if err := n.store.Store(blob); err != nil {
+		return fmt.Errorf("failed to store note: %w", err)
+	}
+
+	n.entries[object] = blob.Hash()
+	return n.commitEntries(author, message)
+}
+
+// commitEntries writes entries as a flat tree, commits it on top of the current notes
+// commit and moves the notes ref.
+func (n *Notes) commitEntries(author objects.Author, message string) error {
+	treeEntries := make([]objects.TreeEntry, 0, len(n.entries))
+	for _, note := range n.List() {
+		entry, err := objects.NewTreeEntry(objects.ModeRegularFile, note.Object, note.Blob)
+		if err != nil {
+			return err
+		}
+		treeEntries = append(treeEntries, *entry)
+	}
+
+	tree, err := objects.NewTree(treeEntries)
+	if err != nil {
+		return err
+	}
+	if err := n.store.Store(tree); err != nil {
+		return fmt.Errorf("failed to store notes tree: %w", err)
+	}
+
+	commit, err := objects.NewCommit(tree.Hash(), n.commit, message, author)
+	if err != nil {
+		return err
+	}
+	if err := n.store.Store(commit); err != nil {
+		return fmt.Errorf("failed to store notes commit: %w", err)
+	}
+
+	if err := refs.Update(n.gitDir, n.ref, commit.Hash()); err != nil {
+		return err
+	}
+	n.commit = commit.Hash()
+	return nil
+}
@@ -0,0 +1,128 @@
+package notes
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/refs"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+var testAuthor = objects.Author{Name: "N", Email: "n@example.com", Timestamp: time.Unix(1700000000, 0).UTC()}
+
+// TestNotes_SetAndRead verifies notes persist across loads and each change records a commit.
+func TestNotes_SetAndRead(t *testing.T) {
+	gitDir := repository.GitDir(testutils.SetupTestRepoWithInit(t))
+	store := objects.NewObjectStore(gitDir)
+	first, second := testutils.RandomHash(), testutils.RandomHash()
+
+	notes, err := Load(gitDir, store, constants.NotesRef)
+	if err != nil {
+		t.Fatalf("Failed to load notes: %v", err)
+	}
+	if len(notes.List()) != 0 {
+		t.Fatalf("Expected no notes before the notes ref exists, got %v", notes.List())
+	}
+
+	if err := notes.Set(first, []byte("first\n"), testAuthor, "add first"); err != nil {
+		t.Fatalf("Failed to set note: %v", err)
+	}
+	firstCommit, _ := refs.Resolve(gitDir, constants.NotesRef)
+	if err := notes.Set(second, []byte("second\n"), testAuthor, "add second"); err != nil {
+		t.Fatalf("Failed to set note: %v", err)
+	}
+
+	reloaded, err := Load(gitDir, store, constants.NotesRef)
+	if err != nil {
+		t.Fatalf("Failed to reload notes: %v", err)
+	}
+	content, err := reloaded.Read(first)
+	if err != nil || string(content) != "first\n" {
+		t.Errorf("Expected note %q, got %q, %v", "first\n", content, err)
+	}
+
+	expected := []Note{
+		{Object: first, Blob: objects.NewBlob([]byte("first\n")).Hash()},
+		{Object: second, Blob: objects.NewBlob([]byte("second\n")).Hash()},
+	}
+	slices.SortFunc(expected, func(a, b Note) int { return strings.Compare(a.Object, b.Object) })
+	if !slices.Equal(reloaded.List(), expected) {
+		t.Errorf("Expected %v, got %v", expected, reloaded.List())
+	}
+
+	head, _ := refs.Resolve(gitDir, constants.NotesRef)
+	commit, err := store.ReadCommit(head)
+	if err != nil {
+		t.Fatalf("Failed to read notes commit: %v", err)
+	}
+	if commit.ParentHash() != firstCommit {
+		t.Errorf("Expected notes commit parent %s, got %s", firstCommit, commit.ParentHash())
+	}
+}
+
+// TestNotes_Read_Missing verifies objects without notes report ErrNoteNotFound.
+func TestNotes_Read_Missing(t *testing.T) {
+	gitDir := repository.GitDir(testutils.SetupTestRepoWithInit(t))
+	notes, err := Load(gitDir, objects.NewObjectStore(gitDir), constants.NotesRef)
+	if err != nil {
+		t.Fatalf("Failed to load notes: %v", err)
+	}
+
+	if _, err := notes.Read(testutils.RandomHash()); !errors.Is(err, ErrNoteNotFound) {
+		t.Errorf("Expected ErrNoteNotFound, got %v", err)
+	}
+}
+
+// TestLoad_FanoutTree verifies notes stored in Git's ab/cdef... fan-out layout are read.
+func TestLoad_FanoutTree(t *testing.T) {
+	gitDir := repository.GitDir(testutils.SetupTestRepoWithInit(t))
+	store := objects.NewObjectStore(gitDir)
+	object := testutils.RandomHash()
+	blob := objects.NewBlob([]byte("fanned out\n"))
+
+	mustStore := func(obj objects.Object) {
+		t.Helper()
+		if err := store.Store(obj); err != nil {
+			t.Fatalf("Failed to store object: %v", err)
+		}
+	}
+	mustTree := func(mode objects.FileMode, name, hash string) *objects.Tree {
+		t.Helper()
+		entry, err := objects.NewTreeEntry(mode, name, hash)
+		if err != nil {
+			t.Fatalf("Failed to create tree entry: %v", err)
+		}
+		tree, err := objects.NewTree([]objects.TreeEntry{*entry})
+		if err != nil {
+			t.Fatalf("Failed to create tree: %v", err)
+		}
+		mustStore(tree)
+		return tree
+	}
+
+	mustStore(blob)
+	inner := mustTree(objects.ModeRegularFile, object[2:], blob.Hash())
+	root := mustTree(objects.ModeDirectory, object[:2], inner.Hash())
+	commit, err := objects.NewInitialCommit(root.Hash(), "notes", testAuthor)
+	if err != nil {
+		t.Fatalf("Failed to create commit: %v", err)
+	}
+	mustStore(commit)
+	if err := refs.Update(gitDir, constants.NotesRef, commit.Hash()); err != nil {
+		t.Fatalf("Failed to update notes ref: %v", err)
+	}
+
+	notes, err := Load(gitDir, store, constants.NotesRef)
+	if err != nil {
+		t.Fatalf("Failed to load notes: %v", err)
+	}
+	if got, ok := notes.Get(object); !ok || got != blob.Hash() {
+		t.Errorf("Expected note blob %s, got %s (found=%v)", blob.Hash(), got, ok)
+	}
+}
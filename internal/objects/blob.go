@@ -29,6 +29,44 @@ func NewBlobFromFile(filepath string) (*Blob, error) {
 	return NewBlob(content), nil
 }
 
+// HashBlobFile computes blob hash of a file by streaming its content.
+// Unlike NewBlobFromFile, the file is never loaded into memory, so arbitrarily large files can be hashed.
+func HashBlobFile(filepath string) (string, error) {
+	file, info, err := openBlobFile(filepath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash, err := utils.ComputeHashFromReader(file, info.Size(), utils.BlobObjectType)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash file %s: %w", filepath, err)
+	}
+
+	return hash, nil
+}
+
+// openBlobFile opens a regular file for streaming and returns its stat data.
+func openBlobFile(filepath string) (*os.File, os.FileInfo, error) {
+	file, err := os.Open(filepath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read file %s: %w", filepath, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("failed to read file %s: %w", filepath, err)
+	}
+
+	if !info.Mode().IsRegular() {
+		file.Close()
+		return nil, nil, fmt.Errorf("failed to read file %s: not a regular file", filepath)
+	}
+
+	return file, info, nil
+}
+
 func (b *Blob) Hash() string {
 	return b.hash
 }
@@ -77,3 +77,32 @@ func TestBlob_DifferentContentDifferentHash(t *testing.T) {
 		t.Fatal("Different content should produce different hashes")
 	}
 }
+
+// TestHashBlobFile verifies streamed hashing matches in-memory blob hash.
+func TestHashBlobFile(t *testing.T) {
+	repoPath := t.TempDir()
+	content := []byte(strings.Repeat("large file line\n", 10000))
+	testFile := testutils.CreateTestFile(t, repoPath, "large.txt", content)
+
+	hash, err := HashBlobFile(testFile)
+	if err != nil {
+		t.Fatalf("Failed to hash file: %v", err)
+	}
+
+	if expected := NewBlob(content).Hash(); hash != expected {
+		t.Fatalf("Expected hash [%s], got [%s]", expected, hash)
+	}
+}
+
+// TestHashBlobFile_Errors verifies missing files and directories are rejected.
+func TestHashBlobFile_Errors(t *testing.T) {
+	for _, path := range []string{"/nonexistent/file.txt", t.TempDir()} {
+		_, err := HashBlobFile(path)
+		if err == nil {
+			t.Fatalf("Expected error hashing %s", path)
+		}
+		if !strings.Contains(err.Error(), "failed to read file") {
+			t.Errorf("Expected error message about reading file, got: %v", err)
+		}
+	}
+}
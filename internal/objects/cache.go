@@ -0,0 +1,125 @@
+package objects
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+)
+
+// CacheStats reports object cache effectiveness.
+type CacheStats struct {
+	Hits      uint64 // Reads served from the cache
+	Misses    uint64 // Reads that had to inflate the object from disk
+	Evictions uint64 // Objects dropped to stay within capacity
+	Size      int    // Objects currently cached
+	Capacity  int    // Maximum number of cached objects
+}
+
+// String formats statistics for debug output.
+func (s CacheStats) String() string {
+	return fmt.Sprintf("object cache: %d/%d entries, %d hits, %d misses, %d evictions",
+		s.Size, s.Capacity, s.Hits, s.Misses, s.Evictions)
+}
+
+// objectCache is a fixed-capacity LRU cache of parsed objects keyed by hash.
+// Objects are immutable, so cached values are shared between readers.
+type objectCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List               // Most recently used at front
+	items    map[string]*list.Element // Hash to element holding cacheItem
+	stats    CacheStats
+}
+
+// cacheItem is the value stored in each list element.
+type cacheItem struct {
+	hash   string
+	object Object
+}
+
+// newObjectCache creates cache holding up to capacity objects.
+func newObjectCache(capacity int) *objectCache {
+	return &objectCache{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// get returns cached object for hash, marking it most recently used.
+func (c *objectCache) get(hash string) (Object, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[hash]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+
+	c.stats.Hits++
+	c.order.MoveToFront(element)
+	return element.Value.(*cacheItem).object, true
+}
+
+// add caches object under hash, evicting least recently used objects beyond capacity.
+func (c *objectCache) add(hash string, object Object) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.items[hash]; ok {
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.items[hash] = c.order.PushFront(&cacheItem{hash: hash, object: object})
+
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheItem).hash)
+		c.stats.Evictions++
+	}
+}
+
+// snapshot returns current statistics.
+func (c *objectCache) snapshot() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Size = c.order.Len()
+	stats.Capacity = c.capacity
+	return stats
+}
+
+// WithCacheSize sets how many parsed trees and commits the store keeps in memory.
+// A size of zero or less disables caching.
+func WithCacheSize(size int) StoreOption {
+	return func(store *ObjectStore) {
+		store.cacheSize = size
+	}
+}
+
+// CacheStats returns object cache statistics, or zero values when caching is disabled.
+func (store *ObjectStore) CacheStats() CacheStats {
+	if store.cache == nil {
+		return CacheStats{}
+	}
+	return store.cache.snapshot()
+}
+
+// cachedObject returns the cached object for hash when caching is enabled.
+func (store *ObjectStore) cachedObject(hash string) (Object, bool) {
+	if store.cache == nil {
+		return nil, false
+	}
+	return store.cache.get(hash)
+}
+
+// cacheObject records a freshly parsed object when caching is enabled.
+func (store *ObjectStore) cacheObject(hash string, object Object) {
+	if store.cache != nil {
+		store.cache.add(hash, object)
+	}
+}
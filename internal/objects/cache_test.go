@@ -0,0 +1,110 @@
+package objects
+
+import (
+	"os"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// TestObjectCache_EvictsLeastRecentlyUsed verifies the oldest untouched entry is evicted first.
+func TestObjectCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newObjectCache(2)
+	first, second, third := NewBlob([]byte("1")), NewBlob([]byte("2")), NewBlob([]byte("3"))
+
+	cache.add(first.Hash(), first)
+	cache.add(second.Hash(), second)
+	cache.get(first.Hash())
+	cache.add(third.Hash(), third)
+
+	if _, ok := cache.get(second.Hash()); ok {
+		t.Error("Expected least recently used entry to be evicted")
+	}
+	if _, ok := cache.get(first.Hash()); !ok {
+		t.Error("Expected recently used entry to remain cached")
+	}
+	if _, ok := cache.get(third.Hash()); !ok {
+		t.Error("Expected newest entry to remain cached")
+	}
+
+	stats := cache.snapshot()
+	expected := CacheStats{Hits: 3, Misses: 1, Evictions: 1, Size: 2, Capacity: 2}
+	if stats != expected {
+		t.Errorf("Expected stats %+v, got %+v", expected, stats)
+	}
+}
+
+// TestObjectStore_Cache_ServesRepeatedReads verifies cached trees and commits skip the object file.
+func TestObjectStore_Cache_ServesRepeatedReads(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath))
+	commit := createAndStoreInitialCommit(t, store)
+	tree := createAndStoreTree(t, store, []TreeEntry{
+		createTreeEntry(t, ModeRegularFile, "file.txt", testutils.RandomHash()),
+	})
+
+	if _, err := store.ReadCommit(commit.Hash()); err != nil {
+		t.Fatalf("Failed to read commit: %v", err)
+	}
+	if _, _, err := store.ReadObject(tree.Hash()); err != nil {
+		t.Fatalf("Failed to read tree: %v", err)
+	}
+
+	// Deleting object files proves later reads come from memory
+	for _, hash := range []string{commit.Hash(), tree.Hash()} {
+		if err := os.Remove(store.objectPath(hash)); err != nil {
+			t.Fatalf("Failed to remove object file: %v", err)
+		}
+	}
+
+	if _, err := store.ReadCommit(commit.Hash()); err != nil {
+		t.Errorf("Expected cached commit, got %v", err)
+	}
+	if _, err := store.ReadTree(tree.Hash()); err != nil {
+		t.Errorf("Expected cached tree, got %v", err)
+	}
+	if _, objectType, err := store.ReadObject(commit.Hash()); err != nil || objectType != "commit" {
+		t.Errorf("Expected cached commit from ReadObject, got type %q and error %v", objectType, err)
+	}
+
+	stats := store.CacheStats()
+	if stats.Hits != 3 || stats.Misses != 2 {
+		t.Errorf("Expected 3 hits and 2 misses, got %s", stats)
+	}
+}
+
+// TestObjectStore_Cache_TypeMismatch verifies a cached object is not returned as the wrong type.
+func TestObjectStore_Cache_TypeMismatch(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath))
+	commit := createAndStoreInitialCommit(t, store)
+
+	if _, err := store.ReadCommit(commit.Hash()); err != nil {
+		t.Fatalf("Failed to read commit: %v", err)
+	}
+	if _, err := store.ReadTree(commit.Hash()); err == nil {
+		t.Error("Expected error reading cached commit as tree")
+	}
+}
+
+// TestObjectStore_Cache_Disabled verifies a non-positive size turns caching off.
+func TestObjectStore_Cache_Disabled(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath), WithCacheSize(0))
+	commit := createAndStoreInitialCommit(t, store)
+
+	if _, err := store.ReadCommit(commit.Hash()); err != nil {
+		t.Fatalf("Failed to read commit: %v", err)
+	}
+	if err := os.Remove(store.objectPath(commit.Hash())); err != nil {
+		t.Fatalf("Failed to remove object file: %v", err)
+	}
+
+	if _, err := store.ReadCommit(commit.Hash()); err == nil {
+		t.Error("Expected read to hit disk when caching is disabled")
+	}
+	if stats := store.CacheStats(); stats != (CacheStats{}) {
+		t.Errorf("Expected zero stats, got %+v", stats)
+	}
+}
@@ -3,6 +3,8 @@ package objects
 import (
 	"bytes"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/KostasZigo/gogit/internal/constants"
@@ -23,41 +25,67 @@ func (a Author) String() string {
 		a.Email)
 }
 
+// Identity formats author as stored in objects: "Name <email> <unix time> <±HHMM>".
+func (a Author) Identity() string {
+	return fmt.Sprintf("%s %d %s", a.String(), a.Timestamp.Unix(), calculateTimezone(a.Timestamp))
+}
+
 // Commit represents a snapshot of the repository
 type Commit struct {
-	hash       string
-	treeHash   string
-	parentHash string
-	author     Author
-	committer  Author
-	message    string
+	hash         string
+	treeHash     string
+	parents      []string // Parent hashes in header order; the first is followed through history
+	author       Author
+	committer    Author
+	message      string
+	encoding     string   // Message encoding from the encoding header, empty meaning UTF-8
+	extraHeaders []string // Unrecognized headers (gpgsig, mergetag, ...) including continuation lines
+	content      []byte   // Raw content, kept verbatim so parsed commits keep their hash
+}
+
+// CommitOption configures optional commit headers.
+type CommitOption func(*Commit)
+
+// WithEncoding records message encoding in the commit's encoding header.
+// Message bytes are stored as given; they are expected to already be in that encoding.
+func WithEncoding(encoding string) CommitOption {
+	return func(commit *Commit) {
+		commit.encoding = encoding
+	}
 }
 
 // NewCommit creates commit with parent reference.
-func NewCommit(treeHash, parentHash, message string, author Author) (*Commit, error) {
-	content := buildCommitContent(treeHash, parentHash, message, author)
-	hash, err := utils.ComputeHash(content, utils.CommitObjectType)
+func NewCommit(treeHash, parentHash, message string, author Author, opts ...CommitOption) (*Commit, error) {
+	commit := &Commit{
+		treeHash:  treeHash,
+		author:    author,
+		committer: author,
+		message:   message,
+	}
+	if parentHash != "" {
+		commit.parents = []string{parentHash}
+	}
+	for _, opt := range opts {
+		opt(commit)
+	}
+
+	commit.content = buildCommitContent(treeHash, parentHash, message, author, commit.encoding)
+	hash, err := utils.ComputeHash(commit.content, utils.CommitObjectType)
 	if err != nil {
 		return nil, fmt.Errorf("failed to compute hash for commit: %v", err)
 	}
+	commit.hash = hash
 
-	return &Commit{
-		hash:       hash,
-		treeHash:   treeHash,
-		parentHash: parentHash,
-		author:     author,
-		committer:  author,
-		message:    message,
-	}, nil
+	return commit, nil
 }
 
 // NewInitialCommit creates root commit without parent.
-func NewInitialCommit(treeHash, message string, author Author) (*Commit, error) {
-	return NewCommit(treeHash, "", message, author)
+func NewInitialCommit(treeHash, message string, author Author, opts ...CommitOption) (*Commit, error) {
+	return NewCommit(treeHash, "", message, author, opts...)
 }
 
 // buildCommitContent constructs Git commit object format
-func buildCommitContent(treeHash, parentHash, message string, author Author) []byte {
+func buildCommitContent(treeHash, parentHash, message string, author Author, encoding string) []byte {
 	var buf bytes.Buffer
 
 	// Tree reference - tree hash\n
@@ -69,20 +97,13 @@ func buildCommitContent(treeHash, parentHash, message string, author Author) []b
 	}
 
 	// Author and commiter - author name <email> time timezone\n
-	timezone := calculateTimezone(author.Timestamp)
-	fmt.Fprintf(&buf, "%s%s %d %s\n",
-		constants.CommitAuthorPrefix,
-		author.String(),
-		author.Timestamp.Unix(),
-		timezone,
-	)
-
-	fmt.Fprintf(&buf, "%s%s %d %s\n",
-		constants.CommitCommitterPrefix,
-		author.String(),
-		author.Timestamp.Unix(),
-		timezone,
-	)
+	fmt.Fprintf(&buf, "%s%s\n", constants.CommitAuthorPrefix, author.Identity())
+	fmt.Fprintf(&buf, "%s%s\n", constants.CommitCommitterPrefix, author.Identity())
+
+	// Encoding follows committer, as Git writes it
+	if encoding != "" {
+		fmt.Fprintf(&buf, "%s%s\n", constants.CommitEncodingPrefix, encoding)
+	}
 
 	// Blank line before message
 	buf.WriteByte('\n')
@@ -118,11 +139,11 @@ func (c *Commit) Hash() string {
 }
 
 func (c *Commit) Content() []byte {
-	return buildCommitContent(c.treeHash, c.parentHash, c.message, c.author)
+	return c.content
 }
 
 func (c *Commit) Size() int {
-	return len(c.Content())
+	return len(c.content)
 }
 
 func (c *Commit) Header() string {
@@ -135,5 +156,61 @@ func (c *Commit) Data() []byte {
 }
 
 func (c *Commit) IsInitialCommit() bool {
-	return c.parentHash == ""
+	return len(c.parents) == 0
+}
+
+// TreeHash returns hash of the root tree recorded by the commit.
+func (c *Commit) TreeHash() string {
+	return c.treeHash
+}
+
+// ParentHash returns hash of the first parent commit, empty for initial commits.
+func (c *Commit) ParentHash() string {
+	if len(c.parents) == 0 {
+		return ""
+	}
+	return c.parents[0]
+}
+
+// Parents returns hashes of all parent commits, more than one for merges.
+func (c *Commit) Parents() []string {
+	return slices.Clone(c.parents)
+}
+
+func (c *Commit) Author() Author {
+	return c.author
+}
+
+func (c *Commit) Committer() Author {
+	return c.committer
+}
+
+func (c *Commit) Message() string {
+	return c.message
+}
+
+// Subject returns first paragraph of the message joined into a single line.
+func (c *Commit) Subject() string {
+	return messageSubject(c.message)
+}
+
+// messageSubject returns first paragraph of message joined into a single line.
+func messageSubject(message string) string {
+	paragraph, _, _ := strings.Cut(strings.Trim(message, "\n"), "\n\n")
+	lines := strings.Split(paragraph, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.Join(lines, " ")
+}
+
+// Encoding returns message encoding from the encoding header, empty for UTF-8 messages.
+func (c *Commit) Encoding() string {
+	return c.encoding
+}
+
+// ExtraHeaders returns headers the commit carries beyond tree, parent, author, committer and encoding.
+// Each entry is the raw header text, with multi-line values joined by "\n ".
+func (c *Commit) ExtraHeaders() []string {
+	return slices.Clone(c.extraHeaders)
 }
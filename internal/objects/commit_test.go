@@ -109,3 +109,85 @@ func TestCommit_MessageWithMultipleLines(t *testing.T) {
 		t.Fatalf("Multi-line message not preserved correctly. Expected [%s] got [%s]", message, commit.message)
 	}
 }
+
+// TestNewCommit_WithEncoding verifies encoding header is written after committer and parsed back.
+func TestNewCommit_WithEncoding(t *testing.T) {
+	author := createTestAuthor("Test User", "test@example.com")
+
+	commit, err := NewInitialCommit("tree123", "caf\xe9", author, WithEncoding("ISO-8859-1"))
+	if err != nil {
+		t.Fatalf("Failed to create commit: %v", err)
+	}
+
+	content := string(commit.Content())
+	if !strings.Contains(content, author.Identity()+"\n"+constants.CommitEncodingPrefix+"ISO-8859-1\n\n") {
+		t.Fatalf("Expected encoding header after committer, got:\n%s", content)
+	}
+
+	parsed, err := parseCommitContent(content)
+	if err != nil {
+		t.Fatalf("Failed to parse commit: %v", err)
+	}
+	if parsed.Encoding() != "ISO-8859-1" {
+		t.Errorf("Expected encoding ISO-8859-1, got %q", parsed.Encoding())
+	}
+	if len(parsed.ExtraHeaders()) != 0 {
+		t.Errorf("Expected encoding not to be reported as extra header, got %q", parsed.ExtraHeaders())
+	}
+	if parsed.Hash() != commit.Hash() {
+		t.Errorf("Expected hash %s, got %s", commit.Hash(), parsed.Hash())
+	}
+}
+
+// TestCommit_DisplayMessage verifies messages are transcoded to UTF-8 when encoding is supported.
+func TestCommit_DisplayMessage(t *testing.T) {
+	author := createTestAuthor("Test User", "test@example.com")
+
+	tests := []struct {
+		name     string
+		message  string
+		encoding string
+		expected string
+	}{
+		{"utf-8 default", "café", "", "café"},
+		{"latin-1", "caf\xe9", "ISO-8859-1", "café"},
+		{"latin-1 alias", "na\xefve", "latin1", "naïve"},
+		{"ascii", "plain", "US-ASCII", "plain"},
+		{"unsupported encoding", "caf\x82", "Shift_JIS", "caf\x82"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commit, err := NewInitialCommit("tree123", tt.message, author, WithEncoding(tt.encoding))
+			if err != nil {
+				t.Fatalf("Failed to create commit: %v", err)
+			}
+			if got := commit.DisplayMessage(); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestCommit_Subject verifies the first paragraph is joined into one line.
+func TestCommit_Subject(t *testing.T) {
+	author := createTestAuthor("Test User", "test@example.com")
+
+	tests := map[string]string{
+		"Single line":                     "Single line",
+		"Wrapped\nsubject  \n\nBody text": "Wrapped subject",
+		"\n\nLeading blank lines\n\nBody": "Leading blank lines",
+		"Trailing newline\n":              "Trailing newline",
+		"":                                "",
+	}
+
+	for message, expected := range tests {
+		commit, err := NewInitialCommit("tree123", message, author)
+		if err != nil {
+			t.Fatalf("Failed to create commit: %v", err)
+		}
+		if got := commit.Subject(); got != expected {
+			t.Errorf("Subject of %q: expected %q, got %q", message, expected, got)
+		}
+	}
+}
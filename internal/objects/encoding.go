@@ -0,0 +1,41 @@
+package objects
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// DisplayMessage returns commit message transcoded to UTF-8 for display.
+// Messages in encodings without a known conversion are returned unchanged.
+func (c *Commit) DisplayMessage() string {
+	if converted, ok := toUTF8(c.message, c.encoding); ok {
+		return converted
+	}
+	return c.message
+}
+
+// toUTF8 converts text from named encoding to UTF-8.
+// Reports false when encoding is not supported or text is invalid for it.
+func toUTF8(text, encoding string) (string, bool) {
+	switch strings.ToLower(strings.ReplaceAll(encoding, "_", "-")) {
+	case "", "utf-8", "utf8":
+		return text, utf8.ValidString(text)
+	case "us-ascii", "ascii":
+		for i := 0; i < len(text); i++ {
+			if text[i] >= utf8.RuneSelf {
+				return "", false
+			}
+		}
+		return text, true
+	case "iso-8859-1", "iso8859-1", "latin1", "latin-1":
+		// Every Latin-1 byte maps directly to the code point of the same value
+		var builder strings.Builder
+		builder.Grow(len(text))
+		for i := 0; i < len(text); i++ {
+			builder.WriteRune(rune(text[i]))
+		}
+		return builder.String(), true
+	default:
+		return "", false
+	}
+}
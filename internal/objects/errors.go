@@ -0,0 +1,28 @@
+package objects
+
+import "errors"
+
+// Sentinel errors returned by ObjectStore, wrapped with the offending hash.
+// Use errors.Is to test for them.
+var (
+	// ErrObjectNotFound is returned when no object matches a hash or prefix.
+	ErrObjectNotFound = errors.New("object not found")
+
+	// ErrAmbiguousObject is returned when an abbreviated hash matches several objects.
+	ErrAmbiguousObject = errors.New("ambiguous object name")
+
+	// ErrNotABlob is returned when a blob was requested but the object has another type.
+	ErrNotABlob = errors.New("not a blob")
+
+	// ErrNotATree is returned when a tree was requested but the object has another type.
+	ErrNotATree = errors.New("not a tree")
+
+	// ErrNotACommit is returned when a commit was requested but the object has another type.
+	ErrNotACommit = errors.New("not a commit")
+
+	// ErrNotATag is returned when a tag was requested but the object has another type.
+	ErrNotATag = errors.New("not a tag")
+
+	// ErrHashMismatch is returned when stored content does not hash to its object name.
+	ErrHashMismatch = errors.New("hash mismatch")
+)
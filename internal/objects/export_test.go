@@ -137,8 +137,8 @@ func assertCommitFields(t *testing.T, commit *Commit, treeHash, parentHash, mess
 		t.Errorf("Expected tree hash [%s], got [%s]", treeHash, commit.treeHash)
 	}
 
-	if commit.parentHash != parentHash {
-		t.Errorf("Expected parent hash [%s], got [%s]", parentHash, commit.parentHash)
+	if commit.ParentHash() != parentHash {
+		t.Errorf("Expected parent hash [%s], got [%s]", parentHash, commit.ParentHash())
 	}
 
 	if commit.message != message {
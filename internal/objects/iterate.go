@@ -0,0 +1,112 @@
+package objects
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+)
+
+// ForEachObject calls fn with the hash of every loose object in the store, in hash order.
+// Temporary files and unrelated entries in the objects directory are skipped.
+// Iteration stops at the first error returned by fn or when ctx is cancelled, and that error is returned.
+func (store *ObjectStore) ForEachObject(ctx context.Context, fn func(hash string) error) error {
+	fanoutDirs, err := os.ReadDir(store.objectsDir())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read objects directory: %w", err)
+	}
+
+	for _, fanoutDir := range fanoutDirs {
+		if !fanoutDir.IsDir() || !isHexString(fanoutDir.Name(), constants.HashDirPrefixLength) {
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		objectFiles, err := os.ReadDir(filepath.Join(store.objectsDir(), fanoutDir.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to read objects directory %s: %w", fanoutDir.Name(), err)
+		}
+
+		for _, objectFile := range objectFiles {
+			suffixLength := constants.HashStringLength - constants.HashDirPrefixLength
+			if !objectFile.Type().IsRegular() || !isHexString(objectFile.Name(), suffixLength) {
+				continue
+			}
+
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			if err := fn(fanoutDir.Name() + objectFile.Name()); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// isHexString reports whether s is exactly length lowercase hexadecimal characters.
+func isHexString(s string, length int) bool {
+	if len(s) != length {
+		return false
+	}
+	return strings.Trim(s, "0123456789abcdef") == ""
+}
+
+// ResolvePrefix expands an abbreviated hash of at least MinPrefixLength characters to the
+// full hash of the single loose object it matches. Only the prefix's fan-out directory is
+// scanned. An ambiguous prefix reports every candidate.
+func (store *ObjectStore) ResolvePrefix(prefix string) (string, error) {
+	normalized := strings.ToLower(prefix)
+	if len(normalized) < constants.MinPrefixLength || len(normalized) > constants.HashStringLength ||
+		!isHexString(normalized, len(normalized)) {
+		return "", fmt.Errorf("invalid object name %q: expected %d to %d hex characters",
+			prefix, constants.MinPrefixLength, constants.HashStringLength)
+	}
+	prefix = normalized
+
+	if len(prefix) == constants.HashStringLength {
+		if !store.Exists(prefix) {
+			return "", fmt.Errorf("%w: %s", ErrObjectNotFound, prefix)
+		}
+		return prefix, nil
+	}
+
+	fanoutName := prefix[:constants.HashDirPrefixLength]
+	objectFiles, err := os.ReadDir(filepath.Join(store.objectsDir(), fanoutName))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("failed to read objects directory %s: %w", fanoutName, err)
+	}
+
+	var candidates []string
+	suffixLength := constants.HashStringLength - constants.HashDirPrefixLength
+	for _, objectFile := range objectFiles {
+		name := objectFile.Name()
+		if objectFile.Type().IsRegular() && isHexString(name, suffixLength) &&
+			strings.HasPrefix(fanoutName+name, prefix) {
+			candidates = append(candidates, fanoutName+name)
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return "", fmt.Errorf("%w: %s", ErrObjectNotFound, prefix)
+	case 1:
+		return candidates[0], nil
+	default:
+		return "", fmt.Errorf("%w: short object ID %s matches:\n  %s",
+			ErrAmbiguousObject, prefix, strings.Join(candidates, "\n  "))
+	}
+}
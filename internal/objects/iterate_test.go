@@ -0,0 +1,196 @@
+package objects
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// collectObjects runs ForEachObject and returns visited hashes.
+func collectObjects(t *testing.T, store *ObjectStore) []string {
+	t.Helper()
+
+	var hashes []string
+	err := store.ForEachObject(context.Background(), func(hash string) error {
+		hashes = append(hashes, hash)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEachObject failed: %v", err)
+	}
+	return hashes
+}
+
+// TestObjectStore_ForEachObject verifies all loose objects are visited in hash order.
+func TestObjectStore_ForEachObject(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath))
+
+	var expected []string
+	for _, content := range []string{"one", "two", "three", "four"} {
+		blob := NewBlob([]byte(content))
+		if err := store.Store(blob); err != nil {
+			t.Fatalf("Failed to store blob: %v", err)
+		}
+		expected = append(expected, blob.Hash())
+	}
+	slices.Sort(expected)
+
+	hashes := collectObjects(t, store)
+	if !slices.Equal(hashes, expected) {
+		t.Errorf("Expected %v, got %v", expected, hashes)
+	}
+}
+
+// TestObjectStore_ForEachObject_SkipsNonObjects verifies temp files and foreign entries are ignored.
+func TestObjectStore_ForEachObject_SkipsNonObjects(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath))
+	blob := NewBlob([]byte("real object"))
+	if err := store.Store(blob); err != nil {
+		t.Fatalf("Failed to store blob: %v", err)
+	}
+
+	objectsDir := filepath.Join(repoPath, constants.Gogit, constants.Objects)
+	for _, dir := range []string{"info", "pack"} {
+		if err := os.MkdirAll(filepath.Join(objectsDir, dir), constants.DirPerms); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+	testutils.CreateTestFile(t, objectsDir, "tmp_obj_123456", []byte("partial"))
+	testutils.CreateTestFile(t, filepath.Join(objectsDir, blob.Hash()[:2]), "not-a-hash", []byte("junk"))
+
+	hashes := collectObjects(t, store)
+	if !slices.Equal(hashes, []string{blob.Hash()}) {
+		t.Errorf("Expected only %s, got %v", blob.Hash(), hashes)
+	}
+}
+
+// TestObjectStore_ForEachObject_EmptyStore verifies a missing objects directory yields no objects.
+func TestObjectStore_ForEachObject_EmptyStore(t *testing.T) {
+	store := NewObjectStore(repository.GitDir(t.TempDir()))
+
+	if hashes := collectObjects(t, store); len(hashes) != 0 {
+		t.Errorf("Expected no objects, got %v", hashes)
+	}
+}
+
+// TestObjectStore_ForEachObject_StopsEarly verifies callback errors and cancellation stop iteration.
+func TestObjectStore_ForEachObject_StopsEarly(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath))
+	for _, content := range []string{"a", "b", "c"} {
+		if err := store.Store(NewBlob([]byte(content))); err != nil {
+			t.Fatalf("Failed to store blob: %v", err)
+		}
+	}
+
+	stopErr := errors.New("stop")
+	visited := 0
+	err := store.ForEachObject(context.Background(), func(hash string) error {
+		visited++
+		return stopErr
+	})
+	if !errors.Is(err, stopErr) || visited != 1 {
+		t.Errorf("Expected stop after 1 object, got %d visits and error %v", visited, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = store.ForEachObject(ctx, func(hash string) error {
+		t.Fatal("Callback should not run after cancellation")
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+// storeBlobsWithSharedPrefix stores blobs until two share the same first prefixLength hash characters.
+func storeBlobsWithSharedPrefix(t *testing.T, store *ObjectStore, prefixLength int) (string, string) {
+	t.Helper()
+
+	seen := make(map[string]string)
+	for i := 0; ; i++ {
+		blob := NewBlob([]byte(fmt.Sprintf("content %d", i)))
+		if err := store.Store(blob); err != nil {
+			t.Fatalf("Failed to store blob: %v", err)
+		}
+		prefix := blob.Hash()[:prefixLength]
+		if other, ok := seen[prefix]; ok {
+			return other, blob.Hash()
+		}
+		seen[prefix] = blob.Hash()
+	}
+}
+
+// TestObjectStore_ResolvePrefix verifies unique prefixes expand to the full hash.
+func TestObjectStore_ResolvePrefix(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath))
+	blob := NewBlob([]byte("resolve me"))
+	if err := store.Store(blob); err != nil {
+		t.Fatalf("Failed to store blob: %v", err)
+	}
+
+	for _, prefix := range []string{blob.Hash()[:4], blob.Hash()[:7], strings.ToUpper(blob.Hash()[:10]), blob.Hash()} {
+		resolved, err := store.ResolvePrefix(prefix)
+		if err != nil {
+			t.Fatalf("ResolvePrefix(%s) failed: %v", prefix, err)
+		}
+		if resolved != blob.Hash() {
+			t.Errorf("Expected %s, got %s", blob.Hash(), resolved)
+		}
+	}
+}
+
+// TestObjectStore_ResolvePrefix_Ambiguous verifies ambiguous prefixes list every candidate.
+func TestObjectStore_ResolvePrefix_Ambiguous(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath))
+	first, second := storeBlobsWithSharedPrefix(t, store, 4)
+
+	_, err := store.ResolvePrefix(first[:4])
+	if !errors.Is(err, ErrAmbiguousObject) {
+		t.Fatalf("Expected ambiguity error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), first) || !strings.Contains(err.Error(), second) {
+		t.Errorf("Expected both candidates in error, got %v", err)
+	}
+}
+
+// TestObjectStore_ResolvePrefix_Errors verifies invalid and unknown prefixes are rejected.
+func TestObjectStore_ResolvePrefix_Errors(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath))
+
+	tests := []struct {
+		name          string
+		prefix        string
+		expectedError string
+	}{
+		{"too short", "abc", "invalid object name"},
+		{"not hex", "ZZZZ", `invalid object name "ZZZZ"`},
+		{"too long", testutils.RandomHash() + "0", "invalid object name"},
+		{"unknown prefix", "abcd", "not found"},
+		{"unknown full hash", testutils.RandomHash(), "not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := store.ResolvePrefix(tt.prefix)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
@@ -1,5 +1,11 @@
 package objects
 
+import (
+	"fmt"
+
+	"github.com/KostasZigo/gogit/utils"
+)
+
 // Object represents any GoGit object that can be stored
 // All GoGit objects (blobs, trees, commits, tags) must implement this interface
 type Object interface {
@@ -10,3 +16,26 @@ type Object interface {
 	// Format: "<type> <size>\0<content>"
 	Data() []byte
 }
+
+// ValidateContent checks that raw content is well-formed for objectType,
+// so malformed trees, commits or tags are never written to the store.
+func ValidateContent(objectType utils.ObjectType, content []byte) error {
+	var err error
+	switch objectType {
+	case utils.BlobObjectType:
+		return nil
+	case utils.TreeObjectType:
+		_, err = parseTreeEntries(content)
+	case utils.CommitObjectType:
+		_, err = parseCommitContent(string(content))
+	case utils.TagObjectType:
+		_, err = ParseTag(content)
+	default:
+		return fmt.Errorf("invalid object type: %s", objectType)
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid %s object: %w", objectType, err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package objects
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+)
+
+// ModTime returns when the loose object hash was last written or freshened.
+func (store *ObjectStore) ModTime(hash string) (time.Time, error) {
+	info, err := os.Stat(store.objectPath(hash))
+	if errors.Is(err, fs.ErrNotExist) {
+		return time.Time{}, fmt.Errorf("%w: %s: %w", ErrObjectNotFound, hash, err)
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to stat object %s: %w", hash, err)
+	}
+	return info.ModTime(), nil
+}
+
+// Remove deletes the loose object hash, and its fan-out directory once that is empty.
+func (store *ObjectStore) Remove(hash string) error {
+	objectPath := store.objectPath(hash)
+	if err := os.Remove(objectPath); err != nil {
+		return fmt.Errorf("failed to remove object %s: %w", hash, err)
+	}
+
+	// Fails harmlessly while other objects share the directory
+	os.Remove(filepath.Dir(objectPath))
+	return nil
+}
+
+// RemoveTempObjects deletes temporary object files last modified before expire, which writers
+// that were interrupted left behind. Younger ones may still be in use. Returns the removed paths.
+func (store *ObjectStore) RemoveTempObjects(expire time.Time) ([]string, error) {
+	tempPaths, err := filepath.Glob(filepath.Join(store.objectsDir(), constants.TempObjectPattern))
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for _, tempPath := range tempPaths {
+		info, err := os.Lstat(tempPath)
+		if err != nil || !info.Mode().IsRegular() || !info.ModTime().Before(expire) {
+			continue
+		}
+		if err := os.Remove(tempPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return removed, fmt.Errorf("failed to remove temporary object file: %w", err)
+		}
+		removed = append(removed, tempPath)
+	}
+	return removed, nil
+}
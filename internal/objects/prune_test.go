@@ -0,0 +1,71 @@
+package objects
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// TestObjectStore_Remove verifies an object and its emptied fan-out directory are removed, while
+// a directory still holding objects stays.
+func TestObjectStore_Remove(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath))
+	blob := NewBlob([]byte("doomed\n"))
+	storeObjects(t, store, blob)
+
+	if _, err := store.ModTime(blob.Hash()); err != nil {
+		t.Fatalf("Failed to get modification time: %v", err)
+	}
+	if err := store.Remove(blob.Hash()); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if store.Exists(blob.Hash()) {
+		t.Error("Expected object to be removed")
+	}
+	if _, err := os.Stat(filepath.Dir(store.objectPath(blob.Hash()))); !os.IsNotExist(err) {
+		t.Errorf("Expected empty fan-out directory to be removed, got %v", err)
+	}
+	if _, err := store.ModTime(blob.Hash()); err == nil {
+		t.Error("Expected ModTime of a removed object to fail")
+	}
+}
+
+// TestObjectStore_RemoveTempObjects verifies only temporary files older than the expiry go.
+func TestObjectStore_RemoveTempObjects(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath))
+
+	stale, err := store.createTempObject()
+	if err != nil {
+		t.Fatalf("Failed to create temporary object: %v", err)
+	}
+	stale.Close()
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(stale.Name(), old, old); err != nil {
+		t.Fatalf("Failed to age temporary object: %v", err)
+	}
+	fresh, err := store.createTempObject()
+	if err != nil {
+		t.Fatalf("Failed to create temporary object: %v", err)
+	}
+	fresh.Close()
+
+	removed, err := store.RemoveTempObjects(time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("RemoveTempObjects failed: %v", err)
+	}
+	if !slices.Equal(removed, []string{stale.Name()}) {
+		t.Errorf("Expected only %s removed, got %v", stale.Name(), removed)
+	}
+	testutils.AssertFileExists(t, fresh.Name())
+	if _, err := os.Stat(filepath.Join(repoPath, constants.Gogit, constants.Objects, filepath.Base(stale.Name()))); !os.IsNotExist(err) {
+		t.Errorf("Expected stale temporary object to be gone, got %v", err)
+	}
+}
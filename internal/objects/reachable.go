@@ -0,0 +1,68 @@
+package objects
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+)
+
+// Reachable returns the objects reachable from tips that are not in seen, in the order they are
+// found, adding them to seen. Objects are followed through References. The empty tree is
+// returned only when it is stored.
+// Objects that cannot be read are passed to unreadable with the failure, and the walk goes on
+// without them unless it returns an error; with a nil unreadable they end the walk.
+func (store *ObjectStore) Reachable(tips []string, seen map[string]bool, unreadable func(hash string, err error) error) ([]string, error) {
+	var found []string
+	pending := slices.Clone(tips)
+	for len(pending) > 0 {
+		hash := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+
+		// The empty tree leads nowhere and counts as present whether stored or not
+		if hash == constants.EmptyTreeHash {
+			if store.Exists(hash) {
+				found = append(found, hash)
+			}
+			continue
+		}
+
+		object, _, err := store.ReadObject(hash)
+		if err != nil {
+			if unreadable == nil {
+				return nil, fmt.Errorf("failed to read object %s: %w", hash, err)
+			}
+			if err := unreadable(hash, err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		found = append(found, hash)
+		pending = append(pending, References(object)...)
+	}
+	return found, nil
+}
+
+// References returns the objects object points to: the parents and tree of a commit, the
+// entries of a tree other than submodule commits, or the object a tag points to.
+func References(object Object) []string {
+	switch object := object.(type) {
+	case *Commit:
+		return append(slices.Clone(object.Parents()), object.TreeHash())
+	case *Tree:
+		var hashes []string
+		for _, entry := range object.Entries() {
+			if entry.Mode() != ModeSubmodule {
+				hashes = append(hashes, entry.Hash())
+			}
+		}
+		return hashes
+	case *Tag:
+		return []string{object.Object()}
+	}
+	return nil
+}
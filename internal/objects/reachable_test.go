@@ -0,0 +1,106 @@
+package objects
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+	"github.com/KostasZigo/gogit/utils"
+)
+
+// reachableTestAuthor signs the commits and tags of reachability tests.
+var reachableTestAuthor = Author{Name: "A", Email: "a@example.com", Timestamp: time.Unix(1700000000, 0).UTC()}
+
+// storeObjects stores every object, failing the test on error.
+func storeObjects(t *testing.T, store *ObjectStore, objects ...Object) {
+	t.Helper()
+	for _, object := range objects {
+		if err := store.Store(object); err != nil {
+			t.Fatalf("Failed to store object: %v", err)
+		}
+	}
+}
+
+// TestObjectStore_Reachable verifies the walk follows tags, commits, parents and trees, skips
+// submodule commits and the unstored empty tree, and returns nothing already seen.
+func TestObjectStore_Reachable(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath))
+
+	blob := NewBlob([]byte("content\n"))
+	file, _ := NewTreeEntry(ModeRegularFile, "file.txt", blob.Hash())
+	submodule, _ := NewTreeEntry(ModeSubmodule, "lib", testutils.RandomHash())
+	tree, err := NewTree([]TreeEntry{*file, *submodule})
+	if err != nil {
+		t.Fatalf("Failed to create tree: %v", err)
+	}
+	first, _ := NewInitialCommit(constants.EmptyTreeHash, "first", reachableTestAuthor)
+	second, _ := NewCommit(tree.Hash(), first.Hash(), "second", reachableTestAuthor)
+	tag, _ := NewTag(second.Hash(), utils.CommitObjectType, "v1", reachableTestAuthor, "v1\n")
+	storeObjects(t, store, blob, tree, first, second, tag)
+
+	found, err := store.Reachable([]string{first.Hash()}, map[string]bool{}, nil)
+	if err != nil || !slices.Equal(found, []string{first.Hash()}) {
+		t.Errorf("Expected the unstored empty tree to be skipped, got %v, %v", found, err)
+	}
+	if _, err := store.StoreStream(utils.TreeObjectType, 0, bytes.NewReader(nil)); err != nil {
+		t.Fatalf("Failed to store empty tree: %v", err)
+	}
+
+	seen := map[string]bool{}
+	found, err = store.Reachable([]string{tag.Hash()}, seen, nil)
+	if err != nil {
+		t.Fatalf("Reachable failed: %v", err)
+	}
+	expected := []string{tag.Hash(), second.Hash(), tree.Hash(), blob.Hash(), first.Hash(), constants.EmptyTreeHash}
+	if !slices.Equal(slices.Sorted(slices.Values(found)), slices.Sorted(slices.Values(expected))) {
+		t.Errorf("Expected %v, got %v", expected, found)
+	}
+
+	found, err = store.Reachable([]string{second.Hash()}, seen, nil)
+	if err != nil || len(found) != 0 {
+		t.Errorf("Expected nothing new from seen objects, got %v, %v", found, err)
+	}
+}
+
+// TestObjectStore_Reachable_Missing verifies unreadable objects go to the callback, which may let
+// the walk go on, and fail the walk without one.
+func TestObjectStore_Reachable_Missing(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath))
+
+	blob := NewBlob([]byte("content\n"))
+	file, _ := NewTreeEntry(ModeRegularFile, "file.txt", blob.Hash())
+	tree, _ := NewTree([]TreeEntry{*file})
+	lostParent := testutils.RandomHash()
+	commit, _ := NewCommit(tree.Hash(), lostParent, "orphaned", reachableTestAuthor)
+	storeObjects(t, store, blob, tree, commit)
+
+	var missing []string
+	found, err := store.Reachable([]string{commit.Hash()}, map[string]bool{}, func(hash string, err error) error {
+		if !errors.Is(err, ErrObjectNotFound) {
+			t.Errorf("Expected ErrObjectNotFound for %s, got %v", hash, err)
+		}
+		missing = append(missing, hash)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Reachable failed: %v", err)
+	}
+	if !slices.Equal(missing, []string{lostParent}) {
+		t.Errorf("Expected %s reported missing, got %v", lostParent, missing)
+	}
+	if len(found) != 3 || slices.Contains(found, lostParent) {
+		t.Errorf("Expected the commit, tree and blob, got %v", found)
+	}
+
+	_, err = store.Reachable([]string{commit.Hash()}, map[string]bool{}, nil)
+	if !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Expected ErrObjectNotFound, got %v", err)
+	}
+}
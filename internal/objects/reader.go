@@ -0,0 +1,140 @@
+package objects
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/utils"
+)
+
+// ObjectReader streams decompressed content of a stored object.
+// The header is consumed on open, so reads return content only. The object hash is
+// verified once content is fully read; a mismatch is reported instead of io.EOF.
+type ObjectReader struct {
+	objectType   utils.ObjectType
+	size         int64
+	hash         string
+	file         *os.File
+	decompressor io.ReadCloser
+	content      io.Reader // Content limited to declared size
+	hasher       hash.Hash // Running hash of header and content read so far
+	remaining    int64
+}
+
+// OpenObject opens a stored object for streaming without loading it into memory.
+// Caller must Close the returned reader.
+func (store *ObjectStore) OpenObject(hash string) (*ObjectReader, error) {
+	if len(hash) != constants.HashStringLength {
+		return nil, fmt.Errorf("invalid object hash %q", hash)
+	}
+	hash, err := store.replacement(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(store.objectPath(hash))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s: %w", ErrObjectNotFound, hash, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object file %s: %w", hash, err)
+	}
+
+	decompressor, err := newDecompressor(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	bufferedReader := bufio.NewReader(decompressor)
+	objectType, size, err := readObjectHeader(bufferedReader)
+	if err != nil {
+		decompressor.Close()
+		file.Close()
+		return nil, fmt.Errorf("invalid object %s: %w", hash, err)
+	}
+
+	hasher := sha1.New()
+	hasher.Write([]byte(utils.ObjectHeader(objectType, size)))
+
+	return &ObjectReader{
+		objectType:   objectType,
+		size:         size,
+		hash:         hash,
+		file:         file,
+		decompressor: decompressor,
+		content:      io.LimitReader(bufferedReader, size),
+		hasher:       hasher,
+		remaining:    size,
+	}, nil
+}
+
+// readObjectHeader parses "<type> <size>\0" from the start of decompressed object data.
+func readObjectHeader(reader *bufio.Reader) (utils.ObjectType, int64, error) {
+	header, err := reader.ReadString(constants.NullByte)
+	if err != nil {
+		return "", 0, fmt.Errorf("no null byte found in header")
+	}
+
+	typeName, sizeText, found := strings.Cut(strings.TrimSuffix(header, string(constants.NullByte)), " ")
+	if !found {
+		return "", 0, fmt.Errorf("malformed header %q", header)
+	}
+
+	objectType := utils.ObjectType(typeName)
+	if !objectType.IsValid() {
+		return "", 0, fmt.Errorf("unknown object type %q", typeName)
+	}
+
+	size, err := strconv.ParseInt(sizeText, 10, 64)
+	if err != nil || size < 0 {
+		return "", 0, fmt.Errorf("invalid object size %q", sizeText)
+	}
+
+	return objectType, size, nil
+}
+
+// Type returns the object type read from the header.
+func (r *ObjectReader) Type() utils.ObjectType {
+	return r.objectType
+}
+
+// Size returns the content size declared in the header.
+func (r *ObjectReader) Size() int64 {
+	return r.size
+}
+
+// Read reads decompressed content, verifying size and hash once all content is consumed.
+func (r *ObjectReader) Read(p []byte) (int, error) {
+	n, err := r.content.Read(p)
+	r.hasher.Write(p[:n])
+	r.remaining -= int64(n)
+
+	if errors.Is(err, io.EOF) {
+		if r.remaining > 0 {
+			return n, fmt.Errorf("object %s truncated: %d bytes missing", r.hash, r.remaining)
+		}
+		if actual := hex.EncodeToString(r.hasher.Sum(nil)); actual != r.hash {
+			return n, fmt.Errorf("%w: expected %s, got %s", ErrHashMismatch, r.hash, actual)
+		}
+	}
+
+	return n, err
+}
+
+// Close releases decompressor and underlying file.
+func (r *ObjectReader) Close() error {
+	decompressorErr := r.decompressor.Close()
+	fileErr := r.file.Close()
+	return errors.Join(decompressorErr, fileErr)
+}
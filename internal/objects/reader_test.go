@@ -0,0 +1,150 @@
+package objects
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+	"github.com/KostasZigo/gogit/utils"
+)
+
+// writeRawObject compresses raw object data and stores it under given hash, bypassing validation.
+func writeRawObject(t *testing.T, repoPath, hash string, data []byte) {
+	t.Helper()
+
+	var compressed bytes.Buffer
+	writer := zlib.NewWriter(&compressed)
+	writer.Write(data)
+	writer.Close()
+
+	objectPath := filepath.Join(repoPath, constants.Gogit, constants.Objects, hash[:constants.HashDirPrefixLength], hash[constants.HashDirPrefixLength:])
+	if err := os.MkdirAll(filepath.Dir(objectPath), constants.DirPerms); err != nil {
+		t.Fatalf("Failed to create object directory: %v", err)
+	}
+	if err := os.WriteFile(objectPath, compressed.Bytes(), constants.FilePerms); err != nil {
+		t.Fatalf("Failed to write raw object: %v", err)
+	}
+}
+
+// TestObjectStore_OpenObject verifies streamed read returns header info and content.
+func TestObjectStore_OpenObject(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath))
+	content := bytes.Repeat([]byte("stream me\n"), 5000)
+	blob := NewBlob(content)
+	if err := store.Store(blob); err != nil {
+		t.Fatalf("Failed to store blob: %v", err)
+	}
+
+	reader, err := store.OpenObject(blob.Hash())
+	if err != nil {
+		t.Fatalf("OpenObject failed: %v", err)
+	}
+	defer reader.Close()
+
+	if reader.Type() != utils.BlobObjectType {
+		t.Errorf("Expected type %s, got %s", utils.BlobObjectType, reader.Type())
+	}
+	if reader.Size() != int64(len(content)) {
+		t.Errorf("Expected size %d, got %d", len(content), reader.Size())
+	}
+
+	streamed, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Failed to read object: %v", err)
+	}
+	if !bytes.Equal(streamed, content) {
+		t.Error("Streamed content does not match stored content")
+	}
+}
+
+// TestObjectStore_OpenObject_Tree verifies non-blob objects report their type.
+func TestObjectStore_OpenObject_Tree(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath))
+	tree := createAndStoreTree(t, store, []TreeEntry{createTreeEntry(t, ModeRegularFile, "a.txt", testutils.RandomHash())})
+
+	reader, err := store.OpenObject(tree.Hash())
+	if err != nil {
+		t.Fatalf("OpenObject failed: %v", err)
+	}
+	defer reader.Close()
+
+	if reader.Type() != utils.TreeObjectType {
+		t.Errorf("Expected type %s, got %s", utils.TreeObjectType, reader.Type())
+	}
+}
+
+// TestObjectStore_OpenObject_NotFound verifies error for missing objects.
+func TestObjectStore_OpenObject_NotFound(t *testing.T) {
+	store := NewObjectStore(repository.GitDir(testutils.SetupTestRepoWithGogitDir(t)))
+
+	if _, err := store.OpenObject(testutils.RandomHash()); err == nil {
+		t.Fatal("Expected error for missing object")
+	}
+	if _, err := store.OpenObject("abc"); err == nil {
+		t.Fatal("Expected error for malformed hash")
+	}
+}
+
+// TestObjectStore_OpenObject_HashMismatch verifies corruption is reported at end of stream.
+func TestObjectStore_OpenObject_HashMismatch(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath))
+	hash := testutils.RandomHash()
+	writeRawObject(t, repoPath, hash, NewBlob([]byte("tampered")).Data())
+
+	reader, err := store.OpenObject(hash)
+	if err != nil {
+		t.Fatalf("OpenObject failed: %v", err)
+	}
+	defer reader.Close()
+
+	_, err = io.ReadAll(reader)
+	if !errors.Is(err, ErrHashMismatch) {
+		t.Fatalf("Expected hash mismatch error, got: %v", err)
+	}
+}
+
+// TestObjectStore_OpenObject_Truncated verifies content shorter than declared size is reported.
+func TestObjectStore_OpenObject_Truncated(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath))
+	hash := testutils.RandomHash()
+	writeRawObject(t, repoPath, hash, []byte("blob 100\x00short"))
+
+	reader, err := store.OpenObject(hash)
+	if err != nil {
+		t.Fatalf("OpenObject failed: %v", err)
+	}
+	defer reader.Close()
+
+	_, err = io.ReadAll(reader)
+	if err == nil || !strings.Contains(err.Error(), "truncated") {
+		t.Fatalf("Expected truncation error, got: %v", err)
+	}
+}
+
+// TestObjectStore_OpenObject_InvalidHeader verifies malformed headers are rejected on open.
+func TestObjectStore_OpenObject_InvalidHeader(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath))
+
+	headers := []string{"blob", "blob 12", "unknown 3\x00abc", "blob -1\x00", "blob x\x00"}
+	for _, header := range headers {
+		hash := testutils.RandomHash()
+		writeRawObject(t, repoPath, hash, []byte(header))
+
+		if _, err := store.OpenObject(hash); err == nil {
+			t.Errorf("Expected error opening object with header %q", header)
+		}
+	}
+}
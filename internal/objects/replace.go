@@ -0,0 +1,75 @@
+package objects
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/refs"
+)
+
+// maxReplaceDepth bounds chains of replacements so cycles fail instead of looping.
+const maxReplaceDepth = 5
+
+// replacements maps object hashes to the objects that stand in for them, loaded on first use.
+type replacements struct {
+	once    sync.Once
+	objects map[string]string
+	err     error
+}
+
+// WithReplaceObjects controls whether reads substitute objects named by refs/replace/,
+// which is enabled unless GOGIT_NO_REPLACE_OBJECTS is set.
+func WithReplaceObjects(enabled bool) StoreOption {
+	return func(store *ObjectStore) {
+		store.replaceObjects = enabled
+	}
+}
+
+// replaceObjectsDefault reports whether stores substitute replaced objects unless told otherwise.
+func replaceObjectsDefault() bool {
+	return os.Getenv(constants.NoReplaceObjectsEnv) == ""
+}
+
+// replacement returns the object read in place of hash: the end of its chain of replacements,
+// or hash itself when it is not replaced or replacements are disabled.
+func (store *ObjectStore) replacement(hash string) (string, error) {
+	if !store.replaceObjects {
+		return hash, nil
+	}
+
+	store.replacements.once.Do(func() {
+		store.replacements.objects, store.replacements.err = loadReplacements(store.gitDir)
+	})
+	if store.replacements.err != nil {
+		return "", store.replacements.err
+	}
+
+	for range maxReplaceDepth {
+		replaced, ok := store.replacements.objects[hash]
+		if !ok {
+			return hash, nil
+		}
+		hash = replaced
+	}
+	return "", fmt.Errorf("replace depth too high for object %s", hash)
+}
+
+// loadReplacements reads refs/replace/<hash> refs into a map from replaced to replacement object.
+// Refs not named after an object hash are ignored.
+func loadReplacements(gitDir string) (map[string]string, error) {
+	objects := make(map[string]string)
+	err := refs.ForEach(gitDir, constants.ReplaceRefPrefix, func(ref refs.Ref) error {
+		name := strings.TrimPrefix(ref.Name, constants.ReplaceRefPrefix)
+		if isHexString(name, constants.HashStringLength) {
+			objects[name] = ref.Hash
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read replace refs: %w", err)
+	}
+	return objects, nil
+}
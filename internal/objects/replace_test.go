@@ -0,0 +1,64 @@
+package objects
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/refs"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// TestObjectStore_ReplaceObjects verifies reads follow chains of replacements, that cycles
+// fail, and that replacements can be disabled by option or environment.
+func TestObjectStore_ReplaceObjects(t *testing.T) {
+	gitDir := repository.GitDir(testutils.SetupTestRepoWithGogitDir(t))
+	writer := NewObjectStore(gitDir)
+
+	var blobs []*Blob
+	for _, content := range []string{"first\n", "second\n", "third\n"} {
+		blob := NewBlob([]byte(content))
+		if err := writer.Store(blob); err != nil {
+			t.Fatalf("Failed to store blob: %v", err)
+		}
+		blobs = append(blobs, blob)
+	}
+	replace := func(object, replacement *Blob) {
+		t.Helper()
+		if err := refs.Update(gitDir, constants.ReplaceRefPrefix+object.Hash(), replacement.Hash()); err != nil {
+			t.Fatalf("Failed to write replace ref: %v", err)
+		}
+	}
+	replace(blobs[0], blobs[1])
+	replace(blobs[1], blobs[2])
+
+	blob, err := NewObjectStore(gitDir).ReadBlob(blobs[0].Hash())
+	if err != nil {
+		t.Fatalf("ReadBlob failed: %v", err)
+	}
+	assertBlobContent(t, blob, blobs[2].Content())
+
+	blob, err = NewObjectStore(gitDir, WithReplaceObjects(false)).ReadBlob(blobs[0].Hash())
+	if err != nil {
+		t.Fatalf("ReadBlob failed: %v", err)
+	}
+	assertBlobContent(t, blob, blobs[0].Content())
+
+	t.Setenv(constants.NoReplaceObjectsEnv, "1")
+	reader, err := NewObjectStore(gitDir).OpenObject(blobs[1].Hash())
+	if err != nil {
+		t.Fatalf("OpenObject failed: %v", err)
+	}
+	reader.Close()
+	if reader.Size() != int64(len("second\n")) {
+		t.Errorf("Expected the original object to be opened, got size %d", reader.Size())
+	}
+
+	t.Setenv(constants.NoReplaceObjectsEnv, "")
+	replace(blobs[2], blobs[0])
+	_, err = NewObjectStore(gitDir).ReadBlob(blobs[0].Hash())
+	if err == nil || !strings.Contains(err.Error(), "replace depth too high") {
+		t.Errorf("Expected replacement cycle to fail, got %v", err)
+	}
+}
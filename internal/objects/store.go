@@ -1,13 +1,18 @@
 package objects
 
 import (
+	"bufio"
 	"bytes"
 	"compress/zlib"
+	"crypto/sha1"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -19,56 +24,238 @@ import (
 
 // ObjectStore manages storage of Git objects
 type ObjectStore struct {
-	repoPath string // Path to repository root
+	gitDir           string         // Metadata directory holding objects/ and refs/
+	fsync            bool           // Flush object files to disk before renaming them into place
+	compressionLevel int            // Compression level for new objects, -1 for library default
+	encoding         ObjectEncoding // Encoding for new objects
+	cacheSize        int            // Maximum parsed trees and commits kept in memory
+	cache            *objectCache   // LRU cache of parsed objects, nil when disabled
+	replaceObjects   bool           // Read objects named by refs/replace/ in place of the ones they replace
+	replacements     replacements   // Replaced objects, loaded on first read
 }
 
-func NewObjectStore(repoPath string) *ObjectStore {
-	return &ObjectStore{
-		repoPath: repoPath,
+// StoreOption configures optional ObjectStore behavior.
+type StoreOption func(*ObjectStore)
+
+// WithFsync makes the store fsync every new object file before it becomes visible,
+// trading write speed for durability across power loss (core.fsyncObjectFiles).
+func WithFsync(enabled bool) StoreOption {
+	return func(store *ObjectStore) {
+		store.fsync = enabled
+	}
+}
+
+// NewObjectStore returns a store for the objects of the repository whose metadata directory is gitDir.
+func NewObjectStore(gitDir string, opts ...StoreOption) *ObjectStore {
+	store := &ObjectStore{
+		gitDir:           gitDir,
+		compressionLevel: zlib.DefaultCompression,
+		encoding:         EncodingZlib,
+		cacheSize:        constants.DefaultObjectCacheSize,
+		replaceObjects:   replaceObjectsDefault(),
+	}
+
+	for _, opt := range opts {
+		opt(store)
 	}
+
+	if store.cacheSize > 0 {
+		store.cache = newObjectCache(store.cacheSize)
+	}
+
+	return store
 }
 
 // Store saves a GoGit Object to .gogit/objects/<first 2 chars>/<rest>
-// Returns nil if object already exists
+// Content is written to a temporary file and renamed into place, so concurrent
+// writers are safe and a crash never leaves a truncated object behind.
+// An object that already exists is freshened instead of written again.
 func (store *ObjectStore) Store(obj Object) error {
 	hash := obj.Hash()
 
-	// Calculate object path: .gogit/objects/ab/cdef123...
-	objectPath := store.objectPath(hash)
-
 	// Check if object already exists (content-addressable)
-	_, err := os.Stat(objectPath)
-	if err == nil {
-		slog.Debug("Object with this hash already exists",
-			"hash", hash)
+	_, err := os.Stat(store.objectPath(hash))
+	if err == nil && store.freshen(hash) {
 		return nil
 	}
-	if !(errors.Is(err, fs.ErrNotExist)) {
+	if err != nil && !(errors.Is(err, fs.ErrNotExist)) {
 		return fmt.Errorf("failed to check object existence: %w", err)
 	}
 
-	// Create directory if it doesn't exist
-	objectDir := filepath.Dir(objectPath)
-	if err := os.MkdirAll(objectDir, constants.DirPerms); err != nil {
-		return fmt.Errorf("failed to create object directory: %w", err)
-	}
-
 	// Compress object content
 	compressedData, err := store.compressData(obj.Data())
 	if err != nil {
 		return fmt.Errorf("failed to compress object: %w", err)
 	}
 
-	// Write compressed object data to file
-	if err := os.WriteFile(objectPath, compressedData, constants.FilePerms); err != nil {
+	tempFile, err := store.createTempObject()
+	if err != nil {
+		return err
+	}
+
+	// Write compressed object data to temporary file
+	if _, err := tempFile.Write(compressedData); err != nil {
+		discardTempObject(tempFile)
 		return fmt.Errorf("failed to write object file: %w", err)
 	}
 
+	return store.commitTempObject(tempFile, hash)
+}
+
+// createTempObject creates a temporary file inside the objects directory.
+// Keeping it on the same filesystem as the final location makes the rename atomic.
+func (store *ObjectStore) createTempObject() (*os.File, error) {
+	tempFile, err := os.CreateTemp(store.objectsDir(), constants.TempObjectPattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary object file: %w", err)
+	}
+	return tempFile, nil
+}
+
+// commitTempObject flushes the temporary file and renames it to the object path for hash.
+// Final object files are read-only since objects are immutable.
+// The temporary file is removed on failure or if the object already exists, which is freshened.
+func (store *ObjectStore) commitTempObject(tempFile *os.File, hash string) error {
+	if store.fsync {
+		if err := tempFile.Sync(); err != nil {
+			discardTempObject(tempFile)
+			return fmt.Errorf("failed to sync object file: %w", err)
+		}
+	}
+
+	tempPath := tempFile.Name()
+	if err := tempFile.Close(); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to close temporary object file: %w", err)
+	}
+
+	if err := os.Chmod(tempPath, constants.ObjectFilePerms); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to set object file permissions: %w", err)
+	}
+
+	if store.Exists(hash) && store.freshen(hash) {
+		os.Remove(tempPath)
+		return nil
+	}
+
+	// Create directory if it doesn't exist
+	objectPath := store.objectPath(hash)
+	if err := os.MkdirAll(filepath.Dir(objectPath), constants.DirPerms); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to create object directory: %w", err)
+	}
+
+	if err := os.Rename(tempPath, objectPath); err != nil {
+		os.Remove(tempPath)
+
+		// A concurrent writer may have won the race; identical content makes that a success
+		if store.Exists(hash) {
+			return nil
+		}
+		return fmt.Errorf("failed to move object into place: %w", err)
+	}
+
 	return nil
 }
 
+// freshen sets the modification time of the existing object hash to now, telling prune it is in
+// use again. Reports false when that fails, in which case the object should be written anew.
+func (store *ObjectStore) freshen(hash string) bool {
+	now := time.Now()
+	if err := os.Chtimes(store.objectPath(hash), now, now); err != nil {
+		slog.Debug("Failed to freshen existing object",
+			"hash", hash, "error", err)
+		return false
+	}
+	slog.Debug("Object with this hash already exists, freshened",
+		"hash", hash)
+	return true
+}
+
+// discardTempObject closes and removes a temporary object file after a failed write.
+func discardTempObject(tempFile *os.File) {
+	tempFile.Close()
+	os.Remove(tempFile.Name())
+}
+
+// StoreStream writes an object of given type and size whose content is read from reader.
+// Content is hashed while being compressed into a temporary file inside the objects
+// directory, which is renamed into place once the hash is known. Memory use stays
+// constant regardless of object size. Returns the object hash.
+func (store *ObjectStore) StoreStream(objectType utils.ObjectType, size int64, reader io.Reader) (string, error) {
+	if !objectType.IsValid() {
+		return "", fmt.Errorf("invalid object type: %s", objectType)
+	}
+
+	tempFile, err := store.createTempObject()
+	if err != nil {
+		return "", err
+	}
+
+	hash, err := store.writeCompressedStream(tempFile, objectType, size, reader)
+	if err != nil {
+		discardTempObject(tempFile)
+		return "", err
+	}
+
+	if err := store.commitTempObject(tempFile, hash); err != nil {
+		return "", err
+	}
+
+	return hash, nil
+}
+
+// writeCompressedStream writes header and content to writer through the store's compressor
+// and returns the object hash.
+func (store *ObjectStore) writeCompressedStream(destination io.Writer, objectType utils.ObjectType, size int64, reader io.Reader) (string, error) {
+	compressor, err := store.newCompressor(destination)
+	if err != nil {
+		return "", err
+	}
+
+	hasher := sha1.New()
+	writer := io.MultiWriter(hasher, compressor)
+
+	if _, err := io.WriteString(writer, utils.ObjectHeader(objectType, size)); err != nil {
+		compressor.Close()
+		return "", fmt.Errorf("failed to write object header: %w", err)
+	}
+
+	written, err := io.Copy(writer, reader)
+	if err != nil {
+		compressor.Close()
+		return "", fmt.Errorf("failed to write object content: %w", err)
+	}
+	if written != size {
+		compressor.Close()
+		return "", fmt.Errorf("content size mismatch: expected %d bytes, read %d", size, written)
+	}
+
+	if err := compressor.Close(); err != nil {
+		return "", fmt.Errorf("failed to compress object: %w", err)
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// StoreBlobFile streams a file into the store as a blob and returns its hash.
+func (store *ObjectStore) StoreBlobFile(filepath string) (string, error) {
+	file, info, err := openBlobFile(filepath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	return store.StoreStream(utils.BlobObjectType, info.Size(), file)
+}
+
 // ReadBlob reads a blob from storage by hash
 func (store *ObjectStore) ReadBlob(hash string) (*Blob, error) {
+	hash, err := store.replacement(hash)
+	if err != nil {
+		return nil, err
+	}
 	data, err := store.readObject(hash)
 	if err != nil {
 		return nil, err
@@ -77,43 +264,193 @@ func (store *ObjectStore) ReadBlob(hash string) (*Blob, error) {
 	return parseBlobData(data, hash)
 }
 
-// ReadTree reads a tree from storage by hash
+// ReadTree reads a tree from storage by hash, serving repeated reads from the object cache
 func (store *ObjectStore) ReadTree(hash string) (*Tree, error) {
+	hash, err := store.replacement(hash)
+	if err != nil {
+		return nil, err
+	}
+	if cached, ok := store.cachedObject(hash); ok {
+		if tree, isTree := cached.(*Tree); isTree {
+			return tree, nil
+		}
+		return nil, fmt.Errorf("object %s is %w", hash, ErrNotATree)
+	}
+
 	data, err := store.readObject(hash)
 	if err != nil {
 		return nil, err
 	}
 
-	return parseTreeData(data, hash)
+	tree, err := parseTreeData(data, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	store.cacheObject(hash, tree)
+	return tree, nil
+}
+
+// FlattenTree returns non-directory entries of tree and its subtrees keyed by slash-separated path.
+func (store *ObjectStore) FlattenTree(treeHash string) (map[string]TreeEntry, error) {
+	entries := make(map[string]TreeEntry)
+	if err := store.flattenTree(treeHash, "", entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// flattenTree collects entries of tree under prefix, treating the empty tree as always present.
+func (store *ObjectStore) flattenTree(treeHash, prefix string, entries map[string]TreeEntry) error {
+	if treeHash == constants.EmptyTreeHash {
+		return nil
+	}
+
+	tree, err := store.ReadTree(treeHash)
+	if err != nil {
+		return fmt.Errorf("failed to read tree %s: %w", treeHash, err)
+	}
+
+	for _, entry := range tree.Entries() {
+		fullPath := path.Join(prefix, entry.Name())
+		if entry.IsDirectory() {
+			if err := store.flattenTree(entry.Hash(), fullPath, entries); err != nil {
+				return err
+			}
+			continue
+		}
+		entries[fullPath] = entry
+	}
+	return nil
 }
 
-// ReadCommit reads a commit from storage by hash
+// ReadCommit reads a commit from storage by hash, serving repeated reads from the object cache
 func (store *ObjectStore) ReadCommit(hash string) (*Commit, error) {
+	hash, err := store.replacement(hash)
+	if err != nil {
+		return nil, err
+	}
+	if cached, ok := store.cachedObject(hash); ok {
+		if commit, isCommit := cached.(*Commit); isCommit {
+			return commit, nil
+		}
+		return nil, fmt.Errorf("object %s is %w", hash, ErrNotACommit)
+	}
+
 	data, err := store.readObject(hash)
 	if err != nil {
 		return nil, err
 	}
 
-	return parseCommitData(data, hash)
+	commit, err := parseCommitData(data, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	store.cacheObject(hash, commit)
+	return commit, nil
 }
 
-// Exists checks if an object exists in storage
+// ReadTag reads an annotated tag from storage by hash
+func (store *ObjectStore) ReadTag(hash string) (*Tag, error) {
+	hash, err := store.replacement(hash)
+	if err != nil {
+		return nil, err
+	}
+	data, err := store.readObject(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseTagData(data, hash)
+}
+
+// ReadObject reads an object of any type, detecting its type from the header.
+// Returns the concrete *Blob, *Tree, *Commit or *Tag behind the Object interface.
+func (store *ObjectStore) ReadObject(hash string) (Object, utils.ObjectType, error) {
+	hash, err := store.replacement(hash)
+	if err != nil {
+		return nil, "", err
+	}
+	if cached, ok := store.cachedObject(hash); ok {
+		return cached, cachedObjectType(cached), nil
+	}
+
+	data, err := store.readObject(hash)
+	if err != nil {
+		return nil, "", err
+	}
+
+	objectType, _, err := readObjectHeader(bufio.NewReader(bytes.NewReader(data)))
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid object %s: %w", hash, err)
+	}
+
+	var obj Object
+	switch objectType {
+	case utils.BlobObjectType:
+		blob, err := parseBlobData(data, hash)
+		if err != nil {
+			return nil, "", err
+		}
+		return blob, objectType, nil
+	case utils.TagObjectType:
+		tag, err := parseTagData(data, hash)
+		if err != nil {
+			return nil, "", err
+		}
+		return tag, objectType, nil
+	case utils.TreeObjectType:
+		obj, err = parseTreeData(data, hash)
+	case utils.CommitObjectType:
+		obj, err = parseCommitData(data, hash)
+	default:
+		return nil, "", fmt.Errorf("unsupported object type %s for %s", objectType, hash)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	store.cacheObject(hash, obj)
+	return obj, objectType, nil
+}
+
+// cachedObjectType returns type of an object held in the cache, which only holds trees and commits.
+func cachedObjectType(obj Object) utils.ObjectType {
+	if _, isTree := obj.(*Tree); isTree {
+		return utils.TreeObjectType
+	}
+	return utils.CommitObjectType
+}
+
+// Exists checks if an object exists in storage. Anything other than a full hash does not, so
+// names taken from outside can never reach a path outside the objects directory.
 func (store *ObjectStore) Exists(hash string) bool {
+	if !isHexString(hash, constants.HashStringLength) {
+		return false
+	}
 	_, err := os.Stat(store.objectPath(hash))
 	return err == nil
 }
 
+// objectsDir returns the objects directory of the repository.
+func (store *ObjectStore) objectsDir() string {
+	return filepath.Join(store.gitDir, constants.Objects)
+}
+
 // objectPath constructs filesystem path for object hash.
 func (s *ObjectStore) objectPath(hash string) string {
-	return filepath.Join(s.repoPath, constants.Gogit, constants.Objects, hash[:constants.HashDirPrefixLength], hash[constants.HashDirPrefixLength:])
+	return filepath.Join(s.objectsDir(), hash[:constants.HashDirPrefixLength], hash[constants.HashDirPrefixLength:])
 }
 
-// compressData compresses byte slice using zlib.
+// compressData compresses byte slice using the store's configured encoding and level.
 func (store *ObjectStore) compressData(data []byte) ([]byte, error) {
-	// Compress with zlib
 	var buffer bytes.Buffer
 	// Crete a new writer that compresses and writes data to the buffer
-	writer := zlib.NewWriter(&buffer)
+	writer, err := store.newCompressor(&buffer)
+	if err != nil {
+		return nil, err
+	}
 
 	if _, err := writer.Write(data); err != nil {
 		writer.Close()
@@ -133,6 +470,9 @@ func (store *ObjectStore) compressData(data []byte) ([]byte, error) {
 func (store *ObjectStore) readObject(hash string) ([]byte, error) {
 	// Read compressed file
 	compressedData, err := os.ReadFile(store.objectPath(hash))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s: %w", ErrObjectNotFound, hash, err)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to read object file %s: %w", hash, err)
 	}
@@ -140,11 +480,11 @@ func (store *ObjectStore) readObject(hash string) ([]byte, error) {
 	return decompressData(compressedData)
 }
 
-// decompressData decompresses zlib-compressed byte slice.
+// decompressData decompresses zlib- or zstd-compressed byte slice.
 func decompressData(compressed []byte) ([]byte, error) {
-	reader, err := zlib.NewReader(bytes.NewReader(compressed))
+	reader, err := newDecompressor(bytes.NewReader(compressed))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create zlib reader: %w", err)
+		return nil, err
 	}
 	defer reader.Close()
 
@@ -160,7 +500,7 @@ func decompressData(compressed []byte) ([]byte, error) {
 func parseBlobData(data []byte, expectedHash string) (*Blob, error) {
 	// Verify object type is blob
 	if !bytes.HasPrefix(data, []byte(constants.BlobPrefix)) {
-		return nil, fmt.Errorf("object %s is not a blob", expectedHash)
+		return nil, fmt.Errorf("object %s is %w", expectedHash, ErrNotABlob)
 	}
 
 	// Find null byte separator (end of header)
@@ -177,7 +517,7 @@ func parseBlobData(data []byte, expectedHash string) (*Blob, error) {
 
 	// Verify hash matches
 	if blob.Hash() != expectedHash {
-		return nil, fmt.Errorf("hash mismatch: expected %s, got %s", expectedHash, blob.Hash())
+		return nil, fmt.Errorf("%w: expected %s, got %s", ErrHashMismatch, expectedHash, blob.Hash())
 	}
 
 	return blob, nil
@@ -187,7 +527,7 @@ func parseBlobData(data []byte, expectedHash string) (*Blob, error) {
 func parseTreeData(data []byte, expectedHash string) (*Tree, error) {
 	// Verify object type is tree
 	if !bytes.HasPrefix(data, []byte(constants.TreePrefix)) {
-		return nil, fmt.Errorf("object %s is not a tree", expectedHash)
+		return nil, fmt.Errorf("object %s is %w", expectedHash, ErrNotATree)
 	}
 
 	// Find null byte separator (end of header)
@@ -197,23 +537,31 @@ func parseTreeData(data []byte, expectedHash string) (*Tree, error) {
 	}
 
 	// Parse tree entries from binary content
-	entries, err := parseTreeEntries(data[nullByteIndex+1:])
+	content := data[nullByteIndex+1:]
+	entries, err := parseTreeEntries(content)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse tree entries: %w", err)
 	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("failed to create tree from entries: tree must contain at least one entry")
+	}
 
-	// Create tree from entries
-	tree, err := NewTree(entries)
+	// Hash the original bytes, so trees written by Git keep their identity
+	hash, err := utils.ComputeHash(content, utils.TreeObjectType)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create tree from entries: %w", err)
+		return nil, fmt.Errorf("failed to compute tree hash: %w", err)
 	}
 
 	// Verify hash matches
-	if tree.Hash() != expectedHash {
-		return nil, fmt.Errorf("hash mismatch: expected %s, got %s", expectedHash, tree.Hash())
+	if hash != expectedHash {
+		return nil, fmt.Errorf("%w: expected %s, got %s", ErrHashMismatch, expectedHash, hash)
 	}
 
-	return tree, nil
+	return &Tree{
+		entries: entries,
+		hash:    hash,
+		content: bytes.Clone(content),
+	}, nil
 }
 
 // parseTreeEntries parses binary tree content into a slice of TreeEntry
@@ -226,12 +574,14 @@ func parseTreeEntries(content []byte) ([]TreeEntry, error) {
 		// 1. Find space separator (between mode and name)
 		spaceIndex := bytes.IndexByte(content[offset:], ' ')
 		if spaceIndex == -1 {
-			// No more entries
-			break
+			return nil, fmt.Errorf("invalid tree entry: no space after mode")
 		}
 
-		// 2. Extract mode (e.g., "100644", "040000")
+		// 2. Extract mode (e.g., "100644", "040000"), accepting Git's "40000" for directories
 		mode := FileMode(content[offset : offset+spaceIndex])
+		if mode == gitDirectoryMode {
+			mode = ModeDirectory
+		}
 		offset += spaceIndex + 1
 
 		// 3. Find null byte (end of name)
@@ -267,7 +617,7 @@ func parseTreeEntries(content []byte) ([]TreeEntry, error) {
 // parseCommitData parses decompressed commit data and validates hash.
 func parseCommitData(data []byte, hash string) (*Commit, error) {
 	if !bytes.HasPrefix(data, []byte(constants.CommitPrefix)) {
-		return nil, fmt.Errorf("object %s is not a commit", hash)
+		return nil, fmt.Errorf("object %s is %w", hash, ErrNotACommit)
 	}
 
 	// Find end of header
@@ -282,31 +632,67 @@ func parseCommitData(data []byte, hash string) (*Commit, error) {
 	}
 
 	if hash != commit.Hash() {
-		return nil, fmt.Errorf("hash mismatch: expected %s , got %s", hash, commit.Hash())
+		return nil, fmt.Errorf("%w: expected %s, got %s", ErrHashMismatch, hash, commit.Hash())
 	}
 
 	return commit, nil
 }
 
+// parseTagData parses decompressed tag data and validates hash.
+func parseTagData(data []byte, hash string) (*Tag, error) {
+	if !bytes.HasPrefix(data, []byte(constants.TagPrefix)) {
+		return nil, fmt.Errorf("object %s is %w", hash, ErrNotATag)
+	}
+
+	nullByteIndex := bytes.IndexByte(data, constants.NullByte)
+	if nullByteIndex == -1 {
+		return nil, fmt.Errorf("invalid tag format: no null byte found")
+	}
+
+	tag, err := ParseTag(data[nullByteIndex+1:])
+	if err != nil {
+		return nil, err
+	}
+
+	if hash != tag.Hash() {
+		return nil, fmt.Errorf("%w: expected %s, got %s", ErrHashMismatch, hash, tag.Hash())
+	}
+
+	return tag, nil
+}
+
+// ParseCommit parses and validates raw commit content.
+func ParseCommit(content []byte) (*Commit, error) {
+	return parseCommitContent(string(content))
+}
+
 // parseCommitContent parses commit text content into Commit object.
+// Headers it does not recognize are kept verbatim, and the hash is computed
+// from the original content so commits imported from Git keep their identity.
 func parseCommitContent(content string) (*Commit, error) {
-	lines := strings.Split(content, "\n")
+	headers, message, _ := strings.Cut(content, "\n\n")
 
-	var treeHash, parentHash string
+	var treeHash, encoding string
+	var parents, extraHeaders []string
 	var author, committer Author
-	var messageIndex int
+	lastIsExtra := false
 
-	for i, line := range lines {
-		if line == "" { // this is the blank line separating the message
-			messageIndex = i + 1
-			break
+	for line := range strings.SplitSeq(headers, "\n") {
+		// Continuation lines of multi-line values (e.g. gpgsig) start with a space
+		if strings.HasPrefix(line, " ") {
+			if !lastIsExtra {
+				return nil, fmt.Errorf("invalid commit: unexpected continuation line %q", line)
+			}
+			extraHeaders[len(extraHeaders)-1] += "\n" + line
+			continue
 		}
+		lastIsExtra = false
 
 		switch {
 		case strings.HasPrefix(line, constants.TreePrefix):
 			treeHash = strings.TrimPrefix(line, constants.TreePrefix)
 		case strings.HasPrefix(line, constants.CommitParentPrefix):
-			parentHash = strings.TrimPrefix(line, constants.CommitParentPrefix)
+			parents = append(parents, strings.TrimPrefix(line, constants.CommitParentPrefix))
 		case strings.HasPrefix(line, constants.CommitAuthorPrefix):
 			var err error
 			author, err = parseAuthor(strings.TrimPrefix(line, constants.CommitAuthorPrefix))
@@ -319,6 +705,11 @@ func parseCommitContent(content string) (*Commit, error) {
 			if err != nil {
 				return nil, fmt.Errorf("failed to parse committer: %w", err)
 			}
+		case strings.HasPrefix(line, constants.CommitEncodingPrefix):
+			encoding = strings.TrimPrefix(line, constants.CommitEncodingPrefix)
+		case line != "":
+			extraHeaders = append(extraHeaders, line)
+			lastIsExtra = true
 		}
 	}
 
@@ -333,25 +724,23 @@ func parseCommitContent(content string) (*Commit, error) {
 		return nil, fmt.Errorf("commit missing committer")
 	}
 
-	// Extract message
-	message := strings.Join(lines[messageIndex:], "\n")
-	message = strings.TrimRight(message, "\n")
-
 	//Compute Hash
-	builtContent := buildCommitContent(treeHash, parentHash, message, author)
-	hash, err := utils.ComputeHash(builtContent, utils.CommitObjectType)
+	hash, err := utils.ComputeHash([]byte(content), utils.CommitObjectType)
 	if err != nil {
 		return nil, fmt.Errorf("failed to compute commit hash: %w", err)
 	}
 
 	// Create commit
 	return &Commit{
-		hash:       hash,
-		treeHash:   treeHash,
-		parentHash: parentHash,
-		author:     author,
-		committer:  committer,
-		message:    message,
+		hash:         hash,
+		treeHash:     treeHash,
+		parents:      parents,
+		author:       author,
+		committer:    committer,
+		message:      strings.TrimRight(message, "\n"),
+		encoding:     encoding,
+		extraHeaders: extraHeaders,
+		content:      []byte(content),
 	}, nil
 }
 
@@ -2,13 +2,22 @@ package objects
 
 import (
 	"bytes"
+	"encoding/hex"
 	"errors"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/repository"
 	"github.com/KostasZigo/gogit/testutils"
+	"github.com/KostasZigo/gogit/utils"
 )
 
 // BLOB STORAGE TESTS
@@ -16,7 +25,7 @@ import (
 // TestObjectStore_StoreBlob verifies blob storage creates correct file structure.
 func TestObjectStore_StoreBlob(t *testing.T) {
 	repoPath := testutils.SetupTestRepoWithGogitDir(t)
-	store := NewObjectStore(repoPath)
+	store := NewObjectStore(repository.GitDir(repoPath))
 	blob := NewBlob([]byte("test content\n"))
 
 	// Store the blob
@@ -34,7 +43,7 @@ func TestObjectStore_StoreBlob(t *testing.T) {
 // TestObjectStore_Compression verifies zlib compression reduces storage size.
 func TestObjectStore_Compression(t *testing.T) {
 	repoPath := testutils.SetupTestRepoWithGogitDir(t)
-	store := NewObjectStore(repoPath)
+	store := NewObjectStore(repository.GitDir(repoPath))
 
 	// Use larger content to ensure compression is effective
 	largeContent := bytes.Repeat([]byte("This is repeated content. "), 100)
@@ -88,7 +97,7 @@ func TestObjectStore_Compression(t *testing.T) {
 // TestObjectStore_StoreIdempotent verifies storing same blob twice is safe.
 func TestObjectStore_StoreIdempotent(t *testing.T) {
 	repoPath := testutils.SetupTestRepoWithGogitDir(t)
-	store := NewObjectStore(repoPath)
+	store := NewObjectStore(repository.GitDir(repoPath))
 	blob := NewBlob([]byte("test\n"))
 
 	// Store twice, second time a debug log should appear
@@ -118,7 +127,7 @@ func TestObjectStore_StoreIdempotent(t *testing.T) {
 // TestObjectStore_Exists verifies object existence detection.
 func TestObjectStore_Exists(t *testing.T) {
 	repoPath := testutils.SetupTestRepoWithGogitDir(t)
-	store := NewObjectStore(repoPath)
+	store := NewObjectStore(repository.GitDir(repoPath))
 	blob := NewBlob([]byte("test\n"))
 
 	// Should not exist initially
@@ -135,12 +144,19 @@ func TestObjectStore_Exists(t *testing.T) {
 	if !store.Exists(blob.Hash()) {
 		t.Error("Blob should exist after storing")
 	}
+
+	// Names other than full hashes never exist, even where they resolve to a file
+	for _, name := range []string{"a", "../config", strings.ToUpper(blob.Hash())} {
+		if store.Exists(name) {
+			t.Errorf("Expected %q not to exist", name)
+		}
+	}
 }
 
 // TestObjectStore_ReadNonExistentBlob verifies error for missing objects.
 func TestObjectStore_ReadNonExistentBlob(t *testing.T) {
 	repoPath := testutils.SetupTestRepoWithGogitDir(t)
-	store := NewObjectStore(repoPath)
+	store := NewObjectStore(repository.GitDir(repoPath))
 
 	// Try to read a non-existent hash
 	fakeHash := testutils.RandomHash()
@@ -150,8 +166,8 @@ func TestObjectStore_ReadNonExistentBlob(t *testing.T) {
 		t.Fatal("Expected error when reading non-existent object")
 	}
 
-	if !os.IsNotExist(errors.Unwrap(err)) {
-		t.Errorf("Expected file not found error, got: %v", err)
+	if !errors.Is(err, ErrObjectNotFound) || !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected object not found error, got: %v", err)
 	}
 }
 
@@ -160,7 +176,7 @@ func TestObjectStore_ReadNonExistentBlob(t *testing.T) {
 // TestObjectStore_StoreAndReadTree verifies tree storage with single entry.
 func TestObjectStore_StoreAndReadTree(t *testing.T) {
 	repoPath := testutils.SetupTestRepoWithGogitDir(t)
-	store := NewObjectStore(repoPath)
+	store := NewObjectStore(repository.GitDir(repoPath))
 
 	// Create a blob
 	blob := NewBlob([]byte("test content"))
@@ -202,7 +218,7 @@ func TestObjectStore_StoreAndReadTree(t *testing.T) {
 // TestObjectStore_ReadTree_MultipleEntries verifies tree with multiple files.
 func TestObjectStore_ReadTree_MultipleEntries(t *testing.T) {
 	repoPath := testutils.SetupTestRepoWithGogitDir(t)
-	store := NewObjectStore(repoPath)
+	store := NewObjectStore(repository.GitDir(repoPath))
 
 	// Create multiple blobs
 	blob1 := NewBlob([]byte("content 1\n"))
@@ -248,7 +264,7 @@ func TestObjectStore_ReadTree_MultipleEntries(t *testing.T) {
 // TestObjectStore_ReadTree_NestedTree verifies nested directory structure storage.
 func TestObjectStore_ReadTree_NestedTree(t *testing.T) {
 	repoPath := testutils.SetupTestRepoWithGogitDir(t)
-	store := NewObjectStore(repoPath)
+	store := NewObjectStore(repository.GitDir(repoPath))
 
 	// Create a blob
 	blob := NewBlob([]byte("nested content\n"))
@@ -312,6 +328,37 @@ func TestObjectStore_ReadTree_NestedTree(t *testing.T) {
 	assertTreeEntryEqual(t, nestedEntry, subTreeEntry)
 }
 
+// TestObjectStore_ReadTree_GitDirectoryMode verifies trees written by Git, with "40000" directory modes, keep their hash.
+func TestObjectStore_ReadTree_GitDirectoryMode(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath))
+
+	// Hash produced by "git mktree" for the same entries
+	const gitTreeHash = "6134ae20ce8bab82bc1a219831dae4dbc036e73d"
+	emptyTree, _ := hex.DecodeString("4b825dc642cb6eb9a060e54bf8d69288fbee4904")
+	emptyBlob, _ := hex.DecodeString("e69de29bb2d1d6434b8b29ae775ad8c2e48c5391")
+	content := slices.Concat([]byte("40000 dir\x00"), emptyTree, []byte("100644 file.txt\x00"), emptyBlob)
+
+	hash, err := store.StoreStream(utils.TreeObjectType, int64(len(content)), bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("Failed to store tree: %v", err)
+	}
+	if hash != gitTreeHash {
+		t.Fatalf("Expected hash %s, got %s", gitTreeHash, hash)
+	}
+
+	tree, err := store.ReadTree(hash)
+	if err != nil {
+		t.Fatalf("Failed to read tree: %v", err)
+	}
+	if !tree.Entries()[0].IsDirectory() {
+		t.Errorf("Expected first entry to be a directory, got mode %s", tree.Entries()[0].Mode())
+	}
+	if !bytes.Equal(tree.Content(), content) {
+		t.Errorf("Expected content to be kept verbatim")
+	}
+}
+
 // COMMIT STORAGE TESTS
 
 // TestParseAuthorLine verifies author metadata parsing from commit format.
@@ -360,8 +407,8 @@ Initial commit message
 		t.Errorf("Unexpected tree hash: %s", commit.treeHash)
 	}
 
-	if commit.parentHash != "abc123def456" {
-		t.Errorf("Unexpected parent hash: %s", commit.parentHash)
+	if commit.ParentHash() != "abc123def456" {
+		t.Errorf("Unexpected parent hash: %s", commit.ParentHash())
 	}
 
 	if commit.message != "Initial commit message" {
@@ -390,7 +437,7 @@ Initial commit message
 // TestObjectStore_StoreAndReadInitialCommit verifies initial commit storage and retrieval.
 func TestObjectStore_StoreAndReadInitialCommit(t *testing.T) {
 	repoPath := testutils.SetupTestRepoWithGogitDir(t)
-	store := NewObjectStore(repoPath)
+	store := NewObjectStore(repository.GitDir(repoPath))
 
 	commit := createAndStoreInitialCommit(t, store)
 
@@ -408,7 +455,7 @@ func TestObjectStore_StoreAndReadInitialCommit(t *testing.T) {
 // TestObjectStore_StoreAndReadCommit_WithParent verifies commit with parent storage.
 func TestObjectStore_StoreAndreadChildCommit_WithParent(t *testing.T) {
 	repoPath := testutils.SetupTestRepoWithGogitDir(t)
-	store := NewObjectStore(repoPath)
+	store := NewObjectStore(repository.GitDir(repoPath))
 
 	parentCommit := createAndStoreInitialCommit(t, store)
 	childCommit := createAndStoreCommit(t, parentCommit.Hash(), store)
@@ -420,12 +467,540 @@ func TestObjectStore_StoreAndreadChildCommit_WithParent(t *testing.T) {
 	}
 
 	// Verify
-	if readChildCommit.parentHash != parentCommit.Hash() {
+	if readChildCommit.ParentHash() != parentCommit.Hash() {
 		t.Errorf("Parent hash mismatch: expected %s, got %s",
-			parentCommit.Hash(), readChildCommit.parentHash)
+			parentCommit.Hash(), readChildCommit.ParentHash())
 	}
 	if readChildCommit.IsInitialCommit() {
 		t.Error("Child commit should not be initial commit")
 	}
 	assertCommitEqual(t, readChildCommit, childCommit)
 }
+
+// signedMergeCommit is a merge commit with encoding and gpgsig headers, hashed by real Git.
+const (
+	signedMergeCommit = "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n" +
+		"parent 1111111111111111111111111111111111111111\n" +
+		"parent 2222222222222222222222222222222222222222\n" +
+		"author Ada Lovelace <ada@example.com> 1698765432 +0100\n" +
+		"committer Charles Babbage <charles@example.com> 1698769032 -0230\n" +
+		"encoding ISO-8859-1\n" +
+		"gpgsig -----BEGIN PGP SIGNATURE-----\n" +
+		" \n" +
+		" iQEzBAABCAAdFiEE\n" +
+		" =abcd\n" +
+		" -----END PGP SIGNATURE-----\n" +
+		"\n" +
+		"Signed merge\n\nBody line\n"
+	signedMergeCommitHash = "16dcb46a8cdbbad126fc9823c011924c85c9ef9a"
+)
+
+// TestParseCommitContent_PreservesUnknownHeaders verifies extended headers survive parsing and keep Git's hash.
+func TestParseCommitContent_PreservesUnknownHeaders(t *testing.T) {
+	commit, err := parseCommitContent(signedMergeCommit)
+	if err != nil {
+		t.Fatalf("Failed to parse commit: %v", err)
+	}
+
+	if commit.Hash() != signedMergeCommitHash {
+		t.Errorf("Expected hash %s, got %s", signedMergeCommitHash, commit.Hash())
+	}
+	if string(commit.Content()) != signedMergeCommit {
+		t.Errorf("Content not preserved verbatim:\n%s", commit.Content())
+	}
+
+	expectedHeaders := []string{
+		"gpgsig -----BEGIN PGP SIGNATURE-----\n \n iQEzBAABCAAdFiEE\n =abcd\n -----END PGP SIGNATURE-----",
+	}
+	if !slices.Equal(commit.ExtraHeaders(), expectedHeaders) {
+		t.Errorf("Expected extra headers %q, got %q", expectedHeaders, commit.ExtraHeaders())
+	}
+
+	if commit.ParentHash() != "1111111111111111111111111111111111111111" {
+		t.Errorf("Expected first parent, got %s", commit.ParentHash())
+	}
+	expectedParents := []string{"1111111111111111111111111111111111111111", "2222222222222222222222222222222222222222"}
+	if !slices.Equal(commit.Parents(), expectedParents) {
+		t.Errorf("Expected parents %v, got %v", expectedParents, commit.Parents())
+	}
+	if commit.Encoding() != "ISO-8859-1" {
+		t.Errorf("Expected encoding ISO-8859-1, got %q", commit.Encoding())
+	}
+	if commit.Committer().Name != "Charles Babbage" {
+		t.Errorf("Expected committer Charles Babbage, got %q", commit.Committer().Name)
+	}
+	if commit.Message() != "Signed merge\n\nBody line" {
+		t.Errorf("Unexpected message: %q", commit.Message())
+	}
+}
+
+// TestParseCommitContent_OrphanContinuationLine verifies continuation lines must follow an extended header.
+func TestParseCommitContent_OrphanContinuationLine(t *testing.T) {
+	content := "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n" +
+		"author A <a@example.com> 1698765432 +0000\n" +
+		" dangling\n" +
+		"committer A <a@example.com> 1698765432 +0000\n\nmsg\n"
+
+	if _, err := parseCommitContent(content); err == nil {
+		t.Fatal("Expected error for continuation line after author header")
+	}
+}
+
+// TestObjectStore_ReadCommit_PreservesUnknownHeaders verifies imported commits read back with their original hash.
+func TestObjectStore_ReadCommit_PreservesUnknownHeaders(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath))
+
+	hash, err := store.StoreStream(utils.CommitObjectType, int64(len(signedMergeCommit)), strings.NewReader(signedMergeCommit))
+	if err != nil {
+		t.Fatalf("Failed to store commit: %v", err)
+	}
+	if hash != signedMergeCommitHash {
+		t.Fatalf("Expected hash %s, got %s", signedMergeCommitHash, hash)
+	}
+
+	commit, err := store.ReadCommit(hash)
+	if err != nil {
+		t.Fatalf("Failed to read commit: %v", err)
+	}
+	if commit.Hash() != hash || string(commit.Content()) != signedMergeCommit {
+		t.Errorf("Commit did not round-trip: hash %s, content %q", commit.Hash(), commit.Content())
+	}
+}
+
+// GENERIC READ TESTS
+
+// TestObjectStore_ReadObject verifies object type is detected and concrete object returned.
+func TestObjectStore_ReadObject(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath))
+
+	blob := NewBlob([]byte("generic read\n"))
+	if err := store.Store(blob); err != nil {
+		t.Fatalf("Failed to store blob: %v", err)
+	}
+	tree := createAndStoreTree(t, store, []TreeEntry{
+		createTreeEntry(t, ModeRegularFile, "file.txt", blob.Hash()),
+	})
+	commit := createAndStoreInitialCommit(t, store)
+
+	tests := []struct {
+		name         string
+		hash         string
+		expectedType utils.ObjectType
+	}{
+		{"blob", blob.Hash(), utils.BlobObjectType},
+		{"tree", tree.Hash(), utils.TreeObjectType},
+		{"commit", commit.Hash(), utils.CommitObjectType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj, objectType, err := store.ReadObject(tt.hash)
+			if err != nil {
+				t.Fatalf("ReadObject failed: %v", err)
+			}
+			if objectType != tt.expectedType {
+				t.Errorf("Expected type %s, got %s", tt.expectedType, objectType)
+			}
+			if obj.Hash() != tt.hash {
+				t.Errorf("Expected hash %s, got %s", tt.hash, obj.Hash())
+			}
+
+			switch concrete := obj.(type) {
+			case *Blob:
+				assertBlobContent(t, concrete, blob.Content())
+			case *Tree:
+				assertTreeEntryEqual(t, concrete.Entries()[0], tree.Entries()[0])
+			case *Commit:
+				assertCommitEqual(t, concrete, commit)
+			default:
+				t.Fatalf("Unexpected concrete type %T", obj)
+			}
+		})
+	}
+}
+
+// TestObjectStore_ReadObject_Errors verifies missing and malformed objects are rejected.
+func TestObjectStore_ReadObject_Errors(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath))
+
+	if _, _, err := store.ReadObject(testutils.RandomHash()); err == nil {
+		t.Error("Expected error for missing object")
+	}
+
+	badTypeHash := testutils.RandomHash()
+	writeRawObject(t, repoPath, badTypeHash, []byte("bogus 4\x00data"))
+	if _, _, err := store.ReadObject(badTypeHash); err == nil || !strings.Contains(err.Error(), "unknown object type") {
+		t.Errorf("Expected unknown object type error, got %v", err)
+	}
+
+	mismatchHash := testutils.RandomHash()
+	writeRawObject(t, repoPath, mismatchHash, []byte("blob 4\x00data"))
+	if _, _, err := store.ReadObject(mismatchHash); !errors.Is(err, ErrHashMismatch) {
+		t.Errorf("Expected hash mismatch error, got %v", err)
+	}
+}
+
+// STREAMING STORAGE TESTS
+
+// assertNoTempObjects verifies no temporary object files remain in objects directory.
+func assertNoTempObjects(t *testing.T, repoPath string) {
+	t.Helper()
+
+	matches, err := filepath.Glob(filepath.Join(repoPath, constants.Gogit, constants.Objects, constants.TempObjectPattern))
+	if err != nil {
+		t.Fatalf("Failed to glob temporary objects: %v", err)
+	}
+	if len(matches) != 0 {
+		t.Errorf("Expected no temporary object files, found %v", matches)
+	}
+}
+
+// TestObjectStore_StoreStream verifies streamed objects match in-memory hashing and read back.
+func TestObjectStore_StoreStream(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath))
+	content := bytes.Repeat([]byte("streamed content\n"), 1000)
+
+	hash, err := store.StoreStream(utils.BlobObjectType, int64(len(content)), bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("StoreStream failed: %v", err)
+	}
+
+	expected := NewBlob(content)
+	if hash != expected.Hash() {
+		t.Fatalf("Expected hash %s, got %s", expected.Hash(), hash)
+	}
+
+	blob, err := store.ReadBlob(hash)
+	if err != nil {
+		t.Fatalf("Failed to read streamed blob: %v", err)
+	}
+	assertBlobContent(t, blob, content)
+	assertNoTempObjects(t, repoPath)
+}
+
+// TestObjectStore_StoreStream_SizeMismatch verifies short content is rejected without leaving files behind.
+func TestObjectStore_StoreStream_SizeMismatch(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath))
+	content := []byte("short")
+
+	_, err := store.StoreStream(utils.BlobObjectType, 100, bytes.NewReader(content))
+	if err == nil {
+		t.Fatal("Expected size mismatch error")
+	}
+	if !strings.Contains(err.Error(), "size mismatch") {
+		t.Errorf("Expected size mismatch error, got: %v", err)
+	}
+
+	if store.Exists(NewBlob(content).Hash()) {
+		t.Error("Expected no object to be stored on failure")
+	}
+	assertNoTempObjects(t, repoPath)
+}
+
+// TestObjectStore_StoreStream_Existing verifies storing existing object keeps single copy.
+func TestObjectStore_StoreStream_Existing(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath))
+	content := []byte("duplicate")
+
+	for range 2 {
+		if _, err := store.StoreStream(utils.BlobObjectType, int64(len(content)), bytes.NewReader(content)); err != nil {
+			t.Fatalf("StoreStream failed: %v", err)
+		}
+	}
+
+	if !store.Exists(NewBlob(content).Hash()) {
+		t.Error("Expected object to exist")
+	}
+	assertNoTempObjects(t, repoPath)
+}
+
+// TestObjectStore_Store_FreshensExisting verifies writing an object that already exists moves its
+// modification time forward, by either write path, so prune sees it is in use.
+func TestObjectStore_Store_FreshensExisting(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath))
+	blob := NewBlob([]byte("old object"))
+	if err := store.Store(blob); err != nil {
+		t.Fatalf("Failed to store blob: %v", err)
+	}
+
+	writes := map[string]func() error{
+		"Store": func() error { return store.Store(blob) },
+		"StoreStream": func() error {
+			_, err := store.StoreStream(utils.BlobObjectType, int64(len(blob.Content())), bytes.NewReader(blob.Content()))
+			return err
+		},
+	}
+	for name, write := range writes {
+		old := time.Now().Add(-30 * 24 * time.Hour)
+		if err := os.Chtimes(store.objectPath(blob.Hash()), old, old); err != nil {
+			t.Fatalf("Failed to age object: %v", err)
+		}
+
+		if err := write(); err != nil {
+			t.Fatalf("%s failed: %v", name, err)
+		}
+		modTime, err := store.ModTime(blob.Hash())
+		if err != nil {
+			t.Fatalf("Failed to get modification time: %v", err)
+		}
+		if time.Since(modTime) > time.Hour {
+			t.Errorf("Expected %s to freshen the object, modification time is %v", name, modTime)
+		}
+	}
+	assertNoTempObjects(t, repoPath)
+}
+
+// TestObjectStore_StoreBlobFile verifies files are streamed into the store as blobs.
+func TestObjectStore_StoreBlobFile(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath))
+	content := []byte("file content\n")
+	path := testutils.CreateTestFile(t, repoPath, "file.txt", content)
+
+	hash, err := store.StoreBlobFile(path)
+	if err != nil {
+		t.Fatalf("StoreBlobFile failed: %v", err)
+	}
+
+	blob, err := store.ReadBlob(hash)
+	if err != nil {
+		t.Fatalf("Failed to read blob: %v", err)
+	}
+	assertBlobContent(t, blob, content)
+}
+
+// ATOMIC WRITE TESTS
+
+// TestObjectStore_Store_ReadOnlyAndNoTempFiles verifies final objects are read-only and temp files are cleaned up.
+func TestObjectStore_Store_ReadOnlyAndNoTempFiles(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath))
+	blob := NewBlob([]byte("immutable\n"))
+
+	if err := store.Store(blob); err != nil {
+		t.Fatalf("Failed to store blob: %v", err)
+	}
+
+	info, err := os.Stat(store.objectPath(blob.Hash()))
+	if err != nil {
+		t.Fatalf("Failed to stat object: %v", err)
+	}
+	if info.Mode().Perm() != constants.ObjectFilePerms {
+		t.Errorf("Expected permissions %v, got %v", constants.ObjectFilePerms, info.Mode().Perm())
+	}
+	assertNoTempObjects(t, repoPath)
+}
+
+// TestObjectStore_Store_Concurrent verifies concurrent writers of the same object all succeed.
+func TestObjectStore_Store_Concurrent(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath))
+	blob := NewBlob(bytes.Repeat([]byte("concurrent "), 1000))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for range 10 {
+		wg.Go(func() {
+			errs <- store.Store(blob)
+		})
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Concurrent store failed: %v", err)
+		}
+	}
+
+	readBlob, err := store.ReadBlob(blob.Hash())
+	if err != nil {
+		t.Fatalf("Failed to read blob: %v", err)
+	}
+	assertBlobContent(t, readBlob, blob.Content())
+	assertNoTempObjects(t, repoPath)
+}
+
+// TestObjectStore_WithFsync verifies durable store writes readable objects.
+func TestObjectStore_WithFsync(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath), WithFsync(true))
+	blob := NewBlob([]byte("durable\n"))
+
+	if err := store.Store(blob); err != nil {
+		t.Fatalf("Failed to store blob: %v", err)
+	}
+	if _, err := store.ReadBlob(blob.Hash()); err != nil {
+		t.Fatalf("Failed to read blob: %v", err)
+	}
+}
+
+// TestObjectStore_Store_MissingObjectsDir verifies failure leaves no partial object.
+func TestObjectStore_Store_MissingObjectsDir(t *testing.T) {
+	store := NewObjectStore(repository.GitDir(t.TempDir()))
+	blob := NewBlob([]byte("nowhere\n"))
+
+	if err := store.Store(blob); err == nil {
+		t.Fatal("Expected error when objects directory is missing")
+	}
+	if store.Exists(blob.Hash()) {
+		t.Error("Expected no object after failed store")
+	}
+}
+
+// COMPRESSION TESTS
+
+// TestObjectStore_CompressionLevel verifies higher levels produce smaller objects that read back identically.
+func TestObjectStore_CompressionLevel(t *testing.T) {
+	content := bytes.Repeat([]byte("compressible content "), 500)
+	sizes := make(map[int]int64)
+
+	for _, level := range []int{0, 9} {
+		repoPath := testutils.SetupTestRepoWithGogitDir(t)
+		store := NewObjectStore(repository.GitDir(repoPath), WithCompressionLevel(level))
+		blob := NewBlob(content)
+
+		if err := store.Store(blob); err != nil {
+			t.Fatalf("Failed to store blob at level %d: %v", level, err)
+		}
+
+		info, err := os.Stat(store.objectPath(blob.Hash()))
+		if err != nil {
+			t.Fatalf("Failed to stat object: %v", err)
+		}
+		sizes[level] = info.Size()
+
+		readBlob, err := store.ReadBlob(blob.Hash())
+		if err != nil {
+			t.Fatalf("Failed to read blob stored at level %d: %v", level, err)
+		}
+		if !bytes.Equal(readBlob.Content(), content) {
+			t.Errorf("Content mismatch at level %d", level)
+		}
+	}
+
+	if sizes[9] >= sizes[0] {
+		t.Errorf("Expected level 9 (%d bytes) to be smaller than level 0 (%d bytes)", sizes[9], sizes[0])
+	}
+}
+
+// TestObjectStore_CompressionLevel_Invalid verifies out-of-range levels are rejected on write.
+func TestObjectStore_CompressionLevel_Invalid(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath), WithCompressionLevel(10))
+
+	err := store.Store(NewBlob([]byte("content")))
+	if err == nil || !strings.Contains(err.Error(), "invalid compression level") {
+		t.Fatalf("Expected invalid compression level error, got %v", err)
+	}
+
+	_, err = store.StoreStream(utils.BlobObjectType, 7, strings.NewReader("content"))
+	if err == nil || !strings.Contains(err.Error(), "invalid compression level") {
+		t.Fatalf("Expected invalid compression level error from StoreStream, got %v", err)
+	}
+	assertNoTempObjects(t, repoPath)
+}
+
+// TestObjectStore_ZstdEncoding verifies zstd objects are written with zstd framing and read back transparently.
+func TestObjectStore_ZstdEncoding(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath), WithObjectEncoding(EncodingZstd))
+	content := []byte("zstd encoded content\n")
+	blob := NewBlob(content)
+
+	if err := store.Store(blob); err != nil {
+		t.Fatalf("Failed to store blob: %v", err)
+	}
+
+	raw, err := os.ReadFile(store.objectPath(blob.Hash()))
+	if err != nil {
+		t.Fatalf("Failed to read object file: %v", err)
+	}
+	if !bytes.HasPrefix(raw, zstdMagic) {
+		t.Fatalf("Expected zstd magic prefix, got %x", raw[:4])
+	}
+
+	readBlob, err := store.ReadBlob(blob.Hash())
+	if err != nil {
+		t.Fatalf("Failed to read zstd blob: %v", err)
+	}
+	if !bytes.Equal(readBlob.Content(), content) {
+		t.Errorf("Expected content %q, got %q", content, readBlob.Content())
+	}
+
+	reader, err := store.OpenObject(blob.Hash())
+	if err != nil {
+		t.Fatalf("Failed to open zstd object: %v", err)
+	}
+	defer reader.Close()
+
+	streamed, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Failed to stream zstd object: %v", err)
+	}
+	if !bytes.Equal(streamed, content) {
+		t.Errorf("Expected streamed content %q, got %q", content, streamed)
+	}
+}
+
+// TestObjectStore_MixedEncodings verifies a store reads zlib and zstd objects side by side.
+func TestObjectStore_MixedEncodings(t *testing.T) {
+	gitDir := repository.GitDir(testutils.SetupTestRepoWithGogitDir(t))
+	zlibStore := NewObjectStore(gitDir)
+	zstdStore := NewObjectStore(gitDir, WithObjectEncoding(EncodingZstd))
+
+	zlibHash, err := zlibStore.StoreStream(utils.BlobObjectType, 5, strings.NewReader("zlib\n"))
+	if err != nil {
+		t.Fatalf("Failed to store zlib object: %v", err)
+	}
+	zstdHash, err := zstdStore.StoreStream(utils.BlobObjectType, 5, strings.NewReader("zstd\n"))
+	if err != nil {
+		t.Fatalf("Failed to store zstd object: %v", err)
+	}
+
+	for hash, expected := range map[string]string{zlibHash: "zlib\n", zstdHash: "zstd\n"} {
+		blob, err := zlibStore.ReadBlob(hash)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", hash, err)
+		}
+		if string(blob.Content()) != expected {
+			t.Errorf("Expected %q, got %q", expected, blob.Content())
+		}
+	}
+}
+
+// ERROR TESTS
+
+// TestObjectStore_TypedErrors verifies reading objects with the wrong type returns matching sentinels.
+func TestObjectStore_TypedErrors(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath), WithCacheSize(0))
+	blob := NewBlob([]byte("typed errors"))
+	if err := store.Store(blob); err != nil {
+		t.Fatalf("Failed to store blob: %v", err)
+	}
+	commit := createAndStoreInitialCommit(t, store)
+
+	if _, err := store.ReadTree(blob.Hash()); !errors.Is(err, ErrNotATree) {
+		t.Errorf("Expected ErrNotATree, got %v", err)
+	}
+	if _, err := store.ReadCommit(blob.Hash()); !errors.Is(err, ErrNotACommit) {
+		t.Errorf("Expected ErrNotACommit, got %v", err)
+	}
+	if _, err := store.ReadBlob(commit.Hash()); !errors.Is(err, ErrNotABlob) {
+		t.Errorf("Expected ErrNotABlob, got %v", err)
+	}
+	if _, err := store.OpenObject(testutils.RandomHash()); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Expected ErrObjectNotFound from OpenObject, got %v", err)
+	}
+	if _, err := store.ResolvePrefix("abcd"); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Expected ErrObjectNotFound from ResolvePrefix, got %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package objects
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/utils"
+)
+
+// Tag represents an annotated tag object pointing at another object
+type Tag struct {
+	hash       string
+	object     string
+	objectType utils.ObjectType
+	name       string
+	tagger     Author
+	message    string
+	content    []byte // Raw content, kept verbatim so parsed tags keep their hash
+}
+
+// NewTag creates annotated tag for object of given type.
+func NewTag(objectHash string, objectType utils.ObjectType, name string, tagger Author, message string) (*Tag, error) {
+	if message != "" && !strings.HasSuffix(message, "\n") {
+		message += "\n"
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s%s\n", constants.TagObjectPrefix, objectHash)
+	fmt.Fprintf(&buf, "%s%s\n", constants.TagTypePrefix, objectType)
+	fmt.Fprintf(&buf, "%s%s\n", constants.TagPrefix, name)
+	fmt.Fprintf(&buf, "%s%s\n", constants.TagTaggerPrefix, tagger.Identity())
+	buf.WriteByte('\n')
+	buf.WriteString(message)
+
+	return ParseTag(buf.Bytes())
+}
+
+// ParseTag parses and validates raw tag content.
+// Headers must appear in Git's order: object, type, tag, tagger, then a blank line and the message.
+func ParseTag(content []byte) (*Tag, error) {
+	text := string(content)
+	headers, message, found := strings.Cut(text, "\n\n")
+	if !found {
+		if !strings.HasSuffix(text, "\n") {
+			return nil, fmt.Errorf("invalid tag: headers must end with a newline")
+		}
+		headers = strings.TrimSuffix(text, "\n")
+	}
+
+	lines := strings.Split(headers, "\n")
+	prefixes := []string{constants.TagObjectPrefix, constants.TagTypePrefix, constants.TagPrefix, constants.TagTaggerPrefix}
+	if len(lines) < len(prefixes) {
+		return nil, fmt.Errorf("invalid tag: expected %d header lines, got %d", len(prefixes), len(lines))
+	}
+
+	values := make([]string, len(prefixes))
+	for i, prefix := range prefixes {
+		value, ok := strings.CutPrefix(lines[i], prefix)
+		if !ok {
+			return nil, fmt.Errorf("invalid tag: expected %q header on line %d", strings.TrimSpace(prefix), i+1)
+		}
+		values[i] = value
+	}
+
+	objectHash, objectType, name := values[0], utils.ObjectType(values[1]), values[2]
+	if len(objectHash) != constants.HashStringLength || !isHexString(objectHash, constants.HashStringLength) {
+		return nil, fmt.Errorf("invalid tag: bad object hash %q", objectHash)
+	}
+	if !objectType.IsValid() {
+		return nil, fmt.Errorf("invalid tag: unknown object type %q", objectType)
+	}
+	if name == "" || strings.ContainsAny(name, " \t") {
+		return nil, fmt.Errorf("invalid tag: bad tag name %q", name)
+	}
+
+	tagger, err := parseAuthor(values[3])
+	if err != nil {
+		return nil, fmt.Errorf("invalid tag: bad tagger: %w", err)
+	}
+
+	hash, err := utils.ComputeHash(content, utils.TagObjectType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute tag hash: %w", err)
+	}
+
+	return &Tag{
+		hash:       hash,
+		object:     objectHash,
+		objectType: objectType,
+		name:       name,
+		tagger:     tagger,
+		message:    message,
+		content:    bytes.Clone(content),
+	}, nil
+}
+
+func (t *Tag) Hash() string {
+	return t.hash
+}
+
+func (t *Tag) Content() []byte {
+	return t.content
+}
+
+func (t *Tag) Size() int {
+	return len(t.content)
+}
+
+func (t *Tag) Header() string {
+	return fmt.Sprintf("%s%d%c", constants.TagPrefix, t.Size(), constants.NullByte)
+}
+
+// Data returns complete Git object data including header.
+func (t *Tag) Data() []byte {
+	return append([]byte(t.Header()), t.content...)
+}
+
+// Object returns hash of the tagged object.
+func (t *Tag) Object() string {
+	return t.object
+}
+
+// ObjectType returns type of the tagged object.
+func (t *Tag) ObjectType() utils.ObjectType {
+	return t.objectType
+}
+
+func (t *Tag) Name() string {
+	return t.name
+}
+
+func (t *Tag) Tagger() Author {
+	return t.tagger
+}
+
+func (t *Tag) Message() string {
+	return t.message
+}
+
+// Subject returns first paragraph of the message joined into a single line.
+func (t *Tag) Subject() string {
+	return messageSubject(t.message)
+}
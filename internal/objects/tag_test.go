@@ -0,0 +1,133 @@
+package objects
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+	"github.com/KostasZigo/gogit/utils"
+)
+
+// TestNewTag verifies tag content follows Git's format and hash matches content.
+func TestNewTag(t *testing.T) {
+	objectHash := testutils.RandomHash()
+	tagger := Author{Name: "A U Thor", Email: "a@b.c", Timestamp: time.Unix(1700000000, 0).In(time.FixedZone("", 3600))}
+
+	tag, err := NewTag(objectHash, utils.CommitObjectType, "v1.0", tagger, "Release 1.0")
+	if err != nil {
+		t.Fatalf("NewTag failed: %v", err)
+	}
+
+	expectedContent := "object " + objectHash + "\n" +
+		"type commit\n" +
+		"tag v1.0\n" +
+		"tagger A U Thor <a@b.c> 1700000000 +0100\n" +
+		"\n" +
+		"Release 1.0\n"
+	if string(tag.Content()) != expectedContent {
+		t.Errorf("Expected content:\n%s\ngot:\n%s", expectedContent, tag.Content())
+	}
+
+	expectedHash := utils.MustComputeHash([]byte(expectedContent), utils.TagObjectType)
+	if tag.Hash() != expectedHash {
+		t.Errorf("Expected hash %s, got %s", expectedHash, tag.Hash())
+	}
+	if tag.Object() != objectHash || tag.ObjectType() != utils.CommitObjectType || tag.Name() != "v1.0" {
+		t.Errorf("Unexpected tag fields: object=%s type=%s name=%s", tag.Object(), tag.ObjectType(), tag.Name())
+	}
+	if !tag.Tagger().Timestamp.Equal(tagger.Timestamp) || tag.Message() != "Release 1.0\n" {
+		t.Errorf("Unexpected tagger or message: %v %q", tag.Tagger(), tag.Message())
+	}
+}
+
+// TestParseTag_Errors verifies malformed tag content is rejected.
+func TestParseTag_Errors(t *testing.T) {
+	hash := testutils.RandomHash()
+	tagger := "tagger A <a@b.c> 1700000000 +0000\n"
+
+	tests := []struct {
+		name          string
+		content       string
+		expectedError string
+	}{
+		{"missing headers", "object " + hash + "\n", "expected 4 header lines"},
+		{"wrong order", "type blob\nobject " + hash + "\ntag v1\n" + tagger, `expected "object" header`},
+		{"bad hash", "object xyz\ntype blob\ntag v1\n" + tagger, "bad object hash"},
+		{"bad type", "object " + hash + "\ntype bogus\ntag v1\n" + tagger, "unknown object type"},
+		{"bad name", "object " + hash + "\ntype blob\ntag v 1\n" + tagger, "bad tag name"},
+		{"bad tagger", "object " + hash + "\ntype blob\ntag v1\ntagger nobody\n", "bad tagger"},
+		{"no trailing newline", "object " + hash + "\ntype blob\ntag v1\n" + strings.TrimSuffix(tagger, "\n"), "must end with a newline"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTag([]byte(tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+// TestObjectStore_StoreAndReadTag verifies tags round-trip through the store and ReadObject.
+func TestObjectStore_StoreAndReadTag(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithGogitDir(t)
+	store := NewObjectStore(repository.GitDir(repoPath))
+	tag, err := NewTag(testutils.RandomHash(), utils.BlobObjectType, "v1", createTestAuthor("Tagger", "t@example.com"), "")
+	if err != nil {
+		t.Fatalf("NewTag failed: %v", err)
+	}
+	if err := store.Store(tag); err != nil {
+		t.Fatalf("Failed to store tag: %v", err)
+	}
+
+	readTag, err := store.ReadTag(tag.Hash())
+	if err != nil {
+		t.Fatalf("ReadTag failed: %v", err)
+	}
+	if string(readTag.Content()) != string(tag.Content()) {
+		t.Errorf("Expected content %q, got %q", tag.Content(), readTag.Content())
+	}
+
+	obj, objectType, err := store.ReadObject(tag.Hash())
+	if err != nil || objectType != utils.TagObjectType {
+		t.Fatalf("Expected tag from ReadObject, got type %q and error %v", objectType, err)
+	}
+	if _, ok := obj.(*Tag); !ok {
+		t.Errorf("Expected *Tag, got %T", obj)
+	}
+
+	if _, err := store.ReadBlob(tag.Hash()); !errors.Is(err, ErrNotABlob) {
+		t.Errorf("Expected ErrNotABlob, got %v", err)
+	}
+}
+
+// TestValidateContent verifies raw content is checked against its declared type.
+func TestValidateContent(t *testing.T) {
+	blobHash := NewBlob([]byte("x")).Hash()
+	tree := createTree(t, []TreeEntry{createTreeEntry(t, ModeRegularFile, "x", blobHash)})
+
+	valid := map[utils.ObjectType][]byte{
+		utils.BlobObjectType:   []byte("anything at all"),
+		utils.TreeObjectType:   tree.Content(),
+		utils.CommitObjectType: []byte("tree " + tree.Hash() + "\nauthor A <a@b.c> 1 +0000\ncommitter A <a@b.c> 1 +0000\n\nmsg\n"),
+		utils.TagObjectType:    []byte("object " + blobHash + "\ntype blob\ntag v1\ntagger A <a@b.c> 1 +0000\n"),
+	}
+	for objectType, content := range valid {
+		if err := ValidateContent(objectType, content); err != nil {
+			t.Errorf("Expected valid %s, got %v", objectType, err)
+		}
+	}
+
+	for _, objectType := range []utils.ObjectType{utils.TreeObjectType, utils.CommitObjectType, utils.TagObjectType} {
+		if err := ValidateContent(objectType, []byte("garbage\x00")); err == nil {
+			t.Errorf("Expected invalid %s content to be rejected", objectType)
+		}
+	}
+	if err := ValidateContent("bogus", nil); err == nil {
+		t.Error("Expected unknown type to be rejected")
+	}
+}
@@ -0,0 +1,119 @@
+package objects
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Trailer is a "Key: value" line at the end of a commit message, such as Signed-off-by.
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+// String formats trailer in Git's normalized form.
+func (t Trailer) String() string {
+	return fmt.Sprintf("%s: %s", t.Key, t.Value)
+}
+
+// ParseTrailer parses "Key: value" or "Key=value" into a trailer.
+// Accepts "=" so command-line arguments can use either separator, as Git does.
+func ParseTrailer(text string) (Trailer, error) {
+	separator := strings.IndexAny(text, ":=")
+	if separator == -1 {
+		return Trailer{}, fmt.Errorf("invalid trailer %q: missing separator", text)
+	}
+
+	key := strings.TrimSpace(text[:separator])
+	if !isTrailerKey(key) {
+		return Trailer{}, fmt.Errorf("invalid trailer %q: bad key %q", text, key)
+	}
+
+	return Trailer{Key: key, Value: strings.TrimSpace(text[separator+1:])}, nil
+}
+
+// Trailers returns trailers found in the final paragraph of the commit message.
+func (c *Commit) Trailers() []Trailer {
+	_, trailers := SplitTrailers(c.message)
+	return trailers
+}
+
+// SplitTrailers separates message into body and trailing trailer block.
+// The last paragraph is a trailer block only when it follows the subject and every line
+// is a trailer or an indented continuation of one; otherwise no trailers are returned.
+func SplitTrailers(message string) (string, []Trailer) {
+	message = strings.TrimRight(message, "\n")
+
+	blockStart := strings.LastIndex(message, "\n\n")
+	if blockStart == -1 {
+		return message, nil
+	}
+
+	var trailers []Trailer
+	for line := range strings.SplitSeq(message[blockStart+2:], "\n") {
+		// Folded values continue on lines starting with whitespace
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			if len(trailers) == 0 {
+				return message, nil
+			}
+			trailers[len(trailers)-1].Value += " " + strings.TrimSpace(line)
+			continue
+		}
+
+		separator := strings.IndexByte(line, ':')
+		if separator == -1 || !isTrailerKey(line[:separator]) {
+			return message, nil
+		}
+		trailers = append(trailers, Trailer{
+			Key:   line[:separator],
+			Value: strings.TrimSpace(line[separator+1:]),
+		})
+	}
+
+	return message[:blockStart], trailers
+}
+
+// AddTrailers appends trailers to message, joining an existing trailer block when present.
+// A trailer identical to the one it would follow is skipped, matching Git's default.
+// Existing trailers are rewritten in normalized "Key: value" form.
+func AddTrailers(message string, add ...Trailer) string {
+	body, trailers := SplitTrailers(message)
+
+	for _, trailer := range add {
+		if n := len(trailers); n > 0 && strings.EqualFold(trailers[n-1].Key, trailer.Key) && trailers[n-1].Value == trailer.Value {
+			continue
+		}
+		trailers = append(trailers, trailer)
+	}
+
+	var builder strings.Builder
+	builder.WriteString(body)
+	if len(trailers) > 0 {
+		if body != "" {
+			builder.WriteString("\n\n")
+		}
+		for i, trailer := range trailers {
+			if i > 0 {
+				builder.WriteByte('\n')
+			}
+			builder.WriteString(trailer.String())
+		}
+	}
+	if builder.Len() > 0 {
+		builder.WriteByte('\n')
+	}
+	return builder.String()
+}
+
+// isTrailerKey reports whether key is a non-empty token of letters, digits and dashes.
+func isTrailerKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if !(r == '-' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
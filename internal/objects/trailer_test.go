@@ -0,0 +1,112 @@
+package objects
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestSplitTrailers verifies only a final paragraph made entirely of trailers is recognized.
+func TestSplitTrailers(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		body     string
+		trailers []Trailer
+	}{
+		{
+			name:     "trailer block",
+			message:  "Fix bug\n\nDetails.\n\nSigned-off-by: Ada <ada@example.com>\nCo-authored-by: Bob <bob@example.com>\n",
+			body:     "Fix bug\n\nDetails.",
+			trailers: []Trailer{{"Signed-off-by", "Ada <ada@example.com>"}, {"Co-authored-by", "Bob <bob@example.com>"}},
+		},
+		{
+			name:     "folded value",
+			message:  "Subject\n\nNote: first\n  second",
+			body:     "Subject",
+			trailers: []Trailer{{"Note", "first second"}},
+		},
+		{
+			name:    "subject only",
+			message: "Fix: crash on startup\n",
+			body:    "Fix: crash on startup",
+		},
+		{
+			name:    "prose paragraph",
+			message: "Subject\n\nThis explains: why\nand how.",
+			body:    "Subject\n\nThis explains: why\nand how.",
+		},
+		{
+			name:    "key with space",
+			message: "Subject\n\nSee also: other",
+			body:    "Subject\n\nSee also: other",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, trailers := SplitTrailers(tt.message)
+			if body != tt.body {
+				t.Errorf("Expected body %q, got %q", tt.body, body)
+			}
+			if !slices.Equal(trailers, tt.trailers) {
+				t.Errorf("Expected trailers %v, got %v", tt.trailers, trailers)
+			}
+		})
+	}
+}
+
+// TestAddTrailers verifies trailers join existing block, start a new one, and skip identical neighbors.
+func TestAddTrailers(t *testing.T) {
+	signOff := Trailer{"Signed-off-by", "Ada <ada@example.com>"}
+
+	tests := []struct {
+		name     string
+		message  string
+		expected string
+	}{
+		{"new block", "Subject\n", "Subject\n\nSigned-off-by: Ada <ada@example.com>\n"},
+		{"existing block normalized", "Subject\n\nReviewed-by:Bob\n", "Subject\n\nReviewed-by: Bob\nSigned-off-by: Ada <ada@example.com>\n"},
+		{"identical neighbor", "Subject\n\nSigned-off-by: Ada <ada@example.com>\n", "Subject\n\nSigned-off-by: Ada <ada@example.com>\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AddTrailers(tt.message, signOff); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestParseTrailer verifies both separators are accepted and malformed trailers rejected.
+func TestParseTrailer(t *testing.T) {
+	for _, text := range []string{"Acked-by: Ada", "Acked-by=Ada", "Acked-by :Ada"} {
+		trailer, err := ParseTrailer(text)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v", text, err)
+		}
+		if trailer != (Trailer{"Acked-by", "Ada"}) {
+			t.Errorf("Unexpected trailer for %q: %v", text, trailer)
+		}
+	}
+
+	for _, text := range []string{"no separator", ": value", "Bad key: value"} {
+		if _, err := ParseTrailer(text); err == nil {
+			t.Errorf("Expected error for %q", text)
+		}
+	}
+}
+
+// TestCommit_Trailers verifies trailers are read from commit message.
+func TestCommit_Trailers(t *testing.T) {
+	author := createTestAuthor("Test User", "test@example.com")
+	commit, err := NewInitialCommit("tree123", "Subject\n\nSigned-off-by: Test User <test@example.com>", author)
+	if err != nil {
+		t.Fatalf("Failed to create commit: %v", err)
+	}
+
+	expected := []Trailer{{"Signed-off-by", "Test User <test@example.com>"}}
+	if !slices.Equal(commit.Trailers(), expected) {
+		t.Errorf("Expected trailers %v, got %v", expected, commit.Trailers())
+	}
+}
@@ -20,6 +20,9 @@ const (
 	ModeSymlink     FileMode = "120000" // Symbolic link
 	ModeDirectory   FileMode = "040000" // Directory (tree)
 	ModeSubmodule   FileMode = "160000" // Git submodule
+
+	// gitDirectoryMode is how Git itself writes directory modes, without the leading zero.
+	gitDirectoryMode FileMode = "40000"
 )
 
 // IsValid verifies file mode matches Git specification.
@@ -81,6 +84,7 @@ func (treeEntry *TreeEntry) IsExecutable() bool {
 type Tree struct {
 	entries []TreeEntry
 	hash    string
+	content []byte // Raw content, kept verbatim so parsed trees keep their hash
 }
 
 // NewTree creates a tree object from the list of Tree Entries
@@ -104,6 +108,7 @@ func NewTree(treeEntries []TreeEntry) (*Tree, error) {
 	return &Tree{
 		entries: entries,
 		hash:    hash,
+		content: treeContent,
 	}, nil
 }
 
@@ -125,16 +130,21 @@ func compareTreeEntries(a, b TreeEntry) int {
 	return strings.Compare(nameA, nameB)
 }
 
-// buildTreeContent creates the raw tree content in GoGit format
+// buildTreeContent creates the raw tree content in Git format
 // <mode> <name>\0<20-byte binary SHA> , ex:
 // 100644 README.md\0[binary SHA for README blob]
 // 100644 main.go\0[binary SHA for main.go blob]
-// 040000 src\0[binary SHA for src/ tree]
+// 40000 src\0[binary SHA for src/ tree]
+// Directory modes are written without the leading zero, as Git does, so trees hash identically.
 func buildTreeContent(entries []TreeEntry) []byte {
 	var buf bytes.Buffer
 
 	for _, entry := range entries {
-		buf.WriteString(string(entry.Mode()))
+		mode := entry.Mode()
+		if mode == ModeDirectory {
+			mode = gitDirectoryMode
+		}
+		buf.WriteString(string(mode))
 		buf.WriteByte(' ')
 		buf.WriteString(entry.Name())
 		buf.WriteByte(0)
@@ -160,11 +170,11 @@ func (t *Tree) Entries() []TreeEntry {
 }
 
 func (t *Tree) Size() int {
-	return len(buildTreeContent(t.entries))
+	return len(t.content)
 }
 
 func (t *Tree) Content() []byte {
-	return buildTreeContent(t.entries)
+	return t.content
 }
 
 // Header returns the Git object header
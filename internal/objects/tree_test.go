@@ -1,6 +1,7 @@
 package objects
 
 import (
+	"bytes"
 	"strings"
 	"testing"
 
@@ -117,6 +118,26 @@ func TestNewTree_SortsEntries(t *testing.T) {
 
 }
 
+// TestTree_DirectoryModeMatchesGit verifies directories are written as "40000" so nested trees hash as in Git.
+func TestTree_DirectoryModeMatchesGit(t *testing.T) {
+	subTree := createTree(t, []TreeEntry{
+		createTreeEntry(t, ModeRegularFile, "b", NewBlob([]byte("x\n")).Hash()),
+	})
+	rootTree := createTree(t, []TreeEntry{
+		createTreeEntry(t, ModeRegularFile, "a", NewBlob([]byte("hi\n")).Hash()),
+		createTreeEntry(t, ModeDirectory, "d", subTree.Hash()),
+	})
+
+	// Hash of the same tree written by git
+	expectedHash := "bd925dfe4a912c27e4beb218958cf1b0aa58d15f"
+	if rootTree.Hash() != expectedHash {
+		t.Errorf("Expected hash %s, got %s", expectedHash, rootTree.Hash())
+	}
+	if !bytes.Contains(rootTree.Content(), []byte("40000 d\x00")) || bytes.Contains(rootTree.Content(), []byte("040000")) {
+		t.Errorf("Expected directory mode 40000 in content %q", rootTree.Content())
+	}
+}
+
 // TestTree_NestedStructure verifies tree with nested directory structure.
 func TestTree_NestedStructure(t *testing.T) {
 	// Create blobs for files
@@ -0,0 +1,68 @@
+package objects
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+)
+
+// ErrStopWalk can be returned from a WalkCommits callback to end the walk without error.
+var ErrStopWalk = errors.New("stop walk")
+
+// WalkCommits calls fn for each commit reachable from starts through every parent, newest
+// committer date first as Git walks history. Commits with equal dates keep the order they were
+// reached in, and commits shared by several starting points are visited once.
+// The walk ends at the first error, when ctx is cancelled, or when fn returns ErrStopWalk, in
+// which case WalkCommits returns nil.
+func (store *ObjectStore) WalkCommits(ctx context.Context, starts []string, fn func(*Commit) error) error {
+	var pending []*Commit
+	queued := make(map[string]bool)
+	enqueue := func(hash string) error {
+		if queued[hash] {
+			return nil
+		}
+		queued[hash] = true
+
+		commit, err := store.ReadCommit(hash)
+		if err != nil {
+			return err
+		}
+		date := commit.Committer().Timestamp
+		i, _ := slices.BinarySearchFunc(pending, date, func(other *Commit, date time.Time) int {
+			if other.Committer().Timestamp.Before(date) {
+				return 1
+			}
+			return -1
+		})
+		pending = slices.Insert(pending, i, commit)
+		return nil
+	}
+
+	for _, start := range starts {
+		if err := enqueue(start); err != nil {
+			return err
+		}
+	}
+
+	for len(pending) > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		commit := pending[0]
+		pending = pending[1:]
+
+		if err := fn(commit); err != nil {
+			if errors.Is(err, ErrStopWalk) {
+				return nil
+			}
+			return err
+		}
+		for _, parent := range commit.Parents() {
+			if err := enqueue(parent); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
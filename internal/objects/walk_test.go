@@ -0,0 +1,57 @@
+package objects
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// storeDatedCommit stores a commit on the empty tree committed at unix time and returns its hash.
+func storeDatedCommit(t *testing.T, store *ObjectStore, parent string, unix int64) string {
+	t.Helper()
+
+	author := Author{Name: "A", Email: "a@example.com", Timestamp: time.Unix(unix, 0).UTC()}
+	commit, err := NewCommit(constants.EmptyTreeHash, parent, "commit", author)
+	if err != nil {
+		t.Fatalf("Failed to create commit: %v", err)
+	}
+	if err := store.Store(commit); err != nil {
+		t.Fatalf("Failed to store commit: %v", err)
+	}
+	return commit.Hash()
+}
+
+// TestObjectStore_WalkCommits verifies commits from several starts are visited once, newest
+// committed first, and that ErrStopWalk ends the walk without error.
+func TestObjectStore_WalkCommits(t *testing.T) {
+	store := NewObjectStore(repository.GitDir(testutils.SetupTestRepoWithGogitDir(t)))
+	root := storeDatedCommit(t, store, "", 100)
+	older := storeDatedCommit(t, store, root, 200)
+	newer := storeDatedCommit(t, store, root, 300)
+
+	var visited []string
+	err := store.WalkCommits(context.Background(), []string{older, newer, older}, func(commit *Commit) error {
+		visited = append(visited, commit.Hash())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkCommits failed: %v", err)
+	}
+	if expected := []string{newer, older, root}; !slices.Equal(visited, expected) {
+		t.Errorf("Expected walk %v, got %v", expected, visited)
+	}
+
+	visited = nil
+	err = store.WalkCommits(context.Background(), []string{newer}, func(commit *Commit) error {
+		visited = append(visited, commit.Hash())
+		return ErrStopWalk
+	})
+	if err != nil || len(visited) != 1 {
+		t.Errorf("Expected walk to stop after one commit, got %v and error %v", visited, err)
+	}
+}
@@ -0,0 +1,92 @@
+package pack
+
+import (
+	"errors"
+	"fmt"
+)
+
+// errTruncatedDelta is returned when a delta ends in the middle of a size or instruction.
+var errTruncatedDelta = errors.New("delta is truncated")
+
+// ApplyDelta rebuilds an object from its delta base and a delta. A delta starts with the
+// sizes of base and result, followed by instructions that either copy a range of the base
+// or insert the bytes that follow them.
+func ApplyDelta(base, delta []byte) ([]byte, error) {
+	pos := 0
+	baseSize, err := deltaSize(delta, &pos)
+	if err != nil {
+		return nil, err
+	}
+	if baseSize != len(base) {
+		return nil, fmt.Errorf("delta expects a base of %d bytes, got %d", baseSize, len(base))
+	}
+	resultSize, err := deltaSize(delta, &pos)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]byte, 0, resultSize)
+	for pos < len(delta) {
+		op := delta[pos]
+		pos++
+
+		switch {
+		case op&0x80 != 0:
+			// Copy: bits 0-3 select offset bytes and bits 4-6 size bytes, least significant first
+			var offset, size int
+			for i := range 7 {
+				if op&(1<<i) == 0 {
+					continue
+				}
+				if pos >= len(delta) {
+					return nil, errTruncatedDelta
+				}
+				if i < 4 {
+					offset |= int(delta[pos]) << (8 * i)
+				} else {
+					size |= int(delta[pos]) << (8 * (i - 4))
+				}
+				pos++
+			}
+			if size == 0 {
+				size = 0x10000
+			}
+			if offset+size > len(base) {
+				return nil, fmt.Errorf("delta copies bytes %d-%d beyond the %d byte base", offset, offset+size, len(base))
+			}
+			result = append(result, base[offset:offset+size]...)
+		case op != 0:
+			// Insert: the opcode is the number of literal bytes that follow
+			size := int(op)
+			if pos+size > len(delta) {
+				return nil, errTruncatedDelta
+			}
+			result = append(result, delta[pos:pos+size]...)
+			pos += size
+		default:
+			return nil, errors.New("delta uses reserved opcode 0")
+		}
+	}
+
+	if len(result) != resultSize {
+		return nil, fmt.Errorf("delta produced %d bytes, expected %d", len(result), resultSize)
+	}
+	return result, nil
+}
+
+// deltaSize reads a little-endian base-128 size from delta at *pos and advances past it.
+func deltaSize(delta []byte, pos *int) (int, error) {
+	size, shift := 0, 0
+	for {
+		if *pos >= len(delta) {
+			return 0, errTruncatedDelta
+		}
+		c := delta[*pos]
+		*pos++
+		size |= int(c&0x7f) << shift
+		shift += 7
+		if c&0x80 == 0 {
+			return size, nil
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package pack
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestApplyDelta verifies copy and insert instructions and rejects malformed deltas.
+func TestApplyDelta(t *testing.T) {
+	base := []byte("hello world\n")
+
+	tests := []struct {
+		name     string
+		delta    []byte
+		expected string
+		err      string
+	}{
+		{
+			name:     "copy and insert",
+			delta:    append([]byte{12, 12, 0x90, 6, 6}, "there\n"...),
+			expected: "hello there\n",
+		},
+		{
+			name:     "copy with offset",
+			delta:    []byte{12, 6, 0x91, 6, 6},
+			expected: "world\n",
+		},
+		{name: "wrong base size", delta: []byte{11, 0}, err: "expects a base of 11 bytes"},
+		{name: "copy beyond base", delta: []byte{12, 6, 0x91, 8, 6}, err: "beyond the 12 byte base"},
+		{name: "truncated insert", delta: []byte{12, 3, 3, 'a'}, err: "truncated"},
+		{name: "reserved opcode", delta: []byte{12, 1, 0}, err: "reserved opcode"},
+		{name: "wrong result size", delta: []byte{12, 7, 0x90, 6}, err: "produced 6 bytes, expected 7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ApplyDelta(base, tt.delta)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyDelta failed: %v", err)
+			}
+			if string(result) != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
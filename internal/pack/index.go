@@ -0,0 +1,184 @@
+package pack
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+)
+
+// indexSignature starts a version 2 pack index, followed by its version.
+var indexSignature = []byte{0xff, 't', 'O', 'c'}
+
+const (
+	indexVersion = 2
+	fanoutSize   = 256 * 4
+
+	// largeOffsetFlag marks a 4-byte offset as an index into the table of 8-byte offsets.
+	largeOffsetFlag = 1 << 31
+)
+
+// IndexEntry locates one object in a pack.
+type IndexEntry struct {
+	Hash   string
+	Offset int64
+	CRC32  uint32
+}
+
+// Index is a parsed pack index.
+type Index struct {
+	Entries      []IndexEntry // Objects sorted by name
+	PackChecksum string       // Hex checksum of the pack the index describes
+}
+
+// WriteIndex writes a version 2 index of pack to w and returns the index's own checksum.
+func WriteIndex(w io.Writer, pack *Pack) (string, error) {
+	entries := slices.Clone(pack.Entries)
+	slices.SortFunc(entries, func(a, b Entry) int {
+		return strings.Compare(a.Hash, b.Hash)
+	})
+
+	hasher := sha1.New()
+	writer := io.MultiWriter(w, hasher)
+	var buf bytes.Buffer
+	buf.Write(indexSignature)
+	buf.Write(binary.BigEndian.AppendUint32(nil, indexVersion))
+
+	var fanout [256]uint32
+	names := make([][]byte, len(entries))
+	for i, entry := range entries {
+		name, err := hex.DecodeString(entry.Hash)
+		if err != nil || len(name) != sha1.Size {
+			return "", fmt.Errorf("invalid object name %q", entry.Hash)
+		}
+		names[i] = name
+		fanout[name[0]]++
+	}
+	var total uint32
+	for _, count := range fanout {
+		total += count
+		buf.Write(binary.BigEndian.AppendUint32(nil, total))
+	}
+
+	for _, name := range names {
+		buf.Write(name)
+	}
+	for _, entry := range entries {
+		buf.Write(binary.BigEndian.AppendUint32(nil, entry.CRC32))
+	}
+
+	var largeOffsets []int64
+	for _, entry := range entries {
+		offset := uint32(entry.Offset)
+		if entry.Offset >= largeOffsetFlag {
+			offset = largeOffsetFlag | uint32(len(largeOffsets))
+			largeOffsets = append(largeOffsets, entry.Offset)
+		}
+		buf.Write(binary.BigEndian.AppendUint32(nil, offset))
+	}
+	for _, offset := range largeOffsets {
+		buf.Write(binary.BigEndian.AppendUint64(nil, uint64(offset)))
+	}
+
+	checksum, err := hex.DecodeString(pack.Checksum)
+	if err != nil {
+		return "", fmt.Errorf("invalid pack checksum %q", pack.Checksum)
+	}
+	buf.Write(checksum)
+
+	if _, err := writer.Write(buf.Bytes()); err != nil {
+		return "", err
+	}
+	sum := hasher.Sum(nil)
+	if _, err := w.Write(sum); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sum), nil
+}
+
+// ReadIndex parses a version 2 pack index, verifying its layout and checksum.
+func ReadIndex(data []byte) (*Index, error) {
+	minSize := len(indexSignature) + 4 + fanoutSize + 2*sha1.Size
+	if len(data) < minSize || !bytes.Equal(data[:len(indexSignature)], indexSignature) {
+		return nil, errors.New("not a version 2 pack index: bad signature")
+	}
+	if version := binary.BigEndian.Uint32(data[4:8]); version != indexVersion {
+		return nil, fmt.Errorf("pack index version %d unsupported", version)
+	}
+
+	trailer := len(data) - sha1.Size
+	if sum := sha1.Sum(data[:trailer]); !bytes.Equal(sum[:], data[trailer:]) {
+		return nil, errors.New("pack index is corrupted (SHA1 mismatch)")
+	}
+
+	fanout := data[8 : 8+fanoutSize]
+	count := int(binary.BigEndian.Uint32(fanout[fanoutSize-4:]))
+	names := 8 + fanoutSize
+	crcs := names + count*sha1.Size
+	offsets := crcs + count*4
+	largeOffsets := offsets + count*4
+	if count > math.MaxInt32 || largeOffsets+sha1.Size > trailer {
+		return nil, errors.New("pack index is truncated")
+	}
+	largeCount := (trailer - sha1.Size - largeOffsets) / 8
+
+	index := &Index{
+		Entries:      make([]IndexEntry, count),
+		PackChecksum: hex.EncodeToString(data[trailer-sha1.Size : trailer]),
+	}
+	for i := range count {
+		name := data[names+i*sha1.Size:]
+		if i > 0 && bytes.Compare(data[names+(i-1)*sha1.Size:names+i*sha1.Size], name[:sha1.Size]) >= 0 {
+			return nil, errors.New("pack index is not sorted by object name")
+		}
+
+		offset := int64(binary.BigEndian.Uint32(data[offsets+i*4:]))
+		if offset&largeOffsetFlag != 0 {
+			large := int(offset &^ largeOffsetFlag)
+			if large >= largeCount {
+				return nil, errors.New("pack index has an out of range large offset")
+			}
+			offset = int64(binary.BigEndian.Uint64(data[largeOffsets+large*8:]))
+		}
+		index.Entries[i] = IndexEntry{
+			Hash:   entryName(name),
+			Offset: offset,
+			CRC32:  binary.BigEndian.Uint32(data[crcs+i*4:]),
+		}
+	}
+	return index, nil
+}
+
+// Verify checks that index describes pack: the same objects at the same offsets with the
+// same checksums, for the pack with the recorded checksum.
+func (index *Index) Verify(pack *Pack) error {
+	if index.PackChecksum != pack.Checksum {
+		return fmt.Errorf("packfile checksum %s does not match index checksum %s", pack.Checksum, index.PackChecksum)
+	}
+	if len(index.Entries) != len(pack.Entries) {
+		return fmt.Errorf("index has %d objects, pack has %d", len(index.Entries), len(pack.Entries))
+	}
+
+	byHash := make(map[string]Entry, len(pack.Entries))
+	for _, entry := range pack.Entries {
+		byHash[entry.Hash] = entry
+	}
+	for _, indexed := range index.Entries {
+		entry, ok := byHash[indexed.Hash]
+		switch {
+		case !ok:
+			return fmt.Errorf("object %s is indexed but not in the pack", indexed.Hash)
+		case entry.Offset != indexed.Offset:
+			return fmt.Errorf("object %s is at offset %d, index says %d", indexed.Hash, entry.Offset, indexed.Offset)
+		case entry.CRC32 != indexed.CRC32:
+			return fmt.Errorf("packed object %s (stored at %d) is corrupt", indexed.Hash, entry.Offset)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package pack
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/testutils"
+	"github.com/KostasZigo/gogit/utils"
+)
+
+// parseTestPack builds and parses a pack of the given blob contents.
+func parseTestPack(t *testing.T, contents ...string) *Pack {
+	t.Helper()
+
+	var entries []testutils.PackEntry
+	for _, content := range contents {
+		entries = append(entries, testutils.PackEntry{Type: utils.BlobObjectType, Data: []byte(content)})
+	}
+	pack, err := Parse(testutils.CreateTestPack(t, entries), nil)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	return pack
+}
+
+// TestIndex_RoundTrip verifies a written index reads back sorted by name and verifies its pack.
+func TestIndex_RoundTrip(t *testing.T) {
+	pack := parseTestPack(t, "one\n", "two\n", "three\n")
+
+	var buf bytes.Buffer
+	if _, err := WriteIndex(&buf, pack); err != nil {
+		t.Fatalf("WriteIndex failed: %v", err)
+	}
+	index, err := ReadIndex(buf.Bytes())
+	if err != nil {
+		t.Fatalf("ReadIndex failed: %v", err)
+	}
+
+	if index.PackChecksum != pack.Checksum || len(index.Entries) != 3 {
+		t.Fatalf("Unexpected index %+v", index)
+	}
+	for i := 1; i < len(index.Entries); i++ {
+		if index.Entries[i-1].Hash >= index.Entries[i].Hash {
+			t.Errorf("Expected entries sorted by name, got %+v", index.Entries)
+		}
+	}
+	if err := index.Verify(pack); err != nil {
+		t.Errorf("Expected index to verify its pack, got %v", err)
+	}
+
+	if err := index.Verify(parseTestPack(t, "one\n", "two\n")); err == nil {
+		t.Error("Expected index to reject another pack")
+	}
+	index.Entries[0].CRC32 ^= 1
+	index.PackChecksum = pack.Checksum
+	if err := index.Verify(pack); err == nil || !strings.Contains(err.Error(), "is corrupt") {
+		t.Errorf("Expected checksum mismatch to be reported, got %v", err)
+	}
+
+	corrupt := buf.Bytes()
+	corrupt[len(corrupt)/2] ^= 1
+	if _, err := ReadIndex(corrupt); err == nil || !strings.Contains(err.Error(), "SHA1 mismatch") {
+		t.Errorf("Expected corrupt index to be rejected, got %v", err)
+	}
+}
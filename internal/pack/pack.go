@@ -0,0 +1,327 @@
+// Package pack reads Git packfiles and reads and writes their version 2 indexes.
+package pack
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/utils"
+)
+
+// signature starts every packfile, followed by its version and object count.
+const (
+	signature  = "PACK"
+	headerSize = 12
+)
+
+// Object type codes of pack entry headers.
+const (
+	typeCommit   = 1
+	typeTree     = 2
+	typeBlob     = 3
+	typeTag      = 4
+	typeOfsDelta = 6
+	typeRefDelta = 7
+)
+
+// ErrUnresolvedDeltas is returned when deltas refer to base objects that are neither in
+// the pack nor supplied by the caller, as in a thin pack.
+var ErrUnresolvedDeltas = errors.New("unresolved deltas")
+
+// Entry describes one object of a pack.
+type Entry struct {
+	Hash       string           // Name of the object
+	Type       utils.ObjectType // Type of the object, for deltas that of their base
+	Size       int64            // Size of the stored data: the object, or the delta for deltas
+	PackedSize int64            // Bytes the entry takes in the pack, header included
+	Offset     int64            // Position of the entry in the pack
+	CRC32      uint32           // Checksum of the packed bytes, as recorded in the index
+	Depth      int              // Length of the delta chain leading to the object, 0 for whole objects
+	Base       string           // Name of the delta base, empty for whole objects
+}
+
+// Pack is a parsed packfile with its deltas resolved.
+type Pack struct {
+	Version  uint32
+	Checksum string  // Hex SHA-1 of the pack content, stored as its trailer
+	Entries  []Entry // Objects in pack order
+	contents [][]byte
+}
+
+// BaseFunc returns the type and content of an object outside the pack, for deltas against
+// objects the receiver already has.
+type BaseFunc func(hash string) (utils.ObjectType, []byte, error)
+
+// rawEntry is a pack entry before its delta, if any, is resolved.
+type rawEntry struct {
+	code       int
+	data       []byte // Inflated object or delta
+	baseOffset int64  // Offset of the base of an offset delta
+	baseHash   string // Name of the base of a reference delta
+	resolved   bool
+}
+
+// Content returns the content of the i-th object of the pack.
+func (p *Pack) Content(i int) []byte {
+	return p.contents[i]
+}
+
+// Parse reads a complete packfile, verifying its checksum and the size of every object,
+// and resolves its deltas. Bases missing from the pack are looked up with external when it
+// is not nil; any still missing fail with ErrUnresolvedDeltas.
+func Parse(data []byte, external BaseFunc) (*Pack, error) {
+	if len(data) < headerSize+sha1.Size {
+		return nil, errors.New("pack is truncated")
+	}
+	if string(data[:len(signature)]) != signature {
+		return nil, errors.New("not a packfile: bad signature")
+	}
+	version := binary.BigEndian.Uint32(data[4:8])
+	if version != 2 && version != 3 {
+		return nil, fmt.Errorf("pack version %d unsupported", version)
+	}
+
+	trailer := len(data) - sha1.Size
+	sum := sha1.Sum(data[:trailer])
+	if !bytes.Equal(sum[:], data[trailer:]) {
+		return nil, errors.New("pack is corrupted (SHA1 mismatch)")
+	}
+
+	count := binary.BigEndian.Uint32(data[8:12])
+	pack := &Pack{
+		Version:  version,
+		Checksum: hex.EncodeToString(sum[:]),
+		Entries:  make([]Entry, 0, count),
+	}
+	raws := make([]rawEntry, 0, count)
+	offsets := make(map[int64]int, count)
+
+	offset := int64(headerSize)
+	for range count {
+		if offset >= int64(trailer) {
+			return nil, errors.New("pack is truncated: fewer objects than its header declares")
+		}
+		raw, size, end, err := readEntry(data[:trailer], offset)
+		if err != nil {
+			return nil, fmt.Errorf("bad object at offset %d: %w", offset, err)
+		}
+		offsets[offset] = len(raws)
+		raws = append(raws, raw)
+		pack.Entries = append(pack.Entries, Entry{
+			Size:       size,
+			PackedSize: end - offset,
+			Offset:     offset,
+			CRC32:      crc32.ChecksumIEEE(data[offset:end]),
+		})
+		offset = end
+	}
+	if offset != int64(trailer) {
+		return nil, fmt.Errorf("pack has %d bytes of garbage after its last object", int64(trailer)-offset)
+	}
+
+	pack.contents = make([][]byte, count)
+	if err := pack.resolve(raws, offsets, external); err != nil {
+		return nil, err
+	}
+	return pack, nil
+}
+
+// readEntry parses the entry at offset and inflates its data, returning the size its header
+// declares and the offset the next entry starts at.
+func readEntry(data []byte, offset int64) (rawEntry, int64, int64, error) {
+	pos := offset
+	next := func() (byte, error) {
+		if pos >= int64(len(data)) {
+			return 0, io.ErrUnexpectedEOF
+		}
+		pos++
+		return data[pos-1], nil
+	}
+
+	// Type and size: 3 type bits and 4 size bits, then 7 size bits per continuation byte
+	c, err := next()
+	if err != nil {
+		return rawEntry{}, 0, 0, err
+	}
+	raw := rawEntry{code: int(c>>4) & 7}
+	size := int64(c & 0x0f)
+	for shift := 4; c&0x80 != 0; shift += 7 {
+		if c, err = next(); err != nil {
+			return rawEntry{}, 0, 0, err
+		}
+		size |= int64(c&0x7f) << shift
+	}
+
+	switch raw.code {
+	case typeCommit, typeTree, typeBlob, typeTag:
+	case typeOfsDelta:
+		// Big-endian base-128 distance back to the base, each continuation adding one
+		if c, err = next(); err != nil {
+			return rawEntry{}, 0, 0, err
+		}
+		distance := int64(c & 0x7f)
+		for c&0x80 != 0 {
+			if c, err = next(); err != nil {
+				return rawEntry{}, 0, 0, err
+			}
+			distance = (distance+1)<<7 | int64(c&0x7f)
+		}
+		if distance <= 0 || distance > offset {
+			return rawEntry{}, 0, 0, fmt.Errorf("delta base offset is out of bounds")
+		}
+		raw.baseOffset = offset - distance
+	case typeRefDelta:
+		if pos+sha1.Size > int64(len(data)) {
+			return rawEntry{}, 0, 0, io.ErrUnexpectedEOF
+		}
+		raw.baseHash = hex.EncodeToString(data[pos : pos+sha1.Size])
+		pos += sha1.Size
+	default:
+		return rawEntry{}, 0, 0, fmt.Errorf("unknown object type %d", raw.code)
+	}
+
+	// A bytes.Reader is read byte by byte by the decompressor, so its position afterwards
+	// is exactly the end of the compressed stream
+	reader := bytes.NewReader(data[pos:])
+	decompressor, err := zlib.NewReader(reader)
+	if err != nil {
+		return rawEntry{}, 0, 0, fmt.Errorf("failed to inflate: %w", err)
+	}
+	// The declared size only bounds what is read, so a hostile header cannot force a huge allocation
+	var inflated bytes.Buffer
+	if _, err := inflated.ReadFrom(io.LimitReader(decompressor, size+1)); err != nil {
+		return rawEntry{}, 0, 0, fmt.Errorf("failed to inflate: %w", err)
+	}
+	if int64(inflated.Len()) != size {
+		return rawEntry{}, 0, 0, fmt.Errorf("inflated size does not match declared size %d", size)
+	}
+	if err := decompressor.Close(); err != nil {
+		return rawEntry{}, 0, 0, fmt.Errorf("failed to inflate: %w", err)
+	}
+
+	raw.data = inflated.Bytes()
+	end := pos + int64(len(data[pos:])-reader.Len())
+	return raw, size, end, nil
+}
+
+// resolve computes content, type and name of every object, applying deltas once their
+// bases are known. Bases inside the pack are preferred; external ones are only looked up
+// for deltas that cannot be resolved otherwise.
+func (p *Pack) resolve(raws []rawEntry, offsets map[int64]int, external BaseFunc) error {
+	byHash := make(map[string]int, len(raws))
+	for _, useExternal := range []bool{false, true} {
+		if useExternal && external == nil {
+			break
+		}
+		for progress := true; progress; {
+			progress = false
+			for i := range raws {
+				if raws[i].resolved {
+					continue
+				}
+				resolved, err := p.resolveEntry(i, raws, offsets, byHash, external, useExternal)
+				if err != nil {
+					return err
+				}
+				progress = progress || resolved
+			}
+		}
+	}
+
+	unresolved := 0
+	for _, raw := range raws {
+		if !raw.resolved {
+			unresolved++
+		}
+	}
+	if unresolved > 0 {
+		return fmt.Errorf("pack has %d %w", unresolved, ErrUnresolvedDeltas)
+	}
+	return nil
+}
+
+// resolveEntry resolves entry i if its base is available, resolving offset delta bases
+// first. Reports whether the entry is resolved.
+func (p *Pack) resolveEntry(i int, raws []rawEntry, offsets map[int64]int, byHash map[string]int, external BaseFunc, useExternal bool) (bool, error) {
+	raw := &raws[i]
+	if raw.resolved {
+		return true, nil
+	}
+	entry := &p.Entries[i]
+
+	var baseType utils.ObjectType
+	var base []byte
+	switch raw.code {
+	case typeOfsDelta:
+		j, ok := offsets[raw.baseOffset]
+		if !ok {
+			return false, fmt.Errorf("bad object at offset %d: delta base offset %d is not an object", entry.Offset, raw.baseOffset)
+		}
+		if resolved, err := p.resolveEntry(j, raws, offsets, byHash, external, useExternal); err != nil || !resolved {
+			return false, err
+		}
+		baseType, base = p.Entries[j].Type, p.contents[j]
+		entry.Base, entry.Depth = p.Entries[j].Hash, p.Entries[j].Depth+1
+	case typeRefDelta:
+		if j, ok := byHash[raw.baseHash]; ok {
+			baseType, base = p.Entries[j].Type, p.contents[j]
+			entry.Depth = p.Entries[j].Depth + 1
+		} else if useExternal {
+			var err error
+			if baseType, base, err = external(raw.baseHash); err != nil {
+				return false, nil
+			}
+			entry.Depth = 1
+		} else {
+			return false, nil
+		}
+		entry.Base = raw.baseHash
+	default:
+		baseType = codeType(raw.code)
+	}
+
+	content := raw.data
+	if entry.Base != "" {
+		var err error
+		if content, err = ApplyDelta(base, raw.data); err != nil {
+			return false, fmt.Errorf("bad delta at offset %d: %w", entry.Offset, err)
+		}
+	}
+
+	hash, err := utils.ComputeHash(content, baseType)
+	if err != nil {
+		return false, err
+	}
+	entry.Hash, entry.Type = hash, baseType
+	p.contents[i] = content
+	byHash[hash] = i
+	raw.resolved, raw.data = true, nil
+	return true, nil
+}
+
+// codeType returns the object type of a whole object entry's type code.
+func codeType(code int) utils.ObjectType {
+	switch code {
+	case typeCommit:
+		return utils.CommitObjectType
+	case typeTree:
+		return utils.TreeObjectType
+	case typeTag:
+		return utils.TagObjectType
+	default:
+		return utils.BlobObjectType
+	}
+}
+
+// entryName reads the 20-byte object name at the start of data as hex.
+func entryName(data []byte) string {
+	return hex.EncodeToString(data[:constants.HashByteLength])
+}
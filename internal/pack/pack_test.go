@@ -0,0 +1,112 @@
+package pack
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/testutils"
+	"github.com/KostasZigo/gogit/utils"
+)
+
+// helloDelta turns "hello world\n" into "hello there\n".
+var helloDelta = append([]byte{12, 12, 0x90, 6, 6}, "there\n"...)
+
+// thereDelta turns "hello there\n" into "there\n".
+var thereDelta = []byte{12, 6, 0x91, 6, 6}
+
+// TestParse verifies whole objects and chains of offset and reference deltas are resolved.
+func TestParse(t *testing.T) {
+	base := utils.MustComputeHash([]byte("hello world\n"), utils.BlobObjectType)
+	data := testutils.CreateTestPack(t, []testutils.PackEntry{
+		{Type: utils.BlobObjectType, Data: []byte("hello world\n")},
+		{OffsetDelta: true, BaseEntry: 0, Data: helloDelta},
+		{OffsetDelta: true, BaseEntry: 1, Data: thereDelta},
+		{BaseHash: base, Data: helloDelta},
+	})
+
+	pack, err := Parse(data, nil)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	hello := utils.MustComputeHash([]byte("hello there\n"), utils.BlobObjectType)
+	expected := []struct {
+		content string
+		depth   int
+		base    string
+	}{
+		{"hello world\n", 0, ""},
+		{"hello there\n", 1, base},
+		{"there\n", 2, hello},
+		{"hello there\n", 1, base},
+	}
+	if len(pack.Entries) != len(expected) {
+		t.Fatalf("Expected %d entries, got %d", len(expected), len(pack.Entries))
+	}
+	for i, want := range expected {
+		entry := pack.Entries[i]
+		if string(pack.Content(i)) != want.content {
+			t.Errorf("Entry %d: expected content %q, got %q", i, want.content, pack.Content(i))
+		}
+		if entry.Hash != utils.MustComputeHash([]byte(want.content), utils.BlobObjectType) || entry.Type != utils.BlobObjectType {
+			t.Errorf("Entry %d: unexpected name %s or type %s", i, entry.Hash, entry.Type)
+		}
+		if entry.Depth != want.depth || entry.Base != want.base {
+			t.Errorf("Entry %d: expected depth %d on %q, got %d on %q", i, want.depth, want.base, entry.Depth, entry.Base)
+		}
+	}
+	if pack.Entries[1].Offset != pack.Entries[0].Offset+pack.Entries[0].PackedSize {
+		t.Errorf("Expected entries to follow each other, got %+v", pack.Entries[:2])
+	}
+}
+
+// TestParse_ExternalBases verifies deltas against objects outside the pack are resolved
+// through the caller, and rejected without it.
+func TestParse_ExternalBases(t *testing.T) {
+	base := utils.MustComputeHash([]byte("hello world\n"), utils.BlobObjectType)
+	data := testutils.CreateTestPack(t, []testutils.PackEntry{
+		{BaseHash: base, Data: helloDelta},
+		{OffsetDelta: true, BaseEntry: 0, Data: thereDelta},
+	})
+
+	if _, err := Parse(data, nil); !errors.Is(err, ErrUnresolvedDeltas) {
+		t.Fatalf("Expected ErrUnresolvedDeltas, got %v", err)
+	}
+
+	pack, err := Parse(data, func(hash string) (utils.ObjectType, []byte, error) {
+		if hash != base {
+			t.Fatalf("Unexpected base lookup %s", hash)
+		}
+		return utils.BlobObjectType, []byte("hello world\n"), nil
+	})
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if string(pack.Content(1)) != "there\n" || pack.Entries[1].Depth != 2 {
+		t.Errorf("Expected chain on the external base to resolve, got %q at depth %d", pack.Content(1), pack.Entries[1].Depth)
+	}
+}
+
+// TestParse_Corrupt verifies damaged packs are rejected.
+func TestParse_Corrupt(t *testing.T) {
+	data := testutils.CreateTestPack(t, []testutils.PackEntry{{Type: utils.BlobObjectType, Data: []byte("content\n")}})
+
+	tests := []struct {
+		name string
+		data []byte
+		err  string
+	}{
+		{"bad signature", append([]byte("KCAP"), data[4:]...), "bad signature"},
+		{"checksum", append(append([]byte{}, data[:len(data)-1]...), data[len(data)-1]^1), "SHA1 mismatch"},
+		{"truncated", data[:20], "truncated"},
+		{"missing data", data[:len(data)-5], "SHA1 mismatch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.data, nil); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Expected error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package pack
+
+import (
+	"compress/zlib"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/KostasZigo/gogit/utils"
+)
+
+// Writer writes a version 2 packfile holding whole objects, without deltas.
+type Writer struct {
+	out      io.Writer // Destination and checksum together
+	checksum hash.Hash
+	count    uint32
+	written  uint32
+}
+
+// NewWriter starts a pack of count objects on w by writing its header.
+func NewWriter(w io.Writer, count int) (*Writer, error) {
+	checksum := sha1.New()
+	writer := &Writer{out: io.MultiWriter(w, checksum), checksum: checksum, count: uint32(count)}
+
+	header := binary.BigEndian.AppendUint32([]byte(signature), 2)
+	header = binary.BigEndian.AppendUint32(header, writer.count)
+	if _, err := writer.out.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write pack header: %w", err)
+	}
+	return writer, nil
+}
+
+// WriteObject appends an object of objectType with content to the pack.
+func (w *Writer) WriteObject(objectType utils.ObjectType, content []byte) error {
+	if w.written == w.count {
+		return fmt.Errorf("pack declares %d objects, cannot add more", w.count)
+	}
+	code, err := typeCode(objectType)
+	if err != nil {
+		return err
+	}
+
+	// Type and size header: 3 type bits and 4 size bits, then 7 size bits per continuation byte
+	size := len(content)
+	c := byte(code<<4) | byte(size&0x0f)
+	var header []byte
+	for size >>= 4; size > 0; size >>= 7 {
+		header = append(header, c|0x80)
+		c = byte(size & 0x7f)
+	}
+	header = append(header, c)
+	if _, err := w.out.Write(header); err != nil {
+		return fmt.Errorf("failed to write pack entry: %w", err)
+	}
+
+	compressor := zlib.NewWriter(w.out)
+	if _, err := compressor.Write(content); err != nil {
+		return fmt.Errorf("failed to write pack entry: %w", err)
+	}
+	if err := compressor.Close(); err != nil {
+		return fmt.Errorf("failed to write pack entry: %w", err)
+	}
+	w.written++
+	return nil
+}
+
+// Close writes the checksum trailer once every declared object is written and returns it as hex.
+func (w *Writer) Close() (string, error) {
+	if w.written != w.count {
+		return "", fmt.Errorf("pack declares %d objects, only %d written", w.count, w.written)
+	}
+	sum := w.checksum.Sum(nil)
+	if _, err := w.out.Write(sum); err != nil {
+		return "", fmt.Errorf("failed to write pack trailer: %w", err)
+	}
+	return hex.EncodeToString(sum), nil
+}
+
+// typeCode returns the pack entry type code of objectType.
+func typeCode(objectType utils.ObjectType) (int, error) {
+	switch objectType {
+	case utils.CommitObjectType:
+		return typeCommit, nil
+	case utils.TreeObjectType:
+		return typeTree, nil
+	case utils.BlobObjectType:
+		return typeBlob, nil
+	case utils.TagObjectType:
+		return typeTag, nil
+	default:
+		return 0, fmt.Errorf("cannot pack object of type %s", objectType)
+	}
+}
@@ -0,0 +1,68 @@
+package pack
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/utils"
+)
+
+// TestWriter verifies a written pack parses back to the same objects with the returned checksum.
+func TestWriter(t *testing.T) {
+	objects := []struct {
+		objectType utils.ObjectType
+		content    []byte
+	}{
+		{utils.BlobObjectType, []byte("hello world\n")},
+		{utils.BlobObjectType, []byte(strings.Repeat("large ", 100))},
+		{utils.TreeObjectType, nil},
+	}
+
+	var buffer bytes.Buffer
+	writer, err := NewWriter(&buffer, len(objects))
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	for _, object := range objects {
+		if err := writer.WriteObject(object.objectType, object.content); err != nil {
+			t.Fatalf("WriteObject failed: %v", err)
+		}
+	}
+	checksum, err := writer.Close()
+	if err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	pack, err := Parse(buffer.Bytes(), nil)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if pack.Checksum != checksum {
+		t.Errorf("Expected checksum %s, got %s", checksum, pack.Checksum)
+	}
+	for i, object := range objects {
+		expected := utils.MustComputeHash(object.content, object.objectType)
+		if pack.Entries[i].Hash != expected || pack.Entries[i].Type != object.objectType {
+			t.Errorf("Entry %d: expected %s %s, got %s %s", i, object.objectType, expected, pack.Entries[i].Type, pack.Entries[i].Hash)
+		}
+	}
+}
+
+// TestWriter_Count verifies writing more or fewer objects than declared fails.
+func TestWriter_Count(t *testing.T) {
+	writer, err := NewWriter(&bytes.Buffer{}, 1)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	if _, err := writer.Close(); err == nil {
+		t.Error("Expected error closing a pack missing objects")
+	}
+
+	if err := writer.WriteObject(utils.BlobObjectType, []byte("a")); err != nil {
+		t.Fatalf("WriteObject failed: %v", err)
+	}
+	if err := writer.WriteObject(utils.BlobObjectType, []byte("b")); err == nil {
+		t.Error("Expected error writing more objects than declared")
+	}
+}
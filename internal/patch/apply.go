@@ -0,0 +1,165 @@
+package patch
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ApplyHunks applies hunks in order to content and returns the patched content.
+// Each hunk is looked for at its recorded line first, then at growing distances around it.
+// A hunk starting at the first line must match at the beginning of the file, and one without
+// trailing context must match at its end. When minContext is not negative and a hunk cannot be
+// found, the anchoring is dropped and then context lines are removed from its edges until at
+// most minContext remain on each side, mirroring git apply -C.
+func ApplyHunks(content []byte, hunks []Hunk, minContext int) ([]byte, error) {
+	lines := splitLines(string(content))
+	for i, hunk := range hunks {
+		var err error
+		if lines, err = applyHunk(lines, hunk, minContext); err != nil {
+			return nil, fmt.Errorf("hunk #%d (%s) %w", i+1, hunkRange(hunk), err)
+		}
+	}
+	return []byte(strings.Join(lines, "")), nil
+}
+
+// applyHunk replaces the hunk's preimage in lines with its postimage.
+func applyHunk(lines []string, hunk Hunk, minContext int) ([]string, error) {
+	leading := countContext(hunk.Lines)
+	trailing := countContext(reversed(hunk.Lines))
+	if leading == len(hunk.Lines) {
+		// A hunk of only context changes nothing
+		return lines, nil
+	}
+
+	matchBeginning := hunk.OldStart <= 1
+	matchEnd := trailing == 0
+	expected := max(hunk.NewStart-1, 0)
+	if hunk.OldLines == 0 {
+		expected = hunk.NewStart
+	}
+
+	dropLeading, dropTrailing := 0, 0
+	for {
+		body := hunk.Lines[dropLeading : len(hunk.Lines)-dropTrailing]
+		preimage, postimage := images(body)
+
+		position := findPreimage(lines, preimage, expected+dropLeading, matchBeginning, matchEnd)
+		if position >= 0 {
+			return append(lines[:position:position], append(postimage, lines[position+len(preimage):]...)...), nil
+		}
+
+		if minContext < 0 || (leading-dropLeading <= minContext && trailing-dropTrailing <= minContext) {
+			return nil, fmt.Errorf("does not apply")
+		}
+		if matchBeginning || matchEnd {
+			matchBeginning, matchEnd = false, false
+			continue
+		}
+
+		// Drop context from both edges when they are equal, otherwise from the longer one
+		remainingLeading, remainingTrailing := leading-dropLeading, trailing-dropTrailing
+		if remainingLeading >= remainingTrailing && remainingLeading > 0 {
+			dropLeading++
+		}
+		if remainingTrailing >= remainingLeading && remainingTrailing > 0 {
+			dropTrailing++
+		}
+	}
+}
+
+// findPreimage returns the position of preimage in lines nearest to expected, or -1.
+// Anchored matches are only tried at the beginning or end of lines.
+func findPreimage(lines, preimage []string, expected int, matchBeginning, matchEnd bool) int {
+	last := len(lines) - len(preimage)
+	if last < 0 {
+		return -1
+	}
+
+	switch {
+	case matchBeginning && matchEnd:
+		if last == 0 && matchesAt(lines, preimage, 0) {
+			return 0
+		}
+		return -1
+	case matchBeginning:
+		if matchesAt(lines, preimage, 0) {
+			return 0
+		}
+		return -1
+	case matchEnd:
+		if matchesAt(lines, preimage, last) {
+			return last
+		}
+		return -1
+	}
+
+	expected = min(expected, last)
+	for distance := 0; expected-distance >= 0 || expected+distance <= last; distance++ {
+		if before := expected - distance; before >= 0 && matchesAt(lines, preimage, before) {
+			return before
+		}
+		if after := expected + distance; distance > 0 && after <= last && matchesAt(lines, preimage, after) {
+			return after
+		}
+	}
+	return -1
+}
+
+// matchesAt reports whether preimage equals lines starting at position.
+func matchesAt(lines, preimage []string, position int) bool {
+	for i, line := range preimage {
+		if lines[position+i] != line {
+			return false
+		}
+	}
+	return true
+}
+
+// images splits hunk lines into the text they expect and the text they produce.
+func images(hunkLines []Line) (preimage, postimage []string) {
+	for _, line := range hunkLines {
+		if line.Op != '+' {
+			preimage = append(preimage, line.Text)
+		}
+		if line.Op != '-' {
+			postimage = append(postimage, line.Text)
+		}
+	}
+	return preimage, postimage
+}
+
+// countContext returns the number of context lines at the start of hunkLines.
+func countContext(hunkLines []Line) int {
+	for i, line := range hunkLines {
+		if line.Op != ' ' {
+			return i
+		}
+	}
+	return len(hunkLines)
+}
+
+// reversed returns a reversed copy of hunkLines.
+func reversed(hunkLines []Line) []Line {
+	result := make([]Line, len(hunkLines))
+	for i, line := range hunkLines {
+		result[len(hunkLines)-1-i] = line
+	}
+	return result
+}
+
+// hunkRange formats the hunk header ranges, as in "-3,4 +3,5".
+func hunkRange(hunk Hunk) string {
+	return fmt.Sprintf("-%d,%d +%d,%d", hunk.OldStart, hunk.OldLines, hunk.NewStart, hunk.NewLines)
+}
+
+// splitLines splits content into lines that keep their newlines.
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,116 @@
+package patch
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// parseHunks parses a single-file patch and returns its hunks.
+func parseHunks(t *testing.T, input string) []Hunk {
+	t.Helper()
+
+	patches, err := Parse(strings.NewReader("--- a/f\n+++ b/f\n"+input), 1)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	return patches[0].Hunks
+}
+
+// numberLines returns lines "1\n" through "<count>\n".
+func numberLines(count int) string {
+	var builder strings.Builder
+	for i := 1; i <= count; i++ {
+		builder.WriteString(strconv.Itoa(i) + "\n")
+	}
+	return builder.String()
+}
+
+// TestApplyHunks verifies exact, shifted, anchored and fuzzy application.
+func TestApplyHunks(t *testing.T) {
+	middle := "@@ -4,3 +4,3 @@\n 4\n-5\n+five\n 6\n"
+
+	tests := []struct {
+		name       string
+		content    string
+		hunks      string
+		minContext int
+		expected   string
+		wantErr    bool
+	}{
+		{
+			name:       "exact position",
+			content:    numberLines(8),
+			minContext: -1,
+			hunks:      middle,
+			expected:   strings.Replace(numberLines(8), "5\n", "five\n", 1),
+		},
+		{
+			name:       "shifted by inserted lines",
+			content:    "a\nb\n" + numberLines(8),
+			minContext: -1,
+			hunks:      middle,
+			expected:   "a\nb\n" + strings.Replace(numberLines(8), "5\n", "five\n", 1),
+		},
+		{
+			name:       "insert at start",
+			content:    "1\n2\n",
+			minContext: -1,
+			hunks:      "@@ -1,1 +1,2 @@\n+0\n 1\n",
+			expected:   "0\n1\n2\n",
+		},
+		{
+			name:       "append at end without newline",
+			content:    "1\n2",
+			minContext: -1,
+			hunks:      "@@ -2 +2,2 @@\n-2\n\\ No newline at end of file\n+2\n+3\n",
+			expected:   "1\n2\n3\n",
+		},
+		{
+			name:       "create file",
+			content:    "",
+			minContext: -1,
+			hunks:      "@@ -0,0 +1,2 @@\n+a\n+b\n",
+			expected:   "a\nb\n",
+		},
+		{
+			name:       "context mismatch",
+			content:    strings.Replace(numberLines(8), "6\n", "six\n", 1),
+			minContext: -1,
+			hunks:      middle,
+			wantErr:    true,
+		},
+		{
+			name:       "reduced context",
+			content:    strings.Replace(numberLines(8), "6\n", "six\n", 1),
+			hunks:      middle,
+			minContext: 0,
+			expected:   strings.Replace(numberLines(8), "5\n6\n", "five\nsix\n", 1),
+		},
+		{
+			name:       "end anchor",
+			content:    "1\n2\n3\nx\n",
+			minContext: -1,
+			hunks:      "@@ -2,2 +2,1 @@\n 2\n-3\n",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ApplyHunks([]byte(tt.content), parseHunks(t, tt.hunks), tt.minContext)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected error, got %q", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyHunks failed: %v", err)
+			}
+			if string(result) != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package patch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/diff"
+)
+
+// Diff returns the hunks turning oldContent into newContent, each with up to context
+// unchanged lines around its changes. Changes separated by at most twice the context
+// share a hunk, as in Git.
+func Diff(oldContent, newContent []byte, context int) []Hunk {
+	a, b := diff.SplitLines(oldContent), diff.SplitLines(newContent)
+	edits := diff.Lines(a, b)
+
+	var hunks []Hunk
+	for first := 0; first < len(edits); {
+		last := first
+		for last+1 < len(edits) && edits[last+1].AStart-(edits[last].AStart+edits[last].ACount) <= 2*context {
+			last++
+		}
+
+		start := max(edits[first].AStart-context, 0)
+		end := min(edits[last].AStart+edits[last].ACount+context, len(a))
+		var lines []Line
+		x := start
+		for _, edit := range edits[first : last+1] {
+			for ; x < edit.AStart; x++ {
+				lines = append(lines, Line{Op: ' ', Text: a[x]})
+			}
+			for _, text := range a[edit.AStart : edit.AStart+edit.ACount] {
+				lines = append(lines, Line{Op: '-', Text: text})
+			}
+			for _, text := range b[edit.BStart : edit.BStart+edit.BCount] {
+				lines = append(lines, Line{Op: '+', Text: text})
+			}
+			x = edit.AStart + edit.ACount
+		}
+		for ; x < end; x++ {
+			lines = append(lines, Line{Op: ' ', Text: a[x]})
+		}
+
+		// Unchanged lines are the same on both sides, so the new side starts as far before
+		// the first change as the old side does
+		newStart := edits[first].BStart - (edits[first].AStart - start)
+		hunks = append(hunks, newHunk(start, newStart, lines))
+		first = last + 1
+	}
+	return hunks
+}
+
+// Split splits hunk at the unchanged lines between its runs of changes. Each part keeps the
+// context before and after its changes, so neighbouring parts share the lines between them.
+// A hunk with a single run of changes is returned unsplit.
+func Split(hunk Hunk) []Hunk {
+	var runs [][2]int
+	for i := 0; i < len(hunk.Lines); {
+		if hunk.Lines[i].Op == ' ' {
+			i++
+			continue
+		}
+		start := i
+		for i < len(hunk.Lines) && hunk.Lines[i].Op != ' ' {
+			i++
+		}
+		runs = append(runs, [2]int{start, i})
+	}
+	if len(runs) < 2 {
+		return []Hunk{hunk}
+	}
+
+	parts := make([]Hunk, 0, len(runs))
+	for i := range runs {
+		from, to := 0, len(hunk.Lines)
+		if i > 0 {
+			from = runs[i-1][1]
+		}
+		if i+1 < len(runs) {
+			to = runs[i+1][0]
+		}
+		oldBefore, newBefore := countLines(hunk.Lines[:from])
+		part := Hunk{OldStart: hunk.OldStart + oldBefore, NewStart: hunk.NewStart + newBefore, Lines: hunk.Lines[from:to:to]}
+		part.Recount()
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// Recount sets the line counts of h from its lines, as needed after they were edited.
+func (h *Hunk) Recount() {
+	h.OldLines, h.NewLines = countLines(h.Lines)
+}
+
+// String formats the hunk as it appears in a unified diff, header included.
+func (h *Hunk) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "@@ -%s +%s @@\n", unifiedRange(h.OldStart, h.OldLines), unifiedRange(h.NewStart, h.NewLines))
+	for _, line := range h.Lines {
+		sb.WriteByte(line.Op)
+		sb.WriteString(line.Text)
+		if !strings.HasSuffix(line.Text, "\n") {
+			sb.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+	return sb.String()
+}
+
+// newHunk returns the hunk of lines starting after start lines of the old side and
+// newStart lines of the new side.
+func newHunk(start, newStart int, lines []Line) Hunk {
+	hunk := Hunk{OldStart: start, NewStart: newStart, Lines: lines}
+	hunk.Recount()
+	// An empty side is numbered by the line before it, as in "@@ -0,0 +1 @@"
+	if hunk.OldLines > 0 {
+		hunk.OldStart++
+	}
+	if hunk.NewLines > 0 {
+		hunk.NewStart++
+	}
+	return hunk
+}
+
+// countLines returns how many of lines belong to the old and to the new side.
+func countLines(lines []Line) (oldLines, newLines int) {
+	for _, line := range lines {
+		if line.Op != '+' {
+			oldLines++
+		}
+		if line.Op != '-' {
+			newLines++
+		}
+	}
+	return oldLines, newLines
+}
+
+// unifiedRange formats a hunk range, leaving out a count of one as Git does.
+func unifiedRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
@@ -0,0 +1,93 @@
+package patch
+
+import (
+	"strings"
+	"testing"
+)
+
+// formatHunks joins hunks as they appear in a unified diff.
+func formatHunks(hunks []Hunk) string {
+	var builder strings.Builder
+	for _, hunk := range hunks {
+		builder.WriteString(hunk.String())
+	}
+	return builder.String()
+}
+
+// TestDiff verifies context, joining of nearby changes, and files created, emptied or
+// lacking a final newline.
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      string
+		new      string
+		expected string
+	}{
+		{
+			name:     "separate hunks",
+			old:      numberLines(20),
+			new:      strings.NewReplacer("\n2\n", "\ntwo\n", "\n15\n", "\nfifteen\n").Replace(numberLines(20)),
+			expected: "@@ -1,5 +1,5 @@\n 1\n-2\n+two\n 3\n 4\n 5\n@@ -12,7 +12,7 @@\n 12\n 13\n 14\n-15\n+fifteen\n 16\n 17\n 18\n",
+		},
+		{
+			name:     "joined hunk",
+			old:      numberLines(9),
+			new:      strings.NewReplacer("\n2\n", "\ntwo\n", "\n8\n", "\neight\n").Replace(numberLines(9)),
+			expected: "@@ -1,9 +1,9 @@\n 1\n-2\n+two\n 3\n 4\n 5\n 6\n 7\n-8\n+eight\n 9\n",
+		},
+		{
+			name:     "created",
+			new:      "a\n",
+			expected: "@@ -0,0 +1 @@\n+a\n",
+		},
+		{
+			name:     "emptied",
+			old:      "a\nb\n",
+			expected: "@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		{
+			name:     "no final newline",
+			old:      "a\nb",
+			new:      "a\nc",
+			expected: "@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n",
+		},
+		{
+			name: "unchanged",
+			old:  "a\n",
+			new:  "a\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hunks := Diff([]byte(test.old), []byte(test.new), 3)
+			if got := formatHunks(hunks); got != test.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", test.expected, got)
+			}
+
+			applied, err := ApplyHunks([]byte(test.old), hunks, -1)
+			if err != nil {
+				t.Fatalf("ApplyHunks failed: %v", err)
+			}
+			if string(applied) != test.new {
+				t.Errorf("Expected hunks to produce %q, got %q", test.new, applied)
+			}
+		})
+	}
+}
+
+// TestSplit verifies a hunk splits between its changes, with parts sharing the context between them.
+func TestSplit(t *testing.T) {
+	hunks := parseHunks(t, "@@ -1,9 +1,9 @@\n 1\n-2\n+two\n 3\n 4\n 5\n-6\n+six\n 7\n 8\n 9\n")
+
+	parts := Split(hunks[0])
+	expected := "@@ -1,5 +1,5 @@\n 1\n-2\n+two\n 3\n 4\n 5\n@@ -3,7 +3,7 @@\n 3\n 4\n 5\n-6\n+six\n 7\n 8\n 9\n"
+	if got := formatHunks(parts); got != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, got)
+	}
+
+	single := parseHunks(t, "@@ -1,3 +1,3 @@\n 1\n-2\n+two\n 3\n")
+	if parts := Split(single[0]); len(parts) != 1 {
+		t.Errorf("Expected a single run of changes to stay whole, got %d parts", len(parts))
+	}
+}
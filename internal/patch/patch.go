@@ -0,0 +1,415 @@
+// Package patch parses unified diffs, as written by diff -u and git diff, and applies
+// their hunks to file content.
+package patch
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/objects"
+)
+
+// Headers recognized in unified and Git diffs.
+const (
+	gitDiffHeader        = "diff --git "
+	oldFileHeader        = "--- "
+	newFileHeader        = "+++ "
+	hunkHeader           = "@@ "
+	oldModeHeader        = "old mode "
+	newModeHeader        = "new mode "
+	deletedFileHeader    = "deleted file mode "
+	newFileModeHeader    = "new file mode "
+	renameFromHeader     = "rename from "
+	renameToHeader       = "rename to "
+	copyFromHeader       = "copy from "
+	copyToHeader         = "copy to "
+	indexHeader          = "index "
+	similarityHeader     = "similarity index "
+	dissimilarityHeader  = "dissimilarity index "
+	binaryFilesHeader    = "Binary files "
+	gitBinaryPatchHeader = "GIT binary patch"
+	noNewlineMarker      = '\\'
+	devNull              = "/dev/null"
+)
+
+// hunkHeaderPattern matches "@@ -<old start>[,<old lines>] +<new start>[,<new lines>] @@".
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// Line is one line of a hunk.
+type Line struct {
+	Op   byte   // ' ' for context, '-' for removed and '+' for added lines
+	Text string // Content including its newline, which a last line without one lacks
+}
+
+// Hunk is a run of changed lines with surrounding context.
+type Hunk struct {
+	OldStart, OldLines int // First line (1-based) and line count in the original file
+	NewStart, NewLines int // First line (1-based) and line count in the patched file
+	Lines              []Line
+}
+
+// FilePatch is the change a patch makes to one file.
+type FilePatch struct {
+	OldPath string           // Empty when the patch creates the file
+	NewPath string           // Empty when the patch deletes the file
+	OldMode objects.FileMode // Mode before the change, when the patch records it
+	NewMode objects.FileMode // Mode after the change, when the patch records it
+	Copy    bool             // NewPath is created from OldPath, which is kept
+	Binary  bool             // Content change is binary and carries no hunks
+	Hunks   []Hunk
+}
+
+// IsCreation reports whether the patch creates a new file.
+func (fp *FilePatch) IsCreation() bool {
+	return fp.OldPath == ""
+}
+
+// IsDeletion reports whether the patch deletes the file.
+func (fp *FilePatch) IsDeletion() bool {
+	return fp.NewPath == ""
+}
+
+// Reverse turns the patch into one undoing it. Copies cannot be reversed.
+func (fp *FilePatch) Reverse() error {
+	if fp.Copy {
+		return fmt.Errorf("cannot reverse copy of %s to %s", fp.OldPath, fp.NewPath)
+	}
+
+	fp.OldPath, fp.NewPath = fp.NewPath, fp.OldPath
+	fp.OldMode, fp.NewMode = fp.NewMode, fp.OldMode
+	for i := range fp.Hunks {
+		hunk := &fp.Hunks[i]
+		hunk.OldStart, hunk.NewStart = hunk.NewStart, hunk.OldStart
+		hunk.OldLines, hunk.NewLines = hunk.NewLines, hunk.OldLines
+		for j := range hunk.Lines {
+			switch hunk.Lines[j].Op {
+			case '+':
+				hunk.Lines[j].Op = '-'
+			case '-':
+				hunk.Lines[j].Op = '+'
+			}
+		}
+	}
+	return nil
+}
+
+// Parse reads every file patch from r, skipping text around them such as commit messages.
+// strip leading path components are removed from names in diff headers, as with patch -p.
+func Parse(r io.Reader, strip int) ([]*FilePatch, error) {
+	p := &parser{reader: bufio.NewReader(r), strip: strip}
+	patches, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("invalid patch at line %d: %w", p.lineNumber, err)
+	}
+	return patches, nil
+}
+
+// parser reads patch lines, with one line of lookahead.
+type parser struct {
+	reader     *bufio.Reader
+	strip      int
+	lineNumber int
+	pending    string
+	hasPending bool
+}
+
+// next returns the next line including its newline, or false at end of input.
+func (p *parser) next() (string, bool, error) {
+	if p.hasPending {
+		p.hasPending = false
+		return p.pending, true, nil
+	}
+
+	line, err := p.reader.ReadString('\n')
+	if errors.Is(err, io.EOF) {
+		if line == "" {
+			return "", false, nil
+		}
+	} else if err != nil {
+		return "", false, fmt.Errorf("failed to read patch: %w", err)
+	}
+	p.lineNumber++
+	return line, true, nil
+}
+
+// unread pushes line back to be returned by the next call to next.
+func (p *parser) unread(line string) {
+	p.pending, p.hasPending = line, true
+}
+
+// parse collects file patches until end of input.
+func (p *parser) parse() ([]*FilePatch, error) {
+	var patches []*FilePatch
+	var current *FilePatch
+	inGitHeader := false
+
+	for {
+		line, ok, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		header := strings.TrimRight(line, "\r\n")
+
+		switch {
+		case strings.HasPrefix(header, gitDiffHeader):
+			current = &FilePatch{}
+			patches = append(patches, current)
+			name, err := p.gitHeaderName(strings.TrimPrefix(header, gitDiffHeader))
+			if err != nil {
+				return nil, err
+			}
+			current.OldPath, current.NewPath = name, name
+			inGitHeader = true
+
+		case strings.HasPrefix(header, oldFileHeader):
+			newLine, ok, err := p.next()
+			if err != nil {
+				return nil, err
+			}
+			if !ok || !strings.HasPrefix(newLine, newFileHeader) {
+				if ok {
+					p.unread(newLine)
+				}
+				continue
+			}
+
+			oldName, err := p.headerName(strings.TrimPrefix(header, oldFileHeader))
+			if err != nil {
+				return nil, err
+			}
+			newName, err := p.headerName(strings.TrimPrefix(strings.TrimRight(newLine, "\r\n"), newFileHeader))
+			if err != nil {
+				return nil, err
+			}
+			if !inGitHeader {
+				current = &FilePatch{}
+				patches = append(patches, current)
+			}
+			current.OldPath, current.NewPath = oldName, newName
+			inGitHeader = false
+
+		case strings.HasPrefix(header, hunkHeader) && current != nil:
+			hunk, err := p.parseHunk(header)
+			if err != nil {
+				return nil, err
+			}
+			current.Hunks = append(current.Hunks, hunk)
+			inGitHeader = false
+
+		case (strings.HasPrefix(header, binaryFilesHeader) || header == gitBinaryPatchHeader) && current != nil:
+			current.Binary = true
+			inGitHeader = false
+
+		case inGitHeader:
+			if err := p.parseExtendedHeader(current, header); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, fp := range patches {
+		if fp.OldPath == "" && fp.NewPath == "" {
+			return nil, errors.New("diff header lacks file name information")
+		}
+	}
+	return patches, nil
+}
+
+// parseExtendedHeader applies a Git extended header line, such as a mode change or rename, to fp.
+func (p *parser) parseExtendedHeader(fp *FilePatch, header string) error {
+	var err error
+	switch {
+	case strings.HasPrefix(header, oldModeHeader):
+		fp.OldMode, err = parseMode(strings.TrimPrefix(header, oldModeHeader))
+	case strings.HasPrefix(header, newModeHeader):
+		fp.NewMode, err = parseMode(strings.TrimPrefix(header, newModeHeader))
+	case strings.HasPrefix(header, deletedFileHeader):
+		fp.OldMode, err = parseMode(strings.TrimPrefix(header, deletedFileHeader))
+		fp.NewPath = ""
+	case strings.HasPrefix(header, newFileModeHeader):
+		fp.NewMode, err = parseMode(strings.TrimPrefix(header, newFileModeHeader))
+		fp.OldPath = ""
+	case strings.HasPrefix(header, renameFromHeader):
+		fp.OldPath, err = unquoteName(strings.TrimPrefix(header, renameFromHeader))
+	case strings.HasPrefix(header, renameToHeader):
+		fp.NewPath, err = unquoteName(strings.TrimPrefix(header, renameToHeader))
+	case strings.HasPrefix(header, copyFromHeader):
+		fp.OldPath, err = unquoteName(strings.TrimPrefix(header, copyFromHeader))
+		fp.Copy = true
+	case strings.HasPrefix(header, copyToHeader):
+		fp.NewPath, err = unquoteName(strings.TrimPrefix(header, copyToHeader))
+		fp.Copy = true
+	case strings.HasPrefix(header, indexHeader):
+		// "index <old>..<new> <mode>" records the mode of files whose mode did not change
+		fields := strings.Fields(strings.TrimPrefix(header, indexHeader))
+		if len(fields) == 2 && fp.OldMode == "" && fp.NewMode == "" {
+			mode, modeErr := parseMode(fields[1])
+			fp.OldMode, fp.NewMode, err = mode, mode, modeErr
+		}
+	case strings.HasPrefix(header, similarityHeader), strings.HasPrefix(header, dissimilarityHeader):
+	}
+	return err
+}
+
+// parseMode validates a file mode from a Git extended header.
+func parseMode(value string) (objects.FileMode, error) {
+	mode := objects.FileMode(strings.TrimSpace(value))
+	if !mode.IsValid() || mode == objects.ModeDirectory {
+		return "", fmt.Errorf("invalid file mode %q", value)
+	}
+	return mode, nil
+}
+
+// parseHunk reads hunk lines following header until its line counts are used up.
+func (p *parser) parseHunk(header string) (Hunk, error) {
+	match := hunkHeaderPattern.FindStringSubmatch(header)
+	if match == nil {
+		return Hunk{}, fmt.Errorf("malformed hunk header %q", header)
+	}
+	hunk := Hunk{
+		OldStart: atoiDefault(match[1], 0),
+		OldLines: atoiDefault(match[2], 1),
+		NewStart: atoiDefault(match[3], 0),
+		NewLines: atoiDefault(match[4], 1),
+	}
+
+	oldLeft, newLeft := hunk.OldLines, hunk.NewLines
+	for oldLeft > 0 || newLeft > 0 {
+		line, ok, err := p.next()
+		if err != nil {
+			return Hunk{}, err
+		}
+		if !ok {
+			return Hunk{}, fmt.Errorf("truncated hunk %q", header)
+		}
+
+		op := line[0]
+		text := line[1:]
+		if line == "\n" || line == "\r\n" {
+			// Blank context lines often lose their leading space in transit
+			op, text = ' ', line
+		}
+		switch op {
+		case ' ':
+			oldLeft--
+			newLeft--
+		case '-':
+			oldLeft--
+		case '+':
+			newLeft--
+		case noNewlineMarker:
+			if err := markNoNewline(hunk.Lines); err != nil {
+				return Hunk{}, err
+			}
+			continue
+		default:
+			return Hunk{}, fmt.Errorf("malformed hunk line %q", strings.TrimRight(line, "\n"))
+		}
+		if oldLeft < 0 || newLeft < 0 {
+			return Hunk{}, fmt.Errorf("hunk %q has more lines than its header counts", header)
+		}
+		hunk.Lines = append(hunk.Lines, Line{Op: op, Text: text})
+	}
+
+	// The last line may be followed by its "\ No newline at end of file" marker
+	line, ok, err := p.next()
+	if err != nil {
+		return Hunk{}, err
+	}
+	if ok && line[0] == noNewlineMarker {
+		if err := markNoNewline(hunk.Lines); err != nil {
+			return Hunk{}, err
+		}
+	} else if ok {
+		p.unread(line)
+	}
+	return hunk, nil
+}
+
+// markNoNewline removes the newline of the last hunk line.
+func markNoNewline(lines []Line) error {
+	if len(lines) == 0 {
+		return errors.New("no newline marker before any hunk line")
+	}
+	last := &lines[len(lines)-1]
+	last.Text = strings.TrimSuffix(last.Text, "\n")
+	return nil
+}
+
+// atoiDefault parses a hunk header number, returning fallback when it is absent.
+func atoiDefault(value string, fallback int) int {
+	if value == "" {
+		return fallback
+	}
+	number, _ := strconv.Atoi(value)
+	return number
+}
+
+// headerName extracts the file name from a ---/+++ header, dropping any timestamp
+// after a tab and strip leading components. /dev/null yields an empty name.
+func (p *parser) headerName(value string) (string, error) {
+	name, _, _ := strings.Cut(value, "\t")
+	name, err := unquoteName(name)
+	if err != nil {
+		return "", err
+	}
+	if name == devNull {
+		return "", nil
+	}
+	return stripComponents(name, p.strip)
+}
+
+// gitHeaderName returns the file name from "diff --git a/<name> b/<name>" when both names agree
+// once stripped. Renames and quoted names are named by later headers, so "" is returned for them.
+func (p *parser) gitHeaderName(names string) (string, error) {
+	if strings.HasPrefix(names, `"`) {
+		return "", nil
+	}
+	for i := strings.IndexByte(names, ' '); i >= 0; {
+		oldName, oldErr := stripComponents(names[:i], p.strip)
+		newName, newErr := stripComponents(names[i+1:], p.strip)
+		if oldErr == nil && newErr == nil && oldName == newName {
+			return oldName, nil
+		}
+
+		next := strings.IndexByte(names[i+1:], ' ')
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return "", nil
+}
+
+// unquoteName decodes a C-style quoted name, as Git writes names with special characters.
+func unquoteName(name string) (string, error) {
+	if !strings.HasPrefix(name, `"`) {
+		return name, nil
+	}
+	unquoted, err := strconv.Unquote(name)
+	if err != nil {
+		return "", fmt.Errorf("invalid quoted name %s: %w", name, err)
+	}
+	return unquoted, nil
+}
+
+// stripComponents removes count leading slash-separated components from name.
+func stripComponents(name string, count int) (string, error) {
+	stripped := name
+	for range count {
+		_, rest, found := strings.Cut(stripped, "/")
+		if !found {
+			return "", fmt.Errorf("cannot strip %d leading components from %s", count, name)
+		}
+		stripped = rest
+	}
+	return stripped, nil
+}
@@ -0,0 +1,152 @@
+package patch
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/objects"
+)
+
+const gitPatch = `From 1234 Mon Sep 17 00:00:00 2001
+Subject: [PATCH] Example
+
+diff --git a/a.txt b/a.txt
+old mode 100644
+new mode 100755
+index e8823e1..2dea7a2
+--- a/a.txt
++++ b/a.txt
+@@ -2,3 +2,3 @@
+ 2
+-3
++three
+ 4
+diff --git a/old.txt b/dir/new.txt
+similarity index 90%
+rename from old.txt
+rename to dir/new.txt
+diff --git a/gone.txt b/gone.txt
+deleted file mode 100644
+index b77b4eb..0000000
+--- a/gone.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-x
+diff --git a/n.txt b/n.txt
+new file mode 100644
+index 0000000..07f33c4
+--- /dev/null
++++ b/n.txt
+@@ -0,0 +1,2 @@
++new
++
+\ No newline at end of file
+--
+2.40.0
+`
+
+// TestParse_GitPatch verifies names, modes, renames, creations, deletions and hunk lines.
+func TestParse_GitPatch(t *testing.T) {
+	patches, err := Parse(strings.NewReader(gitPatch), 1)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(patches) != 4 {
+		t.Fatalf("Expected 4 file patches, got %d", len(patches))
+	}
+
+	modified := patches[0]
+	if modified.OldPath != "a.txt" || modified.NewPath != "a.txt" {
+		t.Errorf("Expected a.txt on both sides, got %q and %q", modified.OldPath, modified.NewPath)
+	}
+	if modified.OldMode != objects.ModeRegularFile || modified.NewMode != objects.ModeExecutable {
+		t.Errorf("Expected mode change 100644 -> 100755, got %s -> %s", modified.OldMode, modified.NewMode)
+	}
+	expectedLines := []Line{{' ', "2\n"}, {'-', "3\n"}, {'+', "three\n"}, {' ', "4\n"}}
+	if hunk := modified.Hunks[0]; hunk.OldStart != 2 || hunk.NewLines != 3 || !slices.Equal(hunk.Lines, expectedLines) {
+		t.Errorf("Unexpected hunk %+v", hunk)
+	}
+
+	renamed := patches[1]
+	if renamed.OldPath != "old.txt" || renamed.NewPath != "dir/new.txt" || len(renamed.Hunks) != 0 {
+		t.Errorf("Expected pure rename old.txt -> dir/new.txt, got %+v", renamed)
+	}
+
+	if deleted := patches[2]; !deleted.IsDeletion() || deleted.OldPath != "gone.txt" {
+		t.Errorf("Expected deletion of gone.txt, got %+v", deleted)
+	}
+
+	created := patches[3]
+	if !created.IsCreation() || created.NewPath != "n.txt" || created.NewMode != objects.ModeRegularFile {
+		t.Errorf("Expected creation of n.txt, got %+v", created)
+	}
+	if lines := created.Hunks[0].Lines; !slices.Equal(lines, []Line{{'+', "new\n"}, {'+', ""}}) {
+		t.Errorf("Expected blank added line without newline, got %q", lines)
+	}
+}
+
+// TestParse_PlainDiff verifies diff -u headers with timestamps, strip levels and quoted names.
+func TestParse_PlainDiff(t *testing.T) {
+	input := "--- orig/src/x.c\t2024-01-01 00:00:00\n+++ \"work/src/\\tab.c\"\t2024-01-02 00:00:00\n@@ -1 +1 @@\n-a\n+b\n"
+
+	tests := []struct {
+		strip   int
+		oldPath string
+		newPath string
+	}{
+		{0, "orig/src/x.c", "work/src/\tab.c"},
+		{2, "x.c", "\tab.c"},
+	}
+	for _, tt := range tests {
+		patches, err := Parse(strings.NewReader(input), tt.strip)
+		if err != nil {
+			t.Fatalf("Parse with strip %d failed: %v", tt.strip, err)
+		}
+		if patches[0].OldPath != tt.oldPath || patches[0].NewPath != tt.newPath {
+			t.Errorf("Strip %d: expected %q and %q, got %q and %q",
+				tt.strip, tt.oldPath, tt.newPath, patches[0].OldPath, patches[0].NewPath)
+		}
+	}
+
+	if _, err := Parse(strings.NewReader(input), 3); err == nil {
+		t.Error("Expected error stripping more components than the name has")
+	}
+}
+
+// TestParse_Malformed verifies truncated hunks and bad lines are rejected.
+func TestParse_Malformed(t *testing.T) {
+	tests := map[string]string{
+		"truncated hunk": "--- a/x\n+++ b/x\n@@ -1,2 +1,2 @@\n a\n",
+		"bad hunk line":  "--- a/x\n+++ b/x\n@@ -1 +1 @@\n*a\n+b\n",
+		"no file names":  "diff --git \"a/x\" \"b/x\"\nindex 1..2\n",
+	}
+	for name, input := range tests {
+		if _, err := Parse(strings.NewReader(input), 1); err == nil {
+			t.Errorf("%s: expected parse error", name)
+		}
+	}
+}
+
+// TestReverse verifies reversal swaps sides, ranges and line operations.
+func TestReverse(t *testing.T) {
+	patches, err := Parse(strings.NewReader(gitPatch), 1)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	fp := patches[3]
+	if err := fp.Reverse(); err != nil {
+		t.Fatalf("Reverse failed: %v", err)
+	}
+	if !fp.IsDeletion() || fp.OldPath != "n.txt" || fp.OldMode != objects.ModeRegularFile {
+		t.Errorf("Expected reversed creation to delete n.txt, got %+v", fp)
+	}
+	if hunk := fp.Hunks[0]; hunk.OldLines != 2 || hunk.NewLines != 0 || hunk.Lines[0].Op != '-' {
+		t.Errorf("Unexpected reversed hunk %+v", hunk)
+	}
+
+	if err := (&FilePatch{OldPath: "a", NewPath: "b", Copy: true}).Reverse(); err == nil {
+		t.Error("Expected error reversing a copy")
+	}
+}
@@ -0,0 +1,218 @@
+// Package pathspec selects repository paths the way Git pathspecs do: by exact path,
+// leading directory or wildcard, with magic prefixes changing how each pattern applies.
+package pathspec
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// wildcards are the characters that make a pattern a glob.
+const wildcards = `*?[\`
+
+// Pathspec is a parsed list of patterns. A path is selected when it matches any pattern
+// that is not an exclusion, or when there are only exclusions, and matches no exclusion.
+// A nil or empty Pathspec selects every path.
+type Pathspec struct {
+	includes []item
+	excludes []item
+}
+
+// item is one pattern, already relative to the repository root.
+type item struct {
+	// original is the pattern as given, magic included
+	original string
+	// pattern is slash-separated and lowercased with icase; a trailing slash only
+	// matches paths inside a directory
+	pattern string
+	// literalLength is the length of the pattern before its first wildcard
+	literalLength int
+	icase         bool
+	glob          bool
+}
+
+// Parse parses patterns given relative to prefix, the slash-separated directory of the
+// repository a command runs in ("" at the root). Each pattern may start with magic:
+//
+//	:(top)pattern or :/pattern      relative to the root instead of prefix
+//	:(exclude)pattern, :!pattern    exclude matching paths (":^" works too)
+//	:(icase)pattern                 match case-insensitively
+//	:(glob)pattern                  "*" stops at "/" and "**" matches across directories
+//
+// Long form magic words are separated by commas, as in ":(top,icase)pattern".
+func Parse(prefix string, patterns []string) (*Pathspec, error) {
+	ps := &Pathspec{}
+	for _, pattern := range patterns {
+		parsed, exclude, err := parseItem(prefix, pattern)
+		if err != nil {
+			return nil, err
+		}
+		if exclude {
+			ps.excludes = append(ps.excludes, parsed)
+		} else {
+			ps.includes = append(ps.includes, parsed)
+		}
+	}
+	return ps, nil
+}
+
+// parseItem parses one pattern and its magic, resolving it against prefix.
+func parseItem(prefix, original string) (item, bool, error) {
+	parsed := item{original: original}
+	pattern := original
+	top, exclude := false, false
+
+	switch {
+	case strings.HasPrefix(pattern, ":("):
+		end := strings.IndexByte(pattern, ')')
+		if end < 0 {
+			return item{}, false, fmt.Errorf("missing ')' at the end of pathspec magic in '%s'", original)
+		}
+		for _, magic := range strings.Split(pattern[2:end], ",") {
+			switch strings.TrimSpace(magic) {
+			case "top":
+				top = true
+			case "exclude":
+				exclude = true
+			case "icase":
+				parsed.icase = true
+			case "glob":
+				parsed.glob = true
+			default:
+				return item{}, false, fmt.Errorf("invalid pathspec magic '%s' in '%s'", magic, original)
+			}
+		}
+		pattern = pattern[end+1:]
+	case strings.HasPrefix(pattern, ":"):
+		i := 1
+		for ; i < len(pattern) && strings.IndexByte("/!^", pattern[i]) >= 0; i++ {
+			if pattern[i] == '/' {
+				top = true
+			} else {
+				exclude = true
+			}
+		}
+		if i < len(pattern) && pattern[i] == ':' {
+			i++
+		}
+		pattern = pattern[i:]
+	}
+
+	if !top && prefix != "" {
+		pattern = prefix + "/" + pattern
+	}
+	directory := strings.HasSuffix(pattern, "/")
+	pattern = path.Clean(pattern)
+	if pattern == "." {
+		pattern = ""
+	}
+	if pattern == ".." || strings.HasPrefix(pattern, "../") || path.IsAbs(pattern) {
+		return item{}, false, fmt.Errorf("'%s' is outside repository", original)
+	}
+	if directory && pattern != "" {
+		pattern += "/"
+	}
+
+	if parsed.icase {
+		pattern = strings.ToLower(pattern)
+	}
+	parsed.pattern = pattern
+	parsed.literalLength = len(pattern)
+	if i := strings.IndexAny(pattern, wildcards); i >= 0 {
+		parsed.literalLength = i
+	}
+	return parsed, exclude, nil
+}
+
+// Match reports whether path, a slash-separated file path from the repository root, is selected.
+func (ps *Pathspec) Match(path string) bool {
+	if ps == nil {
+		return true
+	}
+	for _, exclude := range ps.excludes {
+		if exclude.match(path) {
+			return false
+		}
+	}
+	if len(ps.includes) == 0 {
+		return true
+	}
+	for _, include := range ps.includes {
+		if include.match(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unmatched returns the patterns, as given, that select none of paths. Exclusions are
+// never reported.
+func (ps *Pathspec) Unmatched(paths []string) []string {
+	if ps == nil {
+		return nil
+	}
+	var unmatched []string
+	for _, include := range ps.includes {
+		if !slices.ContainsFunc(paths, include.match) {
+			unmatched = append(unmatched, include.original)
+		}
+	}
+	return unmatched
+}
+
+// MatchDirectory reports whether a listing that does not descend into directories should
+// show directory dir: a pattern names dir or reaches into it, or a wildcard matches dir
+// itself. Exclusions never hide a directory, since they may not cover all of it.
+func (ps *Pathspec) MatchDirectory(dir string) bool {
+	if ps == nil || len(ps.includes) == 0 {
+		return true
+	}
+	for _, include := range ps.includes {
+		name := include.fold(dir)
+		if include.match(dir) || strings.HasPrefix(include.pattern[:include.literalLength], name+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// MayMatchInside reports whether any path inside directory dir could be selected, so
+// callers can skip directories that cannot contain matches.
+func (ps *Pathspec) MayMatchInside(dir string) bool {
+	if ps == nil || len(ps.includes) == 0 {
+		return true
+	}
+	for _, include := range ps.includes {
+		name := include.fold(dir) + "/"
+		literal := include.pattern[:include.literalLength]
+		if strings.HasPrefix(name, literal) || strings.HasPrefix(literal, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// match reports whether path equals the pattern, lies inside the directory it names, or
+// matches it as a wildcard.
+func (it item) match(path string) bool {
+	path = it.fold(path)
+	if it.pattern == "" || path == it.pattern {
+		return true
+	}
+
+	literal := it.pattern[:it.literalLength]
+	if it.literalLength == len(it.pattern) {
+		return strings.HasPrefix(path, strings.TrimSuffix(literal, "/")+"/")
+	}
+	return strings.HasPrefix(path, literal) && Wildmatch(it.pattern, path, it.glob)
+}
+
+// fold lowercases name for case-insensitive patterns.
+func (it item) fold(name string) string {
+	if it.icase {
+		return strings.ToLower(name)
+	}
+	return name
+}
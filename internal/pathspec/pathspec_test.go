@@ -0,0 +1,124 @@
+package pathspec
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParse_Errors verifies that unknown magic, unterminated magic and paths leaving the
+// repository are rejected.
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		prefix   string
+		pattern  string
+		expected string
+	}{
+		{"", ":(bogus)x", "invalid pathspec magic 'bogus'"},
+		{"", ":(top", "missing ')'"},
+		{"", "../x", "is outside repository"},
+		{"sub", "../../x", "is outside repository"},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(test.prefix, []string{test.pattern})
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("Parse(%q, %q): expected error containing %q, got %v", test.prefix, test.pattern, test.expected, err)
+		}
+	}
+}
+
+// TestPathspec_Match verifies literal, wildcard and magic patterns against file paths.
+func TestPathspec_Match(t *testing.T) {
+	paths := []string{"a.c", "b.txt", "dir/x.c", "dir/sub/y.c", "dirt", "Doc/README"}
+
+	tests := []struct {
+		name     string
+		prefix   string
+		patterns []string
+		expected []string
+	}{
+		{name: "empty", expected: paths},
+		{name: "exact file", patterns: []string{"dir/x.c"}, expected: []string{"dir/x.c"}},
+		{name: "leading directory", patterns: []string{"dir"}, expected: []string{"dir/x.c", "dir/sub/y.c"}},
+		{name: "trailing slash", patterns: []string{"dir/"}, expected: []string{"dir/x.c", "dir/sub/y.c"}},
+		{name: "star crosses directories", patterns: []string{"*.c"}, expected: []string{"a.c", "dir/x.c", "dir/sub/y.c"}},
+		{name: "glob star stops at slash", patterns: []string{":(glob)*.c"}, expected: []string{"a.c"}},
+		{name: "glob double star", patterns: []string{":(glob)**/*.c"}, expected: []string{"a.c", "dir/x.c", "dir/sub/y.c"}},
+		{name: "icase", patterns: []string{":(icase)doc/readme"}, expected: []string{"Doc/README"}},
+		{name: "exclude only", patterns: []string{":!*.c"}, expected: []string{"b.txt", "dirt", "Doc/README"}},
+		{name: "include and exclude", patterns: []string{"dir", ":(exclude)dir/sub"}, expected: []string{"dir/x.c"}},
+		{name: "prefix", prefix: "dir", patterns: []string{"*.c"}, expected: []string{"dir/x.c", "dir/sub/y.c"}},
+		{name: "top", prefix: "dir", patterns: []string{":/a.c"}, expected: []string{"a.c"}},
+		{name: "dot in prefix", prefix: "dir", patterns: []string{"."}, expected: []string{"dir/x.c", "dir/sub/y.c"}},
+		{name: "parent of prefix", prefix: "dir/sub", patterns: []string{"../x.c"}, expected: []string{"dir/x.c"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ps, err := Parse(test.prefix, test.patterns)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			var got []string
+			for _, path := range paths {
+				if ps.Match(path) {
+					got = append(got, path)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(test.expected, ",") {
+				t.Errorf("Expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+// TestPathspec_Directories verifies which directories a listing shows and which a walk
+// must descend into.
+func TestPathspec_Directories(t *testing.T) {
+	tests := []struct {
+		pattern    string
+		dir        string
+		showDir    bool
+		mayDescend bool
+	}{
+		{"dir/x.c", "dir", true, true},
+		{"dir/x.c", "other", false, false},
+		{"dir/*.c", "dir", true, true},
+		{"d*", "dir", true, true},
+		{"*.c", "dir", false, true},
+		{":(glob)**/*.c", "dir", false, true},
+		{":(icase)DIR", "dir", true, true},
+		{":!dir", "dir", true, true},
+	}
+
+	for _, test := range tests {
+		ps, err := Parse("", []string{test.pattern})
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		if got := ps.MatchDirectory(test.dir); got != test.showDir {
+			t.Errorf("%q: MatchDirectory(%q) = %v, expected %v", test.pattern, test.dir, got, test.showDir)
+		}
+		if got := ps.MayMatchInside(test.dir); got != test.mayDescend {
+			t.Errorf("%q: MayMatchInside(%q) = %v, expected %v", test.pattern, test.dir, got, test.mayDescend)
+		}
+	}
+
+	var nilSpec *Pathspec
+	if !nilSpec.Match("any") || !nilSpec.MatchDirectory("any") || !nilSpec.MayMatchInside("any") {
+		t.Error("Expected a nil pathspec to select everything")
+	}
+}
+
+// TestPathspec_Unmatched verifies that patterns selecting nothing are reported as given.
+func TestPathspec_Unmatched(t *testing.T) {
+	ps, err := Parse("", []string{"*.c", "missing", ":(icase)DIR", ":!nothing"})
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	unmatched := ps.Unmatched([]string{"a.c", "dir/x"})
+	if len(unmatched) != 1 || unmatched[0] != "missing" {
+		t.Errorf("Expected [missing], got %v", unmatched)
+	}
+}
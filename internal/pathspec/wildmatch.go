@@ -0,0 +1,227 @@
+package pathspec
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Results of matching the rest of a pattern, as in Git's wildmatch. The abort results
+// stop backtracking early: no later position of an enclosing "*" can match either.
+const (
+	wildMatch = iota
+	wildNoMatch
+	wildAbortAll
+	wildAbortToStarStar
+)
+
+// Wildmatch reports whether text matches the shell glob pattern. Without pathname, "*"
+// also matches "/"; with it, "*", "?" and classes stop at "/" and "**" spans directories
+// when it forms a whole path component, as in "**/", "/**/" or "/**".
+func Wildmatch(pattern, text string, pathname bool) bool {
+	m := &wildMatcher{pattern: pattern, text: text, pathname: pathname}
+	return m.match(0, 0) == wildMatch
+}
+
+// wildMatcher holds one pattern and text while their suffixes are matched recursively.
+type wildMatcher struct {
+	pattern  string
+	text     string
+	pathname bool
+}
+
+// match matches pattern[p:] against text[t:].
+func (m *wildMatcher) match(p, t int) int {
+	for ; p < len(m.pattern); p, t = p+1, t+1 {
+		c := m.pattern[p]
+		if t == len(m.text) && c != '*' {
+			return wildAbortAll
+		}
+
+		switch c {
+		case '\\':
+			// Literal match with the following character
+			p++
+			if p == len(m.pattern) || m.text[t] != m.pattern[p] {
+				return wildNoMatch
+			}
+		case '?':
+			if m.pathname && m.text[t] == '/' {
+				return wildNoMatch
+			}
+		case '*':
+			result, next, done := m.matchStar(p, t)
+			if done {
+				return result
+			}
+			// A single "*" before "/" matched up to the next slash, consumed by the loop
+			p, t = next, strings.IndexByte(m.text[t:], '/')+t
+		case '[':
+			end, matched, ok := m.matchClass(p, m.text[t])
+			if !ok {
+				return wildAbortAll
+			}
+			if !matched || (m.pathname && m.text[t] == '/') {
+				return wildNoMatch
+			}
+			p = end
+		default:
+			if m.text[t] != c {
+				return wildNoMatch
+			}
+		}
+	}
+
+	if t < len(m.text) {
+		return wildNoMatch
+	}
+	return wildMatch
+}
+
+// matchStar matches a run of asterisks starting at pattern[p] against text[t:]. It returns
+// done with the final result, or the position of the slash following a single "*" whose
+// match ends at the next slash of text.
+func (m *wildMatcher) matchStar(p, t int) (result, next int, done bool) {
+	start := p
+	for p < len(m.pattern) && m.pattern[p] == '*' {
+		p++
+	}
+
+	// Without pathname, "*" already matches "/"
+	matchSlash := !m.pathname
+	if p-start > 1 {
+		atComponentStart := start == 0 || m.pattern[start-1] == '/'
+		atComponentEnd := p == len(m.pattern) || m.pattern[p] == '/' || strings.HasPrefix(m.pattern[p:], `\/`)
+		matchSlash = atComponentStart && atComponentEnd
+		if matchSlash && p < len(m.pattern) && m.pattern[p] == '/' && m.match(p+1, t) == wildMatch {
+			// "**/" may match no directories at all
+			return wildMatch, 0, true
+		}
+	}
+
+	if p == len(m.pattern) {
+		// A trailing "**" matches everything, a trailing "*" only the last path component
+		if !matchSlash && strings.Contains(m.text[t:], "/") {
+			return wildAbortToStarStar, 0, true
+		}
+		return wildMatch, 0, true
+	}
+	if !matchSlash && m.pattern[p] == '/' {
+		if !strings.Contains(m.text[t:], "/") {
+			return wildAbortAll, 0, true
+		}
+		return 0, p, false
+	}
+
+	for ; t < len(m.text); t++ {
+		matched := m.match(p, t)
+		if matched != wildNoMatch {
+			if !matchSlash || matched != wildAbortToStarStar {
+				return matched, 0, true
+			}
+		} else if !matchSlash && m.text[t] == '/' {
+			return wildAbortToStarStar, 0, true
+		}
+	}
+	return wildAbortAll, 0, true
+}
+
+// matchClass matches c against the bracket expression starting at pattern[p]. It returns
+// the position of the closing bracket and whether c is in the class; ok is false for an
+// unterminated class.
+func (m *wildMatcher) matchClass(p int, c byte) (end int, matched, ok bool) {
+	p++
+	negated := false
+	if p < len(m.pattern) && (m.pattern[p] == '!' || m.pattern[p] == '^') {
+		negated = true
+		p++
+	}
+
+	var previous byte
+	hasPrevious := false
+	// A "]" right after the opening bracket is a literal member
+	for first := true; first || (p < len(m.pattern) && m.pattern[p] != ']'); first, p = false, p+1 {
+		if p >= len(m.pattern) {
+			return 0, false, false
+		}
+		member := m.pattern[p]
+
+		switch {
+		case member == '\\':
+			p++
+			if p == len(m.pattern) {
+				return 0, false, false
+			}
+			member = m.pattern[p]
+			matched = matched || c == member
+		case member == '-' && hasPrevious && p+1 < len(m.pattern) && m.pattern[p+1] != ']':
+			p++
+			upper := m.pattern[p]
+			if upper == '\\' {
+				p++
+				if p == len(m.pattern) {
+					return 0, false, false
+				}
+				upper = m.pattern[p]
+			}
+			matched = matched || (c >= previous && c <= upper)
+			hasPrevious = false
+			continue
+		case member == '[' && p+1 < len(m.pattern) && m.pattern[p+1] == ':':
+			closing := strings.Index(m.pattern[p+2:], ":]")
+			if closing < 0 {
+				return 0, false, false
+			}
+			name := m.pattern[p+2 : p+2+closing]
+			inClass, known := characterClass(name, c)
+			if !known {
+				return 0, false, false
+			}
+			matched = matched || inClass
+			p += 2 + closing + 1
+			hasPrevious = false
+			continue
+		default:
+			matched = matched || c == member
+		}
+		previous, hasPrevious = member, true
+	}
+
+	if p >= len(m.pattern) {
+		return 0, false, false
+	}
+	return p, matched != negated, true
+}
+
+// characterClass reports whether c belongs to the named POSIX class, and whether the
+// name is known.
+func characterClass(name string, c byte) (inClass, known bool) {
+	r := rune(c)
+	switch name {
+	case "alnum":
+		return unicode.IsLetter(r) || unicode.IsDigit(r), true
+	case "alpha":
+		return unicode.IsLetter(r), true
+	case "blank":
+		return c == ' ' || c == '\t', true
+	case "cntrl":
+		return unicode.IsControl(r), true
+	case "digit":
+		return c >= '0' && c <= '9', true
+	case "graph":
+		return unicode.IsGraphic(r) && c != ' ', true
+	case "lower":
+		return unicode.IsLower(r), true
+	case "print":
+		return unicode.IsPrint(r), true
+	case "punct":
+		return unicode.IsPunct(r) || unicode.IsSymbol(r), true
+	case "space":
+		return unicode.IsSpace(r), true
+	case "upper":
+		return unicode.IsUpper(r), true
+	case "xdigit":
+		return strings.IndexByte("0123456789abcdefABCDEF", c) >= 0, true
+	default:
+		return false, false
+	}
+}
@@ -0,0 +1,59 @@
+package pathspec
+
+import "testing"
+
+// TestWildmatch verifies wildcards, classes and escapes with and without pathname
+// matching, using cases from Git's wildmatch tests.
+func TestWildmatch(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		text     string
+		pathname bool
+		expected bool
+	}{
+		{"foo", "foo", true, true},
+		{"bar", "foo", true, false},
+		{"???", "foo", true, true},
+		{"??", "foo", true, false},
+		{"*", "foo", true, true},
+		{"f*", "foo", true, true},
+		{"*f", "foo", true, false},
+		{"*foo*", "foo", true, true},
+		{"*ob*a*r*", "foobar", true, true},
+		{"*ab", "aaaaaaabababab", true, true},
+		{`foo\*`, "foo*", true, true},
+		{`foo\*bar`, "foobar", true, false},
+		{"t[a-g]n", "ten", true, true},
+		{"t[!a-g]n", "ten", true, false},
+		{"t[!a-g]n", "ton", true, true},
+		{"t[^a-g]n", "ton", true, true},
+		{"a[]]b", "a]b", true, true},
+		{"a[]-]b", "a-b", true, true},
+		{"a[]-]b", "aab", true, false},
+		{"[[:digit:]]", "5", true, true},
+		{"[[:upper:]]", "a", true, false},
+		{"[[:alpha:][:digit:]]", "7", true, true},
+		{"[[:bogus:]]", "a", true, false},
+		{"[abc", "a", true, false},
+		{"foo/*", "foo/bar/baz", true, false},
+		{"foo/*", "foo/bar/baz", false, true},
+		{"foo?bar", "foo/bar", true, false},
+		{"foo?bar", "foo/bar", false, true},
+		{"foo[/]bar", "foo/bar", true, false},
+		{"*/bar", "foo/bar", true, true},
+		{"*/bar", "a/foo/bar", true, false},
+		{"**/foo", "foo", true, true},
+		{"**/foo", "a/b/foo", true, true},
+		{"foo/**/bar", "foo/bar", true, true},
+		{"foo/**/bar", "foo/a/b/bar", true, true},
+		{"foo**bar", "foo/baz/bar", true, false},
+		{"a/**", "a/b/c", true, true},
+		{"a/**", "a", true, false},
+	}
+
+	for _, test := range tests {
+		if got := Wildmatch(test.pattern, test.text, test.pathname); got != test.expected {
+			t.Errorf("Wildmatch(%q, %q, %v) = %v, expected %v", test.pattern, test.text, test.pathname, got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+// Package refs reads references stored in the metadata directory of a repository, .gogit by default.
+// Every function takes the path of that directory as gitDir.
+package refs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/lockfile"
+)
+
+// maxSymbolicDepth bounds symbolic ref chains so cycles fail instead of looping.
+const maxSymbolicDepth = 5
+
+// ErrRefNotFound is returned when a reference does not exist or points nowhere yet.
+var ErrRefNotFound = errors.New("ref not found")
+
+// Ref is a named pointer to an object.
+type Ref struct {
+	Name string // Full name, such as "refs/tags/v1.0"
+	Hash string
+}
+
+// Resolve returns object hash that ref name ("HEAD" or "refs/...") points to.
+// Symbolic refs such as HEAD are followed to their target.
+func Resolve(gitDir, name string) (string, error) {
+	for range maxSymbolicDepth {
+		value, err := readRef(gitDir, name)
+		if err != nil {
+			return "", err
+		}
+
+		target, symbolic := strings.CutPrefix(value, constants.SymbolicRefPrefix)
+		if !symbolic {
+			return value, nil
+		}
+		name = target
+	}
+	return "", fmt.Errorf("symbolic ref chain too deep at %s", name)
+}
+
+// SymbolicTarget returns the ref that symbolic ref name, such as HEAD, points to, or ""
+// when name holds a hash directly. The target itself need not exist.
+func SymbolicTarget(gitDir, name string) (string, error) {
+	value, err := readRef(gitDir, name)
+	if err != nil {
+		return "", err
+	}
+	target, _ := strings.CutPrefix(value, constants.SymbolicRefPrefix)
+	if target == value {
+		return "", nil
+	}
+	return target, nil
+}
+
+// Expand finds the ref a short name such as "main" or "v1.0" refers to, trying the name itself,
+// then refs/, refs/tags/ and refs/heads/ prefixes in Git's order. The name itself is only tried
+// when it is a full ref name or a pseudo-ref such as HEAD, ORIG_HEAD, MERGE_HEAD or FETCH_HEAD,
+// so other files in the metadata directory are never taken for refs.
+func Expand(gitDir, name string) (Ref, error) {
+	candidates := []string{constants.Refs + "/" + name, constants.TagRefPrefix + name, constants.BranchRefPrefix + name}
+	if strings.HasPrefix(name, constants.Refs+"/") || isPseudoRefName(name) {
+		candidates = slices.Insert(candidates, 0, name)
+	}
+
+	for _, candidate := range candidates {
+		hash, err := Resolve(gitDir, candidate)
+		if errors.Is(err, ErrRefNotFound) {
+			continue
+		}
+		if err != nil {
+			return Ref{}, err
+		}
+		return Ref{Name: candidate, Hash: hash}, nil
+	}
+	return Ref{}, fmt.Errorf("%w: %s", ErrRefNotFound, name)
+}
+
+// ForEach calls fn with every loose ref whose full name starts with prefix, in directory walk order.
+// Lock files are skipped. Iteration stops at the first error returned by fn, and that error is returned.
+// Only the directory holding refs with the prefix is walked.
+func ForEach(gitDir, prefix string, fn func(ref Ref) error) error {
+	root := constants.Refs
+	if strings.HasPrefix(prefix, constants.Refs+"/") {
+		root = prefix[:strings.LastIndex(prefix, "/")]
+	}
+
+	var fnErr error
+	err := filepath.WalkDir(filepath.Join(gitDir, filepath.FromSlash(root)), func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() || strings.HasSuffix(path, constants.LockSuffix) {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(gitDir, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(relPath)
+		if !strings.HasPrefix(name, prefix) {
+			return nil
+		}
+
+		hash, err := Resolve(gitDir, name)
+		if err != nil {
+			return err
+		}
+		if fnErr = fn(Ref{Name: name, Hash: hash}); fnErr != nil {
+			return fs.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list refs: %w", err)
+	}
+	return fnErr
+}
+
+// List returns loose refs whose full names start with prefix, sorted by name.
+func List(gitDir, prefix string) ([]Ref, error) {
+	var refs []Ref
+	err := ForEach(gitDir, prefix, func(ref Ref) error {
+		refs = append(refs, ref)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(refs, func(a, b Ref) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return refs, nil
+}
+
+// isPseudoRefName reports whether name has the shape of a ref kept at the top of the metadata
+// directory: upper case letters and underscores, as in ORIG_HEAD.
+func isPseudoRefName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if !(c >= 'A' && c <= 'Z' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// readRef returns trimmed content of a ref file, validating direct refs hold a full hash.
+// Anything after the hash is ignored, so files listing several objects with descriptions,
+// such as FETCH_HEAD or the MERGE_HEAD of an octopus merge, resolve to their first object.
+func readRef(gitDir, name string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(gitDir, filepath.FromSlash(name)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("%w: %s", ErrRefNotFound, name)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read ref %s: %w", name, err)
+	}
+
+	value := strings.TrimSpace(string(content))
+	if strings.HasPrefix(value, constants.SymbolicRefPrefix) {
+		return value, nil
+	}
+	if end := strings.IndexAny(value, " \t\n"); end >= 0 {
+		value = value[:end]
+	}
+	if !isHash(value) {
+		return "", fmt.Errorf("invalid ref %s: %q is not an object hash", name, value)
+	}
+	return value, nil
+}
+
+// isHash reports whether value is a full lowercase hex object hash.
+func isHash(value string) bool {
+	if len(value) != constants.HashStringLength {
+		return false
+	}
+	for _, c := range value {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// Update points ref name at hash, creating it if needed.
+// The ref file is written through a lock file, so concurrent writers fail instead of interleaving.
+func Update(gitDir, name, hash string) error {
+	if !isHash(hash) {
+		return fmt.Errorf("invalid hash %q for ref %s", hash, name)
+	}
+
+	path := filepath.Join(gitDir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), constants.DirPerms); err != nil {
+		return fmt.Errorf("failed to create directory for ref %s: %w", name, err)
+	}
+
+	lock, err := lockfile.Acquire(path)
+	if err != nil {
+		return err
+	}
+	defer lock.Rollback()
+
+	if _, err := lock.Write([]byte(hash + "\n")); err != nil {
+		return fmt.Errorf("failed to write ref %s: %w", name, err)
+	}
+	if err := lock.Commit(); err != nil {
+		return fmt.Errorf("failed to update ref %s: %w", name, err)
+	}
+	return nil
+}
+
+// Delete removes ref name, returning ErrRefNotFound if it does not exist.
+// The lock file is held while removing, so concurrent writers fail instead of recreating the ref.
+func Delete(gitDir, name string) error {
+	path := filepath.Join(gitDir, filepath.FromSlash(name))
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrRefNotFound, name)
+	}
+
+	lock, err := lockfile.Acquire(path)
+	if err != nil {
+		return err
+	}
+	defer lock.Rollback()
+
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to delete ref %s: %w", name, err)
+	}
+	return nil
+}
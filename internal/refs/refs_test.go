@@ -0,0 +1,245 @@
+package refs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/lockfile"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// writeRef writes ref file content under .gogit, failing test on error.
+func writeRef(t *testing.T, repoPath, name, content string) {
+	t.Helper()
+
+	path := filepath.Join(repoPath, constants.Gogit, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), constants.DirPerms); err != nil {
+		t.Fatalf("Failed to create directory for ref %s: %v", name, err)
+	}
+	if err := os.WriteFile(path, []byte(content+"\n"), constants.FilePerms); err != nil {
+		t.Fatalf("Failed to write ref %s: %v", name, err)
+	}
+}
+
+// TestResolve verifies direct refs resolve to their hash and HEAD is followed to its branch.
+func TestResolve(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	gitDir := repository.GitDir(repoPath)
+	hash := testutils.RandomHash()
+
+	if _, err := Resolve(gitDir, constants.Head); !errors.Is(err, ErrRefNotFound) {
+		t.Fatalf("Expected ErrRefNotFound for unborn branch, got %v", err)
+	}
+
+	writeRef(t, repoPath, "refs/heads/"+constants.DefaultBranch, hash)
+	resolved, err := Resolve(gitDir, constants.Head)
+	if err != nil {
+		t.Fatalf("Failed to resolve HEAD: %v", err)
+	}
+	if resolved != hash {
+		t.Errorf("Expected %s, got %s", hash, resolved)
+	}
+}
+
+// TestResolve_Invalid verifies malformed refs and symbolic cycles are rejected.
+func TestResolve_Invalid(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	writeRef(t, repoPath, "refs/heads/bad", "not-a-hash")
+	writeRef(t, repoPath, "refs/heads/loop", constants.SymbolicRefPrefix+"refs/heads/loop")
+
+	for _, name := range []string{"refs/heads/bad", "refs/heads/loop"} {
+		if _, err := Resolve(repository.GitDir(repoPath), name); err == nil {
+			t.Errorf("Expected error resolving %s", name)
+		}
+	}
+}
+
+// TestSymbolicTarget verifies the target of a symbolic ref is returned even when unborn,
+// and direct refs report none.
+func TestSymbolicTarget(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	gitDir := repository.GitDir(repoPath)
+	writeRef(t, repoPath, "refs/heads/direct", testutils.RandomHash())
+
+	target, err := SymbolicTarget(gitDir, constants.Head)
+	if err != nil {
+		t.Fatalf("Failed to read HEAD: %v", err)
+	}
+	if target != "refs/heads/"+constants.DefaultBranch {
+		t.Errorf("Expected HEAD to point to refs/heads/%s, got %q", constants.DefaultBranch, target)
+	}
+
+	target, err = SymbolicTarget(gitDir, "refs/heads/direct")
+	if err != nil {
+		t.Fatalf("Failed to read refs/heads/direct: %v", err)
+	}
+	if target != "" {
+		t.Errorf("Expected no target for a direct ref, got %q", target)
+	}
+}
+
+// TestExpand verifies short names resolve in Git's order, tags before branches.
+func TestExpand(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	gitDir := repository.GitDir(repoPath)
+	tagHash, branchHash := testutils.RandomHash(), testutils.RandomHash()
+	writeRef(t, repoPath, "refs/tags/v1", tagHash)
+	writeRef(t, repoPath, "refs/heads/v1", branchHash)
+	writeRef(t, repoPath, "refs/heads/topic", branchHash)
+
+	tests := []struct {
+		name     string
+		expected Ref
+	}{
+		{"v1", Ref{Name: "refs/tags/v1", Hash: tagHash}},
+		{"heads/v1", Ref{Name: "refs/heads/v1", Hash: branchHash}},
+		{"topic", Ref{Name: "refs/heads/topic", Hash: branchHash}},
+		{"refs/heads/topic", Ref{Name: "refs/heads/topic", Hash: branchHash}},
+	}
+	for _, tt := range tests {
+		ref, err := Expand(gitDir, tt.name)
+		if err != nil {
+			t.Fatalf("Failed to expand %s: %v", tt.name, err)
+		}
+		if ref != tt.expected {
+			t.Errorf("Expected %s to expand to %+v, got %+v", tt.name, tt.expected, ref)
+		}
+	}
+
+	if _, err := Expand(gitDir, "missing"); !errors.Is(err, ErrRefNotFound) {
+		t.Errorf("Expected ErrRefNotFound, got %v", err)
+	}
+}
+
+// TestExpand_PseudoRefs verifies top-level refs resolve to the first object they list, and
+// that other files in the metadata directory are not taken for refs.
+func TestExpand_PseudoRefs(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	gitDir := repository.GitDir(repoPath)
+	first, second := testutils.RandomHash(), testutils.RandomHash()
+	writeRef(t, repoPath, "ORIG_HEAD", first)
+	writeRef(t, repoPath, "MERGE_HEAD", first+"\n"+second)
+	writeRef(t, repoPath, "FETCH_HEAD", first+"\t\tbranch 'main' of ../remote\n"+second+"\tnot-for-merge\tbranch 'topic' of ../remote")
+	writeRef(t, repoPath, "refs/heads/config", second)
+
+	for _, name := range []string{"ORIG_HEAD", "MERGE_HEAD", "FETCH_HEAD"} {
+		ref, err := Expand(gitDir, name)
+		if err != nil {
+			t.Fatalf("Failed to expand %s: %v", name, err)
+		}
+		if ref != (Ref{Name: name, Hash: first}) {
+			t.Errorf("Expected %s to resolve to %s, got %+v", name, first, ref)
+		}
+	}
+
+	ref, err := Expand(gitDir, "config")
+	if err != nil {
+		t.Fatalf("Failed to expand config: %v", err)
+	}
+	if ref.Name != "refs/heads/config" {
+		t.Errorf("Expected config to expand to the branch, got %+v", ref)
+	}
+}
+
+// TestList verifies refs under prefix are listed sorted with lock files skipped.
+func TestList(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	v1, v2, branch := testutils.RandomHash(), testutils.RandomHash(), testutils.RandomHash()
+	writeRef(t, repoPath, "refs/tags/v2", v2)
+	writeRef(t, repoPath, "refs/tags/release/v1", v1)
+	writeRef(t, repoPath, "refs/tags/v3"+constants.LockSuffix, testutils.RandomHash())
+	writeRef(t, repoPath, "refs/heads/"+constants.DefaultBranch, branch)
+
+	tags, err := List(repository.GitDir(repoPath), constants.TagRefPrefix)
+	if err != nil {
+		t.Fatalf("Failed to list refs: %v", err)
+	}
+
+	expected := []Ref{{"refs/tags/release/v1", v1}, {"refs/tags/v2", v2}}
+	if !slices.Equal(tags, expected) {
+		t.Errorf("Expected %v, got %v", expected, tags)
+	}
+}
+
+// TestForEach verifies iteration visits refs under prefix and stops at the first callback error.
+func TestForEach(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	gitDir := repository.GitDir(repoPath)
+	writeRef(t, repoPath, "refs/heads/a", testutils.RandomHash())
+	writeRef(t, repoPath, "refs/heads/b", testutils.RandomHash())
+	writeRef(t, repoPath, "refs/tags/v1", testutils.RandomHash())
+
+	var names []string
+	err := ForEach(gitDir, constants.BranchRefPrefix, func(ref Ref) error {
+		names = append(names, ref.Name)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEach failed: %v", err)
+	}
+	slices.Sort(names)
+	if expected := []string{"refs/heads/a", "refs/heads/b"}; !slices.Equal(names, expected) {
+		t.Errorf("Expected %v, got %v", expected, names)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = ForEach(gitDir, constants.Refs+"/", func(Ref) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("Expected iteration to stop with callback error after 1 call, got %v after %d", err, calls)
+	}
+}
+
+// TestUpdate verifies refs are created with parent directories and fail while locked.
+func TestUpdate(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	gitDir := repository.GitDir(repoPath)
+	hash := testutils.RandomHash()
+
+	if err := Update(gitDir, constants.NotesRef, hash); err != nil {
+		t.Fatalf("Failed to update ref: %v", err)
+	}
+	if resolved, err := Resolve(gitDir, constants.NotesRef); err != nil || resolved != hash {
+		t.Fatalf("Expected %s, got %s, %v", hash, resolved, err)
+	}
+
+	if err := Update(gitDir, constants.NotesRef, "short"); err == nil {
+		t.Error("Expected error for invalid hash")
+	}
+
+	writeRef(t, repoPath, constants.NotesRef+constants.LockSuffix, "")
+	if err := Update(gitDir, constants.NotesRef, testutils.RandomHash()); !errors.Is(err, lockfile.ErrLocked) {
+		t.Errorf("Expected ErrLocked, got %v", err)
+	}
+}
+
+// TestDelete verifies refs are removed, missing refs are reported and locked refs are kept.
+func TestDelete(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	gitDir := repository.GitDir(repoPath)
+	writeRef(t, repoPath, "refs/tags/v1", testutils.RandomHash())
+
+	if err := Delete(gitDir, "refs/tags/v1"); err != nil {
+		t.Fatalf("Failed to delete ref: %v", err)
+	}
+	if _, err := Resolve(gitDir, "refs/tags/v1"); !errors.Is(err, ErrRefNotFound) {
+		t.Errorf("Expected deleted ref to be gone, got %v", err)
+	}
+	if err := Delete(gitDir, "refs/tags/v1"); !errors.Is(err, ErrRefNotFound) {
+		t.Errorf("Expected ErrRefNotFound, got %v", err)
+	}
+
+	writeRef(t, repoPath, "refs/tags/v2", testutils.RandomHash())
+	writeRef(t, repoPath, "refs/tags/v2"+constants.LockSuffix, "")
+	if err := Delete(gitDir, "refs/tags/v2"); !errors.Is(err, lockfile.ErrLocked) {
+		t.Errorf("Expected ErrLocked, got %v", err)
+	}
+}
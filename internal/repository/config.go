@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+)
+
+// scanConfig calls visit for every key of the Git-style config file at configPath, with the
+// lowercased section and key and the raw value. A missing file has no keys.
+// Scanning stops at the first error visit returns.
+func scanConfig(configPath string, visit func(section, key, value string) error) error {
+	file, err := os.Open(configPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	defer file.Close()
+
+	var section string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			section = strings.ToLower(strings.Trim(line, "[]"))
+			continue
+		}
+
+		key, value, _ := strings.Cut(line, "=")
+		if err := visit(section, strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	return nil
+}
+
+// GlobalConfigPath returns the user's config file: GOGIT_CONFIG_GLOBAL if set,
+// otherwise ~/.gogitconfig.
+func GlobalConfigPath() (string, error) {
+	if path := os.Getenv(constants.GlobalConfigEnv); path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+	return filepath.Join(home, constants.GlobalConfigFile), nil
+}
+
+// GlobalConfigValue returns the last value of section.key in the user's config file,
+// or "" when it is not set. Section and key are matched case-insensitively.
+func GlobalConfigValue(section, key string) (string, error) {
+	configPath, err := GlobalConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return configValue(configPath, section, key)
+}
+
+// ConfigValue returns the value of section.key for the repository whose metadata directory is
+// gitDir: its own config takes precedence over the user's config file. Returns "" when neither
+// sets it.
+func ConfigValue(gitDir, section, key string) (string, error) {
+	value, err := configValue(filepath.Join(gitDir, "config"), section, key)
+	if err != nil || value != "" {
+		return value, err
+	}
+	return GlobalConfigValue(section, key)
+}
+
+// configValue returns the last value of section.key in the config file at configPath, or "".
+func configValue(configPath, section, key string) (string, error) {
+	var value string
+	err := scanConfig(configPath, func(s, k, v string) error {
+		if s == strings.ToLower(section) && k == strings.ToLower(key) {
+			value = strings.Trim(v, `"`)
+		}
+		return nil
+	})
+	return value, err
+}
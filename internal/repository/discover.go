@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+)
+
+// ErrRepositoryNotFound is returned when no repository contains the searched path.
+var ErrRepositoryNotFound = errors.New("repository not found")
+
+// Layout locates a discovered repository.
+type Layout struct {
+	Root   string // Worktree root, or the metadata directory itself for bare repositories
+	GitDir string // Metadata directory holding objects, refs and HEAD
+	Bare   bool   // Repository has no worktree
+}
+
+// Discover finds the repository containing path by walking up from it. Each directory is
+// checked for a .gogit directory, then for being a bare repository itself. The walk never
+// moves up into a directory listed in GOGIT_CEILING_DIRECTORIES. A non-empty gitDir, as named
+// by --git-dir or GOGIT_DIR, is the metadata directory instead and path is the worktree root.
+// The found metadata directory must pass CheckCompatibility.
+func Discover(path, gitDir string) (Layout, error) {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return Layout{}, fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+
+	if gitDir != "" {
+		if gitDir, err = filepath.Abs(gitDir); err != nil {
+			return Layout{}, fmt.Errorf("failed to resolve %s: %w", gitDir, err)
+		}
+		if !isDir(gitDir) {
+			return Layout{}, fmt.Errorf("%w: %s is not a directory", ErrRepositoryNotFound, gitDir)
+		}
+		return checkedLayout(Layout{Root: dir, GitDir: gitDir})
+	}
+
+	ceilings := ceilingDirectories()
+	for {
+		if gogitDir := filepath.Join(dir, constants.Gogit); isDir(gogitDir) {
+			return checkedLayout(Layout{Root: dir, GitDir: gogitDir})
+		}
+		if isBareRepository(dir) {
+			return checkedLayout(Layout{Root: dir, GitDir: dir, Bare: true})
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir || slices.Contains(ceilings, parent) {
+			return Layout{}, fmt.Errorf("%w: %s directory not found in %s or its parents", ErrRepositoryNotFound, constants.Gogit, path)
+		}
+		dir = parent
+	}
+}
+
+// Open finds the repository at exactly path, without walking up: path/.gogit, then a Git
+// repository's path/.git, then path itself as a bare repository. It serves repositories named
+// explicitly, such as local remotes.
+// The metadata directory must pass CheckCompatibility.
+func Open(path string) (Layout, error) {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return Layout{}, fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+
+	for _, name := range []string{constants.Gogit, constants.GitMetadataDir} {
+		if gitDir := filepath.Join(dir, name); isDir(gitDir) {
+			return checkedLayout(Layout{Root: dir, GitDir: gitDir})
+		}
+	}
+	if isBareRepository(dir) {
+		return checkedLayout(Layout{Root: dir, GitDir: dir, Bare: true})
+	}
+	return Layout{}, fmt.Errorf("%w: %s is not a repository", ErrRepositoryNotFound, path)
+}
+
+// checkedLayout returns layout once its metadata directory passed compatibility checks.
+func checkedLayout(layout Layout) (Layout, error) {
+	if err := CheckCompatibility(layout.GitDir); err != nil {
+		return Layout{}, err
+	}
+	return layout, nil
+}
+
+// ceilingDirectories returns cleaned absolute paths from GOGIT_CEILING_DIRECTORIES.
+// Relative entries are ignored, as Git ignores them.
+func ceilingDirectories() []string {
+	var ceilings []string
+	for _, dir := range filepath.SplitList(os.Getenv(constants.CeilingDirectoriesEnv)) {
+		if filepath.IsAbs(dir) {
+			ceilings = append(ceilings, filepath.Clean(dir))
+		}
+	}
+	return ceilings
+}
+
+// isBareRepository reports whether dir holds repository metadata directly: HEAD, objects/ and refs/.
+func isBareRepository(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, constants.Head))
+	return err == nil && info.Mode().IsRegular() &&
+		isDir(filepath.Join(dir, constants.Objects)) &&
+		isDir(filepath.Join(dir, constants.Refs))
+}
+
+// isDir reports whether path exists and is a directory.
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+)
+
+// initDiscoverRepo creates a repository with a nested subdirectory, returning both paths.
+func initDiscoverRepo(t *testing.T) (string, string) {
+	t.Helper()
+	t.Setenv(constants.CeilingDirectoriesEnv, "")
+
+	repoPath := t.TempDir()
+	if _, err := InitRepository(repoPath, InitOptions{}); err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	nested := filepath.Join(repoPath, "a", "b")
+	if err := os.MkdirAll(nested, constants.DirPerms); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	return repoPath, nested
+}
+
+// TestDiscover_Worktree verifies discovery walks up from a subdirectory to the .gogit directory.
+func TestDiscover_Worktree(t *testing.T) {
+	repoPath, nested := initDiscoverRepo(t)
+
+	layout, err := Discover(nested, "")
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+	expected := Layout{Root: repoPath, GitDir: filepath.Join(repoPath, constants.Gogit)}
+	if layout != expected {
+		t.Errorf("Expected %+v, got %+v", expected, layout)
+	}
+}
+
+// TestDiscover_Bare verifies a metadata directory without worktree is found as a bare repository.
+func TestDiscover_Bare(t *testing.T) {
+	repoPath, _ := initDiscoverRepo(t)
+	bare := filepath.Join(repoPath, constants.Gogit)
+
+	layout, err := Discover(filepath.Join(bare, constants.Refs, constants.Heads), "")
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+	expected := Layout{Root: bare, GitDir: bare, Bare: true}
+	if layout != expected {
+		t.Errorf("Expected %+v, got %+v", expected, layout)
+	}
+}
+
+// TestDiscover_CeilingDirectories verifies the walk stops before entering a ceiling directory.
+func TestDiscover_CeilingDirectories(t *testing.T) {
+	repoPath, nested := initDiscoverRepo(t)
+
+	t.Setenv(constants.CeilingDirectoriesEnv, "relative"+string(filepath.ListSeparator)+repoPath)
+	if _, err := Discover(nested, ""); !errors.Is(err, ErrRepositoryNotFound) {
+		t.Errorf("Expected repository not found below ceiling, got %v", err)
+	}
+
+	// The ceiling itself is still checked when discovery starts there.
+	if _, err := Discover(repoPath, ""); err != nil {
+		t.Errorf("Expected repository at ceiling to be found, got %v", err)
+	}
+}
+
+// TestDiscover_GitDir verifies an explicit metadata directory is used without walking.
+func TestDiscover_GitDir(t *testing.T) {
+	repoPath, nested := initDiscoverRepo(t)
+	gitDir := filepath.Join(repoPath, constants.Gogit)
+
+	layout, err := Discover(nested, gitDir)
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+	expected := Layout{Root: nested, GitDir: gitDir}
+	if layout != expected {
+		t.Errorf("Expected %+v, got %+v", expected, layout)
+	}
+
+	if _, err := Discover(nested, filepath.Join(repoPath, "missing")); !errors.Is(err, ErrRepositoryNotFound) {
+		t.Errorf("Expected repository not found for a missing metadata directory, got %v", err)
+	}
+}
+
+// TestOpen verifies a repository is found at exactly the given path, as a worktree or bare,
+// and subdirectories of a repository are not taken for it.
+func TestOpen(t *testing.T) {
+	repoPath, nested := initDiscoverRepo(t)
+	bare := filepath.Join(repoPath, constants.Gogit)
+
+	layout, err := Open(repoPath)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if expected := (Layout{Root: repoPath, GitDir: bare}); layout != expected {
+		t.Errorf("Expected %+v, got %+v", expected, layout)
+	}
+
+	layout, err = Open(bare)
+	if err != nil {
+		t.Fatalf("Open of bare repository failed: %v", err)
+	}
+	if expected := (Layout{Root: bare, GitDir: bare, Bare: true}); layout != expected {
+		t.Errorf("Expected %+v, got %+v", expected, layout)
+	}
+
+	if _, err := Open(nested); !errors.Is(err, ErrRepositoryNotFound) {
+		t.Errorf("Expected ErrRepositoryNotFound for a subdirectory, got %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+)
+
+// ErrUnsupportedRepository is returned when a metadata directory uses Git features gogit cannot handle yet.
+var ErrUnsupportedRepository = errors.New("unsupported repository")
+
+// GitDir returns the default metadata directory of the worktree rooted at repoPath,
+// repoPath/.gogit. Repositories found by Discover or Open carry their own in Layout.
+func GitDir(repoPath string) string {
+	return filepath.Join(repoPath, constants.Gogit)
+}
+
+// CheckCompatibility verifies gitDir uses no Git features gogit cannot handle yet, so commands
+// fail up front instead of missing packed objects or refs, or writing into a repository
+// in a format it does not understand.
+func CheckCompatibility(gitDir string) error {
+	packs, err := filepath.Glob(filepath.Join(gitDir, constants.Objects, "pack", "*.pack"))
+	if err != nil {
+		return err
+	}
+	if len(packs) > 0 {
+		return fmt.Errorf("%w: %s stores objects in packfiles, which gogit cannot read yet", ErrUnsupportedRepository, gitDir)
+	}
+
+	unsupportedFiles := []struct {
+		path    string
+		feature string
+	}{
+		{filepath.Join(gitDir, "packed-refs"), "packed refs"},
+		{filepath.Join(gitDir, constants.Objects, "info", "alternates"), "alternate object stores"},
+		{filepath.Join(gitDir, "shallow"), "shallow history"},
+		{filepath.Join(gitDir, "commondir"), "linked worktrees"},
+	}
+	for _, unsupported := range unsupportedFiles {
+		if _, err := os.Stat(unsupported.path); err == nil {
+			return fmt.Errorf("%w: %s uses %s, which gogit does not support yet", ErrUnsupportedRepository, gitDir, unsupported.feature)
+		}
+	}
+
+	return checkConfig(filepath.Join(gitDir, "config"))
+}
+
+// checkConfig rejects repository format versions above 1 and extensions other than SHA-1 object format.
+func checkConfig(configPath string) error {
+	return scanConfig(configPath, func(section, key, value string) error {
+		value = strings.ToLower(value)
+		switch {
+		case section == "core" && key == "repositoryformatversion":
+			if version, err := strconv.Atoi(value); err != nil || version > 1 {
+				return fmt.Errorf("%w: repository format version %s", ErrUnsupportedRepository, value)
+			}
+		case section == "extensions":
+			if key != "objectformat" || value != "sha1" {
+				return fmt.Errorf("%w: extension %s = %s", ErrUnsupportedRepository, key, value)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// TestGitDir verifies the default metadata directory is .gogit in the worktree root.
+func TestGitDir(t *testing.T) {
+	repoPath := t.TempDir()
+
+	if gitDir := GitDir(repoPath); gitDir != filepath.Join(repoPath, constants.Gogit) {
+		t.Errorf("Expected default metadata directory, got %s", gitDir)
+	}
+}
+
+// TestCheckCompatibility verifies Git features gogit cannot handle are refused.
+func TestCheckCompatibility(t *testing.T) {
+	tests := []struct {
+		name        string
+		file        string
+		content     string
+		unsupported bool
+	}{
+		{"plain repository", "", "", false},
+		{"sha1 object format", "config", "[core]\n\trepositoryformatversion = 1\n[extensions]\n\tobjectFormat = sha1\n", false},
+		{"sha256 object format", "config", "[core]\n\trepositoryformatversion = 1\n[extensions]\n\tobjectformat = sha256\n", true},
+		{"future format version", "config", "[core]\n\trepositoryformatversion = 2\n", true},
+		{"packfile", "objects/pack/pack-1.pack", "", true},
+		{"packed refs", "packed-refs", "# pack-refs with: peeled\n", true},
+		{"alternates", "objects/info/alternates", "/elsewhere/objects\n", true},
+		{"shallow", "shallow", testutils.RandomHash() + "\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gitDir := t.TempDir()
+			if tt.file != "" {
+				path := filepath.Join(gitDir, filepath.FromSlash(tt.file))
+				if err := os.MkdirAll(filepath.Dir(path), constants.DirPerms); err != nil {
+					t.Fatalf("Failed to create directory: %v", err)
+				}
+				testutils.CreateTestFile(t, filepath.Dir(path), filepath.Base(path), []byte(tt.content))
+			}
+
+			err := CheckCompatibility(gitDir)
+			if tt.unsupported != errors.Is(err, ErrUnsupportedRepository) {
+				t.Errorf("Expected unsupported=%v, got %v", tt.unsupported, err)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
 	"io/fs"
@@ -9,14 +10,31 @@ import (
 	"path/filepath"
 
 	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/utils"
 )
 
-// InitRepository creates .gogit directory structure with objects/, refs/, and HEAD file.
-// Returns error if repository already exists or directory creation fails.
-func InitRepository(path string) error {
-	gogitDir := filepath.Join(path, constants.Gogit)
-	if err := checkRepositoryDoesNotExist(gogitDir); err != nil {
-		return err
+// InitOptions configures repository initialization.
+type InitOptions struct {
+	InitialBranch string // Branch HEAD points to; defaults to init.defaultBranch, then "main"
+	TemplateDir   string // Directory copied into the metadata directory; defaults to init.templateDir
+	GitDir        string // Metadata directory to create; defaults to .gogit in the worktree
+}
+
+// InitRepository creates the metadata directory of the worktree at path, .gogit unless
+// opts.GitDir names another, with objects/, refs/, and HEAD file.
+// Files from the template directory are copied in without overwriting existing ones.
+// Re-running it on an existing repository is safe: missing directories and template
+// files are added, HEAD is left untouched, and reinitialized is reported as true.
+func InitRepository(path string, opts InitOptions) (reinitialized bool, err error) {
+	gogitDir := cmp.Or(opts.GitDir, GitDir(path))
+	reinitialized, err = repositoryExists(gogitDir)
+	if err != nil {
+		return false, err
+	}
+
+	branch, templateDir, err := resolveInitOptions(opts)
+	if err != nil {
+		return false, err
 	}
 
 	// Track if initialization of gogit directories and files was successful
@@ -25,38 +43,114 @@ func InitRepository(path string) error {
 
 	// Clean up any directories/files in the case that repository initialization failed
 	// If all resources got created successfully clean-up is not executed
+	// An existing repository is never removed
 	defer func() {
-		if !initSuccess {
+		if !initSuccess && !reinitialized {
 			cleanupRepository(gogitDir)
 		}
 	}()
 
 	if err := createDirectoryStructure(gogitDir); err != nil {
-		return err
+		return reinitialized, err
 	}
 
-	if err := createHeadFile(gogitDir); err != nil {
-		return err
+	if templateDir != "" {
+		if err := copyTemplate(templateDir, gogitDir); err != nil {
+			return reinitialized, err
+		}
+	}
+
+	if !reinitialized {
+		if err := createHeadFile(gogitDir, branch); err != nil {
+			return false, err
+		}
 	}
 
 	initSuccess = true
-	return nil
+	return reinitialized, nil
 }
 
-// checkRepositoryDoesNotExist verifies .gogit directory doesn't already exist.
-func checkRepositoryDoesNotExist(path string) error {
-	_, err := os.Stat(path)
+// repositoryExists reports whether .gogit directory already exists.
+func repositoryExists(path string) (bool, error) {
+	info, err := os.Stat(path)
 
 	// If path doesn't exist there is no error
 	if errors.Is(err, fs.ErrNotExist) {
-		return nil
+		return false, nil
 	}
 
 	if err != nil {
-		return fmt.Errorf("failed to check repository path: %w", err)
+		return false, fmt.Errorf("failed to check repository path: %w", err)
+	}
+
+	if !info.IsDir() {
+		return false, fmt.Errorf("%s exists and is not a directory", path)
+	}
+	return true, nil
+}
+
+// resolveInitOptions fills unset options from the user's config and validates the branch name.
+func resolveInitOptions(opts InitOptions) (string, string, error) {
+	branch := opts.InitialBranch
+	if branch == "" {
+		configured, err := GlobalConfigValue("init", "defaultBranch")
+		if err != nil {
+			return "", "", err
+		}
+		branch = cmp.Or(configured, constants.DefaultBranch)
 	}
+	if !utils.ValidRefName(branch) {
+		return "", "", fmt.Errorf("invalid initial branch name %q", branch)
+	}
+
+	templateDir := opts.TemplateDir
+	if templateDir == "" {
+		configured, err := GlobalConfigValue("init", "templateDir")
+		if err != nil {
+			return "", "", err
+		}
+		templateDir = configured
+	}
+	return branch, templateDir, nil
+}
 
-	return fmt.Errorf("repository already exists at %s", path)
+// copyTemplate copies files and directories under templateDir into gogitDir.
+// Existing files are kept, matching Git's template handling.
+func copyTemplate(templateDir, gogitDir string) error {
+	err := filepath.WalkDir(templateDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(templateDir, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(gogitDir, rel)
+
+		if entry.IsDir() {
+			return os.MkdirAll(target, constants.DirPerms)
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		if _, err := os.Lstat(target); err == nil {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, content, info.Mode().Perm())
+	})
+	if err != nil {
+		return fmt.Errorf("failed to copy template %s: %w", templateDir, err)
+	}
+	return nil
 }
 
 // Removes the entire .gogit directory if it exists
@@ -93,10 +187,10 @@ func createDirectoryStructure(gogitDir string) error {
 	return nil
 }
 
-// createHeadFile writes HEAD file pointing to default branch.
-func createHeadFile(gogitDir string) error {
+// createHeadFile writes HEAD file pointing to branch.
+func createHeadFile(gogitDir, branch string) error {
 	headFile := filepath.Join(gogitDir, constants.Head)
-	headContent := constants.DefaultRefPrefix + constants.DefaultBranch + "\n"
+	headContent := constants.DefaultRefPrefix + branch + "\n"
 
 	if err := os.WriteFile(headFile, []byte(headContent), constants.FilePerms); err != nil {
 		return fmt.Errorf("failed to create %s file: %w", constants.Head, err)
@@ -13,11 +13,16 @@ import (
 
 // TestInitRepository verifies successful repository initialization.
 func TestInitRepository(t *testing.T) {
+	t.Setenv(constants.GlobalConfigEnv, filepath.Join(t.TempDir(), "missing"))
 	repoPath := t.TempDir()
 
-	if err := InitRepository(repoPath); err != nil {
+	reinitialized, err := InitRepository(repoPath, InitOptions{})
+	if err != nil {
 		t.Fatalf("InitRepository failed: %v", err)
 	}
+	if reinitialized {
+		t.Error("Expected new repository not to be reported as reinitialized")
+	}
 
 	gogitDirectory := filepath.Join(repoPath, constants.Gogit)
 	testutils.AssertDirExists(t, gogitDirectory)
@@ -25,18 +30,110 @@ func TestInitRepository(t *testing.T) {
 	testutils.AssertRepositoryStructure(t, repoPath)
 }
 
-// TestInitRepository_AlreadyExists verifies error when repository exists.
+// TestInitRepository_AlreadyExists verifies re-running init keeps HEAD and restores missing directories.
 func TestInitRepository_AlreadyExists(t *testing.T) {
+	t.Setenv(constants.GlobalConfigEnv, filepath.Join(t.TempDir(), "missing"))
 	repoPath := t.TempDir()
 
 	// Initialize once
-	if err := InitRepository(repoPath); err != nil {
+	if _, err := InitRepository(repoPath, InitOptions{InitialBranch: "trunk"}); err != nil {
 		t.Fatalf("First initialization failed: %v", err)
 	}
+	tagsDir := filepath.Join(repoPath, constants.Gogit, constants.Refs, constants.Tags)
+	if err := os.Remove(tagsDir); err != nil {
+		t.Fatalf("Failed to remove tags directory: %v", err)
+	}
+
+	// Initialize again - should succeed without touching HEAD
+	reinitialized, err := InitRepository(repoPath, InitOptions{InitialBranch: "other"})
+	if err != nil {
+		t.Fatalf("Reinitialization failed: %v", err)
+	}
+	if !reinitialized {
+		t.Error("Expected existing repository to be reported as reinitialized")
+	}
+
+	testutils.AssertDirExists(t, tagsDir)
+	assertHead(t, repoPath, "trunk")
+}
+
+// TestInitRepository_InitialBranch verifies the branch option, init.defaultBranch config and name validation.
+func TestInitRepository_InitialBranch(t *testing.T) {
+	configDir := t.TempDir()
+	configPath := testutils.CreateTestFile(t, configDir, "config", []byte("[init]\n\tdefaultBranch = develop\n"))
+	t.Setenv(constants.GlobalConfigEnv, configPath)
+
+	tests := []struct {
+		name          string
+		branch        string
+		expectedHead  string
+		expectedError bool
+	}{
+		{"configured default", "", "develop", false},
+		{"explicit branch", "feature/x", "feature/x", false},
+		{"invalid branch", "bad..name", "", true},
+		{"branch with space", "bad name", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoPath := t.TempDir()
+			_, err := InitRepository(repoPath, InitOptions{InitialBranch: tt.branch})
+			if tt.expectedError {
+				if err == nil {
+					t.Fatal("Expected error for invalid branch name")
+				}
+				testutils.AssertFileNotExists(t, filepath.Join(repoPath, constants.Gogit))
+				return
+			}
+			if err != nil {
+				t.Fatalf("InitRepository failed: %v", err)
+			}
+			assertHead(t, repoPath, tt.expectedHead)
+		})
+	}
+}
+
+// TestInitRepository_Template verifies template files are copied without overwriting existing ones.
+func TestInitRepository_Template(t *testing.T) {
+	t.Setenv(constants.GlobalConfigEnv, filepath.Join(t.TempDir(), "missing"))
+	templateDir := t.TempDir()
+	hooksDir := filepath.Join(templateDir, "hooks")
+	if err := os.MkdirAll(hooksDir, constants.DirPerms); err != nil {
+		t.Fatalf("Failed to create hooks directory: %v", err)
+	}
+	testutils.CreateTestFile(t, hooksDir, "pre-commit", []byte("#!/bin/sh\n"))
+	testutils.CreateTestFile(t, templateDir, "description", []byte("template\n"))
 
-	// Try to initialize again - should fail
-	if err := InitRepository(repoPath); err == nil {
-		t.Error("Expected error when repository already exists, but got nil")
+	repoPath := t.TempDir()
+	if _, err := InitRepository(repoPath, InitOptions{TemplateDir: templateDir}); err != nil {
+		t.Fatalf("InitRepository failed: %v", err)
+	}
+	gogitDir := filepath.Join(repoPath, constants.Gogit)
+	testutils.AssertFileExists(t, filepath.Join(gogitDir, "hooks", "pre-commit"))
+
+	descriptionPath := filepath.Join(gogitDir, "description")
+	if err := os.WriteFile(descriptionPath, []byte("mine\n"), constants.FilePerms); err != nil {
+		t.Fatalf("Failed to write description: %v", err)
+	}
+	if _, err := InitRepository(repoPath, InitOptions{TemplateDir: templateDir}); err != nil {
+		t.Fatalf("Reinitialization failed: %v", err)
+	}
+	if content, _ := os.ReadFile(descriptionPath); string(content) != "mine\n" {
+		t.Errorf("Expected existing description to be kept, got %q", content)
+	}
+}
+
+// assertHead checks HEAD of repository at repoPath points at branch.
+func assertHead(t *testing.T, repoPath, branch string) {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join(repoPath, constants.Gogit, constants.Head))
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", constants.Head, err)
+	}
+	if expected := constants.DefaultRefPrefix + branch + "\n"; string(content) != expected {
+		t.Errorf("Expected %s %q, got %q", constants.Head, expected, content)
 	}
 }
 
@@ -56,7 +153,7 @@ func TestInitRepository_MkdirAllFailure(t *testing.T) {
 	})
 	defer patches.Reset()
 
-	err := InitRepository(repoPath)
+	_, err := InitRepository(repoPath, InitOptions{})
 	if err == nil {
 		t.Error("Expected error when os.MkdirAll fails, but got nil")
 	}
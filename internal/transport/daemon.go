@@ -0,0 +1,79 @@
+package transport
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DaemonPort is where git:// daemons listen by default.
+const DaemonPort = 9418
+
+// dialTimeout bounds connecting to a git:// daemon.
+const dialTimeout = 30 * time.Second
+
+// DaemonRequest is the first packet a git:// client sends, naming the service it wants run on
+// which repository: "git-upload-pack /path\x00host=example.com\x00".
+type DaemonRequest struct {
+	Service string
+	Path    string
+	Host    string // Host the client connected to, without port, when it said
+}
+
+// ReadDaemonRequest reads the request packet from reader, reading nothing past it.
+func ReadDaemonRequest(reader io.Reader) (DaemonRequest, error) {
+	line, err := readPktLine(reader)
+	if err != nil {
+		return DaemonRequest{}, err
+	}
+	if line == nil {
+		return DaemonRequest{}, errors.New("protocol error: expected request, got flush")
+	}
+
+	fields := strings.Split(strings.TrimSuffix(string(line), "\n"), "\x00")
+	service, path, ok := strings.Cut(fields[0], " ")
+	if !ok || path == "" {
+		return DaemonRequest{}, fmt.Errorf("protocol error: bad request %q", fields[0])
+	}
+	request := DaemonRequest{Service: service, Path: path}
+	for _, field := range fields[1:] {
+		if host, ok := strings.CutPrefix(field, "host="); ok {
+			request.Host, _, _ = strings.Cut(host, ":")
+		}
+	}
+	return request, nil
+}
+
+// WriteDaemonError tells a git:// client why its request is refused, as an "ERR" packet.
+func WriteDaemonError(out io.Writer, message string) error {
+	return writePktLine(out, "ERR %s\n", message)
+}
+
+// discoverDaemon asks the git:// daemon on the host to run upload-pack, reads its advertisement
+// and sends a flush packet, telling it nothing is wanted.
+func discoverDaemon(endpoint Endpoint) (*Advertisement, error) {
+	port := endpoint.Port
+	if port == "" {
+		port = strconv.Itoa(DaemonPort)
+	}
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(endpoint.Host, port), dialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to %s: %w", endpoint, err)
+	}
+	defer conn.Close()
+
+	if err := writePktLine(conn, "%s %s\x00host=%s\x00", UploadPackService, endpoint.Path, endpoint.hostPort()); err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", endpoint, err)
+	}
+	advertisement, err := readAdvertisement(bufio.NewReader(conn))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read refs from %s: %w", endpoint, err)
+	}
+	writeFlush(conn)
+	return advertisement, nil
+}
@@ -0,0 +1,69 @@
+package transport
+
+import (
+	"net"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/refs"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// TestReadDaemonRequest verifies service, path and host are read from the request packet.
+func TestReadDaemonRequest(t *testing.T) {
+	input := pktLines("git-upload-pack /srv/repo.git\x00host=example.com:9418\x00\x00version=2\x00")
+
+	request, err := ReadDaemonRequest(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadDaemonRequest failed: %v", err)
+	}
+	expected := DaemonRequest{Service: UploadPackService, Path: "/srv/repo.git", Host: "example.com"}
+	if request != expected {
+		t.Errorf("Expected %+v, got %+v", expected, request)
+	}
+
+	for _, bad := range []string{flushPkt, pktLines("git-upload-pack"), "00"} {
+		if _, err := ReadDaemonRequest(strings.NewReader(bad)); err == nil {
+			t.Errorf("Expected error reading request %q", bad)
+		}
+	}
+}
+
+// TestDiscoverRefs_Daemon verifies a git:// endpoint sends its request and reads the advertisement.
+func TestDiscoverRefs_Daemon(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	hash := testutils.RandomHash()
+	requests := make(chan DaemonRequest, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		request, _ := ReadDaemonRequest(conn)
+		requests <- request
+		conn.Write([]byte(pktLines(hash+" refs/heads/main\x00"+agent+"\n", "")))
+		readPktLine(conn)
+	}()
+
+	endpoint, err := ParseEndpoint("git://" + listener.Addr().String() + "/repo.git")
+	if err != nil {
+		t.Fatalf("Failed to parse endpoint: %v", err)
+	}
+	advertisement, err := DiscoverRefs(endpoint)
+	if err != nil {
+		t.Fatalf("Failed to discover refs: %v", err)
+	}
+	if expected := []refs.Ref{{Name: "refs/heads/main", Hash: hash}}; !slices.Equal(advertisement.Refs, expected) {
+		t.Errorf("Expected refs %v, got %v", expected, advertisement.Refs)
+	}
+	if request := <-requests; request.Service != UploadPackService || request.Path != "/repo.git" || request.Host != "127.0.0.1" {
+		t.Errorf("Unexpected request %+v", request)
+	}
+}
@@ -0,0 +1,167 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/refs"
+)
+
+// helperCapabilities are the helper capabilities gogit understands. A helper marking any other
+// capability mandatory with a leading "*" cannot be used.
+var helperCapabilities = []string{"fetch", "push", "option", "list", "import", "export", "refspec", "connect"}
+
+// helper is a running remote helper program, spoken to a line at a time.
+type helper struct {
+	command *exec.Cmd
+	stdin   io.WriteCloser
+	stdout  *bufio.Reader
+	stderr  bytes.Buffer
+}
+
+// discoverHelper asks the endpoint's remote helper for its capabilities and then its refs.
+func discoverHelper(endpoint Endpoint) (*Advertisement, error) {
+	h, err := startHelper(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	advertisement, err := h.list()
+	return advertisement, h.close(err)
+}
+
+// startHelper runs gogit-remote-<helper> with the address as both remote name and URL, as for
+// a remote given on the command line, and reads its capabilities.
+func startHelper(endpoint Endpoint) (*helper, error) {
+	program, err := exec.LookPath(helperPrefix + endpoint.Helper)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find remote helper for '%s'", endpoint.Helper)
+	}
+
+	h := &helper{command: exec.Command(program, endpoint.Path, endpoint.Path)}
+	h.command.Stderr = &h.stderr
+	if h.stdin, err = h.command.StdinPipe(); err != nil {
+		return nil, err
+	}
+	stdout, err := h.command.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	h.stdout = bufio.NewReader(stdout)
+	if err := h.command.Start(); err != nil {
+		return nil, fmt.Errorf("failed to run remote helper %s: %w", program, err)
+	}
+
+	capabilities, err := h.request("capabilities")
+	if err == nil {
+		err = checkHelperCapabilities(capabilities)
+	}
+	if err != nil {
+		return nil, h.close(err)
+	}
+	return h, nil
+}
+
+// checkHelperCapabilities rejects capability lists with mandatory capabilities gogit lacks.
+func checkHelperCapabilities(capabilities []string) error {
+	for _, capability := range capabilities {
+		name, mandatory := strings.CutPrefix(capability, "*")
+		name, _, _ = strings.Cut(name, " ")
+		if mandatory && !slices.Contains(helperCapabilities, name) {
+			return fmt.Errorf("unknown mandatory capability %s; this remote helper probably needs a newer version of gogit", name)
+		}
+	}
+	return nil
+}
+
+// request sends command and returns the lines of its reply, up to the blank line ending it.
+func (h *helper) request(command string) ([]string, error) {
+	if _, err := io.WriteString(h.stdin, command+"\n"); err != nil {
+		return nil, fmt.Errorf("failed to send %s to remote helper: %w", command, err)
+	}
+
+	var lines []string
+	for {
+		line, err := h.stdout.ReadString('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, fmt.Errorf("remote helper reply to %s: %w", command, err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return lines, nil
+		}
+		lines = append(lines, line)
+	}
+}
+
+// list asks for the remote's refs: "<hash> <name>", "@<target> <name>" for symbolic refs and
+// "? <name>" for refs whose value the helper does not know. Symbolic refs take the hash of
+// their target; refs of unknown value are left out.
+func (h *helper) list() (*Advertisement, error) {
+	lines, err := h.request("list")
+	if err != nil {
+		return nil, err
+	}
+
+	advertisement := newAdvertisement()
+	hashes := make(map[string]string)
+	for _, line := range lines {
+		value, name, ok := strings.Cut(line, " ")
+		// Attributes such as "unchanged" may follow the name
+		name, _, _ = strings.Cut(name, " ")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("remote helper listed bad ref %q", line)
+		}
+
+		switch {
+		case strings.HasPrefix(value, "@"):
+			advertisement.Symrefs[name] = value[1:]
+			advertisement.Refs = append(advertisement.Refs, refs.Ref{Name: name})
+		case value == "?":
+		case len(value) == constants.HashStringLength:
+			hashes[name] = value
+			advertisement.add(value, name)
+		default:
+			return nil, fmt.Errorf("remote helper listed bad ref %q", line)
+		}
+	}
+
+	// Symbolic refs are listed before their targets may be known
+	resolved := advertisement.Refs[:0]
+	for _, ref := range advertisement.Refs {
+		if ref.Hash == "" {
+			if ref.Hash = hashes[advertisement.Symrefs[ref.Name]]; ref.Hash == "" {
+				continue
+			}
+		}
+		resolved = append(resolved, ref)
+	}
+	advertisement.Refs = resolved
+	return advertisement, nil
+}
+
+// close ends the helper's input, waits for it to exit and returns err, or the helper's failure
+// with what it printed on stderr.
+func (h *helper) close(err error) error {
+	h.stdin.Close()
+	io.Copy(io.Discard, h.stdout)
+	waitErr := h.command.Wait()
+
+	if err := cmp.Or(err, waitErr); err != nil {
+		if message := strings.TrimSpace(h.stderr.String()); message != "" {
+			return fmt.Errorf("%w: %s", err, message)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package transport
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/refs"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// fakeHelper installs a gogit-remote-test program on PATH that records its arguments and the
+// commands it is sent, answering each with the reply given for it. Returns the paths of the
+// argument and command records.
+func fakeHelper(t *testing.T, replies map[string]string) (string, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	argsPath, commandsPath := filepath.Join(dir, "args"), filepath.Join(dir, "commands")
+	var script strings.Builder
+	script.WriteString("#!/bin/sh\nprintf '%s\\n' \"$@\" > " + argsPath + "\n")
+	script.WriteString("while read -r command; do\n\techo \"$command\" >> " + commandsPath + "\n\tcase \"$command\" in\n")
+	for command, reply := range replies {
+		script.WriteString("\t" + command + ") printf '" + reply + "\\n' ;;\n")
+	}
+	script.WriteString("\t*) echo \"unexpected $command\" >&2; exit 1 ;;\n\tesac\ndone\n")
+
+	if err := os.WriteFile(filepath.Join(dir, helperPrefix+"test"), []byte(script.String()), 0o755); err != nil {
+		t.Fatalf("Failed to write helper script: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return argsPath, commandsPath
+}
+
+// TestDiscoverRefs_Helper verifies the helper is run with the address, asked for its
+// capabilities and refs, and its symbolic refs resolved to their targets' hashes.
+func TestDiscoverRefs_Helper(t *testing.T) {
+	main, tag := testutils.RandomHash(), testutils.RandomHash()
+	argsPath, commandsPath := fakeHelper(t, map[string]string{
+		"capabilities": "fetch\\noption\\n",
+		"list":         "@refs/heads/main HEAD\\n" + main + " refs/heads/main\\n" + tag + " refs/tags/v1.0\\n? refs/heads/unknown\\n",
+	})
+
+	endpoint, err := ParseEndpoint("test::bucket/repo")
+	if err != nil {
+		t.Fatalf("Failed to parse endpoint: %v", err)
+	}
+	advertisement, err := DiscoverRefs(endpoint)
+	if err != nil {
+		t.Fatalf("Failed to discover refs: %v", err)
+	}
+
+	expected := []refs.Ref{{Name: "HEAD", Hash: main}, {Name: "refs/heads/main", Hash: main}, {Name: "refs/tags/v1.0", Hash: tag}}
+	if !slices.Equal(advertisement.Refs, expected) {
+		t.Errorf("Expected refs %v, got %v", expected, advertisement.Refs)
+	}
+	if target := advertisement.Symrefs["HEAD"]; target != "refs/heads/main" {
+		t.Errorf("Expected HEAD to point to refs/heads/main, got %q", target)
+	}
+
+	args, _ := os.ReadFile(argsPath)
+	if string(args) != "bucket/repo\nbucket/repo\n" {
+		t.Errorf("Expected the address as remote name and URL, got %q", args)
+	}
+	commands, _ := os.ReadFile(commandsPath)
+	if string(commands) != "capabilities\nlist\n" {
+		t.Errorf("Expected capabilities and list commands, got %q", commands)
+	}
+}
+
+// TestDiscoverRefs_HelperMandatoryCapability verifies helpers requiring an unknown capability are
+// refused before their refs are asked for.
+func TestDiscoverRefs_HelperMandatoryCapability(t *testing.T) {
+	_, commandsPath := fakeHelper(t, map[string]string{"capabilities": "*fetch\\n*signed-tags\\n"})
+
+	_, err := DiscoverRefs(Endpoint{Protocol: ProtocolHelper, Helper: "test", Path: "test://repo"})
+	if err == nil || !strings.Contains(err.Error(), "unknown mandatory capability signed-tags") {
+		t.Fatalf("Expected unknown mandatory capability error, got %v", err)
+	}
+	if commands, _ := os.ReadFile(commandsPath); string(commands) != "capabilities\n" {
+		t.Errorf("Expected only the capabilities command, got %q", commands)
+	}
+}
+
+// TestDiscoverRefs_HelperFailure verifies a helper exiting mid-conversation fails with what it
+// printed on stderr.
+func TestDiscoverRefs_HelperFailure(t *testing.T) {
+	fakeHelper(t, map[string]string{"capabilities": "fetch\\n"})
+
+	_, err := DiscoverRefs(Endpoint{Protocol: ProtocolHelper, Helper: "test", Path: "repo"})
+	if err == nil || !strings.Contains(err.Error(), "unexpected list") {
+		t.Fatalf("Expected the helper's error, got %v", err)
+	}
+}
+
+// TestDiscoverRefs_HelperMissing verifies a scheme without a helper program is reported.
+func TestDiscoverRefs_HelperMissing(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	_, err := DiscoverRefs(Endpoint{Protocol: ProtocolHelper, Helper: "nope", Path: "nope://repo"})
+	if err == nil || !strings.Contains(err.Error(), "unable to find remote helper for 'nope'") {
+		t.Fatalf("Expected missing helper error, got %v", err)
+	}
+}
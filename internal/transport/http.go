@@ -0,0 +1,132 @@
+package transport
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/refs"
+)
+
+// discoverHTTP asks the server for its refs the way smart HTTP clients do, falling back to the
+// info/refs and HEAD files dumb servers publish when the reply is not a smart advertisement.
+func discoverHTTP(endpoint Endpoint) (*Advertisement, error) {
+	base := endpoint.httpURL()
+	response, err := httpGet(endpoint, base+"/info/refs?service="+UploadPackService)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	reader := bufio.NewReader(response.Body)
+	if response.Header.Get("Content-Type") != uploadPackAdvertisement {
+		return readDumbAdvertisement(endpoint, base, reader)
+	}
+
+	// Smart servers announce the service in a section of its own before the refs
+	line, err := readPktLine(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read refs from %s: %w", endpoint, err)
+	}
+	if string(line) != "# service="+UploadPackService+"\n" {
+		return nil, fmt.Errorf("invalid response from %s: unexpected service line %q", endpoint, line)
+	}
+	for line != nil {
+		if line, err = readPktLine(reader); err != nil {
+			return nil, fmt.Errorf("failed to read refs from %s: %w", endpoint, err)
+		}
+	}
+
+	advertisement, err := readAdvertisement(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read refs from %s: %w", endpoint, err)
+	}
+	return advertisement, nil
+}
+
+// readDumbAdvertisement parses an info/refs file, "<hash>\t<name>" per line with peeled tags
+// as "<name>^{}", then fetches HEAD, which the file leaves out.
+func readDumbAdvertisement(endpoint Endpoint, base string, reader *bufio.Reader) (*Advertisement, error) {
+	advertisement := newAdvertisement()
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		if scanner.Text() == "" {
+			continue
+		}
+		hash, name, ok := strings.Cut(scanner.Text(), "\t")
+		if !ok || len(hash) != constants.HashStringLength {
+			return nil, fmt.Errorf("invalid response from %s: bad info/refs line %q", endpoint, scanner.Text())
+		}
+		advertisement.add(hash, name)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read refs from %s: %w", endpoint, err)
+	}
+
+	response, err := httpGet(endpoint, base+"/"+constants.Head)
+	if err != nil {
+		// A server without HEAD still lists its other refs
+		return advertisement, nil
+	}
+	defer response.Body.Close()
+	content, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HEAD from %s: %w", endpoint, err)
+	}
+
+	head := strings.TrimSpace(string(content))
+	if target, ok := strings.CutPrefix(head, constants.SymbolicRefPrefix); ok {
+		advertisement.Symrefs[constants.Head] = target
+		for _, ref := range advertisement.Refs {
+			if ref.Name == target {
+				head = ref.Hash
+			}
+		}
+	}
+	if len(head) == constants.HashStringLength {
+		advertisement.Refs = append([]refs.Ref{{Name: constants.Head, Hash: head}}, advertisement.Refs...)
+	}
+	return advertisement, nil
+}
+
+// httpGet requests rawURL with the endpoint's credentials, turning unsuccessful statuses
+// into errors naming the endpoint.
+func httpGet(endpoint Endpoint, rawURL string) (*http.Response, error) {
+	request, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote %s: %w", endpoint, err)
+	}
+	if endpoint.User != "" {
+		request.SetBasicAuth(endpoint.User, endpoint.Password)
+	}
+	request.Header.Set("Pragma", "no-cache")
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("unable to access %s: %w", endpoint, err)
+	}
+	if response.StatusCode == http.StatusOK {
+		return response, nil
+	}
+
+	response.Body.Close()
+	switch response.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, fmt.Errorf("authentication failed for %s", endpoint)
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("repository %s not found", endpoint)
+	default:
+		return nil, fmt.Errorf("unable to access %s: server returned %s", endpoint, response.Status)
+	}
+}
+
+// httpURL returns the repository URL requests are made under, without credentials or
+// trailing slash.
+func (endpoint Endpoint) httpURL() string {
+	u := url.URL{Scheme: endpoint.Protocol, Host: endpoint.hostPort(), Path: strings.TrimSuffix(endpoint.Path, "/")}
+	return u.String()
+}
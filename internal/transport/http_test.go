@@ -0,0 +1,105 @@
+package transport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/refs"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// serveEndpoint starts a server with handler and returns an endpoint for its /repo.git.
+func serveEndpoint(t *testing.T, handler http.HandlerFunc) Endpoint {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	endpoint, err := ParseEndpoint(server.URL + "/repo.git")
+	if err != nil {
+		t.Fatalf("Failed to parse endpoint: %v", err)
+	}
+	return endpoint
+}
+
+// TestDiscoverRefs_SmartHTTP verifies the service section is skipped and the advertisement read.
+func TestDiscoverRefs_SmartHTTP(t *testing.T) {
+	hash := testutils.RandomHash()
+	endpoint := serveEndpoint(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repo.git/info/refs" || r.URL.Query().Get("service") != UploadPackService {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", uploadPackAdvertisement)
+		w.Write([]byte(pktLines("# service=git-upload-pack\n", "", hash+" HEAD\x00symref=HEAD:refs/heads/main\n", hash+" refs/heads/main\n", "")))
+	})
+
+	advertisement, err := DiscoverRefs(endpoint)
+	if err != nil {
+		t.Fatalf("Failed to discover refs: %v", err)
+	}
+	expected := []refs.Ref{{Name: "HEAD", Hash: hash}, {Name: "refs/heads/main", Hash: hash}}
+	if !slices.Equal(advertisement.Refs, expected) {
+		t.Errorf("Expected refs %v, got %v", expected, advertisement.Refs)
+	}
+	if advertisement.Symrefs["HEAD"] != "refs/heads/main" {
+		t.Errorf("Expected HEAD to point to refs/heads/main, got %v", advertisement.Symrefs)
+	}
+}
+
+// TestDiscoverRefs_DumbHTTP verifies info/refs is read as a plain file and HEAD fetched separately.
+func TestDiscoverRefs_DumbHTTP(t *testing.T) {
+	main, tag, peeled := testutils.RandomHash(), testutils.RandomHash(), testutils.RandomHash()
+	endpoint := serveEndpoint(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repo.git/info/refs":
+			w.Write([]byte(main + "\trefs/heads/main\n" + tag + "\trefs/tags/v1.0\n" + peeled + "\trefs/tags/v1.0^{}\n"))
+		case "/repo.git/HEAD":
+			w.Write([]byte("ref: refs/heads/main\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	advertisement, err := DiscoverRefs(endpoint)
+	if err != nil {
+		t.Fatalf("Failed to discover refs: %v", err)
+	}
+	expected := []refs.Ref{{Name: "HEAD", Hash: main}, {Name: "refs/heads/main", Hash: main}, {Name: "refs/tags/v1.0", Hash: tag}}
+	if !slices.Equal(advertisement.Refs, expected) {
+		t.Errorf("Expected refs %v, got %v", expected, advertisement.Refs)
+	}
+	if advertisement.Peeled["refs/tags/v1.0"] != peeled {
+		t.Errorf("Expected refs/tags/v1.0 to peel to %s, got %v", peeled, advertisement.Peeled)
+	}
+}
+
+// TestDiscoverRefs_HTTPErrors verifies missing repositories and refused credentials are reported.
+func TestDiscoverRefs_HTTPErrors(t *testing.T) {
+	for _, status := range []int{http.StatusNotFound, http.StatusUnauthorized, http.StatusInternalServerError} {
+		endpoint := serveEndpoint(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		})
+		if _, err := DiscoverRefs(endpoint); err == nil {
+			t.Errorf("Expected error for HTTP status %d", status)
+		}
+	}
+}
+
+// TestDiscoverRefs_HTTPCredentials verifies credentials in the URL are sent as basic authentication.
+func TestDiscoverRefs_HTTPCredentials(t *testing.T) {
+	endpoint := serveEndpoint(t, func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "alice" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", uploadPackAdvertisement)
+		w.Write([]byte(pktLines("# service=git-upload-pack\n", "", "")))
+	})
+	endpoint.User, endpoint.Password = "alice", "secret"
+
+	if _, err := DiscoverRefs(endpoint); err != nil {
+		t.Errorf("Expected credentials to be accepted, got %v", err)
+	}
+}
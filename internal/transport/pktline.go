@@ -0,0 +1,138 @@
+package transport
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/refs"
+)
+
+// Protocol v0 framing: every packet starts with its 4 hex digit length, the length digits
+// included, and "0000" is a flush packet ending a section.
+const (
+	pktLengthSize = 4
+	maxPktLength  = 65520
+	flushPkt      = "0000"
+)
+
+// UploadPackService is the service fetching clients ask for, and the content type smart HTTP
+// servers answer its discovery with.
+const (
+	UploadPackService       = "git-upload-pack"
+	uploadPackAdvertisement = "application/x-" + UploadPackService + "-advertisement"
+)
+
+// readPktLine reads one packet, returning nil for a flush packet. It reads no further than the
+// packet, so unbuffered readers can be handed on afterwards.
+func readPktLine(reader io.Reader) ([]byte, error) {
+	var header [pktLengthSize]byte
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	length, err := strconv.ParseUint(string(header[:]), 16, 16)
+	if err != nil {
+		return nil, fmt.Errorf("protocol error: bad line length %q", header[:])
+	}
+	switch {
+	case length == 0:
+		return nil, nil
+	case length < pktLengthSize || length > maxPktLength:
+		return nil, fmt.Errorf("protocol error: bad line length %d", length)
+	}
+
+	payload := make([]byte, length-pktLengthSize)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return nil, fmt.Errorf("protocol error: truncated line: %w", err)
+	}
+	return payload, nil
+}
+
+// readAdvertisement parses a protocol v0 ref advertisement up to its closing flush packet.
+// The first ref carries the capabilities after a NUL byte; a repository without refs sends
+// the capabilities on a placeholder "capabilities^{}" line instead.
+func readAdvertisement(reader *bufio.Reader) (*Advertisement, error) {
+	advertisement := newAdvertisement()
+	first := true
+	for {
+		line, err := readPktLine(reader)
+		if err != nil {
+			return nil, err
+		}
+		if line == nil {
+			return advertisement, nil
+		}
+
+		text := strings.TrimSuffix(string(line), "\n")
+		if message, ok := strings.CutPrefix(text, "ERR "); ok {
+			return nil, fmt.Errorf("remote error: %s", message)
+		}
+		if first && text == "version 1" || strings.HasPrefix(text, "shallow ") {
+			continue
+		}
+
+		withCapabilities := first
+		first = false
+		if withCapabilities {
+			var capabilities string
+			text, capabilities, _ = strings.Cut(text, "\x00")
+			advertisement.setCapabilities(strings.Fields(capabilities))
+		}
+		hash, name, ok := strings.Cut(text, " ")
+		if !ok || len(hash) != constants.HashStringLength {
+			return nil, fmt.Errorf("protocol error: bad ref line %q", text)
+		}
+		if withCapabilities && name == "capabilities^{}" {
+			continue
+		}
+		advertisement.add(hash, name)
+	}
+}
+
+// setCapabilities records capabilities, including the symref=<name>:<target> ones naming
+// the targets of symbolic refs.
+func (advertisement *Advertisement) setCapabilities(capabilities []string) {
+	advertisement.Capabilities = capabilities
+	for _, capability := range capabilities {
+		symref, ok := strings.CutPrefix(capability, "symref=")
+		if !ok {
+			continue
+		}
+		if name, target, ok := strings.Cut(symref, ":"); ok {
+			advertisement.Symrefs[name] = target
+		}
+	}
+}
+
+// add records an advertised ref, or the peeled object of the ref before it for "<name>^{}".
+func (advertisement *Advertisement) add(hash, name string) {
+	if tagged, ok := strings.CutSuffix(name, "^{}"); ok {
+		advertisement.Peeled[tagged] = hash
+		return
+	}
+	advertisement.Refs = append(advertisement.Refs, refs.Ref{Name: name, Hash: hash})
+}
+
+// writePktLine writes the formatted payload as one packet.
+func writePktLine(out io.Writer, format string, args ...any) error {
+	payload := fmt.Sprintf(format, args...)
+	if len(payload)+pktLengthSize > maxPktLength {
+		return fmt.Errorf("protocol error: line of %d bytes is too long", len(payload))
+	}
+	_, err := fmt.Fprintf(out, "%04x%s", len(payload)+pktLengthSize, payload)
+	return err
+}
+
+// writeFlush writes a flush packet.
+func writeFlush(out io.Writer) error {
+	_, err := io.WriteString(out, flushPkt)
+	return err
+}
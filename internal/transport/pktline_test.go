@@ -0,0 +1,96 @@
+package transport
+
+import (
+	"bufio"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/refs"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// pktLines encodes lines as pkt-lines, with "" standing for a flush packet.
+func pktLines(lines ...string) string {
+	var encoded strings.Builder
+	for _, line := range lines {
+		if line == "" {
+			encoded.WriteString(flushPkt)
+			continue
+		}
+		fmt.Fprintf(&encoded, "%04x%s", len(line)+pktLengthSize, line)
+	}
+	return encoded.String()
+}
+
+// TestReadAdvertisement verifies refs, peeled tags, capabilities and symrefs are read from an advertisement.
+func TestReadAdvertisement(t *testing.T) {
+	head, tag, peeled := testutils.RandomHash(), testutils.RandomHash(), testutils.RandomHash()
+	input := pktLines(
+		"version 1\n",
+		head+" HEAD\x00multi_ack symref=HEAD:refs/heads/main agent=git/2.39.5\n",
+		head+" refs/heads/main\n",
+		tag+" refs/tags/v1.0\n",
+		peeled+" refs/tags/v1.0^{}\n",
+		"",
+	)
+
+	advertisement, err := readAdvertisement(bufio.NewReader(strings.NewReader(input)))
+	if err != nil {
+		t.Fatalf("Failed to read advertisement: %v", err)
+	}
+
+	expectedRefs := []refs.Ref{{Name: "HEAD", Hash: head}, {Name: "refs/heads/main", Hash: head}, {Name: "refs/tags/v1.0", Hash: tag}}
+	if !slices.Equal(advertisement.Refs, expectedRefs) {
+		t.Errorf("Expected refs %v, got %v", expectedRefs, advertisement.Refs)
+	}
+	if advertisement.Peeled["refs/tags/v1.0"] != peeled {
+		t.Errorf("Expected refs/tags/v1.0 to peel to %s, got %v", peeled, advertisement.Peeled)
+	}
+	if advertisement.Symrefs["HEAD"] != "refs/heads/main" {
+		t.Errorf("Expected HEAD to point to refs/heads/main, got %v", advertisement.Symrefs)
+	}
+	if !slices.Contains(advertisement.Capabilities, "multi_ack") {
+		t.Errorf("Expected multi_ack capability, got %v", advertisement.Capabilities)
+	}
+}
+
+// TestReadAdvertisement_Empty verifies a repository without refs advertises only capabilities.
+func TestReadAdvertisement_Empty(t *testing.T) {
+	input := pktLines(strings.Repeat("0", 40)+" capabilities^{}\x00ofs-delta\n", "")
+
+	advertisement, err := readAdvertisement(bufio.NewReader(strings.NewReader(input)))
+	if err != nil {
+		t.Fatalf("Failed to read advertisement: %v", err)
+	}
+	if len(advertisement.Refs) != 0 {
+		t.Errorf("Expected no refs, got %v", advertisement.Refs)
+	}
+	if !slices.Equal(advertisement.Capabilities, []string{"ofs-delta"}) {
+		t.Errorf("Expected [ofs-delta], got %v", advertisement.Capabilities)
+	}
+}
+
+// TestReadAdvertisement_Invalid verifies remote errors, bad lengths and truncated input fail.
+func TestReadAdvertisement_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"remote error", pktLines("ERR access denied\n")},
+		{"bad length", "zzzz"},
+		{"too short length", "0002"},
+		{"truncated", "0032" + testutils.RandomHash()},
+		{"missing flush", pktLines(testutils.RandomHash() + " HEAD\x00\n")},
+		{"bad ref line", pktLines("garbage\n", "")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := readAdvertisement(bufio.NewReader(strings.NewReader(test.input))); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+)
+
+// discoverSSH runs git-upload-pack on the host through ssh, or GOGIT_SSH_COMMAND, reads its
+// advertisement and sends a flush packet, telling it nothing is wanted so it exits.
+func discoverSSH(endpoint Endpoint) (*Advertisement, error) {
+	command := exec.Command("ssh")
+	if custom := strings.Fields(os.Getenv(constants.SSHCommandEnv)); len(custom) > 0 {
+		command = exec.Command(custom[0], custom[1:]...)
+	}
+	if endpoint.Port != "" {
+		command.Args = append(command.Args, "-p", endpoint.Port)
+	}
+	host := endpoint.Host
+	if endpoint.User != "" {
+		host = endpoint.User + "@" + host
+	}
+	// "--" keeps ssh from reading the destination as an option, even for endpoints built by hand
+	command.Args = append(command.Args, "--", host, UploadPackService+" "+shellQuote(endpoint.Path))
+
+	var stderr bytes.Buffer
+	command.Stderr = &stderr
+	stdin, err := command.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := command.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := command.Start(); err != nil {
+		return nil, fmt.Errorf("failed to run %s: %w", command.Args[0], err)
+	}
+
+	advertisement, readErr := readAdvertisement(bufio.NewReader(stdout))
+	// The remote may already be gone after an error, so failing to say goodbye is not one
+	stdin.Write([]byte(flushPkt))
+	stdin.Close()
+	io.Copy(io.Discard, stdout)
+	waitErr := command.Wait()
+
+	if err := cmp.Or(readErr, waitErr); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("failed to read refs from %s: %w: %s", endpoint, err, message)
+		}
+		return nil, fmt.Errorf("failed to read refs from %s: %w", endpoint, err)
+	}
+	return advertisement, nil
+}
+
+// shellQuote quotes value for the remote shell, as single-quoted words with embedded
+// single quotes escaped.
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,84 @@
+package transport
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/refs"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// fakeSSH installs a GOGIT_SSH_COMMAND script that records its arguments and what it is sent,
+// answering with advertisement. Returns the paths of the argument and input records.
+func fakeSSH(t *testing.T, advertisement string) (string, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	argsPath, inputPath := filepath.Join(dir, "args"), filepath.Join(dir, "input")
+	advertisementPath := filepath.Join(dir, "advertisement")
+	if err := os.WriteFile(advertisementPath, []byte(advertisement), constants.FilePerms); err != nil {
+		t.Fatalf("Failed to write advertisement: %v", err)
+	}
+
+	script := filepath.Join(dir, "ssh")
+	content := "#!/bin/sh\nprintf '%s\\n' \"$@\" > " + argsPath + "\ncat " + advertisementPath + "\ncat > " + inputPath + "\n"
+	if err := os.WriteFile(script, []byte(content), 0o755); err != nil {
+		t.Fatalf("Failed to write ssh script: %v", err)
+	}
+	t.Setenv(constants.SSHCommandEnv, script+" -o BatchMode=yes")
+	return argsPath, inputPath
+}
+
+// TestDiscoverRefs_SSH verifies upload-pack is run on the host with a quoted path, its
+// advertisement read and a flush packet sent back.
+func TestDiscoverRefs_SSH(t *testing.T) {
+	hash := testutils.RandomHash()
+	argsPath, inputPath := fakeSSH(t, pktLines(hash+" refs/heads/main\x00ofs-delta\n", ""))
+
+	endpoint, err := ParseEndpoint("ssh://git@example.com:2222/srv/it's.git")
+	if err != nil {
+		t.Fatalf("Failed to parse endpoint: %v", err)
+	}
+	advertisement, err := DiscoverRefs(endpoint)
+	if err != nil {
+		t.Fatalf("Failed to discover refs: %v", err)
+	}
+	if expected := []refs.Ref{{Name: "refs/heads/main", Hash: hash}}; !slices.Equal(advertisement.Refs, expected) {
+		t.Errorf("Expected refs %v, got %v", expected, advertisement.Refs)
+	}
+
+	args, err := os.ReadFile(argsPath)
+	if err != nil {
+		t.Fatalf("Failed to read ssh arguments: %v", err)
+	}
+	expectedArgs := []string{"-o", "BatchMode=yes", "-p", "2222", "--", "git@example.com", `git-upload-pack '/srv/it'\''s.git'`}
+	if got := strings.Split(strings.TrimSuffix(string(args), "\n"), "\n"); !slices.Equal(got, expectedArgs) {
+		t.Errorf("Expected ssh arguments %q, got %q", expectedArgs, got)
+	}
+
+	input, err := os.ReadFile(inputPath)
+	if err != nil {
+		t.Fatalf("Failed to read ssh input: %v", err)
+	}
+	if string(input) != flushPkt {
+		t.Errorf("Expected a flush packet to be sent, got %q", input)
+	}
+}
+
+// TestDiscoverRefs_SSHFailure verifies a remote that exits without advertising reports its stderr.
+func TestDiscoverRefs_SSHFailure(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "ssh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho \"fatal: repository not found\" >&2\nexit 128\n"), 0o755); err != nil {
+		t.Fatalf("Failed to write ssh script: %v", err)
+	}
+	t.Setenv(constants.SSHCommandEnv, script)
+
+	_, err := DiscoverRefs(Endpoint{Protocol: ProtocolSSH, Host: "example.com", Path: "missing.git"})
+	if err == nil || !strings.Contains(err.Error(), "repository not found") {
+		t.Errorf("Expected error carrying the remote's message, got %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+// Package transport reaches remote repositories over HTTP, SSH and the git:// protocol and
+// discovers the refs they advertise, the first step of every fetch. It also serves local
+// repositories to fetching clients as upload-pack does.
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/refs"
+)
+
+// Protocols an endpoint can use.
+const (
+	ProtocolHTTP   = "http"
+	ProtocolHTTPS  = "https"
+	ProtocolSSH    = "ssh"
+	ProtocolGit    = "git"
+	ProtocolFile   = "file"
+	ProtocolHelper = "helper"
+)
+
+// helperPrefix starts the names of remote helper programs: "s3" is served by gogit-remote-s3.
+const helperPrefix = "gogit-remote-"
+
+// Endpoint is a parsed remote repository location.
+type Endpoint struct {
+	Protocol string // One of the Protocol constants
+	User     string
+	Password string // Only set by HTTP URLs carrying credentials
+	Host     string
+	Port     string
+	Path     string // Repository path on the host, the local path for ProtocolFile, or the address handed to a helper
+	Helper   string // Remote helper serving the endpoint, for ProtocolHelper
+}
+
+// Advertisement is what a remote announces before any object is transferred.
+type Advertisement struct {
+	Refs         []refs.Ref        // Refs in advertised order, HEAD first when the remote has one
+	Peeled       map[string]string // Objects annotated tags finally point to, by name of the ref naming the tag
+	Symrefs      map[string]string // Targets of symbolic refs such as HEAD, by ref name
+	Capabilities []string
+}
+
+// newAdvertisement returns an empty advertisement ready to be filled.
+func newAdvertisement() *Advertisement {
+	return &Advertisement{Peeled: make(map[string]string), Symrefs: make(map[string]string)}
+}
+
+// ParseEndpoint parses a remote location the way Git does: "<helper>::<address>" or a URL whose
+// scheme gogit does not know naming a remote helper, a URL with a scheme, an scp-like
+// [user@]host:path naming an SSH remote, or else a local path.
+func ParseEndpoint(location string) (Endpoint, error) {
+	if helper, address, ok := strings.Cut(location, "::"); ok && isScheme(helper) {
+		return Endpoint{Protocol: ProtocolHelper, Helper: helper, Path: address}, nil
+	}
+	if scheme, _, ok := strings.Cut(location, "://"); ok && isScheme(scheme) {
+		switch strings.ToLower(scheme) {
+		case ProtocolHTTP, ProtocolHTTPS, ProtocolSSH, "git+ssh", "ssh+git", ProtocolGit, ProtocolFile:
+			return parseURL(location)
+		}
+		return Endpoint{Protocol: ProtocolHelper, Helper: scheme, Path: location}, nil
+	}
+
+	// A colon before any slash makes an scp-like address; "./a:b" stays a path
+	colon := strings.Index(location, ":")
+	slash := strings.Index(location, "/")
+	if colon > 0 && (slash < 0 || colon < slash) {
+		endpoint := Endpoint{Protocol: ProtocolSSH, Host: location[:colon], Path: location[colon+1:]}
+		if user, host, ok := strings.Cut(endpoint.Host, "@"); ok {
+			endpoint.User, endpoint.Host = user, host
+		}
+		if endpoint.Path == "" {
+			return Endpoint{}, fmt.Errorf("invalid remote '%s': no repository path", location)
+		}
+		return endpoint, checkHost(endpoint)
+	}
+
+	if location == "" {
+		return Endpoint{}, errors.New("invalid remote: empty location")
+	}
+	return Endpoint{Protocol: ProtocolFile, Path: location}, nil
+}
+
+// parseURL parses a remote location with a scheme.
+func parseURL(location string) (Endpoint, error) {
+	parsed, err := url.Parse(location)
+	if err != nil {
+		return Endpoint{}, fmt.Errorf("invalid remote '%s': %w", location, err)
+	}
+
+	endpoint := Endpoint{Host: parsed.Hostname(), Port: parsed.Port(), Path: parsed.Path}
+	if parsed.User != nil {
+		endpoint.User = parsed.User.Username()
+		endpoint.Password, _ = parsed.User.Password()
+	}
+
+	switch strings.ToLower(parsed.Scheme) {
+	case ProtocolHTTP, ProtocolHTTPS:
+		endpoint.Protocol = strings.ToLower(parsed.Scheme)
+	case ProtocolSSH, "git+ssh", "ssh+git":
+		endpoint.Protocol = ProtocolSSH
+		// ssh://host/~user/repo names a path relative to a home directory
+		if strings.HasPrefix(endpoint.Path, "/~") {
+			endpoint.Path = endpoint.Path[1:]
+		}
+	case ProtocolGit:
+		endpoint.Protocol = ProtocolGit
+	case ProtocolFile:
+		return Endpoint{Protocol: ProtocolFile, Path: parsed.Path}, nil
+	}
+
+	if endpoint.Host == "" {
+		return Endpoint{}, fmt.Errorf("invalid remote '%s': no host", location)
+	}
+	return endpoint, checkHost(endpoint)
+}
+
+// checkHost refuses hosts and users starting with "-", which ssh would take as options, as Git does.
+func checkHost(endpoint Endpoint) error {
+	if strings.HasPrefix(endpoint.Host, "-") {
+		return fmt.Errorf("strange hostname '%s' blocked", endpoint.Host)
+	}
+	if strings.HasPrefix(endpoint.User, "-") {
+		return fmt.Errorf("strange username '%s' blocked", endpoint.User)
+	}
+	return nil
+}
+
+// isScheme reports whether name can be a URL scheme: a letter followed by letters, digits,
+// "+", "-" or ".".
+func isScheme(name string) bool {
+	for i, c := range name {
+		letter := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+		if !letter && (i == 0 || !(c >= '0' && c <= '9' || c == '+' || c == '-' || c == '.')) {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// String formats endpoint as a URL, leaving out any password.
+func (endpoint Endpoint) String() string {
+	switch endpoint.Protocol {
+	case ProtocolFile:
+		return endpoint.Path
+	case ProtocolHelper:
+		if strings.HasPrefix(endpoint.Path, endpoint.Helper+"://") {
+			return endpoint.Path
+		}
+		return endpoint.Helper + "::" + endpoint.Path
+	}
+	u := url.URL{Scheme: endpoint.Protocol, Host: endpoint.hostPort(), Path: endpoint.Path}
+	if endpoint.User != "" {
+		u.User = url.User(endpoint.User)
+	}
+	return u.String()
+}
+
+// hostPort joins host and port, bracketing IPv6 addresses.
+func (endpoint Endpoint) hostPort() string {
+	host := endpoint.Host
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if endpoint.Port != "" {
+		host += ":" + endpoint.Port
+	}
+	return host
+}
+
+// DiscoverRefs connects to endpoint and returns the refs it advertises for fetching, without
+// transferring any object. Local repositories are listed with LocalAdvertisement instead.
+func DiscoverRefs(endpoint Endpoint) (*Advertisement, error) {
+	switch endpoint.Protocol {
+	case ProtocolHTTP, ProtocolHTTPS:
+		return discoverHTTP(endpoint)
+	case ProtocolSSH:
+		return discoverSSH(endpoint)
+	case ProtocolGit:
+		return discoverDaemon(endpoint)
+	case ProtocolHelper:
+		return discoverHelper(endpoint)
+	default:
+		return nil, fmt.Errorf("cannot discover refs over protocol '%s'", endpoint.Protocol)
+	}
+}
@@ -0,0 +1,256 @@
+package transport
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/pack"
+	"github.com/KostasZigo/gogit/internal/refs"
+	"github.com/KostasZigo/gogit/utils"
+)
+
+// agent identifies gogit to clients in its capabilities.
+const agent = "agent=gogit"
+
+// LocalAdvertisement lists the refs of the repository whose metadata directory is gitDir as
+// upload-pack advertises them: HEAD first when it points somewhere, then every ref by name,
+// annotated tags with the objects they finally point to.
+func LocalAdvertisement(gitDir string) (*Advertisement, error) {
+	store := objects.NewObjectStore(gitDir, objects.WithReplaceObjects(false))
+	advertisement := newAdvertisement()
+
+	head, err := refs.Resolve(gitDir, constants.Head)
+	if err != nil && !errors.Is(err, refs.ErrRefNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		advertisement.Refs = append(advertisement.Refs, refs.Ref{Name: constants.Head, Hash: head})
+		target, err := refs.SymbolicTarget(gitDir, constants.Head)
+		if err != nil {
+			return nil, err
+		}
+		if target != "" {
+			advertisement.Symrefs[constants.Head] = target
+		}
+	}
+
+	listed, err := refs.List(gitDir, constants.Refs+"/")
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range listed {
+		peeled, err := peel(store, ref.Hash)
+		if err != nil {
+			return nil, err
+		}
+		if peeled != ref.Hash {
+			advertisement.Peeled[ref.Name] = peeled
+		}
+	}
+	advertisement.Refs = append(advertisement.Refs, listed...)
+	return advertisement, nil
+}
+
+// peel follows annotated tags from hash to the first object that is not a tag.
+func peel(store *objects.ObjectStore, hash string) (string, error) {
+	for {
+		object, objectType, err := store.ReadObject(hash)
+		if err != nil {
+			return "", fmt.Errorf("failed to read object %s: %w", hash, err)
+		}
+		if objectType != utils.TagObjectType {
+			return hash, nil
+		}
+		hash = object.(*objects.Tag).Object()
+	}
+}
+
+// ServeUploadPack serves one fetch from the repository whose metadata directory is gitDir as
+// upload-pack does over protocol v0: it advertises the refs, reads the wanted objects, negotiates
+// which the client already has and sends a pack of the rest. A client that only wanted the advertisement
+// hangs up with a flush packet. Multi-ack, side-band and shallow capabilities are not offered,
+// so clients fall back to the basic exchange.
+func ServeUploadPack(gitDir string, in io.Reader, out io.Writer) error {
+	advertisement, err := LocalAdvertisement(gitDir)
+	if err != nil {
+		return err
+	}
+	if err := writeAdvertisement(out, advertisement); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(in)
+	wants, err := readWants(reader, advertisement)
+	if err != nil || len(wants) == 0 {
+		return err
+	}
+
+	store := objects.NewObjectStore(gitDir, objects.WithReplaceObjects(false))
+	common, err := negotiate(reader, out, store)
+	if err != nil {
+		return err
+	}
+	return sendPack(out, store, wants, common)
+}
+
+// writeAdvertisement writes advertisement as protocol v0 ref lines, the first carrying the
+// server capabilities, ended by a flush packet.
+func writeAdvertisement(out io.Writer, advertisement *Advertisement) error {
+	capabilities := []string{agent}
+	for _, name := range slices.Sorted(maps.Keys(advertisement.Symrefs)) {
+		capabilities = append(capabilities, "symref="+name+":"+advertisement.Symrefs[name])
+	}
+	capabilityList := "\x00" + strings.Join(capabilities, " ")
+
+	if len(advertisement.Refs) == 0 {
+		if err := writePktLine(out, "%s capabilities^{}%s\n", strings.Repeat("0", constants.HashStringLength), capabilityList); err != nil {
+			return err
+		}
+	}
+	for _, ref := range advertisement.Refs {
+		if err := writePktLine(out, "%s %s%s\n", ref.Hash, ref.Name, capabilityList); err != nil {
+			return err
+		}
+		capabilityList = ""
+		if peeled, ok := advertisement.Peeled[ref.Name]; ok {
+			if err := writePktLine(out, "%s %s^{}\n", peeled, ref.Name); err != nil {
+				return err
+			}
+		}
+	}
+	return writeFlush(out)
+}
+
+// readWants reads the "want <hash>" lines up to the flush packet ending them. Only objects the
+// advertisement names may be wanted.
+func readWants(reader *bufio.Reader, advertisement *Advertisement) ([]string, error) {
+	offered := make(map[string]bool)
+	for _, ref := range advertisement.Refs {
+		offered[ref.Hash] = true
+		if peeled, ok := advertisement.Peeled[ref.Name]; ok {
+			offered[peeled] = true
+		}
+	}
+
+	var wants []string
+	for {
+		line, err := readPktLine(reader)
+		if errors.Is(err, io.ErrUnexpectedEOF) && len(wants) == 0 {
+			// Clients listing refs may hang up without a flush
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line == nil {
+			return wants, nil
+		}
+
+		text := strings.TrimSuffix(string(line), "\n")
+		want, ok := strings.CutPrefix(text, "want ")
+		if !ok {
+			return nil, fmt.Errorf("protocol error: expected want, got %q", text)
+		}
+		// The first want carries the client's capabilities
+		want, _, _ = strings.Cut(want, " ")
+		if !utils.IsHash(want) {
+			return nil, fmt.Errorf("protocol error: invalid want %q", want)
+		}
+		if !offered[want] {
+			return nil, fmt.Errorf("not our ref %s", want)
+		}
+		wants = append(wants, want)
+	}
+}
+
+// negotiate reads the client's "have" lines until "done", returning those the repository has.
+// Without multi-ack the first common object is acknowledged at once and nothing more is said
+// until "done"; each flush before one is found is answered with NAK, as is "done" without one.
+func negotiate(reader *bufio.Reader, out io.Writer, store *objects.ObjectStore) ([]string, error) {
+	var common []string
+	for {
+		line, err := readPktLine(reader)
+		if err != nil {
+			return nil, err
+		}
+		if line == nil {
+			if len(common) == 0 {
+				if err := writePktLine(out, "NAK\n"); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		text := strings.TrimSuffix(string(line), "\n")
+		if text == "done" {
+			if len(common) == 0 {
+				return nil, writePktLine(out, "NAK\n")
+			}
+			return common, nil
+		}
+		have, ok := strings.CutPrefix(text, "have ")
+		if !ok {
+			return nil, fmt.Errorf("protocol error: expected have or done, got %q", text)
+		}
+		// Checked before the store sees it, which would otherwise look up arbitrary paths
+		if !utils.IsHash(have) {
+			return nil, fmt.Errorf("protocol error: invalid have %q", have)
+		}
+		if !store.Exists(have) {
+			continue
+		}
+		common = append(common, have)
+		if len(common) == 1 {
+			if err := writePktLine(out, "ACK %s\n", have); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// sendPack writes a pack of the objects reachable from wants but not from common.
+func sendPack(out io.Writer, store *objects.ObjectStore, wants, common []string) error {
+	excluded := make(map[string]bool)
+	if _, err := store.Reachable(common, excluded, nil); err != nil {
+		return err
+	}
+	hashes, err := store.Reachable(wants, excluded, nil)
+	if err != nil {
+		return err
+	}
+
+	writer, err := pack.NewWriter(out, len(hashes))
+	if err != nil {
+		return err
+	}
+	for _, hash := range hashes {
+		if err := packObject(writer, store, hash); err != nil {
+			return err
+		}
+	}
+	_, err = writer.Close()
+	return err
+}
+
+// packObject appends the stored object hash to writer.
+func packObject(writer *pack.Writer, store *objects.ObjectStore, hash string) error {
+	reader, err := store.OpenObject(hash)
+	if err != nil {
+		return fmt.Errorf("failed to read object %s: %w", hash, err)
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("failed to read object %s: %w", hash, err)
+	}
+	return writer.WriteObject(reader.Type(), content)
+}
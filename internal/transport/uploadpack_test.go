@@ -0,0 +1,208 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/pack"
+	"github.com/KostasZigo/gogit/internal/refs"
+	"github.com/KostasZigo/gogit/internal/repository"
+	"github.com/KostasZigo/gogit/testutils"
+	"github.com/KostasZigo/gogit/utils"
+)
+
+// storeHistory stores count commits on main, each with a tree holding one blob of its own,
+// tags the first with an annotated tag v1, and returns the commit hashes oldest first.
+func storeHistory(t *testing.T, repoPath string, count int) []string {
+	t.Helper()
+
+	store := objects.NewObjectStore(repository.GitDir(repoPath))
+	author := objects.Author{Name: "A", Email: "a@example.com", Timestamp: time.Unix(1700000000, 0).UTC()}
+	var commits []string
+	parent := ""
+	for i := range count {
+		blob := objects.NewBlob(fmt.Appendf(nil, "version %d\n", i))
+		entry, err := objects.NewTreeEntry(objects.ModeRegularFile, "file.txt", blob.Hash())
+		if err != nil {
+			t.Fatalf("Failed to create tree entry: %v", err)
+		}
+		tree, err := objects.NewTree([]objects.TreeEntry{*entry})
+		if err != nil {
+			t.Fatalf("Failed to create tree: %v", err)
+		}
+		commit, err := objects.NewCommit(tree.Hash(), parent, fmt.Sprintf("commit %d", i), author)
+		if err != nil {
+			t.Fatalf("Failed to create commit: %v", err)
+		}
+		for _, object := range []objects.Object{blob, tree, commit} {
+			if err := store.Store(object); err != nil {
+				t.Fatalf("Failed to store object: %v", err)
+			}
+		}
+		parent = commit.Hash()
+		commits = append(commits, parent)
+	}
+
+	tag, err := objects.NewTag(commits[0], utils.CommitObjectType, "v1", author, "v1\n")
+	if err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+	if err := store.Store(tag); err != nil {
+		t.Fatalf("Failed to store tag: %v", err)
+	}
+	if err := refs.Update(repository.GitDir(repoPath), constants.BranchRefPrefix+constants.DefaultBranch, parent); err != nil {
+		t.Fatalf("Failed to update branch: %v", err)
+	}
+	if err := refs.Update(repository.GitDir(repoPath), constants.TagRefPrefix+"v1", tag.Hash()); err != nil {
+		t.Fatalf("Failed to update tag: %v", err)
+	}
+	return commits
+}
+
+// serveUploadPack runs upload-pack on repoPath with client input, returning its output reader
+// positioned after the advertisement, and the advertisement.
+func serveUploadPack(t *testing.T, repoPath, input string) (*bufio.Reader, *Advertisement) {
+	t.Helper()
+
+	var out bytes.Buffer
+	if err := ServeUploadPack(repository.GitDir(repoPath), strings.NewReader(input), &out); err != nil {
+		t.Fatalf("ServeUploadPack failed: %v", err)
+	}
+	reader := bufio.NewReader(&out)
+	advertisement, err := readAdvertisement(reader)
+	if err != nil {
+		t.Fatalf("Failed to read advertisement: %v", err)
+	}
+	return reader, advertisement
+}
+
+// readPackHashes reads the acknowledgement packet expected before the pack, then the pack, and
+// returns the names of the objects it holds.
+func readPackHashes(t *testing.T, reader *bufio.Reader, acknowledgement string) []string {
+	t.Helper()
+
+	line, err := readPktLine(reader)
+	if err != nil {
+		t.Fatalf("Failed to read acknowledgement: %v", err)
+	}
+	if string(line) != acknowledgement {
+		t.Fatalf("Expected %q, got %q", acknowledgement, line)
+	}
+
+	var data bytes.Buffer
+	data.ReadFrom(reader)
+	parsed, err := pack.Parse(data.Bytes(), nil)
+	if err != nil {
+		t.Fatalf("Failed to parse pack: %v", err)
+	}
+	var hashes []string
+	for _, entry := range parsed.Entries {
+		hashes = append(hashes, entry.Hash)
+	}
+	return hashes
+}
+
+// TestServeUploadPack_Advertisement verifies refs, peeled tags and the HEAD symref are advertised
+// and a client hanging up after them is served nothing more.
+func TestServeUploadPack_Advertisement(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	commits := storeHistory(t, repoPath, 2)
+
+	reader, advertisement := serveUploadPack(t, repoPath, flushPkt)
+	names := make([]string, 0, len(advertisement.Refs))
+	for _, ref := range advertisement.Refs {
+		names = append(names, ref.Name)
+	}
+	if expected := []string{"HEAD", "refs/heads/main", "refs/tags/v1"}; !slices.Equal(names, expected) {
+		t.Errorf("Expected refs %v, got %v", expected, names)
+	}
+	if advertisement.Peeled["refs/tags/v1"] != commits[0] {
+		t.Errorf("Expected refs/tags/v1 to peel to %s, got %v", commits[0], advertisement.Peeled)
+	}
+	if advertisement.Symrefs["HEAD"] != "refs/heads/main" {
+		t.Errorf("Expected HEAD to point to refs/heads/main, got %v", advertisement.Symrefs)
+	}
+	if reader.Buffered() != 0 {
+		t.Errorf("Expected nothing after the advertisement, got %d bytes", reader.Buffered())
+	}
+}
+
+// TestServeUploadPack_Clone verifies a client without objects gets NAK and every reachable object.
+func TestServeUploadPack_Clone(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	commits := storeHistory(t, repoPath, 2)
+
+	input := pktLines("want "+commits[1]+" ofs-delta\n", "", "done\n")
+	reader, _ := serveUploadPack(t, repoPath, input)
+
+	// Two commits with a tree and a blob each
+	if hashes := readPackHashes(t, reader, "NAK\n"); len(hashes) != 6 || !slices.Contains(hashes, commits[0]) {
+		t.Errorf("Expected the 6 objects of both commits, got %v", hashes)
+	}
+}
+
+// TestServeUploadPack_Incremental verifies the first common have is acknowledged and objects
+// it reaches are left out of the pack.
+func TestServeUploadPack_Incremental(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	commits := storeHistory(t, repoPath, 3)
+
+	unknown := testutils.RandomHash()
+	input := pktLines("want "+commits[2]+"\n", "", "have "+unknown+"\n", "", "have "+commits[1]+"\n", "have "+commits[0]+"\n", "", "done\n")
+	var out bytes.Buffer
+	if err := ServeUploadPack(repository.GitDir(repoPath), strings.NewReader(input), &out); err != nil {
+		t.Fatalf("ServeUploadPack failed: %v", err)
+	}
+	reader := bufio.NewReader(&out)
+	if _, err := readAdvertisement(reader); err != nil {
+		t.Fatalf("Failed to read advertisement: %v", err)
+	}
+
+	line, err := readPktLine(reader)
+	if err != nil || string(line) != "NAK\n" {
+		t.Fatalf("Expected NAK for a batch without common objects, got %q (%v)", line, err)
+	}
+	hashes := readPackHashes(t, reader, "ACK "+commits[1]+"\n")
+	if len(hashes) != 3 || !slices.Contains(hashes, commits[2]) {
+		t.Errorf("Expected only the 3 objects of the newest commit, got %v", hashes)
+	}
+}
+
+// TestServeUploadPack_Invalid verifies objects the advertisement does not name cannot be wanted,
+// and that wants and haves other than full hashes are refused before the store sees them.
+func TestServeUploadPack_Invalid(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	commits := storeHistory(t, repoPath, 3)
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"not a ref tip", pktLines("want "+commits[1]+"\n", "", "done\n")},
+		{"unknown object", pktLines("want "+testutils.RandomHash()+"\n", "", "done\n")},
+		{"not a want", pktLines("have "+commits[2]+"\n", "")},
+		{"short want", pktLines("want a\n", "", "done\n")},
+		{"short have", pktLines("want "+commits[2]+"\n", "", "have a\n", "done\n")},
+		{"path as have", pktLines("want "+commits[2]+"\n", "", "have ../../../../../../../../etc/passwd\n", "done\n")},
+		{"upper case have", pktLines("want "+commits[2]+"\n", "", "have "+strings.ToUpper(commits[0])+"\n", "done\n")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := ServeUploadPack(repository.GitDir(repoPath), strings.NewReader(test.input), &out); err == nil {
+				t.Error("Expected error")
+			}
+			if strings.Contains(out.String(), "ACK") {
+				t.Errorf("Expected no acknowledgement, got %q", out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package gogit
+
+import (
+	"fmt"
+
+	"github.com/KostasZigo/gogit/internal/diff"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/utils"
+)
+
+// Change statuses, the letters git diff --raw prints.
+const (
+	ChangeAdded       byte = diff.StatusAdded
+	ChangeDeleted     byte = diff.StatusDeleted
+	ChangeModified    byte = diff.StatusModified
+	ChangeTypeChanged byte = diff.StatusTypeChanged
+)
+
+// Change is a file that differs between two trees.
+// Modes are octal strings such as "100644"; both mode and hash are empty on the absent side.
+type Change struct {
+	Path    string
+	Status  byte // One of the Change constants
+	OldMode string
+	OldHash string
+	NewMode string
+	NewHash string
+}
+
+// DiffTrees lists the files that differ from oldTree to newTree, recursing into directories.
+// Trees are named by full or abbreviated hash, or by a commit whose tree is compared. An empty
+// name stands for the empty tree, so every file of the other side is added or deleted.
+func (r *Repository) DiffTrees(oldTree, newTree string) ([]Change, error) {
+	oldHash, err := r.treeHash(oldTree)
+	if err != nil {
+		return nil, err
+	}
+	newHash, err := r.treeHash(newTree)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := diff.Trees(r.store, oldHash, newHash, true, nil)
+	if err != nil {
+		return nil, err
+	}
+	public := make([]Change, 0, len(changes))
+	for _, change := range changes {
+		public = append(public, Change{
+			Path:    change.Path,
+			Status:  change.Status,
+			OldMode: string(change.Old.Mode),
+			OldHash: change.Old.Hash,
+			NewMode: string(change.New.Mode),
+			NewHash: change.New.Hash,
+		})
+	}
+	return public, nil
+}
+
+// treeHash resolves name to a tree hash, taking the tree of a commit. Empty names stay empty.
+func (r *Repository) treeHash(name string) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+	hash, err := r.ResolveObject(name)
+	if err != nil {
+		return "", err
+	}
+
+	reader, err := r.store.OpenObject(hash)
+	if err != nil {
+		return "", err
+	}
+	objectType := reader.Type()
+	reader.Close()
+
+	switch objectType {
+	case utils.TreeObjectType:
+		return hash, nil
+	case utils.CommitObjectType:
+		commit, err := r.store.ReadCommit(hash)
+		if err != nil {
+			return "", err
+		}
+		return commit.TreeHash(), nil
+	}
+	return "", fmt.Errorf("%w: %s is a %s", objects.ErrNotATree, hash, objectType)
+}
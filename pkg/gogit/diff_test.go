@@ -0,0 +1,76 @@
+package gogit
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// TestRepository_DiffTrees verifies changed and added files are listed between trees, and that
+// commits and the empty tree can be compared.
+func TestRepository_DiffTrees(t *testing.T) {
+	repo := openTestRepository(t)
+	writeTree := func(files map[string]string) string {
+		t.Helper()
+		for name, content := range files {
+			testutils.CreateTestFile(t, repo.Path(), name, []byte(content))
+			if err := repo.Add(name); err != nil {
+				t.Fatalf("Add failed: %v", err)
+			}
+		}
+		tree, err := repo.WriteTree()
+		if err != nil {
+			t.Fatalf("WriteTree failed: %v", err)
+		}
+		return tree
+	}
+	oldTree := writeTree(map[string]string{"a.txt": "a\n"})
+	newTree := writeTree(map[string]string{"a.txt": "changed\n", "c.txt": "c\n"})
+
+	changes, err := repo.DiffTrees(oldTree, newTree)
+	if err != nil {
+		t.Fatalf("DiffTrees failed: %v", err)
+	}
+	var summary []string
+	for _, change := range changes {
+		summary = append(summary, string(change.Status)+" "+change.Path)
+	}
+	if expected := []string{"M a.txt", "A c.txt"}; !slices.Equal(summary, expected) {
+		t.Fatalf("Expected changes %v, got %v", expected, summary)
+	}
+	if added := changes[1]; added.OldMode != "" || added.OldHash != "" || added.NewMode != "100644" {
+		t.Errorf("Unexpected sides for added file: %+v", added)
+	}
+
+	commit, err := objects.NewCommit(newTree, "", "commit", objects.Author{Name: "Test", Email: "test@example.com", Timestamp: time.Unix(1700000000, 0).UTC()})
+	if err != nil {
+		t.Fatalf("Failed to create commit: %v", err)
+	}
+	if err := repo.store.Store(commit); err != nil {
+		t.Fatalf("Failed to store commit: %v", err)
+	}
+	if changes, err := repo.DiffTrees(oldTree, commit.Hash()[:7]); err != nil || len(changes) != 2 {
+		t.Errorf("Expected the commit's tree to be compared, got %v (%v)", changes, err)
+	}
+
+	changes, err = repo.DiffTrees(newTree, "")
+	if err != nil {
+		t.Fatalf("DiffTrees against the empty tree failed: %v", err)
+	}
+	for _, change := range changes {
+		if change.Status != ChangeDeleted {
+			t.Errorf("Expected %s to be deleted, got %c", change.Path, change.Status)
+		}
+	}
+
+	blob, err := repo.WriteBlob([]byte("blob\n"))
+	if err != nil {
+		t.Fatalf("WriteBlob failed: %v", err)
+	}
+	if _, err := repo.DiffTrees(blob, newTree); err == nil {
+		t.Error("Expected error comparing a blob")
+	}
+}
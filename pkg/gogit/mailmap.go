@@ -0,0 +1,26 @@
+package gogit
+
+import (
+	"github.com/KostasZigo/gogit/internal/mailmap"
+)
+
+// Mailmap maps commit identities to canonical ones as listed in .mailmap.
+type Mailmap struct {
+	mailmap *mailmap.Mailmap
+}
+
+// Mailmap reads the .mailmap file at the repository root.
+// A repository without one gets a mailmap that leaves identities unchanged.
+func (r *Repository) Mailmap() (*Mailmap, error) {
+	identities, err := mailmap.Load(r.path)
+	if err != nil {
+		return nil, err
+	}
+	return &Mailmap{mailmap: identities}, nil
+}
+
+// Map returns signature with its name and email replaced by their canonical forms.
+func (m *Mailmap) Map(signature Signature) Signature {
+	signature.Name, signature.Email = m.mailmap.Map(signature.Name, signature.Email)
+	return signature
+}
@@ -0,0 +1,26 @@
+package gogit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// TestRepository_Mailmap verifies signatures are canonicalized by the repository's .mailmap.
+func TestRepository_Mailmap(t *testing.T) {
+	repo := openTestRepository(t)
+	testutils.CreateTestFile(t, repo.Path(), constants.MailmapFile, []byte("Ada Lovelace <ada@example.com>\n"))
+
+	mailmap, err := repo.Mailmap()
+	if err != nil {
+		t.Fatalf("Failed to load mailmap: %v", err)
+	}
+
+	when := time.Unix(1700000000, 0)
+	mapped := mailmap.Map(Signature{Name: "ada", Email: "ada@example.com", When: when})
+	if mapped.Name != "Ada Lovelace" || mapped.Email != "ada@example.com" || !mapped.When.Equal(when) {
+		t.Errorf("Unexpected mapped signature: %+v", mapped)
+	}
+}
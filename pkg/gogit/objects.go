@@ -0,0 +1,154 @@
+package gogit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/utils"
+)
+
+// ObjectType names the kind of a stored object.
+type ObjectType string
+
+const (
+	BlobObject   ObjectType = ObjectType(utils.BlobObjectType)
+	TreeObject   ObjectType = ObjectType(utils.TreeObjectType)
+	CommitObject ObjectType = ObjectType(utils.CommitObjectType)
+	TagObject    ObjectType = ObjectType(utils.TagObjectType)
+)
+
+// ErrStopWalk can be returned from a WalkCommits callback to end the walk without error.
+var ErrStopWalk = objects.ErrStopWalk
+
+// Errors returned by object operations, matchable with errors.Is.
+var (
+	ErrObjectNotFound  = objects.ErrObjectNotFound
+	ErrAmbiguousObject = objects.ErrAmbiguousObject
+	ErrNotABlob        = objects.ErrNotABlob
+	ErrNotATree        = objects.ErrNotATree
+	ErrNotACommit      = objects.ErrNotACommit
+	ErrNotATag         = objects.ErrNotATag
+	ErrHashMismatch    = objects.ErrHashMismatch
+)
+
+// Object is the raw content of a stored object.
+type Object struct {
+	Hash    string
+	Type    ObjectType
+	Content []byte // Object content without the "<type> <size>\0" header
+}
+
+// Signature identifies the author or committer of a commit.
+type Signature struct {
+	Name  string
+	Email string
+	When  time.Time
+}
+
+// Commit is a parsed commit object.
+// Message is transcoded to UTF-8 when Encoding names a supported encoding.
+type Commit struct {
+	Hash      string
+	Tree      string
+	Parents   []string
+	Author    Signature
+	Committer Signature
+	Message   string
+	Encoding  string
+}
+
+// ResolveObject expands a full or abbreviated (4+ characters) hash to the full object hash.
+func (r *Repository) ResolveObject(name string) (string, error) {
+	return r.store.ResolvePrefix(name)
+}
+
+// ReadObject reads the object named by a full or abbreviated hash.
+func (r *Repository) ReadObject(name string) (*Object, error) {
+	hash, err := r.ResolveObject(name)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := r.store.OpenObject(hash)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object %s: %w", hash, err)
+	}
+
+	return &Object{
+		Hash:    hash,
+		Type:    ObjectType(reader.Type()),
+		Content: content,
+	}, nil
+}
+
+// WriteObject stores content as an object of the given type and returns its hash.
+// Content is stored as given; callers are responsible for well-formed tree and commit data.
+func (r *Repository) WriteObject(objectType ObjectType, content []byte) (string, error) {
+	if !utils.ObjectType(objectType).IsValid() {
+		return "", fmt.Errorf("invalid object type: %s", objectType)
+	}
+
+	return r.store.StoreStream(utils.ObjectType(objectType), int64(len(content)), bytes.NewReader(content))
+}
+
+// ReadCommit reads and parses the commit named by a full or abbreviated hash.
+func (r *Repository) ReadCommit(name string) (*Commit, error) {
+	hash, err := r.ResolveObject(name)
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := r.store.ReadCommit(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return newCommit(commit), nil
+}
+
+// WalkCommits visits the commit named by start and then its ancestors through every parent,
+// newest committer date first; each commit is visited once, even when merges reach it twice.
+// The walk stops after the root commits, at the first callback error, when ctx is cancelled,
+// or when the callback returns ErrStopWalk, in which case WalkCommits returns nil.
+func (r *Repository) WalkCommits(ctx context.Context, start string, fn func(*Commit) error) error {
+	hash, err := r.ResolveObject(start)
+	if err != nil {
+		return err
+	}
+
+	return r.store.WalkCommits(ctx, []string{hash}, func(commit *objects.Commit) error {
+		return fn(newCommit(commit))
+	})
+}
+
+// newCommit converts internal commit into its public representation.
+func newCommit(commit *objects.Commit) *Commit {
+	return &Commit{
+		Hash:      commit.Hash(),
+		Tree:      commit.TreeHash(),
+		Parents:   commit.Parents(),
+		Author:    newSignature(commit.Author()),
+		Committer: newSignature(commit.Committer()),
+		Message:   commit.DisplayMessage(),
+		Encoding:  commit.Encoding(),
+	}
+}
+
+// newSignature converts internal author into public signature.
+func newSignature(author objects.Author) Signature {
+	return Signature{
+		Name:  author.Name,
+		Email: author.Email,
+		When:  author.Timestamp,
+	}
+}
@@ -0,0 +1,149 @@
+package gogit
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// storeCommitChain stores count commits, each parented on the previous, and returns hashes oldest first.
+func storeCommitChain(t *testing.T, repo *Repository, count int) []string {
+	t.Helper()
+
+	author := objects.Author{Name: "Test", Email: "test@example.com", Timestamp: time.Unix(1700000000, 0).UTC()}
+	var hashes []string
+	parent := ""
+	for i := range count {
+		commit, err := objects.NewCommit(testutils.RandomHash(), parent, "commit "+string(rune('a'+i)), author)
+		if err != nil {
+			t.Fatalf("Failed to create commit: %v", err)
+		}
+		if err := repo.store.Store(commit); err != nil {
+			t.Fatalf("Failed to store commit: %v", err)
+		}
+		parent = commit.Hash()
+		hashes = append(hashes, parent)
+	}
+	return hashes
+}
+
+// TestRepository_WriteAndReadObject verifies raw objects round-trip by full and abbreviated hash.
+func TestRepository_WriteAndReadObject(t *testing.T) {
+	repo := openTestRepository(t)
+	content := []byte("public api\n")
+
+	hash, err := repo.WriteObject(BlobObject, content)
+	if err != nil {
+		t.Fatalf("WriteObject failed: %v", err)
+	}
+
+	for _, name := range []string{hash, hash[:7]} {
+		obj, err := repo.ReadObject(name)
+		if err != nil {
+			t.Fatalf("ReadObject(%s) failed: %v", name, err)
+		}
+		if obj.Hash != hash || obj.Type != BlobObject || string(obj.Content) != string(content) {
+			t.Errorf("Unexpected object %+v", obj)
+		}
+	}
+
+	if _, err := repo.ReadObject(testutils.RandomHash()); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Expected ErrObjectNotFound, got %v", err)
+	}
+
+	if _, err := repo.WriteObject("bogus", content); err == nil {
+		t.Error("Expected error for invalid object type")
+	}
+}
+
+// TestRepository_ReadCommit verifies commit fields are exposed.
+func TestRepository_ReadCommit(t *testing.T) {
+	repo := openTestRepository(t)
+	hashes := storeCommitChain(t, repo, 2)
+
+	commit, err := repo.ReadCommit(hashes[1])
+	if err != nil {
+		t.Fatalf("ReadCommit failed: %v", err)
+	}
+	if commit.Hash != hashes[1] || !slices.Equal(commit.Parents, []string{hashes[0]}) {
+		t.Errorf("Unexpected commit %+v", commit)
+	}
+	if commit.Author.Email != "test@example.com" || commit.Message != "commit b" {
+		t.Errorf("Unexpected commit metadata %+v", commit)
+	}
+}
+
+// TestRepository_WalkCommits verifies history is visited newest first and can stop early.
+func TestRepository_WalkCommits(t *testing.T) {
+	repo := openTestRepository(t)
+	hashes := storeCommitChain(t, repo, 3)
+
+	var visited []string
+	err := repo.WalkCommits(context.Background(), hashes[2], func(commit *Commit) error {
+		visited = append(visited, commit.Hash)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkCommits failed: %v", err)
+	}
+	if !slices.Equal(visited, []string{hashes[2], hashes[1], hashes[0]}) {
+		t.Errorf("Unexpected walk order %v", visited)
+	}
+
+	visited = nil
+	err = repo.WalkCommits(context.Background(), hashes[2], func(commit *Commit) error {
+		visited = append(visited, commit.Hash)
+		return ErrStopWalk
+	})
+	if err != nil || len(visited) != 1 {
+		t.Errorf("Expected walk to stop after one commit, got %v and error %v", visited, err)
+	}
+
+	callbackErr := errors.New("callback failed")
+	err = repo.WalkCommits(context.Background(), hashes[2], func(commit *Commit) error { return callbackErr })
+	if !errors.Is(err, callbackErr) {
+		t.Errorf("Expected callback error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = repo.WalkCommits(ctx, hashes[2], func(commit *Commit) error {
+		t.Fatal("Callback should not run after cancellation")
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+// TestRepository_WalkCommits_Merge verifies every parent of a merge is walked, each commit once.
+func TestRepository_WalkCommits_Merge(t *testing.T) {
+	repo := openTestRepository(t)
+	main := storeCommitChain(t, repo, 2)
+	side := storeCommitChain(t, repo, 1)
+	merge, err := objects.ParseCommit([]byte("tree " + testutils.RandomHash() + "\nparent " + main[1] + "\nparent " + side[0] +
+		"\nauthor Test <test@example.com> 1700000100 +0000\ncommitter Test <test@example.com> 1700000100 +0000\n\nmerge\n"))
+	if err != nil {
+		t.Fatalf("Failed to parse merge commit: %v", err)
+	}
+	if err := repo.store.Store(merge); err != nil {
+		t.Fatalf("Failed to store merge commit: %v", err)
+	}
+
+	var visited []string
+	err = repo.WalkCommits(context.Background(), merge.Hash(), func(commit *Commit) error {
+		visited = append(visited, commit.Hash)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkCommits failed: %v", err)
+	}
+	if expected := []string{merge.Hash(), main[1], side[0], main[0]}; !slices.Equal(visited, expected) {
+		t.Errorf("Expected walk %v, got %v", expected, visited)
+	}
+}
@@ -0,0 +1,89 @@
+package gogit
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/refs"
+	"github.com/KostasZigo/gogit/utils"
+)
+
+// ErrRefNotFound is returned when a ref does not exist, matchable with errors.Is.
+var ErrRefNotFound = refs.ErrRefNotFound
+
+// ResolveRef returns the object hash a ref such as "HEAD" or "refs/heads/main" points to,
+// following symbolic refs.
+func (r *Repository) ResolveRef(name string) (string, error) {
+	if err := checkRefName(name); err != nil {
+		return "", err
+	}
+
+	r.refsMu.RLock()
+	defer r.refsMu.RUnlock()
+
+	return refs.Resolve(r.gitDir, name)
+}
+
+// UpdateRef points the ref with full name, such as "refs/heads/main", at an existing object.
+func (r *Repository) UpdateRef(name, hash string) error {
+	if err := checkRefName(name); err != nil {
+		return err
+	}
+	if !r.HasObject(hash) {
+		return fmt.Errorf("%w: %s", ErrObjectNotFound, hash)
+	}
+
+	r.refsMu.Lock()
+	defer r.refsMu.Unlock()
+
+	return refs.Update(r.gitDir, name, hash)
+}
+
+// DeleteRef removes the ref with full name, returning ErrRefNotFound if it does not exist.
+func (r *Repository) DeleteRef(name string) error {
+	if err := checkRefName(name); err != nil {
+		return err
+	}
+
+	r.refsMu.Lock()
+	defer r.refsMu.Unlock()
+
+	return refs.Delete(r.gitDir, name)
+}
+
+// checkRefName accepts HEAD and valid full names under refs/, so names cannot reach outside
+// the refs directory.
+func checkRefName(name string) error {
+	if name != constants.Head && !(strings.HasPrefix(name, constants.Refs+"/") && utils.ValidRefName(name)) {
+		return fmt.Errorf("invalid ref name %q", name)
+	}
+	return nil
+}
+
+// Ref is a named pointer to an object.
+type Ref struct {
+	Name string // Full name, such as "refs/tags/v1.0"
+	Hash string
+}
+
+// ListRefs returns the refs whose full names start with prefix, such as "refs/tags/", sorted by
+// name. An empty prefix lists every ref.
+func (r *Repository) ListRefs(prefix string) ([]Ref, error) {
+	if prefix != "" && (!strings.HasPrefix(prefix, constants.Refs+"/") || strings.Contains(prefix, "..")) {
+		return nil, fmt.Errorf("invalid ref prefix %q", prefix)
+	}
+
+	r.refsMu.RLock()
+	defer r.refsMu.RUnlock()
+
+	found, err := refs.List(r.gitDir, prefix)
+	if err != nil {
+		return nil, err
+	}
+	listed := make([]Ref, 0, len(found))
+	for _, ref := range found {
+		listed = append(listed, Ref{Name: ref.Name, Hash: ref.Hash})
+	}
+	return listed, nil
+}
@@ -0,0 +1,102 @@
+package gogit
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// TestRepository_Refs verifies refs are updated, resolved through HEAD and deleted, and that
+// invalid names and missing objects are refused.
+func TestRepository_Refs(t *testing.T) {
+	repo := openTestRepository(t)
+	hash, err := repo.WriteBlob([]byte("target\n"))
+	if err != nil {
+		t.Fatalf("WriteBlob failed: %v", err)
+	}
+
+	if err := repo.UpdateRef("refs/heads/main", hash); err != nil {
+		t.Fatalf("UpdateRef failed: %v", err)
+	}
+	if resolved, err := repo.ResolveRef("HEAD"); err != nil || resolved != hash {
+		t.Errorf("Expected HEAD at %s, got %s (%v)", hash, resolved, err)
+	}
+
+	if err := repo.UpdateRef("refs/heads/other", testutils.RandomHash()); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Expected missing object error, got %v", err)
+	}
+	for _, name := range []string{"../config", "refs/../../config", "main", "refs/heads/a..b"} {
+		if err := repo.UpdateRef(name, hash); err == nil {
+			t.Errorf("Expected invalid ref name %q to be refused", name)
+		}
+	}
+
+	if err := repo.DeleteRef("refs/heads/main"); err != nil {
+		t.Fatalf("DeleteRef failed: %v", err)
+	}
+	if _, err := repo.ResolveRef("refs/heads/main"); !errors.Is(err, ErrRefNotFound) {
+		t.Errorf("Expected deleted ref to be missing, got %v", err)
+	}
+}
+
+// TestRepository_ConcurrentUpdateRef verifies concurrent updates of one ref are serialized
+// instead of failing on its lock file.
+func TestRepository_ConcurrentUpdateRef(t *testing.T) {
+	repo := openTestRepository(t)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := range 20 {
+		wg.Go(func() {
+			hash, err := repo.WriteBlob(fmt.Appendf(nil, "blob %d\n", i))
+			if err == nil {
+				err = repo.UpdateRef("refs/heads/main", hash)
+			}
+			errs <- err
+		})
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Concurrent update failed: %v", err)
+		}
+	}
+}
+
+// TestRepository_ListRefs verifies refs are listed by prefix in name order and that prefixes
+// outside refs/ are refused.
+func TestRepository_ListRefs(t *testing.T) {
+	repo := openTestRepository(t)
+	hash, err := repo.WriteBlob([]byte("target\n"))
+	if err != nil {
+		t.Fatalf("WriteBlob failed: %v", err)
+	}
+	for _, name := range []string{"refs/tags/v2", "refs/heads/main", "refs/tags/v1"} {
+		if err := repo.UpdateRef(name, hash); err != nil {
+			t.Fatalf("UpdateRef failed: %v", err)
+		}
+	}
+
+	tags, err := repo.ListRefs("refs/tags/")
+	if err != nil {
+		t.Fatalf("ListRefs failed: %v", err)
+	}
+	if expected := []Ref{{"refs/tags/v1", hash}, {"refs/tags/v2", hash}}; !slices.Equal(tags, expected) {
+		t.Errorf("Expected %v, got %v", expected, tags)
+	}
+	if all, err := repo.ListRefs(""); err != nil || len(all) != 3 {
+		t.Errorf("Expected 3 refs, got %v (%v)", all, err)
+	}
+
+	for _, prefix := range []string{"heads/", "refs/../", "../"} {
+		if _, err := repo.ListRefs(prefix); err == nil {
+			t.Errorf("Expected invalid prefix %q to be refused", prefix)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+// Package gogit exposes a GoGit repository to Go programs.
+//
+// A Repository is safe for concurrent use by multiple goroutines. Object writes are
+// atomic on disk, and index and ref mutations are serialized within the process by
+// mutexes and across processes by lock files. Config is only read.
+package gogit
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/internal/index"
+	"github.com/KostasZigo/gogit/internal/objects"
+	"github.com/KostasZigo/gogit/internal/repository"
+)
+
+// Repository is a handle to a GoGit repository on disk.
+type Repository struct {
+	path    string
+	gitDir  string
+	bare    bool
+	store   *objects.ObjectStore
+	indexMu sync.RWMutex // Guards index reads and read-modify-write cycles within this process
+	refsMu  sync.RWMutex // Guards ref reads against updates and deletions within this process
+}
+
+// InitRepository creates a new repository at path and returns a handle to it.
+// An existing repository at path is reinitialized and opened.
+func InitRepository(path string) (*Repository, error) {
+	if _, err := repository.InitRepository(path, repository.InitOptions{}); err != nil {
+		return nil, err
+	}
+	return OpenRepository(path)
+}
+
+// OpenRepository returns a handle to the existing repository at path: a worktree holding
+// .gogit, or .git of a Git clone, or a bare repository.
+func OpenRepository(path string) (*Repository, error) {
+	layout, err := repository.Open(path)
+	if errors.Is(err, repository.ErrRepositoryNotFound) {
+		return nil, fmt.Errorf("not a gogit repository: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	return &Repository{
+		path:   layout.Root,
+		gitDir: layout.GitDir,
+		bare:   layout.Bare,
+		store:  objects.NewObjectStore(layout.GitDir),
+	}, nil
+}
+
+// Path returns the absolute path of the repository root, or of the metadata directory of a
+// bare repository.
+func (r *Repository) Path() string {
+	return r.path
+}
+
+// ConfigValue returns the value of section.key from the repository's config, falling back to
+// the user's config file. Returns "" when neither sets it.
+func (r *Repository) ConfigValue(section, key string) (string, error) {
+	return repository.ConfigValue(r.gitDir, section, key)
+}
+
+// WriteBlob stores content as a blob and returns its hash.
+func (r *Repository) WriteBlob(content []byte) (string, error) {
+	blob := objects.NewBlob(content)
+	if err := r.store.Store(blob); err != nil {
+		return "", err
+	}
+	return blob.Hash(), nil
+}
+
+// HasObject reports whether an object with the given full hash is stored.
+func (r *Repository) HasObject(hash string) bool {
+	return len(hash) == constants.HashStringLength && r.store.Exists(hash)
+}
+
+// Add stores the content of worktree files and stages them in the index.
+// Paths are relative to the repository root. Bare repositories have no files to add.
+func (r *Repository) Add(paths ...string) error {
+	if r.bare {
+		return errors.New("cannot add files in a bare repository")
+	}
+
+	entries := make([]index.Entry, 0, len(paths))
+	for _, path := range paths {
+		entry, err := r.stageEntry(path)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, *entry)
+	}
+
+	r.indexMu.Lock()
+	defer r.indexMu.Unlock()
+
+	return index.Update(r.gitDir, func(idx *index.Index) error {
+		for _, entry := range entries {
+			idx.Add(entry)
+		}
+		return nil
+	})
+}
+
+// stageEntry stores the blob for a worktree file and builds its index entry.
+func (r *Repository) stageEntry(path string) (*index.Entry, error) {
+	relPath, err := r.relativePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fullPath := filepath.Join(r.path, filepath.FromSlash(relPath))
+	info, err := os.Lstat(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", relPath, err)
+	}
+
+	var hash string
+	switch {
+	case info.Mode()&fs.ModeSymlink != 0:
+		target, err := os.Readlink(fullPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read symlink %s: %w", relPath, err)
+		}
+		hash, err = r.WriteBlob([]byte(target))
+		if err != nil {
+			return nil, err
+		}
+	case info.Mode().IsRegular():
+		hash, err = r.store.StoreBlobFile(fullPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to store %s: %w", relPath, err)
+		}
+	default:
+		return nil, fmt.Errorf("cannot add %s: not a regular file or symlink", relPath)
+	}
+
+	return index.NewEntry(relPath, hash, info)
+}
+
+// relativePath cleans path and converts it to slash-separated form relative to the root.
+// Paths escaping the repository or pointing into .gogit or .git are rejected.
+func (r *Repository) relativePath(path string) (string, error) {
+	if filepath.IsAbs(path) {
+		rel, err := filepath.Rel(r.path, path)
+		if err != nil {
+			return "", fmt.Errorf("path %s is outside repository", path)
+		}
+		path = rel
+	}
+
+	relPath := filepath.ToSlash(filepath.Clean(path))
+	if relPath == "." || relPath == ".." || strings.HasPrefix(relPath, "../") {
+		return "", fmt.Errorf("path %s is outside repository", path)
+	}
+	for _, metadataDir := range []string{constants.Gogit, constants.GitMetadataDir} {
+		if relPath == metadataDir || strings.HasPrefix(relPath, metadataDir+"/") {
+			return "", fmt.Errorf("path %s is inside the repository metadata directory", path)
+		}
+	}
+
+	return relPath, nil
+}
+
+// StagedPaths returns paths recorded in the index, in index order.
+func (r *Repository) StagedPaths() ([]string, error) {
+	r.indexMu.RLock()
+	defer r.indexMu.RUnlock()
+
+	idx, err := index.Read(r.gitDir)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, idx.Len())
+	for _, entry := range idx.Entries() {
+		paths = append(paths, entry.Path)
+	}
+	return paths, nil
+}
+
+// WriteTree writes the staged index content as tree objects and returns the root tree hash.
+// The updated cache tree is saved back to the index so unchanged directories are reused next time.
+func (r *Repository) WriteTree() (string, error) {
+	r.indexMu.Lock()
+	defer r.indexMu.Unlock()
+
+	var treeHash string
+	err := index.Update(r.gitDir, func(idx *index.Index) error {
+		hash, err := idx.WriteTree(r.store)
+		treeHash = hash
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return treeHash, nil
+}
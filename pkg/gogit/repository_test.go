@@ -0,0 +1,175 @@
+package gogit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/KostasZigo/gogit/internal/constants"
+	"github.com/KostasZigo/gogit/testutils"
+)
+
+// openTestRepository initializes repository in temporary directory and returns handle.
+func openTestRepository(t *testing.T) *Repository {
+	t.Helper()
+
+	repo, err := InitRepository(t.TempDir())
+	if err != nil {
+		t.Fatalf("InitRepository failed: %v", err)
+	}
+	return repo
+}
+
+// TestOpenRepository verifies handles open only on initialized repositories.
+func TestOpenRepository(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+
+	repo, err := OpenRepository(repoPath)
+	if err != nil {
+		t.Fatalf("OpenRepository failed: %v", err)
+	}
+	if repo.Path() != repoPath {
+		t.Errorf("Expected path %s, got %s", repoPath, repo.Path())
+	}
+
+	if _, err := OpenRepository(t.TempDir()); err == nil || !strings.Contains(err.Error(), "not a gogit repository") {
+		t.Errorf("Expected not a repository error, got %v", err)
+	}
+}
+
+// TestOpenRepository_GitAndBare verifies Git clones and bare repositories open as well, and
+// that bare repositories refuse worktree operations.
+func TestOpenRepository_GitAndBare(t *testing.T) {
+	repoPath := testutils.SetupTestRepoWithInit(t)
+	gitDir := filepath.Join(repoPath, constants.GitMetadataDir)
+	if err := os.Rename(filepath.Join(repoPath, constants.Gogit), gitDir); err != nil {
+		t.Fatalf("Failed to rename metadata directory: %v", err)
+	}
+
+	repo, err := OpenRepository(repoPath)
+	if err != nil {
+		t.Fatalf("OpenRepository failed for a Git clone: %v", err)
+	}
+	hash, err := repo.WriteBlob([]byte("shared\n"))
+	if err != nil {
+		t.Fatalf("WriteBlob failed: %v", err)
+	}
+
+	bare, err := OpenRepository(gitDir)
+	if err != nil {
+		t.Fatalf("OpenRepository failed for a bare repository: %v", err)
+	}
+	if bare.Path() != gitDir || !bare.HasObject(hash) {
+		t.Errorf("Expected bare repository at %s holding %s, got %s", gitDir, hash, bare.Path())
+	}
+	if err := bare.Add("file.txt"); err == nil || !strings.Contains(err.Error(), "bare repository") {
+		t.Errorf("Expected bare repository error, got %v", err)
+	}
+}
+
+// TestRepository_ConfigValue verifies values are read from the repository's config.
+func TestRepository_ConfigValue(t *testing.T) {
+	repo := openTestRepository(t)
+	testutils.CreateTestFile(t, repo.Path(), filepath.Join(constants.Gogit, "config"), []byte("[user]\n\tname = Library User\n"))
+
+	if value, err := repo.ConfigValue("user", "name"); err != nil || value != "Library User" {
+		t.Errorf("Expected Library User, got %q (%v)", value, err)
+	}
+}
+
+// TestRepository_WriteBlob verifies blobs are stored and reported as present.
+func TestRepository_WriteBlob(t *testing.T) {
+	repo := openTestRepository(t)
+
+	hash, err := repo.WriteBlob([]byte("library content\n"))
+	if err != nil {
+		t.Fatalf("WriteBlob failed: %v", err)
+	}
+	if !repo.HasObject(hash) {
+		t.Errorf("Expected object %s to exist", hash)
+	}
+	if repo.HasObject("abc") {
+		t.Error("Expected short hash to be reported missing")
+	}
+}
+
+// TestRepository_AddAndWriteTree verifies staged files are listed and written as a tree.
+func TestRepository_AddAndWriteTree(t *testing.T) {
+	repo := openTestRepository(t)
+	if err := os.MkdirAll(filepath.Join(repo.Path(), "dir"), constants.DirPerms); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	testutils.CreateTestFile(t, repo.Path(), "a.txt", []byte("a\n"))
+	testutils.CreateTestFile(t, repo.Path(), "dir/b.txt", []byte("b\n"))
+
+	if err := repo.Add("a.txt", filepath.Join(repo.Path(), "dir", "b.txt")); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	paths, err := repo.StagedPaths()
+	if err != nil {
+		t.Fatalf("StagedPaths failed: %v", err)
+	}
+	if !slices.Equal(paths, []string{"a.txt", "dir/b.txt"}) {
+		t.Errorf("Unexpected staged paths %v", paths)
+	}
+
+	treeHash, err := repo.WriteTree()
+	if err != nil {
+		t.Fatalf("WriteTree failed: %v", err)
+	}
+	if !repo.HasObject(treeHash) {
+		t.Errorf("Expected tree %s to be stored", treeHash)
+	}
+}
+
+// TestRepository_Add_RejectsInvalidPaths verifies paths outside the worktree are refused.
+func TestRepository_Add_RejectsInvalidPaths(t *testing.T) {
+	repo := openTestRepository(t)
+
+	for _, path := range []string{"../escape.txt", ".", ".gogit/HEAD", "missing.txt"} {
+		if err := repo.Add(path); err == nil {
+			t.Errorf("Expected error adding %q", path)
+		}
+	}
+}
+
+// TestRepository_ConcurrentAdd verifies concurrent goroutines can stage files without losing updates.
+func TestRepository_ConcurrentAdd(t *testing.T) {
+	repo := openTestRepository(t)
+
+	var expected []string
+	for i := range 20 {
+		name := fmt.Sprintf("file%02d.txt", i)
+		testutils.CreateTestFile(t, repo.Path(), name, []byte(name))
+		expected = append(expected, name)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(expected))
+	for _, name := range expected {
+		wg.Go(func() {
+			errs <- repo.Add(name)
+		})
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Concurrent add failed: %v", err)
+		}
+	}
+
+	paths, err := repo.StagedPaths()
+	if err != nil {
+		t.Fatalf("StagedPaths failed: %v", err)
+	}
+	if !slices.Equal(paths, expected) {
+		t.Errorf("Expected %v, got %v", expected, paths)
+	}
+}
@@ -0,0 +1,91 @@
+package testutils
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/KostasZigo/gogit/utils"
+)
+
+// PackEntry is an object of a test packfile: a whole object of Type, or a delta against
+// an earlier entry (OffsetDelta with BaseEntry) or a named object (BaseHash).
+type PackEntry struct {
+	Type        utils.ObjectType
+	Data        []byte // Object content, or delta instructions for deltas
+	OffsetDelta bool
+	BaseEntry   int
+	BaseHash    string
+}
+
+// packTypeCodes maps object types onto the type codes of pack entry headers.
+var packTypeCodes = map[utils.ObjectType]byte{
+	utils.CommitObjectType: 1,
+	utils.TreeObjectType:   2,
+	utils.BlobObjectType:   3,
+	utils.TagObjectType:    4,
+}
+
+// CreateTestPack encodes entries as a version 2 packfile, trailer included.
+func CreateTestPack(t *testing.T, entries []PackEntry) []byte {
+	t.Helper()
+
+	var pack bytes.Buffer
+	pack.WriteString("PACK")
+	pack.Write(binary.BigEndian.AppendUint32(nil, 2))
+	pack.Write(binary.BigEndian.AppendUint32(nil, uint32(len(entries))))
+
+	offsets := make([]int, len(entries))
+	for i, entry := range entries {
+		offsets[i] = pack.Len()
+
+		code := packTypeCodes[entry.Type]
+		switch {
+		case entry.OffsetDelta:
+			code = 6
+		case entry.BaseHash != "":
+			code = 7
+		}
+
+		// Type and size header, 4 size bits first and 7 per continuation byte
+		size := len(entry.Data)
+		c := code<<4 | byte(size&0x0f)
+		for size >>= 4; size > 0; size >>= 7 {
+			pack.WriteByte(c | 0x80)
+			c = byte(size & 0x7f)
+		}
+		pack.WriteByte(c)
+
+		switch code {
+		case 6:
+			distance := offsets[i] - offsets[entry.BaseEntry]
+			encoded := []byte{byte(distance & 0x7f)}
+			for distance >>= 7; distance > 0; distance >>= 7 {
+				distance--
+				encoded = append([]byte{byte(0x80 | distance&0x7f)}, encoded...)
+			}
+			pack.Write(encoded)
+		case 7:
+			name, err := hex.DecodeString(entry.BaseHash)
+			if err != nil {
+				t.Fatalf("Invalid base hash %q: %v", entry.BaseHash, err)
+			}
+			pack.Write(name)
+		}
+
+		compressor := zlib.NewWriter(&pack)
+		if _, err := compressor.Write(entry.Data); err != nil {
+			t.Fatalf("Failed to compress pack entry: %v", err)
+		}
+		if err := compressor.Close(); err != nil {
+			t.Fatalf("Failed to compress pack entry: %v", err)
+		}
+	}
+
+	sum := sha1.Sum(pack.Bytes())
+	pack.Write(sum[:])
+	return pack.Bytes()
+}
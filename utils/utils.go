@@ -2,9 +2,13 @@ package utils
 
 import (
 	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"path/filepath"
 	"strings"
+
+	"github.com/KostasZigo/gogit/internal/constants"
 )
 
 type ObjectType string
@@ -13,11 +17,12 @@ const (
 	BlobObjectType   ObjectType = "blob"
 	TreeObjectType   ObjectType = "tree"
 	CommitObjectType ObjectType = "commit"
+	TagObjectType    ObjectType = "tag"
 )
 
 func (ot ObjectType) IsValid() bool {
 	switch ot {
-	case BlobObjectType, TreeObjectType, CommitObjectType:
+	case BlobObjectType, TreeObjectType, CommitObjectType, TagObjectType:
 		return true
 	default:
 		return false
@@ -31,12 +36,38 @@ func ComputeHash(content []byte, objectType ObjectType) (string, error) {
 	}
 
 	// format: "ObjectType <size>\0<content>"
-	header := fmt.Sprintf("%v %d\x00", objectType, len(content))
+	header := ObjectHeader(objectType, int64(len(content)))
 	data := append([]byte(header), content...)
 	hash := sha1.Sum(data)
 	return fmt.Sprintf("%x", hash), nil
 }
 
+// ComputeHashFromReader calculates SHA-1 hash for object content streamed from reader.
+// Size must be known upfront since it is part of the hashed header; memory use is constant.
+func ComputeHashFromReader(reader io.Reader, size int64, objectType ObjectType) (string, error) {
+	if !objectType.IsValid() {
+		return "", fmt.Errorf("invalid object type: %s - hash not computed", objectType)
+	}
+
+	hasher := sha1.New()
+	hasher.Write([]byte(ObjectHeader(objectType, size)))
+
+	written, err := io.Copy(hasher, reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to read content: %w", err)
+	}
+	if written != size {
+		return "", fmt.Errorf("content size mismatch: expected %d bytes, read %d", size, written)
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// ObjectHeader builds object header "ObjectType <size>\0".
+func ObjectHeader(objectType ObjectType, size int64) string {
+	return fmt.Sprintf("%v %d\x00", objectType, size)
+}
+
 // MustComputeHash is a non-validating version of Compute Hash
 func MustComputeHash(content []byte, objectType ObjectType) string {
 	hash, err := ComputeHash(content, objectType)
@@ -51,3 +82,27 @@ func MustComputeHash(content []byte, objectType ObjectType) string {
 func BuildDirPath(dirs ...string) string {
 	return strings.Join(dirs, string(filepath.Separator)) + string(filepath.Separator)
 }
+
+// IsHash reports whether value is a full object hash: exactly 40 lowercase hexadecimal characters.
+func IsHash(value string) bool {
+	if len(value) != constants.HashStringLength {
+		return false
+	}
+	return !strings.ContainsFunc(value, func(r rune) bool {
+		return !(r >= '0' && r <= '9' || r >= 'a' && r <= 'f')
+	})
+}
+
+// ValidRefName reports whether name, such as a branch or tag name, follows Git's ref name rules:
+// no empty or dot-ending components, "..", "@{", control characters, spaces or ~^:?*[\.
+func ValidRefName(name string) bool {
+	if name == "" || name == "@" || strings.HasPrefix(name, "-") || strings.HasPrefix(name, "/") ||
+		strings.HasSuffix(name, "/") || strings.HasSuffix(name, ".") || strings.HasSuffix(name, ".lock") ||
+		strings.Contains(name, "..") || strings.Contains(name, "//") || strings.Contains(name, "@{") ||
+		strings.Contains(name, "/.") || strings.HasPrefix(name, ".") {
+		return false
+	}
+	return !strings.ContainsFunc(name, func(r rune) bool {
+		return r <= ' ' || r == 0x7f || strings.ContainsRune(`~^:?*[\`, r)
+	})
+}